@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/redisclient"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backfillFrom   string
+	backfillTo     string
+	backfillNoAI   bool
+	backfillDelay  time.Duration
+	backfillLimit  int
+	backfillForce  bool
+	backfillBackup bool
+)
+
+// backfillDay is one entry of the backfill --output json result.
+type backfillDay struct {
+	Date   string `json:"date"`
+	Stored int    `json:"stored"`
+	generateResult
+}
+
+// backfillCmd stores historical items under their period keys and renders a digest per day.
+// It never marks periods published, so the live builder is unaffected, and keeps
+// days whose digest already exists unless --force or --backup is given.
+var backfillCmd = &cobra.Command{
+	Use:   "backfill hackernews <channel>",
+	Short: "Backfill historical Hacker News digests for a date range",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := strings.ToLower(strings.TrimSpace(args[0]))
+		if source != "hackernews" {
+			return fmt.Errorf("backfill: unsupported source %q (only hackernews)", args[0])
+		}
+		channelName := args[1]
+		cfg := GetConfig()
+		found := false
+		for _, ch := range cfg.Newsletters.Channels {
+			if ch.Name == channelName {
+				if strings.ToLower(ch.Source) != source {
+					return fmt.Errorf("backfill: channel %s has source %q, not %s", channelName, ch.Source, source)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("channel not found: %s", channelName)
+		}
+		from, err := time.Parse("2006-01-02", backfillFrom)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		to, err := time.Parse("2006-01-02", backfillTo)
+		if err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("--to %s is before --from %s", backfillTo, backfillFrom)
+		}
+
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+		hnc, err := newHNClient(cfg)
+		if err != nil {
+			return err
+		}
+		scorer, err := sourceScorer(cfg, source)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		days := make([]backfillDay, 0)
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if len(days) > 0 && backfillDelay > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(backfillDelay):
+				}
+			}
+			date := day.Format("2006-01-02")
+			ctxReq, cancelReq := context.WithTimeout(ctx, 30*time.Second)
+			items, err := hnc.FrontPageBetween(ctxReq, day, day.AddDate(0, 0, 1), backfillLimit)
+			cancelReq()
+			if err != nil {
+				return fmt.Errorf("backfill %s: %w", date, err)
+			}
+			dayKey := period.Key(period.Daily, day)
+			weekKey := period.Key(period.Weekly, day)
+			// Score as of the day's end, as the live collector would have, not against today.
+			scoredAt := day.AddDate(0, 0, 1)
+			stored := 0
+			for _, it := range items {
+				score := scorer.Score(it, scoredAt)
+				if score <= 0 {
+					continue
+				}
+				if err := store.AddNews(ctx, source, dayKey, it, score); err != nil {
+					return fmt.Errorf("backfill %s: store item %s: %w", date, it.ID, err)
+				}
+				if err := store.AddNews(ctx, source, weekKey, it, score); err != nil {
+					return fmt.Errorf("backfill %s: store item %s: %w", date, it.ID, err)
+				}
+				stored++
+			}
+			slog.Info("backfill: stored items", "date", date, "fetched", len(items), "stored", stored)
+
+			res, err := runGenerate(ctx, cmd, channelName, generateOptions{At: day, NoAI: backfillNoAI, Force: backfillForce, Backup: backfillBackup, SkipExisting: true})
+			if err != nil {
+				return fmt.Errorf("backfill %s: %w", date, err)
+			}
+			days = append(days, backfillDay{Date: date, Stored: stored, generateResult: res})
+		}
+		return emit(cmd, map[string]any{"channel": channelName, "days": days}, func(w io.Writer) {
+			for _, d := range days {
+				switch {
+				case d.SkippedReason == nil:
+					fmt.Fprintf(w, "%s: stored %d, generated %s (%d items)\n", d.Date, d.Stored, d.Path, d.Items)
+				default:
+					fmt.Fprintf(w, "%s: stored %d, skipped (%s)\n", d.Date, d.Stored, *d.SkippedReason)
+				}
+			}
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+	backfillCmd.Flags().StringVar(&backfillFrom, "from", "", "first date to backfill (YYYY-MM-DD, UTC)")
+	backfillCmd.Flags().StringVar(&backfillTo, "to", "", "last date to backfill, inclusive (YYYY-MM-DD, UTC)")
+	backfillCmd.Flags().BoolVar(&backfillNoAI, "no-ai", false, "skip AI summaries and cover image generation")
+	backfillCmd.Flags().DurationVar(&backfillDelay, "delay", 2*time.Second, "pause between days to rate-limit API calls")
+	backfillCmd.Flags().IntVar(&backfillLimit, "limit", 50, "max front-page stories fetched per day")
+	backfillCmd.Flags().BoolVar(&backfillForce, "force", false, "overwrite digest files that already exist (default: keep them and skip the day)")
+	backfillCmd.Flags().BoolVar(&backfillBackup, "backup", false, "keep an existing digest file as <name>.md.bak-<timestamp>, then overwrite it")
+	_ = backfillCmd.MarkFlagRequired("from")
+	_ = backfillCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,593 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/arxiv"
+	"quaily-journalist/internal/bluesky"
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/email"
+	"quaily-journalist/internal/githubtrending"
+	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/httpclient"
+	"quaily-journalist/internal/jsonfeed"
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/mastodon"
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/producthunt"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/reddit"
+	"quaily-journalist/internal/rss"
+	"quaily-journalist/internal/scrape"
+	"quaily-journalist/internal/stackoverflow"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/telegram"
+	"quaily-journalist/internal/v2ex"
+	"quaily-journalist/internal/youtube"
+	"quaily-journalist/worker"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+// newStore wraps rdb in a RedisStore honoring the `storage` config block.
+func newStore(cfg config.Config, rdb *redis.Client) *storage.RedisStore {
+	return storage.NewRedisStore(rdb).WithItemCompression(cfg.Storage.CompressItems)
+}
+
+// Constructors for outbound API clients, wired to the shared HTTP client factory
+// so per-service proxy/timeout settings from the `http` config block apply.
+
+func newV2EXClient(cfg config.Config) (*v2ex.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.V2EX, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return v2ex.NewClient(cfg.Sources.V2EX.BaseURL, cfg.Sources.V2EX.Token).WithHTTPClient(hc).WithSearchAPI(cfg.Sources.V2EX.SearchAPI), nil
+}
+
+func newHNClient(cfg config.Config) (*hackernews.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.HackerNews, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := hackernews.NewClient(cfg.Sources.HN.BaseAPI).WithAlgoliaAPI(cfg.Sources.HN.AlgoliaAPI).WithHTTPClient(hc)
+	if r := cfg.Sources.HN.MaxFailRatio; r != nil {
+		c = c.WithMaxFailRatio(*r)
+	}
+	return c, nil
+}
+
+func newRSSClient(cfg config.Config) (*rss.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.RSS, 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return rss.NewClient().WithHTTPClient(hc), nil
+}
+
+func newLobstersClient(cfg config.Config) (*lobsters.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.Lobsters, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return lobsters.NewClient(cfg.Sources.Lobsters.BaseURL).WithHTTPClient(hc), nil
+}
+
+func newRedditClient(cfg config.Config) (*reddit.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.Reddit, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return reddit.NewClient(cfg.Sources.Reddit.BaseURL, cfg.Sources.Reddit.UserAgent).WithHTTPClient(hc), nil
+}
+
+func newGitHubClient(cfg config.Config) (*githubtrending.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.GitHub, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return githubtrending.NewClient(cfg.Sources.GitHub.BaseURL, cfg.Sources.GitHub.Token).WithHTTPClient(hc), nil
+}
+
+func newArxivClient(cfg config.Config) (*arxiv.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.Arxiv, 20*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return arxiv.NewClient(cfg.Sources.Arxiv.BaseURL).WithHTTPClient(hc), nil
+}
+
+func newProductHuntClient(cfg config.Config) (*producthunt.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.ProductHunt, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return producthunt.NewClient(cfg.Sources.ProductHunt.BaseURL, cfg.Sources.ProductHunt.Token).WithHTTPClient(hc), nil
+}
+
+// newMastodonClients returns a client per configured instance, sharing one HTTP
+// client; instances without a base_url are dropped.
+func newMastodonClients(cfg config.Config) ([]*mastodon.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.Mastodon, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	var out []*mastodon.Client
+	for _, in := range cfg.Sources.Mastodon.Instances {
+		if strings.TrimSpace(in.BaseURL) != "" {
+			out = append(out, mastodon.NewClient(in.BaseURL, in.Token).WithHTTPClient(hc))
+		}
+	}
+	return out, nil
+}
+
+func newBlueskyClient(cfg config.Config) (*bluesky.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.Bluesky, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return bluesky.NewClient(cfg.Sources.Bluesky.BaseURL).WithHTTPClient(hc), nil
+}
+
+func newStackOverflowClient(cfg config.Config) (*stackoverflow.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.StackOverflow, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	so := cfg.Sources.StackOverflow
+	return stackoverflow.NewClient(so.BaseURL, so.Site, so.Key).WithHTTPClient(hc), nil
+}
+
+func newJSONFeedClient(cfg config.Config) (*jsonfeed.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.JSONFeed, 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return jsonfeed.NewClient().WithHTTPClient(hc), nil
+}
+
+func newYouTubeClient(cfg config.Config) (*youtube.Client, error) {
+	hc, err := httpclient.New(cfg, httpclient.YouTube, 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	yt := cfg.Sources.YouTube
+	return youtube.NewClient(yt.BaseURL, yt.APIBaseURL, yt.APIKey).WithHTTPClient(hc), nil
+}
+
+// mockSourcesDir is set by --mock-sources; when non-empty the V2EX, Hacker News,
+// RSS, Lobste.rs, Reddit, GitHub, Product Hunt, arXiv, Mastodon, Bluesky, Stack
+// Overflow, JSON Feed, and YouTube sources read fixture files from it instead of
+// calling the APIs.
+var mockSourcesDir string
+
+func addMockSourcesFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&mockSourcesDir, "mock-sources", "", "read V2EX/Hacker News/RSS/Lobste.rs/Reddit/GitHub/Product Hunt/arXiv/Mastodon/Bluesky/Stack Overflow/JSON Feed/YouTube items from fixture files under this directory instead of the APIs (see fixtures/)")
+}
+
+// newV2EXSource returns the V2EX client, or the fixture source under --mock-sources.
+func newV2EXSource(cfg config.Config) (worker.V2EXSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewV2EX(mockSourcesDir), nil
+	}
+	c, err := newV2EXClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newHNSource returns the Hacker News client, or the fixture source under --mock-sources.
+func newHNSource(cfg config.Config) (worker.HNSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewHackerNews(mockSourcesDir), nil
+	}
+	c, err := newHNClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newRSSSource returns the feed client, or the fixture source under --mock-sources.
+func newRSSSource(cfg config.Config) (worker.RSSSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewRSS(mockSourcesDir), nil
+	}
+	c, err := newRSSClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newLobstersSource returns the Lobste.rs client, or the fixture source under --mock-sources.
+func newLobstersSource(cfg config.Config) (worker.LobstersSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewLobsters(mockSourcesDir), nil
+	}
+	c, err := newLobstersClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newRedditSource returns the Reddit client, or the fixture source under --mock-sources.
+func newRedditSource(cfg config.Config) (worker.RedditSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewReddit(mockSourcesDir), nil
+	}
+	c, err := newRedditClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newGitHubSource returns the GitHub search client, or the fixture source under --mock-sources.
+func newGitHubSource(cfg config.Config) (worker.GitHubTrendingSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewGitHub(mockSourcesDir), nil
+	}
+	c, err := newGitHubClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newProductHuntSource returns the Product Hunt client, or the fixture source under --mock-sources.
+func newProductHuntSource(cfg config.Config) (worker.ProductHuntSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewProductHunt(mockSourcesDir), nil
+	}
+	c, err := newProductHuntClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newArxivSource returns the arXiv client, or the fixture source under --mock-sources.
+func newArxivSource(cfg config.Config) (worker.ArxivSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewArxiv(mockSourcesDir), nil
+	}
+	c, err := newArxivClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newMastodonSources returns a client per configured instance or, under
+// --mock-sources, a fixture source per instance host (the mastodon channels' nodes
+// when no instance is configured).
+func newMastodonSources(cfg config.Config) ([]worker.MastodonSource, error) {
+	var out []worker.MastodonSource
+	if mockSourcesDir != "" {
+		var hosts []string
+		for _, in := range cfg.Sources.Mastodon.Instances {
+			if strings.TrimSpace(in.BaseURL) != "" {
+				hosts = append(hosts, mastodon.Host(in.BaseURL))
+			}
+		}
+		if len(hosts) == 0 {
+			hosts = sourceNodeUnion(cfg, "mastodon")
+		}
+		for _, h := range hosts {
+			out = append(out, mocksource.NewMastodon(mockSourcesDir, h))
+		}
+		return out, nil
+	}
+	clients, err := newMastodonClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clients {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// newBlueskySource returns the AppView client, or the fixture source under --mock-sources.
+func newBlueskySource(cfg config.Config) (worker.BlueskySource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewBluesky(mockSourcesDir), nil
+	}
+	c, err := newBlueskyClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// blueskyFeeds returns the configured feeds and searches; entries setting neither a
+// feed nor a query are dropped.
+func blueskyFeeds(cfg config.Config) []worker.BlueskyFeed {
+	var feeds []worker.BlueskyFeed
+	for _, f := range cfg.Sources.Bluesky.Feeds {
+		uri, query := strings.TrimSpace(f.Feed), strings.TrimSpace(f.Query)
+		if uri == "" && query == "" {
+			continue
+		}
+		feeds = append(feeds, worker.BlueskyFeed{Node: f.Node(), URI: uri, Query: query})
+	}
+	return feeds
+}
+
+// newStackOverflowSource returns the Stack Exchange client, or the fixture source
+// under --mock-sources.
+func newStackOverflowSource(cfg config.Config) (worker.StackOverflowSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewStackOverflow(mockSourcesDir), nil
+	}
+	c, err := newStackOverflowClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// stackOverflowTags returns the tags the Stack Overflow collector polls: the nodes
+// of channels with source stackoverflow, plus "" (the whole hot list) when one of
+// them has no nodes. None means no collector.
+func stackOverflowTags(cfg config.Config) []string {
+	tags := sourceNodeUnion(cfg, "stackoverflow")
+	for _, ch := range cfg.Newsletters.Channels {
+		if strings.ToLower(ch.Source) == "stackoverflow" && len(ch.Nodes) == 0 {
+			return append([]string{""}, tags...)
+		}
+	}
+	return tags
+}
+
+// newJSONFeedSource returns the JSON Feed client, or the fixture source under
+// --mock-sources.
+func newJSONFeedSource(cfg config.Config) (worker.JSONFeedSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewJSONFeed(mockSourcesDir), nil
+	}
+	c, err := newJSONFeedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// jsonFeeds returns the configured JSON Feeds with blank URLs dropped.
+func jsonFeeds(cfg config.Config) []worker.JSONFeed {
+	var feeds []worker.JSONFeed
+	for _, f := range cfg.Sources.JSONFeed.Feeds {
+		if u := strings.TrimSpace(f.URL); u != "" {
+			feeds = append(feeds, worker.JSONFeed{URL: u, Label: strings.TrimSpace(f.Label)})
+		}
+	}
+	return feeds
+}
+
+// newYouTubeSource returns the YouTube client, or the fixture source under
+// --mock-sources.
+func newYouTubeSource(cfg config.Config) (worker.YouTubeSource, error) {
+	if mockSourcesDir != "" {
+		return mocksource.NewYouTube(mockSourcesDir), nil
+	}
+	c, err := newYouTubeClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// youTubeFeeds returns the configured channels and playlists; entries setting
+// neither a channel_id nor a playlist_id are dropped.
+func youTubeFeeds(cfg config.Config) []worker.YouTubeFeed {
+	var feeds []worker.YouTubeFeed
+	for _, f := range cfg.Sources.YouTube.Feeds {
+		channel, playlist := strings.TrimSpace(f.ChannelID), strings.TrimSpace(f.PlaylistID)
+		if channel == "" && playlist == "" {
+			continue
+		}
+		feeds = append(feeds, worker.YouTubeFeed{ChannelID: channel, PlaylistID: playlist, Label: strings.TrimSpace(f.Label)})
+	}
+	return feeds
+}
+
+// rssFeeds returns the configured feeds with blank URLs dropped.
+func rssFeeds(cfg config.Config) []worker.RSSFeed {
+	var feeds []worker.RSSFeed
+	for _, f := range cfg.Sources.RSS.Feeds {
+		if u := strings.TrimSpace(f.URL); u != "" {
+			feeds = append(feeds, worker.RSSFeed{URL: u, Node: strings.TrimSpace(f.Node)})
+		}
+	}
+	return feeds
+}
+
+// newQuailyClient returns the client of a Quaily profile ("" is the default). It
+// fails when the profile is undefined or lacks base_url or api_key.
+func newQuailyClient(cfg config.Config, profile string, timeout time.Duration) (*quaily.Client, error) {
+	p, ok := cfg.Quaily.Profile(profile)
+	if !ok {
+		return nil, fmt.Errorf("quaily profile %q is not defined under quaily.profiles", profile)
+	}
+	if !p.Configured() {
+		if name := strings.ToLower(strings.TrimSpace(profile)); name != "" && name != config.DefaultQuailyProfile {
+			return nil, fmt.Errorf("quaily config missing: set quaily.profiles.%s.base_url and api_key in config.yaml", name)
+		}
+		return nil, fmt.Errorf("quaily config missing: set quaily.base_url and quaily.api_key in config.yaml")
+	}
+	hc, err := httpclient.New(cfg, httpclient.Quaily, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return quaily.New(p.BaseURL, p.APIKey, timeout).WithHTTPClient(hc).WithExtraParams(cfg.Quaily.ExtraParams), nil
+}
+
+// newChannelQuailyClients returns, per channel name, the client of the channel's
+// quaily_profile; channels whose profile is not configured are left out. Channels
+// sharing a profile share its client.
+func newChannelQuailyClients(cfg config.Config, timeout time.Duration) (map[string]*quaily.Client, error) {
+	byProfile := map[string]*quaily.Client{}
+	out := map[string]*quaily.Client{}
+	for _, ch := range cfg.Newsletters.Channels {
+		name := strings.ToLower(strings.TrimSpace(ch.QuailyProfile))
+		if name == "" {
+			name = config.DefaultQuailyProfile
+		}
+		c, ok := byProfile[name]
+		if !ok {
+			if p, defined := cfg.Quaily.Profile(name); defined && p.Configured() {
+				var err error
+				if c, err = newQuailyClient(cfg, name, timeout); err != nil {
+					return nil, err
+				}
+			}
+			byProfile[name] = c
+		}
+		if c != nil {
+			out[ch.Name] = c
+		}
+	}
+	return out, nil
+}
+
+// newSummarizer returns the OpenAI client; serve shares it between every builder so
+// openai.max_concurrent_requests bounds the whole process. Tokens spent are counted
+// in the store for the health report.
+func newSummarizer(cfg config.Config, store *storage.RedisStore) *ai.OpenAIClient {
+	return ai.NewOpenAI(ai.Config{
+		APIKey:                cfg.OpenAI.APIKey,
+		Model:                 cfg.OpenAI.Model,
+		BaseURL:               cfg.OpenAI.BaseURL,
+		MaxConcurrentRequests: cfg.OpenAI.MaxConcurrentRequests,
+		OnUsage:               worker.CountAITokens(store),
+		WhyItMattersPrompt:    cfg.OpenAI.WhyItMattersPrompt,
+	})
+}
+
+func newCloudflareClient(cfg config.Config) (*scrape.CloudflareClient, error) {
+	const timeout = 20 * time.Second
+	hc, err := httpclient.New(cfg, httpclient.Cloudflare, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return scrape.NewCloudflare(cfg.Cloudflare.AccountID, cfg.Cloudflare.APIToken, timeout).WithHTTPClient(hc), nil
+}
+
+// newNotifier returns the configured webhook notifier, or nil when none is configured.
+// newEmailTargets returns the email output of every channel that enables one.
+func newEmailTargets(cfg config.Config) (map[string]worker.EmailTarget, error) {
+	out := map[string]worker.EmailTarget{}
+	for _, ch := range cfg.Newsletters.Channels {
+		e := ch.Email
+		if !e.Enabled() {
+			continue
+		}
+		if err := email.CheckTLSMode(e.TLS); err != nil {
+			return nil, fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		if e.From == "" {
+			return nil, fmt.Errorf("channel %s: email.from is required", ch.Name)
+		}
+		formats, err := newsletter.ParseFormats(ch.Formats)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		if !slices.Contains(formats, newsletter.FormatHTML) {
+			return nil, fmt.Errorf("channel %s: email output needs the html format in formats", ch.Name)
+		}
+		out[ch.Name] = worker.EmailTarget{
+			Sender: &email.Sender{
+				Host:               e.Host,
+				Port:               e.Port,
+				Username:           e.Username,
+				Password:           e.Password,
+				From:               e.From,
+				To:                 e.To,
+				TLS:                e.TLS,
+				InsecureSkipVerify: e.InsecureSkipVerify,
+			},
+			Subject: e.Subject,
+		}
+	}
+	return out, nil
+}
+
+// newTelegramTargets returns the Telegram output of every channel that enables one.
+func newTelegramTargets(cfg config.Config) (map[string]worker.TelegramTarget, error) {
+	out := map[string]worker.TelegramTarget{}
+	for _, ch := range cfg.Newsletters.Channels {
+		tc := ch.Telegram
+		if !tc.Enabled() {
+			continue
+		}
+		if err := telegram.CheckFormat(tc.Format); err != nil {
+			return nil, fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		formats, err := newsletter.ParseFormats(ch.Formats)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		if !slices.Contains(formats, newsletter.FormatMarkdown) {
+			return nil, fmt.Errorf("channel %s: telegram output needs the markdown format in formats", ch.Name)
+		}
+		hc, err := httpclient.New(cfg, httpclient.Telegram, 20*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		out[ch.Name] = worker.TelegramTarget{
+			Client: telegram.New(tc.BaseURL, tc.BotToken).WithHTTPClient(hc),
+			ChatID: tc.ChatID,
+			Format: tc.Format,
+		}
+	}
+	return out, nil
+}
+
+// newChatTargets returns the Discord and Slack outputs of every channel, keyed by
+// worker.ChatKey. They share the notify HTTP client settings.
+func newChatTargets(cfg config.Config) (map[string]worker.ChatTarget, error) {
+	out := map[string]worker.ChatTarget{}
+	for _, ch := range cfg.Newsletters.Channels {
+		for _, c := range []struct {
+			platform string
+			cfg      config.ChatWebhookConfig
+		}{{notify.PlatformDiscord, ch.Discord}, {notify.PlatformSlack, ch.Slack}} {
+			if !c.cfg.Enabled() {
+				continue
+			}
+			formats, err := newsletter.ParseFormats(ch.Formats)
+			if err != nil {
+				return nil, fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			if !slices.Contains(formats, newsletter.FormatMarkdown) {
+				return nil, fmt.Errorf("channel %s: %s output needs the markdown format in formats", ch.Name, c.platform)
+			}
+			hc, err := httpclient.New(cfg, httpclient.Notify, 10*time.Second)
+			if err != nil {
+				return nil, err
+			}
+			out[worker.ChatKey(ch.Name, c.platform)] = worker.ChatTarget{
+				Webhook: &notify.ChatWebhook{Platform: c.platform, URL: strings.TrimSpace(c.cfg.WebhookURL), HTTP: hc},
+			}
+		}
+	}
+	return out, nil
+}
+
+func newNotifier(cfg config.Config) (notify.Notifier, error) {
+	hc, err := httpclient.New(cfg, httpclient.Notify, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewWebhooks(cfg.Notify.WebhookURLs, hc), nil
+}
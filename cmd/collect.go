@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+// collectCmd runs one pass of the collectors that serve would start, then exits.
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Run the collectors once and exit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		res, err := collectOnce(ctx, cfg, store)
+		if err != nil {
+			return err
+		}
+		return emit(cmd, res, func(w io.Writer) {
+			if len(res.Sources) == 0 {
+				fmt.Fprintln(w, "No collector configured.")
+				return
+			}
+			for _, src := range res.Sources {
+				r := res.Results[src]
+				fmt.Fprintf(w, "Collected %s: %d fetched, %d stored", src, r.Fetched, r.Stored)
+				if r.Failed > 0 {
+					fmt.Fprintf(w, ", %d failed (see log)", r.Failed)
+				}
+				if r.Buffered > 0 {
+					fmt.Fprintf(w, ", %d not stored (redis unavailable)", r.Buffered)
+				}
+				fmt.Fprintln(w)
+			}
+		})
+	},
+}
+
+// collectResult is the --output json schema of the collect command.
+type collectResult struct {
+	Sources []string                        `json:"sources"`
+	Results map[string]worker.CollectResult `json:"results"` // by source
+}
+
+// collectOnce runs a single pass of the V2EX, Hacker News, RSS, Lobste.rs, and
+// Reddit collectors for the nodes of cfg's channels and the configured feeds and lists,
+// under the same conditions serve starts them. Nodes or lists that
+// fail are logged and counted in the results rather than returned as errors.
+func collectOnce(ctx context.Context, cfg config.Config, store *storage.RedisStore) (collectResult, error) {
+	res := collectResult{Sources: []string{}, Results: map[string]worker.CollectResult{}}
+	if nodes := v2exNodeUnion(cfg); len(nodes) > 0 && (cfg.Sources.V2EX.Token != "" || mockSourcesDir != "") {
+		src, err := newV2EXSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "v2ex")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.V2EXCollector{
+			Client:             src,
+			Store:              store,
+			Nodes:              nodes,
+			MaxContentRunes:    cfg.Sources.V2EX.MaxContentRunes,
+			IncludeSupplements: cfg.Sources.V2EX.IncludeSupplements && cfg.Sources.V2EX.Token != "",
+			IncludePoints:      cfg.Sources.V2EX.IncludePoints && (cfg.Sources.V2EX.Token != "" || mockSourcesDir != ""),
+			Ranking:            scorer,
+			ArchiveRaw:         cfg.Sources.ArchiveRaw,
+		}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "v2ex")
+		res.Results["v2ex"] = r
+	}
+	if hasSource(cfg, "hackernews") && (cfg.Sources.HN.BaseAPI != "" || mockSourcesDir != "") {
+		src, err := newHNSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "hackernews")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.HNCollector{
+			Client:       src,
+			Store:        store,
+			Lists:        hnListUnion(cfg),
+			LimitPerList: 64,
+			Ranking:      scorer,
+			ArchiveRaw:   cfg.Sources.ArchiveRaw,
+		}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "hackernews")
+		res.Results["hackernews"] = r
+	}
+	if feeds := rssFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "rss") {
+		src, err := newRSSSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "rss")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.RSSCollector{Client: src, Store: store, Feeds: feeds, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "rss")
+		res.Results["rss"] = r
+	}
+	if hasSource(cfg, "lobsters") && (cfg.Sources.Lobsters.BaseURL != "" || mockSourcesDir != "") {
+		src, err := newLobstersSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "lobsters")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.LobstersCollector{Client: src, Store: store, Lists: cfg.Sources.Lobsters.Lists, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "lobsters")
+		res.Results["lobsters"] = r
+	}
+	if subs := sourceNodeUnion(cfg, "reddit"); len(subs) > 0 {
+		src, err := newRedditSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "reddit")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.RedditCollector{Client: src, Store: store, Subreddits: subs, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "reddit")
+		res.Results["reddit"] = r
+	}
+	if langs := sourceNodeUnion(cfg, "github"); len(langs) > 0 {
+		src, err := newGitHubSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		within, err := time.ParseDuration(cfg.Sources.GitHub.CreatedWithin)
+		if err != nil {
+			return res, fmt.Errorf("invalid sources.github.created_within: %w", err)
+		}
+		scorer, err := sourceScorer(cfg, "github")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.GitHubTrendingCollector{Client: src, Store: store, Languages: langs, CreatedWithin: within, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "github")
+		res.Results["github"] = r
+	}
+	if hasSource(cfg, "producthunt") && (cfg.Sources.ProductHunt.Token != "" || mockSourcesDir != "") {
+		src, err := newProductHuntSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "producthunt")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.ProductHuntCollector{Client: src, Store: store, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "producthunt")
+		res.Results["producthunt"] = r
+	}
+	if cats := sourceNodeUnion(cfg, "arxiv"); len(cats) > 0 {
+		src, err := newArxivSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "arxiv")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.ArxivCollector{Client: src, Store: store, Categories: cats, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "arxiv")
+		res.Results["arxiv"] = r
+	}
+	if hasSource(cfg, "mastodon") {
+		instances, err := newMastodonSources(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "mastodon")
+		if err != nil {
+			return res, err
+		}
+		if len(instances) > 0 {
+			r, _ := (&worker.MastodonCollector{Instances: instances, Store: store, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+			res.Sources = append(res.Sources, "mastodon")
+			res.Results["mastodon"] = r
+		}
+	}
+	if feeds := blueskyFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "bluesky") {
+		src, err := newBlueskySource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "bluesky")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.BlueskyCollector{Client: src, Store: store, Feeds: feeds, Limit: cfg.Sources.Bluesky.Limit, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "bluesky")
+		res.Results["bluesky"] = r
+	}
+	if tags := stackOverflowTags(cfg); len(tags) > 0 {
+		src, err := newStackOverflowSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "stackoverflow")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.StackOverflowCollector{Client: src, Store: store, Tags: tags, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "stackoverflow")
+		res.Results["stackoverflow"] = r
+	}
+	if feeds := jsonFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "jsonfeed") {
+		src, err := newJSONFeedSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "jsonfeed")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.JSONFeedCollector{Client: src, Store: store, Feeds: feeds, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "jsonfeed")
+		res.Results["jsonfeed"] = r
+	}
+	if feeds := youTubeFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "youtube") {
+		src, err := newYouTubeSource(cfg)
+		if err != nil {
+			return res, err
+		}
+		scorer, err := sourceScorer(cfg, "youtube")
+		if err != nil {
+			return res, err
+		}
+		r, _ := (&worker.YouTubeCollector{Client: src, Store: store, Feeds: feeds, Ranking: scorer, ArchiveRaw: cfg.Sources.ArchiveRaw}).RunOnce(ctx)
+		res.Sources = append(res.Sources, "youtube")
+		res.Results["youtube"] = r
+	}
+	return res, nil
+}
+
+// hasSource reports whether any channel reads from source.
+func hasSource(cfg config.Config, source string) bool {
+	for _, ch := range cfg.Newsletters.Channels {
+		if strings.ToLower(ch.Source) == source {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(collectCmd)
+	addMockSourcesFlag(collectCmd)
+}
@@ -2,7 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"quaily-journalist/internal/markdown"
@@ -18,21 +19,24 @@ var debugParseCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Fprintf(os.Stdout, "frontmatter keys: ")
-		first := true
+		keys := make([]string, 0, len(doc.Frontmatter))
 		for k := range doc.Frontmatter {
-			if !first {
-				fmt.Fprint(os.Stdout, ", ")
-			}
-			fmt.Fprint(os.Stdout, k)
-			first = false
+			keys = append(keys, k)
 		}
-		fmt.Fprintln(os.Stdout)
-		fmt.Fprintf(os.Stdout, "body bytes: %d\n", len(doc.Body))
-		return nil
+		res := debugParseResult{FrontmatterKeys: keys, BodyBytes: len(doc.Body)}
+		return emit(cmd, res, func(w io.Writer) {
+			fmt.Fprintf(w, "frontmatter keys: %s\n", strings.Join(keys, ", "))
+			fmt.Fprintf(w, "body bytes: %d\n", res.BodyBytes)
+		})
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(debugParseCmd)
 }
+
+// debugParseResult is the --output json schema of the debug-parse command.
+type debugParseResult struct {
+	FrontmatterKeys []string `json:"frontmatter_keys"`
+	BodyBytes       int      `json:"body_bytes"`
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+// deliveriesCmd groups commands for inspecting and retrying queued Quaily deliveries.
+var deliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "Inspect and retry queued deliveries (Quaily sends, emails, Telegram, Discord and Slack posts)",
+}
+
+// deliveriesResult is the --output json schema of the deliveries commands.
+type deliveriesResult struct {
+	Deliveries []storage.DeliveryTask `json:"deliveries"`
+}
+
+var deliveriesListCmd = &cobra.Command{
+	Use:   "list [channel]",
+	Short: "List delivery tasks (pending, done, dead), oldest first",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel := ""
+		if len(args) == 1 {
+			channel = strings.TrimSpace(args[0])
+		}
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		tasks, err := store.Deliveries(ctx, channel)
+		if err != nil {
+			return err
+		}
+		return emit(cmd, deliveriesResult{Deliveries: tasks}, func(w io.Writer) {
+			if len(tasks) == 0 {
+				fmt.Fprintln(w, "No delivery tasks.")
+				return
+			}
+			printDeliveries(w, tasks)
+		})
+	},
+}
+
+var deliveriesRetryCmd = &cobra.Command{
+	Use:   "retry <channel> [slug]",
+	Short: "Retry pending and dead deliveries of a channel now (or one post by slug)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel := strings.TrimSpace(args[0])
+		cfg := GetConfig()
+		// Quaily tasks fail (and stay retryable) when the channel's Quaily profile is not configured.
+		qclients, err := newChannelQuailyClients(cfg, 20*time.Second)
+		if err != nil {
+			return err
+		}
+		emailTargets, err := newEmailTargets(cfg)
+		if err != nil {
+			return err
+		}
+		telegramTargets, err := newTelegramTargets(cfg)
+		if err != nil {
+			return err
+		}
+		chatTargets, err := newChatTargets(cfg)
+		if err != nil {
+			return err
+		}
+		notifier, err := newNotifier(cfg)
+		if err != nil {
+			return err
+		}
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		all, err := store.Deliveries(ctx, channel)
+		if err != nil {
+			return err
+		}
+		var tasks []storage.DeliveryTask
+		if len(args) == 2 {
+			// Every target of the post, whatever its state.
+			slug := strings.TrimSpace(args[1])
+			for _, t := range all {
+				if t.Slug == slug {
+					tasks = append(tasks, t)
+				}
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no delivery task for %s/%s", channel, slug)
+			}
+		} else {
+			for _, t := range all {
+				if t.State != storage.DeliveryDone {
+					tasks = append(tasks, t)
+				}
+			}
+		}
+		rec := &worker.DeliveryReconciler{
+			Store:       store,
+			Quaily:      qclients,
+			Email:       emailTargets,
+			Telegram:    telegramTargets,
+			Chat:        chatTargets,
+			Notifier:    notifier,
+			MaxAttempts: cfg.Quaily.DeliveryMaxAttempts,
+		}
+		out := make([]storage.DeliveryTask, 0, len(tasks))
+		for _, t := range tasks {
+			// A manual retry starts a fresh attempt budget.
+			t.Attempts = 0
+			out = append(out, rec.Deliver(ctx, t))
+		}
+		return emit(cmd, deliveriesResult{Deliveries: out}, func(w io.Writer) {
+			if len(out) == 0 {
+				fmt.Fprintf(w, "No deliveries to retry for %s.\n", channel)
+				return
+			}
+			printDeliveries(w, out)
+		})
+	},
+}
+
+// printDeliveries writes one line per task.
+func printDeliveries(w io.Writer, tasks []storage.DeliveryTask) {
+	for _, t := range tasks {
+		target := t.Target
+		if target == "" {
+			target = storage.TargetQuaily
+		}
+		line := fmt.Sprintf("%-8s %-8s %s/%s attempts=%d", t.State, target, t.Channel, t.Slug, t.Attempts)
+		if t.State == storage.DeliveryPending {
+			line += " next=" + t.NextAttemptAt.Local().Format(time.RFC3339)
+		}
+		if t.LastError != "" {
+			line += " error=" + t.LastError
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(deliveriesCmd)
+	deliveriesCmd.AddCommand(deliveriesListCmd)
+	deliveriesCmd.AddCommand(deliveriesRetryCmd)
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/textdiff"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffDate   string
+	diffRemote bool
+	diffNoAI   bool
+)
+
+// Exit statuses of the diff command; errors exit with 1 like every command.
+const (
+	diffExitIdentical = 0
+	diffExitDiffers   = 2
+	diffExitMissing   = 3
+)
+
+// frontmatterChange is one differing frontmatter key; Old or New is absent when
+// the key was added or removed.
+type frontmatterChange struct {
+	Key string `json:"key"`
+	Old any    `json:"old,omitempty"`
+	New any    `json:"new,omitempty"`
+}
+
+// diffResult is the --output json schema of the diff command. Status is
+// "identical", "differs", or "missing" (no existing digest to compare with).
+type diffResult struct {
+	Channel     string              `json:"channel"`
+	Slug        string              `json:"slug"`
+	Against     string              `json:"against"` // the file path, or "quaily"
+	Status      string              `json:"status"`
+	BodyDiff    string              `json:"body_diff,omitempty"` // unified diff
+	Frontmatter []frontmatterChange `json:"frontmatter,omitempty"`
+}
+
+// diffCmd regenerates a digest in memory and compares it with the written or published one.
+var diffCmd = &cobra.Command{
+	Use:   "diff <channel>",
+	Short: "Show how a regenerated digest would differ from the existing one",
+	Long: "Regenerate a channel's digest in memory (nothing is written) and compare it with the digest on disk,\n" +
+		"or with the post on Quaily with --remote: a unified diff of the body and the changed frontmatter keys.\n" +
+		"Exit status: 0 identical, 2 differs, 3 no existing digest, 1 error.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		cfg := GetConfig()
+		ch, ok := cfg.FindChannel(args[0])
+		if !ok {
+			return fmt.Errorf("channel not found: %s", args[0])
+		}
+		at := time.Now()
+		if diffDate != "" {
+			d, err := time.Parse("2006-01-02", diffDate)
+			if err != nil {
+				return fmt.Errorf("invalid --date %q (want YYYY-MM-DD): %w", diffDate, err)
+			}
+			at = d
+		}
+		slug := digestSlug(ch.Frequency, at)
+		res := diffResult{Channel: ch.Name, Slug: slug}
+
+		var old markdown.Document
+		found := false
+		if diffRemote {
+			res.Against = "quaily"
+			qc, err := newQuailyClient(cfg, ch.Config.QuailyProfile, 20*time.Second)
+			if err != nil {
+				return err
+			}
+			ctxQ, cancel := context.WithTimeout(ctx, 20*time.Second)
+			post, ok, err := qc.GetPostBySlug(ctxQ, ch.Name, slug)
+			cancel()
+			if err != nil {
+				return err
+			}
+			found, old.Body = ok, post.Content
+		} else {
+			p := filepath.Join(worker.DigestDir(ch.OutputDir, ch.Name, ch.Config.OutputLayout, at), slug+".md")
+			res.Against = p
+			doc, err := markdown.ParseFile(p)
+			switch {
+			case os.IsNotExist(err):
+			case err != nil:
+				return err
+			default:
+				found, old = true, doc
+			}
+		}
+		if !found {
+			res.Status = "missing"
+			if err := emitDiffResult(cmd, res); err != nil {
+				return err
+			}
+			return exitWith(cmd, diffExitMissing)
+		}
+
+		// Regenerate at the compared digest's own time so its datetime matches.
+		opts := generateOptions{At: at, NoAI: diffNoAI, DryRun: true, Formats: []string{newsletter.FormatMarkdown}, Progress: cmd.ErrOrStderr()}
+		if s, _ := old.Frontmatter["datetime"].(string); s != "" {
+			if t, err := time.Parse("2006-01-02 15:04", s); err == nil && digestSlug(ch.Frequency, t) == slug {
+				opts.At = t
+			}
+		}
+		opts.Cover, _ = old.Frontmatter["cover_image_url"].(string)
+		opts.CoverAlt, _ = old.Frontmatter["cover_image_alt"].(string)
+		gen, err := runGenerate(ctx, cmd, ch.Name, opts)
+		if err != nil {
+			return err
+		}
+		if gen.SkippedReason != nil {
+			return fmt.Errorf("regenerated digest was skipped: %s", *gen.SkippedReason)
+		}
+		cur, err := markdown.Parse(bytes.NewReader(gen.Outputs[0].Content))
+		if err != nil {
+			return err
+		}
+		res.BodyDiff = textdiff.Unified(res.Against, "regenerated", old.Body, cur.Body, 3)
+		if !diffRemote {
+			res.Frontmatter = diffFrontmatter(old.Frontmatter, cur.Frontmatter)
+		}
+		code := diffExitIdentical
+		res.Status = "identical"
+		if res.BodyDiff != "" || len(res.Frontmatter) > 0 {
+			code, res.Status = diffExitDiffers, "differs"
+		}
+		if err := emitDiffResult(cmd, res); err != nil {
+			return err
+		}
+		if code != diffExitIdentical {
+			return exitWith(cmd, code)
+		}
+		return nil
+	},
+}
+
+// diffFrontmatter lists the keys whose values differ between a and b, sorted.
+func diffFrontmatter(a, b map[string]any) []frontmatterChange {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	var out []frontmatterChange
+	for k := range keys {
+		if !reflect.DeepEqual(a[k], b[k]) {
+			out = append(out, frontmatterChange{Key: k, Old: a[k], New: b[k]})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func emitDiffResult(cmd *cobra.Command, res diffResult) error {
+	return emit(cmd, res, func(w io.Writer) {
+		switch res.Status {
+		case "missing":
+			fmt.Fprintf(w, "No existing digest %s to compare with (%s).\n", res.Slug, res.Against)
+			return
+		case "identical":
+			fmt.Fprintf(w, "%s is identical to the regenerated digest.\n", res.Against)
+			return
+		}
+		for _, c := range res.Frontmatter {
+			switch {
+			case c.Old == nil:
+				fmt.Fprintf(w, "+ %s: %s\n", c.Key, diffValue(c.New))
+			case c.New == nil:
+				fmt.Fprintf(w, "- %s: %s\n", c.Key, diffValue(c.Old))
+			default:
+				fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Key, diffValue(c.Old), diffValue(c.New))
+			}
+		}
+		if len(res.Frontmatter) > 0 && res.BodyDiff != "" {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, res.BodyDiff)
+	})
+}
+
+// diffValue formats a frontmatter value on one line.
+func diffValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+	return string(b)
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVar(&diffDate, "date", "", "digest date YYYY-MM-DD (default: today, UTC)")
+	diffCmd.Flags().BoolVar(&diffRemote, "remote", false, "compare with the post published on Quaily instead of the file (body only)")
+	diffCmd.Flags().BoolVar(&diffNoAI, "no-ai", false, "regenerate without AI summaries")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/selection"
+
+	"github.com/spf13/cobra"
+)
+
+// excludeResult is the --output json schema of the exclude and exclude remove commands.
+type excludeResult struct {
+	Source  string `json:"source"`
+	Entry   string `json:"entry"`   // "id:<item id>" or "url:<canonical URL>"
+	Changed bool   `json:"changed"` // false when the entry already was (exclude) or was not (remove) listed
+}
+
+// excludeCmd adds an item to a source's permanent exclusion list.
+var excludeCmd = &cobra.Command{
+	Use:   "exclude <source> <item_id_or_url>",
+	Short: "Permanently exclude an item from every channel's digests",
+	Long: "Permanently exclude an item, by ID or URL, from the digests of every channel of the source.\n" +
+		"URLs are matched canonically (no www., fragment, trailing slash, or utm_* parameters).",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExclude(cmd, args, true)
+	},
+}
+
+var excludeRemoveCmd = &cobra.Command{
+	Use:   "remove <source> <item_id_or_url>",
+	Short: "Remove an item from the exclusion list",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExclude(cmd, args, false)
+	},
+}
+
+var excludeListCmd = &cobra.Command{
+	Use:   "list [source]",
+	Short: "List the exclusion entries of one or all sources",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sources := []string{"arxiv", "bluesky", "github", "hackernews", "jsonfeed", "lobsters", "mastodon", "producthunt", "reddit", "rss", "stackoverflow", "v2ex", "youtube"}
+		if len(args) == 1 {
+			source, err := excludeSource(args[0])
+			if err != nil {
+				return err
+			}
+			sources = []string{source}
+		}
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res := map[string][]string{}
+		for _, s := range sources {
+			entries, err := store.Exclusions(ctx, s)
+			if err != nil {
+				return err
+			}
+			res[s] = entries
+		}
+		return emit(cmd, map[string]any{"exclusions": res}, func(w io.Writer) {
+			n := 0
+			for _, s := range sources {
+				for _, e := range res[s] {
+					fmt.Fprintf(w, "%s\t%s\n", s, e)
+					n++
+				}
+			}
+			if n == 0 {
+				fmt.Fprintln(w, "No excluded items.")
+			}
+		})
+	},
+}
+
+func excludeSource(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s != "v2ex" && s != "hackernews" && s != "rss" && s != "lobsters" && s != "reddit" && s != "github" && s != "producthunt" && s != "arxiv" && s != "mastodon" && s != "bluesky" && s != "stackoverflow" && s != "jsonfeed" && s != "youtube" {
+		return "", fmt.Errorf("unknown source %q (want v2ex, hackernews, rss, lobsters, reddit, github, producthunt, arxiv, mastodon, bluesky, stackoverflow, jsonfeed, or youtube)", s)
+	}
+	return s, nil
+}
+
+func runExclude(cmd *cobra.Command, args []string, add bool) error {
+	source, err := excludeSource(args[0])
+	if err != nil {
+		return err
+	}
+	entry := selection.ExclusionEntry(args[1])
+	cfg := GetConfig()
+	rdb := redisclient.New(cfg.Redis)
+	defer rdb.Close()
+	store := newStore(cfg, rdb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var changed bool
+	if add {
+		changed, err = store.AddExclusion(ctx, source, entry)
+	} else {
+		changed, err = store.RemoveExclusion(ctx, source, entry)
+	}
+	if err != nil {
+		return err
+	}
+	res := excludeResult{Source: source, Entry: entry, Changed: changed}
+	return emit(cmd, res, func(w io.Writer) {
+		switch {
+		case add && changed:
+			fmt.Fprintf(w, "Excluded %s from %s digests.\n", entry, source)
+		case add:
+			fmt.Fprintf(w, "%s is already excluded from %s digests.\n", entry, source)
+		case changed:
+			fmt.Fprintf(w, "Removed %s from the %s exclusion list.\n", entry, source)
+		default:
+			fmt.Fprintf(w, "%s was not on the %s exclusion list.\n", entry, source)
+		}
+	})
+}
+
+func init() {
+	excludeCmd.AddCommand(excludeListCmd)
+	excludeCmd.AddCommand(excludeRemoveCmd)
+	rootCmd.AddCommand(excludeCmd)
+}
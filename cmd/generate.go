@@ -4,29 +4,65 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
-	"unicode/utf8"
 
 	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/arxiv"
+	"quaily-journalist/internal/bluesky"
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/fsutil"
+	"quaily-journalist/internal/githubtrending"
+	"quaily-journalist/internal/httpclient"
 	"quaily-journalist/internal/imagegen"
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/markdown"
 	"quaily-journalist/internal/model"
 	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/producthunt"
 	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/reddit"
 	"quaily-journalist/internal/redisclient"
 	"quaily-journalist/internal/scrape"
+	"quaily-journalist/internal/selection"
+	"quaily-journalist/internal/stackoverflow"
 	"quaily-journalist/internal/storage"
 	"quaily-journalist/internal/v2ex"
+	"quaily-journalist/worker"
 
 	"github.com/spf13/cobra"
 )
 
-var genInputFile string
+var (
+	genInputFile string
+	genNoAI      bool
+	genForce     bool
+	genBackup    bool
+	genReason    string
+	genFormats   []string
+	genQuiet     bool
+	genTimeout   time.Duration
+)
+
+// Budgets for the stages of a generate run. Each derives from the run's context,
+// so the overall --timeout deadline and Ctrl-C still cut them short; AI calls get
+// no budget of their own beyond that deadline.
+const (
+	genStorageTimeout = 10 * time.Second
+	genNodeTimeout    = 5 * time.Second
+	genScrapeTimeout  = 20 * time.Second
+	genUploadTimeout  = 30 * time.Second
+)
 
 // generateCmd force-generates a newsletter for a given channel, ignoring skip/published state.
 var generateCmd = &cobra.Command{
@@ -34,515 +70,712 @@ var generateCmd = &cobra.Command{
 	Short: "Force-generate a newsletter for a channel (daily)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		channelName := args[0]
-		cfg := GetConfig()
-
-		// find channel
-		var ch *struct {
-			Name      string
-			Source    string
-			Frequency string
-			TopN      int
-			MinItems  int
-			OutputDir string
-			Nodes     []string
-			Template  struct {
-				Title      string
-				Preface    string
-				Postscript string
-			}
-			Language string
-		}
-		for i := range cfg.Newsletters.Channels {
-			c := cfg.Newsletters.Channels[i]
-			if c.Name == channelName {
-				ch = &struct {
-					Name      string
-					Source    string
-					Frequency string
-					TopN      int
-					MinItems  int
-					OutputDir string
-					Nodes     []string
-					Template  struct {
-						Title      string
-						Preface    string
-						Postscript string
-					}
-					Language string
-				}{
-					Name:      c.Name,
-					Source:    strings.ToLower(c.Source),
-					Frequency: strings.ToLower(c.Frequency),
-					TopN:      c.TopN,
-					MinItems:  c.MinItems,
-					OutputDir: cfg.Newsletters.OutputDir,
-					Nodes:     c.Nodes,
-					Template: struct {
-						Title      string
-						Preface    string
-						Postscript string
-					}{
-						Title:      c.Template.Title,
-						Preface:    c.Template.Preface,
-						Postscript: c.Template.Postscript,
-					},
-					Language: c.Language,
-				}
-				break
-			}
-		}
-		if ch == nil {
-			return fmt.Errorf("channel not found: %s", channelName)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if genTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, genTimeout)
+			defer cancel()
+		}
+		res, err := runGenerate(ctx, cmd, args[0], generateOptions{
+			At:        time.Now(),
+			InputFile: genInputFile,
+			NoAI:      genNoAI,
+			Force:     genForce,
+			Backup:    genBackup,
+			Reason:    genReason,
+			Formats:   genFormats,
+			Progress:  generateProgress(cmd),
+		})
+		if err != nil {
+			return err
 		}
+		return emitGenerateResult(cmd, res)
+	},
+}
 
-		slog.Info("generate: generating newsletter", "channel", ch.Name, "output", ch.OutputDir)
+// generateOptions controls a single generate run.
+type generateOptions struct {
+	// At is the digest date; the period key, file name, and title variables derive from it.
+	At time.Time
+	// InputFile optionally switches to URL-list mode.
+	InputFile string
+	// NoAI disables item/post summaries and cover image generation.
+	NoAI bool
+	// Force overwrites an existing digest file for the date.
+	Force bool
+	// Formats overrides the channel's output formats (see newsletter.ParseFormats).
+	Formats []string
+	// Backup keeps an existing digest file as <name>.md.bak-<timestamp> before
+	// overwriting it; it implies Force.
+	Backup bool
+	// SkipExisting reports an existing digest as skipped ("exists") instead of
+	// failing, unless Force or Backup is set.
+	SkipExisting bool
+	// Reason is noted in the revision added when an existing digest is overwritten.
+	Reason string
+	// Progress receives per-stage progress and timing totals; nil is silent.
+	Progress io.Writer
+	// DryRun renders in memory: the outputs are returned in the result and no
+	// digest, cover, or upload is written. Existing files do not stop it.
+	DryRun bool
+	// Cover and CoverAlt, when Cover is set, are used as the digest's cover instead
+	// of a generated one (diff passes the compared digest's).
+	Cover, CoverAlt string
+}
 
-		// Prepare storage
-		rdb := redisclient.New(cfg.Redis)
-		defer rdb.Close()
-		store := storage.NewRedisStore(rdb)
+// digestSlug is the slug (and file name without extension) of a generated digest.
+func digestSlug(frequency string, at time.Time) string {
+	return fmt.Sprintf("%s-%s", frequency, at.UTC().Format("20060102"))
+}
 
-		// Daily period key (UTC) matches collector storage
-		period := time.Now().UTC().Format("2006-01-02")
-		// fetch more than TopN to allow node filtering
-		fetchN := ch.TopN * 5
-		if fetchN < ch.TopN {
-			fetchN = ch.TopN
-		}
+// runGenerate renders and writes the digest of a channel for opts.At, ignoring
+// skip/published state. When ctx ends first, it returns ctx's error without
+// writing any file, even if later stages could have fallen back to non-AI text.
+func runGenerate(ctx context.Context, cmd *cobra.Command, channelName string, opts generateOptions) (generateResult, error) {
+	cfg := GetConfig()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	ch, ok := cfg.FindChannel(channelName)
+	if !ok {
+		return generateResult{}, fmt.Errorf("channel not found: %s", channelName)
+	}
+	chCfg := ch.Config
+	minRunesForAI := ch.MinContentRunesForAI
 
-		externalList := strings.TrimSpace(genInputFile) != ""
-		// Prefetch node titles at initialization using the node list from config (normal flow only)
-		if !externalList {
-			if strings.ToLower(ch.Source) == "v2ex" {
-				v2c := v2ex.NewClient(cfg.Sources.V2EX.BaseURL, cfg.Sources.V2EX.Token)
-				for _, n := range ch.Nodes {
-					slog.Info("generate: fetching v2ex node title", "node", n)
-					n = strings.TrimSpace(n)
-					if n == "" {
-						slog.Info("generate: v2ex node title fetch skipped for empty node")
-						continue
-					}
-					t, err := store.GetNodeTitle(context.Background(), "v2ex", n)
-					if err != nil {
-						slog.Warn("generate: v2ex node title fetch from cache failed", "node", n, "err", err)
-						continue
-					}
-					if strings.TrimSpace(t) == "" {
-						ctxNode, cancelNode := context.WithTimeout(context.Background(), 5*time.Second)
-						title, err := v2c.NodeTitle(ctxNode, n)
-						if err != nil {
-							slog.Warn("generate: v2ex node title fetch failed", "node", n, "err", err)
-							cancelNode()
-							continue
-						}
-						slog.Info("generate: v2ex node title fetched", "node", n, "title", title)
-						if err == nil && strings.TrimSpace(title) != "" {
-							_ = store.SetNodeTitle(context.Background(), "v2ex", n, title, 30*24*time.Hour)
-						}
-						cancelNode()
-					} else {
-						slog.Info("generate: v2ex node title found in cache", "node", n, "title", t)
-					}
-				}
-			}
+	slog.Info("generate: generating newsletter", "channel", ch.Name, "output", ch.OutputDir)
+	prog := newProgressReporter(opts.Progress)
+
+	// Prepare storage
+	rdb := redisclient.New(cfg.Redis)
+	defer rdb.Close()
+	store := newStore(cfg, rdb)
+
+	// Daily period key (UTC) matches collector storage
+	day := period.Key(period.Daily, opts.At)
+
+	// Slug: frequency-YYYYMMDD; one file per format (.md, .html, .json)
+	// output path: :output_dir/:channel_name/[:layout/]:slug.:ext
+	formats := chCfg.Formats
+	if len(opts.Formats) > 0 {
+		formats = opts.Formats
+	}
+	formats, err := newsletter.ParseFormats(formats)
+	if err != nil {
+		return generateResult{}, err
+	}
+	slug := digestSlug(ch.Frequency, opts.At)
+	if err := worker.CheckOutputLayout(chCfg.OutputLayout); err != nil {
+		return generateResult{}, fmt.Errorf("channel %s: %w", ch.Name, err)
+	}
+	if err := newsletter.CheckStyle(chCfg.Style); err != nil {
+		return generateResult{}, fmt.Errorf("channel %s: %w", ch.Name, err)
+	}
+	sel := selection.FromChannel(ch)
+	if err := sel.Check(); err != nil {
+		return generateResult{}, err
+	}
+	// A minimal digest lists links only, so it is built as with --no-ai.
+	minimal := chCfg.Style == newsletter.StyleMinimal
+	if minimal {
+		opts.NoAI = true
+	}
+	dir := worker.DigestDir(ch.OutputDir, ch.Name, chCfg.OutputLayout, opts.At)
+	// Refuse to clobber a digest that may carry manual edits before spending any AI calls.
+	var existing []string
+	for _, f := range formats {
+		p := filepath.Join(dir, slug+newsletter.FormatExt(f))
+		if _, err := os.Stat(p); err == nil {
+			existing = append(existing, p)
+		} else if !os.IsNotExist(err) {
+			return generateResult{}, err
+		}
+	}
+	if len(existing) > 0 && !opts.DryRun {
+		ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+		note := publishStatusNote(ctxStore, store, ch.Name, period.Key(ch.Frequency, opts.At))
+		cancelStore()
+		if !opts.Force && !opts.Backup && opts.SkipExisting {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Keeping %s (%s)\n", strings.Join(existing, ", "), note)
+			return generateResult{Path: existing[0], SkippedReason: strPtr("exists")}, nil
+		}
+		if !opts.Force && !opts.Backup {
+			return generateResult{}, fmt.Errorf("%s already exists (%s); pass --force to overwrite it or --backup to keep a copy", strings.Join(existing, ", "), note)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Overwriting %s (%s)\n", strings.Join(existing, ", "), note)
+	}
+	// A regenerated digest keeps the revisions of the Markdown file it replaces and,
+	// unless nothing is written, records itself as the next one.
+	var revisions []newsletter.Revision
+	if doc, err := markdown.ParseFile(filepath.Join(dir, slug+newsletter.FormatExt(newsletter.FormatMarkdown))); err == nil {
+		revisions = newsletter.ParseRevisions(doc.Frontmatter)
+	} else if !os.IsNotExist(err) {
+		slog.Warn("generate: read revisions of the existing digest failed", "err", err, "channel", ch.Name, "slug", slug)
+	}
+	if len(existing) > 0 && !opts.DryRun {
+		revisions = append(revisions, newsletter.NewRevision(time.Now(), opts.Reason))
+	}
+
+	prog.Stage("fetching items")
+	// With --mock-sources, load the channel's fixtures first so the digest renders
+	// from them without a running collector.
+	if mockSourcesDir != "" && strings.TrimSpace(opts.InputFile) == "" {
+		one := cfg
+		one.Newsletters.Channels = []config.ChannelConfig{chCfg}
+		if _, err := collectOnce(ctx, one, store); err != nil {
+			return generateResult{}, err
 		}
+	}
 
-		var items []model.WithScore
-		if externalList {
-			// URL-list mode: scrape via Cloudflare Browser Rendering, keep order
-			if strings.TrimSpace(cfg.Cloudflare.AccountID) == "" || strings.TrimSpace(cfg.Cloudflare.APIToken) == "" {
-				return fmt.Errorf("cloudflare config missing: set cloudflare.account_id and cloudflare.api_token in config.yaml")
-			}
-			cfc := scrape.NewCloudflare(cfg.Cloudflare.AccountID, cfg.Cloudflare.APIToken, 20*time.Second)
-			f, err := os.Open(genInputFile)
+	// Setup summarizer
+	var summarizer ai.Summarizer
+	if cfg.OpenAI.APIKey != "" && !opts.NoAI {
+		summarizer = newSummarizer(cfg, store)
+	}
+
+	externalList := strings.TrimSpace(opts.InputFile) != ""
+	from := strings.TrimSpace(chCfg.DeriveFrom)
+	derived := from != "" && !externalList
+	// Prefetch node titles at initialization using the node list from config (normal flow only)
+	if !externalList {
+		if strings.ToLower(ch.Source) == "v2ex" {
+			v2c, err := newV2EXSource(cfg)
 			if err != nil {
-				return fmt.Errorf("open input file: %w", err)
+				return generateResult{}, err
 			}
-			defer f.Close()
-			scanner := bufio.NewScanner(f)
-			buf := make([]byte, 0, 1024*64)
-			scanner.Buffer(buf, 1024*1024)
-			lineNo := 0
-			for scanner.Scan() {
-				raw := strings.TrimSpace(scanner.Text())
-				lineNo++
-				if raw == "" || strings.HasPrefix(raw, "#") {
+			for _, n := range ch.Nodes {
+				slog.Debug("generate: fetching v2ex node title", "node", n)
+				n = strings.TrimSpace(n)
+				if n == "" {
+					slog.Debug("generate: v2ex node title fetch skipped for empty node")
 					continue
 				}
-				ctxReq, cancelReq := context.WithTimeout(context.Background(), 20*time.Second)
-				title, content, err := cfc.Scrape(ctxReq, raw)
-				slog.Info("generate: scraped URL", "line", lineNo, "url", raw, "title", title)
-				cancelReq()
-				if err != nil {
-					// continue but warn
-					fmt.Fprintf(cmd.ErrOrStderr(), "generate: scrape failed line %d: %v\n", lineNo, err)
-				}
-				if strings.TrimSpace(title) == "" {
-					title = raw
+				if _, isQuery := v2ex.SearchQuery(n); isQuery {
+					continue
 				}
-				host := "link"
-				if u, err := url.Parse(raw); err == nil && u.Host != "" {
-					host = u.Host
+				ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+				t, err := store.GetNodeTitle(ctxStore, "v2ex", n)
+				cancelStore()
+				if err != nil {
+					slog.Warn("generate: v2ex node title fetch from cache failed", "node", n, "err", err)
+					continue
 				}
-				items = append(items, model.WithScore{Item: model.NewsItem{
-					ID:        raw,
-					Title:     title,
-					URL:       raw,
-					NodeName:  host,
-					Replies:   0,
-					Points:    0,
-					CreatedAt: time.Now().UTC(),
-					Content:   content,
-				}, Score: 0})
-			}
-			if err := scanner.Err(); err != nil {
-				return fmt.Errorf("read input file: %w", err)
-			}
-		} else {
-			var err error
-			items, err = store.TopNews(ctx, ch.Source, period, fetchN)
-			if err != nil {
-				return err
-			}
-		}
-		// For Hacker News, nodes list are lists to poll; only filter by nodes
-		// if they include HN item types (ask/show/job/story). Otherwise, skip filtering.
-		if !externalList {
-			if ch.Source == "hackernews" {
-				items = filterHNTypesLocal(items, ch.Nodes)
-			} else {
-				items = filterByNodesLocal(items, ch.Nodes)
-			}
-			// ensure low-signal items are excluded (source-specific)
-			nz := make([]model.WithScore, 0, len(items))
-			for _, ws := range items {
-				if ch.Source == "hackernews" {
-					if ws.Score > 0 {
-						nz = append(nz, ws)
+				if strings.TrimSpace(t) == "" {
+					ctxNode, cancelNode := context.WithTimeout(ctx, genNodeTimeout)
+					title, err := v2c.NodeTitle(ctxNode, n)
+					if err != nil {
+						slog.Warn("generate: v2ex node title fetch failed", "node", n, "err", err)
+						cancelNode()
+						continue
 					}
-				} else {
-					if ws.Item.Replies > 0 && ws.Score > 0 {
-						nz = append(nz, ws)
+					slog.Info("generate: v2ex node title fetched", "node", n, "title", title)
+					if err == nil && strings.TrimSpace(title) != "" {
+						_ = store.SetNodeTitle(ctxNode, "v2ex", n, title, 30*24*time.Hour)
 					}
+					cancelNode()
+				} else {
+					slog.Debug("generate: v2ex node title found in cache", "node", n, "title", t)
 				}
 			}
-			items = nz
-		}
-		if len(items) == 0 {
-			fmt.Fprintln(cmd.OutOrStdout(), "No items found for channel; skipping file creation.")
-			return nil
-		}
-		if len(items) < ch.MinItems {
-			fmt.Fprintf(cmd.OutOrStdout(), "Only %d items (< min_items=%d); skipping file creation.\n", len(items), ch.MinItems)
-			return nil
-		}
-		if len(items) > ch.TopN {
-			items = items[:ch.TopN]
 		}
+	}
 
-		// Prepare template data
-		// Determine post title: use configured template or default to "Digest of <Channel> <YYYY-MM-DD>"
-		now := time.Now()
-		postTitle := strings.TrimSpace(ch.Template.Title)
-		if postTitle == "" {
-			postTitle = fmt.Sprintf("Digest of %s %s", ch.Name, period)
-		}
-		// Expand template variables in configured title/preface/postscript
-		postTitle = newsletter.ExpandVars(postTitle, now)
-		// Filename and slug: frequency-YYYYMMDD.md
-		dateName := time.Now().UTC().Format("20060102")
-		fileName := fmt.Sprintf("%s-%s.md", ch.Frequency, dateName)
-		slug := strings.TrimSuffix(fileName, ".md")
-		var baseURL string
-		if ch.Source == "v2ex" {
-			baseURL = cfg.Sources.V2EX.BaseURL
-		} else if ch.Source == "hackernews" {
-			baseURL = "https://news.ycombinator.com"
-		} else {
-			baseURL = ""
-		}
-		nd := newsletter.Data{
-			Title:      postTitle,
-			Slug:       slug,
-			Datetime:   time.Now().UTC().Format("2006-01-02 15:04"),
-			Preface:    newsletter.ExpandVars(ch.Template.Preface, now),
-			Postscript: newsletter.ExpandVars(ch.Template.Postscript, now),
-			Items:      make([]newsletter.Item, 0, len(items)),
-		}
-		// Setup summarizer
-		var summarizer ai.Summarizer
-		if cfg.OpenAI.APIKey != "" {
-			summarizer = ai.NewOpenAI(ai.Config{APIKey: cfg.OpenAI.APIKey, Model: cfg.OpenAI.Model, BaseURL: cfg.OpenAI.BaseURL})
-		}
-		// Optional Cloudflare client for content fallback during summarization
-		var cfc *scrape.CloudflareClient
-		if strings.TrimSpace(cfg.Cloudflare.AccountID) != "" && strings.TrimSpace(cfg.Cloudflare.APIToken) != "" {
-			cfc = scrape.NewCloudflare(cfg.Cloudflare.AccountID, cfg.Cloudflare.APIToken, 20*time.Second)
-		}
-		var coverGen imagegen.Generator
-		if strings.TrimSpace(cfg.Susanoo.BaseURL) != "" && strings.TrimSpace(cfg.Susanoo.APIKey) != "" {
-			timeout := 30 * time.Second
-			if strings.TrimSpace(cfg.Susanoo.Timeout) != "" {
-				if d, err := time.ParseDuration(cfg.Susanoo.Timeout); err != nil {
-					return fmt.Errorf("invalid susanoo.timeout: %w", err)
-				} else {
-					timeout = d
-				}
+	// The permanent exclusion list applies to stored and URL-list items alike.
+	ctxEx, cancelEx := context.WithTimeout(ctx, genStorageTimeout)
+	excluded, err := selection.LoadExclusions(ctxEx, store, strings.ToLower(ch.Source))
+	cancelEx()
+	if err != nil {
+		return generateResult{}, fmt.Errorf("load exclusions: %w", err)
+	}
+
+	var items []model.WithScore
+	var removed selection.Report // items each selection stage dropped
+	if externalList {
+		// URL-list mode: scrape via Cloudflare Browser Rendering, keep order
+		if strings.TrimSpace(cfg.Cloudflare.AccountID) == "" || strings.TrimSpace(cfg.Cloudflare.APIToken) == "" {
+			return generateResult{}, fmt.Errorf("cloudflare config missing: set cloudflare.account_id and cloudflare.api_token in config.yaml")
+		}
+		cfc, err := newCloudflareClient(cfg)
+		if err != nil {
+			return generateResult{}, err
+		}
+		f, err := os.Open(opts.InputFile)
+		if err != nil {
+			return generateResult{}, fmt.Errorf("open input file: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		buf := make([]byte, 0, 1024*64)
+		scanner.Buffer(buf, 1024*1024)
+		lineNo := 0
+		for scanner.Scan() {
+			raw := strings.TrimSpace(scanner.Text())
+			lineNo++
+			if raw == "" || strings.HasPrefix(raw, "#") {
+				continue
+			}
+			ctxReq, cancelReq := context.WithTimeout(ctx, genScrapeTimeout)
+			title, content, err := cfc.Scrape(ctxReq, raw)
+			slog.Info("generate: scraped URL", "line", lineNo, "url", raw, "title", title)
+			cancelReq()
+			if ctx.Err() != nil {
+				return generateResult{}, ctx.Err()
 			}
-			gen, err := imagegen.NewSusanoo(imagegen.SusanooConfig{
-				BaseURL:     cfg.Susanoo.BaseURL,
-				APIKey:      cfg.Susanoo.APIKey,
-				Model:       cfg.Susanoo.Model,
-				AspectRatio: cfg.Susanoo.AspectRatio,
-				Timeout:     timeout,
-				WebPQuality: cfg.Susanoo.WebPQuality,
-			})
 			if err != nil {
-				return err
+				// continue but warn
+				fmt.Fprintf(cmd.ErrOrStderr(), "generate: scrape failed line %d: %v\n", lineNo, err)
 			}
-			coverGen = gen
-		}
-		var qcli *quaily.Client
-		if strings.TrimSpace(cfg.Quaily.BaseURL) != "" && strings.TrimSpace(cfg.Quaily.APIKey) != "" {
-			qcli = quaily.New(cfg.Quaily.BaseURL, cfg.Quaily.APIKey, 20*time.Second)
-		}
-		// Use base context; AI client enforces per-call timeouts
-		ctxAI := context.Background()
-		// Resolve node titles for display (best-effort) from Redis cache (skip in external mode)
-		titleByNode := map[string]string{}
-		if !externalList {
-			set := map[string]struct{}{}
-			for _, ws := range items {
-				set[ws.Item.NodeName] = struct{}{}
+			if strings.TrimSpace(title) == "" {
+				title = raw
 			}
-			for n := range set {
-				if t, err := store.GetNodeTitle(context.Background(), ch.Source, n); err == nil && strings.TrimSpace(t) != "" {
-					titleByNode[n] = t
-				}
+			host := "link"
+			if u, err := url.Parse(raw); err == nil && u.Host != "" {
+				host = u.Host
 			}
+			items = append(items, model.WithScore{Item: model.NewsItem{
+				ID:        raw,
+				Title:     title,
+				URL:       raw,
+				NodeName:  host,
+				Replies:   0,
+				Points:    0,
+				CreatedAt: opts.At.UTC(),
+				Content:   content,
+			}, Score: 0})
+		}
+		if err := scanner.Err(); err != nil {
+			return generateResult{}, fmt.Errorf("read input file: %w", err)
+		}
+	} else if derived {
+		// The week's published daily items, by stored score; see worker.DerivedItems.
+		ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+		defer cancelStore()
+		var err error
+		items, err = worker.DerivedItems(ctxStore, store, ch.Source, from, period.Key(period.Weekly, opts.At))
+		if err != nil {
+			return generateResult{}, err
 		}
-		for _, ws := range items {
-			it := ws.Item
-			var nodeURL string
-			if externalList {
-				// use scheme://host as category link for external URLs
-				if u, err := url.Parse(it.URL); err == nil && u.Host != "" {
-					if u.Scheme != "" {
-						nodeURL = u.Scheme + "://" + u.Host
-					} else {
-						nodeURL = "https://" + u.Host
-					}
-				}
-				if strings.TrimSpace(nodeURL) == "" {
-					nodeURL = it.URL
-				}
+	} else {
+		ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+		defer cancelStore()
+		var err error
+		sel.Scorer, err = channelScorer(cfg, chCfg)
+		if err != nil {
+			return generateResult{}, err
+		}
+		// Read as deep as the builder would; skip marks are ignored, as generate
+		// regenerates published periods.
+		perBatch := append(append(sel.Ranking(store, time.Now()), excluded.Stage()), sel.Filters()...)
+		gate := newQualityGate(chCfg, summarizer, store).Stage(ch.TopN)
+		f, err := sel.Fetch(ctxStore, store, day, perBatch, selection.Pipeline{sel.Dedup(), gate})
+		if err != nil {
+			return generateResult{}, err
+		}
+		items, removed = f.Items, f.Removed
+		slog.Debug("generate: fetched candidates", "channel", ch.Name, "depth", f.Depth)
+	}
+	var filters selection.Pipeline
+	if externalList || derived {
+		filters = selection.Pipeline{excluded.Stage()}
+	}
+	if derived {
+		// Derived items passed the daily channel's filters already; stored ones
+		// passed the quality gate while they were fetched.
+		filters = append(filters, sel.Dedup(), newQualityGate(chCfg, summarizer, store).Stage(ch.TopN))
+		prog.Stage("filtering items")
+	}
+	items, r := filters.Run(ctx, items)
+	removed = removed.Add(r)
+	slog.Info("generate: selected items", "channel", ch.Name, "items", len(items), "removed", removed)
+	if len(items) == 0 {
+		return generateResult{SkippedReason: strPtr("no_items")}, nil
+	}
+	if len(items) < ch.MinItems {
+		return generateResult{Items: len(items), MinItems: ch.MinItems, SkippedReason: strPtr("below_min_items")}, nil
+	}
+	if externalList {
+		items = items[:min(len(items), ch.TopN)]
+	} else {
+		items = sel.Select(items, 0)
+	}
+
+	// Prepare template data
+	// Determine post title: use configured template or default to "Digest of <Channel> <YYYY-MM-DD>"
+	now := opts.At
+	postTitle := strings.TrimSpace(ch.Template.Title)
+	if postTitle == "" {
+		postTitle = fmt.Sprintf("Digest of %s %s", ch.Name, day)
+	}
+	// Expand template variables in configured title/preface/postscript
+	postTitle = newsletter.ExpandVars(postTitle, now)
+	baseURL := sourceBaseURL(cfg, ch.Source)
+	nd := newsletter.Data{
+		Title:      postTitle,
+		Slug:       slug,
+		Datetime:   now.UTC().Format("2006-01-02 15:04"),
+		Preface:    newsletter.ExpandVars(ch.Template.Preface, now),
+		Postscript: newsletter.ExpandVars(ch.Template.Postscript, now),
+		Style:      chCfg.Style,
+		Frequency:  ch.Frequency,
+		Revisions:  revisions,
+	}
+	if chCfg.Navigation && strings.TrimSpace(cfg.Quaily.SiteBaseURL) == "" {
+		slog.Warn("generate: navigation needs quaily.site_base_url; links skipped", "channel", ch.Name)
+	} else if chCfg.Navigation {
+		ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+		err := worker.Navigate(ctxStore, store, ch.Name, period.Key(ch.Frequency, opts.At), cfg.Quaily.SiteBaseURL, &nd)
+		cancelStore()
+		if err != nil {
+			slog.Warn("generate: navigation links skipped", "err", err, "channel", ch.Name)
+		}
+	}
+	// Optional Cloudflare client for content fallback during summarization
+	var cfc *scrape.CloudflareClient
+	if strings.TrimSpace(cfg.Cloudflare.AccountID) != "" && strings.TrimSpace(cfg.Cloudflare.APIToken) != "" && !minimal {
+		c, err := newCloudflareClient(cfg)
+		if err != nil {
+			return generateResult{}, err
+		}
+		cfc = c
+	}
+	var coverGen imagegen.Generator
+	if strings.TrimSpace(cfg.Susanoo.BaseURL) != "" && strings.TrimSpace(cfg.Susanoo.APIKey) != "" && !opts.NoAI {
+		timeout := 30 * time.Second
+		if strings.TrimSpace(cfg.Susanoo.Timeout) != "" {
+			if d, err := time.ParseDuration(cfg.Susanoo.Timeout); err != nil {
+				return generateResult{}, fmt.Errorf("invalid susanoo.timeout: %w", err)
 			} else {
-				nodeURL = nodeURLForLocal(ch.Source, baseURL, it.NodeName)
-			}
-			var desc string
-			contentForSum := it.Content
-			// If content is empty and Cloudflare client is available, scrape the URL to populate content
-			if strings.TrimSpace(contentForSum) == "" && cfc != nil {
-				ctxReq, cancelReq := context.WithTimeout(context.Background(), 20*time.Second)
-				_, scraped, err := cfc.Scrape(ctxReq, it.URL)
-				cancelReq()
-				if err == nil && strings.TrimSpace(scraped) != "" {
-					contentForSum = scraped
-				}
-			}
-			if summarizer != nil {
-				if d, err := summarizer.SummarizeItem(ctxAI, it.Title, contentForSum, ch.Language); err == nil && d != "" {
-					desc = d
-				} else if err != nil {
-					slog.Warn("generate: summarize item failed", "err", err, "channel", ch.Name, "title", it.Title, "url", it.URL)
-				}
+				timeout = d
 			}
-			displayNode := it.NodeName
-			if !externalList {
-				if t, ok := titleByNode[it.NodeName]; ok && strings.TrimSpace(t) != "" {
-					displayNode = t
-				}
-			}
-			nd.Items = append(nd.Items, newsletter.Item{
-				Title:       it.Title,
-				URL:         it.URL,
-				NodeName:    displayNode,
-				NodeURL:     nodeURL,
-				Description: desc,
-				Replies:     it.Replies,
-				Created:     it.CreatedAt.UTC().Format("2006-01-02 15:04"),
-			})
-		}
-		// Post-level summary: prefer AI, fallback to heuristic to ensure non-empty
-		raw := make([]model.NewsItem, 0, len(items))
+		}
+		hc, err := httpclient.New(cfg, httpclient.Susanoo, timeout)
+		if err != nil {
+			return generateResult{}, err
+		}
+		gen, err := imagegen.NewSusanoo(imagegen.SusanooConfig{
+			BaseURL:     cfg.Susanoo.BaseURL,
+			APIKey:      cfg.Susanoo.APIKey,
+			Model:       cfg.Susanoo.Model,
+			AspectRatio: cfg.Susanoo.AspectRatio,
+			Timeout:     timeout,
+			WebPQuality: cfg.Susanoo.WebPQuality,
+			HTTPClient:  hc,
+		})
+		if err != nil {
+			return generateResult{}, err
+		}
+		coverGen = gen
+	}
+	var qcli *quaily.Client
+	if p, ok := cfg.Quaily.Profile(chCfg.QuailyProfile); !ok {
+		return generateResult{}, fmt.Errorf("channel %s: quaily_profile %q is not defined under quaily.profiles", ch.Name, chCfg.QuailyProfile)
+	} else if p.Configured() {
+		c, err := newQuailyClient(cfg, chCfg.QuailyProfile, 20*time.Second)
+		if err != nil {
+			return generateResult{}, err
+		}
+		qcli = c
+	}
+	// AI calls run under the run's context; the AI client also enforces per-call timeouts.
+	ctxAI := ctx
+	// Resolve node titles for display (best-effort) from Redis cache (skip in external mode)
+	titleByNode := map[string]string{}
+	if !externalList {
+		set := map[string]struct{}{}
 		for _, ws := range items {
-			raw = append(raw, ws.Item)
+			set[ws.Item.NodeName] = struct{}{}
 		}
-		if summarizer != nil {
-			if s, err := summarizer.SummarizePost(ctxAI, raw, ch.Language); err == nil {
-				nd.Summary = strings.TrimSpace(s)
-			} else if err != nil {
-				slog.Warn("generate: summarize post failed", "err", err, "channel", ch.Name)
-			}
-			if s, err := summarizer.SummarizePostLikeAZenMaster(ctxAI, raw, ch.Language); err == nil {
-				nd.ShortSummary = strings.TrimSpace(s)
-			} else if err != nil {
-				slog.Warn("generate: summarize short post failed", "err", err, "channel", ch.Name)
+		ctxStore, cancelStore := context.WithTimeout(ctx, genStorageTimeout)
+		for n := range set {
+			if t, err := store.GetNodeTitle(ctxStore, ch.Source, n); err == nil && strings.TrimSpace(t) != "" {
+				titleByNode[n] = t
 			}
 		}
-		coverRel := path.Join(slug, "cover.webp")
-		coverPath := filepath.Join(ch.OutputDir, ch.Name, slug, "cover.webp")
-		coverURL := ""
-		if _, err := os.Stat(coverPath); err == nil {
-			coverURL = coverRel
-			slog.Info("generate: using existing cover image", "channel", ch.Name, "slug", slug, "path", coverPath)
-		} else if coverGen != nil {
-			slog.Info("generate: generating cover image", "channel", ch.Name, "slug", slug, "path", coverPath)
-			highlights := make([]string, 0, min(5, len(nd.Items)))
-			for i := 0; i < min(5, len(nd.Items)); i++ {
-				highlights = append(highlights, nd.Items[i].Title)
-			}
-			promptSummary := strings.TrimSpace(nd.ShortSummary)
-			if promptSummary == "" {
-				promptSummary = strings.TrimSpace(nd.Summary)
-			}
-			prompt := imagegen.BuildCoverPrompt(imagegen.PromptData{
-				Title:       nd.Title,
-				Summary:     promptSummary,
-				Highlights:  highlights,
-				Language:    ch.Language,
-				AspectRatio: cfg.Susanoo.AspectRatio,
-			}, cfg.Susanoo.PromptTemplate)
-			if err := coverGen.GenerateCover(ctxAI, prompt, coverPath); err != nil {
-				slog.Warn("generate: cover image generation failed", "err", err)
-			} else {
-				coverURL = coverRel
-				slog.Info("generate: cover image generated", "channel", ch.Name, "slug", slug, "path", coverPath)
-			}
-		} else {
-			slog.Info("generate: cover image generation skipped (no generator configured)", "channel", ch.Name, "slug", slug)
+		cancelStore()
+	}
+	var highlights map[string]model.CommentHighlight
+	if chCfg.IncludeTopComment && ch.Source == "hackernews" && !externalList && !minimal {
+		hn, err := newHNSource(cfg)
+		if err != nil {
+			return generateResult{}, err
 		}
-		if qcli != nil && coverURL != "" {
-			ctxUp, cancelUp := context.WithTimeout(ctxAI, 30*time.Second)
-			viewURL, err := qcli.UploadAttachment(ctxUp, coverPath, false)
-			cancelUp()
-			if err != nil {
-				slog.Warn("generate: cover upload failed", "err", err)
-			} else if strings.TrimSpace(viewURL) != "" {
-				coverURL = viewURL
-			}
+		raw := make([]model.NewsItem, len(items))
+		for i, ws := range items {
+			raw[i] = ws.Item
 		}
-		if coverURL != "" {
-			nd.CoverImageURL = coverURL
+		prog.Stage("fetching top comments")
+		highlights = worker.TopComments(ctxAI, store, hn, summarizer, raw, ch.Language)
+	}
+	prog.Stage("summarizing items")
+	var skippedAI int
+	nd.Items, skippedAI = itemDescriber{
+		Summarizer:    summarizer,
+		Store:         store,
+		Scraper:       cfc,
+		External:      externalList,
+		Source:        ch.Source,
+		BaseURL:       baseURL,
+		Channel:       ch.Name,
+		Language:      ch.Language,
+		MinRunesForAI: minRunesForAI,
+		ShowAuthor:    chCfg.ShowAuthor,
+		WhyItMatters:  chCfg.WhyItMatters,
+		NodeTitles:    titleByNode,
+		Highlights:    highlights,
+	}.Describe(ctxAI, items, prog.Item)
+	nd.ReadingMinutes = newsletter.TotalReadingMinutes(nd.Items)
+	if skippedAI > 0 {
+		slog.Info("generate: skipped AI item summaries for thin content", "channel", ch.Name, "count", skippedAI, "min_runes", minRunesForAI)
+	}
+	// Post-level summary: prefer AI, fallback to heuristic to ensure non-empty
+	raw := make([]model.NewsItem, 0, len(items))
+	for _, ws := range items {
+		raw = append(raw, ws.Item)
+	}
+	prog.Stage("post summary")
+	if summarizer != nil && chCfg.Summary.PostEnabled() {
+		if s, err := summarizer.SummarizePost(ctxAI, raw, ch.Language); err == nil {
+			nd.Summary = strings.TrimSpace(s)
+		} else if err != nil {
+			slog.Warn("generate: summarize post failed", "err", err, "channel", ch.Name)
 		}
-
-		content, err := newsletter.Render(nd)
-		if err != nil {
-			return err
+	}
+	if summarizer != nil && chCfg.Summary.ShortEnabled() {
+		if s, err := summarizer.SummarizePostLikeAZenMaster(ctxAI, raw, ch.Language); err == nil {
+			nd.ShortSummary = strings.TrimSpace(s)
+		} else if err != nil {
+			slog.Warn("generate: summarize short post failed", "err", err, "channel", ch.Name)
 		}
-		if !utf8.ValidString(content) {
-			content = string([]rune(content))
+	}
+	if chCfg.PullQuote && summarizer != nil {
+		if q, err := summarizer.ExtractQuote(ctxAI, raw, ch.Language); err != nil {
+			slog.Warn("generate: extract quote failed", "err", err, "channel", ch.Name)
+		} else {
+			nd.Quote, nd.QuoteSource = q.Text, newsletter.QuoteSource{Title: q.Item.Title, URL: q.Item.URL}
 		}
-		// output path: :output_dir/:channel_name/:frequency-YYYYMMDD.md (overwrite)
-		dir := filepath.Join(ch.OutputDir, ch.Name)
-		slog.Info("generate: generating newsletter", "channel", ch.Name, "file", filepath.Join(dir, fileName))
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return err
+	}
+	if chCfg.Frontmatter.SEO {
+		if meta, ok := worker.DigestSEO(ctxAI, store, summarizer, ch.Name, period.Key(ch.Frequency, opts.At), nd.Title, nd.Summary, raw, ch.Language); ok {
+			nd.SEODescription, nd.Keywords = meta.Description, meta.Keywords
 		}
-		outPath := filepath.Join(dir, fileName)
-		if err := os.WriteFile(outPath, []byte(content), 0o644); err != nil {
-			return err
+	}
+	// Fallback summaries built from titles if AI is not configured or returned empty
+	if nd.Summary == "" && !minimal && chCfg.Summary.PostEnabled() {
+		nd.Summary = newsletter.FallbackSummary(nd.Items)
+	}
+	if nd.ShortSummary == "" && chCfg.Summary.ShortEnabled() {
+		nd.ShortSummary = newsletter.FallbackSummary(nd.Items)
+	}
+	prog.Stage("cover image")
+	coverRel := path.Join(slug, "cover.webp")
+	coverPath := filepath.Join(dir, slug, "cover.webp")
+	coverURL := ""
+	coverPrompt := "" // set when the cover is generated in this run
+	if opts.Cover != "" {
+		coverURL = opts.Cover
+	} else if _, err := os.Stat(coverPath); err == nil {
+		coverURL = coverRel
+		slog.Info("generate: using existing cover image", "channel", ch.Name, "slug", slug, "path", coverPath)
+	} else if coverGen != nil && !opts.DryRun {
+		slog.Info("generate: generating cover image", "channel", ch.Name, "slug", slug, "path", coverPath)
+		highlights := make([]string, 0, min(5, len(nd.Items)))
+		for i := 0; i < min(5, len(nd.Items)); i++ {
+			highlights = append(highlights, nd.Items[i].Title)
+		}
+		promptSummary := strings.TrimSpace(nd.ShortSummary)
+		if promptSummary == "" {
+			promptSummary = strings.TrimSpace(nd.Summary)
+		}
+		prompt := imagegen.BuildCoverPrompt(imagegen.PromptData{
+			Title:       nd.Title,
+			Summary:     promptSummary,
+			Highlights:  highlights,
+			Language:    ch.Language,
+			AspectRatio: cfg.Susanoo.AspectRatio,
+		}, cfg.Susanoo.PromptTemplate)
+		if err := coverGen.GenerateCover(ctxAI, prompt, coverPath); err != nil {
+			slog.Warn("generate: cover image generation failed", "err", err)
+		} else {
+			coverURL = coverRel
+			coverPrompt = prompt
+			slog.Info("generate: cover image generated", "channel", ch.Name, "slug", slug, "path", coverPath)
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Generated: %s\n", outPath)
-		return nil
-	},
-}
-
-func init() {
-	rootCmd.AddCommand(generateCmd)
-	generateCmd.Flags().StringVarP(&genInputFile, "input-file", "i", "", "optional path to a text file of URLs to include (one per line)")
-}
-
-// Local helpers (ignore skip/published)
+	} else {
+		slog.Info("generate: cover image generation skipped (no generator configured)", "channel", ch.Name, "slug", slug)
+	}
+	if qcli != nil && coverURL == coverRel && !opts.DryRun {
+		ctxUp, cancelUp := context.WithTimeout(ctx, genUploadTimeout)
+		viewURL, err := qcli.UploadAttachment(ctxUp, coverPath, false)
+		cancelUp()
+		if err != nil {
+			slog.Warn("generate: cover upload failed", "err", err)
+		} else if strings.TrimSpace(viewURL) != "" {
+			coverURL = viewURL
+		}
+	}
+	switch {
+	case opts.Cover != "":
+		nd.CoverImageURL, nd.CoverImageAlt = opts.Cover, opts.CoverAlt
+	case coverURL != "":
+		nd.CoverImageURL = coverURL
+		nd.CoverImageAlt = worker.CoverAlt(ctxAI, summarizer, coverPath, nd.Title, coverPrompt, ch.Language)
+	}
 
-// filterByNodesLocal filters by node names (case-insensitive).
-func filterByNodesLocal(items []model.WithScore, nodes []string) []model.WithScore {
-	if len(nodes) == 0 {
-		return items
+	// AI failures fall back to plain text, so a run past its deadline would
+	// otherwise write a degraded digest; abort before touching any file instead.
+	if err := ctx.Err(); err != nil {
+		return generateResult{}, fmt.Errorf("generate aborted: %w", err)
 	}
-	set := map[string]struct{}{}
-	for _, n := range nodes {
-		set[strings.ToLower(strings.TrimSpace(n))] = struct{}{}
+	prog.Stage("rendering")
+	outputs, err := newsletter.RenderAll(nd, formats)
+	if err != nil {
+		return generateResult{}, err
 	}
-	out := make([]model.WithScore, 0, len(items))
-	for _, ws := range items {
-		if _, ok := set[strings.ToLower(ws.Item.NodeName)]; ok {
-			out = append(out, ws)
+	if opts.DryRun {
+		prog.Finish()
+		return generateResult{Items: len(nd.Items), Outputs: outputs}, nil
+	}
+	prog.Stage("writing")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return generateResult{}, err
+	}
+	if opts.Backup {
+		for _, p := range existing {
+			bak, err := backupFile(p, time.Now())
+			if err != nil {
+				return generateResult{}, fmt.Errorf("backup %s: %w", p, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Backed up previous version to %s\n", bak)
 		}
 	}
-	return out
-}
-
-// nodeURLForLocal mirrors worker's logic for building a node/category URL per source
-func nodeURLForLocal(source, baseURL, node string) string {
-	source = strings.ToLower(strings.TrimSpace(source))
-	base := strings.TrimRight(baseURL, "/")
-	switch source {
-	case "v2ex":
-		if base == "" {
-			return ""
+	res := generateResult{Items: len(nd.Items), Paths: map[string]string{}}
+	for _, o := range outputs {
+		outPath := filepath.Join(dir, slug+o.Ext)
+		slog.Info("generate: generating newsletter", "channel", ch.Name, "file", outPath)
+		if err := fsutil.WriteFileAtomic(outPath, o.Content); err != nil {
+			return generateResult{}, err
 		}
-		return base + "/go/" + node
-	case "hackernews":
-		if base == "" {
-			base = "https://news.ycombinator.com"
-		}
-		n := strings.ToLower(strings.TrimSpace(node))
-		switch n {
-		case "ask":
-			return base + "/ask"
-		case "show":
-			return base + "/show"
-		case "job", "jobs":
-			return base + "/jobs"
-		default:
-			return base + "/news"
+		if res.Path == "" {
+			res.Path = outPath
 		}
-	default:
-		return base
+		res.Paths[o.Format] = outPath
 	}
+	prog.Finish()
+	return res, nil
 }
 
-// filterHNTypesLocal filters only when nodes include known HN item types; otherwise returns input unmodified.
-func filterHNTypesLocal(items []model.WithScore, nodes []string) []model.WithScore {
-	if len(nodes) == 0 {
-		return items
+// backupFile copies path to <path>.bak-<timestamp> and returns the backup path.
+func backupFile(path string, now time.Time) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
 	}
-	allowed := map[string]struct{}{}
-	for _, n := range nodes {
-		s := strings.ToLower(strings.TrimSpace(n))
-		switch s {
-		case "ask", "show", "job", "story":
-			allowed[s] = struct{}{}
-		}
+	bak := path + ".bak-" + now.UTC().Format("20060102T150405Z")
+	if err := fsutil.WriteFileAtomic(bak, b); err != nil {
+		return "", err
 	}
-	if len(allowed) == 0 {
-		return items
+	return bak, nil
+}
+
+// publishStatusNote describes whether the digest of a period was already pushed to Quaily.
+func publishStatusNote(ctx context.Context, store *storage.RedisStore, channel, period string) string {
+	meta, ok, err := store.GetPublishMeta(ctx, channel, period)
+	switch {
+	case err != nil:
+		slog.Warn("generate: read publish metadata failed", "err", err, "channel", channel, "period", period)
+		return "publish status unknown"
+	case !ok:
+		return "no publish record for this period"
+	case meta.Skipped != "":
+		return fmt.Sprintf("skipped by the builder at %s: %s", meta.SkippedAt.Format(time.RFC3339), meta.Skipped)
+	case meta.QuailyPublishedAt != nil:
+		return fmt.Sprintf("already pushed to Quaily as %q at %s; regenerating will not update the published post", meta.Slug, meta.QuailyPublishedAt.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("written at %s, not pushed to Quaily", meta.WrittenAt.Format(time.RFC3339))
 	}
-	out := make([]model.WithScore, 0, len(items))
-	for _, ws := range items {
-		if _, ok := allowed[strings.ToLower(ws.Item.NodeName)]; ok {
-			out = append(out, ws)
+}
+
+// emitGenerateResult prints the outcome of a generate run.
+func emitGenerateResult(cmd *cobra.Command, res generateResult) error {
+	return emit(cmd, res, func(w io.Writer) {
+		switch {
+		case res.SkippedReason == nil && len(res.Paths) > 1:
+			paths := make([]string, 0, len(res.Paths))
+			for _, p := range res.Paths {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+			for _, p := range paths {
+				fmt.Fprintf(w, "Generated: %s\n", p)
+			}
+		case res.SkippedReason == nil:
+			fmt.Fprintf(w, "Generated: %s\n", res.Path)
+		case *res.SkippedReason == "no_items":
+			fmt.Fprintln(w, "No items found for channel; skipping file creation.")
+		default:
+			fmt.Fprintf(w, "Only %d items (< min_items=%d); skipping file creation.\n", res.Items, res.MinItems)
 		}
+	})
+}
+
+// generateResult is the --output json schema of the generate command.
+// SkippedReason is null when a file was written, otherwise one of
+// "no_items", "below_min_items", or "exists" (SkipExisting kept the file at Path).
+type generateResult struct {
+	Path          string              `json:"path"`            // first format's file
+	Paths         map[string]string   `json:"paths,omitempty"` // file per format
+	Items         int                 `json:"items"`
+	MinItems      int                 `json:"-"`
+	Outputs       []newsletter.Output `json:"-"` // the rendered digest of a dry run
+	SkippedReason *string             `json:"skipped_reason"`
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.Flags().StringVarP(&genInputFile, "input-file", "i", "", "optional path to a text file of URLs to include (one per line)")
+	generateCmd.Flags().BoolVar(&genNoAI, "no-ai", false, "skip AI summaries and cover image generation")
+	generateCmd.Flags().StringSliceVar(&genFormats, "format", nil, "comma-separated output formats: markdown, html, json (default: the channel's formats)")
+	generateCmd.Flags().BoolVar(&genForce, "force", false, "overwrite an existing digest file for the date")
+	addMockSourcesFlag(generateCmd)
+	generateCmd.Flags().BoolVar(&genBackup, "backup", false, "keep an existing digest file as <name>.md.bak-<timestamp>, then overwrite it")
+	generateCmd.Flags().StringVar(&genReason, "reason", "", "why an existing digest is regenerated; noted in the revision added to its frontmatter")
+	generateCmd.Flags().BoolVarP(&genQuiet, "quiet", "q", false, "do not print progress and timings to stderr")
+	generateCmd.Flags().DurationVar(&genTimeout, "timeout", 0, "overall deadline for the run, e.g. 10m; when it passes nothing is written (0 = no limit)")
+}
+
+// generateProgress returns where generate reports progress: stderr unless --quiet.
+func generateProgress(cmd *cobra.Command) io.Writer {
+	if genQuiet {
+		return nil
 	}
-	return out
+	return cmd.ErrOrStderr()
+}
+
+// sourceBaseURL is the site a source's node links point into (see worker.NodeURL);
+// feed sources and Mastodon, whose nodes name the instance, have none.
+func sourceBaseURL(cfg config.Config, source string) string {
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case "v2ex":
+		return cfg.Sources.V2EX.BaseURL
+	case "hackernews":
+		return "https://news.ycombinator.com"
+	case "lobsters":
+		return firstNonEmpty(cfg.Sources.Lobsters.BaseURL, lobsters.DefaultBaseURL)
+	case "reddit":
+		return firstNonEmpty(cfg.Sources.Reddit.BaseURL, reddit.DefaultBaseURL)
+	case "github":
+		return githubtrending.SiteURL
+	case "producthunt":
+		return producthunt.SiteURL
+	case "arxiv":
+		return arxiv.SiteURL
+	case "bluesky":
+		return bluesky.SiteURL
+	case "stackoverflow":
+		return stackoverflow.SiteURL(cfg.Sources.StackOverflow.Site)
+	}
+	return ""
 }
 
-// firstNonEmpty returns the first non-empty string among inputs.
 func firstNonEmpty(vals ...string) string {
 	for _, v := range vals {
 		if strings.TrimSpace(v) != "" {
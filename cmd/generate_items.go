@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/scrape"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
+	"quaily-journalist/worker"
+)
+
+// itemDescriber turns the ranked items of a generate run into newsletter entries.
+type itemDescriber struct {
+	Summarizer    ai.Summarizer            // nil skips AI descriptions
+	Store         *storage.RedisStore      // tracks items whose summaries keep failing; may be nil
+	Scraper       *scrape.CloudflareClient // fetches content for items without any; may be nil
+	External      bool                     // URL-list mode: items link their host instead of a node
+	Source        string
+	BaseURL       string
+	Channel       string
+	Language      string
+	MinRunesForAI int
+	ShowAuthor    bool
+	WhyItMatters  bool                              // also ask for each item's "why it matters" takeaway
+	NodeTitles    map[string]string                 // display titles by node name
+	Highlights    map[string]model.CommentHighlight // community highlights by item ID
+}
+
+// Describe builds the entries of items in order. onItem, if set, is called before
+// each item is summarized with its 1-based position, so callers can report progress.
+// It also returns how many items got a first-sentence description instead of an AI
+// summary because their content was too thin.
+func (d itemDescriber) Describe(ctx context.Context, items []model.WithScore, onItem func(n, total int, title string)) ([]newsletter.Item, int) {
+	out := make([]newsletter.Item, 0, len(items))
+	skippedAI := 0
+	for i, ws := range items {
+		it := ws.Item
+		if onItem != nil {
+			onItem(i+1, len(items), it.Title)
+		}
+		var nodeURL string
+		if d.External {
+			// use scheme://host as category link for external URLs
+			if u, err := url.Parse(it.URL); err == nil && u.Host != "" {
+				if u.Scheme != "" {
+					nodeURL = u.Scheme + "://" + u.Host
+				} else {
+					nodeURL = "https://" + u.Host
+				}
+			}
+			if strings.TrimSpace(nodeURL) == "" {
+				nodeURL = it.URL
+			}
+		} else {
+			src := it.Source
+			if src == "" {
+				src = d.Source
+			}
+			nodeURL = worker.NodeURL(src, d.BaseURL, it.NodeName)
+		}
+		var desc, why string
+		contentForSum := it.Content
+		// If content is empty and Cloudflare client is available, scrape the URL to populate content
+		if strings.TrimSpace(contentForSum) == "" && d.Scraper != nil {
+			ctxReq, cancelReq := context.WithTimeout(ctx, 20*time.Second)
+			_, scraped, err := d.Scraper.Scrape(ctxReq, it.URL)
+			cancelReq()
+			if err == nil && strings.TrimSpace(scraped) != "" {
+				contentForSum = scraped
+			}
+		}
+		if d.Summarizer != nil {
+			if d.MinRunesForAI > 0 && textclean.ContentRunes(contentForSum) < d.MinRunesForAI {
+				// Too little text to summarize faithfully; use a deterministic description instead.
+				desc = textclean.FirstSentence(contentForSum)
+				skippedAI++
+			} else if d.WhyItMatters {
+				sum, _, err := worker.SummarizeItemWithTakeaway(ctx, d.Store, d.Summarizer, summaryItem(it, d.Source), contentForSum, d.Language)
+				if err != nil {
+					slog.Warn("generate: summarize item failed", "err", err, "channel", d.Channel, "title", it.Title, "url", it.URL)
+				}
+				desc, why = sum.Description, sum.WhyItMatters
+			} else if s, _, err := worker.SummarizeItem(ctx, d.Store, d.Summarizer, summaryItem(it, d.Source), contentForSum, d.Language); err == nil && s != "" {
+				desc = s
+			} else if err != nil {
+				slog.Warn("generate: summarize item failed", "err", err, "channel", d.Channel, "title", it.Title, "url", it.URL)
+			}
+		}
+		displayNode := it.NodeName
+		if !d.External {
+			if t, ok := d.NodeTitles[it.NodeName]; ok && strings.TrimSpace(t) != "" {
+				displayNode = t
+			}
+		}
+		author := ""
+		if d.ShowAuthor {
+			author = it.Author
+		}
+		out = append(out, newsletter.Item{
+			Title:        it.Title,
+			URL:          it.URL,
+			NodeName:     displayNode,
+			NodeURL:      nodeURL,
+			Description:  desc,
+			WhyItMatters: why,
+			Replies:      it.Replies,
+			Points:       it.Points,
+			Created:      it.CreatedAt.UTC().Format("2006-01-02 15:04"),
+			Author:       author,
+
+			ReadingMinutes: textclean.ReadingMinutes(contentForSum),
+			Highlight:      worker.NewsletterHighlight(d.Highlights, it.ID),
+		})
+	}
+	return out, skippedAI
+}
+
+// summaryItem is it with its source defaulted, for per-item summary failure tracking.
+func summaryItem(it model.NewsItem, source string) model.NewsItem {
+	if it.Source == "" {
+		it.Source = source
+	}
+	return it
+}
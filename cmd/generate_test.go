@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+func TestGenerateDeadlineWritesNothing(t *testing.T) {
+	mr := miniredis.RunT(t)
+	// An OpenAI endpoint that never answers: every AI call hangs until canceled.
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	out := t.TempDir()
+	prev := appCfg
+	t.Cleanup(func() { appCfg = prev })
+	appCfg = config.Config{
+		Redis:  config.RedisConfig{Addr: mr.Addr()},
+		OpenAI: config.OpenAIConfig{APIKey: "test", Model: "test", BaseURL: slow.URL},
+		Newsletters: config.NewslettersConfig{
+			OutputDir: out,
+			Channels:  []config.ChannelConfig{{Name: "hn", Source: "hackernews", Frequency: "daily", TopN: 5}},
+		},
+	}
+
+	at := time.Now().UTC()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+	for _, id := range []string{"1", "2"} {
+		it := model.NewsItem{ID: id, Title: "Story " + id, URL: "https://example.com/" + id, Points: 50, CreatedAt: at, Content: strings.Repeat("words ", 400)}
+		if err := store.AddNews(context.Background(), "hackernews", at.Format("2006-01-02"), it, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := runGenerate(ctx, &cobra.Command{}, "hn", generateOptions{At: at})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("runGenerate err = %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("runGenerate took %v after a 300ms deadline", elapsed)
+	}
+	var written []string
+	_ = filepath.WalkDir(out, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			written = append(written, p)
+		}
+		return nil
+	})
+	if len(written) > 0 {
+		t.Errorf("files written despite the deadline: %v", written)
+	}
+}
+
+func TestDiffAgainstWrittenDigest(t *testing.T) {
+	mr := miniredis.RunT(t)
+	out := t.TempDir()
+	prev := appCfg
+	t.Cleanup(func() { appCfg = prev })
+	appCfg = config.Config{
+		Redis: config.RedisConfig{Addr: mr.Addr()},
+		Newsletters: config.NewslettersConfig{
+			OutputDir: out,
+			Channels:  []config.ChannelConfig{{Name: "hn", Source: "hackernews", Frequency: "daily", TopN: 5}},
+		},
+	}
+	at := time.Now().UTC()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+	for _, id := range []string{"1", "2"} {
+		it := model.NewsItem{ID: id, Title: "Story " + id, URL: "https://example.com/" + id, Points: 50, CreatedAt: at, Content: "Some words."}
+		if err := store.AddNews(context.Background(), "hackernews", at.Format("2006-01-02"), it, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := runGenerate(context.Background(), &cobra.Command{}, "hn", generateOptions{At: at})
+	if err != nil || res.Path == "" {
+		t.Fatalf("runGenerate = %+v, %v", res, err)
+	}
+	diffDate = at.Format("2006-01-02")
+	t.Cleanup(func() { diffDate = "" })
+	diff := func() (string, int) {
+		var buf strings.Builder
+		c := &cobra.Command{}
+		c.SetOut(&buf)
+		c.SetErr(io.Discard)
+		if err := diffCmd.RunE(c, []string{"hn"}); err != nil {
+			if ExitCode(err) == 1 {
+				t.Fatal(err)
+			}
+			return buf.String(), ExitCode(err)
+		}
+		return buf.String(), diffExitIdentical
+	}
+
+	if text, code := diff(); code != diffExitIdentical {
+		t.Fatalf("fresh digest: exit %d\n%s", code, text)
+	}
+	b, _ := os.ReadFile(res.Path)
+	edited := strings.Replace(string(b), "## [Story 1]", "## [Story One]", 1)
+	edited = strings.Replace(edited, "slug: ", "draft: true\nslug: ", 1)
+	if err := os.WriteFile(res.Path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	text, code := diff()
+	if code != diffExitDiffers || !strings.Contains(text, "-## [Story One]") || !strings.Contains(text, "+## [Story 1]") || !strings.Contains(text, "- draft: true") {
+		t.Errorf("edited digest: exit %d\n%s", code, text)
+	}
+	os.Remove(res.Path)
+	if text, code := diff(); code != diffExitMissing {
+		t.Errorf("missing digest: exit %d\n%s", code, text)
+	}
+}
+
+// Regenerating a digest with --force keeps the revisions of the file it replaces
+// and appends its own.
+func TestRegenerateAppendsRevisions(t *testing.T) {
+	mr := miniredis.RunT(t)
+	prev := appCfg
+	t.Cleanup(func() { appCfg = prev })
+	appCfg = config.Config{
+		Redis: config.RedisConfig{Addr: mr.Addr()},
+		Newsletters: config.NewslettersConfig{
+			OutputDir: t.TempDir(),
+			Channels:  []config.ChannelConfig{{Name: "hn", Source: "hackernews", Frequency: "daily", TopN: 5}},
+		},
+	}
+	at := time.Now().UTC()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+	it := model.NewsItem{ID: "1", Title: "Story 1", URL: "https://example.com/1", Points: 50, CreatedAt: at, Content: "Some words."}
+	if err := store.AddNews(context.Background(), "hackernews", at.Format("2006-01-02"), it, 10); err != nil {
+		t.Fatal(err)
+	}
+	revisions := func(opts generateOptions) []newsletter.Revision {
+		t.Helper()
+		opts.At = at
+		c := &cobra.Command{}
+		c.SetErr(io.Discard)
+		res, err := runGenerate(context.Background(), c, "hn", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := markdown.ParseFile(res.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newsletter.ParseRevisions(doc.Frontmatter)
+	}
+
+	if revs := revisions(generateOptions{}); len(revs) != 0 {
+		t.Fatalf("first digest has revisions %+v", revs)
+	}
+	revs := revisions(generateOptions{Force: true, Reason: "fixed a summary"})
+	if len(revs) != 1 || revs[0].Reason != "fixed a summary" || revs[0].At == "" {
+		t.Fatalf("after one regeneration: %+v", revs)
+	}
+	first := revs[0]
+	revs = revisions(generateOptions{Force: true})
+	if len(revs) != 2 || revs[0] != first || revs[1].Reason != "" {
+		t.Fatalf("after two regenerations: %+v", revs)
+	}
+
+	// Backfill keeps the existing digest and reports the day as skipped.
+	c := &cobra.Command{}
+	c.SetErr(io.Discard)
+	res, err := runGenerate(context.Background(), c, "hn", generateOptions{At: at, SkipExisting: true})
+	if err != nil || res.SkippedReason == nil || *res.SkippedReason != "exists" {
+		t.Fatalf("SkipExisting = %+v, %v; want skipped as exists", res, err)
+	}
+	doc, err := markdown.ParseFile(res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revs := newsletter.ParseRevisions(doc.Frontmatter); len(revs) != 2 {
+		t.Errorf("SkipExisting rewrote the digest: revisions %+v", revs)
+	}
+}
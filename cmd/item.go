@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	itemRawJSON       bool
+	itemSourcePayload bool
+	itemSearchPeriod  string
+)
+
+// itemCmd groups commands for inspecting stored news items.
+var itemCmd = &cobra.Command{
+	Use:   "item",
+	Short: "Inspect stored news items",
+}
+
+// itemShowResult is the --output json schema of the item show command.
+type itemShowResult struct {
+	Source        string         `json:"source"`
+	Item          model.NewsItem `json:"item"`
+	AgeHours      float64        `json:"age_hours"`
+	ComputedScore float64        `json:"computed_score"`
+	DailyPeriod   string         `json:"daily_period"`
+	DailyScore    *float64       `json:"daily_score"`
+	WeeklyPeriod  string         `json:"weekly_period"`
+	WeeklyScore   *float64       `json:"weekly_score"`
+	// SummaryFailures counts consecutive failed AI summaries; at worker.SummaryFailureLimit
+	// the item gets its first sentence until the count expires.
+	SummaryFailures int `json:"summary_failures"`
+}
+
+var itemShowCmd = &cobra.Command{
+	Use:   "show <source> <id>",
+	Short: "Print a stored item with derived fields (age, scores)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := strings.ToLower(strings.TrimSpace(args[0]))
+		id := strings.TrimSpace(args[1])
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if itemSourcePayload {
+			return printSourcePayload(ctx, cmd, store, source, id)
+		}
+		raw, err := store.ItemJSON(ctx, source, id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("item not found: %s/%s", source, id)
+		}
+		if err != nil {
+			return err
+		}
+		if itemRawJSON {
+			_, err := cmd.OutOrStdout().Write(append(raw, '\n'))
+			return err
+		}
+		it, err := storage.DecodeItem(raw, source)
+		if err != nil {
+			return fmt.Errorf("decode item: %w", err)
+		}
+
+		scorer, err := sourceScorer(cfg, source)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		res := itemShowResult{
+			Source:        source,
+			Item:          it,
+			AgeHours:      now.Sub(it.CreatedAt).Hours(),
+			ComputedScore: scorer.Score(it, now),
+			DailyPeriod:   period.Key(period.Daily, now),
+			WeeklyPeriod:  period.Key(period.Weekly, now),
+		}
+		if sc, ok, err := store.ItemScore(ctx, source, res.DailyPeriod, id); err != nil {
+			return err
+		} else if ok {
+			res.DailyScore = &sc
+		}
+		if sc, ok, err := store.ItemScore(ctx, source, res.WeeklyPeriod, id); err != nil {
+			return err
+		} else if ok {
+			res.WeeklyScore = &sc
+		}
+		if res.SummaryFailures, err = store.SummaryFailures(ctx, source, id); err != nil {
+			return err
+		}
+
+		return emit(cmd, res, func(w io.Writer) {
+			// Decoded rather than raw so legacy records show the key-derived source.
+			pretty, err := json.MarshalIndent(it, "", "  ")
+			if err != nil {
+				pretty = raw
+			}
+			fmt.Fprintln(w, string(pretty))
+			fmt.Fprintf(w, "age: %.1fh\n", res.AgeHours)
+			fmt.Fprintf(w, "computed score: %.6f\n", res.ComputedScore)
+			fmt.Fprintf(w, "daily score (%s): %s\n", res.DailyPeriod, formatScore(res.DailyScore))
+			fmt.Fprintf(w, "weekly score (%s): %s\n", res.WeeklyPeriod, formatScore(res.WeeklyScore))
+			fmt.Fprintf(w, "AI summary failures: %d", res.SummaryFailures)
+			if res.SummaryFailures >= worker.SummaryFailureLimit {
+				fmt.Fprintf(w, " (backed off: first sentence used for %s after the latest failure)", worker.SummaryFailureCooldown)
+			}
+			fmt.Fprintln(w)
+		})
+	},
+}
+
+// itemSearchHit is one entry of the item search --output json result.
+type itemSearchHit struct {
+	ID       string  `json:"id"`
+	Score    float64 `json:"score"`
+	NodeName string  `json:"node_name"`
+	Title    string  `json:"title"`
+}
+
+var itemSearchCmd = &cobra.Command{
+	Use:   "search <source> <title-substring>",
+	Short: "Search the current period's items by title (case-insensitive)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := strings.ToLower(strings.TrimSpace(args[0]))
+		needle := strings.ToLower(strings.TrimSpace(args[1]))
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		key := strings.TrimSpace(itemSearchPeriod)
+		if key == "" {
+			key = period.Key(period.Daily, time.Now())
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		items, err := store.PeriodItems(ctx, source, key)
+		if err != nil {
+			return err
+		}
+		hits := make([]itemSearchHit, 0)
+		for _, ws := range items {
+			if strings.Contains(strings.ToLower(ws.Item.Title), needle) {
+				hits = append(hits, itemSearchHit{ID: ws.Item.ID, Score: ws.Score, NodeName: ws.Item.NodeName, Title: ws.Item.Title})
+			}
+		}
+		res := map[string]any{"source": source, "period": key, "items": hits}
+		return emit(cmd, res, func(w io.Writer) {
+			if len(hits) == 0 {
+				fmt.Fprintf(w, "No items matching %q in %s/%s.\n", args[1], source, key)
+				return
+			}
+			for _, h := range hits {
+				fmt.Fprintf(w, "%s\t%.6f\t%s\t%s\n", h.ID, h.Score, h.NodeName, h.Title)
+			}
+		})
+	},
+}
+
+// printSourcePayload prints the archived source payload of an item, JSON indented.
+func printSourcePayload(ctx context.Context, cmd *cobra.Command, store *storage.RedisStore, source, id string) error {
+	payload, err := store.GetRaw(ctx, source, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("no raw payload archived for %s/%s (needs sources.archive_raw; payloads expire after %s)", source, id, storage.RawTTL)
+	}
+	if err != nil {
+		return err
+	}
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, payload, "", "  ") == nil {
+		payload = pretty.Bytes()
+	}
+	_, err = cmd.OutOrStdout().Write(append(payload, '\n'))
+	return err
+}
+
+func formatScore(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.6f", *p)
+}
+
+func init() {
+	rootCmd.AddCommand(itemCmd)
+	itemCmd.AddCommand(itemShowCmd)
+	itemCmd.AddCommand(itemSearchCmd)
+	itemShowCmd.Flags().BoolVar(&itemRawJSON, "json", false, "print the raw stored JSON only")
+	itemShowCmd.Flags().BoolVar(&itemSourcePayload, "raw", false, "print the source payload the item was parsed from (needs sources.archive_raw)")
+	itemSearchCmd.Flags().StringVar(&itemSearchPeriod, "period", "", "period key to scan (default: today's daily period, e.g. 2025-10-24)")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// outputFormat selects how commands print their results: "text" (default) or "json".
+var outputFormat string
+
+// validateOutputFormat normalizes and checks the --output flag value.
+func validateOutputFormat() error {
+	outputFormat = strings.ToLower(strings.TrimSpace(outputFormat))
+	switch outputFormat {
+	case "", "text":
+		outputFormat = "text"
+		return nil
+	case "json":
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: must be text or json", outputFormat)
+	}
+}
+
+// jsonOutput reports whether machine-readable output was requested.
+func jsonOutput() bool {
+	return outputFormat == "json"
+}
+
+// emit prints a command result. In JSON mode v is written to stdout as a single
+// JSON object; otherwise text is called to print the human-readable form.
+func emit(cmd *cobra.Command, v any, text func(w io.Writer)) error {
+	w := cmd.OutOrStdout()
+	if jsonOutput() {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	}
+	if text != nil {
+		text(w)
+	}
+	return nil
+}
+
+// strPtr returns a pointer to s, used for nullable JSON fields.
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// pinResult is the --output json schema of the pin and unpin commands.
+type pinResult struct {
+	Channel string `json:"channel"`
+	Source  string `json:"source"`
+	ID      string `json:"id"`
+	Pinned  bool   `json:"pinned"`  // whether the item is pinned after the command
+	Changed bool   `json:"changed"` // false when it already was (pin) or was not (unpin)
+}
+
+// pinCmd forces a stored item into a channel's next digest.
+var pinCmd = &cobra.Command{
+	Use:   "pin <channel> <source> <item_id>",
+	Short: "Pin an item to the top of a channel's next digest",
+	Long: "Pin an item to the top of a channel's next digest, even if it ranks below the cutoff or outside\n" +
+		"the channel's nodes. Pinned items count toward top_n and are unpinned once published.",
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPin(cmd, args, true)
+	},
+}
+
+// unpinCmd removes a pin set with pinCmd.
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <channel> <source> <item_id>",
+	Short: "Remove an item's pin from a channel's next digest",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPin(cmd, args, false)
+	},
+}
+
+func runPin(cmd *cobra.Command, args []string, pin bool) error {
+	cfg := GetConfig()
+	var ch *config.ChannelConfig
+	for i := range cfg.Newsletters.Channels {
+		if cfg.Newsletters.Channels[i].Name == args[0] {
+			ch = &cfg.Newsletters.Channels[i]
+			break
+		}
+	}
+	if ch == nil {
+		return fmt.Errorf("channel not found: %s", args[0])
+	}
+	source := strings.ToLower(strings.TrimSpace(args[1]))
+	if source != strings.ToLower(ch.Source) {
+		return fmt.Errorf("channel %s collects from %s, not %s", ch.Name, strings.ToLower(ch.Source), source)
+	}
+	id := strings.TrimSpace(args[2])
+	rdb := redisclient.New(cfg.Redis)
+	defer rdb.Close()
+	store := newStore(cfg, rdb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ids, err := store.PinnedIDs(ctx, ch.Name)
+	if err != nil {
+		return err
+	}
+	was := false
+	for _, p := range ids {
+		if p == id {
+			was = true
+			break
+		}
+	}
+	res := pinResult{Channel: ch.Name, Source: source, ID: id, Pinned: pin, Changed: was != pin}
+	if pin {
+		if _, err := store.GetItem(ctx, source, id); errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("item not found: %s/%s", source, id)
+		} else if err != nil {
+			return err
+		}
+		if err := store.PinItem(ctx, ch.Name, id); err != nil {
+			return err
+		}
+	} else if _, err := store.UnpinItem(ctx, ch.Name, id); err != nil {
+		return err
+	}
+	return emit(cmd, res, func(w io.Writer) {
+		switch {
+		case pin && res.Changed:
+			fmt.Fprintf(w, "Pinned %s/%s into the next %s digest.\n", source, id, ch.Name)
+		case pin:
+			fmt.Fprintf(w, "%s/%s is already pinned in %s.\n", source, id, ch.Name)
+		case res.Changed:
+			fmt.Fprintf(w, "Unpinned %s/%s from %s.\n", source, id, ch.Name)
+		default:
+			fmt.Fprintf(w, "%s/%s was not pinned in %s.\n", source, id, ch.Name)
+		}
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
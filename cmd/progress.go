@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressReporter prints the stages of a generate run as they start, one line per
+// summarized item, and the time spent per stage at the end. A nil reporter is silent.
+type progressReporter struct {
+	w      io.Writer
+	now    func() time.Time
+	start  time.Time
+	stage  string
+	began  time.Time
+	timing []stageTiming
+}
+
+type stageTiming struct {
+	name string
+	d    time.Duration
+}
+
+// newProgressReporter returns a reporter writing to w, or nil when w is nil.
+func newProgressReporter(w io.Writer) *progressReporter {
+	if w == nil {
+		return nil
+	}
+	now := time.Now
+	return &progressReporter{w: w, now: now, start: now()}
+}
+
+// Stage ends the current stage and starts the named one.
+func (p *progressReporter) Stage(name string) {
+	if p == nil {
+		return
+	}
+	p.endStage()
+	p.stage, p.began = name, p.now()
+	p.printf("%s", name)
+}
+
+// Item reports that item n of total is being summarized.
+func (p *progressReporter) Item(n, total int, title string) {
+	if p == nil {
+		return
+	}
+	p.printf("summarizing item %d/%d: %s", n, total, title)
+}
+
+// Finish ends the current stage and prints the timing totals.
+func (p *progressReporter) Finish() {
+	if p == nil {
+		return
+	}
+	p.endStage()
+	fmt.Fprintln(p.w, "Timings:")
+	for _, t := range p.timing {
+		fmt.Fprintf(p.w, "  %-20s %7.1fs\n", t.name, t.d.Seconds())
+	}
+	fmt.Fprintf(p.w, "  %-20s %7.1fs\n", "total", p.now().Sub(p.start).Seconds())
+}
+
+func (p *progressReporter) endStage() {
+	if p.stage != "" {
+		p.timing = append(p.timing, stageTiming{name: p.stage, d: p.now().Sub(p.began)})
+		p.stage = ""
+	}
+}
+
+// printf writes one line prefixed with the time elapsed since the run started.
+func (p *progressReporter) printf(format string, args ...any) {
+	fmt.Fprintf(p.w, "[%6.1fs] %s\n", p.now().Sub(p.start).Seconds(), fmt.Sprintf(format, args...))
+}
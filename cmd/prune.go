@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+// pruneCmd groups commands that remove local state the app no longer needs.
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove local data beyond the configured retention",
+}
+
+var (
+	pruneKeep   int
+	pruneDryRun bool
+)
+
+var pruneFilesCmd = &cobra.Command{
+	Use:   "files [channel]",
+	Short: "Delete or archive digest files beyond each channel's retention.keep_files",
+	Long: "Keeps the newest retention.keep_files digests of each channel (or of one channel) on disk " +
+		"and deletes the older ones, or moves them to <channel>/archive with retention.mode archive. " +
+		"Digests a pending delivery still reads are kept. Channels without keep_files are skipped unless --keep is given.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		var channels []config.Channel
+		if len(args) == 1 {
+			ch, ok := cfg.FindChannel(strings.TrimSpace(args[0]))
+			if !ok {
+				return fmt.Errorf("channel not found: %s", args[0])
+			}
+			channels = append(channels, ch)
+		} else {
+			for _, cc := range cfg.Newsletters.Channels {
+				channels = append(channels, cfg.ResolveChannel(cc))
+			}
+		}
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		results := []worker.PruneResult{}
+		for _, ch := range channels {
+			keep := ch.Config.Retention.KeepFiles
+			if pruneKeep > 0 {
+				keep = pruneKeep
+			}
+			if keep <= 0 {
+				continue
+			}
+			res, err := worker.PruneDigestFiles(ctx, store, worker.PruneOptions{
+				OutputDir: ch.OutputDir,
+				Channel:   ch.Name,
+				Keep:      keep,
+				Mode:      ch.Config.Retention.Mode,
+				DryRun:    pruneDryRun,
+			})
+			if err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			results = append(results, res)
+		}
+		return emit(cmd, pruneFilesResult{Channels: results}, func(w io.Writer) {
+			if len(results) == 0 {
+				fmt.Fprintln(w, "No channel sets retention.keep_files; pass --keep to prune anyway.")
+				return
+			}
+			for _, r := range results {
+				printPruneResult(w, r)
+			}
+		})
+	},
+}
+
+// pruneFilesResult is the --output json schema of prune files.
+type pruneFilesResult struct {
+	Channels []worker.PruneResult `json:"channels"`
+}
+
+// printPruneResult writes a channel's pruned digests, one line per file.
+func printPruneResult(w io.Writer, r worker.PruneResult) {
+	verb := map[string]string{worker.RetentionDelete: "deleted", worker.RetentionArchive: "archived"}[r.Mode]
+	if r.DryRun {
+		verb = "would be " + verb
+	}
+	fmt.Fprintf(w, "%s: kept %d digests, %d %s\n", r.Channel, r.Kept, len(r.Pruned), verb)
+	for _, d := range r.Pruned {
+		for _, f := range d.Files {
+			fmt.Fprintf(w, "  %s\n", f)
+		}
+	}
+	for _, name := range r.Held {
+		fmt.Fprintf(w, "  %s held: a delivery is pending\n", name)
+	}
+}
+
+func init() {
+	pruneFilesCmd.Flags().IntVar(&pruneKeep, "keep", 0, "digests to keep per channel (default: the channel's retention.keep_files)")
+	pruneFilesCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "print what would be pruned without touching any file")
+	pruneCmd.AddCommand(pruneFilesCmd)
+	rootCmd.AddCommand(pruneCmd)
+}
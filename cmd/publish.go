@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
-	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/worker"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	publishProfile string
+	publishForce   bool
+)
+
 var publishCmd = &cobra.Command{
 	Use:   "publish <markdown_path> <channel_slug>",
 	Short: "Publish a markdown file to Quaily",
@@ -22,23 +29,42 @@ var publishCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := GetConfig()
-		if cfg.Quaily.BaseURL == "" || cfg.Quaily.APIKey == "" {
-			return fmt.Errorf("quaily config missing: set quaily.base_url and quaily.api_key in config.yaml")
-		}
 		tm := 20 * time.Second
-		cli := quaily.New(cfg.Quaily.BaseURL, cfg.Quaily.APIKey, tm)
+		cli, err := newQuailyClient(cfg, publishProfile, tm)
+		if err != nil {
+			return err
+		}
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
 		ctx, cancel := context.WithTimeout(context.Background(), tm)
 		defer cancel()
 		mdPath := args[0]
 		channelSlug := args[1]
-		if err := quaily.PublishMarkdownFile(ctx, cli, mdPath, channelSlug); err != nil {
+		_, unchanged, err := worker.PushMarkdownFile(ctx, newStore(cfg, rdb), cli, mdPath, channelSlug, publishForce)
+		if err != nil {
 			return err
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Published %s to Quaily channel %s\n", mdPath, channelSlug)
-		return nil
+		return emit(cmd, publishResult{Path: mdPath, Channel: channelSlug, Published: !unchanged, Unchanged: unchanged}, func(w io.Writer) {
+			if unchanged {
+				fmt.Fprintf(w, "%s is unchanged since it was last pushed to Quaily channel %s; skipped (pass --force to publish anyway)\n", mdPath, channelSlug)
+				return
+			}
+			fmt.Fprintf(w, "Published %s to Quaily channel %s\n", mdPath, channelSlug)
+		})
 	},
 }
 
+// publishResult is the --output json schema of the publish command.
+type publishResult struct {
+	Path      string `json:"path"`
+	Channel   string `json:"channel"`
+	Published bool   `json:"published"`
+	// Unchanged is true when the content matched the last push and nothing was sent.
+	Unchanged bool `json:"unchanged,omitempty"`
+}
+
 func init() {
 	rootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().BoolVar(&publishForce, "force", false, "publish even when the content is identical to the last push of the same slug")
+	publishCmd.Flags().StringVar(&publishProfile, "profile", "", "quaily.profiles entry to publish with (default: the flat quaily.base_url/api_key)")
 }
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"quaily-journalist/internal/config"
+	"quaily-journalist/worker"
+)
+
+// quietHours converts a quiet_hours setting for a collector or builder; unset or
+// malformed settings (which Validate already reported) never quiet.
+func quietHours(q config.QuietHoursConfig) worker.QuietHours {
+	if !q.Enabled() {
+		return worker.QuietHours{}
+	}
+	start, end, loc, err := q.Window()
+	if err != nil {
+		return worker.QuietHours{}
+	}
+	return worker.QuietHours{Start: start, End: end, Loc: loc}
+}
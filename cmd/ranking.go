@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/ranking"
+)
+
+// sourceScorer returns the collector scorer of a source: its defaults with the
+// sources.<source>.ranking parameters applied.
+func sourceScorer(cfg config.Config, source string) (ranking.Scorer, error) {
+	source = strings.ToLower(source)
+	s := ranking.ForSource(source).Merge(rankingParams(cfg.SourceRanking(source)))
+	if err := s.Validate(); err != nil {
+		return ranking.Scorer{}, fmt.Errorf("sources.%s.ranking: %w", source, err)
+	}
+	return s, nil
+}
+
+// channelScorer returns the scorer a channel rescores its candidates with, or nil
+// when the channel has no ranking override and keeps the collector scores.
+func channelScorer(cfg config.Config, ch config.ChannelConfig) (*ranking.Scorer, error) {
+	if ch.Ranking.IsZero() {
+		return nil, nil
+	}
+	base, err := sourceScorer(cfg, ch.Source)
+	if err != nil {
+		return nil, err
+	}
+	s := base.Merge(rankingParams(ch.Ranking))
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("channel %s ranking: %w", ch.Name, err)
+	}
+	return &s, nil
+}
+
+func rankingParams(r config.RankingConfig) ranking.Scorer {
+	return ranking.Scorer{
+		Gravity:      r.Gravity,
+		AgeOffset:    r.AgeOffset,
+		Signal:       strings.ToLower(strings.TrimSpace(r.Signal)),
+		ReplyWeight:  r.ReplyWeight,
+		PointWeight:  r.PointWeight,
+		RecencyBoost: r.RecencyBoost,
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/worker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileQuaily bool
+	reconcileDays   int
+	reconcileDryRun bool
+)
+
+// reconcileCmd repairs state that a failed step left behind; serve runs the Quaily check at startup.
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile --quaily [channel]",
+	Short: "Push recent digests that were written but never reached Quaily",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !reconcileQuaily {
+			return errors.New("nothing to reconcile; pass --quaily")
+		}
+		cfg := GetConfig()
+		qclients, err := newChannelQuailyClients(cfg, 20*time.Second)
+		if err != nil {
+			return err
+		}
+		if len(qclients) == 0 {
+			return fmt.Errorf("quaily config missing: set quaily.base_url and quaily.api_key (or the channels' quaily.profiles) in config.yaml")
+		}
+		channels := reconcileChannels(cfg, qclients)
+		if len(args) == 1 {
+			name := strings.TrimSpace(args[0])
+			var one []worker.ReconcileChannel
+			for _, ch := range channels {
+				if ch.Name == name {
+					one = append(one, ch)
+				}
+			}
+			if len(one) == 0 {
+				return fmt.Errorf("channel not found: %s", name)
+			}
+			channels = one
+		}
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		days := reconcileDays
+		if days <= 0 {
+			days = cfg.Quaily.ReconcileDays
+		}
+		rec := &worker.QuailyReconciler{
+			Store:    newStore(cfg, rdb),
+			Channels: channels,
+			Days:     days,
+			DryRun:   reconcileDryRun,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		results, err := rec.Run(ctx)
+		if err != nil {
+			return err
+		}
+		res := reconcileResult{DryRun: reconcileDryRun, Digests: results}
+		if res.Digests == nil {
+			res.Digests = []worker.QuailyReconcileResult{}
+		}
+		return emit(cmd, res, func(w io.Writer) {
+			if len(results) == 0 {
+				fmt.Fprintln(w, "All recent digests are on Quaily.")
+				return
+			}
+			for _, r := range results {
+				fmt.Fprintf(w, "%s %s %s: %s\n", r.Channel, r.Period, r.Slug, reconcileNote(r, reconcileDryRun))
+			}
+		})
+	},
+}
+
+// reconcileResult is the --output json schema of reconcile --quaily.
+type reconcileResult struct {
+	DryRun  bool                           `json:"dry_run"`
+	Digests []worker.QuailyReconcileResult `json:"digests"`
+}
+
+// reconcileChannels lists the configured channels for a QuailyReconciler.
+func reconcileChannels(cfg config.Config, qclients map[string]*quaily.Client) []worker.ReconcileChannel {
+	out := make([]worker.ReconcileChannel, 0, len(cfg.Newsletters.Channels))
+	for _, ch := range cfg.Newsletters.Channels {
+		out = append(out, worker.ReconcileChannel{Name: ch.Name, Frequency: strings.ToLower(ch.Frequency), Quaily: qclients[ch.Name]})
+	}
+	return out
+}
+
+// reconcileNote describes the outcome of one digest for text output.
+func reconcileNote(r worker.QuailyReconcileResult, dryRun bool) string {
+	if r.Error != "" {
+		return "failed: " + r.Error
+	}
+	switch {
+	case r.Action == worker.ReconcilePush && dryRun:
+		return "missing on Quaily; would push " + r.Path
+	case r.Action == worker.ReconcilePush:
+		return "pushed " + r.Path
+	case r.Action == worker.ReconcilePublish && dryRun:
+		return "draft on Quaily; would publish it"
+	case r.Action == worker.ReconcilePublish:
+		return "published the existing draft"
+	case dryRun:
+		return "already on Quaily; would record it as pushed"
+	default:
+		return "already on Quaily; recorded as pushed"
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.Flags().BoolVar(&reconcileQuaily, "quaily", false, "check recent digests against Quaily and push the missing ones")
+	reconcileCmd.Flags().IntVar(&reconcileDays, "days", 0, "how many days back to check, including today (default: quaily.reconcile_days, or 3)")
+	reconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "print what would be pushed without changing anything")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	keysPattern string
+	keysWithTTL bool
+	keysLimit   int
+	keysDelete  bool
+	keysYes     bool
+)
+
+// keysCmd lists (and optionally deletes) keys in this app's Redis keyspace.
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "List this app's Redis keys with type and size (SCAN-based)",
+	Long: "List keys under the app's prefixes (" + strings.Join(storage.KeyPrefixes, "*, ") + "*) using SCAN.\n" +
+		"Keys outside these prefixes are never listed or deleted, whatever the pattern.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if keysDelete && strings.TrimSpace(keysPattern) == "" {
+			return errors.New("--delete requires an explicit --pattern")
+		}
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		limit := keysLimit
+		if keysDelete {
+			limit = 0 // delete every match, not just the first page
+		}
+		keys, err := store.ScanKeys(ctx, strings.TrimSpace(keysPattern), limit)
+		if err != nil {
+			return err
+		}
+		if keysDelete {
+			if !keysYes {
+				return fmt.Errorf("%d keys match %q; re-run with --yes to delete them", len(keys), keysPattern)
+			}
+			n, err := store.DeleteKeys(ctx, keys)
+			if err != nil {
+				return err
+			}
+			return emit(cmd, map[string]any{"pattern": keysPattern, "deleted": n}, func(w io.Writer) {
+				fmt.Fprintf(w, "Deleted %d keys matching %q\n", n, keysPattern)
+			})
+		}
+
+		infos := make([]storage.KeyInfo, 0, len(keys))
+		for _, k := range keys {
+			info, err := store.DescribeKey(ctx, k)
+			if err != nil {
+				return err
+			}
+			infos = append(infos, info)
+		}
+		res := keysResult{Keys: make([]keyEntry, 0, len(infos))}
+		for _, in := range infos {
+			ttl := int64(-1)
+			if in.TTL >= 0 {
+				ttl = int64(in.TTL / time.Second)
+			}
+			res.Keys = append(res.Keys, keyEntry{Key: in.Key, Type: in.Type, TTLSeconds: ttl, Size: in.Size})
+		}
+		return emit(cmd, res, func(w io.Writer) {
+			for _, in := range infos {
+				size := "-"
+				if in.Size >= 0 {
+					size = fmt.Sprintf("%dB", in.Size)
+				}
+				if keysWithTTL {
+					ttl := "none"
+					if in.TTL >= 0 {
+						ttl = in.TTL.Round(time.Second).String()
+					}
+					fmt.Fprintf(w, "%-6s %8s %10s  %s\n", in.Type, size, ttl, in.Key)
+				} else {
+					fmt.Fprintf(w, "%-6s %8s  %s\n", in.Type, size, in.Key)
+				}
+			}
+			if keysLimit > 0 && len(infos) == keysLimit {
+				fmt.Fprintf(w, "(stopped at --limit %d)\n", keysLimit)
+			}
+		})
+	},
+}
+
+// keysResult is the --output json schema of the redis keys command.
+type keysResult struct {
+	Keys []keyEntry `json:"keys"`
+}
+
+// keyEntry describes one key; ttl_seconds is -1 without expiry and size (bytes) is -1
+// when the server does not support MEMORY USAGE.
+type keyEntry struct {
+	Key        string `json:"key"`
+	Type       string `json:"type"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+	Size       int64  `json:"size"`
+}
+
+func init() {
+	redisCmd.AddCommand(keysCmd)
+	keysCmd.Flags().StringVar(&keysPattern, "pattern", "", "glob pattern, e.g. 'news:item:hackernews:*' (default: all app keys)")
+	keysCmd.Flags().BoolVar(&keysWithTTL, "with-ttl", false, "show each key's remaining TTL")
+	keysCmd.Flags().IntVar(&keysLimit, "limit", 100, "maximum keys to list; 0 = no limit")
+	keysCmd.Flags().BoolVar(&keysDelete, "delete", false, "delete every key matching --pattern (requires --yes)")
+	keysCmd.Flags().BoolVar(&keysYes, "yes", false, "confirm --delete")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"quaily-journalist/internal/redisclient"
@@ -27,8 +28,9 @@ var pingCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		fmt.Fprintln(cmd.OutOrStdout(), res)
-		return nil
+		return emit(cmd, map[string]string{"result": res}, func(w io.Writer) {
+			fmt.Fprintln(w, res)
+		})
 	},
 }
 
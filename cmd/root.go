@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/logging"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,6 +22,9 @@ var rootCmd = &cobra.Command{
 	Use:   "quaily-journalist",
 	Short: "Quaily Journalist CLI",
 	Long:  "Minimal CLI using Cobra, Viper, and Redis.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat()
+	},
 }
 
 // Execute runs the root command.
@@ -28,11 +32,33 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// exitCodeError ends a command whose exit status carries its result (diff) after
+// its output was printed; it is not reported as an error.
+type exitCodeError struct{ code int }
+
+func (e exitCodeError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+// exitWith returns an exitCodeError for code, silencing cobra's error report.
+func exitWith(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	return exitCodeError{code}
+}
+
+// ExitCode is the process exit status for an error returned by Execute.
+func ExitCode(err error) int {
+	var ec exitCodeError
+	if errors.As(err, &ec) {
+		return ec.code
+	}
+	return 1
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ./config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "output format: text or json")
 }
 
 func initConfig() {
@@ -64,9 +90,29 @@ func initConfig() {
 	}
 
 	appCfg.FillDefaults()
+
+	if err := logging.Setup(appCfg.App); err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring logging: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 // GetConfig exposes the loaded configuration to subcommands.
 func GetConfig() config.Config {
 	return appCfg
 }
+
+// reloadConfig re-reads the config file used at startup and returns the parsed result.
+// The configuration returned by GetConfig is left unchanged.
+func reloadConfig() (config.Config, error) {
+	v := viper.GetViper()
+	if err := v.ReadInConfig(); err != nil {
+		return config.Config{}, err
+	}
+	var c config.Config
+	if err := v.Unmarshal(&c); err != nil {
+		return config.Config{}, err
+	}
+	c.FillDefaults()
+	return c, nil
+}
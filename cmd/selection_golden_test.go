@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// The digests generate writes for existing channel configs must stay byte for byte
+// what they were before item selection moved to internal/selection.
+func TestGenerateSelectionGolden(t *testing.T) {
+	mr := miniredis.RunT(t)
+	prev := appCfg
+	t.Cleanup(func() { appCfg = prev })
+	appCfg = config.Config{
+		Redis: config.RedisConfig{Addr: mr.Addr()},
+		Newsletters: config.NewslettersConfig{
+			OutputDir: t.TempDir(),
+			Channels: []config.ChannelConfig{
+				{Name: "hn-show", Source: "hackernews", Frequency: "daily", TopN: 3, Nodes: []string{"top", "show", "ask"}},
+				{Name: "lob-go", Source: "lobsters", Frequency: "daily", TopN: 4, Nodes: []string{"go", "rust"}, NodeWeights: map[string]float64{"rust": 0.5}},
+				{Name: "v2-py", Source: "v2ex", Frequency: "daily", TopN: 4, MinItems: 2, Nodes: []string{"python", "q:django"}, RepeatPenalty: 0.5, ItemOrder: "chronological"},
+				{Name: "v2-all", Source: "v2ex", Frequency: "daily", TopN: 10, MinReplies: 3, TitleDedupThreshold: -1, ItemOrder: "node"},
+				// Its posts rank past TopN×5, which generate reads as deep as the builder does.
+				{Name: "reddit-go", Source: "reddit", Frequency: "daily", TopN: 2, Nodes: []string{"golang"}},
+			},
+		},
+	}
+	at := time.Date(2025, 10, 24, 9, 0, 0, 0, time.UTC)
+	day := at.Format("2006-01-02")
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+	ctx := context.Background()
+	add := func(source string, score float64, it model.NewsItem) {
+		t.Helper()
+		it.Source = source
+		it.CreatedAt = at.Add(-time.Duration(score) * time.Minute)
+		it.Content = "Content of " + it.Title + "."
+		if err := store.AddNews(ctx, source, day, it, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add("hackernews", 90, model.NewsItem{ID: "h1", Title: "Show HN: A tiny database", URL: "https://example.com/db", NodeName: "show", Points: 90})
+	add("hackernews", 80, model.NewsItem{ID: "h2", Title: "A story about compilers", URL: "https://example.com/compilers", NodeName: "story", Points: 80})
+	add("hackernews", 70, model.NewsItem{ID: "h3", Title: "Ask HN: How do you take notes?", URL: "https://news.ycombinator.com/item?id=3", NodeName: "ask", Points: 70})
+	add("hackernews", 60, model.NewsItem{ID: "h4", Title: "Show HN: A tiny database", URL: "https://www.example.com/db/?utm_source=hn", NodeName: "show", Points: 60})
+	add("hackernews", 50, model.NewsItem{ID: "h5", Title: "Show HN: Terminal spreadsheets", URL: "https://example.com/sheets", NodeName: "show", Points: 50})
+	add("hackernews", 40, model.NewsItem{ID: "h6", Title: "Show HN: Excluded by URL", URL: "https://example.com/excluded", NodeName: "show", Points: 40})
+	add("hackernews", 30, model.NewsItem{ID: "h7", Title: "Ask HN: Favourite shell tricks?", URL: "https://news.ycombinator.com/item?id=7", NodeName: "ask", Points: 30})
+
+	add("lobsters", 50, model.NewsItem{ID: "l1", Title: "Generics in practice", URL: "https://example.org/generics", NodeName: "go,plt", Replies: 4})
+	add("lobsters", 45, model.NewsItem{ID: "l2", Title: "Borrow checker internals", URL: "https://example.org/borrow", NodeName: "rust"})
+	add("lobsters", 30, model.NewsItem{ID: "l3", Title: "A Haskell tutorial", URL: "https://example.org/haskell", NodeName: "haskell"})
+	add("lobsters", 24, model.NewsItem{ID: "l4", Title: "Profiling Go services", URL: "https://example.org/pprof", NodeName: "go,performance"})
+	add("lobsters", 20, model.NewsItem{ID: "l5", Title: "Async Rust patterns", URL: "https://example.org/async", NodeName: "rust,async"})
+	add("lobsters", 0, model.NewsItem{ID: "l6", Title: "Zero-scored Go post", URL: "https://example.org/zero", NodeName: "go"})
+
+	add("v2ex", 40, model.NewsItem{ID: "v1", Title: "Python packaging in 2025", URL: "https://v2ex.com/t/1", NodeName: "python", Replies: 12})
+	add("v2ex", 35, model.NewsItem{ID: "v2", Title: "Which laptop for work", URL: "https://v2ex.com/t/2", NodeName: "qna", Replies: 30})
+	add("v2ex", 30, model.NewsItem{ID: "v3", Title: "Django or FastAPI for a new service", URL: "https://v2ex.com/t/3", NodeName: "programmer", Replies: 8})
+	add("v2ex", 25, model.NewsItem{ID: "v4", Title: "Python typing tips", URL: "https://v2ex.com/t/4", NodeName: "python", Replies: 2})
+	add("v2ex", 20, model.NewsItem{ID: "v5", Title: "Python packaging in 2025 again", URL: "https://v2ex.com/t/5", NodeName: "python", Replies: 5})
+	add("v2ex", 15, model.NewsItem{ID: "v6", Title: "A quiet python question", URL: "https://v2ex.com/t/6", NodeName: "python"})
+	add("v2ex", 10, model.NewsItem{ID: "v7", Title: "Excluded by ID", URL: "https://v2ex.com/t/7", NodeName: "python", Replies: 9})
+
+	for i := 0; i < 12; i++ {
+		add("reddit", float64(100-i), model.NewsItem{ID: fmt.Sprintf("r%d", i), Title: fmt.Sprintf("Rust post %d", i), URL: fmt.Sprintf("https://example.net/rust/%d", i), NodeName: "rust"})
+	}
+	add("reddit", 60, model.NewsItem{ID: "g1", Title: "Go 1.25 release notes", URL: "https://example.net/go/1", NodeName: "golang"})
+	add("reddit", 55, model.NewsItem{ID: "g2", Title: "Structured logging with slog", URL: "https://example.net/go/2", NodeName: "golang"})
+
+	for _, e := range []string{"url:https://example.com/excluded", "id:v7"} {
+		source := map[byte]string{'u': "hackernews", 'i': "v2ex"}[e[0]]
+		if _, err := store.AddExclusion(ctx, source, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// v1 was in two earlier digests of v2-py, which quarters its score there.
+	for i := 0; i < 2; i++ {
+		if err := store.RecordAppearances(ctx, "v2-py", []string{"v1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ch := range appCfg.Newsletters.Channels {
+		t.Run(ch.Name, func(t *testing.T) {
+			c := &cobra.Command{}
+			c.SetErr(io.Discard)
+			res, err := runGenerate(ctx, c, ch.Name, generateOptions{At: at, NoAI: true, DryRun: true, Formats: []string{"markdown"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if res.SkippedReason != nil {
+				fmt.Fprintf(&got, "skipped: %s (%d items)\n", *res.SkippedReason, res.Items)
+			}
+			for _, o := range res.Outputs {
+				got.Write(o.Content)
+			}
+			golden := filepath.Join("testdata", "selection_"+ch.Name+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("digest differs from %s:\n%s", golden, got.String())
+			}
+		})
+	}
+}
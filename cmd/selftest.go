@@ -0,0 +1,381 @@
+package cmd
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/worker"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+// selftestItems are the items the selftest builds its digest from.
+//
+//go:embed selftest_items.json
+var selftestItems []byte
+
+var (
+	selftestRedisDB       int
+	selftestWithQuaily    bool
+	selftestQuailyChannel string
+	selftestQuailyProfile string
+)
+
+// selftestChannel is the synthetic channel the selftest builds.
+const selftestChannel = "selftest"
+
+// Selftest stage statuses.
+const (
+	selftestOK      = "ok"
+	selftestFailed  = "failed"
+	selftestSkipped = "skipped"
+)
+
+// selftestStage is the outcome of one pipeline stage.
+type selftestStage struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, failed, or skipped
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// selftestResult is the --output json schema of the selftest command.
+type selftestResult struct {
+	OK     bool            `json:"ok"`
+	Stages []selftestStage `json:"stages"`
+}
+
+// selftestOptions selects the store and the optional Quaily round trip.
+type selftestOptions struct {
+	RedisDB       int // negative uses an in-memory Redis
+	QuailyChannel string
+	QuailyProfile string
+	WithQuaily    bool
+}
+
+// selftestSkip is returned by a stage that does not apply; its text is the detail.
+type selftestSkip string
+
+func (s selftestSkip) Error() string { return string(s) }
+
+// selftestRun carries what one stage hands to the next.
+type selftestRun struct {
+	cfg     config.Config
+	opts    selftestOptions
+	now     time.Time
+	store   *storage.RedisStore
+	build   worker.BuildResult
+	doc     markdown.Document
+	cleanup []func()
+}
+
+// selftestCmd runs the whole digest pipeline on bundled items.
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run the digest pipeline end to end on bundled items",
+	Long: "Load bundled items into an in-memory Redis (or the empty Redis DB index given with --redis-db, flushed\n" +
+		"afterwards), build a digest for a synthetic channel with the title-based fallback summaries in every\n" +
+		"format, parse the Markdown back, and check its frontmatter and items. With --with-quaily a draft of it is\n" +
+		"created on --quaily-channel, read back, and deleted. Nothing is published or delivered.\n" +
+		"Exit status: 0 when every stage passed, 1 otherwise; the report names the failed stage.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if selftestWithQuaily && strings.TrimSpace(selftestQuailyChannel) == "" {
+			return errors.New("--with-quaily needs --quaily-channel (a test channel the draft is created on)")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+		res := runSelftest(ctx, GetConfig(), selftestOptions{
+			RedisDB:       selftestRedisDB,
+			QuailyChannel: strings.TrimSpace(selftestQuailyChannel),
+			QuailyProfile: selftestQuailyProfile,
+			WithQuaily:    selftestWithQuaily,
+		})
+		if err := emit(cmd, res, func(w io.Writer) {
+			failed := ""
+			for _, st := range res.Stages {
+				line := fmt.Sprintf("%-8s %-12s %s", st.Status, st.Name, st.Detail)
+				if st.Error != "" {
+					line = fmt.Sprintf("%-8s %-12s %s", st.Status, st.Name, st.Error)
+					failed = st.Name
+				}
+				fmt.Fprintln(w, strings.TrimRight(line, " "))
+			}
+			if failed != "" {
+				fmt.Fprintf(w, "selftest failed at %s\n", failed)
+			} else {
+				fmt.Fprintln(w, "selftest passed")
+			}
+		}); err != nil {
+			return err
+		}
+		if !res.OK {
+			return exitWith(cmd, 1)
+		}
+		return nil
+	},
+}
+
+// runSelftest runs the stages in order; after a failure the rest are skipped.
+func runSelftest(ctx context.Context, cfg config.Config, opts selftestOptions) selftestResult {
+	r := &selftestRun{cfg: cfg, opts: opts, now: time.Now()}
+	defer func() {
+		for i := len(r.cleanup) - 1; i >= 0; i-- {
+			r.cleanup[i]()
+		}
+	}()
+	stages := []struct {
+		name string
+		run  func(context.Context) (string, error)
+	}{
+		{"config", r.checkConfig},
+		{"store", r.openStore},
+		{"fixtures", r.loadFixtures},
+		{"build", r.buildDigest},
+		{"parse", r.parseDigest},
+		{"frontmatter", r.checkDigest},
+		{"quaily", r.roundTripQuaily},
+	}
+	res := selftestResult{OK: true}
+	for _, s := range stages {
+		st := selftestStage{Name: s.name, Status: selftestSkipped}
+		if res.OK {
+			detail, err := s.run(ctx)
+			var skip selftestSkip
+			switch {
+			case errors.As(err, &skip):
+				st.Detail = string(skip)
+			case err != nil:
+				st.Status, st.Error, res.OK = selftestFailed, err.Error(), false
+			default:
+				st.Status, st.Detail = selftestOK, detail
+			}
+		}
+		res.Stages = append(res.Stages, st)
+	}
+	return res
+}
+
+func (r *selftestRun) checkConfig(ctx context.Context) (string, error) {
+	if err := r.cfg.Validate(mockSourcesDir != ""); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d channels valid", len(r.cfg.Newsletters.Channels)), nil
+}
+
+// openStore starts an in-memory Redis or connects to the disposable DB index, which
+// must be empty so that flushing it afterwards loses nothing.
+func (r *selftestRun) openStore(ctx context.Context) (string, error) {
+	if r.opts.RedisDB < 0 {
+		mr, err := miniredis.Run()
+		if err != nil {
+			return "", err
+		}
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		r.cleanup = append(r.cleanup, mr.Close, func() { rdb.Close() })
+		r.store = newStore(r.cfg, rdb)
+		return "in-memory redis", nil
+	}
+	rc := r.cfg.Redis
+	rc.DB = r.opts.RedisDB
+	rdb := redisclient.New(rc)
+	n, err := rdb.DBSize(ctx).Result()
+	if err != nil {
+		rdb.Close()
+		return "", err
+	}
+	if n > 0 {
+		rdb.Close()
+		return "", fmt.Errorf("redis db %d at %s holds %d keys; pick an unused index", rc.DB, rc.Addr, n)
+	}
+	r.cleanup = append(r.cleanup, func() {
+		// The run's own context may have expired by now.
+		fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := rdb.FlushDB(fctx).Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "selftest: flush redis db %d: %v\n", rc.DB, err)
+		}
+		rdb.Close()
+	})
+	r.store = newStore(r.cfg, rdb)
+	return fmt.Sprintf("redis db %d at %s (flushed afterwards)", rc.DB, rc.Addr), nil
+}
+
+// loadFixtures stores the bundled items as today's V2EX topics, an hour apart.
+func (r *selftestRun) loadFixtures(ctx context.Context) (string, error) {
+	var items []model.NewsItem
+	if err := json.Unmarshal(selftestItems, &items); err != nil {
+		return "", fmt.Errorf("decode bundled items: %w", err)
+	}
+	key := period.Key(period.Daily, r.now.UTC())
+	for i, it := range items {
+		it.Source = "v2ex"
+		it.CreatedAt = r.now.Add(-time.Duration(i+1) * time.Hour).UTC()
+		if err := r.store.AddNews(ctx, "v2ex", key, it, float64(len(items)-i)); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%d items in period %s", len(items), key), nil
+}
+
+// buildDigest runs one builder pass for the synthetic channel. Without a
+// summarizer the builder falls back to title-based summaries, and without a Quaily
+// client it only writes files.
+func (r *selftestRun) buildDigest(ctx context.Context) (string, error) {
+	dir, err := os.MkdirTemp("", "quaily-selftest-")
+	if err != nil {
+		return "", err
+	}
+	r.cleanup = append(r.cleanup, func() { os.RemoveAll(dir) })
+	b := &worker.NewsletterBuilder{
+		Store:      r.store,
+		Source:     "v2ex",
+		Channel:    selftestChannel,
+		Frequency:  period.Daily,
+		TopN:       5,
+		MinItems:   3,
+		OutputDir:  dir,
+		Interval:   time.Hour,
+		Nodes:      []string{"selftest"},
+		Preface:    "Selftest digest.",
+		BaseURL:    "https://www.v2ex.com",
+		Language:   "English",
+		Formats:    []string{newsletter.FormatMarkdown, newsletter.FormatHTML, newsletter.FormatJSON},
+		ShowAuthor: true,
+		Now:        func() time.Time { return r.now },
+	}
+	res, err := b.RunOnce(ctx)
+	if err != nil {
+		return "", err
+	}
+	if res.Path == "" {
+		return "", fmt.Errorf("no digest written (candidates %d, after filters %d, skipped %q)", res.Candidates, res.Filtered, res.Skipped)
+	}
+	r.build = res
+	return fmt.Sprintf("%d candidates, %d after filters, %d formats, title-based summaries", res.Candidates, res.Filtered, len(res.Paths)), nil
+}
+
+func (r *selftestRun) parseDigest(ctx context.Context) (string, error) {
+	doc, err := markdown.ParseFile(r.build.Path)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(doc.Body) == "" {
+		return "", errors.New("empty body")
+	}
+	r.doc = doc
+	return fmt.Sprintf("%d frontmatter keys, %d bytes of body", len(doc.Frontmatter), len(doc.Body)), nil
+}
+
+// checkDigest validates the frontmatter, that every item the publish metadata lists
+// is linked in the body, and the other formats.
+func (r *selftestRun) checkDigest(ctx context.Context) (string, error) {
+	var errs []error
+	for _, k := range []string{"title", "slug", "datetime", "summary"} {
+		if v, ok := r.doc.Frontmatter[k]; !ok || strings.TrimSpace(fmt.Sprint(v)) == "" {
+			errs = append(errs, fmt.Errorf("frontmatter %s is missing", k))
+		}
+	}
+	if slug := fmt.Sprint(r.doc.Frontmatter["slug"]); slug != digestSlug(period.Daily, r.now) {
+		errs = append(errs, fmt.Errorf("frontmatter slug %q, want %q", slug, digestSlug(period.Daily, r.now)))
+	}
+	meta, ok, err := r.store.GetPublishMeta(ctx, selftestChannel, r.build.Period)
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case !ok:
+		errs = append(errs, fmt.Errorf("period %s not marked published", r.build.Period))
+	case len(meta.ItemIDs) != 5:
+		errs = append(errs, fmt.Errorf("%d items published, want 5", len(meta.ItemIDs)))
+	}
+	for _, id := range meta.ItemIDs {
+		it, err := r.store.GetItem(ctx, "v2ex", id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %s: %w", id, err))
+			continue
+		}
+		if !strings.Contains(r.doc.Body, it.URL) {
+			errs = append(errs, fmt.Errorf("item %s is not linked in the body", id))
+		}
+	}
+	for format, p := range r.build.Paths {
+		b, err := os.ReadFile(p)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case len(b) == 0:
+			errs = append(errs, fmt.Errorf("%s output is empty", format))
+		case format == newsletter.FormatJSON && !json.Valid(b):
+			errs = append(errs, errors.New("json output is not valid JSON"))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%q with %d items", r.doc.Frontmatter["title"], len(meta.ItemIDs)), nil
+}
+
+// roundTripQuaily creates the digest as a draft under a unique slug, reads it back,
+// and deletes it, even when the read fails.
+func (r *selftestRun) roundTripQuaily(ctx context.Context) (string, error) {
+	if !r.opts.WithQuaily {
+		return "", selftestSkip("pass --with-quaily --quaily-channel <test channel> to round-trip a draft")
+	}
+	qc, err := newQuailyClient(r.cfg, r.opts.QuailyProfile, 20*time.Second)
+	if err != nil {
+		return "", err
+	}
+	slug := fmt.Sprintf("selftest-%d", r.now.Unix())
+	id, body, err := quaily.DraftMarkdownFile(ctx, qc, r.build.Path, r.opts.QuailyChannel, slug)
+	if err != nil {
+		return "", fmt.Errorf("create draft: %w", err)
+	}
+	var errs []error
+	post, ok, err := qc.GetPostBySlug(ctx, r.opts.QuailyChannel, slug)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("read draft: %w", err))
+	case !ok:
+		errs = append(errs, fmt.Errorf("draft %s not found after creating it", slug))
+	case post.Published:
+		errs = append(errs, fmt.Errorf("draft %s is published", slug))
+	case !quaily.SameContent(post.Content, body):
+		errs = append(errs, fmt.Errorf("draft %s reads back with different content", slug))
+	}
+	if err := qc.DeletePost(ctx, r.opts.QuailyChannel, id); err != nil {
+		errs = append(errs, fmt.Errorf("delete draft %s (post %s): %w", slug, id, err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("draft %s created on %s, read back, and deleted", slug, r.opts.QuailyChannel), nil
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().IntVar(&selftestRedisDB, "redis-db", -1, "use this empty DB index of the configured Redis (flushed afterwards) instead of an in-memory Redis")
+	selftestCmd.Flags().BoolVar(&selftestWithQuaily, "with-quaily", false, "also create the digest as a draft on --quaily-channel, read it back, and delete it")
+	selftestCmd.Flags().StringVar(&selftestQuailyChannel, "quaily-channel", "", "Quaily test channel slug for --with-quaily")
+	selftestCmd.Flags().StringVar(&selftestQuailyProfile, "quaily-profile", "", "quaily.profiles entry for --with-quaily (default: quaily.base_url/api_key)")
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"quaily-journalist/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func stageStatuses(res selftestResult) map[string]string {
+	m := map[string]string{}
+	for _, st := range res.Stages {
+		m[st.Name] = st.Status
+	}
+	return m
+}
+
+func TestSelftestPasses(t *testing.T) {
+	res := runSelftest(context.Background(), config.Config{}, selftestOptions{RedisDB: -1})
+	if !res.OK {
+		t.Fatalf("selftest failed: %+v", res.Stages)
+	}
+	got := stageStatuses(res)
+	for _, name := range []string{"config", "store", "fixtures", "build", "parse", "frontmatter"} {
+		if got[name] != selftestOK {
+			t.Errorf("stage %s = %s", name, got[name])
+		}
+	}
+	if got["quaily"] != selftestSkipped {
+		t.Errorf("quaily stage without --with-quaily = %s", got["quaily"])
+	}
+}
+
+// A disposable Redis DB that already holds keys is refused before anything is
+// written to it, and the later stages are skipped.
+func TestSelftestRefusesNonEmptyRedisDB(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.Select(3)
+	mr.Set("someone:else", "x")
+	cfg := config.Config{Redis: config.RedisConfig{Addr: mr.Addr()}}
+	res := runSelftest(context.Background(), cfg, selftestOptions{RedisDB: 3})
+	got := stageStatuses(res)
+	if res.OK || got["store"] != selftestFailed || got["build"] != selftestSkipped {
+		t.Fatalf("stages = %+v", res.Stages)
+	}
+	if !mr.Exists("someone:else") {
+		t.Error("the non-empty DB was flushed")
+	}
+
+	// An empty index is used and flushed afterwards.
+	res = runSelftest(context.Background(), cfg, selftestOptions{RedisDB: 4})
+	mr.Select(4)
+	if !res.OK || len(mr.Keys()) != 0 {
+		t.Fatalf("selftest on db 4 = %+v, keys left %v", res.Stages, mr.Keys())
+	}
+}
+
+// With Quaily the digest is created as a draft on the test channel, read back, and
+// deleted, and nothing is published.
+func TestSelftestQuailyRoundTrip(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	var content string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/lists/test-ch/posts":
+			var params map[string]any
+			json.NewDecoder(r.Body).Decode(&params)
+			content, _ = params["content"].(string)
+			if !strings.HasPrefix(params["slug"].(string), "selftest-") {
+				http.Error(w, "unexpected slug", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"data": {"id": 42}}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/lists/test-ch/posts/selftest-"):
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"id": 42, "content": content}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/lists/test-ch/posts/42":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "unexpected", http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.Config{Quaily: config.QuailyConfig{BaseURL: srv.URL, APIKey: "k"}}
+	res := runSelftest(context.Background(), cfg, selftestOptions{RedisDB: -1, WithQuaily: true, QuailyChannel: "test-ch"})
+	if !res.OK || stageStatuses(res)["quaily"] != selftestOK {
+		t.Fatalf("stages = %+v", res.Stages)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 3 || !strings.HasPrefix(calls[2], "DELETE ") {
+		t.Errorf("quaily calls = %v; want create, get, delete", calls)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"quaily-journalist/internal/quaily"
@@ -11,6 +12,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var sendProfile string
+
 var sendCmd = &cobra.Command{
 	Use:   "send <path_or_slug> <channel_slug>",
 	Short: "Deliver a Quaily post by slug or markdown file",
@@ -22,11 +25,11 @@ var sendCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := GetConfig()
-		if cfg.Quaily.BaseURL == "" || cfg.Quaily.APIKey == "" {
-			return fmt.Errorf("quaily config missing: set quaily.base_url and quaily.api_key in config.yaml")
-		}
 		tm := 20 * time.Second
-		cli := quaily.New(cfg.Quaily.BaseURL, cfg.Quaily.APIKey, tm)
+		cli, err := newQuailyClient(cfg, sendProfile, tm)
+		if err != nil {
+			return err
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), tm)
 		defer cancel()
 
@@ -35,11 +38,20 @@ var sendCmd = &cobra.Command{
 		if err := quaily.DeliverMarkdownOrSlug(ctx, cli, pathOrSlug, channelSlug); err != nil {
 			return err
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Delivered post '%s' on channel %s\n", pathOrSlug, channelSlug)
-		return nil
+		return emit(cmd, sendResult{Post: pathOrSlug, Channel: channelSlug, Delivered: true}, func(w io.Writer) {
+			fmt.Fprintf(w, "Delivered post '%s' on channel %s\n", pathOrSlug, channelSlug)
+		})
 	},
 }
 
+// sendResult is the --output json schema of the send command.
+type sendResult struct {
+	Post      string `json:"post"`
+	Channel   string `json:"channel"`
+	Delivered bool   `json:"delivered"`
+}
+
 func init() {
 	rootCmd.AddCommand(sendCmd)
+	sendCmd.Flags().StringVar(&sendProfile, "profile", "", "quaily.profiles entry to deliver with (default: the flat quaily.base_url/api_key)")
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"log"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -12,11 +11,14 @@ import (
 	"time"
 
 	"quaily-journalist/internal/ai"
-	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/httpclient"
 	"quaily-journalist/internal/imagegen"
+	"quaily-journalist/internal/newsletter"
 	"quaily-journalist/internal/quaily"
 	"quaily-journalist/internal/redisclient"
 	"quaily-journalist/internal/scrape"
+	"quaily-journalist/internal/selection"
 	"quaily-journalist/internal/storage"
 	"quaily-journalist/internal/v2ex"
 	"quaily-journalist/worker"
@@ -29,115 +31,456 @@ var serveCmd = &cobra.Command{
 	Short: "Run the service workers",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := GetConfig()
+		if err := cfg.Validate(mockSourcesDir != ""); err != nil {
+			return fmt.Errorf("invalid configuration:\n%w", err)
+		}
 		// Redis client
 		rdb := redisclient.New(cfg.Redis)
 		defer rdb.Close()
-		store := storage.NewRedisStore(rdb)
+		store := newStore(cfg, rdb)
 
 		var collector *worker.V2EXCollector
 		var hnCollector *worker.HNCollector
+		var rssCollector *worker.RSSCollector
+		var lobstersCollector *worker.LobstersCollector
+		var redditCollector *worker.RedditCollector
+		var githubCollector *worker.GitHubTrendingCollector
+		var productHuntCollector *worker.ProductHuntCollector
+		var arxivCollector *worker.ArxivCollector
+		var mastodonCollector *worker.MastodonCollector
+		var blueskyCollector *worker.BlueskyCollector
+		var stackOverflowCollector *worker.StackOverflowCollector
+		var jsonFeedCollector *worker.JSONFeedCollector
+		var youTubeCollector *worker.YouTubeCollector
+		var reloads []nodeReload // one per running collector polling nodes, for SIGHUP
 
 		var nodes []string
 
-		var v2c *v2ex.Client
-		var hnc *hackernews.Client
+		var v2c worker.V2EXSource
+		var hnc worker.HNSource
 
 		// V2EX collector setup with union of nodes across channels using v2ex
-		if cfg.Sources.V2EX.Token != "" {
-			v2c = v2ex.NewClient(cfg.Sources.V2EX.BaseURL, cfg.Sources.V2EX.Token)
+		if cfg.Sources.V2EX.Token != "" || mockSourcesDir != "" {
+			c, err := newV2EXSource(cfg)
+			if err != nil {
+				return err
+			}
+			v2c = c
 			interval, err := time.ParseDuration(cfg.Sources.V2EX.FetchInterval)
 			if err != nil {
 				return err
 			}
 			// gather nodes from channels where source==v2ex
-			nodeSet := map[string]struct{}{}
-			for _, ch := range cfg.Newsletters.Channels {
-				if strings.ToLower(ch.Source) == "v2ex" {
-					for _, n := range ch.Nodes {
-						n = strings.TrimSpace(n)
-						if n == "" {
-							continue
-						}
-						nodeSet[n] = struct{}{}
-					}
-				}
+			nodes = v2exNodeUnion(cfg)
+			scorer, err := sourceScorer(cfg, "v2ex")
+			if err != nil {
+				return err
 			}
-			nodes := make([]string, 0, len(nodeSet))
-			for n := range nodeSet {
-				nodes = append(nodes, n)
+			var searchInterval time.Duration
+			if s := strings.TrimSpace(cfg.Sources.V2EX.SearchInterval); s != "" {
+				if searchInterval, err = time.ParseDuration(s); err != nil {
+					return fmt.Errorf("invalid sources.v2ex.search_interval: %w", err)
+				}
 			}
 			collector = &worker.V2EXCollector{
-				Client:   v2c,
-				Store:    store,
-				Nodes:    nodes,
-				Interval: interval,
+				Client:          v2c,
+				Ranking:         scorer,
+				Store:           store,
+				Nodes:           nodes,
+				Interval:        interval,
+				MaxContentRunes: cfg.Sources.V2EX.MaxContentRunes,
+				ResumeRatio:     cfg.Sources.ResumeRatio,
+				SearchInterval:  searchInterval,
+
+				IncludeSupplements: cfg.Sources.V2EX.IncludeSupplements,
+				IncludePoints:      cfg.Sources.V2EX.IncludePoints,
+				ArchiveRaw:         cfg.Sources.ArchiveRaw,
+				QuietHours:         quietHours(cfg.SourceQuietHours("v2ex")),
+			}
+			if collector.IncludeSupplements && cfg.Sources.V2EX.Token == "" {
+				slog.Warn("serve: sources.v2ex.include_supplements needs sources.v2ex.token; supplements disabled")
+				collector.IncludeSupplements = false
+			}
+			if collector.IncludePoints && cfg.Sources.V2EX.Token == "" && mockSourcesDir == "" {
+				slog.Warn("serve: sources.v2ex.include_points needs sources.v2ex.token; points disabled")
+				collector.IncludePoints = false
 			}
+			reloads = append(reloads, func(ctx context.Context, cfg config.Config) {
+				nodes := v2exNodeUnion(cfg)
+				added := collector.SetNodes(nodes)
+				prefetchV2EXNodeTitles(ctx, store, v2c, added)
+				slog.Info("reload: v2ex collector nodes updated", "nodes", nodes, "added", added)
+			})
 		}
 
-		if cfg.Sources.HN.BaseAPI != "" {
+		if cfg.Sources.HN.BaseAPI != "" || mockSourcesDir != "" {
 			// Hacker News collector setup: use HN channel nodes directly as lists
-			hnc = hackernews.NewClient(cfg.Sources.HN.BaseAPI)
+			c, err := newHNSource(cfg)
+			if err != nil {
+				return err
+			}
+			hnc = c
 			hnInterval, err := time.ParseDuration(cfg.Sources.HN.FetchInterval)
 			if err != nil {
 				return err
 			}
+			hnStaleness, err := time.ParseDuration(cfg.Sources.HN.ItemStaleness)
+			if err != nil {
+				return fmt.Errorf("invalid sources.hackernews.item_staleness: %w", err)
+			}
 			// Gather union of nodes for HN channels; treat them as lists directly
-			hnNodeSet := map[string]struct{}{}
-			for _, ch := range cfg.Newsletters.Channels {
-				if strings.ToLower(ch.Source) == "hackernews" {
-					for _, n := range ch.Nodes {
-						n = strings.ToLower(strings.TrimSpace(n))
-						if n == "" {
-							continue
-						}
-						hnNodeSet[n] = struct{}{}
-					}
+			hnLists := hnListUnion(cfg)
+			scorer, err := sourceScorer(cfg, "hackernews")
+			if err != nil {
+				return err
+			}
+			hnCollector = &worker.HNCollector{
+				Client:        hnc,
+				Ranking:       scorer,
+				Store:         store,
+				Lists:         hnLists,
+				Interval:      hnInterval,
+				LimitPerList:  64,
+				ItemStaleness: hnStaleness,
+				ResumeRatio:   cfg.Sources.ResumeRatio,
+				ArchiveRaw:    cfg.Sources.ArchiveRaw,
+				QuietHours:    quietHours(cfg.SourceQuietHours("hackernews")),
+			}
+			reloads = append(reloads, reloadNodes("hackernews", hnCollector, func(cfg config.Config) ([]string, error) { return hnListUnion(cfg), nil }))
+		}
+
+		if feeds := rssFeeds(cfg); len(feeds) > 0 {
+			src, err := newRSSSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.RSS.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.rss.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "rss")
+			if err != nil {
+				return err
+			}
+			rssCollector = &worker.RSSCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Feeds:       feeds,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("rss")),
+			}
+			reloads = append(reloads, reloadNodes("rss", rssCollector, func(cfg config.Config) ([]worker.RSSFeed, error) { return rssFeeds(cfg), nil }))
+		}
+
+		if cfg.Sources.Lobsters.BaseURL != "" || (mockSourcesDir != "" && hasSource(cfg, "lobsters")) {
+			src, err := newLobstersSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.Lobsters.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.lobsters.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "lobsters")
+			if err != nil {
+				return err
+			}
+			lobstersCollector = &worker.LobstersCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Lists:       cfg.Sources.Lobsters.Lists,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("lobsters")),
+			}
+			reloads = append(reloads, reloadNodes("lobsters", lobstersCollector, func(cfg config.Config) ([]string, error) { return cfg.Sources.Lobsters.Lists, nil }))
+		}
+
+		if subs := sourceNodeUnion(cfg, "reddit"); len(subs) > 0 {
+			src, err := newRedditSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.Reddit.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.reddit.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "reddit")
+			if err != nil {
+				return err
+			}
+			redditCollector = &worker.RedditCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Subreddits:  subs,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("reddit")),
+			}
+			reloads = append(reloads, reloadNodes("reddit", redditCollector, func(cfg config.Config) ([]string, error) { return sourceNodeUnion(cfg, "reddit"), nil }))
+		}
+
+		if langs := sourceNodeUnion(cfg, "github"); len(langs) > 0 {
+			src, err := newGitHubSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.GitHub.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.github.fetch_interval: %w", err)
+			}
+			within, err := time.ParseDuration(cfg.Sources.GitHub.CreatedWithin)
+			if err != nil {
+				return fmt.Errorf("invalid sources.github.created_within: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "github")
+			if err != nil {
+				return err
+			}
+			githubCollector = &worker.GitHubTrendingCollector{
+				Client:        src,
+				Ranking:       scorer,
+				Store:         store,
+				Languages:     langs,
+				Interval:      interval,
+				CreatedWithin: within,
+				ResumeRatio:   cfg.Sources.ResumeRatio,
+				ArchiveRaw:    cfg.Sources.ArchiveRaw,
+				QuietHours:    quietHours(cfg.SourceQuietHours("github")),
+			}
+			reloads = append(reloads, reloadNodes("github", githubCollector, func(cfg config.Config) ([]string, error) { return sourceNodeUnion(cfg, "github"), nil }))
+		}
+
+		if hasSource(cfg, "producthunt") && (cfg.Sources.ProductHunt.Token != "" || mockSourcesDir != "") {
+			src, err := newProductHuntSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.ProductHunt.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.producthunt.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "producthunt")
+			if err != nil {
+				return err
+			}
+			productHuntCollector = &worker.ProductHuntCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("producthunt")),
+			}
+		}
+
+		if cats := sourceNodeUnion(cfg, "arxiv"); len(cats) > 0 {
+			src, err := newArxivSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.Arxiv.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.arxiv.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "arxiv")
+			if err != nil {
+				return err
+			}
+			arxivCollector = &worker.ArxivCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Categories:  cats,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("arxiv")),
+			}
+			reloads = append(reloads, reloadNodes("arxiv", arxivCollector, func(cfg config.Config) ([]string, error) { return sourceNodeUnion(cfg, "arxiv"), nil }))
+		}
+
+		if hasSource(cfg, "mastodon") {
+			instances, err := newMastodonSources(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.Mastodon.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.mastodon.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "mastodon")
+			if err != nil {
+				return err
+			}
+			if len(instances) > 0 {
+				mastodonCollector = &worker.MastodonCollector{
+					Instances:   instances,
+					Ranking:     scorer,
+					Store:       store,
+					Interval:    interval,
+					ResumeRatio: cfg.Sources.ResumeRatio,
+					ArchiveRaw:  cfg.Sources.ArchiveRaw,
+					QuietHours:  quietHours(cfg.SourceQuietHours("mastodon")),
 				}
+				reloads = append(reloads, reloadNodes("mastodon", mastodonCollector, newMastodonSources))
 			}
-			hnLists := make([]string, 0, len(hnNodeSet))
-			for n := range hnNodeSet {
-				hnLists = append(hnLists, n)
+		}
+
+		if feeds := blueskyFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "bluesky") {
+			src, err := newBlueskySource(cfg)
+			if err != nil {
+				return err
 			}
-			if len(hnLists) == 0 {
-				hnLists = []string{"top"}
+			interval, err := time.ParseDuration(cfg.Sources.Bluesky.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.bluesky.fetch_interval: %w", err)
 			}
-			hnCollector = &worker.HNCollector{
-				Client:       hnc,
-				Store:        store,
-				Lists:        hnLists,
-				Interval:     hnInterval,
-				LimitPerList: 64,
+			scorer, err := sourceScorer(cfg, "bluesky")
+			if err != nil {
+				return err
+			}
+			blueskyCollector = &worker.BlueskyCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Feeds:       feeds,
+				Limit:       cfg.Sources.Bluesky.Limit,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("bluesky")),
+			}
+			reloads = append(reloads, reloadNodes("bluesky", blueskyCollector, func(cfg config.Config) ([]worker.BlueskyFeed, error) { return blueskyFeeds(cfg), nil }))
+		}
+
+		if tags := stackOverflowTags(cfg); len(tags) > 0 {
+			src, err := newStackOverflowSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.StackOverflow.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.stackoverflow.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "stackoverflow")
+			if err != nil {
+				return err
+			}
+			stackOverflowCollector = &worker.StackOverflowCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Tags:        tags,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("stackoverflow")),
+			}
+			reloads = append(reloads, reloadNodes("stackoverflow", stackOverflowCollector, func(cfg config.Config) ([]string, error) { return stackOverflowTags(cfg), nil }))
+		}
+
+		if feeds := jsonFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "jsonfeed") {
+			src, err := newJSONFeedSource(cfg)
+			if err != nil {
+				return err
+			}
+			interval, err := time.ParseDuration(cfg.Sources.JSONFeed.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.jsonfeed.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "jsonfeed")
+			if err != nil {
+				return err
+			}
+			jsonFeedCollector = &worker.JSONFeedCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Feeds:       feeds,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("jsonfeed")),
+			}
+			reloads = append(reloads, reloadNodes("jsonfeed", jsonFeedCollector, func(cfg config.Config) ([]worker.JSONFeed, error) { return jsonFeeds(cfg), nil }))
+		}
+
+		if feeds := youTubeFeeds(cfg); len(feeds) > 0 && hasSource(cfg, "youtube") {
+			src, err := newYouTubeSource(cfg)
+			if err != nil {
+				return err
 			}
+			interval, err := time.ParseDuration(cfg.Sources.YouTube.FetchInterval)
+			if err != nil {
+				return fmt.Errorf("invalid sources.youtube.fetch_interval: %w", err)
+			}
+			scorer, err := sourceScorer(cfg, "youtube")
+			if err != nil {
+				return err
+			}
+			youTubeCollector = &worker.YouTubeCollector{
+				Client:      src,
+				Ranking:     scorer,
+				Store:       store,
+				Feeds:       feeds,
+				Interval:    interval,
+				ResumeRatio: cfg.Sources.ResumeRatio,
+				ArchiveRaw:  cfg.Sources.ArchiveRaw,
+				QuietHours:  quietHours(cfg.SourceQuietHours("youtube")),
+			}
+			reloads = append(reloads, reloadNodes("youtube", youTubeCollector, func(cfg config.Config) ([]worker.YouTubeFeed, error) { return youTubeFeeds(cfg), nil }))
 		}
 
 		var summarizer ai.Summarizer
-		if cfg.OpenAI.APIKey != "" {
-			summarizer = ai.NewOpenAI(ai.Config{APIKey: cfg.OpenAI.APIKey, Model: cfg.OpenAI.Model, BaseURL: cfg.OpenAI.BaseURL})
+		if cfg.OpenAI.APIKey != "" && !allMinimal(cfg.Newsletters.Channels) {
+			summarizer = newSummarizer(cfg, store)
 		}
 
-		// Quaily client (optional)
-		var qcli *quaily.Client
-		if strings.TrimSpace(cfg.Quaily.BaseURL) != "" && strings.TrimSpace(cfg.Quaily.APIKey) != "" {
-			tm := 20 * time.Second
-			qcli = quaily.New(cfg.Quaily.BaseURL, cfg.Quaily.APIKey, tm)
+		// Quaily clients (optional), one per profile the channels use
+		qclients, err := newChannelQuailyClients(cfg, 20*time.Second)
+		if err != nil {
+			return err
 		}
 
-		// Cache human-friendly node titles at init (best-effort)
-		for _, n := range nodes {
-			ctxNode, cancelNode := context.WithTimeout(context.Background(), 5*time.Second)
-			// Skip fetch if already cached
-			if t, _ := store.GetNodeTitle(ctxNode, "v2ex", n); strings.TrimSpace(t) == "" {
-				if title, err := v2c.NodeTitle(ctxNode, n); err == nil && strings.TrimSpace(title) != "" {
-					_ = store.SetNodeTitle(context.Background(), "v2ex", n, title, 30*24*time.Hour)
+		// Every outbound call from here on derives from ctx, so a signal during startup
+		// or a run stops in-flight requests instead of waiting out their timeouts.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// Signal handling for systemd; SIGHUP reloads collector nodes from the config file.
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		go func() {
+			for s := range sigc {
+				if s == syscall.SIGHUP {
+					reloadCollectorNodes(ctx, reloads)
+					continue
 				}
+				slog.Info("received signal, shutting down", "signal", s.String())
+				cancel()
+				return
 			}
-			cancelNode()
-		}
+		}()
+
+		// Cache human-friendly node titles at init (best-effort)
+		prefetchV2EXNodeTitles(ctx, store, v2c, nodes)
 
 		// Cloudflare client (optional) for content fallback on HN
 		var cfc *scrape.CloudflareClient
 		if strings.TrimSpace(cfg.Cloudflare.AccountID) != "" && strings.TrimSpace(cfg.Cloudflare.APIToken) != "" {
-			cfc = scrape.NewCloudflare(cfg.Cloudflare.AccountID, cfg.Cloudflare.APIToken, 20*time.Second)
+			c, err := newCloudflareClient(cfg)
+			if err != nil {
+				return err
+			}
+			cfc = c
 		}
 
 		var coverGen imagegen.Generator
@@ -150,6 +493,10 @@ var serveCmd = &cobra.Command{
 					timeout = d
 				}
 			}
+			hc, err := httpclient.New(cfg, httpclient.Susanoo, timeout)
+			if err != nil {
+				return err
+			}
 			gen, err := imagegen.NewSusanoo(imagegen.SusanooConfig{
 				BaseURL:     cfg.Susanoo.BaseURL,
 				APIKey:      cfg.Susanoo.APIKey,
@@ -157,6 +504,7 @@ var serveCmd = &cobra.Command{
 				AspectRatio: cfg.Susanoo.AspectRatio,
 				Timeout:     timeout,
 				WebPQuality: cfg.Susanoo.WebPQuality,
+				HTTPClient:  hc,
 			})
 			if err != nil {
 				return err
@@ -164,6 +512,24 @@ var serveCmd = &cobra.Command{
 			coverGen = gen
 		}
 
+		emailTargets, err := newEmailTargets(cfg)
+		if err != nil {
+			return err
+		}
+		telegramTargets, err := newTelegramTargets(cfg)
+		if err != nil {
+			return err
+		}
+		chatTargets, err := newChatTargets(cfg)
+		if err != nil {
+			return err
+		}
+
+		notifier, err := newNotifier(cfg)
+		if err != nil {
+			return err
+		}
+
 		// Newsletter builders (one per channel)
 		var builders []worker.Worker
 		for _, ch := range cfg.Newsletters.Channels {
@@ -171,18 +537,70 @@ var serveCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("invalid item_skip_duration for channel %s: %w", ch.Name, err)
 			}
-			baseURL := cfg.Sources.V2EX.BaseURL
-			if strings.ToLower(ch.Source) == "hackernews" {
-				baseURL = "https://news.ycombinator.com"
+			if err := worker.CheckOutputLayout(ch.OutputLayout); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
 			}
+			formats, err := newsletter.ParseFormats(ch.Formats)
+			if err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			if err := newsletter.CheckRender(formats); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			chScorer, err := channelScorer(cfg, ch)
+			if err != nil {
+				return err
+			}
+			if err := selection.FromChannel(cfg.ResolveChannel(ch)).Check(); err != nil {
+				return err
+			}
+			if err := worker.CheckOnInsufficientItems(ch.OnInsufficientItems); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			if err := newsletter.CheckOversize(ch.OnOversize); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			if err := worker.CheckRetentionMode(ch.Retention.Mode); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			if err := newsletter.CheckStyle(ch.Style); err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			// Minimal channels never call AI, scrape, or generate a cover.
+			chSummarizer, chCloudflare, chCoverGen := summarizer, cfc, coverGen
+			if ch.Style == newsletter.StyleMinimal {
+				chSummarizer, chCloudflare, chCoverGen = nil, nil, nil
+			}
+			previewUntil, err := ch.PreviewUntilTime()
+			if err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			qcli := qclients[ch.Name]
+			if qcli != nil && time.Now().Before(previewUntil) {
+				slog.Warn("serve: PREVIEW MODE: channel publishes to the preview Quaily channel", "channel", ch.Name, "preview_channel", cfg.Quaily.PreviewChannelSlug, "until", previewUntil)
+			}
+			var siteBaseURL string
+			if ch.Navigation {
+				siteBaseURL = cfg.Quaily.SiteBaseURL
+			}
+			var topComments worker.HNSource
+			if ch.IncludeTopComment && strings.ToLower(ch.Source) == "hackernews" && ch.Style != newsletter.StyleMinimal {
+				if hnc == nil {
+					slog.Warn("serve: include_top_comment needs the hacker news source; highlights disabled", "channel", ch.Name)
+				} else {
+					topComments = hnc
+				}
+			}
+			baseURL := sourceBaseURL(cfg, ch.Source)
+			rc := cfg.ResolveChannel(ch)
 			builders = append(builders, &worker.NewsletterBuilder{
 				Store:         store,
-				Source:        strings.ToLower(ch.Source),
-				Channel:       ch.Name,
-				Frequency:     strings.ToLower(ch.Frequency),
-				TopN:          ch.TopN,
-				MinItems:      ch.MinItems,
-				OutputDir:     cfg.Newsletters.OutputDir,
+				Source:        rc.Source,
+				Channel:       rc.Name,
+				Frequency:     rc.Frequency,
+				TopN:          rc.TopN,
+				MinItems:      rc.MinItems,
+				OutputDir:     rc.OutputDir,
 				Interval:      30 * time.Minute,
 				Nodes:         ch.Nodes,
 				SkipDuration:  sd,
@@ -190,13 +608,49 @@ var serveCmd = &cobra.Command{
 				Postscript:    ch.Template.Postscript,
 				BaseURL:       baseURL,
 				Language:      ch.Language,
-				Summarizer:    summarizer,
+				Summarizer:    chSummarizer,
 				TitleTemplate: ch.Template.Title,
 				Quaily:        qcli,
-				Cloudflare:    cfc,
-				CoverGen:      coverGen,
+				Cloudflare:    chCloudflare,
+				CoverGen:      chCoverGen,
 				CoverPrompt:   cfg.Susanoo.PromptTemplate,
 				CoverAspect:   cfg.Susanoo.AspectRatio,
+
+				MinContentRunesForAI: rc.MinContentRunesForAI,
+				QualityGate:          newQualityGate(ch, chSummarizer, store),
+				ShowAuthor:           ch.ShowAuthor,
+				TitleDedupThreshold:  ch.TitleDedupThreshold,
+				OutputLayout:         ch.OutputLayout,
+				Formats:              ch.Formats,
+				SEO:                  ch.Frontmatter.SEO,
+				PullQuote:            ch.PullQuote,
+				WhyItMatters:         ch.WhyItMatters,
+				Email:                ch.Email.Enabled(),
+				Telegram:             ch.Telegram.Enabled(),
+				Ranking:              chScorer,
+				NodeWeights:          ch.NodeWeights,
+				RepeatPenalty:        ch.RepeatPenalty,
+				TopComments:          topComments,
+				OnInsufficientItems:  ch.OnInsufficientItems,
+				Notifier:             notifier,
+				MaxContentBytes:      cfg.Quaily.MaxContentBytes,
+				OnOversize:           ch.OnOversize,
+				MaxFetchDepth:        ch.MaxFetchDepth,
+				PreviewChannel:       cfg.Quaily.PreviewChannelSlug,
+				PreviewUntil:         previewUntil,
+				ItemOrder:            ch.ItemOrder,
+				Discord:              ch.Discord.Enabled(),
+				Slack:                ch.Slack.Enabled(),
+				PinLabel:             ch.PinLabel,
+				MinReplies:           ch.MinReplies,
+				DeriveFrom:           strings.TrimSpace(ch.DeriveFrom),
+				KeepFiles:            ch.Retention.KeepFiles,
+				RetentionMode:        ch.Retention.Mode,
+				Style:                ch.Style,
+				SiteBaseURL:          siteBaseURL,
+				NoPostSummary:        !ch.Summary.PostEnabled(),
+				NoShortSummary:       !ch.Summary.ShortEnabled(),
+				QuietHours:           quietHours(ch.QuietHours),
 			})
 		}
 
@@ -209,27 +663,320 @@ var serveCmd = &cobra.Command{
 			slog.Info("starting Hacker News collector for lists", "lists", hnCollector.Lists)
 			ws = append(ws, hnCollector)
 		}
+		if rssCollector != nil {
+			slog.Info("starting RSS collector for feeds", "feeds", len(rssCollector.Feeds))
+			ws = append(ws, rssCollector)
+		}
+		if lobstersCollector != nil {
+			slog.Info("starting Lobste.rs collector for lists", "lists", lobstersCollector.Lists)
+			ws = append(ws, lobstersCollector)
+		}
+		if redditCollector != nil {
+			slog.Info("starting Reddit collector for subreddits", "subreddits", redditCollector.Subreddits)
+			ws = append(ws, redditCollector)
+		}
+		if githubCollector != nil {
+			slog.Info("starting GitHub trending collector for languages", "languages", githubCollector.Languages)
+			ws = append(ws, githubCollector)
+		}
+		if productHuntCollector != nil {
+			slog.Info("starting Product Hunt collector", "interval", productHuntCollector.Interval)
+			ws = append(ws, productHuntCollector)
+		}
+		if arxivCollector != nil {
+			slog.Info("starting arXiv collector for categories", "categories", arxivCollector.Categories)
+			ws = append(ws, arxivCollector)
+		}
+		if mastodonCollector != nil {
+			slog.Info("starting Mastodon collector for instances", "instances", mastodonCollector.Hosts())
+			ws = append(ws, mastodonCollector)
+		}
+		if blueskyCollector != nil {
+			slog.Info("starting Bluesky collector for feeds", "nodes", blueskyCollector.Nodes())
+			ws = append(ws, blueskyCollector)
+		}
+		if stackOverflowCollector != nil {
+			slog.Info("starting Stack Overflow collector for tags", "tags", stackOverflowCollector.Tags)
+			ws = append(ws, stackOverflowCollector)
+		}
+		if jsonFeedCollector != nil {
+			slog.Info("starting JSON Feed collector for feeds", "feeds", len(jsonFeedCollector.Feeds))
+			ws = append(ws, jsonFeedCollector)
+		}
+		if youTubeCollector != nil {
+			slog.Info("starting YouTube collector for channels and playlists", "feeds", len(youTubeCollector.Feeds), "api", cfg.Sources.YouTube.APIKey != "")
+			ws = append(ws, youTubeCollector)
+		}
 		ws = append(ws, builders...)
-		mgr := worker.NewManager(ws...)
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		if len(qclients) > 0 || len(emailTargets) > 0 || len(telegramTargets) > 0 || len(chatTargets) > 0 {
+			ws = append(ws, &worker.DeliveryReconciler{
+				Store:       store,
+				Quaily:      qclients,
+				Email:       emailTargets,
+				Telegram:    telegramTargets,
+				Chat:        chatTargets,
+				Notifier:    notifier,
+				MaxAttempts: cfg.Quaily.DeliveryMaxAttempts,
+			})
+		}
+		if strings.TrimSpace(cfg.Reporting.DailyAt) != "" {
+			hour, minute, loc, err := cfg.Reporting.Schedule()
+			if err != nil {
+				return err
+			}
+			reporter := &worker.HealthReporter{
+				Store:    store,
+				Channels: healthChannels(cfg, qclients),
+				Notifier: notifier,
+				Hour:     hour,
+				Minute:   minute,
+				Location: loc,
+			}
+			if v2c != nil {
+				reporter.Sources = append(reporter.Sources, "v2ex")
+			}
+			if hnc != nil {
+				reporter.Sources = append(reporter.Sources, "hackernews")
+			}
+			if rssCollector != nil {
+				reporter.Sources = append(reporter.Sources, "rss")
+			}
+			if lobstersCollector != nil {
+				reporter.Sources = append(reporter.Sources, "lobsters")
+			}
+			if redditCollector != nil {
+				reporter.Sources = append(reporter.Sources, "reddit")
+			}
+			if githubCollector != nil {
+				reporter.Sources = append(reporter.Sources, "github")
+			}
+			if productHuntCollector != nil {
+				reporter.Sources = append(reporter.Sources, "producthunt")
+			}
+			if arxivCollector != nil {
+				reporter.Sources = append(reporter.Sources, "arxiv")
+			}
+			if mastodonCollector != nil {
+				reporter.Sources = append(reporter.Sources, "mastodon")
+			}
+			if blueskyCollector != nil {
+				reporter.Sources = append(reporter.Sources, "bluesky")
+			}
+			if stackOverflowCollector != nil {
+				reporter.Sources = append(reporter.Sources, "stackoverflow")
+			}
+			if jsonFeedCollector != nil {
+				reporter.Sources = append(reporter.Sources, "jsonfeed")
+			}
+			if youTubeCollector != nil {
+				reporter.Sources = append(reporter.Sources, "youtube")
+			}
+			if cfg.Reporting.WriteFile {
+				reporter.OutputDir = cfg.Newsletters.OutputDir
+			}
+			ws = append(ws, reporter)
+		}
+		mgr, err := worker.NewManager(ws...)
+		if err != nil {
+			return err
+		}
 
-		// Signal handling for systemd
-		sigc := make(chan os.Signal, 1)
-		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
-		go func() {
-			s := <-sigc
-			log.Printf("received signal: %s, shutting down", s)
-			cancel()
-		}()
+		// Push digests whose Quaily publish failed before the builders run again, so
+		// a builder publishing right now cannot race the lookup.
+		if len(qclients) > 0 && cfg.Quaily.ReconcileDays >= 0 {
+			rec := &worker.QuailyReconciler{Store: store, Channels: reconcileChannels(cfg, qclients), Days: cfg.Quaily.ReconcileDays}
+			ctxRec, cancelRec := context.WithTimeout(ctx, 2*time.Minute)
+			if _, err := rec.Run(ctxRec); err != nil {
+				slog.Warn("quaily reconcile: startup check failed", "err", err)
+			}
+			cancelRec()
+		}
 
 		if err := mgr.Start(ctx); err != nil {
 			return err
 		}
-		return nil
+		return mgr.Wait()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
+	addMockSourcesFlag(serveCmd)
+}
+
+// allMinimal reports whether every channel uses the minimal style, in which case
+// serve needs no AI client even when openai.api_key is set.
+func allMinimal(channels []config.ChannelConfig) bool {
+	for _, ch := range channels {
+		if ch.Style != newsletter.StyleMinimal {
+			return false
+		}
+	}
+	return len(channels) > 0
+}
+
+// newQualityGate builds the optional AI relevance gate for a channel; nil when disabled or AI is not configured.
+func newQualityGate(ch config.ChannelConfig, summarizer ai.Summarizer, store *storage.RedisStore) *selection.QualityGate {
+	if !ch.QualityGate.Enabled {
+		return nil
+	}
+	if summarizer == nil {
+		slog.Warn("quality gate enabled but openai is not configured; gate disabled", "channel", ch.Name)
+		return nil
+	}
+	threshold := ch.QualityGate.Threshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	desc := strings.TrimSpace(ch.QualityGate.Description)
+	if desc == "" {
+		desc = ch.Name
+	}
+	return &selection.QualityGate{
+		Summarizer:  summarizer,
+		Store:       store,
+		Channel:     ch.Name,
+		Description: desc,
+		Threshold:   threshold,
+		Language:    ch.Language,
+	}
+}
+
+// healthChannels lists the channels the health report covers.
+func healthChannels(cfg config.Config, qclients map[string]*quaily.Client) []worker.HealthChannel {
+	out := make([]worker.HealthChannel, 0, len(cfg.Newsletters.Channels))
+	for _, ch := range cfg.Newsletters.Channels {
+		out = append(out, worker.HealthChannel{Name: ch.Name, Frequency: strings.ToLower(ch.Frequency), Quaily: qclients[ch.Name] != nil})
+	}
+	return out
+}
+
+// v2exNodeUnion returns the distinct V2EX nodes across channels with source v2ex, in config order.
+func v2exNodeUnion(cfg config.Config) []string {
+	seen := map[string]struct{}{}
+	nodes := []string{}
+	for _, ch := range cfg.Newsletters.Channels {
+		if strings.ToLower(ch.Source) != "v2ex" {
+			continue
+		}
+		for _, n := range ch.Nodes {
+			n = strings.TrimSpace(n)
+			if n == "" {
+				continue
+			}
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// sourceNodeUnion returns the distinct nodes, lowercased, across channels with the
+// given source: the subreddits Reddit polls, or the languages GitHub is searched in.
+// None means no collector for the source.
+func sourceNodeUnion(cfg config.Config, source string) []string {
+	seen := map[string]struct{}{}
+	var nodes []string
+	for _, ch := range cfg.Newsletters.Channels {
+		if strings.ToLower(ch.Source) != source {
+			continue
+		}
+		for _, n := range ch.Nodes {
+			n = strings.ToLower(strings.TrimSpace(n))
+			if n == "" {
+				continue
+			}
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// hnListUnion returns the distinct HN lists across channels with source hackernews, defaulting to top.
+func hnListUnion(cfg config.Config) []string {
+	seen := map[string]struct{}{}
+	lists := []string{}
+	for _, ch := range cfg.Newsletters.Channels {
+		if strings.ToLower(ch.Source) != "hackernews" {
+			continue
+		}
+		for _, n := range ch.Nodes {
+			n = strings.ToLower(strings.TrimSpace(n))
+			if n == "" {
+				continue
+			}
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			lists = append(lists, n)
+		}
+	}
+	if len(lists) == 0 {
+		lists = []string{"top"}
+	}
+	return lists
+}
+
+// prefetchV2EXNodeTitles caches human-friendly node titles that are not cached yet (best-effort).
+func prefetchV2EXNodeTitles(ctx context.Context, store *storage.RedisStore, v2c worker.V2EXSource, nodes []string) {
+	if v2c == nil {
+		return
+	}
+	for _, n := range nodes {
+		if _, isQuery := v2ex.SearchQuery(n); isQuery {
+			continue // search results cache the titles of their nodes
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		ctxNode, cancelNode := context.WithTimeout(ctx, 5*time.Second)
+		// Skip fetch if already cached
+		if t, _ := store.GetNodeTitle(ctxNode, "v2ex", n); strings.TrimSpace(t) == "" {
+			if title, err := v2c.NodeTitle(ctxNode, n); err == nil && strings.TrimSpace(title) != "" {
+				_ = store.SetNodeTitle(ctx, "v2ex", n, title, 30*24*time.Hour)
+			}
+		}
+		cancelNode()
+	}
+}
+
+// nodeReload pushes the nodes of one running collector, recomputed from a reloaded
+// config, to it.
+type nodeReload func(ctx context.Context, cfg config.Config)
+
+// reloadNodes returns the nodeReload setting c's units to those units computes; when
+// units fails, c keeps polling its current ones.
+func reloadNodes[T any](source string, c worker.NodeSetter[T], units func(config.Config) ([]T, error)) nodeReload {
+	return func(ctx context.Context, cfg config.Config) {
+		next, err := units(cfg)
+		if err != nil {
+			slog.Error("reload: "+source+" collector nodes not updated", "error", err)
+			return
+		}
+		added := c.SetNodes(next)
+		slog.Info("reload: "+source+" collector nodes updated", "nodes", len(next), "added", added)
+	}
+}
+
+// reloadCollectorNodes re-reads the config file and pushes the recomputed nodes,
+// lists, and feeds to the running collectors; changes apply at each collector's next
+// run. Other settings (channels' rendering options, intervals, credentials) and
+// collectors that had nothing to poll at startup still need a restart.
+func reloadCollectorNodes(ctx context.Context, reloads []nodeReload) {
+	cfg, err := reloadConfig()
+	if err != nil {
+		slog.Error("reload: config read failed; keeping current nodes", "error", err)
+		return
+	}
+	for _, reload := range reloads {
+		reload(ctx, cfg)
+	}
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"quaily-journalist/internal/site"
+
+	"github.com/spf13/cobra"
+)
+
+// siteCmd groups commands for the static site export.
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Export a channel's digests as a static website",
+}
+
+var siteOut string
+
+var siteBuildCmd = &cobra.Command{
+	Use:   "build <channel>",
+	Short: "Write HTML pages, an index, an Atom feed, and cover images for a channel's digests",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		ch, ok := cfg.FindChannel(strings.TrimSpace(args[0]))
+		if !ok {
+			return fmt.Errorf("channel not found: %s", args[0])
+		}
+		sc := ch.Config.Site
+		res, err := site.Build(site.Options{
+			Channel:       ch.Name,
+			Title:         sc.Title,
+			BaseURL:       sc.BaseURL,
+			SrcDir:        filepath.Join(ch.OutputDir, ch.Name),
+			OutDir:        siteOut,
+			PageTemplate:  sc.PageTemplate,
+			IndexTemplate: sc.IndexTemplate,
+		})
+		if err != nil {
+			return err
+		}
+		return emit(cmd, res, func(w io.Writer) {
+			fmt.Fprintf(w, "Built %s: %d pages (%d written, %d unchanged, %d removed)\n", res.Out, res.Pages, len(res.Built), res.Unchanged, len(res.Removed))
+		})
+	},
+}
+
+func init() {
+	siteBuildCmd.Flags().StringVar(&siteOut, "out", "", "directory to write the site to")
+	_ = siteBuildCmd.MarkFlagRequired("out")
+	siteCmd.AddCommand(siteBuildCmd)
+	rootCmd.AddCommand(siteCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// statusCmd prints the persisted state of the serve workers.
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show persisted worker status (last run times, errors, and quiet hours)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		workers, err := store.WorkerStatuses(ctx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return emit(cmd, statusResult{Workers: workers}, func(w io.Writer) {
+			if len(workers) == 0 {
+				fmt.Fprintln(w, "No worker status recorded yet.")
+				return
+			}
+			for _, st := range workers {
+				fmt.Fprintf(w, "%-16s last run %s (%s ago)\n", st.Worker, st.LastRunAt.Local().Format(time.RFC3339), now.Sub(st.LastRunAt).Round(time.Second))
+				if st.LastError != "" && st.LastErrorAt != nil {
+					fmt.Fprintf(w, "%-16s last error at %s: %s\n", "", st.LastErrorAt.Local().Format(time.RFC3339), st.LastError)
+				}
+				if st.QuietUntil != nil && now.Before(*st.QuietUntil) {
+					fmt.Fprintf(w, "%-16s in quiet hours until %s\n", "", st.QuietUntil.Format("15:04"))
+				}
+			}
+		})
+	},
+}
+
+// statusResult is the --output json schema of the status command.
+type statusResult struct {
+	Workers []storage.WorkerStatus `json:"workers"`
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/redisclient"
+	"quaily-journalist/internal/selection"
+
+	"github.com/spf13/cobra"
+)
+
+var topLimit int
+
+// topEntry is one candidate of the top --output json result.
+type topEntry struct {
+	Rank        int     `json:"rank"`
+	ID          string  `json:"id"`
+	Score       float64 `json:"score"`        // after channel ranking, node weights, and repeat penalty
+	StoredScore float64 `json:"stored_score"` // collector score in the period set
+	Appearances int     `json:"appearances"`  // earlier digests of the channel that included the item
+	Skipped     bool    `json:"skipped"`      // within item_skip_duration of a previous digest
+	Pinned      bool    `json:"pinned"`       // pinned into the next digest; leads it whatever its score
+	Excluded    bool    `json:"excluded"`     // on the source's exclusion list; never selected
+	NodeName    string  `json:"node_name"`
+	Title       string  `json:"title"`
+}
+
+// topCmd shows how a channel's builder would rank its candidates right now.
+var topCmd = &cobra.Command{
+	Use:   "top <channel>",
+	Short: "Show a channel's ranked candidates for the current period",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		var ch *config.ChannelConfig
+		for i := range cfg.Newsletters.Channels {
+			if cfg.Newsletters.Channels[i].Name == args[0] {
+				ch = &cfg.Newsletters.Channels[i]
+				break
+			}
+		}
+		if ch == nil {
+			return fmt.Errorf("channel not found: %s", args[0])
+		}
+		source := strings.ToLower(ch.Source)
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		key := period.Key(strings.ToLower(ch.Frequency), time.Now().UTC())
+		sel := selection.FromChannel(cfg.ResolveChannel(*ch))
+		var err error
+		if sel.Scorer, err = channelScorer(cfg, *ch); err != nil {
+			return err
+		}
+		// Excluded items stay listed, flagged, to show they were considered and rejected.
+		excluded, err := selection.LoadExclusions(ctx, store, source)
+		if err != nil {
+			return err
+		}
+		// Read as deep as the builder would. Only the node filter drops items from the
+		// list: low-signal, excluded, reposted, and skipped ones count toward the depth
+		// as they do for the builder, but stay listed.
+		stored := map[string]float64{}
+		perBatch := selection.Pipeline{
+			{Name: "stored", Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+				for _, ws := range items {
+					stored[ws.Item.ID] = ws.Score
+				}
+				return items
+			}},
+			sel.Rescore(time.Now()),
+			sel.WeightNodes(),
+			sel.PenalizeRepeats(store),
+			sel.FilterNodes(),
+		}
+		perPool := selection.Pipeline{sel.DropLowSignal(), excluded.Stage(), sel.Dedup(), sel.DropSkipped(store, map[string]bool{})}
+		f, err := sel.Fetch(ctx, store, key, perBatch, perPool)
+		if err != nil {
+			return err
+		}
+		items := f.Pool
+		slog.Debug("top: ranked candidates", "channel", ch.Name, "depth", f.Depth, "items", len(items), "removed", f.Removed)
+		ids := make([]string, len(items))
+		for i, ws := range items {
+			ids[i] = ws.Item.ID
+		}
+		counts, err := store.Appearances(ctx, ch.Name, ids)
+		if err != nil {
+			return err
+		}
+		pins, err := selection.PinnedItems(ctx, store, ch.Name, source, key)
+		if err != nil {
+			return err
+		}
+		pinned := make(map[string]bool, len(pins))
+		for _, ws := range pins {
+			pinned[ws.Item.ID] = true
+		}
+		items = selection.MergePins(pins, items)
+		if topLimit > 0 && len(items) > topLimit {
+			items = items[:topLimit]
+		}
+
+		entries := make([]topEntry, 0, len(items))
+		for i, ws := range items {
+			skipped, err := store.IsSkipped(ctx, ch.Name, ws.Item.ID)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, topEntry{
+				Rank:        i + 1,
+				ID:          ws.Item.ID,
+				Score:       ws.Score,
+				StoredScore: stored[ws.Item.ID],
+				Appearances: counts[ws.Item.ID],
+				Skipped:     skipped,
+				Pinned:      pinned[ws.Item.ID],
+				Excluded:    !pinned[ws.Item.ID] && excluded.Excludes(ws.Item),
+				NodeName:    ws.Item.NodeName,
+				Title:       ws.Item.Title,
+			})
+		}
+		res := map[string]any{"channel": ch.Name, "period": key, "items": entries}
+		return emit(cmd, res, func(w io.Writer) {
+			if len(entries) == 0 {
+				fmt.Fprintf(w, "No candidates for %s in %s/%s.\n", ch.Name, source, key)
+				return
+			}
+			for _, e := range entries {
+				flags := ""
+				if e.Pinned {
+					flags += " [pinned]"
+				}
+				if e.Excluded {
+					flags += " [excluded]"
+				}
+				if e.Skipped {
+					flags += " [skipped]"
+				}
+				fmt.Fprintf(w, "%3d  %s\t%.6f\t(stored %.6f, seen %d)\t%s\t%s%s\n", e.Rank, e.ID, e.Score, e.StoredScore, e.Appearances, e.NodeName, e.Title, flags)
+			}
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().IntVar(&topLimit, "limit", 20, "maximum candidates to show; 0 = all the builder would read")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"quaily-journalist/internal/redisclient"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchSource string
+	watchNode   string
+)
+
+// watchCmd streams newly collected items announced by the collectors over Redis pub/sub.
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream newly collected items until Ctrl-C",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfig()
+		rdb := redisclient.New(cfg.Redis)
+		defer rdb.Close()
+		store := newStore(cfg, rdb)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		events, err := store.SubscribeItemEvents(ctx)
+		if err != nil {
+			return err
+		}
+		source := strings.ToLower(strings.TrimSpace(watchSource))
+		node := strings.ToLower(strings.TrimSpace(watchNode))
+		if !jsonOutput() {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Watching for new items (Ctrl-C to stop)...")
+		}
+		// Collectors re-store the same items on every tick; only print the first sighting.
+		seen := map[string]struct{}{}
+		for ev := range events {
+			if source != "" && strings.ToLower(ev.Source) != source {
+				continue
+			}
+			if node != "" && strings.ToLower(ev.NodeName) != node {
+				continue
+			}
+			key := ev.Source + ":" + ev.ID
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			ev := ev
+			if err := emit(cmd, ev, func(w io.Writer) {
+				fmt.Fprintf(w, "%s\t%s\t%.6f\t%s\t%s\n", ev.Source, ev.ID, ev.Score, ev.NodeName, ev.Title)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVar(&watchSource, "source", "", "only show items from this source (e.g., v2ex, hackernews)")
+	watchCmd.Flags().StringVar(&watchNode, "node", "", "only show items from this node")
+}
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DefaultMaxConcurrentRequests bounds the in-flight requests of a client when
+// Config.MaxConcurrentRequests is 0.
+const DefaultMaxConcurrentRequests = 4
+
+// limiter bounds concurrent requests. A nil limiter admits everything.
+type limiter struct {
+	sem chan struct{}
+}
+
+// newLimiter returns a limiter admitting n requests at once; 0 uses
+// DefaultMaxConcurrentRequests and a negative n means no limit (nil).
+func newLimiter(n int) *limiter {
+	if n == 0 {
+		n = DefaultMaxConcurrentRequests
+	}
+	if n < 0 {
+		return nil
+	}
+	return &limiter{sem: make(chan struct{}, n)}
+}
+
+// acquire waits for a slot until ctx is done and returns its release func. Waits
+// are logged, so contention on the shared budget shows up in the logs.
+func (l *limiter) acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+	start := time.Now()
+	select {
+	case l.sem <- struct{}{}:
+		slog.Info("openai: waited for a request slot", "wait", time.Since(start).Round(time.Millisecond), "max_concurrent_requests", cap(l.sem))
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *limiter) release() { <-l.sem }
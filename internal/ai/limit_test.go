@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chatServer answers chat completions after a short delay and records the peak
+// number of requests in flight.
+func chatServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &peak
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	for _, tc := range []struct{ limit, want int }{{2, 2}, {0, DefaultMaxConcurrentRequests}} {
+		srv, peak := chatServer(t)
+		c := NewOpenAI(Config{APIKey: "k", Model: "m", BaseURL: srv.URL, MaxConcurrentRequests: tc.limit})
+		var wg sync.WaitGroup
+		for i := 0; i < 12; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.SummarizeItem(context.Background(), "t", "content", "English"); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if got := int(peak.Load()); got != tc.want {
+			t.Errorf("limit %d: peak in-flight = %d, want %d", tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestLimiterHonorsContext(t *testing.T) {
+	l := newLimiter(1)
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire while full = %v, want deadline exceeded", err)
+	}
+	release()
+	if r, err := l.acquire(context.Background()); err != nil {
+		t.Errorf("acquire after release = %v", err)
+	} else {
+		r()
+	}
+	if newLimiter(-1) != nil {
+		t.Error("negative limit should disable the limiter")
+	}
+}
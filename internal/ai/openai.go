@@ -2,8 +2,12 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,24 +20,53 @@ import (
 type Summarizer interface {
 	// SummarizeItem creates a concise 1-2 sentence description for an item in the given language.
 	SummarizeItem(ctx context.Context, title, content, language string) (string, error)
+	// SummarizeItemWithTakeaway writes the item description and a one-line "why it
+	// matters" takeaway in one call.
+	SummarizeItemWithTakeaway(ctx context.Context, title, content, language string) (model.ItemSummary, error)
 	// SummarizePost creates a short post-level summary for a set of items in the given language.
 	SummarizePost(ctx context.Context, items []model.NewsItem, language string) (string, error)
 	// SummarizePostLikeAZenMaster creates a very concise, zen-master-style post-level summary for a set of items in the given language.
 	SummarizePostLikeAZenMaster(ctx context.Context, items []model.NewsItem, language string) (string, error)
+	// ScoreRelevance rates from 0 (off-topic/low value) to 1 (highly relevant) how well an item fits a channel description.
+	ScoreRelevance(ctx context.Context, title, content, channelDescription, language string) (float64, error)
+	// SEOMeta writes a search description (at most 160 characters) and 5 keywords for a digest in one call.
+	SEOMeta(ctx context.Context, title, summary string, items []model.NewsItem, language string) (model.SEOMeta, error)
+	// ExtractQuote picks one short, near-verbatim quote (at most 140 characters) from the items,
+	// attributed to the item it comes from.
+	ExtractQuote(ctx context.Context, items []model.NewsItem, language string) (model.Quote, error)
+	// CoverAlt writes a one-sentence alt text for a cover image generated from prompt.
+	CoverAlt(ctx context.Context, title, prompt, language string) (string, error)
 }
 
 // OpenAIClient implements Summarizer using OpenAI Chat Completions API.
+// All of its methods share one concurrency budget (Config.MaxConcurrentRequests),
+// so a single client passed to every builder and command caps the requests of the
+// whole process, whatever concurrency its callers use.
 type OpenAIClient struct {
-	client *openai.Client
-	model  string
+	client    *openai.Client
+	model     string
+	limit     *limiter
+	onUsage   func(tokens int)
+	whyPrompt string
 }
 
 type Config struct {
 	APIKey  string
 	Model   string
 	BaseURL string // optional
+	// MaxConcurrentRequests bounds in-flight requests; 0 uses
+	// DefaultMaxConcurrentRequests, negative means unlimited.
+	MaxConcurrentRequests int
+	// OnUsage, when set, is called with the total tokens of every completion.
+	OnUsage func(tokens int)
+	// WhyItMattersPrompt instructs the "why it matters" takeaway of
+	// SummarizeItemWithTakeaway; empty uses DefaultWhyItMattersPrompt.
+	WhyItMattersPrompt string
 }
 
+// DefaultWhyItMattersPrompt asks for the takeaway rendered under an item's description.
+const DefaultWhyItMattersPrompt = "In one sentence of at most 25 words, say why this matters to the reader: an opinionated takeaway, not a restatement of the summary."
+
 func NewOpenAI(cfg Config) *OpenAIClient {
 	var c *openai.Client
 	if cfg.BaseURL != "" {
@@ -47,7 +80,11 @@ func NewOpenAI(cfg Config) *OpenAIClient {
 	if model == "" {
 		panic("OpenAI model must be specified")
 	}
-	return &OpenAIClient{client: c, model: model}
+	whyPrompt := strings.TrimSpace(cfg.WhyItMattersPrompt)
+	if whyPrompt == "" {
+		whyPrompt = DefaultWhyItMattersPrompt
+	}
+	return &OpenAIClient{client: c, model: model, limit: newLimiter(cfg.MaxConcurrentRequests), onUsage: cfg.OnUsage, whyPrompt: whyPrompt}
 }
 
 func (o *OpenAIClient) SummarizeItem(ctx context.Context, title, content, language string) (string, error) {
@@ -79,6 +116,50 @@ func (o *OpenAIClient) SummarizeItem(ctx context.Context, title, content, langua
 	return strings.TrimSpace(out), nil
 }
 
+func (o *OpenAIClient) SummarizeItemWithTakeaway(ctx context.Context, title, content, language string) (model.ItemSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+	content = strings.TrimSpace(content)
+	if content == "" {
+		content = title
+	}
+	if len([]rune(content)) > 1000 {
+		content = string([]rune(content)[:1000])
+	}
+	sys := fmt.Sprintf(`
+		Write in %s. Reply with a JSON object only: {"description": "...", "why_it_matters": "..."}.
+		description: try your best to rewrite the text into a summary of 1–3 sentences (30–180 words), neutral, in the author's writing style, retaining the deep meaning of the text.
+		why_it_matters: %s
+		`, langOrDefault(language), o.whyPrompt)
+	user := fmt.Sprintf("Title: %s\nContent: %s", title, content)
+	out, err := o.create(ctx, sys, user)
+	if err != nil {
+		slog.Error("openai: summarize item with takeaway error", "err", err)
+		return model.ItemSummary{}, err
+	}
+	return parseItemSummary(out)
+}
+
+// parseItemSummary decodes the JSON reply of SummarizeItemWithTakeaway, tolerating
+// code fences. A reply without a description is an error; one without a takeaway
+// is not.
+func parseItemSummary(out string) (model.ItemSummary, error) {
+	s := strings.TrimSpace(out)
+	if i, j := strings.Index(s, "{"), strings.LastIndex(s, "}"); i >= 0 && j > i {
+		s = s[i : j+1]
+	}
+	var sum model.ItemSummary
+	if err := json.Unmarshal([]byte(s), &sum); err != nil {
+		return model.ItemSummary{}, fmt.Errorf("openai: unparseable item summary %q: %w", strings.TrimSpace(out), err)
+	}
+	sum.Description = strings.TrimSpace(sum.Description)
+	sum.WhyItMatters = strings.Trim(strings.TrimSpace(sum.WhyItMatters), `"`)
+	if sum.Description == "" {
+		return model.ItemSummary{}, fmt.Errorf("openai: empty item description")
+	}
+	return sum, nil
+}
+
 func (o *OpenAIClient) SummarizePostLikeAZenMaster(ctx context.Context, items []model.NewsItem, language string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 300*time.Second)
 	defer cancel()
@@ -139,7 +220,183 @@ func (o *OpenAIClient) SummarizePost(ctx context.Context, items []model.NewsItem
 	return strings.TrimSpace(out), nil
 }
 
+var relevanceNumRe = regexp.MustCompile(`[01](?:\.\d+)?|\.\d+`)
+
+func (o *OpenAIClient) ScoreRelevance(ctx context.Context, title, content, channelDescription, language string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	content = strings.TrimSpace(content)
+	if len([]rune(content)) > 1000 {
+		content = string([]rune(content)[:1000])
+	}
+	sys := `
+		You are the editor of a curated newsletter. Rate how well a candidate post fits the newsletter's topic and how much value it offers its readers.
+		Reply with a single number between 0 and 1 and nothing else.
+		0 means off-topic or low value (e.g., shopping advice, personal chit-chat, spam); 1 means highly relevant and substantive.
+		`
+	user := fmt.Sprintf("Newsletter topic: %s\nNewsletter language: %s\n\nTitle: %s\nContent: %s", channelDescription, langOrDefault(language), title, content)
+	out, err := o.create(ctx, sys, user)
+	if err != nil {
+		slog.Error("openai: score relevance error", "err", err)
+		return 0, err
+	}
+	m := relevanceNumRe.FindString(out)
+	if m == "" {
+		return 0, fmt.Errorf("openai: unparseable relevance score %q", strings.TrimSpace(out))
+	}
+	v, err := strconv.ParseFloat(m, 64)
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(0, math.Min(1, v)), nil
+}
+
+// Limits for SEOMeta output.
+const (
+	seoDescriptionMaxRunes = 160
+	seoKeywords            = 5
+)
+
+func (o *OpenAIClient) SEOMeta(ctx context.Context, title, summary string, items []model.NewsItem, language string) (model.SEOMeta, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+	b := &strings.Builder{}
+	for i, it := range items {
+		if i >= 10 {
+			break
+		}
+		fmt.Fprintf(b, "- %s\n", it.Title)
+	}
+	sys := fmt.Sprintf(`
+		You write search-engine metadata for a newsletter issue, in %s.
+		Reply with a JSON object only: {"description": "...", "keywords": ["...", "...", "...", "...", "..."]}.
+		The description is one plain sentence of at most %d characters that makes a searcher want to click.
+		Give exactly %d short keywords, most important first.
+		`, langOrDefault(language), seoDescriptionMaxRunes, seoKeywords)
+	user := fmt.Sprintf("Title: %s\nSummary: %s\nItems:\n%s", title, summary, b.String())
+	out, err := o.create(ctx, sys, user)
+	if err != nil {
+		slog.Error("openai: seo meta error", "err", err)
+		return model.SEOMeta{}, err
+	}
+	return parseSEOMeta(out)
+}
+
+// parseSEOMeta decodes the JSON reply of SEOMeta, tolerating code fences, and
+// enforces the description length and keyword count.
+func parseSEOMeta(out string) (model.SEOMeta, error) {
+	s := strings.TrimSpace(out)
+	if i, j := strings.Index(s, "{"), strings.LastIndex(s, "}"); i >= 0 && j > i {
+		s = s[i : j+1]
+	}
+	var meta model.SEOMeta
+	if err := json.Unmarshal([]byte(s), &meta); err != nil {
+		return model.SEOMeta{}, fmt.Errorf("openai: unparseable seo meta %q: %w", strings.TrimSpace(out), err)
+	}
+	meta.Description = strings.TrimSpace(meta.Description)
+	if r := []rune(meta.Description); len(r) > seoDescriptionMaxRunes {
+		meta.Description = strings.TrimSpace(string(r[:seoDescriptionMaxRunes-1])) + "…"
+	}
+	kws := make([]string, 0, seoKeywords)
+	for _, k := range meta.Keywords {
+		if k = strings.TrimSpace(k); k != "" && len(kws) < seoKeywords {
+			kws = append(kws, k)
+		}
+	}
+	meta.Keywords = kws
+	if meta.Description == "" {
+		return model.SEOMeta{}, fmt.Errorf("openai: empty seo description")
+	}
+	return meta, nil
+}
+
+// Limits for ExtractQuote input and output.
+const (
+	quoteMaxRunes       = 140
+	quoteItems          = 10
+	quoteItemInputRunes = 1500
+)
+
+func (o *OpenAIClient) ExtractQuote(ctx context.Context, items []model.NewsItem, language string) (model.Quote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+	if len(items) == 0 {
+		return model.Quote{}, fmt.Errorf("openai: no items to quote")
+	}
+	b := &strings.Builder{}
+	for i, it := range items {
+		if i >= quoteItems {
+			break
+		}
+		content := []rune(strings.TrimSpace(it.Content))
+		if len(content) > quoteItemInputRunes {
+			content = content[:quoteItemInputRunes]
+		}
+		fmt.Fprintf(b, "[%d] %s\n%s\n\n", i+1, it.Title, string(content))
+	}
+	sys := fmt.Sprintf(`
+		You pick a pull quote for a newsletter issue written in %s.
+		Choose the single most striking sentence from the numbered items below and quote it as close to verbatim as possible,
+		translating only if it is not in the newsletter language. At most %d characters.
+		Reply with a JSON object only: {"quote": "...", "item": <item number>}.
+		`, langOrDefault(language), quoteMaxRunes)
+	out, err := o.create(ctx, sys, b.String())
+	if err != nil {
+		slog.Error("openai: extract quote error", "err", err)
+		return model.Quote{}, err
+	}
+	return parseQuote(out, items)
+}
+
+// parseQuote decodes the JSON reply of ExtractQuote and resolves the item number.
+func parseQuote(out string, items []model.NewsItem) (model.Quote, error) {
+	s := strings.TrimSpace(out)
+	if i, j := strings.Index(s, "{"), strings.LastIndex(s, "}"); i >= 0 && j > i {
+		s = s[i : j+1]
+	}
+	var res struct {
+		Quote string `json:"quote"`
+		Item  int    `json:"item"`
+	}
+	if err := json.Unmarshal([]byte(s), &res); err != nil {
+		return model.Quote{}, fmt.Errorf("openai: unparseable quote %q: %w", strings.TrimSpace(out), err)
+	}
+	if res.Item < 1 || res.Item > len(items) || res.Item > quoteItems {
+		return model.Quote{}, fmt.Errorf("openai: quote item %d out of range", res.Item)
+	}
+	text := strings.Trim(strings.TrimSpace(res.Quote), `"“”「」`)
+	if text == "" {
+		return model.Quote{}, fmt.Errorf("openai: empty quote")
+	}
+	if r := []rune(text); len(r) > quoteMaxRunes {
+		text = strings.TrimSpace(string(r[:quoteMaxRunes-1])) + "…"
+	}
+	return model.Quote{Text: text, Item: items[res.Item-1]}, nil
+}
+
+func (o *OpenAIClient) CoverAlt(ctx context.Context, title, prompt, language string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	sys := fmt.Sprintf(`
+		You write alt text for the cover image of a newsletter issue, in %s.
+		Describe what the image shows in one plain sentence of at most 125 characters, based on the prompt it was generated from.
+		Do not start with "Image of" or "Picture of". Output the sentence only.
+		`, langOrDefault(language))
+	user := fmt.Sprintf("Issue title: %s\nImage prompt: %s", title, prompt)
+	out, err := o.create(ctx, sys, user)
+	if err != nil {
+		slog.Error("openai: cover alt error", "err", err)
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(out), `"`), nil
+}
+
 func (o *OpenAIClient) create(ctx context.Context, system, user string) (string, error) {
+	release, err := o.limit.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
 	// Default timeout guard, if caller didn't set one
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -157,6 +414,9 @@ func (o *OpenAIClient) create(ctx context.Context, system, user string) (string,
 	if err != nil {
 		return "", err
 	}
+	if o.onUsage != nil {
+		o.onUsage(resp.Usage.TotalTokens)
+	}
 	if len(resp.Choices) == 0 {
 		return "", nil
 	}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestParseSEOMeta(t *testing.T) {
+	out := "```json\n{\"description\": \"" + strings.Repeat("长", 200) + "\", \"keywords\": [\"go\", \" \", \"rust\", \"ai\", \"db\", \"web\", \"extra\"]}\n```"
+	meta, err := parseSEOMeta(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(meta.Description); n != seoDescriptionMaxRunes {
+		t.Errorf("description is %d runes, want %d", n, seoDescriptionMaxRunes)
+	}
+	if strings.Join(meta.Keywords, ",") != "go,rust,ai,db,web" {
+		t.Errorf("keywords = %v", meta.Keywords)
+	}
+	if _, err := parseSEOMeta("Sure! Here is a description."); err == nil {
+		t.Error("parseSEOMeta(prose) = nil error")
+	}
+}
+
+func TestParseItemSummary(t *testing.T) {
+	sum, err := parseItemSummary("```json\n{\"description\": \" Go 1.25 ships a new GC. \", \"why_it_matters\": \"\\\"Your p99s just got cheaper.\\\"\"}\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Description != "Go 1.25 ships a new GC." || sum.WhyItMatters != "Your p99s just got cheaper." {
+		t.Errorf("parseItemSummary = %+v", sum)
+	}
+	if sum, err := parseItemSummary(`{"description": "Only a summary."}`); err != nil || sum.WhyItMatters != "" {
+		t.Errorf("reply without takeaway = %+v, %v", sum, err)
+	}
+	if _, err := parseItemSummary(`{"why_it_matters": "x"}`); err == nil {
+		t.Error("reply without description accepted")
+	}
+}
+
+func TestParseQuote(t *testing.T) {
+	items := []model.NewsItem{{ID: "1", Title: "A"}, {ID: "2", Title: "B", URL: "https://example.com/b"}}
+	q, err := parseQuote(`{"quote": "“Ship it on Friday.”", "item": 2}`, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Text != "Ship it on Friday." || q.Item.ID != "2" {
+		t.Errorf("parseQuote = %+v", q)
+	}
+	if _, err := parseQuote(`{"quote": "x", "item": 3}`, items); err == nil {
+		t.Error("out-of-range item accepted")
+	}
+}
@@ -0,0 +1,165 @@
+// Package arxiv reads the newest submissions of arXiv categories from the arXiv
+// Atom API (/api/query) into model.NewsItem.
+package arxiv
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of arXiv papers.
+const Source = "arxiv"
+
+// DefaultBaseURL serves the query API.
+const DefaultBaseURL = "https://export.arxiv.org"
+
+// SiteURL is the site category listings (/list/<category>/new) live on.
+const SiteURL = "https://arxiv.org"
+
+// maxResults is the number of entries requested per category, newest first.
+const maxResults = 50
+
+// Client is a minimal arXiv API client.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient returns a client for the API at baseURL (empty uses DefaultBaseURL).
+func NewClient(baseURL string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{baseURL: strings.TrimRight(strings.TrimSpace(baseURL), "/"), client: &http.Client{Timeout: 20 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// entry mirrors the Atom entry fields items are built from.
+type entry struct {
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Summary   string    `xml:"summary"`
+	Published time.Time `xml:"published"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"link"`
+	Raw string `xml:",innerxml"`
+}
+
+type feed struct {
+	Entries []entry `xml:"entry"`
+}
+
+// Papers returns the newest submissions to category (e.g., cs.CL), cross-lists
+// included, labeled with category as given.
+func (c *Client) Papers(ctx context.Context, category string) ([]model.NewsItem, error) {
+	cat := strings.TrimSpace(category)
+	q := url.Values{}
+	q.Set("search_query", "cat:"+cat)
+	q.Set("sortBy", "submittedDate")
+	q.Set("sortOrder", "descending")
+	q.Set("max_results", fmt.Sprint(maxResults))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/query?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/atom+xml")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("arxiv %s: status %d", cat, resp.StatusCode)
+	}
+	var f feed
+	if err := xml.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return nil, fmt.Errorf("arxiv %s: decode: %w", cat, err)
+	}
+	out := make([]model.NewsItem, 0, len(f.Entries))
+	for _, e := range f.Entries {
+		if strings.Contains(e.ID, "/api/errors") {
+			// A query the API rejects comes back as a feed holding one error entry.
+			return nil, fmt.Errorf("arxiv %s: %s", cat, strings.Join(strings.Fields(e.Summary), " "))
+		}
+		it, ok := e.item(cat)
+		if !ok {
+			continue
+		}
+		it.Raw = []byte(strings.TrimSpace(e.Raw))
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// versionSuffix is the version of an arXiv ID, e.g., v2 in 2410.01234v2.
+var versionSuffix = regexp.MustCompile(`v\d+$`)
+
+// item maps an entry to a NewsItem: the abstract with the authors appended is its
+// content, and the first author, with "et al." for more, its author. The ID drops
+// the version, so a revised paper is the same item. Entries without an ID or a
+// title are left out.
+func (e entry) item(category string) (model.NewsItem, bool) {
+	id := strings.TrimSpace(e.ID)
+	if i := strings.Index(id, "/abs/"); i >= 0 {
+		id = id[i+len("/abs/"):]
+	}
+	id = versionSuffix.ReplaceAllString(id, "")
+	title := strings.Join(strings.Fields(e.Title), " ")
+	if id == "" || title == "" {
+		return model.NewsItem{}, false
+	}
+	link := SiteURL + "/abs/" + id
+	for _, l := range e.Links {
+		if l.Rel == "alternate" && l.Href != "" {
+			link = strings.Replace(l.Href, "http://", "https://", 1)
+			break
+		}
+	}
+	authors := make([]string, 0, len(e.Authors))
+	for _, a := range e.Authors {
+		if n := strings.TrimSpace(a.Name); n != "" {
+			authors = append(authors, n)
+		}
+	}
+	content := strings.Join(strings.Fields(e.Summary), " ")
+	author := ""
+	if len(authors) > 0 {
+		content = strings.TrimSpace(content + "\n\nAuthors: " + strings.Join(authors, ", "))
+		author = authors[0]
+		if len(authors) > 1 {
+			author += " et al."
+		}
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        id,
+		Title:     title,
+		URL:       link,
+		NodeName:  category,
+		CreatedAt: e.Published.UTC(),
+		Content:   content,
+		Author:    author,
+	}, true
+}
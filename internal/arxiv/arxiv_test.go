@@ -0,0 +1,75 @@
+package arxiv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestPapers(t *testing.T) {
+	body, err := os.ReadFile("testdata/query.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("search_query") == "cat:cs" {
+			_, _ = w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>http://arxiv.org/api/errors#malformed_query</id><title>Error</title><summary>malformed query</summary></entry></feed>`))
+			return
+		}
+		if r.URL.Path != "/api/query" || q.Get("search_query") != "cat:cs.CL" || q.Get("sortBy") != "submittedDate" || q.Get("sortOrder") != "descending" {
+			http.Error(w, "bad query "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/atom+xml")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	items, err := NewClient(srv.URL).Papers(context.Background(), "cs.CL")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Papers = %d items, %v", len(items), err)
+	}
+	if !strings.Contains(string(items[0].Raw), "<arxiv:comment") {
+		t.Errorf("items[0].Raw = %s, want the entry as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "2510.20001",
+		Title:     "Sparse Attention at Scale: Long-Context Retrieval Without the Quadratic Cost",
+		URL:       "https://arxiv.org/abs/2510.20001v2",
+		NodeName:  "cs.CL",
+		CreatedAt: time.Date(2025, 10, 22, 17, 59, 58, 0, time.UTC),
+		Content:   "We study sparse attention patterns for long-context language models and show that retrieval quality holds at 1M tokens.\n\nAuthors: Ada Lovelace, Alan Turing",
+		Author:    "Ada Lovelace et al.",
+	}, {
+		Source:    Source,
+		ID:        "2510.19876",
+		Title:     "A Note on Tokenizers",
+		URL:       "https://arxiv.org/abs/2510.19876v1",
+		NodeName:  "cs.CL",
+		CreatedAt: time.Date(2025, 10, 22, 9, 0, 0, 0, time.UTC),
+		Content:   "Tokenizers matter.\n\nAuthors: Grace Hopper",
+		Author:    "Grace Hopper",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+
+	if _, err := NewClient(srv.URL).Papers(context.Background(), "cs.XX"); err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Papers on a bad query = %v, want a status error", err)
+	}
+	if _, err := NewClient(srv.URL).Papers(context.Background(), "cs"); err == nil || !strings.Contains(err.Error(), "malformed query") {
+		t.Errorf("Papers answered with an error entry = %v", err)
+	}
+}
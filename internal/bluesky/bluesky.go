@@ -0,0 +1,253 @@
+// Package bluesky reads posts of Bluesky custom feeds and searches from the public
+// AppView XRPC API (app.bsky.feed.getFeed, app.bsky.feed.searchPosts) into
+// model.NewsItem.
+package bluesky
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of Bluesky posts.
+const Source = "bluesky"
+
+// DefaultBaseURL is the public AppView, which serves both endpoints without auth.
+const DefaultBaseURL = "https://public.api.bsky.app"
+
+// SiteURL is the web app posts link to.
+const SiteURL = "https://bsky.app"
+
+// DefaultLimit is the number of posts read per feed or search when none is set.
+const DefaultLimit = 100
+
+// pageSize is the most posts either endpoint returns per request.
+const pageSize = 100
+
+// titleRunes bounds the title taken from a post's first line.
+const titleRunes = 120
+
+// Client is a minimal AppView client.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient returns a client for the AppView at baseURL (empty uses DefaultBaseURL).
+func NewClient(baseURL string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{baseURL: strings.TrimRight(strings.TrimSpace(baseURL), "/"), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// post mirrors the app.bsky.feed.defs#postView fields items are built from.
+type post struct {
+	URI    string `json:"uri"`
+	Author struct {
+		Handle string `json:"handle"`
+	} `json:"author"`
+	Record struct {
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"createdAt"`
+	} `json:"record"`
+	ReplyCount int       `json:"replyCount"`
+	LikeCount  int       `json:"likeCount"`
+	IndexedAt  time.Time `json:"indexedAt"`
+}
+
+// feedPage is a getFeed response: posts wrapped with an optional repost reason.
+type feedPage struct {
+	Feed []struct {
+		Post json.RawMessage `json:"post"`
+	} `json:"feed"`
+	Cursor string `json:"cursor"`
+}
+
+// searchPage is a searchPosts response.
+type searchPage struct {
+	Posts  []json.RawMessage `json:"posts"`
+	Cursor string            `json:"cursor"`
+}
+
+// Feed returns up to limit posts of the custom feed at feedURI (an
+// at://…/app.bsky.feed.generator/… URI) stored under node, in feed order; limit 0
+// uses DefaultLimit. A post the feed repeats, e.g., as a repost, is kept once.
+func (c *Client) Feed(ctx context.Context, feedURI, node string, limit int) ([]model.NewsItem, error) {
+	return c.collect(ctx, "app.bsky.feed.getFeed", url.Values{"feed": {strings.TrimSpace(feedURI)}}, node, limit, func(b []byte) ([]json.RawMessage, string, error) {
+		var p feedPage
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, "", err
+		}
+		posts := make([]json.RawMessage, 0, len(p.Feed))
+		for _, f := range p.Feed {
+			posts = append(posts, f.Post)
+		}
+		return posts, p.Cursor, nil
+	})
+}
+
+// SearchPosts returns up to limit posts matching query stored under node, newest
+// first; limit 0 uses DefaultLimit.
+func (c *Client) SearchPosts(ctx context.Context, query, node string, limit int) ([]model.NewsItem, error) {
+	return c.collect(ctx, "app.bsky.feed.searchPosts", url.Values{"q": {strings.TrimSpace(query)}, "sort": {"latest"}}, node, limit, func(b []byte) ([]json.RawMessage, string, error) {
+		var p searchPage
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, "", err
+		}
+		return p.Posts, p.Cursor, nil
+	})
+}
+
+// collect follows the cursor of method until limit distinct posts are read or the
+// results end.
+func (c *Client) collect(ctx context.Context, method string, q url.Values, node string, limit int, decode func([]byte) ([]json.RawMessage, string, error)) ([]model.NewsItem, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	var out []model.NewsItem
+	seen := map[string]struct{}{}
+	cursor := ""
+	for len(out) < limit {
+		q.Set("limit", strconv.Itoa(min(pageSize, limit-len(out))))
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		b, err := c.get(ctx, method, q)
+		if err != nil {
+			return nil, err
+		}
+		raws, next, err := decode(b)
+		if err != nil {
+			return nil, fmt.Errorf("bluesky %s: decode: %w", method, err)
+		}
+		for _, raw := range raws {
+			var p post
+			if err := json.Unmarshal(raw, &p); err != nil {
+				return nil, fmt.Errorf("bluesky %s: decode post: %w", method, err)
+			}
+			if p.URI == "" {
+				continue
+			}
+			if _, dup := seen[p.URI]; dup {
+				continue
+			}
+			seen[p.URI] = struct{}{}
+			it := p.item(node)
+			it.Raw = raw
+			out = append(out, it)
+			if len(out) == limit {
+				break
+			}
+		}
+		if next == "" || next == cursor || len(raws) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return out, nil
+}
+
+func (c *Client) get(ctx context.Context, method string, q url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/xrpc/"+method+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// XRPC errors carry a name and message, e.g., UnknownFeed.
+		var e struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("bluesky %s: status %d: %s: %s", method, resp.StatusCode, e.Error, e.Message)
+		}
+		return nil, fmt.Errorf("bluesky %s: status %d", method, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// item maps a post to a NewsItem: likes are its points, the text its content, and
+// its first line (shortened) its title, or "Post by @<handle>" for a post without
+// text, e.g., an image. The post links to its bsky.app page; the
+// ID is a short hash of its at:// URI.
+func (p post) item(node string) model.NewsItem {
+	text := strings.TrimSpace(p.Record.Text)
+	created := p.Record.CreatedAt
+	if created.IsZero() {
+		created = p.IndexedAt
+	}
+	t := title(text)
+	if t == "" {
+		t = "Post by @" + p.Author.Handle
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        ItemID(p.URI),
+		Title:     t,
+		URL:       PostURL(p.URI, p.Author.Handle),
+		NodeName:  node,
+		Replies:   p.ReplyCount,
+		Points:    p.LikeCount,
+		CreatedAt: created.UTC(),
+		Content:   text,
+		Author:    p.Author.Handle,
+	}
+}
+
+// title returns the first line of text, cut at titleRunes with an ellipsis.
+func title(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= titleRunes {
+		return line
+	}
+	r := []rune(line)
+	return strings.TrimSpace(string(r[:titleRunes-1])) + "…"
+}
+
+// PostURL returns the bsky.app page of the post at uri
+// (at://<did>/app.bsky.feed.post/<rkey>), under the author's handle when known.
+func PostURL(uri, handle string) string {
+	rest := strings.TrimPrefix(uri, "at://")
+	did, path, _ := strings.Cut(rest, "/")
+	rkey := path[strings.LastIndex(path, "/")+1:]
+	who := strings.TrimSpace(handle)
+	if who == "" {
+		who = did
+	}
+	return SiteURL + "/profile/" + who + "/post/" + rkey
+}
+
+// ItemID returns the stored ID of the post at uri: a short hash, compact and safe in
+// Redis keys and file names.
+func ItemID(uri string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(uri)))
+	return hex.EncodeToString(sum[:8])
+}
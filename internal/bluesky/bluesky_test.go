@@ -0,0 +1,118 @@
+package bluesky
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestFeed(t *testing.T) {
+	pages := map[string]string{"": "testdata/feed_page1.json", "page2": "testdata/feed_page2.json"}
+	var limits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/xrpc/app.bsky.feed.getFeed" || q.Get("feed") != "at://did:plc:gofeed/app.bsky.feed.generator/golang" {
+			http.Error(w, `{"error":"UnknownFeed","message":"could not find feed"}`, http.StatusBadRequest)
+			return
+		}
+		limits = append(limits, q.Get("limit"))
+		b, err := os.ReadFile(pages[q.Get("cursor")])
+		if err != nil {
+			http.Error(w, "no page", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	// The repost of Alice's post on page two is dropped, and the third post reaches the limit.
+	items, err := c.Feed(context.Background(), "at://did:plc:gofeed/app.bsky.feed.generator/golang", "golang", 3)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("Feed = %d items, %v", len(items), err)
+	}
+	if strings.Join(limits, ",") != "3,1" {
+		t.Errorf("limits asked = %v, want [3 1]", limits)
+	}
+	if !strings.Contains(string(items[0].Raw), `"cid": "bafyreia1"`) {
+		t.Errorf("items[0].Raw = %s, want the post as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        ItemID("at://did:plc:alice111/app.bsky.feed.post/3l4abcxyz001"),
+		Title:     "Go 1.24 is out: generic type aliases, faster maps",
+		URL:       "https://bsky.app/profile/alice.example.com/post/3l4abcxyz001",
+		NodeName:  "golang",
+		Replies:   12,
+		Points:    240,
+		CreatedAt: time.Date(2025, 10, 24, 8, 0, 0, 0, time.UTC),
+		Content:   "Go 1.24 is out: generic type aliases, faster maps\n\nRelease notes below.",
+		Author:    "alice.example.com",
+	}, {
+		Source:    Source,
+		ID:        ItemID("at://did:plc:bob222/app.bsky.feed.post/3l4abcxyz002"),
+		Title:     "Post by @bob.bsky.social",
+		URL:       "https://bsky.app/profile/bob.bsky.social/post/3l4abcxyz002",
+		NodeName:  "golang",
+		Points:    15,
+		CreatedAt: time.Date(2025, 10, 24, 7, 30, 0, 0, time.UTC),
+		Author:    "bob.bsky.social",
+	}, {
+		Source:    Source,
+		ID:        ItemID("at://did:plc:dave444/app.bsky.feed.post/3l4abcxyz003"),
+		Title:     "Notes from the Go meetup",
+		URL:       "https://bsky.app/profile/did:plc:dave444/post/3l4abcxyz003",
+		NodeName:  "golang",
+		Replies:   3,
+		Points:    40,
+		CreatedAt: time.Date(2025, 10, 24, 6, 0, 0, 0, time.UTC),
+		Content:   "Notes from the Go meetup",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+
+	if _, err := c.Feed(context.Background(), "at://nope", "x", 0); err == nil || !strings.Contains(err.Error(), "UnknownFeed: could not find feed") {
+		t.Errorf("unknown feed err = %v", err)
+	}
+}
+
+func TestSearchPosts(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		q := r.URL.Query()
+		if r.URL.Path != "/xrpc/app.bsky.feed.searchPosts" || q.Get("q") != "golang" || q.Get("sort") != "latest" || q.Get("limit") != "100" {
+			http.Error(w, "bad request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		// No cursor: the results end after this page.
+		_, _ = w.Write([]byte(`{"posts": [{"uri": "at://did:plc:erin/app.bsky.feed.post/3l4s1", "author": {"handle": "erin.bsky.social"}, "record": {"text": "Why I moved our services to Go", "createdAt": "2025-10-24T09:00:00Z"}, "replyCount": 1, "likeCount": 9}]}`))
+	}))
+	defer srv.Close()
+
+	items, err := NewClient(srv.URL).SearchPosts(context.Background(), "golang", "go-search", 0)
+	if err != nil || len(items) != 1 || calls != 1 {
+		t.Fatalf("SearchPosts = %d items, %v after %d calls", len(items), err, calls)
+	}
+	if it := items[0]; it.NodeName != "go-search" || it.Points != 9 || it.Replies != 1 || it.Title != "Why I moved our services to Go" {
+		t.Errorf("item = %+v", it)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	if got := title(long); len([]rune(got)) != titleRunes || !strings.HasSuffix(got, "…") {
+		t.Errorf("title(long) = %q (%d runes)", got, len([]rune(got)))
+	}
+}
@@ -1,8 +1,22 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/version"
+)
+
 // AppConfig holds application-level settings.
 type AppConfig struct {
-	LogLevel string `mapstructure:"log_level"`
+	LogLevel  string `mapstructure:"log_level"`  // debug, info, warn, or error
+	LogFormat string `mapstructure:"log_format"` // text (default) or json
+	LogFile   string `mapstructure:"log_file"`   // append logs to this file instead of stderr
+	UserAgent string `mapstructure:"user_agent"` // sent on outbound source/scrape requests; default "quaily-journalist/<version>"
 }
 
 // RedisConfig holds redis connection settings.
@@ -14,21 +28,251 @@ type RedisConfig struct {
 
 // V2EXConfig controls the V2EX data source.
 type V2EXConfig struct {
-	Token         string `mapstructure:"token"`
-	BaseURL       string `mapstructure:"base_url"`
-	FetchInterval string `mapstructure:"fetch_interval"` // duration string, e.g., "5m"
+	Token           string `mapstructure:"token"`
+	BaseURL         string `mapstructure:"base_url"`
+	FetchInterval   string `mapstructure:"fetch_interval"`    // duration string, e.g., "5m"
+	MaxContentRunes int    `mapstructure:"max_content_runes"` // cap for cleaned topic content; 0 = 2000, -1 = no cap
+	// IncludeSupplements appends topic supplements ("附言 N:") to item content; requires token.
+	IncludeSupplements bool             `mapstructure:"include_supplements"`
+	Ranking            RankingConfig    `mapstructure:"ranking"`
+	QuietHours         QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+	// SearchAPI is the full-text search endpoint for "q:<query>" channel nodes,
+	// defaults to https://www.sov2ex.com/api/search.
+	SearchAPI string `mapstructure:"search_api"`
+	// SearchInterval is the least time between two searches of a query, e.g., "1h";
+	// collector runs in between reuse the last results. Empty means 1h.
+	SearchInterval string `mapstructure:"search_interval"`
+	// IncludePoints stores each topic's likes as its points, for ranking.signal
+	// points or blend; one API v2 call per fetched topic, requires token.
+	IncludePoints bool `mapstructure:"include_points"`
 }
 
 // HackerNewsConfig controls the Hacker News data source.
 type HackerNewsConfig struct {
 	BaseAPI       string `mapstructure:"base_api"`       // API base, defaults to https://hacker-news.firebaseio.com/v0
 	FetchInterval string `mapstructure:"fetch_interval"` // duration string, e.g., "10m"
+	AlgoliaAPI    string `mapstructure:"algolia_api"`    // HN Search API for backfill, defaults to https://hn.algolia.com/api/v1
+	ItemStaleness string `mapstructure:"item_staleness"` // re-fetch unchanged items after this long, e.g., "1h"; "0" disables change detection
+	// MaxFailRatio is the fraction (0..1) of a run's item fetches that may fail before
+	// the run reports an error; unset uses 0.5, 0 makes any failure an error.
+	MaxFailRatio *float64         `mapstructure:"max_fail_ratio"`
+	Ranking      RankingConfig    `mapstructure:"ranking"`
+	QuietHours   QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// RSSConfig controls the RSS/Atom feed source.
+type RSSConfig struct {
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "30m"
+	Feeds         []RSSFeedConfig  `mapstructure:"feeds"`
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// RSSFeedConfig is one polled feed. Node is the node name its items are stored
+// under, which rss channels list in nodes; empty uses the feed's title.
+type RSSFeedConfig struct {
+	URL  string `mapstructure:"url"`
+	Node string `mapstructure:"node"`
+}
+
+// LobstersConfig controls the Lobste.rs source. Channels of source lobsters list
+// tags in nodes; every story of the polled lists is stored under its tags.
+type LobstersConfig struct {
+	BaseURL       string           `mapstructure:"base_url"`       // site serving the JSON listings, e.g., https://lobste.rs
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "15m"
+	Lists         []string         `mapstructure:"lists"`          // hottest and/or newest; empty = hottest
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// RedditConfig controls the Reddit source. Channels of source reddit list
+// subreddits in nodes; the collector polls the hot listing of each.
+type RedditConfig struct {
+	BaseURL       string `mapstructure:"base_url"`       // default https://www.reddit.com
+	FetchInterval string `mapstructure:"fetch_interval"` // duration string, e.g., "15m"
+	// UserAgent identifies the app to Reddit, which throttles generic agents; the
+	// default is "go:quaily-journalist:<version>". Reddit asks for
+	// "<platform>:<app ID>:<version> (by /u/<username>)".
+	UserAgent  string           `mapstructure:"user_agent"`
+	Ranking    RankingConfig    `mapstructure:"ranking"`
+	QuietHours QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// GitHubConfig controls the GitHub trending source. Channels of source github list
+// languages in nodes; the collector searches each for the most-starred repositories
+// created within CreatedWithin, as GitHub has no trending API.
+type GitHubConfig struct {
+	BaseURL string `mapstructure:"base_url"` // default https://api.github.com
+	// Token is optional; it raises the search limit from 10 to 30 requests a minute.
+	Token         string           `mapstructure:"token"`
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "1h"
+	CreatedWithin string           `mapstructure:"created_within"` // duration string; default "168h" (7 days)
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// ProductHuntConfig controls the Product Hunt source, the day's launches read from
+// the GraphQL API. Launches are stored under their topics; channels of source
+// producthunt pick topics in nodes (e.g., developer-tools), or take every launch.
+type ProductHuntConfig struct {
+	BaseURL       string           `mapstructure:"base_url"`       // default https://api.producthunt.com/v2/api/graphql
+	Token         string           `mapstructure:"token"`          // developer token of an API application
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "30m"
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// ArxivConfig controls the arXiv source. Channels of source arxiv list categories
+// (e.g., cs.CL) in nodes; the collector reads the newest submissions of each.
+type ArxivConfig struct {
+	BaseURL       string           `mapstructure:"base_url"`       // default https://export.arxiv.org
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "1h"
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// MastodonConfig controls the Mastodon trending links source. Links are stored under
+// the host of the instance they trend on; channels of source mastodon pick hosts in
+// nodes (e.g., mastodon.social), or mix every instance.
+type MastodonConfig struct {
+	Instances     []MastodonInstanceConfig `mapstructure:"instances"`
+	FetchInterval string                   `mapstructure:"fetch_interval"` // duration string, e.g., "30m"
+	Ranking       RankingConfig            `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig         `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// MastodonInstanceConfig is one polled instance.
+type MastodonInstanceConfig struct {
+	BaseURL string `mapstructure:"base_url"` // e.g., https://mastodon.social
+	Token   string `mapstructure:"token"`    // optional; for instances that keep trends private
+}
+
+// BlueskyConfig controls the Bluesky source, custom feeds and searches read from
+// the public AppView. Posts are stored under each feed's name; channels of source
+// bluesky pick names in nodes.
+type BlueskyConfig struct {
+	BaseURL       string              `mapstructure:"base_url"` // default https://public.api.bsky.app
+	Feeds         []BlueskyFeedConfig `mapstructure:"feeds"`
+	Limit         int                 `mapstructure:"limit"`          // posts read per feed or search; 0 = 100
+	FetchInterval string              `mapstructure:"fetch_interval"` // duration string, e.g., "30m"
+	Ranking       RankingConfig       `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig    `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// BlueskyFeedConfig is one polled custom feed (an at:// feed generator URI) or
+// search query; exactly one of Feed and Query is set. Name is the node its posts
+// are stored under; empty uses the query, or the feed's record key.
+type BlueskyFeedConfig struct {
+	Name  string `mapstructure:"name"`
+	Feed  string `mapstructure:"feed"`
+	Query string `mapstructure:"query"`
+}
+
+// Node returns the node the feed's posts are stored under.
+func (f BlueskyFeedConfig) Node() string {
+	if n := strings.TrimSpace(f.Name); n != "" {
+		return n
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		return q
+	}
+	feed := strings.TrimRight(strings.TrimSpace(f.Feed), "/")
+	return feed[strings.LastIndex(feed, "/")+1:]
+}
+
+// StackOverflowConfig controls the Stack Overflow source, the hot questions of a
+// Stack Exchange site. Questions are stored under the tag they were searched with;
+// channels of source stackoverflow pick tags in nodes (e.g., go), or take the
+// site's whole hot list, stored under each question's first tag.
+type StackOverflowConfig struct {
+	BaseURL string `mapstructure:"base_url"` // default https://api.stackexchange.com
+	Site    string `mapstructure:"site"`     // API site parameter; default stackoverflow
+	// Key is optional; it raises the daily quota from 300 to 10,000 requests.
+	Key           string           `mapstructure:"key"`
+	FetchInterval string           `mapstructure:"fetch_interval"` // duration string, e.g., "1h"
+	Ranking       RankingConfig    `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// JSONFeedConfig controls the JSON Feed (https://jsonfeed.org) source, for sites
+// that publish no RSS or Atom.
+type JSONFeedConfig struct {
+	FetchInterval string               `mapstructure:"fetch_interval"` // duration string, e.g., "30m"
+	Feeds         []JSONFeedFeedConfig `mapstructure:"feeds"`
+	Ranking       RankingConfig        `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig     `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// JSONFeedFeedConfig is one polled feed. Label is the node name its items are
+// stored under, which jsonfeed channels list in nodes; empty uses the feed's title.
+type JSONFeedFeedConfig struct {
+	URL   string `mapstructure:"url"`
+	Label string `mapstructure:"label"`
+}
+
+// YouTubeConfig controls the YouTube source, the newest videos of channels and
+// playlists read from their public feeds. With an API key, each video's likes and
+// comments are looked up in the Data API, so videos rank by likes instead of age.
+type YouTubeConfig struct {
+	BaseURL    string `mapstructure:"base_url"`     // feeds; default https://www.youtube.com
+	APIBaseURL string `mapstructure:"api_base_url"` // default https://www.googleapis.com/youtube/v3
+	// APIKey is optional; every polled channel or playlist costs one unit of the
+	// Data API's daily quota per run.
+	APIKey        string              `mapstructure:"api_key"`
+	Feeds         []YouTubeFeedConfig `mapstructure:"feeds"`
+	FetchInterval string              `mapstructure:"fetch_interval"` // duration string, e.g., "1h"
+	Ranking       RankingConfig       `mapstructure:"ranking"`
+	QuietHours    QuietHoursConfig    `mapstructure:"quiet_hours"` // no polling inside this daily window
+}
+
+// YouTubeFeedConfig is one polled channel (UC...) or playlist (PL...); exactly one
+// of ChannelID and PlaylistID is set. Label is the node its videos are stored
+// under, which youtube channels list in nodes; empty uses the uploading channel's
+// title.
+type YouTubeFeedConfig struct {
+	ChannelID  string `mapstructure:"channel_id"`
+	PlaylistID string `mapstructure:"playlist_id"`
+	Label      string `mapstructure:"label"`
+}
+
+// RankingConfig tunes the popularity score (count-1) / (age_hours+age_offset_hours)^gravity.
+// Zero fields keep the defaults: gravity 1.8, age offset 2h, and the source's signal
+// (replies for V2EX, points for Hacker News).
+type RankingConfig struct {
+	Gravity     float64 `mapstructure:"gravity"`
+	AgeOffset   float64 `mapstructure:"age_offset_hours"`
+	Signal      string  `mapstructure:"signal"`       // replies | points | blend
+	ReplyWeight float64 `mapstructure:"reply_weight"` // blend only; both weights 0 = 1 each
+	PointWeight float64 `mapstructure:"point_weight"`
+	// RecencyBoost is what an item without replies or points counts as above the
+	// floor on sources ranked by recency (rss, arxiv); 0 = 1.
+	RecencyBoost float64 `mapstructure:"recency_boost"`
 }
 
+// IsZero reports whether no ranking parameter is set.
+func (r RankingConfig) IsZero() bool { return r == RankingConfig{} }
+
 // DataSources groups available collectors.
 type DataSources struct {
 	V2EX V2EXConfig       `mapstructure:"v2ex"`
 	HN   HackerNewsConfig `mapstructure:"hackernews"`
+	// ResumeRatio skips a collector's initial run after a restart when its last run was
+	// within this fraction of fetch_interval; 0 = 0.5, negative always runs on startup.
+	ResumeRatio float64   `mapstructure:"resume_ratio"`
+	RSS         RSSConfig `mapstructure:"rss"`
+	// ArchiveRaw makes collectors keep each item's source payload (compressed, 48h,
+	// up to 256 KiB) for "item show --raw"; off by default for the memory it costs.
+	ArchiveRaw    bool                `mapstructure:"archive_raw"`
+	Lobsters      LobstersConfig      `mapstructure:"lobsters"`
+	Reddit        RedditConfig        `mapstructure:"reddit"`
+	GitHub        GitHubConfig        `mapstructure:"github"`
+	ProductHunt   ProductHuntConfig   `mapstructure:"producthunt"`
+	Arxiv         ArxivConfig         `mapstructure:"arxiv"`
+	Mastodon      MastodonConfig      `mapstructure:"mastodon"`
+	Bluesky       BlueskyConfig       `mapstructure:"bluesky"`
+	StackOverflow StackOverflowConfig `mapstructure:"stackoverflow"`
+	JSONFeed      JSONFeedConfig      `mapstructure:"jsonfeed"`
+	YouTube       YouTubeConfig       `mapstructure:"youtube"`
 }
 
 // OpenAIConfig holds OpenAI settings.
@@ -36,6 +280,12 @@ type OpenAIConfig struct {
 	APIKey  string `mapstructure:"api_key"`
 	Model   string `mapstructure:"model"`
 	BaseURL string `mapstructure:"base_url"`
+	// MaxConcurrentRequests caps in-flight OpenAI requests across all channels of the
+	// process; 0 = 4, negative = unlimited.
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+	// WhyItMattersPrompt instructs the takeaway of channels with why_it_matters; empty
+	// uses the built-in prompt.
+	WhyItMattersPrompt string `mapstructure:"why_it_matters_prompt"`
 }
 
 // SusanooConfig holds Susanoo image generation settings.
@@ -51,11 +301,12 @@ type SusanooConfig struct {
 
 // NewsletterConfig controls publication logic.
 type NewslettersConfig struct {
-	Frequency string          `mapstructure:"frequency"` // default frequency
-	TopN      int             `mapstructure:"top_n"`     // default top N
-	MinItems  int             `mapstructure:"min_items"` // default min items
-	OutputDir string          `mapstructure:"output_dir"`
-	Channels  []ChannelConfig `mapstructure:"channels"`
+	Frequency            string          `mapstructure:"frequency"` // default frequency
+	TopN                 int             `mapstructure:"top_n"`     // default top N
+	MinItems             int             `mapstructure:"min_items"` // default min items
+	OutputDir            string          `mapstructure:"output_dir"`
+	MinContentRunesForAI int             `mapstructure:"min_content_runes_for_ai"` // items with less content skip AI summaries; 0 disables
+	Channels             []ChannelConfig `mapstructure:"channels"`
 }
 
 // ChannelTemplate groups text fields for rendering.
@@ -74,23 +325,271 @@ type ChannelConfig struct {
 	MinItems         int             `mapstructure:"min_items"`
 	Nodes            []string        `mapstructure:"nodes"`              // source-specific nodes (e.g., V2EX node names)
 	ItemSkipDuration string          `mapstructure:"item_skip_duration"` // e.g., "72h"
+	OutputDir        string          `mapstructure:"output_dir"`         // overrides newsletters.output_dir
 	Template         ChannelTemplate `mapstructure:"template"`
 	// Legacy fields to maintain backward compatibility; copied into Template in FillDefaults.
 	PrefaceLegacy    string `mapstructure:"preface"`
 	PostscriptLegacy string `mapstructure:"postscript"`
 	Language         string `mapstructure:"language"` // e.g., "English", "中文", affects AI output
+	// MinContentRunesForAI overrides newsletters.min_content_runes_for_ai; 0 inherits, negative disables.
+	MinContentRunesForAI int               `mapstructure:"min_content_runes_for_ai"`
+	QualityGate          QualityGateConfig `mapstructure:"quality_gate"`
+	ShowAuthor           bool              `mapstructure:"show_author"` // render "by <author>" in each item's metadata line
+	// TitleDedupThreshold is the title similarity (0..1) above which items are treated as
+	// reposts and only the higher-scored one is kept; 0 uses the default (0.8), negative disables.
+	TitleDedupThreshold float64 `mapstructure:"title_dedup_threshold"`
+	// OutputLayout groups digests into subdirectories: flat (default), by_month, or by_year.
+	OutputLayout string `mapstructure:"output_layout"`
+	// Formats lists the files written per digest: markdown (default), html, json.
+	// Only the markdown file is published to Quaily.
+	Formats     []string          `mapstructure:"formats"`
+	Frontmatter FrontmatterConfig `mapstructure:"frontmatter"`
+	// PullQuote renders an AI-picked quote from the items between the preface and the summary.
+	PullQuote bool `mapstructure:"pull_quote"`
+	// WhyItMatters renders an AI-written, opinionated one-line takeaway in italics
+	// under each item's description; it comes from the same request as the description.
+	WhyItMatters bool `mapstructure:"why_it_matters"`
+	// Email sends each digest (HTML with a plain-text alternative) over SMTP after it is written.
+	Email    EmailConfig    `mapstructure:"email"`
+	Telegram TelegramConfig `mapstructure:"telegram"`
+	// Ranking overrides the source's ranking parameters for this channel: the builder
+	// rescores its candidates with them instead of using the collector scores.
+	Ranking RankingConfig `mapstructure:"ranking"`
+	// NodeWeights multiplies the scores of a node's items when the builder ranks them
+	// (node name → weight; unknown nodes weigh 1, 0 excludes the node).
+	NodeWeights map[string]float64 `mapstructure:"node_weights"`
+	// RepeatPenalty (0..1) scales an item's score by (1-repeat_penalty) for every earlier
+	// digest of the channel that included it; 0 disables.
+	RepeatPenalty float64 `mapstructure:"repeat_penalty"`
+	// IncludeTopComment quotes each Hacker News item's top-ranked top-level comment
+	// under it as a community highlight.
+	IncludeTopComment bool `mapstructure:"include_top_comment"`
+	// OnInsufficientItems decides what happens to a period that ended below min_items:
+	// skip (default) records and reports the skip; publish writes a light edition.
+	OnInsufficientItems string `mapstructure:"on_insufficient_items"`
+	// OnOversize fits a digest above quaily.max_content_bytes: trim (default) drops
+	// trailing items, split publishes "Part i/n" posts.
+	OnOversize string `mapstructure:"on_oversize"`
+	// MaxFetchDepth caps how many of the period's top items the builder, generate,
+	// and top read to find top_n candidates; 0 = 20×top_n, negative reads the whole
+	// period.
+	MaxFetchDepth int `mapstructure:"max_fetch_depth"`
+	// Site configures the static site written by "site build".
+	Site SiteConfig `mapstructure:"site"`
+	// PreviewUntil (RFC 3339) publishes the channel's digests to
+	// quaily.preview_channel_slug instead of the channel until that time.
+	PreviewUntil string `mapstructure:"preview_until"`
+	// ItemOrder lists a digest's selected items by score (default), chronological
+	// (oldest first), or node (alphabetically).
+	ItemOrder string `mapstructure:"item_order"`
+	// QuailyProfile names the quaily.profiles entry the channel publishes through;
+	// empty uses the default (the flat quaily.base_url/api_key).
+	QuailyProfile string `mapstructure:"quaily_profile"`
+	// Discord and Slack post a summary of each digest (title, summary, top items)
+	// to an incoming webhook after it is published.
+	Discord ChatWebhookConfig `mapstructure:"discord"`
+	Slack   ChatWebhookConfig `mapstructure:"slack"`
+	// PinLabel marks items pinned with the pin command in the digest, e.g.,
+	// "Editor's pick"; empty renders them unmarked.
+	PinLabel string `mapstructure:"pin_label"`
+	// MinReplies is the least replies a digest item needs (not on Hacker News); 0
+	// means 1, negative keeps items without replies that still score, such as V2EX
+	// posts with likes under a blend ranking.
+	MinReplies int `mapstructure:"min_replies"`
+	// DeriveFrom names a daily channel of the same source: this weekly channel then
+	// re-ranks the items that channel published during the week instead of the
+	// week's collected items, skipping node filters, once the week has ended.
+	DeriveFrom string `mapstructure:"derive_from"`
+	// Retention limits the digests kept on disk; Quaily stays the system of record.
+	Retention RetentionConfig `mapstructure:"retention"`
+	// Style is full (default) or minimal: a links-only digest built without AI
+	// summaries or a cover, so the channel runs with no OpenAI or Susanoo keys.
+	Style string `mapstructure:"style"`
+	// Navigation links each digest to the channel's previous (and, when an older
+	// period is regenerated, next) digest on the site at quaily.site_base_url.
+	Navigation bool `mapstructure:"navigation"`
+	// Summary turns the AI post summary and the short (zen) summary off separately.
+	Summary SummaryConfig `mapstructure:"summary"`
+	// QuietHours defers publishing while inside the window; the digest is
+	// evaluated and published on the first tick after it ends.
+	QuietHours QuietHoursConfig `mapstructure:"quiet_hours"`
+}
+
+// QuietHoursConfig is a daily window, e.g., 22:00 to 07:00, in which a source is not
+// polled or a channel does not publish. An end before the start spans midnight;
+// leaving both empty disables the window.
+type QuietHoursConfig struct {
+	Start    string `mapstructure:"start"`    // HH:MM
+	End      string `mapstructure:"end"`      // HH:MM
+	Timezone string `mapstructure:"timezone"` // IANA name, e.g., Europe/Berlin; empty = UTC
+}
+
+// Enabled reports whether a window is set.
+func (q QuietHoursConfig) Enabled() bool {
+	return strings.TrimSpace(q.Start) != "" || strings.TrimSpace(q.End) != ""
+}
+
+// Window parses Start, End (as minutes after midnight), and Timezone.
+func (q QuietHoursConfig) Window() (start, end int, loc *time.Location, err error) {
+	s, err := time.Parse("15:04", strings.TrimSpace(q.Start))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("quiet_hours.start must be HH:MM: %w", err)
+	}
+	e, err := time.Parse("15:04", strings.TrimSpace(q.End))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("quiet_hours.end must be HH:MM: %w", err)
+	}
+	start, end = s.Hour()*60+s.Minute(), e.Hour()*60+e.Minute()
+	if start == end {
+		return 0, 0, nil, errors.New("quiet_hours.start and quiet_hours.end must differ")
+	}
+	loc = time.UTC
+	if tz := strings.TrimSpace(q.Timezone); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid quiet_hours.timezone: %w", err)
+		}
+	}
+	return start, end, loc, nil
+}
+
+// RetentionConfig prunes a channel's digest files after each publish. A digest
+// counts once with all its formats, parts, and cover; digests a pending delivery
+// still reads are kept.
+type RetentionConfig struct {
+	KeepFiles int    `mapstructure:"keep_files"` // newest digests kept; 0 keeps all
+	Mode      string `mapstructure:"mode"`       // delete (default) | archive (move to <channel>/archive)
+}
+
+// PreviewUntilTime parses PreviewUntil; it is zero when unset.
+func (ch ChannelConfig) PreviewUntilTime() (time.Time, error) {
+	if strings.TrimSpace(ch.PreviewUntil) == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(ch.PreviewUntil))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("preview_until must be an RFC 3339 time (e.g., 2025-10-31T00:00:00Z): %w", err)
+	}
+	return t, nil
+}
+
+// SiteConfig is a channel's static site export. Template paths are html/template
+// files replacing the built-in page and index templates.
+type SiteConfig struct {
+	Title         string `mapstructure:"title"`    // default: the channel name
+	BaseURL       string `mapstructure:"base_url"` // where the site is served; makes feed links absolute
+	PageTemplate  string `mapstructure:"page_template"`
+	IndexTemplate string `mapstructure:"index_template"`
+}
+
+// EmailConfig is a channel's SMTP output. It is enabled when host and to are set
+// and needs the html format.
+type EmailConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"` // 0 = 587 for starttls, 465 for tls, 25 for none
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	// Subject supports {.Title} and {.CurrentDate}; default "{.Title}".
+	Subject            string `mapstructure:"subject"`
+	TLS                string `mapstructure:"tls"` // starttls (default) | tls | none
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// Enabled reports whether the channel sends digests by email.
+func (e EmailConfig) Enabled() bool { return e.Host != "" && len(e.To) > 0 }
+
+// TelegramConfig posts a channel's digests to a Telegram chat through the Bot API.
+// It is enabled when bot_token and chat_id are set and needs the markdown format.
+type TelegramConfig struct {
+	BotToken string `mapstructure:"bot_token"`
+	ChatID   string `mapstructure:"chat_id"`  // e.g., "@mychannel" or a numeric ID
+	Format   string `mapstructure:"format"`   // markdownv2 (default) | text
+	BaseURL  string `mapstructure:"base_url"` // default https://api.telegram.org
+}
+
+// Enabled reports whether the channel posts digests to Telegram.
+func (t TelegramConfig) Enabled() bool { return t.BotToken != "" && t.ChatID != "" }
+
+// ChatWebhookConfig posts digest summaries to a Discord or Slack incoming webhook.
+// It is enabled when webhook_url is set and needs the markdown format.
+type ChatWebhookConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// Enabled reports whether the channel posts digest summaries to the webhook.
+func (c ChatWebhookConfig) Enabled() bool { return strings.TrimSpace(c.WebhookURL) != "" }
+
+// FrontmatterConfig enables optional, AI-generated frontmatter keys.
+type FrontmatterConfig struct {
+	// SEO adds seo_description (at most 160 characters) and keywords, cached per period.
+	SEO bool `mapstructure:"seo"`
+}
+
+// SummaryConfig toggles a digest's two post-level summaries; both default to on.
+// A disabled summary costs no AI call and gets no title-based fallback either.
+type SummaryConfig struct {
+	Post  *bool `mapstructure:"post"`  // the summary at the top of the body
+	Short *bool `mapstructure:"short"` // the teaser in the frontmatter and meta description
+}
+
+// PostEnabled reports whether the digest gets a post summary.
+func (c SummaryConfig) PostEnabled() bool { return c.Post == nil || *c.Post }
+
+// ShortEnabled reports whether the digest gets a short summary.
+func (c SummaryConfig) ShortEnabled() bool { return c.Short == nil || *c.Short }
+
+// QualityGateConfig enables an opt-in AI relevance check per channel.
+// Every candidate item costs one AI call (cached per item), so keep it off unless needed.
+type QualityGateConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Description string  `mapstructure:"description"` // topic statement items are rated against
+	Threshold   float64 `mapstructure:"threshold"`   // 0..1, items scoring below are dropped; default 0.5
+}
+
+// HTTPClientConfig tunes an outbound HTTP client. Zero values inherit from http.default,
+// then from the built-in per-service defaults.
+type HTTPClientConfig struct {
+	Timeout            string  `mapstructure:"timeout"`              // duration string, e.g., "15s"
+	Proxy              string  `mapstructure:"proxy"`                // proxy URL; empty uses HTTP(S)_PROXY env, "direct" bypasses proxies
+	MaxIdleConns       int     `mapstructure:"max_idle_conns"`       // per client; default 100
+	InsecureSkipVerify bool    `mapstructure:"insecure_skip_verify"` // skip TLS verification, e.g., for self-hosted Quaily
+	MaxRetries         int     `mapstructure:"max_retries"`          // retries for GET/idempotent requests on 429/5xx/resets; 0 = 2, negative disables
+	MaxPerHost         int     `mapstructure:"max_per_host"`         // concurrent in-flight requests per host; 0 = unlimited
+	RateLimit          float64 `mapstructure:"rate_limit"`           // requests per second per client; 0 = unlimited
+	// Headers are added to every request unless the client already set them;
+	// service headers override default ones with the same name, including User-Agent.
+	Headers map[string]string `mapstructure:"headers"`
+	// MaxPerHour is the service's request budget, shared by every client of the
+	// process and refilled evenly over the hour; 0 = unlimited.
+	MaxPerHour int `mapstructure:"max_per_hour"`
+	// BreakerThreshold consecutive 403/429 responses open the service's circuit
+	// for BreakerCooldown; 0 or negative disables it. Cooldown default "15m".
+	BreakerThreshold int    `mapstructure:"breaker_threshold"`
+	BreakerCooldown  string `mapstructure:"breaker_cooldown"`
+}
+
+// HTTPConfig groups outbound HTTP client settings: a default block plus per-service
+// overrides keyed by v2ex, hackernews, quaily, cloudflare, susanoo.
+type HTTPConfig struct {
+	Default  HTTPClientConfig            `mapstructure:"default"`
+	Services map[string]HTTPClientConfig `mapstructure:"services"`
 }
 
 // Config is the top-level configuration structure.
 type Config struct {
 	App         AppConfig         `mapstructure:"app"`
 	Redis       RedisConfig       `mapstructure:"redis"`
+	Storage     StorageConfig     `mapstructure:"storage"`
 	Sources     DataSources       `mapstructure:"sources"`
 	OpenAI      OpenAIConfig      `mapstructure:"openai"`
 	Susanoo     SusanooConfig     `mapstructure:"susanoo"`
 	Newsletters NewslettersConfig `mapstructure:"newsletters"`
 	Quaily      QuailyConfig      `mapstructure:"quaily"`
 	Cloudflare  CloudflareConfig  `mapstructure:"cloudflare"`
+	HTTP        HTTPConfig        `mapstructure:"http"`
+	Notify      NotifyConfig      `mapstructure:"notify"`
+	Reporting   ReportingConfig   `mapstructure:"reporting"`
 }
 
 // FillDefaults applies default values if not provided.
@@ -98,6 +597,9 @@ func (c *Config) FillDefaults() {
 	if c.App.LogLevel == "" {
 		c.App.LogLevel = "info"
 	}
+	if c.App.UserAgent == "" {
+		c.App.UserAgent = "quaily-journalist/" + version.Version
+	}
 	if c.Susanoo.Model == "" {
 		c.Susanoo.Model = "gemini-2.5-flash"
 	}
@@ -110,14 +612,462 @@ func (c *Config) FillDefaults() {
 	if c.Susanoo.WebPQuality == 0 {
 		c.Susanoo.WebPQuality = 85
 	}
+	if c.Sources.HN.ItemStaleness == "" {
+		c.Sources.HN.ItemStaleness = "1h"
+	}
+	if c.Sources.RSS.FetchInterval == "" {
+		c.Sources.RSS.FetchInterval = "30m"
+	}
+	if c.Sources.Lobsters.FetchInterval == "" {
+		c.Sources.Lobsters.FetchInterval = "15m"
+	}
+	if c.Sources.Reddit.FetchInterval == "" {
+		c.Sources.Reddit.FetchInterval = "15m"
+	}
+	if c.Sources.GitHub.FetchInterval == "" {
+		c.Sources.GitHub.FetchInterval = "1h"
+	}
+	if c.Sources.GitHub.CreatedWithin == "" {
+		c.Sources.GitHub.CreatedWithin = "168h"
+	}
+	if c.Sources.ProductHunt.FetchInterval == "" {
+		c.Sources.ProductHunt.FetchInterval = "30m"
+	}
+	if c.Sources.Arxiv.FetchInterval == "" {
+		c.Sources.Arxiv.FetchInterval = "1h"
+	}
+	if c.Sources.Mastodon.FetchInterval == "" {
+		c.Sources.Mastodon.FetchInterval = "30m"
+	}
+	if c.Sources.Bluesky.FetchInterval == "" {
+		c.Sources.Bluesky.FetchInterval = "30m"
+	}
+	if c.Sources.StackOverflow.FetchInterval == "" {
+		c.Sources.StackOverflow.FetchInterval = "1h"
+	}
+	if c.Sources.JSONFeed.FetchInterval == "" {
+		c.Sources.JSONFeed.FetchInterval = "30m"
+	}
+	if c.Sources.YouTube.FetchInterval == "" {
+		c.Sources.YouTube.FetchInterval = "1h"
+	}
+}
+
+// MinContentRunesForAI resolves the thin-content threshold for a channel:
+// the channel override when set, otherwise the newsletters default. Values <= 0 disable the check.
+func (c Config) MinContentRunesForAI(ch ChannelConfig) int {
+	n := c.Newsletters.MinContentRunesForAI
+	if ch.MinContentRunesForAI != 0 {
+		n = ch.MinContentRunesForAI
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Channel is a channel with the newsletters-level settings applied; the builder and
+// the generate command both run from it, so they write to the same place.
+type Channel struct {
+	Config    ChannelConfig // the channel block as configured
+	Name      string
+	Source    string // lowercased
+	Frequency string // lowercased
+	TopN      int
+	MinItems  int
+	OutputDir string // the channel's output_dir, else newsletters.output_dir
+	Nodes     []string
+	Template  ChannelTemplate
+	Language  string
+	// MinContentRunesForAI is the resolved thin-content threshold; 0 disables it.
+	MinContentRunesForAI int
+}
+
+// checkDeriveFrom reports a derive_from that does not name a daily channel of the
+// same source, or that is set on a channel that is not weekly.
+func (c Config) checkDeriveFrom(ch ChannelConfig) error {
+	name := strings.TrimSpace(ch.DeriveFrom)
+	if name == "" {
+		return nil
+	}
+	from, ok := c.FindChannel(name)
+	switch {
+	case !ok:
+		return fmt.Errorf("derive_from channel %q is not defined", name)
+	case c.ResolveChannel(ch).Frequency != "weekly":
+		return errors.New("derive_from needs frequency weekly")
+	case from.Frequency == "weekly":
+		return fmt.Errorf("derive_from channel %s is not daily", name)
+	case from.Source != strings.ToLower(strings.TrimSpace(ch.Source)):
+		return fmt.Errorf("derive_from channel %s reads source %s, not %s", name, from.Source, ch.Source)
+	}
+	return nil
+}
+
+// ResolveChannel applies the newsletters-level settings to a channel block.
+func (c Config) ResolveChannel(ch ChannelConfig) Channel {
+	dir := ch.OutputDir
+	if strings.TrimSpace(dir) == "" {
+		dir = c.Newsletters.OutputDir
+	}
+	return Channel{
+		Config:               ch,
+		Name:                 ch.Name,
+		Source:               strings.ToLower(ch.Source),
+		Frequency:            strings.ToLower(ch.Frequency),
+		TopN:                 ch.TopN,
+		MinItems:             ch.MinItems,
+		OutputDir:            dir,
+		Nodes:                ch.Nodes,
+		Template:             ch.Template,
+		Language:             ch.Language,
+		MinContentRunesForAI: c.MinContentRunesForAI(ch),
+	}
+}
+
+// FindChannel returns the resolved channel named name; ok is false when none is configured.
+func (c Config) FindChannel(name string) (ch Channel, ok bool) {
+	for _, cc := range c.Newsletters.Channels {
+		if cc.Name == name {
+			return c.ResolveChannel(cc), true
+		}
+	}
+	return ch, false
+}
+
+// SourceRanking returns the ranking parameters configured for a source's collector.
+func (c Config) SourceRanking(source string) RankingConfig {
+	switch source {
+	case "v2ex":
+		return c.Sources.V2EX.Ranking
+	case "hackernews":
+		return c.Sources.HN.Ranking
+	case "rss":
+		return c.Sources.RSS.Ranking
+	case "lobsters":
+		return c.Sources.Lobsters.Ranking
+	case "reddit":
+		return c.Sources.Reddit.Ranking
+	case "github":
+		return c.Sources.GitHub.Ranking
+	case "producthunt":
+		return c.Sources.ProductHunt.Ranking
+	case "arxiv":
+		return c.Sources.Arxiv.Ranking
+	case "mastodon":
+		return c.Sources.Mastodon.Ranking
+	case "bluesky":
+		return c.Sources.Bluesky.Ranking
+	case "stackoverflow":
+		return c.Sources.StackOverflow.Ranking
+	case "jsonfeed":
+		return c.Sources.JSONFeed.Ranking
+	case "youtube":
+		return c.Sources.YouTube.Ranking
+	}
+	return RankingConfig{}
+}
+
+// SourceQuietHours returns the quiet hours of a source's collector, by the same
+// names as SourceRanking.
+func (c Config) SourceQuietHours(source string) QuietHoursConfig {
+	switch source {
+	case "v2ex":
+		return c.Sources.V2EX.QuietHours
+	case "hackernews":
+		return c.Sources.HN.QuietHours
+	case "rss":
+		return c.Sources.RSS.QuietHours
+	case "lobsters":
+		return c.Sources.Lobsters.QuietHours
+	case "reddit":
+		return c.Sources.Reddit.QuietHours
+	case "github":
+		return c.Sources.GitHub.QuietHours
+	case "producthunt":
+		return c.Sources.ProductHunt.QuietHours
+	case "arxiv":
+		return c.Sources.Arxiv.QuietHours
+	case "mastodon":
+		return c.Sources.Mastodon.QuietHours
+	case "bluesky":
+		return c.Sources.Bluesky.QuietHours
+	case "stackoverflow":
+		return c.Sources.StackOverflow.QuietHours
+	case "jsonfeed":
+		return c.Sources.JSONFeed.QuietHours
+	case "youtube":
+		return c.Sources.YouTube.QuietHours
+	}
+	return QuietHoursConfig{}
+}
+
+// checkSourceEndpoint reports what would fail the first request of the sources whose
+// base URL and credentials are optional: a base_url that is set but not an http(s)
+// URL, a token or key with whitespace inside (usually a bad paste), and a GitHub
+// created_within that is no positive duration.
+func (c Config) checkSourceEndpoint(src string) []error {
+	var baseURL, credKey, cred string
+	var errs []error
+	switch src {
+	case "github":
+		baseURL, credKey, cred = c.Sources.GitHub.BaseURL, "token", c.Sources.GitHub.Token
+		if d, err := time.ParseDuration(strings.TrimSpace(c.Sources.GitHub.CreatedWithin)); c.Sources.GitHub.CreatedWithin != "" && (err != nil || d <= 0) {
+			errs = append(errs, fmt.Errorf("sources.github.created_within %q is not a positive duration", c.Sources.GitHub.CreatedWithin))
+		}
+	case "arxiv":
+		baseURL = c.Sources.Arxiv.BaseURL
+	case "stackoverflow":
+		baseURL, credKey, cred = c.Sources.StackOverflow.BaseURL, "key", c.Sources.StackOverflow.Key
+		if strings.ContainsAny(strings.TrimSpace(c.Sources.StackOverflow.Site), " /") {
+			errs = append(errs, fmt.Errorf("sources.stackoverflow.site %q is not a site name (e.g., stackoverflow, superuser)", c.Sources.StackOverflow.Site))
+		}
+	default:
+		return nil
+	}
+	if s := strings.TrimSpace(baseURL); s != "" {
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("sources.%s.base_url %q is not an http(s) URL", src, baseURL))
+		}
+	}
+	if strings.ContainsAny(strings.TrimSpace(cred), " \t\r\n") {
+		errs = append(errs, fmt.Errorf("sources.%s.%s contains whitespace", src, credKey))
+	}
+	return errs
+}
+
+// Validate reports, all at once, settings serve would otherwise silently ignore: a
+// channel whose source has no collector (V2EX without a token, Hacker News without
+// base_api, RSS without feeds, Lobste.rs without base_url, Product Hunt without a
+// token, Mastodon without instances, Bluesky, JSON Feed, or YouTube without feeds,
+// or an unknown source),
+// a Hacker News max_fail_ratio outside 0..1, a Bluesky feed setting both or neither of feed and query, a YouTube feed setting
+// both or neither of channel_id and playlist_id, a Reddit, GitHub, or arXiv channel without nodes, a
+// channel naming an undefined quaily_profile, a Quaily API key without a base URL,
+// Susanoo or Cloudflare configured with only one of their two credentials, a
+// malformed endpoint or credential of GitHub, arXiv, or Stack Overflow (see
+// checkSourceEndpoint), and
+// malformed quiet_hours of a source or channel.
+// mockSources skips the source checks, as fixtures replace the APIs.
+func (c Config) Validate(mockSources bool) error {
+	var errs []error
+	for _, ch := range c.Newsletters.Channels {
+		switch src := strings.ToLower(strings.TrimSpace(ch.Source)); {
+		case src != "v2ex" && src != "hackernews" && src != "rss" && src != "lobsters" && src != "reddit" && src != "github" && src != "producthunt" && src != "arxiv" && src != "mastodon" && src != "bluesky" && src != "stackoverflow" && src != "jsonfeed" && src != "youtube":
+			errs = append(errs, fmt.Errorf("channel %s: unknown source %q (want v2ex, hackernews, rss, lobsters, reddit, github, producthunt, arxiv, mastodon, bluesky, stackoverflow, jsonfeed, or youtube)", ch.Name, ch.Source))
+		case src == "reddit" && len(ch.Nodes) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source reddit needs nodes (subreddit names)", ch.Name))
+		case src == "github" && len(ch.Nodes) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source github needs nodes (languages, e.g., go)", ch.Name))
+		case src == "arxiv" && len(ch.Nodes) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source arxiv needs nodes (categories, e.g., cs.CL)", ch.Name))
+		case mockSources:
+		case src == "v2ex" && strings.TrimSpace(c.Sources.V2EX.Token) == "":
+			errs = append(errs, fmt.Errorf("channel %s: source v2ex needs sources.v2ex.token", ch.Name))
+		case src == "hackernews" && strings.TrimSpace(c.Sources.HN.BaseAPI) == "":
+			errs = append(errs, fmt.Errorf("channel %s: source hackernews needs sources.hackernews.base_api", ch.Name))
+		case src == "rss" && len(c.Sources.RSS.Feeds) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source rss needs sources.rss.feeds", ch.Name))
+		case src == "lobsters" && strings.TrimSpace(c.Sources.Lobsters.BaseURL) == "":
+			errs = append(errs, fmt.Errorf("channel %s: source lobsters needs sources.lobsters.base_url", ch.Name))
+		case src == "producthunt" && strings.TrimSpace(c.Sources.ProductHunt.Token) == "":
+			errs = append(errs, fmt.Errorf("channel %s: source producthunt needs sources.producthunt.token", ch.Name))
+		case src == "mastodon" && len(c.Sources.Mastodon.Instances) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source mastodon needs sources.mastodon.instances", ch.Name))
+		case src == "bluesky" && len(c.Sources.Bluesky.Feeds) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source bluesky needs sources.bluesky.feeds", ch.Name))
+		case src == "jsonfeed" && len(c.Sources.JSONFeed.Feeds) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source jsonfeed needs sources.jsonfeed.feeds", ch.Name))
+		case src == "youtube" && len(c.Sources.YouTube.Feeds) == 0:
+			errs = append(errs, fmt.Errorf("channel %s: source youtube needs sources.youtube.feeds", ch.Name))
+		}
+		if !mockSources {
+			for _, err := range c.checkSourceEndpoint(strings.ToLower(strings.TrimSpace(ch.Source))) {
+				errs = append(errs, fmt.Errorf("channel %s: %w", ch.Name, err))
+			}
+		}
+		if _, err := ch.PreviewUntilTime(); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", ch.Name, err))
+		} else if strings.TrimSpace(ch.PreviewUntil) != "" && strings.TrimSpace(c.Quaily.PreviewChannelSlug) == "" {
+			errs = append(errs, fmt.Errorf("channel %s: preview_until needs quaily.preview_channel_slug", ch.Name))
+		}
+		if ch.Navigation && strings.TrimSpace(c.Quaily.SiteBaseURL) == "" {
+			errs = append(errs, fmt.Errorf("channel %s: navigation needs quaily.site_base_url", ch.Name))
+		}
+		if _, ok := c.Quaily.Profile(ch.QuailyProfile); !ok {
+			errs = append(errs, fmt.Errorf("channel %s: quaily_profile %q is not defined under quaily.profiles", ch.Name, ch.QuailyProfile))
+		}
+		if err := c.checkDeriveFrom(ch); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", ch.Name, err))
+		}
+		if ch.QuietHours.Enabled() {
+			if _, _, _, err := ch.QuietHours.Window(); err != nil {
+				errs = append(errs, fmt.Errorf("channel %s: %w", ch.Name, err))
+			}
+		}
+	}
+	for _, src := range []string{"v2ex", "hackernews", "rss", "lobsters", "reddit", "github", "producthunt", "arxiv", "mastodon", "bluesky", "stackoverflow", "jsonfeed", "youtube"} {
+		if q := c.SourceQuietHours(src); q.Enabled() {
+			if _, _, _, err := q.Window(); err != nil {
+				errs = append(errs, fmt.Errorf("sources.%s.%w", src, err))
+			}
+		}
+	}
+	if r := c.Sources.HN.MaxFailRatio; r != nil && (*r < 0 || *r > 1) {
+		errs = append(errs, fmt.Errorf("sources.hackernews.max_fail_ratio must be between 0 and 1, got %v", *r))
+	}
+	for i, f := range c.Sources.Bluesky.Feeds {
+		if (strings.TrimSpace(f.Feed) == "") == (strings.TrimSpace(f.Query) == "") {
+			errs = append(errs, fmt.Errorf("sources.bluesky.feeds[%d]: set one of feed or query", i))
+		}
+	}
+	for i, f := range c.Sources.YouTube.Feeds {
+		if (strings.TrimSpace(f.ChannelID) == "") == (strings.TrimSpace(f.PlaylistID) == "") {
+			errs = append(errs, fmt.Errorf("sources.youtube.feeds[%d]: set one of channel_id or playlist_id", i))
+		}
+	}
+	// quaily.base_url alone is the usual setup without publishing.
+	if strings.TrimSpace(c.Quaily.APIKey) != "" && strings.TrimSpace(c.Quaily.BaseURL) == "" {
+		errs = append(errs, errors.New("quaily.api_key is set without quaily.base_url"))
+	}
+	for name, p := range c.Quaily.Profiles {
+		if strings.ToLower(name) == DefaultQuailyProfile && (strings.TrimSpace(c.Quaily.BaseURL) != "" || strings.TrimSpace(c.Quaily.APIKey) != "") {
+			errs = append(errs, errors.New("quaily.profiles.default and quaily.base_url/api_key both define the default profile; keep one"))
+		}
+		if strings.TrimSpace(p.APIKey) != "" && strings.TrimSpace(p.BaseURL) == "" {
+			errs = append(errs, fmt.Errorf("quaily.profiles.%s.api_key is set without base_url", name))
+		}
+	}
+	pairs := []struct{ name, a, b, aKey, bKey string }{
+		{"susanoo", c.Susanoo.BaseURL, c.Susanoo.APIKey, "base_url", "api_key"},
+		{"cloudflare", c.Cloudflare.AccountID, c.Cloudflare.APIToken, "account_id", "api_token"},
+	}
+	for _, p := range pairs {
+		if (strings.TrimSpace(p.a) == "") != (strings.TrimSpace(p.b) == "") {
+			errs = append(errs, fmt.Errorf("%s.%s and %s.%s must be set together", p.name, p.aKey, p.name, p.bKey))
+		}
+	}
+	if strings.TrimSpace(c.Reporting.DailyAt) != "" {
+		if _, _, _, err := c.Reporting.Schedule(); err != nil {
+			errs = append(errs, err)
+		}
+		if !c.Reporting.WriteFile && len(c.Notify.WebhookURLs) == 0 {
+			errs = append(errs, errors.New("reporting.daily_at needs notify.webhook_urls or reporting.write_file"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StorageConfig tunes how records are kept in Redis.
+type StorageConfig struct {
+	// CompressItems stores new item records gzip-compressed; existing plain
+	// records stay readable, so it can be turned on or off at any time.
+	CompressItems bool `mapstructure:"compress_items"`
 }
 
 // QuailyConfig holds Quaily API settings.
 type QuailyConfig struct {
+	// BaseURL and APIKey are the implicit "default" profile.
+	BaseURL string `mapstructure:"base_url"`
+	APIKey  string `mapstructure:"api_key"`
+	// Profiles are further Quaily accounts or instances by name, chosen per
+	// channel with quaily_profile. Names are case-insensitive.
+	Profiles map[string]QuailyProfile `mapstructure:"profiles"`
+	// DeliveryMaxAttempts is how many times a failed delivery is retried before it is
+	// dead-lettered; 0 = 8.
+	DeliveryMaxAttempts int `mapstructure:"delivery_max_attempts"`
+	// ReconcileDays is how many days back serve checks at startup for digests that were
+	// written but never pushed to Quaily; 0 = 3, negative disables the check.
+	ReconcileDays int `mapstructure:"reconcile_days"`
+	// MaxContentBytes caps the rendered markdown of a digest so Create Post does not
+	// reject it; 0 disables. Channels choose how to fit with on_oversize.
+	MaxContentBytes int `mapstructure:"max_content_bytes"`
+	// ExtraParams are frontmatter keys passed to Create Post in addition to
+	// quaily.DefaultParams; all other keys are dropped.
+	ExtraParams []string `mapstructure:"extra_params"`
+	// PreviewChannelSlug is the staging channel that channels with a future
+	// preview_until publish to instead of their own.
+	PreviewChannelSlug string `mapstructure:"preview_channel_slug"`
+	// SiteBaseURL is the public site posts are read on (e.g., https://quaily.com);
+	// channels with navigation link digests as <site_base_url>/<channel>/p/<slug>.
+	SiteBaseURL string `mapstructure:"site_base_url"`
+}
+
+// QuailyProfile is the address and key of one Quaily account.
+type QuailyProfile struct {
 	BaseURL string `mapstructure:"base_url"`
 	APIKey  string `mapstructure:"api_key"`
 }
 
+// Configured reports whether the profile can publish (both base_url and api_key set).
+func (p QuailyProfile) Configured() bool {
+	return strings.TrimSpace(p.BaseURL) != "" && strings.TrimSpace(p.APIKey) != ""
+}
+
+// DefaultQuailyProfile names the flat quaily.base_url/api_key, used by channels
+// without quaily_profile.
+const DefaultQuailyProfile = "default"
+
+// Profile returns the named Quaily profile; "" means DefaultQuailyProfile. The
+// default profile always exists (it may be unconfigured); ok is false for any
+// other name that quaily.profiles does not define.
+func (q QuailyConfig) Profile(name string) (p QuailyProfile, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultQuailyProfile
+	}
+	for n, p := range q.Profiles {
+		if strings.ToLower(n) == name {
+			return p, true
+		}
+	}
+	if name == DefaultQuailyProfile {
+		return QuailyProfile{BaseURL: q.BaseURL, APIKey: q.APIKey}, true
+	}
+	return QuailyProfile{}, false
+}
+
+// ProfileNames returns the defined Quaily profile names, lowercased and sorted,
+// including "default".
+func (q QuailyConfig) ProfileNames() []string {
+	names := []string{DefaultQuailyProfile}
+	for n := range q.Profiles {
+		if n = strings.ToLower(n); n != DefaultQuailyProfile {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NotifyConfig lists where operator notifications (e.g., dead-lettered deliveries) are sent.
+type NotifyConfig struct {
+	WebhookURLs []string `mapstructure:"webhook_urls"` // each receives a JSON POST per event
+}
+
+// ReportingConfig schedules the daily pipeline health report; it is off while
+// DailyAt is empty.
+type ReportingConfig struct {
+	DailyAt   string `mapstructure:"daily_at"`   // "HH:MM", in Timezone
+	Timezone  string `mapstructure:"timezone"`   // IANA name, e.g. Asia/Shanghai; default UTC
+	WriteFile bool   `mapstructure:"write_file"` // also write <newsletters.output_dir>/_health/YYYYMMDD.md
+}
+
+// Schedule parses DailyAt and Timezone.
+func (r ReportingConfig) Schedule() (hour, minute int, loc *time.Location, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(r.DailyAt))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("reporting.daily_at must be HH:MM: %w", err)
+	}
+	loc = time.UTC
+	if tz := strings.TrimSpace(r.Timezone); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid reporting.timezone: %w", err)
+		}
+	}
+	return t.Hour(), t.Minute(), loc, nil
+}
+
 // CloudflareConfig holds Cloudflare Browser Rendering API settings.
 type CloudflareConfig struct {
 	AccountID string `mapstructure:"account_id"` // Cloudflare account ID
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveChannelOutputDir(t *testing.T) {
+	c := Config{Newsletters: NewslettersConfig{
+		OutputDir: "out",
+		Channels: []ChannelConfig{
+			{Name: "own", Source: "V2EX", Frequency: "Daily", OutputDir: "site/content/digests"},
+			{Name: "inherits", Source: "hackernews", OutputDir: "  "},
+		},
+	}}
+
+	ch, ok := c.FindChannel("own")
+	if !ok {
+		t.Fatal("channel own not found")
+	}
+	if ch.OutputDir != "site/content/digests" || ch.Source != "v2ex" || ch.Frequency != "daily" || ch.Config.Name != "own" {
+		t.Errorf("own = %+v", ch)
+	}
+	if ch, _ := c.FindChannel("inherits"); ch.OutputDir != "out" {
+		t.Errorf("blank output_dir should fall back to newsletters.output_dir, got %q", ch.OutputDir)
+	}
+	if _, ok := c.FindChannel("missing"); ok {
+		t.Error("FindChannel(missing) = ok")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := Config{
+		Sources: DataSources{V2EX: V2EXConfig{Token: "t"}},
+		Newsletters: NewslettersConfig{Channels: []ChannelConfig{
+			{Name: "v", Source: "V2EX"},
+			{Name: "hn", Source: "hackernews"},
+			{Name: "typo", Source: "myspace"},
+			{Name: "subs", Source: "reddit"},
+			{Name: "repos", Source: "github"},
+			{Name: "when", Source: "v2ex", PreviewUntil: "next friday"},
+			{Name: "where", Source: "v2ex", PreviewUntil: "2025-10-31T00:00:00Z"},
+			{Name: "feeds", Source: "rss"},
+			{Name: "lob", Source: "lobsters"},
+			{Name: "launches", Source: "producthunt"},
+			{Name: "nav", Source: "v2ex", Navigation: true},
+			{Name: "papers", Source: "arxiv"},
+			{Name: "fedi", Source: "mastodon"},
+			{Name: "sky", Source: "bluesky"},
+			{Name: "so", Source: "stackoverflow"},
+			{Name: "jf", Source: "jsonfeed"},
+			{Name: "yt", Source: "youtube"},
+			{Name: "best", Source: "v2ex", Frequency: "weekly", DeriveFrom: "best"},
+			{Name: "mixed", Source: "v2ex", Frequency: "weekly", DeriveFrom: "hn"},
+			{Name: "self", Source: "v2ex", DeriveFrom: "v"},
+		}},
+		Quaily:     QuailyConfig{APIKey: "k"},
+		Susanoo:    SusanooConfig{BaseURL: "https://susanoo", APIKey: "k"},
+		Cloudflare: CloudflareConfig{APIToken: "tok"},
+		Reporting:  ReportingConfig{DailyAt: "8am", Timezone: "Mars/Olympus"},
+	}
+	ratio := 1.5
+	c.Sources.HN.MaxFailRatio = &ratio
+	err := c.Validate(false)
+	if err == nil {
+		t.Fatal("Validate = nil")
+	}
+	for _, want := range []string{
+		"channel hn: source hackernews needs sources.hackernews.base_api",
+		"channel feeds: source rss needs sources.rss.feeds",
+		"channel lob: source lobsters needs sources.lobsters.base_url",
+		"channel launches: source producthunt needs sources.producthunt.token",
+		"channel nav: navigation needs quaily.site_base_url",
+		"channel papers: source arxiv needs nodes (categories, e.g., cs.CL)",
+		"channel best: derive_from channel best is not daily",
+		"channel mixed: derive_from channel hn reads source hackernews, not v2ex",
+		"channel self: derive_from needs frequency weekly",
+		`channel typo: unknown source "myspace"`,
+		"channel fedi: source mastodon needs sources.mastodon.instances",
+		"channel sky: source bluesky needs sources.bluesky.feeds",
+		"channel jf: source jsonfeed needs sources.jsonfeed.feeds",
+		"channel yt: source youtube needs sources.youtube.feeds",
+		"channel subs: source reddit needs nodes (subreddit names)",
+		"channel repos: source github needs nodes (languages, e.g., go)",
+		"quaily.api_key is set without quaily.base_url",
+		"cloudflare.account_id and cloudflare.api_token must be set together",
+		"channel when: preview_until must be an RFC 3339 time",
+		"channel where: preview_until needs quaily.preview_channel_slug",
+		"reporting.daily_at must be HH:MM",
+		"reporting.daily_at needs notify.webhook_urls or reporting.write_file",
+		"sources.hackernews.max_fail_ratio must be between 0 and 1, got 1.5",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+	for _, unwanted := range []string{"channel v:", "susanoo"} {
+		if strings.Contains(err.Error(), unwanted) {
+			t.Errorf("unexpected %q in:\n%v", unwanted, err)
+		}
+	}
+
+	// Fixtures stand in for both APIs.
+	c.Newsletters.Channels = c.Newsletters.Channels[:2]
+	c.Sources.V2EX.Token = ""
+	c.Quaily.BaseURL, c.Cloudflare.AccountID = "https://api.quaily.com/v1", "acct"
+	c.Reporting = ReportingConfig{DailyAt: "08:30", Timezone: "UTC", WriteFile: true}
+	ratio = 0
+	if err := c.Validate(true); err != nil {
+		t.Errorf("Validate(mock) = %v", err)
+	}
+	if err := c.Validate(false); err == nil || !strings.Contains(err.Error(), "sources.v2ex.token") {
+		t.Errorf("Validate = %v, want the missing v2ex token", err)
+	}
+}
+
+func TestQuailyProfiles(t *testing.T) {
+	q := QuailyConfig{
+		BaseURL: "https://api.quaily.com/v1", APIKey: "k",
+		Profiles: map[string]QuailyProfile{"selfhosted": {BaseURL: "https://quaily.example.com/v1", APIKey: "k2"}},
+	}
+	if p, ok := q.Profile(""); !ok || p.BaseURL != q.BaseURL {
+		t.Errorf("Profile(\"\") = %+v, %v; want the flat settings", p, ok)
+	}
+	if p, ok := q.Profile("SelfHosted"); !ok || p.APIKey != "k2" {
+		t.Errorf("Profile(SelfHosted) = %+v, %v", p, ok)
+	}
+	if _, ok := q.Profile("staging"); ok {
+		t.Error("Profile(staging) ok for an undefined profile")
+	}
+	if got := strings.Join(q.ProfileNames(), ","); got != "default,selfhosted" {
+		t.Errorf("ProfileNames = %s", got)
+	}
+
+	c := Config{
+		Quaily: q,
+		Newsletters: NewslettersConfig{Channels: []ChannelConfig{
+			{Name: "a", Source: "hackernews", QuailyProfile: "selfhosted"},
+			{Name: "b", Source: "hackernews", QuailyProfile: "staging"},
+		}},
+	}
+	c.Quaily.Profiles["default"] = QuailyProfile{BaseURL: "https://x"}
+	c.Quaily.Profiles["keyonly"] = QuailyProfile{APIKey: "k3"}
+	err := c.Validate(true)
+	for _, want := range []string{
+		`channel b: quaily_profile "staging" is not defined`,
+		"quaily.profiles.default and quaily.base_url/api_key both define the default profile",
+		"quaily.profiles.keyonly.api_key is set without base_url",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "channel a:") {
+		t.Errorf("unexpected error for channel a:\n%v", err)
+	}
+}
+
+func TestBlueskyFeeds(t *testing.T) {
+	for f, want := range map[BlueskyFeedConfig]string{
+		{Name: "Science", Query: "telescope"}:                                 "Science",
+		{Query: " golang "}:                                                   "golang",
+		{Feed: "at://did:plc:abc/app.bsky.feed.generator/whats-hot"}:          "whats-hot",
+		{Name: " ", Feed: "at://did:plc:abc/app.bsky.feed.generator/science"}: "science",
+	} {
+		if got := f.Node(); got != want {
+			t.Errorf("%+v.Node() = %q, want %q", f, got, want)
+		}
+	}
+	c := Config{Sources: DataSources{Bluesky: BlueskyConfig{Feeds: []BlueskyFeedConfig{
+		{Query: "golang"},
+		{Name: "both", Feed: "at://x/app.bsky.feed.generator/y", Query: "q"},
+		{Name: "neither"},
+	}}}}
+	err := c.Validate(true)
+	for _, want := range []string{"sources.bluesky.feeds[1]: set one of feed or query", "sources.bluesky.feeds[2]: set one of feed or query"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want %q", err, want)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "feeds[0]") {
+		t.Errorf("valid feed reported: %v", err)
+	}
+}
+
+// Sources with default endpoints and optional credentials pass when left blank and
+// fail on values their first request would reject.
+func TestSourceEndpoints(t *testing.T) {
+	channels := NewslettersConfig{Channels: []ChannelConfig{
+		{Name: "gh", Source: "github", Nodes: []string{"go"}},
+		{Name: "ax", Source: "arxiv", Nodes: []string{"cs.CL"}},
+		{Name: "so", Source: "stackoverflow"},
+	}}
+	c := Config{Newsletters: channels}
+	if err := c.Validate(false); err != nil {
+		t.Errorf("blank endpoints = %v, want the defaults accepted", err)
+	}
+	c.Sources = DataSources{
+		GitHub:        GitHubConfig{BaseURL: "api.github.com", Token: "ghp_abc def", CreatedWithin: "a week"},
+		Arxiv:         ArxivConfig{BaseURL: "ftp://export.arxiv.org"},
+		StackOverflow: StackOverflowConfig{BaseURL: "https://api.stackexchange.com", Site: "stackoverflow.com/questions", Key: "key\nmore"},
+	}
+	err := c.Validate(false)
+	for _, want := range []string{
+		`channel gh: sources.github.base_url "api.github.com" is not an http(s) URL`,
+		"channel gh: sources.github.token contains whitespace",
+		`channel gh: sources.github.created_within "a week" is not a positive duration`,
+		`channel ax: sources.arxiv.base_url "ftp://export.arxiv.org" is not an http(s) URL`,
+		`channel so: sources.stackoverflow.site "stackoverflow.com/questions" is not a site name`,
+		"channel so: sources.stackoverflow.key contains whitespace",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want %q", err, want)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "stackoverflow.base_url") {
+		t.Errorf("valid base_url reported: %v", err)
+	}
+	if err := c.Validate(true); err != nil {
+		t.Errorf("Validate(mock) = %v, want fixtures to skip endpoint checks", err)
+	}
+}
+
+func TestYouTubeFeeds(t *testing.T) {
+	c := Config{Sources: DataSources{YouTube: YouTubeConfig{Feeds: []YouTubeFeedConfig{
+		{ChannelID: "UCgo"},
+		{PlaylistID: "PLgo", Label: "Go talks"},
+		{ChannelID: "UCgo", PlaylistID: "PLgo"},
+		{Label: "neither"},
+	}}}}
+	err := c.Validate(true)
+	for _, want := range []string{"sources.youtube.feeds[2]: set one of channel_id or playlist_id", "sources.youtube.feeds[3]: set one of channel_id or playlist_id"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want %q", err, want)
+		}
+	}
+	if err != nil && (strings.Contains(err.Error(), "feeds[0]") || strings.Contains(err.Error(), "feeds[1]")) {
+		t.Errorf("valid feed reported: %v", err)
+	}
+}
+
+func TestSummaryConfig(t *testing.T) {
+	var c SummaryConfig
+	if !c.PostEnabled() || !c.ShortEnabled() {
+		t.Error("unset summaries disabled")
+	}
+	off, on := false, true
+	c = SummaryConfig{Post: &off, Short: &on}
+	if c.PostEnabled() || !c.ShortEnabled() {
+		t.Errorf("post=false short=true: post %v, short %v", c.PostEnabled(), c.ShortEnabled())
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	start, end, loc, err := QuietHoursConfig{Start: "22:30", End: " 07:00", Timezone: "Europe/Berlin"}.Window()
+	if err != nil || start != 22*60+30 || end != 7*60 || loc.String() != "Europe/Berlin" {
+		t.Fatalf("Window = %d, %d, %v, %v", start, end, loc, err)
+	}
+	if (QuietHoursConfig{Timezone: "UTC"}).Enabled() {
+		t.Error("a timezone alone enables quiet hours")
+	}
+	c := Config{
+		Sources: DataSources{Reddit: RedditConfig{QuietHours: QuietHoursConfig{Start: "23:00"}}},
+		Newsletters: NewslettersConfig{Channels: []ChannelConfig{
+			{Name: "night", Source: "v2ex", QuietHours: QuietHoursConfig{Start: "22:00", End: "7am"}},
+			{Name: "same", Source: "v2ex", QuietHours: QuietHoursConfig{Start: "08:00", End: "08:00"}},
+			{Name: "ok", Source: "v2ex", QuietHours: QuietHoursConfig{Start: "22:00", End: "06:00", Timezone: "Asia/Shanghai"}},
+		}},
+	}
+	err = c.Validate(true)
+	for _, want := range []string{
+		"sources.reddit.quiet_hours.end must be HH:MM",
+		"channel night: quiet_hours.end must be HH:MM",
+		"channel same: quiet_hours.start and quiet_hours.end must differ",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate = %v, want %q", err, want)
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "channel ok") {
+		t.Errorf("valid window reported: %v", err)
+	}
+}
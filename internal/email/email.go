@@ -0,0 +1,179 @@
+// Package email sends rendered digests over SMTP as multipart (plain text + HTML) messages.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes.
+const (
+	TLSStartTLS = "starttls" // plain connection upgraded with STARTTLS (default, port 587)
+	TLSImplicit = "tls"      // TLS from the first byte (port 465)
+	TLSNone     = "none"     // no encryption (port 25); only sensible for a local relay
+)
+
+// CheckTLSMode validates a tls option; empty means TLSStartTLS.
+func CheckTLSMode(mode string) error {
+	switch mode {
+	case "", TLSStartTLS, TLSImplicit, TLSNone:
+		return nil
+	}
+	return fmt.Errorf("unknown email tls mode %q (want %s, %s, or %s)", mode, TLSStartTLS, TLSImplicit, TLSNone)
+}
+
+// Sender delivers messages through one SMTP server.
+type Sender struct {
+	Host     string
+	Port     int // 0 picks the default port of the TLS mode
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      string // see CheckTLSMode
+	// InsecureSkipVerify disables certificate checks, e.g., for a relay with a self-signed certificate.
+	InsecureSkipVerify bool
+	Timeout            time.Duration // per send; default 30s
+}
+
+// Message is one email with a plain-text alternative to its HTML body.
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Send delivers m to every recipient in To.
+func (s *Sender) Send(ctx context.Context, m Message) error {
+	if s.Host == "" || s.From == "" || len(s.To) == 0 {
+		return errors.New("email: host, from, and to are required")
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.port())))
+	if err != nil {
+		return fmt.Errorf("email: dial: %w", err)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+	tlsCfg := &tls.Config{ServerName: s.Host, InsecureSkipVerify: s.InsecureSkipVerify}
+	if s.TLS == TLSImplicit {
+		conn = tls.Client(conn, tlsCfg)
+	}
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("email: handshake: %w", err)
+	}
+	defer c.Close()
+	if s.TLS == "" || s.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("email: server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("email: starttls: %w", err)
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("email: auth: %w", err)
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return fmt.Errorf("email: mail from: %w", err)
+	}
+	for _, to := range s.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("email: rcpt %s: %w", to, err)
+		}
+	}
+	raw, err := buildMessage(s.From, s.To, m, time.Now())
+	if err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("email: data: %w", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return fmt.Errorf("email: write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("email: data: %w", err)
+	}
+	return c.Quit()
+}
+
+func (s *Sender) port() int {
+	if s.Port > 0 {
+		return s.Port
+	}
+	switch s.TLS {
+	case TLSImplicit:
+		return 465
+	case TLSNone:
+		return 25
+	}
+	return 587
+}
+
+// buildMessage renders a multipart/alternative message; the HTML part comes last
+// so clients that can show it prefer it.
+func buildMessage(from string, to []string, m Message, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range []struct{ typ, content string }{
+		{"text/plain", m.Text},
+		{"text/html", m.HTML},
+	} {
+		if p.content == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.typ + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
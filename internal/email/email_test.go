@@ -0,0 +1,232 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer is a minimal SMTP server that records the last message it accepted.
+type smtpServer struct {
+	ln       net.Listener
+	cert     tls.Certificate
+	implicit bool // TLS from the first byte
+
+	mu       sync.Mutex
+	data     string
+	rcpts    []string
+	tlsUsed  bool
+	authUsed bool
+}
+
+func newSMTPServer(t *testing.T, implicit bool) *smtpServer {
+	t.Helper()
+	s := &smtpServer{cert: selfSignedCert(t), implicit: implicit}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if implicit {
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{s.cert}})
+	}
+	s.ln = ln
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) port() int { return s.ln.Addr().(*net.TCPAddr).Port }
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	secure := s.implicit
+	r, w := bufio.NewReader(conn), conn
+	reply := func(line string) { io.WriteString(w, line+"\r\n") }
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			if !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tc := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{s.cert}})
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tc, true
+			r, w = bufio.NewReader(tc), tc
+			s.mu.Lock()
+			s.tlsUsed = true
+			s.mu.Unlock()
+		case "AUTH":
+			s.mu.Lock()
+			s.authUsed = true
+			s.mu.Unlock()
+			reply("235 ok")
+		case "MAIL":
+			reply("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, cmd)
+			s.mu.Unlock()
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown")
+		}
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSendMultipart(t *testing.T) {
+	for _, mode := range []string{TLSStartTLS, TLSImplicit, TLSNone} {
+		t.Run(mode, func(t *testing.T) {
+			srv := newSMTPServer(t, mode == TLSImplicit)
+			s := &Sender{
+				Host:               "127.0.0.1",
+				Port:               srv.port(),
+				From:               "digest@example.com",
+				To:                 []string{"a@example.com", "b@example.com"},
+				TLS:                mode,
+				InsecureSkipVerify: true,
+			}
+			if mode != TLSNone {
+				s.Username, s.Password = "user", "secret" // PlainAuth refuses unencrypted non-localhost servers
+			}
+			msg := Message{Subject: "Daily digest — 2025-10-24", HTML: "<h1>Hi</h1><p>Long line " + strings.Repeat("x", 100) + "</p>", Text: "Hi\n\nplain body"}
+			if err := s.Send(context.Background(), msg); err != nil {
+				t.Fatal(err)
+			}
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if len(srv.rcpts) != 2 {
+				t.Errorf("rcpts = %v, want 2", srv.rcpts)
+			}
+			if srv.tlsUsed != (mode == TLSStartTLS) {
+				t.Errorf("starttls used = %v", srv.tlsUsed)
+			}
+			if srv.authUsed != (mode != TLSNone) {
+				t.Errorf("auth used = %v", srv.authUsed)
+			}
+			parts := parseMessage(t, srv.data)
+			if parts["subject"] != msg.Subject {
+				t.Errorf("subject = %q", parts["subject"])
+			}
+			if parts["text/plain"] != msg.Text || parts["text/html"] != msg.HTML {
+				t.Errorf("parts = %q", parts)
+			}
+		})
+	}
+}
+
+func TestSendStartTLSRequired(t *testing.T) {
+	srv := newSMTPServer(t, false)
+	s := &Sender{Host: "127.0.0.1", Port: srv.port(), From: "f@example.com", To: []string{"t@example.com"}}
+	// A server certificate that is not trusted must fail the STARTTLS handshake.
+	if err := s.Send(context.Background(), Message{Subject: "s", Text: "t"}); err == nil || !strings.Contains(err.Error(), "starttls") {
+		t.Errorf("Send with untrusted cert err = %v, want starttls error", err)
+	}
+}
+
+// parseMessage decodes the subject and the MIME parts of a raw message.
+func parseMessage(t *testing.T, raw string) map[string]string {
+	t.Helper()
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]string{}
+	dec := new(mime.WordDecoder)
+	if out["subject"], err = dec.DecodeHeader(m.Header.Get("Subject")); err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart() // decodes quoted-printable
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(p)
+		out[typ] = strings.ReplaceAll(string(b), "\r\n", "\n")
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+// Package fsutil holds small filesystem helpers shared by the builders and commands.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rename is swapped in tests to simulate a crash between write and rename.
+var rename = os.Rename
+
+// WriteFileAtomic writes data to a temp file in the same directory as path, fsyncs it,
+// and renames it into place, so readers never observe a truncated file. On failure
+// the previous contents of path (if any) are left untouched and the temp file is removed.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			_ = os.Remove(tmp)
+		}
+	}()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	// CreateTemp uses 0600; digests are meant to be world-readable like os.WriteFile(…, 0o644).
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return err
+	}
+	if err := rename(tmp, path); err != nil {
+		return fmt.Errorf("rename %s: %w", path, err)
+	}
+	ok = true
+	// Persist the rename itself; best-effort since not every platform supports syncing directories.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
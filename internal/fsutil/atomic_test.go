@@ -0,0 +1,73 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daily-20250102.md")
+	if err := WriteFileAtomic(path, []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "v2" {
+		t.Fatalf("content = %q, %v; want v2", b, err)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o644 {
+		t.Errorf("mode = %v, want 0644", fi.Mode().Perm())
+	}
+	assertOnlyFile(t, dir, "daily-20250102.md")
+}
+
+func TestWriteFileAtomicFailureBeforeRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daily-20250102.md")
+	if err := os.WriteFile(path, []byte("edited by hand"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	injected := errors.New("disk full")
+	rename = func(string, string) error { return injected }
+	defer func() { rename = os.Rename }()
+
+	if err := WriteFileAtomic(path, []byte("regenerated")); !errors.Is(err, injected) {
+		t.Fatalf("err = %v, want injected rename failure", err)
+	}
+	b, _ := os.ReadFile(path)
+	if string(b) != "edited by hand" {
+		t.Errorf("original file changed to %q", b)
+	}
+	assertOnlyFile(t, dir, "daily-20250102.md")
+
+	// A missing target stays missing.
+	fresh := filepath.Join(dir, "weekly-20250102.md")
+	if err := WriteFileAtomic(fresh, []byte("x")); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(fresh); !os.IsNotExist(err) {
+		t.Errorf("target should not exist after a failed write, stat err = %v", err)
+	}
+}
+
+// assertOnlyFile fails if dir holds anything besides name (e.g., leftover temp files).
+func assertOnlyFile(t *testing.T, dir, name string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != name {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("dir contains %v, want only %s", names, name)
+	}
+}
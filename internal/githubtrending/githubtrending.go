@@ -0,0 +1,161 @@
+// Package githubtrending approximates GitHub's trending repositories, which have no
+// API, with the search API: repositories created recently in a language, by stars.
+package githubtrending
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of trending repositories.
+const Source = "github"
+
+// DefaultBaseURL serves the REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// SiteURL is the site the trending pages (/trending/<language>) live on.
+const SiteURL = "https://github.com"
+
+// perPage is the number of repositories requested per language.
+const perPage = 50
+
+// RateLimitError is returned when the search API's rate limit is used up. Reset is
+// when it refills; zero when GitHub did not say.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if !e.Reset.IsZero() {
+		return fmt.Sprintf("github: rate limited until %s", e.Reset.UTC().Format(time.RFC3339))
+	}
+	return "github: rate limited"
+}
+
+// Client is a minimal client of the repository search API.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient returns a client for the API at baseURL (empty uses DefaultBaseURL).
+// token is optional; unauthenticated searches are limited to 10 a minute.
+func NewClient(baseURL, token string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: strings.TrimSpace(token), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// repo mirrors the search result fields items are built from.
+type repo struct {
+	ID          int64     `json:"id"`
+	FullName    string    `json:"full_name"`
+	HTMLURL     string    `json:"html_url"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stargazers_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	Fork        bool      `json:"fork"`
+	Archived    bool      `json:"archived"`
+	Owner       struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// Trending returns the most-starred repositories in language created after since,
+// leaving out forks and archived repositories. Items are stored under language as
+// given (lowercased), so channel nodes match however GitHub spells it.
+func (c *Client) Trending(ctx context.Context, language string, since time.Time) ([]model.NewsItem, error) {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	q := "created:>" + since.UTC().Format("2006-01-02")
+	if lang != "" {
+		q += " language:" + lang
+	}
+	endpoint := fmt.Sprintf("%s/search/repositories?q=%s&sort=stars&order=desc&per_page=%d", c.baseURL, url.QueryEscape(q), perPage)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0") {
+		return nil, &RateLimitError{Reset: rateLimitReset(resp.Header)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("github search %q: status %d", q, resp.StatusCode)
+	}
+	var body struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("github search %q: decode: %w", q, err)
+	}
+	out := make([]model.NewsItem, 0, len(body.Items))
+	for _, raw := range body.Items {
+		var r repo
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil, fmt.Errorf("github search %q: decode repository: %w", q, err)
+		}
+		if r.ID == 0 || r.Fork || r.Archived {
+			continue
+		}
+		it := r.item(lang)
+		it.Raw = raw
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// item maps a repository to a NewsItem: stars are its points and the description
+// its content.
+func (r repo) item(node string) model.NewsItem {
+	if node == "" {
+		node = strings.ToLower(r.Language)
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        strconv.FormatInt(r.ID, 10),
+		Title:     r.FullName,
+		URL:       r.HTMLURL,
+		NodeName:  node,
+		Points:    r.Stars,
+		CreatedAt: r.CreatedAt.UTC(),
+		Content:   strings.TrimSpace(r.Description),
+		Author:    r.Owner.Login,
+	}
+}
+
+// rateLimitReset reads X-RateLimit-Reset (Unix seconds); zero when absent.
+func rateLimitReset(h http.Header) time.Time {
+	if s, err := strconv.ParseInt(strings.TrimSpace(h.Get("X-RateLimit-Reset")), 10, 64); err == nil && s > 0 {
+		return time.Unix(s, 0).UTC()
+	}
+	return time.Time{}
+}
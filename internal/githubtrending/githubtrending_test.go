@@ -0,0 +1,87 @@
+package githubtrending
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestTrending(t *testing.T) {
+	body, err := os.ReadFile("testdata/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotQuery, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/repositories" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery, gotAuth = r.URL.Query().Get("q"), r.Header.Get("Authorization")
+		if r.URL.Query().Get("sort") != "stars" {
+			t.Errorf("sort = %q", r.URL.Query().Get("sort"))
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	since := time.Date(2025, 10, 17, 23, 0, 0, 0, time.UTC)
+	items, err := NewClient(srv.URL, "tok").Trending(context.Background(), "Go", since)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Trending = %d items, %v; want 2 (the fork left out)", len(items), err)
+	}
+	if gotQuery != "created:>2025-10-17 language:go" || gotAuth != "Bearer tok" {
+		t.Errorf("q = %q, Authorization = %q", gotQuery, gotAuth)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "1001",
+		Title:     "gopher/fastcache",
+		URL:       "https://github.com/gopher/fastcache",
+		NodeName:  "go",
+		Points:    842,
+		CreatedAt: time.Date(2025, 10, 20, 9, 30, 0, 0, time.UTC),
+		Content:   "A sharded in-memory cache for Go.",
+		Author:    "gopher",
+	}, {
+		Source:    Source,
+		ID:        "1003",
+		Title:     "acme/tracer",
+		URL:       "https://github.com/acme/tracer",
+		NodeName:  "go",
+		Points:    120,
+		CreatedAt: time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+		Author:    "acme",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+}
+
+func TestTrendingRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Authorization sent without a token")
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1761300000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "").Trending(context.Background(), "rust", time.Now())
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || !rl.Reset.Equal(time.Unix(1761300000, 0)) {
+		t.Fatalf("Trending = %v, want a RateLimitError with the reset time", err)
+	}
+}
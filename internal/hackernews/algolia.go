@@ -0,0 +1,103 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// DefaultAlgoliaAPI is the public HN Search (Algolia) endpoint.
+// Docs: https://hn.algolia.com/api
+const DefaultAlgoliaAPI = "https://hn.algolia.com/api/v1"
+
+// WithAlgoliaAPI optionally overrides the HN Search API base used for historical queries.
+func (c *Client) WithAlgoliaAPI(base string) *Client {
+	c2 := *c
+	if strings.TrimSpace(base) != "" {
+		c2.algoliaAPI = strings.TrimRight(base, "/")
+	}
+	return &c2
+}
+
+// algoliaHit mirrors the subset of HN Search hit fields we care about.
+type algoliaHit struct {
+	ObjectID    string   `json:"objectID"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Author      string   `json:"author"`
+	Points      int      `json:"points"`
+	NumComments int      `json:"num_comments"`
+	StoryText   string   `json:"story_text"`
+	CreatedAtI  int64    `json:"created_at_i"`
+	Tags        []string `json:"_tags"`
+}
+
+type algoliaResponse struct {
+	Hits []algoliaHit `json:"hits"`
+}
+
+// FrontPageBetween returns stories that reached the HN front page and were
+// created within [from, to), using the Algolia HN Search API (up to limit).
+func (c *Client) FrontPageBetween(ctx context.Context, from, to time.Time, limit int) ([]model.NewsItem, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	q := url.Values{
+		"tags":           {"front_page"},
+		"numericFilters": {fmt.Sprintf("created_at_i>=%d,created_at_i<%d", from.Unix(), to.Unix())},
+		"hitsPerPage":    {strconv.Itoa(limit)},
+	}
+	endpoint := c.algoliaAPI + "/search?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hackernews: algolia search status %d", resp.StatusCode)
+	}
+	var out algoliaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	items := make([]model.NewsItem, 0, len(out.Hits))
+	for _, h := range out.Hits {
+		id, err := strconv.Atoi(h.ObjectID)
+		if err != nil {
+			continue
+		}
+		items = append(items, convertItem(hnItem{
+			ID:          id,
+			Type:        algoliaType(h.Tags),
+			By:          h.Author,
+			Title:       h.Title,
+			URL:         h.URL,
+			Text:        h.StoryText,
+			Time:        h.CreatedAtI,
+			Descendants: h.NumComments,
+			Score:       h.Points,
+		}))
+	}
+	return items, nil
+}
+
+// algoliaType maps HN Search tags to the HN item type used by convertItem.
+func algoliaType(tags []string) string {
+	for _, t := range tags {
+		if t == "job" {
+			return "job"
+		}
+	}
+	return "story"
+}
@@ -4,23 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/textclean"
+	"quaily-journalist/internal/urlutil"
+
+	"golang.org/x/sync/errgroup"
 )
 
 // Client is a minimal Hacker News API client.
 // Docs: https://github.com/HackerNews/API
 type Client struct {
-	baseAPI string
-	client  *http.Client
+	baseAPI    string
+	algoliaAPI string
+	client     *http.Client
+	// maxFailRatio is the fraction of item fetches allowed to fail before a batch reports an error.
+	maxFailRatio float64
 }
 
+// DefaultMaxFailRatio is the failure fraction above which batch item fetches return an error.
+const DefaultMaxFailRatio = 0.5
+
 // NewClient creates a new Hacker News client. baseAPI should be something like
 // "https://hacker-news.firebaseio.com/v0". If empty, it defaults to the v0 endpoint.
 func NewClient(baseAPI string) *Client {
@@ -28,9 +41,30 @@ func NewClient(baseAPI string) *Client {
 		baseAPI = "https://hacker-news.firebaseio.com/v0"
 	}
 	return &Client{
-		baseAPI: strings.TrimRight(baseAPI, "/"),
-		client:  &http.Client{Timeout: 10 * time.Second},
+		baseAPI:      strings.TrimRight(baseAPI, "/"),
+		algoliaAPI:   DefaultAlgoliaAPI,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		maxFailRatio: DefaultMaxFailRatio,
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// WithMaxFailRatio returns a copy of the client that tolerates up to ratio (0..1) failed
+// item fetches per batch before returning an error. 0 makes any failure an error.
+func (c *Client) WithMaxFailRatio(ratio float64) *Client {
+	c2 := *c
+	if ratio >= 0 && ratio <= 1 {
+		c2.maxFailRatio = ratio
 	}
+	return &c2
 }
 
 // hnItem mirrors the subset of HN item fields we care about.
@@ -46,6 +80,8 @@ type hnItem struct {
 	Descendants int    `json:"descendants"`
 	Score       int    `json:"score"`
 	Parts       []int  `json:"parts"` // polls
+	Deleted     bool   `json:"deleted"`
+	Dead        bool   `json:"dead"`
 }
 
 // TopStories returns top stories as NewsItems (up to limit).
@@ -80,132 +116,232 @@ func (c *Client) JobStories(ctx context.Context, limit int) ([]model.NewsItem, e
 
 // Item fetches a single HN item by ID and converts it into NewsItem.
 func (c *Client) Item(ctx context.Context, id int) (model.NewsItem, error) {
-	var zero model.NewsItem
+	it, payload, err := c.fetchItem(ctx, id)
+	if err != nil {
+		return model.NewsItem{}, err
+	}
+	out := convertItem(it)
+	out.Raw = payload
+	return out, nil
+}
+
+func (c *Client) rawItem(ctx context.Context, id int) (hnItem, error) {
+	it, _, err := c.fetchItem(ctx, id)
+	return it, err
+}
+
+// fetchItem returns an item and its JSON as the API returned it.
+func (c *Client) fetchItem(ctx context.Context, id int) (hnItem, []byte, error) {
+	var it hnItem
 	endpoint := fmt.Sprintf("%s/item/%d.json", c.baseAPI, id)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return zero, err
+		return it, nil, err
 	}
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return zero, err
+		return it, nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return zero, fmt.Errorf("hackernews: item %d status %d", id, resp.StatusCode)
+		return it, nil, fmt.Errorf("hackernews: item %d status %d", id, resp.StatusCode)
 	}
-	var it hnItem
-	if err := json.NewDecoder(resp.Body).Decode(&it); err != nil {
-		return zero, err
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return it, nil, err
+	}
+	if err := json.Unmarshal(payload, &it); err != nil {
+		return it, nil, err
 	}
-	return convertItem(it), nil
+	return it, payload, nil
+}
+
+// Comment is a top-level comment on a story.
+type Comment struct {
+	ID     int    `json:"id"`
+	Author string `json:"author"`
+	Text   string `json:"text"` // plain text, see textclean.StripHTML
+	URL    string `json:"url"`  // permalink
+}
+
+// topCommentProbe bounds how many top-level comments TopComment inspects.
+const topCommentProbe = 3
+
+// TopComment returns the highest-ranked live top-level comment of a story; ok is
+// false when it has none. The API exposes no comment scores, but a story's kids
+// come in the ranked order of the discussion page, so the first comment that is
+// neither deleted nor dead is the top one.
+func (c *Client) TopComment(ctx context.Context, storyID int) (cm Comment, ok bool, err error) {
+	story, err := c.rawItem(ctx, storyID)
+	if err != nil {
+		return Comment{}, false, err
+	}
+	for i, kid := range story.Kids {
+		if i == topCommentProbe {
+			break
+		}
+		it, err := c.rawItem(ctx, kid)
+		if err != nil {
+			return Comment{}, false, err
+		}
+		text := textclean.StripHTML(it.Text)
+		if it.Deleted || it.Dead || it.Type != "comment" || text == "" {
+			continue
+		}
+		return Comment{
+			ID:     it.ID,
+			Author: it.By,
+			Text:   text,
+			URL:    fmt.Sprintf("https://news.ycombinator.com/item?id=%d", it.ID),
+		}, true, nil
+	}
+	return Comment{}, false, nil
 }
 
 // storiesByList fetches IDs from a stories list and resolves them to NewsItems.
 func (c *Client) storiesByList(ctx context.Context, list string, limit int) ([]model.NewsItem, error) {
-	ids, err := c.fetchIDs(ctx, list)
+	res, err := c.ListIDs(ctx, list, "")
 	if err != nil {
 		return nil, err
 	}
+	ids := res.IDs
 	if limit > 0 && len(ids) > limit {
 		ids = ids[:limit]
 	}
-	slog.Info("hackernews: fetching items", "list", list, "count", len(ids))
+	slog.Debug("hackernews: fetching items", "list", list, "count", len(ids))
 	return c.itemsByIDs(ctx, ids)
 }
 
-// fetchIDs loads a list endpoint such as topstories/newstories/etc.
-func (c *Client) fetchIDs(ctx context.Context, list string) ([]int, error) {
+// ListResult is the outcome of a (conditional) list fetch.
+type ListResult struct {
+	IDs         []int  // nil when NotModified
+	ETag        string // ETag to send on the next request, if the API provided one
+	NotModified bool   // true when the server answered 304 to If-None-Match
+}
+
+// ListIDs loads a list endpoint such as topstories/newstories/etc.
+// When etag is non-empty it is sent as If-None-Match; a 304 reply yields NotModified.
+func (c *Client) ListIDs(ctx context.Context, list, etag string) (ListResult, error) {
 	path := fmt.Sprintf("%s/%s.json", c.baseAPI, url.PathEscape(list))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
 	if err != nil {
-		return nil, err
+		return ListResult{}, err
+	}
+	// Firebase only returns ETags when asked to.
+	req.Header.Set("X-Firebase-ETag", "true")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
 	}
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return ListResult{}, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return ListResult{ETag: etag, NotModified: true}, nil
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("hackernews: %s status %d", list, resp.StatusCode)
+		return ListResult{}, fmt.Errorf("hackernews: %s status %d", list, resp.StatusCode)
 	}
 	var ids []int
 	if err := json.NewDecoder(resp.Body).Decode(&ids); err != nil {
-		return nil, err
+		return ListResult{}, err
 	}
-	return ids, nil
+	return ListResult{IDs: ids, ETag: resp.Header.Get("ETag")}, nil
 }
 
-// itemsByIDs resolves multiple IDs concurrently into NewsItems.
+// Items resolves multiple item IDs into NewsItems, preserving order. On partial failure
+// it may return both the resolved items and an error; see itemsByIDs.
+func (c *Client) Items(ctx context.Context, ids []int) ([]model.NewsItem, error) {
+	return c.itemsByIDs(ctx, ids)
+}
+
+// itemsByIDs resolves multiple IDs concurrently into NewsItems, preserving order.
+// Individual failures are tolerated: the successfully resolved items are returned
+// alongside an error only when more than maxFailRatio of the IDs failed. If ctx is
+// cancelled, in-flight requests are aborted and the partial results are returned with ctx.Err().
 func (c *Client) itemsByIDs(ctx context.Context, ids []int) ([]model.NewsItem, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	// bounded concurrency
 	const maxWorkers = 8
-	type result struct {
-		idx  int
-		item model.NewsItem
-		err  error
-	}
-	out := make([]result, len(ids))
-	sem := make(chan struct{}, maxWorkers)
-	done := make(chan result, len(ids))
+	resolved := make([]model.NewsItem, len(ids))
+	var (
+		mu       sync.Mutex
+		failed   int
+		firstErr error
+	)
+	// Workers never return errors, so the group is only cancelled by the parent ctx.
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxWorkers)
 	for i, id := range ids {
+		if gctx.Err() != nil {
+			break
+		}
 		i, id := i, id
-		sem <- struct{}{}
-		go func() {
-			defer func() { <-sem }()
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return nil
+			}
 			// Per-item timeout to avoid hanging
-			ictx, cancel := context.WithTimeout(ctx, 8*time.Second)
+			ictx, cancel := context.WithTimeout(gctx, 8*time.Second)
 			defer cancel()
 			it, err := c.Item(ictx, id)
-			done <- result{idx: i, item: it, err: err}
-		}()
-	}
-	// wait for all
-	for i := 0; i < len(ids); i++ {
-		r := <-done
-		if r.err != nil {
-			// skip failed ones silently; continue
-			continue
-		}
-		out[r.idx] = r
+			if err != nil {
+				mu.Lock()
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return nil
+			}
+			resolved[i] = it
+			return nil
+		})
 	}
+	_ = g.Wait()
+
 	// collect non-zero entries preserving order
 	items := make([]model.NewsItem, 0, len(ids))
-	for _, r := range out {
-		if r.item.ID != "" {
-			items = append(items, r.item)
+	for _, it := range resolved {
+		if it.ID != "" {
+			items = append(items, it)
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return items, err
+	}
+	if failed > 0 && float64(failed) > c.maxFailRatio*float64(len(ids)) {
+		return items, fmt.Errorf("hackernews: %d of %d items failed: %w", failed, len(ids), firstErr)
+	}
+	if failed > 0 {
+		slog.Warn("hackernews: some items failed", "failed", failed, "total", len(ids), "err", firstErr)
+	}
 	return items, nil
 }
 
 // convertItem maps an hnItem to our NewsItem model.
 func convertItem(h hnItem) model.NewsItem {
 	idStr := fmt.Sprintf("%d", h.ID)
-	urlStr := strings.TrimSpace(h.URL)
-	if urlStr == "" {
-		urlStr = "https://news.ycombinator.com/item?id=" + idStr
+	// Self posts have no URL and link to their discussion page, which is also the
+	// fallback for URLs that cannot be repaired.
+	itemPage := "https://news.ycombinator.com/item?id=" + idStr
+	urlStr, changed := urlutil.Normalize(h.URL, "https://news.ycombinator.com", itemPage)
+	if changed && strings.TrimSpace(h.URL) != "" {
+		slog.Debug("hackernews: repaired item url", "id", h.ID, "url", h.URL, "repaired", urlStr)
 	}
-	content := stripHTML(h.Text)
-	// Derive a pseudo-node for filtering: ask/show/job/story
+	content := textclean.StripHTML(h.Text)
+	// Derive a pseudo-node for filtering: ask/show/tell/launch/job/story
 	typ := strings.ToLower(strings.TrimSpace(h.Type))
 	cat := typ
 	if typ == "story" {
-		t := strings.ToLower(strings.TrimSpace(h.Title))
-		if strings.HasPrefix(t, "ask hn:") {
-			cat = "ask"
-		} else if strings.HasPrefix(t, "show hn:") {
-			cat = "show"
-		} else {
-			cat = "story"
-		}
+		cat = storyCategory(h.Title)
 	} else if typ == "job" {
 		cat = "job"
 	}
 	return model.NewsItem{
+		Source:    "hackernews",
 		ID:        idStr,
 		Title:     h.Title,
 		URL:       urlStr,
@@ -214,28 +350,39 @@ func convertItem(h hnItem) model.NewsItem {
 		Points:    h.Score,
 		CreatedAt: time.Unix(h.Time, 0),
 		Content:   content,
+		Author:    h.By,
 	}
 }
 
-var htmlTagRe = regexp.MustCompile(`<[^>]+>`) // best-effort removal
+// storyPrefixes maps title prefixes (lowercase, without the colon) to story pseudo-nodes.
+var storyPrefixes = []struct {
+	prefix string
+	cat    string
+}{
+	{"ask hn", "ask"},
+	{"show hn", "show"},
+	{"tell hn", "tell"},
+	{"launch hn", "launch"},
+}
 
-func stripHTML(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return s
+// storyCategory classifies a story by its title prefix ("Ask HN:", "Tell HN -", "Launch HN ...").
+// Matching is case-insensitive and the colon is optional, but the prefix must end at a word boundary.
+func storyCategory(title string) string {
+	t := strings.ToLower(strings.TrimSpace(title))
+	for _, p := range storyPrefixes {
+		if !strings.HasPrefix(t, p.prefix) {
+			continue
+		}
+		rest := t[len(p.prefix):]
+		if rest == "" {
+			return p.cat
+		}
+		r, _ := utf8.DecodeRuneInString(rest)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return p.cat
+		}
 	}
-	// Remove common HTML tags to feed cleaner text to summarizers.
-	// This is a minimal approach; HN "text" is simple HTML.
-	s = htmlTagRe.ReplaceAllString(s, "")
-	// Unescape a few common entities by hand to avoid extra deps.
-	replacer := strings.NewReplacer(
-		"&quot;", "\"",
-		"&apos;", "'",
-		"&amp;", "&",
-		"&lt;", "<",
-		"&gt;", ">",
-	)
-	return strings.TrimSpace(replacer.Replace(s))
+	return "story"
 }
 
 func maxInt(a, b int) int {
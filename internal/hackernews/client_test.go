@@ -0,0 +1,217 @@
+package hackernews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStoryCategory(t *testing.T) {
+	cases := []struct {
+		title string
+		want  string
+	}{
+		{"Ask HN: Who is hiring? (October 2025)", "ask"},
+		{"ask hn: how do you back up your homelab?", "ask"},
+		{"Show HN: Foo – a tiny bar for baz", "show"},
+		{"Show HN – I built a thing", "show"},
+		{"Tell HN: I'm leaving my job to work on open source", "tell"},
+		{"TELL HN: Thanks, HN", "tell"},
+		{"Tell HN – Google Search is down", "tell"},
+		{"Tell HN", "tell"},
+		{"Launch HN: Pig (YC W25) – Automate Windows apps", "launch"},
+		{"Launch HN Acme (YC S24) - Postgres for robots", "launch"},
+		{"launch hn: lowercase launch", "launch"},
+		{"  Launch HN:  leading spaces", "launch"},
+		{"Telling HN stories", "story"},
+		{"Show HNs that never launched", "story"},
+		{"Launching HN clones is easy", "story"},
+		{"The Tell HN phenomenon", "story"},
+		{"Linux 6.18 released", "story"},
+		{"", "story"},
+	}
+	for _, tc := range cases {
+		if got := storyCategory(tc.title); got != tc.want {
+			t.Errorf("storyCategory(%q) = %q, want %q", tc.title, got, tc.want)
+		}
+	}
+}
+
+func TestConvertItemCategory(t *testing.T) {
+	cases := []struct {
+		typ, title, want string
+	}{
+		{"story", "Tell HN: We shipped", "tell"},
+		{"story", "Launch HN: Acme (YC F25)", "launch"},
+		{"job", "Acme (YC S24) is hiring", "job"},
+		{"poll", "Poll: tabs or spaces?", "poll"},
+	}
+	for _, tc := range cases {
+		it := convertItem(hnItem{ID: 1, Type: tc.typ, Title: tc.title})
+		if it.NodeName != tc.want {
+			t.Errorf("convertItem(%q, %q).NodeName = %q, want %q", tc.typ, tc.title, it.NodeName, tc.want)
+		}
+		if it.Source != "hackernews" {
+			t.Errorf("convertItem(%q, %q).Source = %q, want hackernews", tc.typ, tc.title, it.Source)
+		}
+	}
+}
+
+func TestConvertItemURL(t *testing.T) {
+	cases := []struct {
+		url, want string
+	}{
+		{"", "https://news.ycombinator.com/item?id=7"},
+		{"www.example.com/post", "https://www.example.com/post"},
+		{"/item?id=8", "https://news.ycombinator.com/item?id=8"},
+		{"https://example.com/a b", "https://example.com/a%20b"},
+		{"javascript:void(0)", "https://news.ycombinator.com/item?id=7"},
+	}
+	for _, tc := range cases {
+		if got := convertItem(hnItem{ID: 7, Type: "story", URL: tc.url}).URL; got != tc.want {
+			t.Errorf("convertItem(url %q).URL = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+// itemServer serves /item/<id>.json; fail decides which IDs return 500.
+func itemServer(t *testing.T, fail func(id int) bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/item/"), "%d.json", &id)
+		if fail(id) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%d,"type":"story","title":"Story %d","score":10,"time":1700000000}`, id, id)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func seqIDs(n int) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	return ids
+}
+
+func TestItemsByIDsPartialFailure(t *testing.T) {
+	srv := itemServer(t, func(id int) bool { return id%4 == 0 })
+	c := NewClient(srv.URL)
+
+	items, err := c.Items(context.Background(), seqIDs(20))
+	if err != nil {
+		t.Fatalf("25%% failures should be tolerated, got %v", err)
+	}
+	if len(items) != 15 {
+		t.Fatalf("got %d items, want 15", len(items))
+	}
+	want := []string{"1", "2", "3", "5", "6", "7", "9", "10", "11", "13", "14", "15", "17", "18", "19"}
+	for i, it := range items {
+		if it.ID != want[i] {
+			t.Fatalf("items[%d].ID = %s, want %s (order must be preserved)", i, it.ID, want[i])
+		}
+	}
+
+	// A stricter ratio turns the same batch into an error, still with partial results.
+	items, err = c.WithMaxFailRatio(0.1).Items(context.Background(), seqIDs(20))
+	if err == nil || len(items) != 15 {
+		t.Fatalf("ratio 0.1: items=%d err=%v, want 15 items and an error", len(items), err)
+	}
+}
+
+func TestItemsByIDsTotalFailure(t *testing.T) {
+	srv := itemServer(t, func(int) bool { return true })
+	items, err := NewClient(srv.URL).Items(context.Background(), seqIDs(10))
+	if err == nil {
+		t.Fatal("expected an error when every item fails")
+	}
+	if !strings.Contains(err.Error(), "10 of 10 items failed") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("got %d items, want none", len(items))
+	}
+}
+
+func TestItemsByIDsCancellation(t *testing.T) {
+	var started atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/item/"), "%d.json", &id)
+		if id <= 3 {
+			fmt.Fprintf(w, `{"id":%d,"type":"story","title":"Fast","score":1,"time":1700000000}`, id)
+			return
+		}
+		started.Add(1)
+		// Slow items hang until the client goes away.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(30 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for started.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	items, err := NewClient(srv.URL).Items(ctx, seqIDs(40))
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("cancellation took %v; in-flight fetches should abort promptly", elapsed)
+	}
+	if len(items) != 3 {
+		t.Errorf("got %d partial items, want the 3 fast ones", len(items))
+	}
+	if n := started.Load(); n > 8 {
+		t.Errorf("%d slow fetches started, want at most the worker limit (8)", n)
+	}
+}
+
+func TestTopCommentSkipsDeletedAndDead(t *testing.T) {
+	items := map[int]string{
+		1:  `{"id":1,"type":"story","kids":[2,3,4,5]}`,
+		2:  `{"id":2,"type":"comment","deleted":true}`,
+		3:  `{"id":3,"type":"comment","by":"troll","text":"flagged","dead":true}`,
+		4:  `{"id":4,"type":"comment","by":"pg","text":"<p>First &amp; best</p>"}`,
+		5:  `{"id":5,"type":"comment","by":"dang","text":"second"}`,
+		10: `{"id":10,"type":"story"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/item/"), "%d.json", &id)
+		fmt.Fprint(w, items[id])
+	}))
+	t.Cleanup(srv.Close)
+	c := NewClient(srv.URL)
+
+	cm, ok, err := c.TopComment(context.Background(), 1)
+	if err != nil || !ok {
+		t.Fatalf("TopComment: ok=%v err=%v", ok, err)
+	}
+	if cm.ID != 4 || cm.Author != "pg" || cm.Text != "First & best" || cm.URL != "https://news.ycombinator.com/item?id=4" {
+		t.Errorf("comment = %+v", cm)
+	}
+	if _, ok, err := c.TopComment(context.Background(), 10); ok || err != nil {
+		t.Errorf("story without comments: ok=%v err=%v", ok, err)
+	}
+}
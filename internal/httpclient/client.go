@@ -0,0 +1,210 @@
+// Package httpclient builds the outbound HTTP clients used by source, publishing,
+// and scraping clients from the `http` config block.
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/config"
+)
+
+// Service names used as keys under http.services.
+const (
+	V2EX          = "v2ex"
+	HackerNews    = "hackernews"
+	RSS           = "rss"
+	Lobsters      = "lobsters"
+	Reddit        = "reddit"
+	GitHub        = "github"
+	ProductHunt   = "producthunt"
+	Arxiv         = "arxiv"
+	Mastodon      = "mastodon"
+	Bluesky       = "bluesky"
+	StackOverflow = "stackoverflow"
+	JSONFeed      = "jsonfeed"
+	YouTube       = "youtube"
+	Quaily        = "quaily"
+	Cloudflare    = "cloudflare"
+	Susanoo       = "susanoo"
+	Notify        = "notify"
+	Telegram      = "telegram"
+)
+
+const defaultMaxIdleConns = 100
+
+// Resolve merges the per-service block over http.default; unset fields fall through.
+func Resolve(cfg config.HTTPConfig, service string) config.HTTPClientConfig {
+	out := cfg.Default
+	svc, ok := cfg.Services[service]
+	if !ok {
+		return out
+	}
+	if strings.TrimSpace(svc.Timeout) != "" {
+		out.Timeout = svc.Timeout
+	}
+	if strings.TrimSpace(svc.Proxy) != "" {
+		out.Proxy = svc.Proxy
+	}
+	if svc.MaxIdleConns > 0 {
+		out.MaxIdleConns = svc.MaxIdleConns
+	}
+	if svc.InsecureSkipVerify {
+		out.InsecureSkipVerify = true
+	}
+	if svc.MaxRetries != 0 {
+		out.MaxRetries = svc.MaxRetries
+	}
+	if svc.MaxPerHost > 0 {
+		out.MaxPerHost = svc.MaxPerHost
+	}
+	if svc.RateLimit > 0 {
+		out.RateLimit = svc.RateLimit
+	}
+	if svc.MaxPerHour != 0 {
+		out.MaxPerHour = svc.MaxPerHour
+	}
+	if svc.BreakerThreshold != 0 {
+		out.BreakerThreshold = svc.BreakerThreshold
+	}
+	if strings.TrimSpace(svc.BreakerCooldown) != "" {
+		out.BreakerCooldown = svc.BreakerCooldown
+	}
+	if len(svc.Headers) > 0 {
+		merged := make(map[string]string, len(out.Headers)+len(svc.Headers))
+		for k, v := range out.Headers {
+			merged[k] = v
+		}
+		for k, v := range svc.Headers {
+			merged[k] = v
+		}
+		out.Headers = merged
+	}
+	return out
+}
+
+// New returns an *http.Client for service. defaultTimeout applies when neither the
+// service block nor http.default sets a timeout. Requests carry app.user_agent and
+// the configured extra headers, and GET/idempotent requests are retried per Policy.
+// Clients of the same service share one Guard for the life of the process.
+func New(cfg config.Config, service string, defaultTimeout time.Duration) (*http.Client, error) {
+	c := Resolve(cfg.HTTP, service)
+	timeout := defaultTimeout
+	if strings.TrimSpace(c.Timeout) != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("http %s: invalid timeout %q: %w", service, c.Timeout, err)
+		}
+		timeout = d
+	}
+	tr, err := newTransport(c)
+	if err != nil {
+		return nil, fmt.Errorf("http %s: %w", service, err)
+	}
+	p := policyFor(c)
+	if p.Guard, err = guardFor(service, c); err != nil {
+		return nil, fmt.Errorf("http %s: %w", service, err)
+	}
+	rt := Wrap(tr, p)
+	return &http.Client{Timeout: timeout, Transport: withHeaders(rt, requestHeaders(cfg.App.UserAgent, c.Headers))}, nil
+}
+
+// policyFor maps a resolved config block to middleware settings.
+func policyFor(c config.HTTPClientConfig) Policy {
+	retries := c.MaxRetries
+	if retries == 0 {
+		retries = defaultMaxRetries
+	}
+	return Policy{
+		MaxRetries:    retries,
+		MaxPerHost:    c.MaxPerHost,
+		RatePerSecond: c.RateLimit,
+	}
+}
+
+func newTransport(c config.HTTPClientConfig) (*http.Transport, error) {
+	proxy, err := proxyFunc(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	maxIdle := c.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	// Mirrors http.DefaultTransport apart from the configurable fields.
+	tr := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdle,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if c.InsecureSkipVerify {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // explicit opt-in for self-hosted endpoints
+	}
+	return tr, nil
+}
+
+// proxyFunc maps the proxy setting to a Transport.Proxy func:
+// empty uses the environment, "direct"/"none" disables proxying, anything else is a proxy URL.
+func proxyFunc(raw string) (func(*http.Request) (*url.URL, error), error) {
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "direct", "none":
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q", raw)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// requestHeaders builds the header set for a service: User-Agent first, then extra headers.
+func requestHeaders(userAgent string, extra map[string]string) http.Header {
+	h := http.Header{}
+	if strings.TrimSpace(userAgent) != "" {
+		h.Set("User-Agent", userAgent)
+	}
+	for k, v := range extra {
+		h.Set(k, v)
+	}
+	return h
+}
+
+// headerTransport adds default headers to requests that do not already carry them.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func withHeaders(base http.RoundTripper, headers http.Header) http.RoundTripper {
+	if len(headers) == 0 {
+		return base
+	}
+	return &headerTransport{base: base, headers: headers}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request.
+	r := req.Clone(req.Context())
+	for k, vs := range t.headers {
+		if r.Header.Get(k) == "" {
+			r.Header[k] = vs
+		}
+	}
+	return t.base.RoundTrip(r)
+}
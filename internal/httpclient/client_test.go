@@ -0,0 +1,184 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/config"
+)
+
+func TestNewAppliesServiceOverrides(t *testing.T) {
+	cfg := config.HTTPConfig{
+		Default: config.HTTPClientConfig{Timeout: "15s", MaxIdleConns: 10},
+		Services: map[string]config.HTTPClientConfig{
+			V2EX:       {Proxy: "http://proxy.internal:3128"},
+			HackerNews: {Proxy: "direct", Timeout: "5s"},
+			Quaily:     {InsecureSkipVerify: true},
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://www.v2ex.com/api/v2/nodes/go", nil)
+
+	v2, err := New(config.Config{HTTP: cfg}, V2EX, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Timeout != 15*time.Second {
+		t.Errorf("v2ex timeout = %v, want default block 15s", v2.Timeout)
+	}
+	tr := baseTransport(v2)
+	if u, _ := tr.Proxy(req); u == nil || u.Host != "proxy.internal:3128" {
+		t.Errorf("v2ex proxy = %v, want proxy.internal:3128", u)
+	}
+	if tr.MaxIdleConns != 10 {
+		t.Errorf("v2ex max idle conns = %d, want 10", tr.MaxIdleConns)
+	}
+
+	hn, err := New(config.Config{HTTP: cfg}, HackerNews, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hn.Timeout != 5*time.Second {
+		t.Errorf("hackernews timeout = %v, want 5s", hn.Timeout)
+	}
+	if baseTransport(hn).Proxy != nil {
+		t.Errorf("hackernews should bypass proxies")
+	}
+
+	q, err := New(config.Config{HTTP: cfg}, Quaily, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tls := baseTransport(q).TLSClientConfig; tls == nil || !tls.InsecureSkipVerify {
+		t.Errorf("quaily should skip TLS verification")
+	}
+
+	// Nothing configured: the caller's default timeout applies.
+	sc, err := New(config.Config{}, Cloudflare, 20*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Timeout != 20*time.Second {
+		t.Errorf("cloudflare timeout = %v, want 20s", sc.Timeout)
+	}
+}
+
+// baseTransport unwraps middleware down to the *http.Transport.
+func baseTransport(c *http.Client) *http.Transport {
+	rt := c.Transport
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t
+		case *headerTransport:
+			rt = t.base
+		case *retryTransport:
+			rt = t.base
+		case *hostTransport:
+			rt = t.base
+		case *rateTransport:
+			rt = t.base
+		case *guardTransport:
+			rt = t.base
+		default:
+			return nil
+		}
+	}
+}
+
+func TestNewRejectsInvalidSettings(t *testing.T) {
+	bad := []config.HTTPConfig{
+		{Default: config.HTTPClientConfig{Timeout: "soon"}},
+		{Services: map[string]config.HTTPClientConfig{V2EX: {Proxy: "not a url"}}},
+	}
+	for i, cfg := range bad {
+		if _, err := New(config.Config{HTTP: cfg}, V2EX, time.Second); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+// recordingTransport captures requests instead of sending them.
+type recordingTransport struct {
+	reqs []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.reqs = append(t.reqs, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestHeadersAppliedToRequests(t *testing.T) {
+	cfg := config.Config{
+		App: config.AppConfig{UserAgent: "quaily-journalist/test"},
+		HTTP: config.HTTPConfig{
+			Default: config.HTTPClientConfig{Headers: map[string]string{"x-crawler-contact": "ops@example.com"}},
+			Services: map[string]config.HTTPClientConfig{
+				V2EX: {Headers: map[string]string{"user-agent": "Mozilla/5.0 (compatible; quaily-journalist)"}},
+			},
+		},
+	}
+	cases := []struct {
+		service string
+		wantUA  string
+	}{
+		{HackerNews, "quaily-journalist/test"},
+		{V2EX, "Mozilla/5.0 (compatible; quaily-journalist)"},
+	}
+	for _, tc := range cases {
+		rec := &recordingTransport{}
+		c := Resolve(cfg.HTTP, tc.service)
+		hc := &http.Client{Transport: withHeaders(rec, requestHeaders(cfg.App.UserAgent, c.Headers))}
+
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := hc.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if len(rec.reqs) != 1 {
+			t.Fatalf("%s: recorded %d requests, want 1", tc.service, len(rec.reqs))
+		}
+		got := rec.reqs[0].Header
+		if ua := got.Get("User-Agent"); ua != tc.wantUA {
+			t.Errorf("%s: User-Agent = %q, want %q", tc.service, ua, tc.wantUA)
+		}
+		if v := got.Get("X-Crawler-Contact"); v != "ops@example.com" {
+			t.Errorf("%s: X-Crawler-Contact = %q", tc.service, v)
+		}
+		if v := got.Get("Authorization"); v != "Bearer token" {
+			t.Errorf("%s: client header overwritten: Authorization = %q", tc.service, v)
+		}
+		if req.Header.Get("User-Agent") != "" {
+			t.Errorf("%s: caller's request was mutated", tc.service)
+		}
+	}
+}
+
+func TestNewSendsUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	cfg := config.Config{}
+	cfg.FillDefaults()
+	hc, err := New(cfg, HackerNews, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !strings.HasPrefix(got, "quaily-journalist/") {
+		t.Errorf("User-Agent = %q, want quaily-journalist/<version>", got)
+	}
+}
@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/config"
+)
+
+// defaultBreakerCooldown is used when http.*.breaker_cooldown is unset. The breaker
+// itself is opt-in: it applies only where breaker_threshold is set.
+const defaultBreakerCooldown = 15 * time.Minute
+
+// Reasons a Guard refuses a request.
+const (
+	ReasonBudget  = "request budget exhausted"
+	ReasonBreaker = "circuit open"
+	ReasonProbing = "circuit half-open; probe in flight"
+)
+
+// BlockedError is returned instead of sending a request the service's Guard refuses.
+type BlockedError struct {
+	Service string
+	Reason  string
+	Until   time.Time // when requests may go out again; zero while a probe is in flight
+}
+
+func (e *BlockedError) Error() string {
+	if e.Until.IsZero() {
+		return fmt.Sprintf("http %s: %s", e.Service, e.Reason)
+	}
+	return fmt.Sprintf("http %s: %s until %s", e.Service, e.Reason, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Guard enforces a service's hourly request budget and its circuit breaker. The
+// budget is a bucket of PerHour requests refilled evenly over the hour. The breaker
+// opens after Threshold consecutive 403/429 responses and refuses requests for
+// Cooldown; then one probe request is let through (half-open), which closes it on
+// success or reopens it on another 403/429.
+type Guard struct {
+	service string
+	now     func() time.Time
+
+	mu        sync.Mutex
+	perHour   int           // <= 0 is unlimited
+	threshold int           // <= 0 disables the breaker
+	cooldown  time.Duration // how long the breaker stays open
+	tokens    float64
+	refilled  time.Time
+	failures  int       // consecutive 403/429 responses
+	openUntil time.Time // zero while closed
+	probing   bool      // a half-open probe is in flight
+}
+
+// NewGuard returns a guard for service; now is its clock (nil uses time.Now).
+func NewGuard(service string, perHour, threshold int, cooldown time.Duration, now func() time.Time) *Guard {
+	if now == nil {
+		now = time.Now
+	}
+	g := &Guard{service: service, now: now}
+	g.configure(perHour, threshold, cooldown)
+	return g
+}
+
+// configure applies limits, keeping the spent budget and the breaker state.
+func (g *Guard) configure(perHour, threshold int, cooldown time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.refilled.IsZero() || perHour != g.perHour {
+		g.tokens, g.refilled = float64(perHour), g.now()
+	}
+	g.perHour, g.threshold, g.cooldown = perHour, threshold, cooldown
+}
+
+// refill adds the budget accrued since the last refill. Callers hold mu.
+func (g *Guard) refill(now time.Time) {
+	if g.perHour <= 0 {
+		return
+	}
+	if elapsed := now.Sub(g.refilled); elapsed > 0 {
+		g.tokens += elapsed.Hours() * float64(g.perHour)
+		if g.tokens > float64(g.perHour) {
+			g.tokens = float64(g.perHour)
+		}
+	}
+	g.refilled = now
+}
+
+// blocked reports why a request would be refused at now, without taking budget.
+// Callers hold mu.
+func (g *Guard) blocked(now time.Time) *BlockedError {
+	if g.threshold > 0 && !g.openUntil.IsZero() {
+		if now.Before(g.openUntil) {
+			return &BlockedError{Service: g.service, Reason: ReasonBreaker, Until: g.openUntil}
+		}
+		if g.probing {
+			return &BlockedError{Service: g.service, Reason: ReasonProbing}
+		}
+	}
+	if g.perHour > 0 {
+		g.refill(now)
+		if g.tokens < 1 {
+			wait := time.Duration((1 - g.tokens) / float64(g.perHour) * float64(time.Hour))
+			return &BlockedError{Service: g.service, Reason: ReasonBudget, Until: now.Add(wait)}
+		}
+	}
+	return nil
+}
+
+// Blocked reports whether a request would be refused now, and why.
+func (g *Guard) Blocked() (*BlockedError, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b := g.blocked(g.now())
+	return b, b != nil
+}
+
+// acquire takes one request from the budget, or returns why it cannot go out.
+func (g *Guard) acquire() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b := g.blocked(g.now()); b != nil {
+		return b
+	}
+	if g.perHour > 0 {
+		g.tokens--
+	}
+	if g.threshold > 0 && !g.openUntil.IsZero() {
+		g.probing = true
+	}
+	return nil
+}
+
+// record feeds a response (or transport error) to the breaker.
+func (g *Guard) record(resp *http.Response, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.threshold <= 0 {
+		return
+	}
+	probe := g.probing
+	g.probing = false
+	if err != nil {
+		return // says nothing about the server's verdict; a later request probes again
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		if probe {
+			slog.Info("http: circuit closed", "service", g.service)
+		}
+		g.failures, g.openUntil = 0, time.Time{}
+		return
+	}
+	g.failures++
+	if probe || g.failures >= g.threshold {
+		g.openUntil = g.now().Add(g.cooldown)
+		g.failures = 0
+		slog.Warn("http: circuit open", "service", g.service, "status", resp.StatusCode, "until", g.openUntil)
+	}
+}
+
+// guardTransport sends requests only when the guard allows them.
+type guardTransport struct {
+	base  http.RoundTripper
+	guard *Guard
+}
+
+func (t *guardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.acquire(); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	t.guard.record(resp, err)
+	return resp, err
+}
+
+// guards holds one Guard per service for the process, so every client built for a
+// service, by a collector or a CLI command, shares its budget and breaker.
+var guards = struct {
+	sync.Mutex
+	m map[string]*Guard
+}{m: map[string]*Guard{}}
+
+// guardFor returns the shared guard of service configured from c, or nil when
+// neither a budget nor the breaker applies.
+func guardFor(service string, c config.HTTPClientConfig) (*Guard, error) {
+	threshold := c.BreakerThreshold
+	cooldown := defaultBreakerCooldown
+	if c.BreakerCooldown != "" {
+		d, err := time.ParseDuration(c.BreakerCooldown)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid breaker_cooldown %q", c.BreakerCooldown)
+		}
+		cooldown = d
+	}
+	if c.MaxPerHour <= 0 && threshold <= 0 {
+		return nil, nil
+	}
+	guards.Lock()
+	defer guards.Unlock()
+	g, ok := guards.m[service]
+	if !ok {
+		g = NewGuard(service, c.MaxPerHour, threshold, cooldown, nil)
+		guards.m[service] = g
+		return g, nil
+	}
+	g.configure(c.MaxPerHour, threshold, cooldown)
+	return g, nil
+}
+
+// Blocked reports whether the shared guard of service refuses requests now, so a
+// collector can skip a run instead of failing every request of it.
+func Blocked(service string) (*BlockedError, bool) {
+	guards.Lock()
+	g, ok := guards.m[service]
+	guards.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return g.Blocked()
+}
@@ -0,0 +1,166 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/config"
+)
+
+// fakeClock is a settable clock for guards.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestGuardBudgetRefills(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)}
+	g := NewGuard("v2ex", 4, -1, 0, clock.now)
+	for i := 0; i < 4; i++ {
+		if err := g.acquire(); err != nil {
+			t.Fatalf("request %d refused: %v", i+1, err)
+		}
+	}
+	err := g.acquire()
+	var b *BlockedError
+	if !errors.As(err, &b) || b.Reason != ReasonBudget || !b.Until.Equal(clock.t.Add(15*time.Minute)) {
+		t.Fatalf("fifth request = %v, want the budget exhausted for 15m", err)
+	}
+	if _, blocked := g.Blocked(); !blocked {
+		t.Error("Blocked = false with the budget spent")
+	}
+
+	// A quarter of the hour refills one request, and no more.
+	clock.advance(15 * time.Minute)
+	if err := g.acquire(); err != nil {
+		t.Fatalf("after 15m: %v", err)
+	}
+	if err := g.acquire(); err == nil {
+		t.Fatal("second request after 15m went out")
+	}
+	// Idle time refills up to the hourly budget, not beyond.
+	clock.advance(3 * time.Hour)
+	for i := 0; i < 4; i++ {
+		if err := g.acquire(); err != nil {
+			t.Fatalf("after 3h, request %d refused: %v", i+1, err)
+		}
+	}
+	if err := g.acquire(); err == nil {
+		t.Fatal("budget grew past max_per_hour")
+	}
+}
+
+func TestGuardBreakerHalfOpen(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)}
+	g := NewGuard("reddit", 0, 2, 10*time.Minute, clock.now)
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+
+	// A success in between resets the count of consecutive failures.
+	for _, code := range []int{429, 200, 403} {
+		if err := g.acquire(); err != nil {
+			t.Fatal(err)
+		}
+		g.record(status(code), nil)
+	}
+	if _, blocked := g.Blocked(); blocked {
+		t.Fatal("circuit opened without two consecutive failures")
+	}
+	_ = g.acquire()
+	g.record(status(429), nil)
+	err := g.acquire()
+	var b *BlockedError
+	if !errors.As(err, &b) || b.Reason != ReasonBreaker || !b.Until.Equal(clock.t.Add(10*time.Minute)) {
+		t.Fatalf("request with the circuit open = %v", err)
+	}
+
+	// After the cooldown one probe goes out; others wait for its verdict.
+	clock.advance(10 * time.Minute)
+	if err := g.acquire(); err != nil {
+		t.Fatalf("probe refused: %v", err)
+	}
+	if err := g.acquire(); !errors.As(err, &b) || b.Reason != ReasonProbing {
+		t.Fatalf("request during the probe = %v", err)
+	}
+	// A failed probe reopens the circuit at once.
+	g.record(status(403), nil)
+	if err := g.acquire(); !errors.As(err, &b) || b.Reason != ReasonBreaker {
+		t.Fatalf("after a failed probe = %v, want the circuit open again", err)
+	}
+	clock.advance(10 * time.Minute)
+	_ = g.acquire()
+	g.record(status(200), nil)
+	for i := 0; i < 3; i++ {
+		if err := g.acquire(); err != nil {
+			t.Fatalf("after a good probe, request %d refused: %v", i+1, err)
+		}
+		g.record(status(200), nil)
+	}
+}
+
+// Clients built for the same service draw on one budget, and a refused request
+// never reaches the server or the retries.
+// Without breaker_threshold the breaker stays off: every 403 reaches the service.
+func TestBreakerOptIn(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	c, err := New(config.Config{}, "unset-breaker-test", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request %d = %v, want it sent", i, err)
+		}
+		resp.Body.Close()
+	}
+	if hits.Load() != 20 {
+		t.Errorf("service got %d requests, want 20", hits.Load())
+	}
+	if st, ok := Blocked("unset-breaker-test"); ok {
+		t.Errorf("Blocked = %v with no breaker_threshold", st)
+	}
+}
+
+func TestNewSharesGuardPerService(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+	cfg := config.Config{HTTP: config.HTTPConfig{Services: map[string]config.HTTPClientConfig{
+		"budget-test": {MaxPerHour: 1},
+	}}}
+	a, err := New(cfg, "budget-test", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(cfg, "budget-test", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := a.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	_, err = b.Get(srv.URL)
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Service != "budget-test" || hits.Load() != 1 {
+		t.Fatalf("second client's request = %v after %d hits, want the shared budget exhausted", err, hits.Load())
+	}
+	if st, ok := Blocked("budget-test"); !ok || st.Reason != ReasonBudget {
+		t.Errorf("Blocked = %v, %v", st, ok)
+	}
+	if _, err := New(config.Config{HTTP: config.HTTPConfig{Default: config.HTTPClientConfig{BreakerCooldown: "soon"}}}, "budget-test", time.Second); err == nil {
+		t.Error("invalid breaker_cooldown accepted")
+	}
+}
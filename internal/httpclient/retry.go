@@ -0,0 +1,251 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Policy configures the retry and limiting middleware.
+type Policy struct {
+	MaxRetries    int           // extra attempts after the first; <= 0 disables retries
+	BaseDelay     time.Duration // first backoff; doubled per attempt
+	MaxDelay      time.Duration // cap for computed backoff and honored Retry-After
+	MaxPerHost    int           // concurrent in-flight requests per host; <= 0 is unlimited
+	RatePerSecond float64       // request rate across the client; <= 0 is unlimited
+	Guard         *Guard        // request budget and circuit breaker; nil is unguarded
+}
+
+const (
+	defaultMaxRetries = 2
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+type idempotentKey struct{}
+
+// Idempotent marks requests made with ctx as safe to retry regardless of method.
+// Requests with a body are only retried when it can be replayed (req.GetBody set).
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// Wrap layers retries, the per-host concurrency limit, the rate limit, and the guard
+// over base. Each retry attempt passes through the limiters and the guard again, so
+// every request actually sent counts against the budget.
+func Wrap(base http.RoundTripper, p Policy) http.RoundTripper {
+	rt := base
+	if p.Guard != nil {
+		rt = &guardTransport{base: rt, guard: p.Guard}
+	}
+	if p.RatePerSecond > 0 {
+		rt = &rateTransport{base: rt, interval: time.Duration(float64(time.Second) / p.RatePerSecond)}
+	}
+	if p.MaxPerHost > 0 {
+		rt = &hostTransport{base: rt, max: p.MaxPerHost, sems: map[string]chan struct{}{}}
+	}
+	if p.MaxRetries > 0 {
+		if p.BaseDelay <= 0 {
+			p.BaseDelay = defaultBaseDelay
+		}
+		if p.MaxDelay <= 0 {
+			p.MaxDelay = defaultMaxDelay
+		}
+		rt = &retryTransport{base: rt, policy: p, sleep: sleepCtx}
+	}
+	return rt
+}
+
+// retryTransport retries idempotent requests on 429, 5xx, and connection resets.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy Policy
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !retryable(req) {
+		return t.base.RoundTrip(req)
+	}
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		if attempt >= t.policy.MaxRetries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if ra > t.policy.MaxDelay {
+					// The server asked for a longer pause than we are willing to wait.
+					return resp, nil
+				}
+				delay = ra
+			}
+			// Drain so the connection can be reused (and per-host slots released).
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		slog.Debug("http: retrying request", "method", req.Method, "host", req.URL.Host, "attempt", attempt+1, "delay", delay, "status", statusOf(resp), "err", err)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns BaseDelay*2^attempt with up to 20% jitter, capped at MaxDelay.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.policy.BaseDelay << uint(attempt)
+	if d <= 0 || d > t.policy.MaxDelay {
+		d = t.policy.MaxDelay
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// hostTransport bounds concurrent in-flight requests per host. A slot is held
+// until the response body is closed.
+type hostTransport struct {
+	base http.RoundTripper
+	max  int
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func (t *hostTransport) sem(host string) chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sems[host]
+	if !ok {
+		s = make(chan struct{}, t.max)
+		t.sems[host] = s
+	}
+	return s
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := t.sem(req.URL.Host)
+	select {
+	case s <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-s }
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// rateTransport spaces requests at least interval apart.
+type rateTransport struct {
+	base     http.RoundTripper
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func (t *rateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	now := time.Now()
+	at := t.next
+	if at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(t.interval)
+	t.mu.Unlock()
+	if err := sleepCtx(req.Context(), time.Until(at)); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,353 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// step is one scripted reply: either a status (with optional headers) or a transport error.
+type step struct {
+	status int
+	header http.Header
+	err    error
+}
+
+// scriptedTransport replays steps in order and records the bodies it received.
+type scriptedTransport struct {
+	mu     sync.Mutex
+	steps  []step
+	calls  int
+	bodies []string
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.calls >= len(t.steps) {
+		return nil, fmt.Errorf("unexpected call %d", t.calls+1)
+	}
+	s := t.steps[t.calls]
+	t.calls++
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		t.bodies = append(t.bodies, string(b))
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	h := s.header
+	if h == nil {
+		h = http.Header{}
+	}
+	return &http.Response{StatusCode: s.status, Header: h, Body: io.NopCloser(strings.NewReader("body")), Request: req}, nil
+}
+
+// newTestRetry returns a retry transport over script that records sleeps instead of waiting.
+func newTestRetry(script *scriptedTransport, retries int) (*retryTransport, *[]time.Duration) {
+	var slept []time.Duration
+	rt := Wrap(script, Policy{MaxRetries: retries, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}).(*retryTransport)
+	rt.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return rt, &slept
+}
+
+func get(t *testing.T, rt http.RoundTripper) (*http.Response, error) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/items", nil)
+	return rt.RoundTrip(req)
+}
+
+func TestRetryRecoversFromTransientErrors(t *testing.T) {
+	script := &scriptedTransport{steps: []step{{status: 503}, {status: 502}, {status: 200}}}
+	rt, slept := newTestRetry(script, 3)
+
+	resp, err := get(t, rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || script.calls != 3 {
+		t.Fatalf("status=%d calls=%d, want 200 after 3 calls", resp.StatusCode, script.calls)
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("slept %d times, want 2", len(*slept))
+	}
+	// Exponential backoff with at most 20% jitter below the nominal delay.
+	for i, nominal := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+		if d := (*slept)[i]; d > nominal || d < nominal*8/10 {
+			t.Errorf("backoff %d = %v, want within [%v, %v]", i, d, nominal*8/10, nominal)
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	script := &scriptedTransport{steps: []step{{status: 500}, {status: 500}, {status: 500}}}
+	rt, _ := newTestRetry(script, 2)
+
+	resp, err := get(t, rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 500 || script.calls != 3 {
+		t.Fatalf("status=%d calls=%d, want final 500 after 3 calls", resp.StatusCode, script.calls)
+	}
+	if b, _ := io.ReadAll(resp.Body); string(b) != "body" {
+		t.Errorf("final response body should be intact, got %q", b)
+	}
+}
+
+func TestRetrySkipsNonRetryableStatus(t *testing.T) {
+	for _, status := range []int{200, 304, 400, 401, 404} {
+		script := &scriptedTransport{steps: []step{{status: status}}}
+		rt, _ := newTestRetry(script, 3)
+		resp, err := get(t, rt)
+		if err != nil || resp.StatusCode != status || script.calls != 1 {
+			t.Errorf("status %d: calls=%d err=%v, want a single attempt", status, script.calls, err)
+		}
+	}
+}
+
+func TestRetryConnectionErrors(t *testing.T) {
+	reset := &netOpError{err: syscall.ECONNRESET}
+	script := &scriptedTransport{steps: []step{{err: reset}, {err: io.ErrUnexpectedEOF}, {status: 200}}}
+	rt, _ := newTestRetry(script, 3)
+	resp, err := get(t, rt)
+	if err != nil || resp.StatusCode != 200 || script.calls != 3 {
+		t.Fatalf("calls=%d err=%v, want success after resets", script.calls, err)
+	}
+
+	// Other errors (DNS, TLS, ...) are returned immediately.
+	other := errors.New("tls: handshake failure")
+	script = &scriptedTransport{steps: []step{{err: other}}}
+	rt, _ = newTestRetry(script, 3)
+	if _, err := get(t, rt); !errors.Is(err, other) || script.calls != 1 {
+		t.Fatalf("calls=%d err=%v, want the original error after one call", script.calls, err)
+	}
+}
+
+// netOpError wraps a syscall error like *net.OpError does.
+type netOpError struct{ err error }
+
+func (e *netOpError) Error() string { return "read tcp: " + e.err.Error() }
+func (e *netOpError) Unwrap() error { return e.err }
+
+func TestRetryOnlyIdempotentRequests(t *testing.T) {
+	// Unmarked POST: never retried.
+	script := &scriptedTransport{steps: []step{{status: 503}}}
+	rt, _ := newTestRetry(script, 3)
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/posts", strings.NewReader(`{"a":1}`))
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != 503 || script.calls != 1 {
+		t.Fatalf("unmarked POST: calls=%d err=%v, want one attempt", script.calls, err)
+	}
+
+	// Marked POST: retried with the body replayed each time.
+	script = &scriptedTransport{steps: []step{{status: 429}, {status: 200}}}
+	rt, _ = newTestRetry(script, 3)
+	req, _ = http.NewRequestWithContext(Idempotent(context.Background()), http.MethodPost, "https://api.example.com/render", strings.NewReader(`{"url":"x"}`))
+	resp, err = rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != 200 || script.calls != 2 {
+		t.Fatalf("marked POST: calls=%d err=%v, want success on retry", script.calls, err)
+	}
+	for i, b := range script.bodies {
+		if b != `{"url":"x"}` {
+			t.Errorf("attempt %d body = %q, want replayed payload", i+1, b)
+		}
+	}
+
+	// Marked but not replayable (no GetBody): single attempt.
+	script = &scriptedTransport{steps: []step{{status: 503}}}
+	rt, _ = newTestRetry(script, 3)
+	req, _ = http.NewRequestWithContext(Idempotent(context.Background()), http.MethodPost, "https://api.example.com/render", io.NopCloser(strings.NewReader("stream")))
+	if _, err := rt.RoundTrip(req); err != nil || script.calls != 1 {
+		t.Fatalf("unreplayable body: calls=%d err=%v, want one attempt", script.calls, err)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	script := &scriptedTransport{steps: []step{
+		{status: 429, header: http.Header{"Retry-After": {"3"}}},
+		{status: 503, header: http.Header{"Retry-After": {time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)}}},
+		{status: 200},
+	}}
+	rt, slept := newTestRetry(script, 3)
+	resp, err := get(t, rt)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("status=%v err=%v", resp, err)
+	}
+	if (*slept)[0] != 3*time.Second {
+		t.Errorf("Retry-After seconds: slept %v, want 3s", (*slept)[0])
+	}
+	if d := (*slept)[1]; d < 3*time.Second || d > 5*time.Second {
+		t.Errorf("Retry-After date: slept %v, want ~5s", d)
+	}
+
+	// A Retry-After beyond MaxDelay is not waited for; the 429 is returned.
+	script = &scriptedTransport{steps: []step{{status: 429, header: http.Header{"Retry-After": {"3600"}}}}}
+	rt, slept = newTestRetry(script, 3)
+	resp, err = get(t, rt)
+	if err != nil || resp.StatusCode != 429 || script.calls != 1 || len(*slept) != 0 {
+		t.Fatalf("long Retry-After: status=%d calls=%d slept=%v", resp.StatusCode, script.calls, *slept)
+	}
+}
+
+func TestRetryAfterParsing(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tc := range cases {
+		got, ok := retryAfter(tc.in, now)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRetryStopsWhenContextCanceled(t *testing.T) {
+	script := &scriptedTransport{steps: []step{{status: 503}, {status: 200}}}
+	rt := Wrap(script, Policy{MaxRetries: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/", nil)
+	start := time.Now()
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > time.Second || script.calls != 1 {
+		t.Fatalf("calls=%d elapsed=%v, want prompt abort during backoff", script.calls, time.Since(start))
+	}
+}
+
+// blockingTransport holds each request until released and tracks peak concurrency per host.
+type blockingTransport struct {
+	mu      sync.Mutex
+	active  map[string]int
+	peak    map[string]int
+	release chan struct{}
+}
+
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	t.mu.Lock()
+	t.active[host]++
+	if t.active[host] > t.peak[host] {
+		t.peak[host] = t.active[host]
+	}
+	t.mu.Unlock()
+	<-t.release
+	t.mu.Lock()
+	t.active[host]--
+	t.mu.Unlock()
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestPerHostConcurrencyLimit(t *testing.T) {
+	bt := &blockingTransport{active: map[string]int{}, peak: map[string]int{}, release: make(chan struct{})}
+	rt := Wrap(bt, Policy{MaxPerHost: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		for _, host := range []string{"a.example.com", "b.example.com"} {
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "https://"+host+"/", nil)
+				resp, err := rt.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}(host)
+		}
+	}
+	// Let requests pile up, then release them one at a time.
+	time.Sleep(20 * time.Millisecond)
+	for i := 0; i < 12; i++ {
+		bt.release <- struct{}{}
+	}
+	wg.Wait()
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		if p := bt.peak[host]; p != 2 {
+			t.Errorf("%s peak concurrency = %d, want 2", host, p)
+		}
+	}
+}
+
+func TestPerHostSlotHeldUntilBodyClosed(t *testing.T) {
+	script := &scriptedTransport{steps: []step{{status: 200}, {status: 200}}}
+	rt := Wrap(script, Policy{MaxPerHost: 1})
+	first, err := get(t, rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/items", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second request err = %v, want to block while the first body is open", err)
+	}
+	first.Body.Close()
+	first.Body.Close() // double close must not release twice
+	if resp, err := get(t, rt); err != nil {
+		t.Fatalf("after close: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var n atomic.Int32
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n.Add(1)
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+	rt := Wrap(base, Policy{RatePerSecond: 100})
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := get(t, rt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// The first request is immediate; the next four are spaced 10ms apart.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("5 requests at 100/s took %v, want >= ~40ms", elapsed)
+	}
+	if n.Load() != 5 {
+		t.Errorf("sent %d requests, want 5", n.Load())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWrapWithoutPolicyIsPassthrough(t *testing.T) {
+	script := &scriptedTransport{}
+	if rt := Wrap(script, Policy{}); rt != script {
+		t.Errorf("empty policy should return the base transport, got %T", rt)
+	}
+}
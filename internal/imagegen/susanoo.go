@@ -34,6 +34,7 @@ type SusanooConfig struct {
 	AspectRatio string
 	Timeout     time.Duration
 	WebPQuality int
+	HTTPClient  *http.Client // optional; defaults to a client with Timeout
 }
 
 // Susanoo implements Generator using Susanoo image generation.
@@ -64,6 +65,10 @@ func NewSusanoo(cfg SusanooConfig) (*Susanoo, error) {
 	if quality <= 0 || quality > 100 {
 		quality = 85
 	}
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: timeout}
+	}
 	return &Susanoo{
 		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
 		apiKey:      cfg.APIKey,
@@ -71,7 +76,7 @@ func NewSusanoo(cfg SusanooConfig) (*Susanoo, error) {
 		aspectRatio: strings.TrimSpace(cfg.AspectRatio),
 		timeout:     timeout,
 		webPQuality: quality,
-		httpClient:  &http.Client{Timeout: timeout},
+		httpClient:  hc,
 	}, nil
 }
 
@@ -134,7 +139,7 @@ func (s *Susanoo) GenerateCover(ctx context.Context, prompt, outPath string) err
 		return fmt.Errorf("susanoo request: %w", err)
 	}
 	defer resp.Body.Close()
-	slog.Info("susanoo: response received",
+	slog.Debug("susanoo: response received",
 		"status", resp.StatusCode,
 		"duration", time.Since(reqStart),
 	)
@@ -156,13 +161,13 @@ func (s *Susanoo) GenerateCover(ctx context.Context, prompt, outPath string) err
 	if err != nil {
 		return fmt.Errorf("decode base64 image: %w", err)
 	}
-	slog.Info("susanoo: image payload decoded", "bytes", len(raw))
+	slog.Debug("susanoo: image payload decoded", "bytes", len(raw))
 	img, _, err := image.Decode(bytes.NewReader(raw))
 	if err != nil {
 		return fmt.Errorf("decode image: %w", err)
 	}
 	bounds := img.Bounds()
-	slog.Info("susanoo: image decoded",
+	slog.Debug("susanoo: image decoded",
 		"width", bounds.Dx(),
 		"height", bounds.Dy(),
 	)
@@ -176,7 +181,7 @@ func (s *Susanoo) GenerateCover(ctx context.Context, prompt, outPath string) err
 	}
 	defer f.Close()
 
-	slog.Info("susanoo: writing webp", "path", outPath, "quality", s.webPQuality)
+	slog.Debug("susanoo: writing webp", "path", outPath, "quality", s.webPQuality)
 	if err := webp.Encode(f, img, &webp.Options{Quality: float32(s.webPQuality)}); err != nil {
 		return fmt.Errorf("encode webp: %w", err)
 	}
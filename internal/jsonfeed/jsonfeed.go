@@ -0,0 +1,213 @@
+// Package jsonfeed reads JSON Feed (https://jsonfeed.org) versions 1 and 1.1 into
+// model.NewsItem.
+package jsonfeed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/textclean"
+)
+
+// Source is the model.NewsItem source of JSON Feed items.
+const Source = "jsonfeed"
+
+// versionPrefix starts the version URL of every JSON Feed 1.x document, e.g.,
+// https://jsonfeed.org/version/1.1.
+const versionPrefix = "https://jsonfeed.org/version/1"
+
+// maxFeedBytes caps the feed body read per fetch.
+const maxFeedBytes = 10 << 20
+
+// Client fetches feeds over HTTP.
+type Client struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClient returns a feed client with a 15s timeout.
+func NewClient() *Client {
+	return &Client{client: &http.Client{Timeout: 15 * time.Second}, now: time.Now}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// Fetch reads the feed at feedURL. Items are labeled with node, or with the feed's
+// title when node is empty.
+func (c *Client) Fetch(ctx context.Context, feedURL, node string) ([]model.NewsItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/feed+json, application/json;q=0.9")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("jsonfeed: %s: status %d", feedURL, resp.StatusCode)
+	}
+	items, err := Parse(io.LimitReader(resp.Body, maxFeedBytes), feedURL, node, c.now())
+	if err != nil {
+		return nil, fmt.Errorf("jsonfeed: %s: %w", feedURL, err)
+	}
+	return items, nil
+}
+
+// feed is the top-level object.
+type feed struct {
+	Version string            `json:"version"`
+	Title   string            `json:"title"`
+	Items   []json.RawMessage `json:"items"`
+}
+
+// author is a 1.1 authors entry, or the 1.0 author object.
+type author struct {
+	Name string `json:"name"`
+}
+
+type item struct {
+	// ID is a string in 1.1; 1.0 feeds also used numbers.
+	ID            json.RawMessage `json:"id"`
+	URL           string          `json:"url"`
+	ExternalURL   string          `json:"external_url"`
+	Title         string          `json:"title"`
+	ContentText   string          `json:"content_text"`
+	ContentHTML   string          `json:"content_html"`
+	Summary       string          `json:"summary"`
+	DatePublished string          `json:"date_published"`
+	DateModified  string          `json:"date_modified"`
+	Authors       []author        `json:"authors"`
+	Author        *author         `json:"author"`
+}
+
+// Parse reads a feed document, which must declare a JSON Feed 1.x version. Item
+// IDs are derived from the id, else the url; items with neither are skipped. The
+// content is content_text, else content_html as plain text, else the summary.
+// Items without a parseable date get now, and relative links are resolved against
+// feedURL.
+func Parse(r io.Reader, feedURL, node string, now time.Time) ([]model.NewsItem, error) {
+	var f feed
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(f.Version), versionPrefix) {
+		return nil, fmt.Errorf("parse feed: unsupported version %q (want %s or %s.1)", f.Version, versionPrefix, versionPrefix)
+	}
+	if node == "" {
+		node = clean(f.Title)
+	}
+	base, _ := url.Parse(feedURL)
+	var out []model.NewsItem
+	for _, raw := range f.Items {
+		var it item
+		if err := json.Unmarshal(raw, &it); err != nil {
+			return nil, fmt.Errorf("parse feed: item: %w", err)
+		}
+		link := resolve(base, firstNonEmpty(it.URL, it.ExternalURL))
+		key := firstNonEmpty(itemKey(it.ID), link)
+		if key == "" {
+			continue
+		}
+		content := strings.TrimSpace(it.ContentText)
+		if content == "" {
+			content = textclean.StripHTML(it.ContentHTML)
+		}
+		if content == "" {
+			content = strings.TrimSpace(it.Summary)
+		}
+		name := ""
+		if len(it.Authors) > 0 {
+			name = it.Authors[0].Name
+		} else if it.Author != nil {
+			name = it.Author.Name
+		}
+		title := clean(it.Title)
+		if title == "" {
+			title = link
+		}
+		out = append(out, model.NewsItem{
+			Source:    Source,
+			ID:        ItemID(key),
+			Title:     title,
+			URL:       link,
+			NodeName:  node,
+			CreatedAt: parseDate(firstNonEmpty(it.DatePublished, it.DateModified), now),
+			Content:   content,
+			Author:    clean(name),
+			Raw:       bytes.TrimSpace(raw),
+		})
+	}
+	return out, nil
+}
+
+// itemKey returns an item id as text: a JSON string unquoted, a number as written.
+func itemKey(id json.RawMessage) string {
+	var s string
+	if json.Unmarshal(id, &s) == nil {
+		return strings.TrimSpace(s)
+	}
+	var n json.Number
+	if json.Unmarshal(id, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// ItemID returns the stored ID of a feed item with the given id or url: a short
+// hash, so IDs stay compact and safe in Redis keys and file names.
+func ItemID(key string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// parseDate reads an RFC 3339 date, as the spec requires; anything else is now.
+func parseDate(s string, now time.Time) time.Time {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(s)); err == nil {
+		return t.UTC()
+	}
+	return now.UTC()
+}
+
+func resolve(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" || base == nil {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(u).String()
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
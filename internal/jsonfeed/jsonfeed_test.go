@@ -0,0 +1,108 @@
+package jsonfeed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+var now = time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/feed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	items, err := Parse(f, "https://example.org/feed.json", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3: %+v", len(items), items)
+	}
+	first := items[0]
+	want := model.NewsItem{
+		Source:    "jsonfeed",
+		ID:        ItemID("https://example.org/posts/pgo"),
+		Title:     "Profile-guided optimization in practice",
+		URL:       "https://example.org/posts/pgo",
+		NodeName:  "Example Engineering",
+		CreatedAt: time.Date(2025, 10, 20, 6, 30, 0, 0, time.UTC),
+		Content:   "We turned on PGO for our API servers.\n\nCPU fell by 9%.",
+		Author:    "Ada Example",
+	}
+	if !strings.Contains(string(first.Raw), `"date_published"`) {
+		t.Errorf("first item raw = %q, want the item's JSON", first.Raw)
+	}
+	first.Raw = nil
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("first item =\n%+v\nwant\n%+v", first, want)
+	}
+
+	second := items[1]
+	if second.ID != ItemID("note-2") || second.URL != "https://example.org/notes/2" || second.Title != second.URL {
+		t.Errorf("relative link and title fallback: %+v", second)
+	}
+	if second.Content != "A short note without a title." || !second.CreatedAt.Equal(time.Date(2025, 10, 21, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("content_text and date_modified: %+v", second)
+	}
+
+	third := items[2]
+	if third.ID != ItemID("3") || third.URL != "https://go.dev/blog/" || third.Content != "Worth a read." || !third.CreatedAt.Equal(now) {
+		t.Errorf("numeric id, external_url, summary and bad date: %+v", third)
+	}
+}
+
+func TestParseLabel(t *testing.T) {
+	doc := `{"version": "https://jsonfeed.org/version/1", "title": "Blog", "items": [{"id": "1", "url": "https://b.example/1", "author": {"name": "Old Style"}}]}`
+	items, err := Parse(strings.NewReader(doc), "https://b.example/feed.json", "friends", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].NodeName != "friends" || items[0].Author != "Old Style" {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestParseRejectsOtherDocuments(t *testing.T) {
+	for name, doc := range map[string]string{
+		"no version":    `{"title": "x", "items": []}`,
+		"other version": `{"version": "https://jsonfeed.org/version/2", "items": []}`,
+		"not json":      `<rss version="2.0"></rss>`,
+	} {
+		if _, err := Parse(strings.NewReader(doc), "https://x.example/feed.json", "", now); err == nil {
+			t.Errorf("%s: parsed without error", name)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/feed+json")
+		http.ServeFile(w, r, "testdata/feed.json")
+	}))
+	defer srv.Close()
+	c := NewClient().WithHTTPClient(srv.Client())
+	items, err := c.Fetch(context.Background(), srv.URL+"/feed.json", "eng")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].NodeName != "eng" || items[1].URL != srv.URL+"/notes/2" {
+		t.Errorf("items = %+v", items)
+	}
+	if _, err := c.Fetch(context.Background(), srv.URL+"/missing.json", "eng"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("missing feed err = %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package lobsters reads the Lobste.rs story listings (hottest.json, newest.json)
+// into model.NewsItem.
+package lobsters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of Lobste.rs stories.
+const Source = "lobsters"
+
+// DefaultBaseURL is the Lobste.rs site, which serves the JSON listings.
+const DefaultBaseURL = "https://lobste.rs"
+
+// Listings the client reads.
+const (
+	Hottest = "hottest"
+	Newest  = "newest"
+)
+
+// Client is a minimal Lobste.rs client.
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient returns a client for the site at baseURL; empty uses DefaultBaseURL.
+func NewClient(baseURL string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// Hottest returns the stories of the front page.
+func (c *Client) Hottest(ctx context.Context) ([]model.NewsItem, error) {
+	return c.list(ctx, Hottest)
+}
+
+// Newest returns the most recently submitted stories.
+func (c *Client) Newest(ctx context.Context) ([]model.NewsItem, error) {
+	return c.list(ctx, Newest)
+}
+
+// story mirrors the listing fields items are built from.
+type story struct {
+	ShortID          string          `json:"short_id"`
+	ShortIDURL       string          `json:"short_id_url"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Title            string          `json:"title"`
+	URL              string          `json:"url"`
+	Score            int             `json:"score"`
+	CommentCount     int             `json:"comment_count"`
+	Description      string          `json:"description"`
+	DescriptionPlain string          `json:"description_plain"`
+	CommentsURL      string          `json:"comments_url"`
+	Submitter        json.RawMessage `json:"submitter_user"` // a username, or an object with one on older deployments
+	Tags             []string        `json:"tags"`
+}
+
+func (c *Client) list(ctx context.Context, name string) ([]model.NewsItem, error) {
+	endpoint := c.baseURL + "/" + name + ".json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("lobsters %s: status %d", name, resp.StatusCode)
+	}
+	var payloads []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&payloads); err != nil {
+		return nil, fmt.Errorf("lobsters %s: decode: %w", name, err)
+	}
+	out := make([]model.NewsItem, 0, len(payloads))
+	for _, payload := range payloads {
+		var s story
+		if err := json.Unmarshal(payload, &s); err != nil {
+			return nil, fmt.Errorf("lobsters %s: decode story: %w", name, err)
+		}
+		if s.ShortID == "" {
+			continue
+		}
+		it := s.item()
+		it.Raw = payload
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// item maps a story to a NewsItem. Text posts without a URL link their comments
+// page, and the tags become the node name (see NodeName).
+func (s story) item() model.NewsItem {
+	link := strings.TrimSpace(s.URL)
+	if link == "" {
+		link = firstNonEmpty(s.CommentsURL, s.ShortIDURL)
+	}
+	content := s.DescriptionPlain
+	if strings.TrimSpace(content) == "" {
+		content = s.Description
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        s.ShortID,
+		Title:     strings.TrimSpace(s.Title),
+		URL:       link,
+		NodeName:  NodeName(s.Tags),
+		Replies:   s.CommentCount,
+		Points:    s.Score,
+		CreatedAt: s.CreatedAt.UTC(),
+		Content:   strings.TrimSpace(content),
+		Author:    submitter(s.Submitter),
+	}
+}
+
+// NodeName joins a story's tags into the node name it is stored under, e.g.
+// "go,security"; Lobste.rs serves the tag page of that name at /t/go,security.
+func NodeName(tags []string) string {
+	clean := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			clean = append(clean, t)
+		}
+	}
+	return strings.Join(clean, ",")
+}
+
+// Tags splits a node name made by NodeName back into tags.
+func Tags(node string) []string {
+	var out []string
+	for _, t := range strings.Split(node, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func submitter(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	_ = json.Unmarshal(raw, &user)
+	return user.Username
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
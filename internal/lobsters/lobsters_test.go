@@ -0,0 +1,79 @@
+package lobsters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestHottest(t *testing.T) {
+	body, err := os.ReadFile("testdata/hottest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hottest.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	items, err := NewClient(srv.URL).Hottest(context.Background())
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Hottest = %d items, %v; want 2", len(items), err)
+	}
+	if !strings.Contains(string(items[0].Raw), `"user_is_author": true`) {
+		t.Errorf("items[0].Raw = %s, want the story as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "x9kq2m",
+		Title:     "Profiling Go services with continuous pprof",
+		URL:       "https://example.com/continuous-pprof",
+		NodeName:  "go,performance",
+		Replies:   17,
+		Points:    42,
+		CreatedAt: time.Date(2025, 10, 24, 12, 12, 34, 0, time.UTC),
+		Author:    "gopher",
+	}, {
+		// A text post links its comments page; older APIs nest the submitter.
+		Source:    Source,
+		ID:        "a1b2c3",
+		Title:     "Ask: how do you rotate SSH host keys?",
+		URL:       "https://lobste.rs/s/a1b2c3/ask_how_do_you_rotate_ssh_host_keys",
+		NodeName:  "ask,security",
+		Replies:   4,
+		Points:    9,
+		CreatedAt: time.Date(2025, 10, 24, 10, 0, 0, 0, time.UTC),
+		Content:   "We have a few hundred hosts & no CA yet.",
+		Author:    "opsfolk",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+
+	if _, err := NewClient(srv.URL).Newest(context.Background()); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Newest on a missing listing = %v, want a status error", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	if got := NodeName([]string{" Go", "", "security"}); got != "go,security" {
+		t.Errorf("NodeName = %q", got)
+	}
+	if got := Tags("go, security,"); !reflect.DeepEqual(got, []string{"go", "security"}) {
+		t.Errorf("Tags = %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+// Package logging configures the process-wide slog handler from the app config.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"quaily-journalist/internal/config"
+)
+
+// Setup installs the default slog logger per app.log_level, app.log_format, and
+// app.log_file. Logs go to stderr unless a file is set, which is opened for append
+// and stays open for the life of the process. The standard log package is routed
+// through the same handler.
+func Setup(cfg config.AppConfig) error {
+	level, err := ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stderr
+	if path := strings.TrimSpace(cfg.LogFile); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open log file: %w", err)
+		}
+		w = f
+	}
+	h, err := NewHandler(w, cfg.LogFormat, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// NewHandler returns a text (default) or json handler writing to w at level.
+func NewHandler(w io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return slog.NewTextHandler(w, opts), nil
+	case "json":
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("invalid app.log_format %q: must be text or json", format)
+	}
+}
+
+// ParseLevel maps debug, info, warn (or warning), and error to slog levels; empty is info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid app.log_level %q: must be debug, info, warn, or error", s)
+	}
+}
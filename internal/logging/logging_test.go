@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestJSONHandlerRespectsLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	h, err := NewHandler(&buf, "json", level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(h)
+	log.Info("builder: published", "channel", "ch")
+	log.Warn("builder: write file failed", "channel", "ch")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("want exactly one JSON record, got %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "WARN" || rec["channel"] != "ch" {
+		t.Errorf("record = %v", rec)
+	}
+}
+
+func TestInvalidSettings(t *testing.T) {
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) = nil error")
+	}
+	if _, err := NewHandler(&bytes.Buffer{}, "logfmt", slog.LevelInfo); err == nil {
+		t.Error("NewHandler(logfmt) = nil error")
+	}
+}
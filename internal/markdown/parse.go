@@ -24,8 +24,12 @@ func ParseFile(path string) (Document, error) {
 		return Document{}, err
 	}
 	defer f.Close()
+	return Parse(f)
+}
 
-	br := bufio.NewReader(f)
+// Parse reads a Markdown document from r; see ParseFile.
+func Parse(r io.Reader) (Document, error) {
+	br := bufio.NewReader(r)
 	peek, err := br.Peek(3)
 	if err != nil && !errors.Is(err, io.EOF) {
 		return Document{}, err
@@ -0,0 +1,198 @@
+// Package mastodon reads the trending links of a Mastodon instance
+// (/api/v1/trends/links) into model.NewsItem.
+package mastodon
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of Mastodon trending links.
+const Source = "mastodon"
+
+// pageSize is the most links the API returns per request; maxLinks caps the pages read.
+const (
+	pageSize = 20
+	maxLinks = 40
+)
+
+// Client is a minimal client of one Mastodon instance.
+type Client struct {
+	baseURL string
+	host    string
+	token   string
+	client  *http.Client
+}
+
+// NewClient returns a client for the instance at baseURL (e.g., https://mastodon.social).
+// token is optional; instances that keep trends private need one.
+func NewClient(baseURL, token string) *Client {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return &Client{baseURL: baseURL, host: Host(baseURL), token: strings.TrimSpace(token), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// Host returns the instance host items are stored under.
+func (c *Client) Host() string { return c.host }
+
+// Host returns the lower-cased host of an instance base URL, which also accepts a
+// bare host such as mastodon.social.
+func Host(baseURL string) string {
+	s := strings.TrimSpace(baseURL)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(baseURL))
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// link mirrors the preview card fields items are built from.
+type link struct {
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	AuthorName   string    `json:"author_name"`
+	ProviderName string    `json:"provider_name"`
+	History      []history `json:"history"`
+}
+
+// history is one day of a link's activity, newest first; the API sends numbers as strings.
+type history struct {
+	Day      string `json:"day"` // UNIX timestamp of the day's start
+	Uses     string `json:"uses"`
+	Accounts string `json:"accounts"`
+}
+
+// TrendingLinks returns the instance's trending links in the instance's order.
+func (c *Client) TrendingLinks(ctx context.Context) ([]model.NewsItem, error) {
+	var out []model.NewsItem
+	for offset := 0; offset < maxLinks; offset += pageSize {
+		page, err := c.page(ctx, offset)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (c *Client) page(ctx context.Context, offset int) ([]model.NewsItem, error) {
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(pageSize))
+	q.Set("offset", strconv.Itoa(offset))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/trends/links?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("mastodon %s trends: status %d", c.host, resp.StatusCode)
+	}
+	var raws []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raws); err != nil {
+		return nil, fmt.Errorf("mastodon %s trends: decode: %w", c.host, err)
+	}
+	out := make([]model.NewsItem, 0, len(raws))
+	for _, raw := range raws {
+		var l link
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return nil, fmt.Errorf("mastodon %s trends: decode link: %w", c.host, err)
+		}
+		if strings.TrimSpace(l.URL) == "" {
+			continue
+		}
+		it := l.item(c.host)
+		it.Raw = raw
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// item maps a link to a NewsItem stored under the instance host. Its points are the
+// accounts that shared it per day with each earlier day counting half as much as
+// the next (see activity), its replies the posts sharing it over the history, and
+// it is dated at the start of its latest day with activity. The ID hashes host and
+// URL, so a link trending on two instances is kept for each.
+func (l link) item(host string) model.NewsItem {
+	points, uses, last := activity(l.History)
+	author := strings.TrimSpace(l.AuthorName)
+	if author == "" {
+		author = strings.TrimSpace(l.ProviderName)
+	}
+	u := strings.TrimSpace(l.URL)
+	title := strings.TrimSpace(l.Title)
+	if title == "" {
+		title = u
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        ItemID(host, u),
+		Title:     title,
+		URL:       u,
+		NodeName:  host,
+		Replies:   uses,
+		Points:    points,
+		CreatedAt: last,
+		Content:   strings.TrimSpace(l.Description),
+		Author:    author,
+	}
+}
+
+// activity sums a link's history, newest day first: points are the accounts per
+// day weighted by 2^-n on the n-th day back, uses the plain total, and last the
+// start of the newest day with any use (zero when there is none).
+func activity(h []history) (points, uses int, last time.Time) {
+	var weighted float64
+	for i, d := range h {
+		accounts, _ := strconv.Atoi(strings.TrimSpace(d.Accounts))
+		n, _ := strconv.Atoi(strings.TrimSpace(d.Uses))
+		weighted += float64(accounts) * math.Pow(0.5, float64(i))
+		uses += n
+		if last.IsZero() && n > 0 {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(d.Day), 10, 64); err == nil {
+				last = time.Unix(sec, 0).UTC()
+			}
+		}
+	}
+	return int(math.Round(weighted)), uses, last
+}
+
+// ItemID returns the stored ID of a link trending on host: a short hash of both,
+// compact and safe in Redis keys and file names.
+func ItemID(host, linkURL string) string {
+	sum := sha1.Sum([]byte(host + " " + linkURL))
+	return hex.EncodeToString(sum[:8])
+}
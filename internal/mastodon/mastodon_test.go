@@ -0,0 +1,103 @@
+package mastodon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestTrendingLinks(t *testing.T) {
+	body, err := os.ReadFile("testdata/links.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/trends/links" || q.Get("limit") != "20" || q.Get("offset") != "0" {
+			http.Error(w, "bad request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/", "tok")
+	host := Host(srv.URL)
+	if c.Host() != host || host != "127.0.0.1" {
+		t.Fatalf("Host = %q, %q", c.Host(), host)
+	}
+	items, err := c.TrendingLinks(context.Background())
+	if err != nil || len(items) != 2 {
+		t.Fatalf("TrendingLinks = %d items, %v", len(items), err)
+	}
+	if auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if !strings.Contains(string(items[0].Raw), `"provider_name": "Example Weekly"`) {
+		t.Errorf("items[0].Raw = %s, want the link as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        ItemID(host, "https://example.org/2025/10/open-protocols"),
+		Title:     "Why open protocols keep winning",
+		URL:       "https://example.org/2025/10/open-protocols",
+		NodeName:  host,
+		Replies:   180,
+		Points:    100, // 80 today + 40/2 yesterday
+		CreatedAt: time.Date(2025, 10, 24, 0, 0, 0, 0, time.UTC),
+		Content:   "A look at how ActivityPub, RSS, and email outlived the platforms built on top of them.",
+		Author:    "Jane Doe",
+	}, {
+		Source:    Source,
+		ID:        ItemID(host, "https://news.example.com/fediverse-growth"),
+		Title:     "The fediverse passed 15 million accounts",
+		URL:       "https://news.example.com/fediverse-growth",
+		NodeName:  host,
+		Replies:   40,
+		Points:    13, // 22/2 + 9/4, rounded
+		CreatedAt: time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC),
+		Author:    "Example News",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+	if items[0].ID == ItemID("mastodon.social", items[0].URL) {
+		t.Error("item ID does not depend on the instance")
+	}
+}
+
+func TestTrendingLinksStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "trends are private", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	if _, err := NewClient(srv.URL, "").TrendingLinks(context.Background()); err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("err = %v, want status 401", err)
+	}
+}
+
+func TestHost(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://Mastodon.Social":   "mastodon.social",
+		"https://fosstodon.org/":    "fosstodon.org",
+		"hachyderm.io":              "hachyderm.io",
+		" https://a.example:8443/ ": "a.example",
+	} {
+		if got := Host(in); got != want {
+			t.Errorf("Host(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+// Package mocksource serves model.NewsItem fixtures from disk in place of the V2EX,
+// Hacker News, Lobste.rs, Reddit, GitHub, Product Hunt, arXiv, Mastodon, Bluesky,
+// and Stack Exchange APIs and of RSS, JSON, and YouTube feeds, so the pipeline can
+// run without network or API keys.
+//
+// Fixtures are JSON arrays of model.NewsItem, one file per source and node:
+// <dir>/v2ex/<node>.json, <dir>/hackernews/<list>.json (list names as in the
+// channel config, e.g., top or show), <dir>/rss/<node>.json (the feed's node),
+// <dir>/lobsters/<list>.json (hottest or newest), <dir>/reddit/<subreddit>.json,
+// <dir>/github/<language>.json, <dir>/producthunt/today.json,
+// <dir>/arxiv/<category>.json, <dir>/mastodon/<instance host>.json,
+// <dir>/bluesky/<node>.json (a feed's or search's node),
+// <dir>/stackoverflow/<tag>.json (hot.json for the unfiltered hot list),
+// <dir>/jsonfeed/<label>.json, and <dir>/youtube/<label>.json (the channel or
+// playlist ID when it has no label).
+// V2EX searches match across all V2EX fixtures. Files are read on every call, so
+// edits take effect at the next collector run.
+package mocksource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/v2ex"
+)
+
+// V2EX serves topics from <Dir>/v2ex/<node>.json.
+type V2EX struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewV2EX returns a V2EX source reading fixtures under dir.
+func NewV2EX(dir string) *V2EX { return &V2EX{Dir: dir} }
+
+// TopicsByNode returns the fixture items of node.
+func (m *V2EX) TopicsByNode(ctx context.Context, node string) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "v2ex", fixtureName(node)), "v2ex", m.Now)
+}
+
+// SearchTopics returns the items of every V2EX fixture matching query (see
+// v2ex.MatchesQuery), each once.
+func (m *V2EX) SearchTopics(ctx context.Context, query string) ([]model.NewsItem, error) {
+	files, err := filepath.Glob(filepath.Join(m.Dir, "v2ex", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []model.NewsItem
+	seen := map[string]struct{}{}
+	for _, f := range files {
+		items, err := loadFile(f, "v2ex", m.Now)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			if _, dup := seen[it.ID]; dup || !v2ex.MatchesQuery(it, query) {
+				continue
+			}
+			seen[it.ID] = struct{}{}
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+// TopicDetails returns the Points of the fixture item with topicID as its likes,
+// and no supplements; fixtures carry any in Content.
+func (m *V2EX) TopicDetails(ctx context.Context, topicID string) (v2ex.TopicDetail, error) {
+	files, err := filepath.Glob(filepath.Join(m.Dir, "v2ex", "*.json"))
+	if err != nil {
+		return v2ex.TopicDetail{}, err
+	}
+	for _, f := range files {
+		items, err := loadFile(f, "v2ex", m.Now)
+		if err != nil {
+			return v2ex.TopicDetail{}, err
+		}
+		for _, it := range items {
+			if it.ID == topicID {
+				return v2ex.TopicDetail{Likes: it.Points}, nil
+			}
+		}
+	}
+	return v2ex.TopicDetail{}, nil
+}
+
+// NodeTitle returns an empty title so callers fall back to the node name and
+// nothing is cached for it.
+func (m *V2EX) NodeTitle(ctx context.Context, node string) (string, error) {
+	return "", nil
+}
+
+// RSS serves feed items from <Dir>/rss/<node>.json; the feed URL is ignored, so
+// fixture feeds need a node.
+type RSS struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewRSS returns an RSS source reading fixtures under dir.
+func NewRSS(dir string) *RSS { return &RSS{Dir: dir} }
+
+// Fetch returns the fixture items of node.
+func (m *RSS) Fetch(ctx context.Context, feedURL, node string) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "rss", fixtureName(node)), "rss", m.Now)
+}
+
+// JSONFeed serves feed items from <Dir>/jsonfeed/<label>.json; the feed URL is
+// ignored, so fixture feeds need a label.
+type JSONFeed struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewJSONFeed returns a JSON Feed source reading fixtures under dir.
+func NewJSONFeed(dir string) *JSONFeed { return &JSONFeed{Dir: dir} }
+
+// Fetch returns the fixture items of label.
+func (m *JSONFeed) Fetch(ctx context.Context, feedURL, label string) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "jsonfeed", fixtureName(label)), "jsonfeed", m.Now)
+}
+
+// YouTube serves the videos of a channel or playlist from
+// <Dir>/youtube/<label>.json, or <Dir>/youtube/<id>.json when label is empty.
+type YouTube struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewYouTube returns a YouTube source reading fixtures under dir.
+func NewYouTube(dir string) *YouTube { return &YouTube{Dir: dir} }
+
+// ChannelVideos returns the fixture videos of label, else of channelID.
+func (m *YouTube) ChannelVideos(ctx context.Context, channelID, label string) ([]model.NewsItem, error) {
+	return m.load(channelID, label)
+}
+
+// PlaylistVideos returns the fixture videos of label, else of playlistID.
+func (m *YouTube) PlaylistVideos(ctx context.Context, playlistID, label string) ([]model.NewsItem, error) {
+	return m.load(playlistID, label)
+}
+
+func (m *YouTube) load(id, label string) ([]model.NewsItem, error) {
+	name := label
+	if name == "" {
+		name = id
+	}
+	return loadFile(filepath.Join(m.Dir, "youtube", fixtureName(name)), "youtube", m.Now)
+}
+
+// Lobsters serves stories from <Dir>/lobsters/hottest.json and newest.json.
+type Lobsters struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewLobsters returns a Lobste.rs source reading fixtures under dir.
+func NewLobsters(dir string) *Lobsters { return &Lobsters{Dir: dir} }
+
+// Hottest returns the items of the hottest fixture.
+func (m *Lobsters) Hottest(ctx context.Context) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "lobsters", "hottest.json"), "lobsters", m.Now)
+}
+
+// Newest returns the items of the newest fixture.
+func (m *Lobsters) Newest(ctx context.Context) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "lobsters", "newest.json"), "lobsters", m.Now)
+}
+
+// Reddit serves posts from <Dir>/reddit/<subreddit>.json.
+type Reddit struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewReddit returns a Reddit source reading fixtures under dir.
+func NewReddit(dir string) *Reddit { return &Reddit{Dir: dir} }
+
+// Hot returns the fixture items of subreddit.
+func (m *Reddit) Hot(ctx context.Context, subreddit string) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "reddit", fixtureName(subreddit)), "reddit", m.Now)
+}
+
+// GitHub serves repositories from <Dir>/github/<language>.json.
+type GitHub struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewGitHub returns a GitHub trending source reading fixtures under dir.
+func NewGitHub(dir string) *GitHub { return &GitHub{Dir: dir} }
+
+// Trending returns the fixture items of language; since is ignored, as fixture
+// times are rebased to now.
+func (m *GitHub) Trending(ctx context.Context, language string, since time.Time) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "github", fixtureName(language)), "github", m.Now)
+}
+
+// StackOverflow serves hot questions from <Dir>/stackoverflow/<tag>.json, or
+// hot.json for the untagged list.
+type StackOverflow struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewStackOverflow returns a Stack Overflow source reading fixtures under dir.
+func NewStackOverflow(dir string) *StackOverflow { return &StackOverflow{Dir: dir} }
+
+// Hot returns the fixture items of tag, stored under it; untagged fixture items keep
+// their node.
+func (m *StackOverflow) Hot(ctx context.Context, tag string) ([]model.NewsItem, error) {
+	name := tag
+	if strings.TrimSpace(tag) == "" {
+		name = "hot"
+	}
+	items, err := loadFile(filepath.Join(m.Dir, "stackoverflow", fixtureName(name)), "stackoverflow", m.Now)
+	if strings.TrimSpace(tag) != "" {
+		for i := range items {
+			items[i].NodeName = strings.ToLower(strings.TrimSpace(tag))
+		}
+	}
+	return items, err
+}
+
+// ProductHunt serves launches from <Dir>/producthunt/today.json.
+type ProductHunt struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewProductHunt returns a Product Hunt source reading fixtures under dir.
+func NewProductHunt(dir string) *ProductHunt { return &ProductHunt{Dir: dir} }
+
+// Posts returns the items of the today fixture; since is ignored, as fixture times
+// are rebased to now.
+func (m *ProductHunt) Posts(ctx context.Context, since time.Time) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "producthunt", "today.json"), "producthunt", m.Now)
+}
+
+// Arxiv serves papers from <Dir>/arxiv/<category>.json.
+type Arxiv struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewArxiv returns an arXiv source reading fixtures under dir.
+func NewArxiv(dir string) *Arxiv { return &Arxiv{Dir: dir} }
+
+// Papers returns the fixture items of category.
+func (m *Arxiv) Papers(ctx context.Context, category string) ([]model.NewsItem, error) {
+	return loadFile(filepath.Join(m.Dir, "arxiv", fixtureName(category)), "arxiv", m.Now)
+}
+
+// Mastodon serves one instance's trending links from <Dir>/mastodon/<host>.json.
+type Mastodon struct {
+	Dir      string
+	Instance string           // host, e.g., mastodon.social
+	Now      func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewMastodon returns a Mastodon source for the instance host reading fixtures under dir.
+func NewMastodon(dir, host string) *Mastodon { return &Mastodon{Dir: dir, Instance: host} }
+
+// Host returns the instance host.
+func (m *Mastodon) Host() string { return m.Instance }
+
+// TrendingLinks returns the items of the instance's fixture, stored under its host.
+func (m *Mastodon) TrendingLinks(ctx context.Context) ([]model.NewsItem, error) {
+	items, err := loadFile(filepath.Join(m.Dir, "mastodon", fixtureName(m.Instance)), "mastodon", m.Now)
+	for i := range items {
+		items[i].NodeName = m.Instance
+	}
+	return items, err
+}
+
+// Bluesky serves the posts of a feed or search from <Dir>/bluesky/<node>.json; the
+// feed URI or query is ignored, so fixtures are keyed by the node they are stored
+// under.
+type Bluesky struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewBluesky returns a Bluesky source reading fixtures under dir.
+func NewBluesky(dir string) *Bluesky { return &Bluesky{Dir: dir} }
+
+// Feed returns the fixture items of node.
+func (m *Bluesky) Feed(ctx context.Context, feedURI, node string, limit int) ([]model.NewsItem, error) {
+	return m.load(node, limit)
+}
+
+// SearchPosts returns the fixture items of node.
+func (m *Bluesky) SearchPosts(ctx context.Context, query, node string, limit int) ([]model.NewsItem, error) {
+	return m.load(node, limit)
+}
+
+func (m *Bluesky) load(node string, limit int) ([]model.NewsItem, error) {
+	items, err := loadFile(filepath.Join(m.Dir, "bluesky", fixtureName(node)), "bluesky", m.Now)
+	for i := range items {
+		items[i].NodeName = node
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, err
+}
+
+// HackerNews serves stories from <Dir>/hackernews/<list>.json.
+type HackerNews struct {
+	Dir string
+	Now func() time.Time // clock for rebasing item times; nil uses time.Now
+}
+
+// NewHackerNews returns a Hacker News source reading fixtures under dir.
+func NewHackerNews(dir string) *HackerNews { return &HackerNews{Dir: dir} }
+
+// ListIDs returns the item IDs of a list fixture; list may be an API endpoint
+// such as topstories or a list name such as top.
+func (m *HackerNews) ListIDs(ctx context.Context, list, etag string) (hackernews.ListResult, error) {
+	name := strings.TrimSuffix(list, "stories")
+	items, err := loadFile(filepath.Join(m.Dir, "hackernews", fixtureName(name)), "hackernews", m.Now)
+	if err != nil {
+		return hackernews.ListResult{}, err
+	}
+	ids := make([]int, 0, len(items))
+	for _, it := range items {
+		id, err := strconv.Atoi(it.ID)
+		if err != nil {
+			return hackernews.ListResult{}, fmt.Errorf("mocksource: hackernews/%s: item id %q is not numeric", name, it.ID)
+		}
+		ids = append(ids, id)
+	}
+	return hackernews.ListResult{IDs: ids}, nil
+}
+
+// Items resolves IDs against every Hacker News fixture, preserving order. IDs not
+// found in any fixture are skipped and reported in the error, like a partial fetch.
+func (m *HackerNews) Items(ctx context.Context, ids []int) ([]model.NewsItem, error) {
+	files, err := filepath.Glob(filepath.Join(m.Dir, "hackernews", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]model.NewsItem{}
+	for _, f := range files {
+		items, err := loadFile(f, "hackernews", m.Now)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			byID[it.ID] = it
+		}
+	}
+	out := make([]model.NewsItem, 0, len(ids))
+	missing := 0
+	for _, id := range ids {
+		if it, ok := byID[strconv.Itoa(id)]; ok {
+			out = append(out, it)
+		} else {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return out, fmt.Errorf("mocksource: %d of %d hackernews items not found in fixtures", missing, len(ids))
+	}
+	return out, nil
+}
+
+// TopComment reports no comment; fixtures carry none.
+func (m *HackerNews) TopComment(ctx context.Context, storyID int) (hackernews.Comment, bool, error) {
+	return hackernews.Comment{}, false, nil
+}
+
+// fixtureName maps a node to its file name, keeping it inside the source directory.
+func fixtureName(node string) string {
+	return filepath.Base(strings.ToLower(strings.TrimSpace(node))) + ".json"
+}
+
+// loadFile reads one fixture file. Item times are shifted so the newest item is
+// an hour old, keeping their spacing: collectors score by age and store into the
+// current period, so fixtures stay usable whenever they were captured.
+func loadFile(path, source string, now func() time.Time) ([]model.NewsItem, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("mocksource: no fixture %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []model.NewsItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("mocksource: parse %s: %w", path, err)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	var newest time.Time
+	for _, it := range items {
+		if it.CreatedAt.After(newest) {
+			newest = it.CreatedAt
+		}
+	}
+	shift := now().Add(-time.Hour).Sub(newest)
+	for i := range items {
+		items[i].Source = source
+		if !newest.IsZero() && !items[i].CreatedAt.IsZero() {
+			items[i].CreatedAt = items[i].CreatedAt.Add(shift)
+		}
+	}
+	return items, nil
+}
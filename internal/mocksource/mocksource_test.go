@@ -0,0 +1,81 @@
+package mocksource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestV2EXRebasesTimes(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "v2ex/go.json", `[
+		{"id": "1", "title": "older", "replies": 3, "created_at": "2024-01-01T00:00:00Z"},
+		{"id": "2", "title": "newer", "replies": 5, "created_at": "2024-01-01T05:00:00Z"}
+	]`)
+	now := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	m := &V2EX{Dir: dir, Now: func() time.Time { return now }}
+	items, err := m.TopicsByNode(context.Background(), "Go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Title != "older" {
+		t.Fatalf("items = %+v, want the file order", items)
+	}
+	if want := now.Add(-time.Hour); !items[1].CreatedAt.Equal(want) {
+		t.Errorf("newest created_at = %v, want %v", items[1].CreatedAt, want)
+	}
+	if got := items[1].CreatedAt.Sub(items[0].CreatedAt); got != 5*time.Hour {
+		t.Errorf("spacing = %v, want 5h", got)
+	}
+	if items[0].Source != "v2ex" {
+		t.Errorf("source = %q", items[0].Source)
+	}
+	// Node names never reach outside the source directory.
+	if got, err := m.TopicsByNode(context.Background(), "../../go"); err != nil || len(got) != 2 {
+		t.Errorf("TopicsByNode(../../go) = %d items, %v; want v2ex/go.json", len(got), err)
+	}
+	if _, err := m.TopicsByNode(context.Background(), "rust"); err == nil || !strings.Contains(err.Error(), "no fixture") {
+		t.Errorf("missing node err = %v", err)
+	}
+}
+
+func TestHackerNewsListsAndItems(t *testing.T) {
+	m := NewHackerNews(filepath.Join("..", "..", "fixtures"))
+	ctx := context.Background()
+	res, err := m.ListIDs(ctx, "topstories", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.IDs) == 0 {
+		t.Fatal("bundled top list is empty")
+	}
+	items, err := m.Items(ctx, append(res.IDs, 1))
+	if err == nil {
+		t.Error("unknown id should be reported")
+	}
+	if len(items) != len(res.IDs) {
+		t.Fatalf("resolved %d items, want %d", len(items), len(res.IDs))
+	}
+	for i, it := range items {
+		if it.ID == "" || it.URL == "" || it.Source != "hackernews" || it.Points <= 0 {
+			t.Errorf("item %d incomplete: %+v", i, it)
+		}
+	}
+	if _, err := m.ListIDs(ctx, "beststories", ""); err == nil {
+		t.Error("missing list fixture should fail")
+	}
+}
+
+func writeFixture(t *testing.T, dir, name, body string) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
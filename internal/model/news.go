@@ -4,6 +4,7 @@ import "time"
 
 // NewsItem represents a single news/topic item from a source.
 type NewsItem struct {
+	Source    string    `json:"source,omitempty"` // e.g., v2ex, hackernews; records stored before it existed lack it (see storage)
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
 	URL       string    `json:"url"`
@@ -12,6 +13,10 @@ type NewsItem struct {
 	Points    int       `json:"points"`
 	CreatedAt time.Time `json:"created_at"`
 	Content   string    `json:"content"`
+	Author    string    `json:"author,omitempty"` // V2EX member username or HN "by"; empty for items stored before it was captured
+	// Raw is the item's payload as the source returned it (JSON, or XML for feeds);
+	// it is never stored with the item, only archived under sources.archive_raw.
+	Raw []byte `json:"-"`
 }
 
 // WithScore decorates a news item with a ranking score.
@@ -19,3 +24,30 @@ type WithScore struct {
 	Item  NewsItem
 	Score float64
 }
+
+// SEOMeta is search-engine metadata for a digest.
+type SEOMeta struct {
+	Description string   `json:"description"` // at most 160 characters
+	Keywords    []string `json:"keywords"`
+}
+
+// ItemSummary is an item's AI description with its optional one-line editorial
+// takeaway.
+type ItemSummary struct {
+	Description  string `json:"description"`
+	WhyItMatters string `json:"why_it_matters,omitempty"`
+}
+
+// CommentHighlight is a notable comment shown under an item.
+type CommentHighlight struct {
+	ID     string `json:"id"`
+	Author string `json:"author"`
+	Text   string `json:"text"` // shortened for the digest
+	URL    string `json:"url"`  // permalink to the comment
+}
+
+// Quote is a short pull quote taken from one of a digest's items.
+type Quote struct {
+	Text string   `json:"text"` // at most 140 characters
+	Item NewsItem `json:"item"` // the item the quote comes from
+}
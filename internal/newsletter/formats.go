@@ -0,0 +1,148 @@
+package newsletter
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+)
+
+// Output formats a channel can render.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// formatRenderer renders Data in one format; ext is the file extension including the dot.
+type formatRenderer struct {
+	ext    string
+	render func(Data) ([]byte, error)
+}
+
+// renderers is the format registry; adding a format is one entry here.
+var renderers = map[string]formatRenderer{
+	FormatMarkdown: {ext: ".md", render: func(d Data) ([]byte, error) {
+		s, err := Render(d)
+		return []byte(s), err
+	}},
+	FormatHTML: {ext: ".html", render: renderHTML},
+	FormatJSON: {ext: ".json", render: func(d Data) ([]byte, error) {
+		b, err := json.MarshalIndent(d, "", "  ")
+		return append(b, '\n'), err
+	}},
+}
+
+// FormatExt returns the file extension (with dot) of a known format, or "" otherwise.
+func FormatExt(format string) string {
+	return renderers[format].ext
+}
+
+// Output is one rendered format of a digest.
+type Output struct {
+	Format  string
+	Ext     string
+	Content []byte
+}
+
+// ParseFormats normalizes a format list: lower-cased, trimmed, de-duplicated, with
+// comma-separated entries split. An empty list means markdown only.
+func ParseFormats(list []string) ([]string, error) {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, entry := range list {
+		for _, f := range strings.Split(entry, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if f == "md" {
+				f = FormatMarkdown
+			}
+			if f == "" {
+				continue
+			}
+			if _, ok := renderers[f]; !ok {
+				return nil, fmt.Errorf("unknown output format %q (want markdown, html, or json)", f)
+			}
+			if _, dup := seen[f]; dup {
+				continue
+			}
+			seen[f] = struct{}{}
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		out = []string{FormatMarkdown}
+	}
+	return out, nil
+}
+
+// RenderAll renders d once per format, in the given order. Invalid UTF-8 in the
+// output is replaced so downstream consumers never see broken text.
+func RenderAll(d Data, formats []string) ([]Output, error) {
+	out := make([]Output, 0, len(formats))
+	for _, f := range formats {
+		r, ok := renderers[f]
+		if !ok {
+			return nil, fmt.Errorf("unknown output format %q", f)
+		}
+		b, err := r.render(d)
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", f, err)
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			return nil, fmt.Errorf("render %s: empty output", f)
+		}
+		out = append(out, Output{Format: f, Ext: r.ext, Content: bytes.ToValidUTF8(b, []byte("�"))})
+	}
+	return out, nil
+}
+
+// CheckRender renders a sample digest in each format, so a broken template is
+// reported at startup rather than when a period is built.
+func CheckRender(formats []string) error {
+	sample := Data{
+		Title: "Sample", Slug: "daily-20251024", Datetime: "2025-10-24 08:00",
+		Summary: "Summary.", ShortSummary: "Short summary.", Preface: "Preface.", Postscript: "Postscript.",
+		CoverImageURL: "https://example.com/cover.webp", CoverImageAlt: "Cover",
+		ReadingMinutes: 3, SEODescription: "Description.", Keywords: []string{"sample"},
+		Quote: "Quote.", QuoteSource: QuoteSource{Title: "Item", URL: "https://example.com/1"},
+		PreviousSlug: "daily-20251023", PreviousTitle: "Previous", PreviousURL: "https://example.com/p/daily-20251023",
+		NextSlug: "daily-20251025", NextTitle: "Next", NextURL: "https://example.com/p/daily-20251025",
+		Items: []Item{{Title: "Item", URL: "https://example.com/1", NodeName: "go", NodeURL: "https://example.com/go", Description: "Description.", Replies: 5, Created: "2025-10-24 07:00",
+			Author: "author", ReadingMinutes: 3, Highlight: &Highlight{Text: "Comment.", Author: "commenter", URL: "https://example.com/c"}}},
+	}
+	for _, style := range []string{StyleFull, StyleMinimal} {
+		sample.Style = style
+		if _, err := RenderAll(sample, formats); err != nil {
+			return fmt.Errorf("template check: %w", err)
+		}
+	}
+	return nil
+}
+
+//go:embed newsletter.html.tmpl
+var newsletterHTMLTpl string
+
+var compiledHTML = htmltemplate.Must(htmltemplate.New("newsletter.html").Funcs(htmltemplate.FuncMap{
+	"paragraphs": paragraphs,
+}).Parse(newsletterHTMLTpl))
+
+func renderHTML(d Data) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := compiledHTML.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// paragraphs splits plain text on blank lines for <p> rendering.
+func paragraphs(s string) []string {
+	var out []string
+	for _, p := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package newsletter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ways to handle a digest whose rendered markdown exceeds the size limit.
+const (
+	OversizeTrim  = "trim"  // drop trailing items until it fits
+	OversizeSplit = "split" // divide the items into "Part i/n" digests
+)
+
+// CheckOversize reports an unknown on_oversize value; empty means trim.
+func CheckOversize(v string) error {
+	switch v {
+	case "", OversizeTrim, OversizeSplit:
+		return nil
+	}
+	return fmt.Errorf("on_oversize must be %q or %q, got %q", OversizeTrim, OversizeSplit, v)
+}
+
+// MarkdownSize is the size in bytes of d rendered as markdown, frontmatter included.
+func MarkdownSize(d Data) (int, error) {
+	s, err := Render(d)
+	return len(s), err
+}
+
+// Trim drops trailing items of d until its markdown is at most maxBytes and returns
+// the dropped items. It fails when d is still too large without any item.
+func Trim(d Data, maxBytes int) (Data, []Item, error) {
+	items := d.Items
+	for n := len(items); n >= 0; n-- {
+		d.Items = items[:n]
+		d.ReadingMinutes = TotalReadingMinutes(d.Items)
+		size, err := MarkdownSize(d)
+		if err != nil {
+			return d, nil, err
+		}
+		if size <= maxBytes {
+			return d, items[n:], nil
+		}
+	}
+	return d, nil, fmt.Errorf("digest %s exceeds %d bytes without any item", d.Slug, maxBytes)
+}
+
+// Split divides the items of d, in order and at item boundaries, into as few digests
+// as keep each rendered markdown within maxBytes. A digest that fits is returned as is.
+// Otherwise every part repeats the header (preface, summary, cover) and postscript,
+// its title gets a " (Part i/n)" suffix, and parts after the first get a "-part-i"
+// slug suffix, so the first part keeps the digest's slug. Split fails when a single
+// item does not fit.
+func Split(d Data, maxBytes int) ([]Data, error) {
+	size, err := MarkdownSize(d)
+	if err != nil {
+		return nil, err
+	}
+	if size <= maxBytes || len(d.Items) <= 1 {
+		if size > maxBytes {
+			return nil, fmt.Errorf("digest %s exceeds %d bytes with a single item", d.Slug, maxBytes)
+		}
+		return []Data{d}, nil
+	}
+	// Measure with the widest part suffixes, so renaming parts cannot push them over.
+	probe := d
+	probe.Title, probe.Slug = d.Title+partTitle(99, 99), d.Slug+partSlug(99)
+	var groups [][]Item
+	start := 0
+	for start < len(d.Items) {
+		end := start
+		for end < len(d.Items) {
+			probe.Items = d.Items[start : end+1]
+			size, err := MarkdownSize(probe)
+			if err != nil {
+				return nil, err
+			}
+			if size > maxBytes {
+				break
+			}
+			end++
+		}
+		if end == start {
+			return nil, fmt.Errorf("item %q alone exceeds %d bytes", d.Items[start].Title, maxBytes)
+		}
+		groups = append(groups, d.Items[start:end])
+		start = end
+	}
+	parts := make([]Data, len(groups))
+	for i, items := range groups {
+		p := d
+		p.Items = items
+		p.ReadingMinutes = TotalReadingMinutes(items)
+		p.Title = d.Title + partTitle(i+1, len(groups))
+		if i > 0 {
+			p.Slug = d.Slug + partSlug(i+1)
+		}
+		parts[i] = p
+	}
+	return parts, nil
+}
+
+func partTitle(i, n int) string { return fmt.Sprintf(" (Part %d/%d)", i, n) }
+
+func partSlug(i int) string { return fmt.Sprintf("-part-%d", i) }
+
+// ItemTitles lists the titles of items, for logs.
+func ItemTitles(items []Item) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = strings.TrimSpace(it.Title)
+	}
+	return out
+}
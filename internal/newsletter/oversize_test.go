@@ -0,0 +1,116 @@
+package newsletter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func oversizeData(n int) Data {
+	d := Data{Title: "Weekly", Slug: "weekly-20251024", Datetime: "2025-10-24 08:00", Summary: "Summary.", Postscript: "Bye."}
+	for i := 1; i <= n; i++ {
+		d.Items = append(d.Items, Item{
+			Title:          fmt.Sprintf("Item %d", i),
+			URL:            fmt.Sprintf("https://example.com/%d", i),
+			NodeName:       "go",
+			Description:    strings.Repeat("word ", 40),
+			ReadingMinutes: 2,
+		})
+	}
+	return d
+}
+
+func TestSplitAtItemBoundaries(t *testing.T) {
+	d := oversizeData(10)
+	one, err := MarkdownSize(oversizeData(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := one + 3*400 // room for about four items per part
+	parts, err := Split(d, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+	var titles []string
+	for i, p := range parts {
+		size, err := MarkdownSize(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size > max {
+			t.Errorf("part %d is %d bytes, over %d", i+1, size, max)
+		}
+		if want := fmt.Sprintf("Weekly (Part %d/%d)", i+1, len(parts)); p.Title != want {
+			t.Errorf("part %d title = %q, want %q", i+1, p.Title, want)
+		}
+		wantSlug := "weekly-20251024"
+		if i > 0 {
+			wantSlug += fmt.Sprintf("-part-%d", i+1)
+		}
+		if p.Slug != wantSlug {
+			t.Errorf("part %d slug = %q, want %q", i+1, p.Slug, wantSlug)
+		}
+		if p.ReadingMinutes != 2*len(p.Items) {
+			t.Errorf("part %d reading minutes = %d for %d items", i+1, p.ReadingMinutes, len(p.Items))
+		}
+		titles = append(titles, ItemTitles(p.Items)...)
+	}
+	if got := strings.Join(titles, ","); got != strings.Join(ItemTitles(d.Items), ",") {
+		t.Errorf("items across parts = %s", got)
+	}
+}
+
+func TestSplitFittingDigestUnchanged(t *testing.T) {
+	d := oversizeData(3)
+	parts, err := Split(d, 1<<20)
+	if err != nil || len(parts) != 1 || parts[0].Title != "Weekly" || parts[0].Slug != d.Slug {
+		t.Fatalf("Split = %+v, %v", parts, err)
+	}
+}
+
+func TestSplitItemTooLarge(t *testing.T) {
+	d := oversizeData(2)
+	d.Items[1].Description = strings.Repeat("x", 10000)
+	if _, err := Split(d, 5000); err == nil || !strings.Contains(err.Error(), "Item 2") {
+		t.Errorf("err = %v, want the oversized item named", err)
+	}
+}
+
+func TestTrimDropsTrailingItems(t *testing.T) {
+	d := oversizeData(10)
+	size, err := MarkdownSize(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := size - 500
+	trimmed, dropped, err := Trim(d, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dropped) == 0 || len(trimmed.Items)+len(dropped) != 10 || dropped[0].Title != fmt.Sprintf("Item %d", len(trimmed.Items)+1) {
+		t.Fatalf("kept %d, dropped %v", len(trimmed.Items), ItemTitles(dropped))
+	}
+	if got, _ := MarkdownSize(trimmed); got > max {
+		t.Errorf("trimmed digest is %d bytes, over %d", got, max)
+	}
+	if trimmed.ReadingMinutes != 2*len(trimmed.Items) {
+		t.Errorf("reading minutes = %d", trimmed.ReadingMinutes)
+	}
+	if _, _, err := Trim(d, 10); err == nil {
+		t.Error("Trim fit a digest into 10 bytes")
+	}
+}
+
+func TestCheckOversize(t *testing.T) {
+	for _, v := range []string{"", "trim", "split"} {
+		if err := CheckOversize(v); err != nil {
+			t.Errorf("%q: %v", v, err)
+		}
+	}
+	if err := CheckOversize("drop"); err == nil {
+		t.Error("drop accepted")
+	}
+}
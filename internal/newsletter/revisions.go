@@ -0,0 +1,60 @@
+package newsletter
+
+import (
+	"strings"
+	"time"
+)
+
+// Revision records one regeneration of a published digest.
+type Revision struct {
+	At     string `json:"at"` // RFC 3339, UTC
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewRevision returns the revision of a regeneration at t.
+func NewRevision(t time.Time, reason string) Revision {
+	return Revision{At: t.UTC().Format(time.RFC3339), Reason: strings.TrimSpace(reason)}
+}
+
+// UpdatedDate is the day (YYYY-MM-DD) of the latest revision, shown as "Updated
+// <date>" in the footer; empty without revisions.
+func (d Data) UpdatedDate() string {
+	if len(d.Revisions) == 0 {
+		return ""
+	}
+	at := d.Revisions[len(d.Revisions)-1].At
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t.UTC().Format("2006-01-02")
+	}
+	if len(at) >= 10 {
+		return at[:10]
+	}
+	return at
+}
+
+// ParseRevisions reads the revisions list of a parsed digest's frontmatter, so a
+// regeneration appends to it. Entries without a time are dropped; a hand-written
+// unquoted timestamp, which YAML parses as a time, is accepted.
+func ParseRevisions(fm map[string]any) []Revision {
+	list, _ := fm["revisions"].([]any)
+	var out []Revision
+	for _, e := range list {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		var r Revision
+		switch at := m["at"].(type) {
+		case string:
+			r.At = strings.TrimSpace(at)
+		case time.Time:
+			r.At = at.UTC().Format(time.RFC3339)
+		}
+		if r.At == "" {
+			continue
+		}
+		r.Reason, _ = m["reason"].(string)
+		out = append(out, r)
+	}
+	return out
+}
@@ -3,39 +3,182 @@ package newsletter
 import (
 	"bytes"
 	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"text/template"
 )
 
 type Item struct {
-	Title       string
-	URL         string
-	NodeName    string
-	NodeURL     string
-	Description string
-	Replies     int
-	Created     string
+	// ID is the source item's ID. It is not rendered; it lets callers tell which
+	// items survived Trim or Split.
+	ID          string `json:"-"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	NodeName    string `json:"node_name"`
+	NodeURL     string `json:"node_url"`
+	Description string `json:"description"`
+	// WhyItMatters is an optional one-line editorial takeaway rendered in italics
+	// under the description.
+	WhyItMatters string `json:"why_it_matters,omitempty"`
+	Replies      int    `json:"replies"`
+	Points       int    `json:"points,omitempty"` // rendered when non-zero
+	Created      string `json:"created"`
+	Author       string `json:"author,omitempty"` // set only when the channel enables show_author
+	// ReadingMinutes estimates the linked article's reading time; 0 when no content was available.
+	ReadingMinutes int `json:"reading_minutes,omitempty"`
+	// Label is an optional marker rendered under the title, e.g., "Editor's pick".
+	Label string `json:"label,omitempty"`
+	// Highlight is an optional community comment quoted under the item.
+	Highlight *Highlight `json:"highlight,omitempty"`
+}
+
+// Highlight is a comment quoted under an item, linked to its permalink.
+type Highlight struct {
+	Text   string `json:"text"`
+	Author string `json:"author"`
+	URL    string `json:"url"`
 }
 
 type Data struct {
-	Title         string
-	Slug          string
-	Datetime      string
-	Summary       string
-	ShortSummary  string
-	Preface       string
-	Postscript    string
-	CoverImageURL string
-	Items         []Item
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Datetime string `json:"datetime"`
+	// Summary is the AI-written overview of the issue, possibly several
+	// paragraphs; it opens the body, after the preface and pull quote.
+	Summary string `json:"summary"`
+	// ShortSummary is the one- or two-sentence teaser that becomes the
+	// frontmatter summary (Quaily's post excerpt) and the HTML meta description.
+	// Both summaries are optional and omitted from the output when empty.
+	ShortSummary  string `json:"short_summary"`
+	Preface       string `json:"preface,omitempty"`
+	Postscript    string `json:"postscript,omitempty"`
+	CoverImageURL string `json:"cover_image_url,omitempty"`
+	CoverImageAlt string `json:"cover_image_alt,omitempty"`
+	Items         []Item `json:"items"`
+	// ReadingMinutes is the sum of the items' reading times.
+	ReadingMinutes int `json:"reading_minutes,omitempty"`
+	// SEODescription and Keywords become the seo_description and keywords
+	// frontmatter keys when set (channel option frontmatter.seo).
+	SEODescription string   `json:"seo_description,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+	// Quote is an optional pull quote rendered between the preface and the
+	// summary; it is only shown when QuoteSource links to the quoted item.
+	Quote       string      `json:"quote,omitempty"`
+	QuoteSource QuoteSource `json:"quote_source,omitempty"`
+	// Style selects the Markdown template (StyleFull or StyleMinimal); empty is full.
+	Style string `json:"-"`
+	// PreviousSlug, PreviousTitle, and PreviousURL describe the channel's digest of
+	// the period before; the Next fields the one after, known only when an older
+	// period is regenerated. The footer links each one with a URL (channel option
+	// navigation).
+	PreviousSlug  string `json:"previous_slug,omitempty"`
+	PreviousTitle string `json:"previous_title,omitempty"`
+	PreviousURL   string `json:"previous_url,omitempty"`
+	NextSlug      string `json:"next_slug,omitempty"`
+	NextTitle     string `json:"next_title,omitempty"`
+	NextURL       string `json:"next_url,omitempty"`
+	// Frequency (daily or weekly) words the navigation links.
+	Frequency string `json:"-"`
+	// Revisions lists the regenerations of the digest, oldest first; when set they
+	// go into the frontmatter and the footer notes the latest one.
+	Revisions []Revision `json:"revisions,omitempty"`
+}
+
+// PreviousLabel is the text of the link to the previous digest.
+func (d Data) PreviousLabel() string {
+	if d.Frequency == "weekly" {
+		return "Last week's digest"
+	}
+	return "Yesterday's digest"
+}
+
+// NextLabel is the text of the link to the next digest.
+func (d Data) NextLabel() string {
+	if d.Frequency == "weekly" {
+		return "Next week's digest"
+	}
+	return "Tomorrow's digest"
+}
+
+// Digest styles a channel can choose.
+const (
+	// StyleFull renders the summary, pull quote, and each item's description and metadata.
+	StyleFull = "full"
+	// StyleMinimal renders a numbered list of links under a one-line preface,
+	// leaving out the summary and descriptions; channels using it need no AI.
+	StyleMinimal = "minimal"
+)
+
+// CheckStyle reports an unknown style; empty is accepted as full.
+func CheckStyle(style string) error {
+	switch style {
+	case "", StyleFull, StyleMinimal:
+		return nil
+	}
+	return fmt.Errorf("unknown style %q (want full or minimal)", style)
+}
+
+// QuoteSource is the item a pull quote comes from.
+type QuoteSource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// TotalReadingMinutes sums the reading time of items.
+func TotalReadingMinutes(items []Item) int {
+	n := 0
+	for _, it := range items {
+		n += it.ReadingMinutes
+	}
+	return n
+}
+
+// FallbackSummary is the post summary used when AI is not configured or returns
+// nothing: the titles of the first three items. It is empty when there are no items.
+func FallbackSummary(items []Item) string {
+	titles := make([]string, 0, 3)
+	for _, it := range items {
+		if len(titles) == 3 {
+			break
+		}
+		if t := strings.TrimSpace(it.Title); t != "" {
+			titles = append(titles, t)
+		}
+	}
+	if len(titles) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Top highlights: %s.", strings.Join(titles, ", "))
 }
 
 //go:embed newsletter.tmpl
 var newsletterTpl string
 
-var compiled = template.Must(template.New("newsletter").Parse(newsletterTpl))
+var compiled = template.Must(template.New("newsletter").Funcs(template.FuncMap{"yaml": yamlValue}).Parse(newsletterTpl))
+
+//go:embed newsletter.minimal.tmpl
+var minimalTpl string
+
+var compiledMinimal = template.Must(template.New("newsletter.minimal").Funcs(template.FuncMap{
+	"yaml": yamlValue,
+	"inc":  func(i int) int { return i + 1 },
+}).Parse(minimalTpl))
+
+// yamlValue renders v as an inline YAML value. JSON is a subset of YAML, so
+// quotes, colons, and newlines in AI-written text cannot break the frontmatter.
+func yamlValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
 
 func Render(d Data) (string, error) {
+	t := compiled
+	if d.Style == StyleMinimal {
+		t = compiledMinimal
+	}
 	var buf bytes.Buffer
-	if err := compiled.Execute(&buf, d); err != nil {
+	if err := t.Execute(&buf, d); err != nil {
 		return "", err
 	}
 	return buf.String(), nil
@@ -0,0 +1,398 @@
+package newsletter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderAuthor(t *testing.T) {
+	base := Item{Title: "T", URL: "https://example.com", NodeName: "go", NodeURL: "https://v2ex.com/go/go", Replies: 3, Created: "2025-01-02 03:04"}
+
+	withAuthor := base
+	withAuthor.Author = "Livid"
+	out, err := Render(Data{Title: "D", Items: []Item{withAuthor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "*3 Replies - [@go](https://v2ex.com/go/go) - by Livid - 2025-01-02 03:04*"; !strings.Contains(out, want) {
+		t.Errorf("missing author metadata line %q in:\n%s", want, out)
+	}
+
+	out, err = Render(Data{Title: "D", Items: []Item{base}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "*3 Replies - [@go](https://v2ex.com/go/go) - 2025-01-02 03:04*"; !strings.Contains(out, want) {
+		t.Errorf("missing metadata line %q in:\n%s", want, out)
+	}
+
+	withPoints := base
+	withPoints.Points = 37
+	out, err = Render(Data{Title: "D", Items: []Item{withPoints}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "*3 Replies - 37 Points - [@go](https://v2ex.com/go/go) - 2025-01-02 03:04*"; !strings.Contains(out, want) {
+		t.Errorf("missing points metadata line %q in:\n%s", want, out)
+	}
+}
+
+func TestRenderAllFormats(t *testing.T) {
+	d := Data{
+		Title:   "Daily <Digest>",
+		Slug:    "daily-20251024",
+		Summary: "First paragraph.\n\nSecond & last.",
+		Items:   []Item{{Title: "A <b>bold</b> claim", URL: "https://example.com/a?x=1&y=2", NodeName: "go", Replies: 2}},
+	}
+	formats, err := ParseFormats([]string{"md,html", "json", "HTML"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(formats, ",") != "markdown,html,json" {
+		t.Fatalf("ParseFormats = %v", formats)
+	}
+	outs, err := RenderAll(d, formats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outs) != 3 || outs[0].Ext != ".md" || outs[1].Ext != ".html" || outs[2].Ext != ".json" {
+		t.Fatalf("outputs = %+v", outs)
+	}
+	html := string(outs[1].Content)
+	for _, want := range []string{"<title>Daily &lt;Digest&gt;</title>", "<p>Second &amp; last.</p>", `href="https://example.com/a?x=1&amp;y=2"`, "A &lt;b&gt;bold&lt;/b&gt; claim"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %q:\n%s", want, html)
+		}
+	}
+	var back Data
+	if err := json.Unmarshal(outs[2].Content, &back); err != nil || back.Items[0].URL != d.Items[0].URL {
+		t.Errorf("json round trip = %+v, %v", back, err)
+	}
+
+	if _, err := ParseFormats([]string{"pdf"}); err == nil {
+		t.Error("ParseFormats(pdf) = nil error")
+	}
+	if f, _ := ParseFormats(nil); len(f) != 1 || f[0] != FormatMarkdown {
+		t.Errorf("ParseFormats(nil) = %v, want [markdown]", f)
+	}
+}
+
+func TestRenderReadingTime(t *testing.T) {
+	items := []Item{
+		{Title: "Essay", URL: "https://example.com/e", NodeName: "go", Replies: 1, Created: "2025-01-02 03:04", ReadingMinutes: 32},
+		{Title: "Link only", URL: "https://example.com/l", NodeName: "go", Replies: 1, Created: "2025-01-02 03:04"},
+		{Title: "Post", URL: "https://example.com/p", NodeName: "go", Replies: 1, Created: "2025-01-02 03:04", ReadingMinutes: 3},
+	}
+	out, err := Render(Data{Title: "D", Preface: "Hello", Items: items, ReadingMinutes: TotalReadingMinutes(items)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"*≈35 min of reading*", "- 2025-01-02 03:04 - ~32 min*", "- 2025-01-02 03:04 - ~3 min*", "- 2025-01-02 03:04*"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderSEOFrontmatter(t *testing.T) {
+	out, err := Render(Data{Title: "D", SEODescription: `Go 1.22: "range" over ints`, Keywords: []string{"go", "release"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := strings.SplitN(out, "---", 3)[1]
+	var meta struct {
+		SEODescription string   `yaml:"seo_description"`
+		Keywords       []string `yaml:"keywords"`
+	}
+	if err := yaml.Unmarshal([]byte(fm), &meta); err != nil {
+		t.Fatalf("frontmatter is not valid YAML: %v\n%s", err, fm)
+	}
+	if meta.SEODescription != `Go 1.22: "range" over ints` || strings.Join(meta.Keywords, ",") != "go,release" {
+		t.Errorf("frontmatter = %+v", meta)
+	}
+
+	out, err = Render(Data{Title: "D"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "seo_description") || strings.Contains(out, "keywords:") {
+		t.Errorf("SEO keys rendered without metadata:\n%s", out)
+	}
+}
+
+func TestRenderPullQuote(t *testing.T) {
+	d := Data{Title: "D", Preface: "Hello.", Summary: "Summary.", Quote: "Ship it on Friday.", QuoteSource: QuoteSource{Title: "Deploys", URL: "https://example.com/d"}}
+	out, err := Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "> “Ship it on Friday.”\n>\n> — [Deploys](https://example.com/d)"
+	i, p, s := strings.Index(out, want), strings.Index(out, "> Hello."), strings.Index(out, "Summary.")
+	if i < 0 || !(p < i && i < s) {
+		t.Errorf("pull quote missing or misplaced:\n%s", out)
+	}
+
+	// A quote without a source link is dropped.
+	d.QuoteSource = QuoteSource{}
+	if out, _ := Render(d); strings.Contains(out, "Ship it") {
+		t.Errorf("unlinked quote rendered:\n%s", out)
+	}
+}
+
+func TestRenderCoverAlt(t *testing.T) {
+	out, err := Render(Data{Title: "D", CoverImageURL: "https://cdn.example.com/c.webp", CoverImageAlt: `A "quoted": scene`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `cover_image_alt: "A \"quoted\": scene"`) {
+		t.Errorf("missing cover_image_alt in:\n%s", out)
+	}
+	// Alt text without a cover is not rendered.
+	if out, _ := Render(Data{Title: "D", CoverImageAlt: "x"}); strings.Contains(out, "cover_image_alt") {
+		t.Errorf("alt rendered without cover:\n%s", out)
+	}
+}
+
+func TestRenderHighlight(t *testing.T) {
+	it := Item{Title: "T", URL: "https://example.com", NodeName: "story", NodeURL: "https://news.ycombinator.com", Replies: 3, Created: "2025-01-02 03:04",
+		Highlight: &Highlight{Text: "Worth <reading>.", Author: "pg", URL: "https://news.ycombinator.com/item?id=4"}}
+	d := Data{Title: "D", Items: []Item{it, {Title: "Plain", URL: "https://example.com/p"}}}
+	out, err := Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2025-01-02 03:04*\n\n> Community highlight: Worth <reading>.\n>\n> — [pg](https://news.ycombinator.com/item?id=4)\n"
+	if !strings.Contains(out, want) || strings.Count(out, "Community highlight") != 1 {
+		t.Errorf("highlight missing or repeated:\n%s", out)
+	}
+	html, err := renderHTML(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), "<strong>Community highlight:</strong> Worth &lt;reading&gt;.") {
+		t.Errorf("html highlight not escaped:\n%s", html)
+	}
+}
+
+func TestFallbackSummary(t *testing.T) {
+	items := []Item{{Title: "A"}, {Title: " "}, {Title: "B"}, {Title: "C"}, {Title: "D"}}
+	if got := FallbackSummary(items); got != "Top highlights: A, B, C." {
+		t.Errorf("FallbackSummary = %q", got)
+	}
+	if got := FallbackSummary(nil); got != "" {
+		t.Errorf("FallbackSummary(nil) = %q", got)
+	}
+
+	// An empty summary omits the key instead of writing an empty scalar.
+	out, err := Render(Data{Title: "D", Slug: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "summary:") {
+		t.Errorf("empty summary rendered:\n%s", out)
+	}
+	out, err = Render(Data{Title: "D", Slug: "s", ShortSummary: FallbackSummary(items)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := strings.SplitN(out, "---", 3)[1]
+	var meta struct {
+		Summary string `yaml:"summary"`
+	}
+	if err := yaml.Unmarshal([]byte(fm), &meta); err != nil || meta.Summary != "Top highlights: A, B, C." {
+		t.Errorf("summary = %q (%v) in:\n%s", meta.Summary, err, fm)
+	}
+}
+
+// TestSummaryPlacement locks where the two summaries go: ShortSummary into the
+// frontmatter and meta description, Summary at the top of the body.
+func TestSummaryPlacement(t *testing.T) {
+	d := Data{
+		Title:        "D",
+		Slug:         "s",
+		Preface:      "Hello.",
+		Summary:      "The long summary.\n\nIts second paragraph.",
+		ShortSummary: "A teaser: with a colon\nand a second line.",
+		Items:        []Item{{Title: "First item", URL: "https://example.com/1"}},
+	}
+	out, err := Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.SplitN(out, "---", 3)
+	var meta struct {
+		Summary string `yaml:"summary"`
+	}
+	if err := yaml.Unmarshal([]byte(parts[1]), &meta); err != nil || meta.Summary != d.ShortSummary {
+		t.Errorf("frontmatter summary = %q (%v) in:\n%s", meta.Summary, err, parts[1])
+	}
+	body := parts[2]
+	if strings.Contains(body, "A teaser") {
+		t.Errorf("short summary leaked into the body:\n%s", body)
+	}
+	p, s, i := strings.Index(body, "> Hello."), strings.Index(body, d.Summary), strings.Index(body, "## [First item]")
+	if p < 0 || s < 0 || i < 0 || !(p < s && s < i) {
+		t.Errorf("want preface, summary, then items; got offsets %d, %d, %d in:\n%s", p, s, i, body)
+	}
+
+	b, err := renderHTML(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(b)
+	for _, want := range []string{`<meta name="description" content="A teaser: with a colon`, "<p>The long summary.</p>\n<p>Its second paragraph.</p>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %q:\n%s", want, html)
+		}
+	}
+
+	// Both are optional: without them there is no key and nothing before the items.
+	out, err = Render(Data{Title: "D", Slug: "s", Items: d.Items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts = strings.SplitN(out, "---", 3)
+	if strings.Contains(parts[1], "summary:") || !strings.HasPrefix(strings.TrimSpace(parts[2]), "## [First item]") {
+		t.Errorf("empty summaries rendered:\n%s", out)
+	}
+}
+
+// The minimal style lists links only: no summary, quote, or descriptions, but the
+// frontmatter Quaily publishes with is the same.
+func TestRenderMinimal(t *testing.T) {
+	d := Data{
+		Title: "D", Slug: "daily-20251024", Datetime: "2025-10-24 08:00", Style: StyleMinimal,
+		Preface: "Today's links.", Summary: "The long summary.", ShortSummary: "Top highlights: A.",
+		Quote: "Quote.", QuoteSource: QuoteSource{Title: "A", URL: "https://example.com/a"},
+		Items: []Item{
+			{Title: "A", URL: "https://example.com/a", NodeName: "go", NodeURL: "https://v2ex.com/go/go", Replies: 3, Description: "About A."},
+			{Title: "B", URL: "https://example.com/b", NodeName: "rust", Replies: 1, Points: 20, Label: "Editor's pick"},
+		},
+	}
+	out, err := Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.SplitN(out, "---", 3)
+	if !strings.Contains(parts[1], `summary: "Top highlights: A."`) || !strings.Contains(parts[1], "slug: daily-20251024") {
+		t.Errorf("frontmatter:\n%s", parts[1])
+	}
+	want := "\n\nToday's links.\n\n" +
+		"1. [A](https://example.com/a) · 3 replies · [@go](https://v2ex.com/go/go)\n" +
+		"2. [B](https://example.com/b) · 1 replies · 20 points · @rust · *Editor's pick*\n"
+	if parts[2] != want {
+		t.Errorf("body =\n%q\nwant\n%q", parts[2], want)
+	}
+	if err := CheckStyle("compact"); err == nil {
+		t.Error("unknown style accepted")
+	}
+}
+
+func TestCheckRender(t *testing.T) {
+	if err := CheckRender([]string{FormatMarkdown, FormatHTML, FormatJSON}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckRender([]string{"pdf"}); err == nil {
+		t.Error("CheckRender accepted an unknown format")
+	}
+}
+
+func TestRenderNavigation(t *testing.T) {
+	items := []Item{{Title: "A", URL: "https://example.com/a", NodeName: "go", Replies: 3}}
+	first := Data{Title: "D", Items: items, Postscript: "Bye."}
+	out, err := Render(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out, "> Bye.\n\n") || strings.Contains(out, "digest](") {
+		t.Errorf("digest without neighbours ends:\n%q", out[len(out)-40:])
+	}
+
+	d := first
+	d.PreviousSlug, d.PreviousTitle, d.PreviousURL = "daily-20251023", `Say "hi"`, "https://quaily.com/ch/p/daily-20251023"
+	out, err = Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "> Bye.\n\n[← Yesterday's digest](https://quaily.com/ch/p/daily-20251023)\n"; !strings.HasSuffix(out, want) {
+		t.Errorf("footer = %q, want suffix %q", out[len(out)-80:], want)
+	}
+
+	d.Frequency, d.NextURL = "weekly", "https://quaily.com/ch/p/weekly-20251031"
+	out, err = Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nav := "[← Last week's digest](https://quaily.com/ch/p/daily-20251023) · [Next week's digest →](https://quaily.com/ch/p/weekly-20251031)\n"
+	if !strings.HasSuffix(out, nav) {
+		t.Errorf("footer = %q, want suffix %q", out[len(out)-120:], nav)
+	}
+	d.Style = StyleMinimal
+	if out, err := Render(d); err != nil || !strings.HasSuffix(out, "Bye.\n\n"+nav) {
+		t.Errorf("minimal footer = %q, %v", out, err)
+	}
+	outs, err := RenderAll(d, []string{FormatHTML})
+	if err != nil || !strings.Contains(string(outs[0].Content), `<a rel="prev" href="https://quaily.com/ch/p/daily-20251023" title="Say &#34;hi&#34;">← Last week&#39;s digest</a>`) {
+		t.Errorf("html nav:\n%s, %v", outs[0].Content, err)
+	}
+}
+
+func TestRenderRevisions(t *testing.T) {
+	d := Data{Title: "D", Revisions: []Revision{
+		{At: "2026-03-10T08:00:00Z", Reason: `fixed "typo"`},
+		NewRevision(time.Date(2026, 3, 11, 23, 30, 0, 0, time.FixedZone("", -3*3600)), " "),
+	}}
+	outs, err := RenderAll(d, []string{FormatMarkdown, FormatHTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(outs[0].Content)
+	var meta map[string]any
+	if err := yaml.Unmarshal([]byte(strings.SplitN(md, "---", 3)[1]), &meta); err != nil {
+		t.Fatalf("frontmatter is not valid YAML: %v\n%s", err, md)
+	}
+	if got := ParseRevisions(meta); len(got) != 2 || got[0] != d.Revisions[0] || got[1] != (Revision{At: "2026-03-12T02:30:00Z"}) {
+		t.Errorf("revisions round trip = %+v", got)
+	}
+	if !strings.Contains(md, "*Updated 2026-03-12*") || !strings.Contains(string(outs[1].Content), "Updated 2026-03-12") {
+		t.Errorf("footer note missing:\n%s", md)
+	}
+
+	// Unquoted hand-written timestamps parse as times.
+	if got := ParseRevisions(map[string]any{"revisions": []any{map[string]any{"at": time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, "junk"}}); len(got) != 1 || got[0].At != "2026-01-02T03:04:05Z" {
+		t.Errorf("ParseRevisions(time) = %+v", got)
+	}
+
+	out, err := Render(Data{Title: "D"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "revisions:") || strings.Contains(out, "Updated") {
+		t.Errorf("revisions rendered without any:\n%s", out)
+	}
+}
+
+func TestRenderWhyItMatters(t *testing.T) {
+	d := Data{Title: "D", Items: []Item{
+		{Title: "A", URL: "https://a.example", NodeName: "n", Description: "Neutral summary.", WhyItMatters: "Your builds get faster."},
+		{Title: "B", URL: "https://b.example", NodeName: "n", Description: "Another summary."},
+	}}
+	outs, err := RenderAll(d, []string{FormatMarkdown, FormatHTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, html := string(outs[0].Content), string(outs[1].Content)
+	if !strings.Contains(md, "Neutral summary.\n\n*Your builds get faster.*\n\n*0 Replies") {
+		t.Errorf("markdown takeaway not in italics under the description:\n%s", md)
+	}
+	if !strings.Contains(html, `<p class="why-it-matters"><em>Your builds get faster.</em></p>`) {
+		t.Errorf("html takeaway missing:\n%s", html)
+	}
+	if strings.Count(md, "*Your builds") != 1 || strings.Count(html, "why-it-matters") != 1 {
+		t.Errorf("takeaway rendered for an item without one:\n%s", md)
+	}
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Chat platforms a digest summary can be posted to through an incoming webhook.
+const (
+	PlatformDiscord = "discord"
+	PlatformSlack   = "slack"
+)
+
+// DigestTopItems is how many items a chat summary lists.
+const DigestTopItems = 5
+
+// Platform limits, in characters.
+const (
+	discordDescriptionLimit = 4096 // embed description
+	discordTitleLimit       = 256  // embed title
+	slackTextLimit          = 4000 // message text; Slack truncates far longer texts
+)
+
+// Digest is the chat summary of a published digest.
+type Digest struct {
+	Title   string
+	Summary string
+	Items   []DigestItem // only the first DigestTopItems are posted
+}
+
+// DigestItem is one linked item of a Digest.
+type DigestItem struct {
+	Title string
+	URL   string
+}
+
+// ChatWebhook posts digest summaries to a Discord or Slack incoming webhook.
+type ChatWebhook struct {
+	Platform string // PlatformDiscord or PlatformSlack
+	URL      string
+	HTTP     *http.Client
+}
+
+// Payloads returns the webhook bodies for d, in posting order; text that exceeds
+// the platform limit is split between lines, or between words within a line.
+func (c *ChatWebhook) Payloads(d Digest) ([]any, error) {
+	switch c.Platform {
+	case PlatformDiscord:
+		return DiscordPayloads(d), nil
+	case PlatformSlack:
+		return SlackPayloads(d), nil
+	}
+	return nil, fmt.Errorf("unknown chat platform %q", c.Platform)
+}
+
+// Post sends one payload returned by Payloads; any non-2xx response is an error.
+func (c *ChatWebhook) Post(ctx context.Context, payload any) error {
+	return postJSON(ctx, c.HTTP, c.URL, payload)
+}
+
+// discordSpecial are the characters Discord markdown treats as formatting.
+const discordSpecial = "\\*_~`|>#-[]()"
+
+// EscapeDiscord escapes s for use as plain text in Discord markdown.
+func EscapeDiscord(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 128 && strings.ContainsRune(discordSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EscapeSlack escapes s for use as plain text in Slack mrkdwn, where only
+// "&", "<" and ">" are control characters.
+func EscapeSlack(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+type discordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+}
+
+type discordPayload struct {
+	Embeds          []discordEmbed `json:"embeds"`
+	AllowedMentions struct {
+		Parse []string `json:"parse"`
+	} `json:"allowed_mentions"` // empty: digest text never pings anyone
+}
+
+// DiscordPayloads renders d as embeds: the first carries the title, and the
+// summary and numbered item links fill the descriptions.
+func DiscordPayloads(d Digest) []any {
+	lines := []string{}
+	if s := strings.TrimSpace(d.Summary); s != "" {
+		lines = append(lines, EscapeDiscord(s), "")
+	}
+	for i, it := range topItems(d.Items) {
+		u := strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(it.URL)
+		lines = append(lines, strconv.Itoa(i+1)+"\\. ["+EscapeDiscord(it.Title)+"]("+u+")")
+	}
+	var out []any
+	for i, desc := range pack(lines, discordDescriptionLimit) {
+		p := discordPayload{Embeds: []discordEmbed{{Description: desc}}}
+		p.AllowedMentions.Parse = []string{}
+		if i == 0 {
+			p.Embeds[0].Title = truncate(strings.TrimSpace(d.Title), discordTitleLimit)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// SlackPayloads renders d as mrkdwn messages: a bold title, the summary, and a
+// bulleted list of <url|title> links.
+func SlackPayloads(d Digest) []any {
+	lines := []string{}
+	if t := strings.TrimSpace(d.Title); t != "" {
+		lines = append(lines, "*"+EscapeSlack(t)+"*")
+	}
+	if s := strings.TrimSpace(d.Summary); s != "" {
+		lines = append(lines, EscapeSlack(s), "")
+	}
+	for _, it := range topItems(d.Items) {
+		u := strings.NewReplacer("|", "%7C", "<", "%3C", ">", "%3E", " ", "%20").Replace(it.URL)
+		lines = append(lines, "• <"+u+"|"+EscapeSlack(it.Title)+">")
+	}
+	var out []any
+	for _, text := range pack(lines, slackTextLimit) {
+		out = append(out, slackPayload{Text: text})
+	}
+	return out
+}
+
+func topItems(items []DigestItem) []DigestItem {
+	return items[:min(len(items), DigestTopItems)]
+}
+
+// pack joins lines into as few texts of at most limit characters as possible;
+// a single line over the limit is split between words.
+func pack(lines []string, limit int) []string {
+	var split []string
+	for _, l := range lines {
+		split = append(split, splitLine(l, limit)...)
+	}
+	var out []string
+	cur := ""
+	for _, l := range split {
+		switch {
+		case cur == "":
+			cur = l
+		case utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(l) <= limit:
+			cur += "\n" + l
+		default:
+			out = append(out, strings.TrimSpace(cur))
+			cur = l
+		}
+	}
+	if strings.TrimSpace(cur) != "" {
+		out = append(out, strings.TrimSpace(cur))
+	}
+	return out
+}
+
+// splitLine cuts l into pieces of at most limit characters, at the last space
+// before the limit when there is one.
+func splitLine(l string, limit int) []string {
+	var out []string
+	for utf8.RuneCountInString(l) > limit {
+		r := []rune(l)
+		cut := limit
+		if i := strings.LastIndex(string(r[:limit]), " "); i > 0 {
+			cut = utf8.RuneCountInString(string(r[:limit])[:i])
+		}
+		out = append(out, strings.TrimSpace(string(r[:cut])))
+		l = strings.TrimSpace(string(r[cut:]))
+	}
+	return append(out, l)
+}
+
+// truncate shortens s to at most n characters, ending it with "…" when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
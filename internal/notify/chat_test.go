@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeDiscord(t *testing.T) {
+	got := EscapeDiscord(`**bold** _it_ ~x~ a|b > q #h - l [t](u) ` + "`code`" + ` \ 你好`)
+	want := `\*\*bold\*\* \_it\_ \~x\~ a\|b \> q \#h \- l \[t\]\(u\) ` + "\\`code\\`" + ` \\ 你好`
+	if got != want {
+		t.Errorf("EscapeDiscord = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeSlack(t *testing.T) {
+	got := EscapeSlack(`<@U123> & <!channel> *stays*`)
+	want := `&lt;@U123&gt; &amp; &lt;!channel&gt; *stays*`
+	if got != want {
+		t.Errorf("EscapeSlack = %q, want %q", got, want)
+	}
+}
+
+func testDigest() Digest {
+	d := Digest{Title: "Daily <Go> *digest*", Summary: "Rust & [Go] news"}
+	for _, title := range []string{"One_1", "Two|2", "Three>3", "Four", "Five", "Six"} {
+		d.Items = append(d.Items, DigestItem{Title: title, URL: "https://example.com/a_(b)?x=1&y=2|z"})
+	}
+	return d
+}
+
+func TestDiscordPayloads(t *testing.T) {
+	ps := DiscordPayloads(testDigest())
+	if len(ps) != 1 {
+		t.Fatalf("payloads = %d, want 1", len(ps))
+	}
+	e := ps[0].(discordPayload).Embeds[0]
+	if e.Title != "Daily <Go> *digest*" {
+		t.Errorf("title = %q; embed titles take no markdown", e.Title)
+	}
+	for _, want := range []string{
+		`Rust & \[Go\] news`,
+		`1\. [One\_1](https://example.com/a_%28b%29?x=1&y=2|z)`,
+		`2\. [Two\|2]`,
+		`5\. [Five]`,
+	} {
+		if !strings.Contains(e.Description, want) {
+			t.Errorf("description lacks %q:\n%s", want, e.Description)
+		}
+	}
+	if strings.Contains(e.Description, "Six") {
+		t.Errorf("more than %d items posted:\n%s", DigestTopItems, e.Description)
+	}
+}
+
+func TestSlackPayloads(t *testing.T) {
+	ps := SlackPayloads(testDigest())
+	if len(ps) != 1 {
+		t.Fatalf("payloads = %d, want 1", len(ps))
+	}
+	text := ps[0].(slackPayload).Text
+	for _, want := range []string{
+		"*Daily &lt;Go&gt; *digest**\n",
+		"Rust &amp; [Go] news",
+		"• <https://example.com/a_(b)?x=1&y=2%7Cz|One_1>",
+		"• <https://example.com/a_(b)?x=1&y=2%7Cz|Three&gt;3>",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Six") {
+		t.Errorf("more than %d items posted:\n%s", DigestTopItems, text)
+	}
+}
+
+func TestPayloadsSplitAtPlatformLimits(t *testing.T) {
+	d := Digest{Title: "Long", Summary: strings.Repeat("word ", 900)}
+	for i := 0; i < DigestTopItems; i++ {
+		d.Items = append(d.Items, DigestItem{Title: strings.Repeat("t", 300), URL: "https://example.com"})
+	}
+	var all strings.Builder
+	ds := DiscordPayloads(d)
+	if len(ds) < 2 {
+		t.Fatalf("discord payloads = %d, want a split", len(ds))
+	}
+	for i, p := range ds {
+		e := p.(discordPayload).Embeds[0]
+		if n := utf8.RuneCountInString(e.Description); n > discordDescriptionLimit {
+			t.Errorf("discord payload %d: description of %d chars", i, n)
+		}
+		if (e.Title != "") != (i == 0) {
+			t.Errorf("discord payload %d: title %q; only the first carries it", i, e.Title)
+		}
+		all.WriteString(e.Description + "\n")
+	}
+	if n := strings.Count(all.String(), "word"); n != 900 {
+		t.Errorf("discord payloads carry %d summary words, want 900", n)
+	}
+	all.Reset()
+	ss := SlackPayloads(d)
+	if len(ss) < 2 {
+		t.Fatalf("slack payloads = %d, want a split", len(ss))
+	}
+	for i, p := range ss {
+		text := p.(slackPayload).Text
+		if n := utf8.RuneCountInString(text); n > slackTextLimit {
+			t.Errorf("slack payload %d: text of %d chars", i, n)
+		}
+		all.WriteString(text + "\n")
+	}
+	if n := strings.Count(all.String(), "word"); n != 900 {
+		t.Errorf("slack payloads carry %d summary words, want 900", n)
+	}
+}
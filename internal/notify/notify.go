@@ -0,0 +1,107 @@
+// Package notify sends operator notifications about pipeline events (failed
+// deliveries, skipped digests) to webhooks, and digest summaries to Discord and
+// Slack incoming webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event kinds.
+const (
+	KindDeliveryFailed = "delivery_failed"
+	KindDigestSkipped  = "digest_skipped"
+	KindRenderFailed   = "render_failed"
+	KindHealthReport   = "health_report" // the daily pipeline health report, in Message as markdown
+)
+
+// Event is the JSON body posted to webhooks.
+type Event struct {
+	Kind    string    `json:"kind"`
+	Channel string    `json:"channel,omitempty"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Notifier delivers an event to an operator-facing sink.
+type Notifier interface {
+	Notify(ctx context.Context, ev Event) error
+}
+
+// Webhook posts events as JSON to a URL.
+type Webhook struct {
+	URL  string
+	HTTP *http.Client
+}
+
+// Notify posts ev to the webhook URL; any non-2xx response is an error.
+func (w *Webhook) Notify(ctx context.Context, ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	return postJSON(ctx, w.HTTP, w.URL, ev)
+}
+
+// postJSON posts v as JSON to url; any non-2xx response is an error. Failed
+// posts are not retried here: callers queue them for the delivery reconciler or
+// just log them.
+func postJSON(ctx context.Context, hc *http.Client, url string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s: status=%d body=%s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Multi fans an event out to several notifiers and joins their errors.
+type Multi []Notifier
+
+// Notify sends ev to every notifier, even if some fail.
+func (m Multi) Notify(ctx context.Context, ev Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewWebhooks returns a notifier posting to every non-empty URL, or nil when there are none.
+func NewWebhooks(urls []string, hc *http.Client) Notifier {
+	var m Multi
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			m = append(m, &Webhook{URL: u, HTTP: hc})
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
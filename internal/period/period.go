@@ -0,0 +1,97 @@
+// Package period converts between times and the period keys items and digests
+// are stored under: "2025-10-23" for a daily period and "2025-W43" for an ISO
+// week, both in UTC.
+package period
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies.
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+const dayLayout = "2006-01-02"
+
+// Key returns the key of the period of freq containing t; frequencies other than
+// weekly are daily.
+func Key(freq string, t time.Time) string {
+	utc := t.UTC()
+	if freq == Weekly {
+		y, w := utc.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", y, w)
+	}
+	return utc.Format(dayLayout)
+}
+
+// Parse returns the frequency of a period key and the UTC time range
+// [start, end) it covers.
+func Parse(key string) (freq string, start, end time.Time, err error) {
+	if y, w, ok := strings.Cut(key, "-W"); ok {
+		year, errY := strconv.Atoi(y)
+		week, errW := strconv.Atoi(w)
+		if errY != nil || errW != nil || len(y) != 4 || len(w) != 2 || week < 1 {
+			return "", time.Time{}, time.Time{}, fmt.Errorf("invalid weekly period %q (want YYYY-Www)", key)
+		}
+		start = weekStart(year, week)
+		if gy, gw := start.ISOWeek(); gy != year || gw != week {
+			return "", time.Time{}, time.Time{}, fmt.Errorf("invalid weekly period %q: %d has no week %d", key, year, week)
+		}
+		return Weekly, start, start.AddDate(0, 0, 7), nil
+	}
+	start, err = time.Parse(dayLayout, key)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("invalid period %q (want YYYY-MM-DD or YYYY-Www)", key)
+	}
+	return Daily, start, start.AddDate(0, 0, 1), nil
+}
+
+// weekStart returns the Monday starting ISO week w of year; week 1 is the week
+// holding January 4th.
+func weekStart(year, w int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7 // days since Monday
+	return jan4.AddDate(0, 0, -offset+(w-1)*7)
+}
+
+// Previous returns the key of the period before key, of the same frequency.
+func Previous(key string) (string, error) {
+	freq, start, _, err := Parse(key)
+	if err != nil {
+		return "", err
+	}
+	return Key(freq, start.Add(-time.Nanosecond)), nil
+}
+
+// Next returns the key of the period after key, of the same frequency.
+func Next(key string) (string, error) {
+	freq, _, end, err := Parse(key)
+	if err != nil {
+		return "", err
+	}
+	return Key(freq, end), nil
+}
+
+// Range returns the keys of the periods of freq from the one containing from to
+// the one containing to, oldest first; it is empty when to is before from.
+func Range(freq string, from, to time.Time) []string {
+	var out []string
+	if to.Before(from) {
+		return out
+	}
+	last := Key(freq, to)
+	for t := from; ; {
+		k := Key(freq, t)
+		out = append(out, k)
+		if k == last {
+			return out
+		}
+		_, _, end, _ := Parse(k)
+		t = end
+	}
+}
@@ -0,0 +1,123 @@
+package period
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func TestKey(t *testing.T) {
+	for _, c := range []struct {
+		freq string
+		at   time.Time
+		want string
+	}{
+		{Daily, day(2025, 10, 23), "2025-10-23"},
+		{"", day(2025, 10, 23), "2025-10-23"},
+		{Daily, time.Date(2025, 1, 1, 7, 0, 0, 0, time.FixedZone("CST", 8*3600)), "2024-12-31"},
+		{Weekly, day(2025, 10, 23), "2025-W43"},
+		{Weekly, day(2020, 12, 31), "2020-W53"}, // Thursday
+		{Weekly, day(2021, 1, 3), "2020-W53"},   // Sunday of the 53rd week
+		{Weekly, day(2021, 1, 4), "2021-W01"},
+		{Weekly, day(2024, 12, 30), "2025-W01"}, // Monday of the next ISO year
+		{Weekly, day(2026, 12, 31), "2026-W53"},
+		{Weekly, day(2027, 1, 3), "2026-W53"},
+		{Weekly, day(2027, 1, 4), "2027-W01"},
+	} {
+		if got := Key(c.freq, c.at); got != c.want {
+			t.Errorf("Key(%q, %v) = %q, want %q", c.freq, c.at, got, c.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		key        string
+		freq       string
+		start, end time.Time
+	}{
+		{"2025-10-23", Daily, day(2025, 10, 23), day(2025, 10, 24)},
+		{"2025-01-31", Daily, day(2025, 1, 31), day(2025, 2, 1)},
+		{"2024-02-29", Daily, day(2024, 2, 29), day(2024, 3, 1)},
+		{"2025-12-31", Daily, day(2025, 12, 31), day(2026, 1, 1)},
+		{"2025-W43", Weekly, day(2025, 10, 20), day(2025, 10, 27)},
+		{"2020-W53", Weekly, day(2020, 12, 28), day(2021, 1, 4)},
+		{"2025-W01", Weekly, day(2024, 12, 30), day(2025, 1, 6)},
+		{"2026-W01", Weekly, day(2025, 12, 29), day(2026, 1, 5)},
+	} {
+		freq, start, end, err := Parse(c.key)
+		if err != nil || freq != c.freq || !start.Equal(c.start) || !end.Equal(c.end) {
+			t.Errorf("Parse(%q) = %q, %v, %v, %v; want %q, %v, %v", c.key, freq, start, end, err, c.freq, c.start, c.end)
+		}
+	}
+	for _, key := range []string{"", "garbage", "2025-02-29", "2025-13-01", "2025-W00", "2025-W1", "2021-W53", "2025-W54", "25-W01", "2025-Wxx"} {
+		if _, _, _, err := Parse(key); err == nil {
+			t.Errorf("Parse(%q) = nil error", key)
+		}
+	}
+}
+
+// Every day of two decades falls in the range its keys parse back to.
+func TestKeyParseRoundTrip(t *testing.T) {
+	for at := day(2015, 1, 1); at.Before(day(2036, 1, 1)); at = at.AddDate(0, 0, 1) {
+		for _, freq := range []string{Daily, Weekly} {
+			noon := at.Add(12 * time.Hour)
+			key := Key(freq, noon)
+			f, start, end, err := Parse(key)
+			if err != nil || f != freq || noon.Before(start) || !noon.Before(end) || Key(freq, start) != key {
+				t.Fatalf("Parse(Key(%s, %v) = %q) = %q, %v, %v, %v", freq, noon, key, f, start, end, err)
+			}
+		}
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	for key, want := range map[string]string{
+		"2025-03-01": "2025-02-28",
+		"2024-03-01": "2024-02-29",
+		"2025-01-01": "2024-12-31",
+		"2025-W43":   "2025-W42",
+		"2021-W01":   "2020-W53",
+		"2025-W01":   "2024-W52",
+	} {
+		if got, err := Previous(key); err != nil || got != want {
+			t.Errorf("Previous(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if _, err := Previous("2025-W60"); err == nil {
+		t.Error("Previous of an invalid key = nil error")
+	}
+}
+
+func TestNext(t *testing.T) {
+	for key, want := range map[string]string{
+		"2024-02-28": "2024-02-29",
+		"2024-12-31": "2025-01-01",
+		"2020-W53":   "2021-W01",
+		"2025-W43":   "2025-W44",
+	} {
+		if got, err := Next(key); err != nil || got != want {
+			t.Errorf("Next(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, c := range []struct {
+		freq     string
+		from, to time.Time
+		want     []string
+	}{
+		{Daily, day(2025, 1, 30), day(2025, 2, 2).Add(23 * time.Hour), []string{"2025-01-30", "2025-01-31", "2025-02-01", "2025-02-02"}},
+		{Daily, day(2025, 1, 30).Add(20 * time.Hour), day(2025, 1, 30).Add(21 * time.Hour), []string{"2025-01-30"}},
+		{Weekly, day(2020, 12, 25), day(2021, 1, 11), []string{"2020-W52", "2020-W53", "2021-W01", "2021-W02"}},
+		{Weekly, day(2025, 10, 21), day(2025, 10, 23), []string{"2025-W43"}},
+		{Daily, day(2025, 2, 2), day(2025, 2, 1), nil},
+	} {
+		if got := Range(c.freq, c.from, c.to); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Range(%s, %v, %v) = %v, want %v", c.freq, c.from, c.to, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Package producthunt reads the day's launches from the Product Hunt GraphQL API
+// (v2) into model.NewsItem.
+package producthunt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of Product Hunt launches.
+const Source = "producthunt"
+
+// DefaultBaseURL is the GraphQL endpoint of API v2.
+const DefaultBaseURL = "https://api.producthunt.com/v2/api/graphql"
+
+// SiteURL is the site topic pages (/topics/<slug>) live on.
+const SiteURL = "https://www.producthunt.com"
+
+// pageSize is the number of posts requested, most voted first.
+const pageSize = 50
+
+// postsQuery asks for the most voted posts since postedAfter with the fields items
+// are built from.
+const postsQuery = `query Posts($postedAfter: DateTime!, $first: Int!) {
+  posts(order: VOTES, postedAfter: $postedAfter, first: $first) {
+    edges {
+      node {
+        id
+        name
+        tagline
+        description
+        url
+        votesCount
+        commentsCount
+        createdAt
+        user { username }
+        topics(first: 5) { edges { node { slug } } }
+      }
+    }
+  }
+}`
+
+// Client is a minimal Product Hunt API client.
+type Client struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewClient returns a client for the GraphQL endpoint (empty uses DefaultBaseURL)
+// authenticating with a developer token.
+func NewClient(endpoint, token string) *Client {
+	if strings.TrimSpace(endpoint) == "" {
+		endpoint = DefaultBaseURL
+	}
+	return &Client{endpoint: strings.TrimSpace(endpoint), token: strings.TrimSpace(token), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// post mirrors the GraphQL post fields items are built from.
+type post struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Tagline       string    `json:"tagline"`
+	Description   string    `json:"description"`
+	URL           string    `json:"url"`
+	VotesCount    int       `json:"votesCount"`
+	CommentsCount int       `json:"commentsCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+	User          struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Topics struct {
+		Edges []struct {
+			Node struct {
+				Slug string `json:"slug"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"topics"`
+}
+
+type response struct {
+	Data struct {
+		Posts struct {
+			Edges []struct {
+				Node json.RawMessage `json:"node"`
+			} `json:"edges"`
+		} `json:"posts"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Posts returns the posts launched after since, most voted first.
+func (c *Client) Posts(ctx context.Context, since time.Time) ([]model.NewsItem, error) {
+	if c.token == "" {
+		return nil, errors.New("producthunt: no token")
+	}
+	body, err := json.Marshal(map[string]any{
+		"query":     postsQuery,
+		"variables": map[string]any{"postedAfter": since.UTC().Format(time.RFC3339), "first": pageSize},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("producthunt posts: status %d", resp.StatusCode)
+	}
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("producthunt posts: decode: %w", err)
+	}
+	if len(r.Errors) > 0 {
+		msgs := make([]string, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return nil, fmt.Errorf("producthunt posts: %s", strings.Join(msgs, "; "))
+	}
+	out := make([]model.NewsItem, 0, len(r.Data.Posts.Edges))
+	for _, e := range r.Data.Posts.Edges {
+		var p post
+		if err := json.Unmarshal(e.Node, &p); err != nil {
+			return nil, fmt.Errorf("producthunt posts: decode post: %w", err)
+		}
+		if p.ID == "" {
+			continue
+		}
+		it := p.item()
+		it.Raw = e.Node
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// item maps a post to a NewsItem: votes are its points, the tagline (and
+// description) its content, and its topics are joined into the node name the way
+// Lobste.rs tags are, so channels pick topics with the same filter.
+func (p post) item() model.NewsItem {
+	topics := make([]string, 0, len(p.Topics.Edges))
+	for _, t := range p.Topics.Edges {
+		topics = append(topics, t.Node.Slug)
+	}
+	content := strings.TrimSpace(p.Tagline)
+	if d := strings.TrimSpace(p.Description); d != "" && d != content {
+		content = strings.TrimSpace(content + "\n\n" + d)
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        p.ID,
+		Title:     strings.TrimSpace(p.Name),
+		URL:       strings.TrimSpace(p.URL),
+		NodeName:  lobsters.NodeName(topics),
+		Replies:   p.CommentsCount,
+		Points:    p.VotesCount,
+		CreatedAt: p.CreatedAt.UTC(),
+		Content:   content,
+		Author:    p.User.Username,
+	}
+}
@@ -0,0 +1,93 @@
+package producthunt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestPosts(t *testing.T) {
+	body, err := os.ReadFile("testdata/posts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotVars map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("%s with Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.Contains(req.Query, "posts(order: VOTES") {
+			t.Errorf("request = %+v, %v", req, err)
+		}
+		gotVars = req.Variables
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	since := time.Date(2025, 10, 23, 7, 0, 0, 0, time.UTC)
+	items, err := NewClient(srv.URL, "tok").Posts(context.Background(), since)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Posts = %d items, %v", len(items), err)
+	}
+	if gotVars["postedAfter"] != "2025-10-23T07:00:00Z" {
+		t.Errorf("postedAfter = %v", gotVars["postedAfter"])
+	}
+	if !strings.Contains(string(items[0].Raw), `"votesCount": 642`) {
+		t.Errorf("items[0].Raw = %s, want the post as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "912345",
+		Title:     "Inkwell",
+		URL:       "https://www.producthunt.com/posts/inkwell?utm_campaign=producthunt-api",
+		NodeName:  "developer-tools,productivity",
+		Replies:   58,
+		Points:    642,
+		CreatedAt: time.Date(2025, 10, 24, 7, 1, 0, 0, time.UTC),
+		Content:   "Write docs your team actually reads\n\nInkwell turns your codebase into living documentation.",
+		Author:    "maker",
+	}, {
+		// A description repeating the tagline is not doubled.
+		Source:    Source,
+		ID:        "912399",
+		Title:     "Lumen",
+		URL:       "https://www.producthunt.com/posts/lumen",
+		NodeName:  "email",
+		Points:    133,
+		CreatedAt: time.Date(2025, 10, 24, 7, 5, 30, 0, time.UTC),
+		Content:   "A calmer inbox",
+		Author:    "[REDACTED]",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+}
+
+func TestPostsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": null, "errors": [{"message": "Field 'posts' doesn't accept argument 'foo'"}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "tok").Posts(context.Background(), time.Now()); err == nil || !strings.Contains(err.Error(), "doesn't accept argument") {
+		t.Errorf("Posts with GraphQL errors = %v", err)
+	}
+	if _, err := NewClient(srv.URL, "").Posts(context.Background(), time.Now()); err == nil {
+		t.Error("Posts without a token went out")
+	}
+}
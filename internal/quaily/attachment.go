@@ -65,7 +65,7 @@ func (c *Client) UploadAttachment(ctx context.Context, filePath string, encrypte
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("upload attachment failed: status=%d body=%s", resp.StatusCode, string(b))
+		return "", &StatusError{Op: "upload attachment", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 	var out attachmentResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
@@ -12,6 +12,24 @@ import (
 	"time"
 )
 
+// StatusError is a non-2xx response from the Quaily API.
+type StatusError struct {
+	Op         string // e.g., "create post"
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: status=%d body=%s", e.Op, e.StatusCode, e.Body)
+}
+
+// IsConflict reports whether err is a 409 from Quaily, e.g., Create Post with a slug
+// the channel already has.
+func IsConflict(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusConflict
+}
+
 // Client is a minimal HTTP client for Quaily API.
 type Client struct {
 	baseURL string
@@ -21,6 +39,9 @@ type Client struct {
 	createPath  string
 	publishPath string // Template: "/posts/%s/publish"
 	deliverPath string // Template: "/lists/%s/posts/%s/deliver"
+	postPath    string // Template: "/lists/%s/posts/%s" (channel slug, post slug)
+	// extraParams are frontmatter keys sent to Create Post besides DefaultParams.
+	extraParams []string
 }
 
 // New creates a new Quaily client.
@@ -36,7 +57,17 @@ func New(baseURL, apiKey string, timeout time.Duration) *Client {
 		createPath:  "/lists/%s/posts",
 		publishPath: "/lists/%s/posts/%s/publish",
 		deliverPath: "/lists/%s/posts/%s/deliver",
+		postPath:    "/lists/%s/posts/%s",
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.http = hc
 	}
+	return &c2
 }
 
 // WithPaths optionally overrides endpoints.
@@ -51,6 +82,14 @@ func (c *Client) WithPaths(createPath, publishPath string) *Client {
 	return &c2
 }
 
+// WithExtraParams returns a copy of the client that also passes the given
+// frontmatter keys to Create Post; see DefaultParams.
+func (c *Client) WithExtraParams(keys []string) *Client {
+	c2 := *c
+	c2.extraParams = append([]string(nil), keys...)
+	return &c2
+}
+
 // WithDeliverPath optionally overrides the deliver endpoint path.
 func (c *Client) WithDeliverPath(deliverPath string) *Client {
 	c2 := *c
@@ -87,30 +126,91 @@ func (c *Client) CreatePost(ctx context.Context, channelSlug string, params map[
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("create post failed: status=%d body=%s", resp.StatusCode, string(b))
+		return "", &StatusError{Op: "create post", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 	var out map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
 		return "", err
 	}
-	// Try common patterns for id
-	if id, ok := out["id"].(string); ok && id != "" {
+	if id := responseID(out); id != "" {
 		return id, nil
 	}
-	if idf, ok := out["id"].(float64); ok {
-		return fmt.Sprintf("%v", idf), nil
-	}
 	if data, ok := out["data"].(map[string]any); ok {
-		if id, ok := data["id"].(string); ok && id != "" {
+		if id := responseID(data); id != "" {
 			return id, nil
 		}
-		if idf, ok := data["id"].(float64); ok {
-			return fmt.Sprintf("%v", idf), nil
-		}
 	}
 	return "", errors.New("create post: missing id in response")
 }
 
+// responseID returns the "id" of a response object, which may be a string or a number.
+func responseID(m map[string]any) string {
+	if id, ok := m["id"].(string); ok && id != "" {
+		return id
+	}
+	if idf, ok := m["id"].(float64); ok {
+		return fmt.Sprintf("%v", idf)
+	}
+	return ""
+}
+
+// Post is the part of a Quaily post the client reads back.
+type Post struct {
+	ID   string
+	Slug string
+	// Published is false for a draft, e.g., one whose publish call failed after it was created.
+	Published bool
+	// Content is the post's Markdown body, as sent to Create Post.
+	Content string
+}
+
+// GetPostBySlug looks up a post of a channel by slug; ok is false when the channel
+// has no such post.
+func (c *Client) GetPostBySlug(ctx context.Context, channelSlug, postSlug string) (p Post, ok bool, err error) {
+	if c == nil {
+		return p, false, errors.New("nil quaily client")
+	}
+	if strings.TrimSpace(postSlug) == "" {
+		return p, false, errors.New("empty post slug")
+	}
+	url := c.baseURL + fmt.Sprintf(c.postPath, channelSlug, postSlug)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return p, false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return p, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return p, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return p, false, &StatusError{Op: "get post", StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return p, false, err
+	}
+	if data, ok := out["data"].(map[string]any); ok {
+		out = data
+	}
+	p.ID = responseID(out)
+	p.Slug, _ = out["slug"].(string)
+	p.Content, _ = out["content"].(string)
+	if p.Slug == "" {
+		p.Slug = postSlug
+	}
+	// A published post carries its publish time; drafts have none (or null).
+	if at, _ := out["published_at"].(string); at != "" {
+		p.Published = true
+	}
+	return p, true, nil
+}
+
 // PublishPost triggers publishing for a post by ID.
 func (c *Client) PublishPost(ctx context.Context, channelSlug, id string) error {
 	if c == nil {
@@ -133,7 +233,7 @@ func (c *Client) PublishPost(ctx context.Context, channelSlug, id string) error
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("publish post failed: status=%d body=%s", resp.StatusCode, string(b))
+		return &StatusError{Op: "publish post", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 	return nil
 }
@@ -160,7 +260,37 @@ func (c *Client) DeliverPost(ctx context.Context, channelSlug, postSlug string)
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("deliver post failed: status=%d body=%s", resp.StatusCode, string(b))
+		return &StatusError{Op: "deliver post", StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	return nil
+}
+
+// DeletePost deletes a post of a channel by ID; a post that no longer exists is not
+// an error.
+func (c *Client) DeletePost(ctx context.Context, channelSlug, id string) error {
+	if c == nil {
+		return errors.New("nil quaily client")
+	}
+	if strings.TrimSpace(id) == "" {
+		return errors.New("empty post id")
+	}
+	url := c.baseURL + fmt.Sprintf(c.postPath, channelSlug, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return &StatusError{Op: "delete post", StatusCode: resp.StatusCode, Body: string(b)}
 	}
 	return nil
 }
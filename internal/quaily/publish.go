@@ -2,40 +2,146 @@ package quaily
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
 	"time"
 
 	"quaily-journalist/internal/markdown"
 )
 
-// PublishMarkdownFile parses a Markdown file, uses its frontmatter as params,
+// DefaultParams are the frontmatter keys passed to Create Post. Other keys
+// (e.g., a static site's draft flag) are dropped unless listed in quaily.extra_params.
+var DefaultParams = []string{"title", "slug", "datetime", "summary", "cover_image_url", "cover_image_alt", "tags", "seo_description", "keywords"}
+
+// PublishMarkdownFile parses a Markdown file, uses its allowed frontmatter keys as params,
 // adds channel_slug and content, creates the post and publishes it.
+//
+// When Create Post conflicts because the slug exists (e.g., a retry after the publish
+// step failed), the existing post is adopted if its content matches: a draft is
+// published and a live post is left as is. Different content is an error; the
+// existing post is never overwritten.
 func PublishMarkdownFile(ctx context.Context, c *Client, path, channelSlug string) error {
 	doc, err := markdown.ParseFile(path)
 	if err != nil {
 		return fmt.Errorf("read markdown: %w", err)
 	}
-	params := map[string]any{}
-	for k, v := range doc.Frontmatter {
-		params[k] = v
-	}
+	params := postParams(doc.Frontmatter, c.extraParams)
 	params["channel_slug"] = channelSlug
 	params["content"] = doc.Body
-	// for the datetime
-	// if it's not RFC3339, try to parse it as RFC 3339
-	// re-format it as RFC3339 if it's present
-	if dtRaw, ok := params["datetime"]; ok {
-		if dtStr, ok := dtRaw.(string); ok {
-			if t, err := time.Parse("2006-01-02 15:04", dtStr); err == nil {
-				params["datetime"] = t.Format(time.RFC3339)
-			}
-		}
-	}
-	params["content"] = doc.Body
 
 	postID, err := c.CreatePost(ctx, channelSlug, params)
+	if slug, _ := params["slug"].(string); IsConflict(err) && slug != "" {
+		return adoptPost(ctx, c, channelSlug, slug, doc.Body)
+	}
 	if err != nil {
 		return err
 	}
 	return c.PublishPost(ctx, channelSlug, postID)
 }
+
+// DraftMarkdownFile creates a post from a Markdown file like PublishMarkdownFile, but
+// under slug and without publishing it, and returns its ID and the body it sent.
+func DraftMarkdownFile(ctx context.Context, c *Client, path, channelSlug, slug string) (id, body string, err error) {
+	doc, err := markdown.ParseFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read markdown: %w", err)
+	}
+	params := postParams(doc.Frontmatter, c.extraParams)
+	params["channel_slug"] = channelSlug
+	params["slug"] = slug
+	params["content"] = doc.Body
+	id, err = c.CreatePost(ctx, channelSlug, params)
+	return id, doc.Body, err
+}
+
+// SameContent reports whether two post bodies match the way a conflicting slug is
+// adopted: ignoring line endings and surrounding whitespace.
+func SameContent(a, b string) bool {
+	return contentHash(a) == contentHash(b)
+}
+
+// FileHash identifies what PublishMarkdownFile would send for a Markdown file with
+// c: its body and the frontmatter params kept for Create Post (see DefaultParams and
+// WithExtraParams). It is a hex SHA-256 and changes whenever either changes.
+func FileHash(c *Client, path string) (string, error) {
+	doc, err := markdown.ParseFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read markdown: %w", err)
+	}
+	params := postParams(doc.Frontmatter, c.extraParams)
+	b, err := json.Marshal(params) // map keys are sorted, so equal params marshal equally
+	if err != nil {
+		return "", err
+	}
+	body := contentHash(doc.Body)
+	sum := sha256.Sum256(append(append(b, 0), body[:]...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// adoptPost publishes the existing post of a conflicting slug when its content is body.
+func adoptPost(ctx context.Context, c *Client, channelSlug, slug, body string) error {
+	post, ok, err := c.GetPostBySlug(ctx, channelSlug, slug)
+	if err != nil {
+		return fmt.Errorf("slug %q already exists; look up the existing post: %w", slug, err)
+	}
+	if !ok {
+		return fmt.Errorf("create post conflicted on slug %q, but no such post was found", slug)
+	}
+	if contentHash(post.Content) != contentHash(body) {
+		slog.Warn("quaily: slug exists with different content; not adopting", "channel", channelSlug, "slug", slug, "post_id", post.ID)
+		return fmt.Errorf("slug %q already exists on Quaily with different content (post %s)", slug, post.ID)
+	}
+	if post.Published {
+		slog.Info("quaily: slug exists and is live with the same content; adopted it", "channel", channelSlug, "slug", slug, "post_id", post.ID)
+		return nil
+	}
+	slog.Info("quaily: slug exists as a draft with the same content; adopting and publishing it", "channel", channelSlug, "slug", slug, "post_id", post.ID)
+	return c.PublishPost(ctx, channelSlug, post.ID)
+}
+
+// contentHash hashes a post body, ignoring line endings and surrounding whitespace,
+// which the API may not preserve.
+func contentHash(s string) [sha256.Size]byte {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return sha256.Sum256([]byte(strings.TrimSpace(s)))
+}
+
+// postParams keeps the allowed frontmatter keys and normalizes datetime to RFC 3339.
+func postParams(fm map[string]any, extra []string) map[string]any {
+	allowed := make(map[string]struct{}, len(DefaultParams)+len(extra))
+	for _, k := range DefaultParams {
+		allowed[k] = struct{}{}
+	}
+	for _, k := range extra {
+		allowed[k] = struct{}{}
+	}
+	params := map[string]any{}
+	for k, v := range fm {
+		if _, ok := allowed[k]; !ok {
+			slog.Debug("quaily: dropped frontmatter key", "key", k)
+			continue
+		}
+		params[k] = v
+	}
+	// yaml parses unquoted timestamps into time.Time; quoted or minute-precision ones stay strings.
+	switch dt := params["datetime"].(type) {
+	case time.Time:
+		params["datetime"] = dt.Format(time.RFC3339)
+	case string:
+		if t, err := time.Parse("2006-01-02 15:04", dt); err == nil {
+			params["datetime"] = t.Format(time.RFC3339)
+		}
+	}
+	return params
+}
+
+// PostURL is the public address of a post on the Quaily site at siteBaseURL (e.g.,
+// https://quaily.com): <siteBaseURL>/<channelSlug>/p/<postSlug>.
+func PostURL(siteBaseURL, channelSlug, postSlug string) string {
+	return strings.TrimRight(strings.TrimSpace(siteBaseURL), "/") + "/" + url.PathEscape(channelSlug) + "/p/" + url.PathEscape(postSlug)
+}
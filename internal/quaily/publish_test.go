@@ -0,0 +1,168 @@
+package quaily
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"quaily-journalist/internal/markdown"
+)
+
+func TestPostParamsAllowlist(t *testing.T) {
+	fm := map[string]any{"title": "T", "slug": "s", "draft": true, "layout": "post", "series": "weekly"}
+	params := postParams(fm, []string{"series"})
+	for _, k := range []string{"title", "slug", "series"} {
+		if _, ok := params[k]; !ok {
+			t.Errorf("missing allowed key %q", k)
+		}
+	}
+	for _, k := range []string{"draft", "layout"} {
+		if _, ok := params[k]; ok {
+			t.Errorf("unexpected key %q passed through", k)
+		}
+	}
+}
+
+func TestPostParamsDatetime(t *testing.T) {
+	cases := map[string]string{
+		"datetime: 2025-10-24 00:30\n":          "2025-10-24T00:30:00Z", // string, minute precision
+		"datetime: 2025-10-24T08:30:00+08:00\n": "2025-10-24T08:30:00+08:00",
+		"datetime: 2025-10-24\n":                "2025-10-24T00:00:00Z",
+		"datetime: \"2025-10-24T00:30:00Z\"\n":  "2025-10-24T00:30:00Z",
+	}
+	dir := t.TempDir()
+	for in, want := range cases {
+		p := filepath.Join(dir, "post.md")
+		if err := os.WriteFile(p, []byte("---\n"+in+"---\nbody\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := markdown.ParseFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := postParams(doc.Frontmatter, nil)["datetime"]; got != want {
+			t.Errorf("%q: datetime = %v (%T), want %s", in, got, got, want)
+		}
+	}
+}
+
+// conflictServer is a fake Quaily whose Create Post always conflicts and whose
+// existing post has the given content and publish state.
+func conflictServer(t *testing.T, content string, published bool) (*Client, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/lists/ch/posts":
+			http.Error(w, `{"error":"slug exists"}`, http.StatusConflict)
+		case r.Method == http.MethodGet && r.URL.Path == "/lists/ch/posts/daily-20251024":
+			post := map[string]any{"id": 7, "slug": "daily-20251024", "content": content, "published_at": nil}
+			if published {
+				post["published_at"] = "2025-10-24T08:00:00Z"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": post})
+		case r.Method == http.MethodPut && r.URL.Path == "/lists/ch/posts/7/publish":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL, "key", 0), &calls
+}
+
+func writePost(t *testing.T, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "daily-20251024.md")
+	if err := os.WriteFile(p, []byte("---\ntitle: T\nslug: daily-20251024\n---\n"+body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPublishConflictAdoptsDraft(t *testing.T) {
+	c, calls := conflictServer(t, "## Item\r\n\nText\r\n", false)
+	if err := PublishMarkdownFile(context.Background(), c, writePost(t, "## Item\n\nText\n"), "ch"); err != nil {
+		t.Fatal(err)
+	}
+	want := "POST /lists/ch/posts,GET /lists/ch/posts/daily-20251024,PUT /lists/ch/posts/7/publish"
+	if got := strings.Join(*calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestPublishConflictAdoptsLivePost(t *testing.T) {
+	c, calls := conflictServer(t, "## Item\n\nText", true)
+	if err := PublishMarkdownFile(context.Background(), c, writePost(t, "## Item\n\nText\n"), "ch"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(*calls, ","); strings.Contains(got, "PUT") {
+		t.Errorf("live post published again: %s", got)
+	}
+}
+
+func TestPublishConflictWithDifferentContent(t *testing.T) {
+	c, calls := conflictServer(t, "## Someone else's post", false)
+	err := PublishMarkdownFile(context.Background(), c, writePost(t, "## Item\n\nText\n"), "ch")
+	if err == nil || !strings.Contains(err.Error(), "different content") {
+		t.Fatalf("err = %v, want a content mismatch", err)
+	}
+	if got := strings.Join(*calls, ","); strings.Contains(got, "PUT") {
+		t.Errorf("mismatched post was published: %s", got)
+	}
+	if IsConflict(err) {
+		t.Error("mismatch reported as a plain conflict")
+	}
+}
+
+func TestIsConflict(t *testing.T) {
+	if !IsConflict(&StatusError{Op: "create post", StatusCode: http.StatusConflict}) {
+		t.Error("409 not a conflict")
+	}
+	if IsConflict(&StatusError{Op: "create post", StatusCode: http.StatusBadRequest}) {
+		t.Error("400 is a conflict")
+	}
+}
+
+func TestFileHash(t *testing.T) {
+	c := New("https://quaily.example", "k", 0)
+	dir := t.TempDir()
+	hash := func(doc string) string {
+		t.Helper()
+		p := filepath.Join(dir, "post.md")
+		if err := os.WriteFile(p, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		h, err := FileHash(c, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	base := hash("---\ntitle: T\nslug: s\ndraft: true\n---\nBody.\n")
+	if got := hash("---\nslug: s\ntitle: T\ndraft: false\n---\r\nBody.\r\n"); got != base {
+		t.Error("hash changed for reordered keys, a dropped key, or line endings")
+	}
+	if got := hash("---\ntitle: T2\nslug: s\n---\nBody.\n"); got == base {
+		t.Error("hash unchanged after a title change")
+	}
+	if got := hash("---\ntitle: T\nslug: s\n---\nBody, edited.\n"); got == base {
+		t.Error("hash unchanged after a body change")
+	}
+}
+
+func TestPostURL(t *testing.T) {
+	if got := PostURL("https://quaily.com/", "go-weekly", "weekly-20251024"); got != "https://quaily.com/go-weekly/p/weekly-20251024" {
+		t.Errorf("PostURL = %q", got)
+	}
+}
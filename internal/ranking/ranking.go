@@ -0,0 +1,154 @@
+// Package ranking scores items by popularity with a Hacker News-style time decay:
+//
+//	score = (count - 1) / (age_hours + age_offset) ^ gravity
+//
+// where count is the item's replies, points, or a weighted blend of both. Sources
+// whose items often carry neither, such as RSS feeds, fall back to recency alone.
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Signals an item's count can be taken from.
+const (
+	SignalReplies = "replies"
+	SignalPoints  = "points"
+	SignalBlend   = "blend" // ReplyWeight*replies + PointWeight*points
+)
+
+// Defaults of the formula.
+const (
+	DefaultGravity   = 1.8
+	DefaultAgeOffset = 2.0 // hours
+)
+
+// Scorer holds the formula parameters. Zero fields use the defaults: DefaultGravity,
+// DefaultAgeOffset, SignalReplies, and a weight of 1 for each blended count, so a
+// blend with only reply_weight set is points + reply_weight*replies.
+type Scorer struct {
+	Gravity     float64 // age exponent; lower decays more slowly
+	AgeOffset   float64 // hours added to the age, so brand-new items do not dominate
+	Signal      string  // replies, points, or blend
+	ReplyWeight float64 // blend only
+	PointWeight float64 // blend only
+	// RecencyFallback floors the count at 1+RecencyBoost, so items without replies
+	// or points still score RecencyBoost / (age_hours + age_offset)^gravity and
+	// decay by age. RecencyBoost 0 means 1.
+	RecencyFallback bool
+	RecencyBoost    float64
+}
+
+// ForSource returns the default scorer of a source: Hacker News, Lobste.rs, Reddit,
+// GitHub, Product Hunt, Mastodon, Bluesky, and Stack Overflow rank by points
+// (upvotes, stars, votes, sharing accounts, likes, or question score), every other
+// source by replies, and RSS items without comments, arXiv papers, and JSON Feed
+// items (which have neither) by recency. YouTube videos rank by likes, or by
+// recency when they carry none (feeds read without an API key).
+func ForSource(source string) Scorer {
+	switch strings.ToLower(source) {
+	case "youtube":
+		return Scorer{Signal: SignalPoints, RecencyFallback: true}
+	case "hackernews", "lobsters", "reddit", "github", "producthunt", "mastodon", "bluesky", "stackoverflow":
+		return Scorer{Signal: SignalPoints}
+	case "rss", "arxiv", "jsonfeed":
+		return Scorer{Signal: SignalReplies, RecencyFallback: true}
+	}
+	return Scorer{Signal: SignalReplies}
+}
+
+// Merge returns s with the non-zero fields of o applied on top.
+func (s Scorer) Merge(o Scorer) Scorer {
+	if o.Gravity != 0 {
+		s.Gravity = o.Gravity
+	}
+	if o.AgeOffset != 0 {
+		s.AgeOffset = o.AgeOffset
+	}
+	if o.Signal != "" {
+		s.Signal = o.Signal
+	}
+	if o.ReplyWeight != 0 {
+		s.ReplyWeight = o.ReplyWeight
+	}
+	if o.PointWeight != 0 {
+		s.PointWeight = o.PointWeight
+	}
+	if o.RecencyFallback {
+		s.RecencyFallback = true
+	}
+	if o.RecencyBoost != 0 {
+		s.RecencyBoost = o.RecencyBoost
+	}
+	return s
+}
+
+// Validate reports parameters the formula cannot use.
+func (s Scorer) Validate() error {
+	switch s.Signal {
+	case "", SignalReplies, SignalPoints, SignalBlend:
+	default:
+		return fmt.Errorf("unknown ranking signal %q (want %s, %s, or %s)", s.Signal, SignalReplies, SignalPoints, SignalBlend)
+	}
+	if s.Gravity < 0 || s.AgeOffset < 0 || s.ReplyWeight < 0 || s.PointWeight < 0 || s.RecencyBoost < 0 {
+		return fmt.Errorf("ranking gravity, age_offset_hours, weights, and recency_boost must not be negative")
+	}
+	return nil
+}
+
+// Score returns the item's score at now; items with a count of at most zero score 0
+// unless RecencyFallback is set.
+func (s Scorer) Score(it model.NewsItem, now time.Time) float64 {
+	count := s.count(it)
+	if s.RecencyFallback {
+		boost := s.RecencyBoost
+		if boost == 0 {
+			boost = 1
+		}
+		if count < 1+boost {
+			count = 1 + boost
+		}
+	}
+	if count <= 0 {
+		return 0
+	}
+	gravity, offset := s.Gravity, s.AgeOffset
+	if gravity == 0 {
+		gravity = DefaultGravity
+	}
+	if offset == 0 {
+		offset = DefaultAgeOffset
+	}
+	diff := now.Sub(it.CreatedAt).Hours()
+	if diff < 0 {
+		diff = 0
+	}
+	score := (count - 1) / math.Pow(diff+offset, gravity)
+	if math.IsNaN(score) || score < 0 {
+		score = 0
+	}
+	return score
+}
+
+func (s Scorer) count(it model.NewsItem) float64 {
+	switch s.Signal {
+	case SignalPoints:
+		return float64(it.Points)
+	case SignalBlend:
+		rw, pw := s.ReplyWeight, s.PointWeight
+		if rw == 0 {
+			rw = 1
+		}
+		if pw == 0 {
+			pw = 1
+		}
+		return rw*float64(it.Replies) + pw*float64(it.Points)
+	default:
+		return float64(it.Replies)
+	}
+}
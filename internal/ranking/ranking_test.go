@@ -0,0 +1,195 @@
+package ranking
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+var now = time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+
+// legacy is the formula the collectors hard-coded before it became configurable.
+func legacy(count int, created time.Time) float64 {
+	if count <= 0 {
+		return 0
+	}
+	diff := now.Sub(created).Hours()
+	if diff < 0 {
+		diff = 0
+	}
+	score := float64(count-1) / math.Pow(diff+2, 1.8)
+	if math.IsNaN(score) || score < 0 {
+		score = 0
+	}
+	return score
+}
+
+func TestDefaultsMatchLegacyFormula(t *testing.T) {
+	for _, count := range []int{-3, 0, 1, 2, 7, 150, 4321} {
+		for _, age := range []time.Duration{-time.Hour, 0, 37 * time.Minute, 5 * time.Hour, 200 * time.Hour} {
+			it := model.NewsItem{Replies: count, Points: count, CreatedAt: now.Add(-age)}
+			want := legacy(count, it.CreatedAt)
+			if got := ForSource("v2ex").Score(it, now); got != want {
+				t.Errorf("v2ex count=%d age=%v: got %v, want %v", count, age, got, want)
+			}
+			if got := ForSource("hackernews").Score(it, now); got != want {
+				t.Errorf("hackernews count=%d age=%v: got %v, want %v", count, age, got, want)
+			}
+		}
+	}
+	// Each source reads its own signal.
+	it := model.NewsItem{Replies: 10, Points: 3, CreatedAt: now}
+	if ForSource("v2ex").Score(it, now) != legacy(10, now) || ForSource("HackerNews").Score(it, now) != legacy(3, now) || ForSource("lobsters").Score(it, now) != legacy(3, now) || ForSource("reddit").Score(it, now) != legacy(3, now) || ForSource("github").Score(it, now) != legacy(3, now) || ForSource("producthunt").Score(it, now) != legacy(3, now) || ForSource("mastodon").Score(it, now) != legacy(3, now) || ForSource("bluesky").Score(it, now) != legacy(3, now) || ForSource("stackoverflow").Score(it, now) != legacy(3, now) {
+		t.Error("source defaults use the wrong signal")
+	}
+}
+
+func TestScoreProperties(t *testing.T) {
+	scorers := []Scorer{
+		ForSource("v2ex"),
+		{Signal: SignalPoints, Gravity: 0.8, AgeOffset: 12},
+		{Signal: SignalBlend, ReplyWeight: 2, PointWeight: 0.5, Gravity: 2.5},
+	}
+	for _, s := range scorers {
+		// More engagement never scores lower at the same age.
+		monotonic := func(count uint16, extra uint8, ageMin uint32) bool {
+			created := now.Add(-time.Duration(ageMin%(60*24*30)) * time.Minute)
+			a := model.NewsItem{Replies: int(count), Points: int(count), CreatedAt: created}
+			b := a
+			b.Replies += int(extra)
+			b.Points += int(extra)
+			return s.Score(b, now) >= s.Score(a, now)
+		}
+		// An older item never outscores a newer one with the same engagement.
+		decaying := func(count uint16, ageMin, olderBy uint32) bool {
+			created := now.Add(-time.Duration(ageMin%(60*24*30)) * time.Minute)
+			a := model.NewsItem{Replies: int(count), Points: int(count), CreatedAt: created}
+			b := a
+			b.CreatedAt = created.Add(-time.Duration(olderBy%(60*24*30)) * time.Minute)
+			return s.Score(b, now) <= s.Score(a, now)
+		}
+		if err := quick.Check(monotonic, nil); err != nil {
+			t.Errorf("%+v not monotonic in count: %v", s, err)
+		}
+		if err := quick.Check(decaying, nil); err != nil {
+			t.Errorf("%+v not decreasing in age: %v", s, err)
+		}
+	}
+}
+
+func TestSlowerGravityFavorsOlderItems(t *testing.T) {
+	old := model.NewsItem{Replies: 100, CreatedAt: now.Add(-72 * time.Hour)}
+	fresh := model.NewsItem{Replies: 10, CreatedAt: now.Add(-time.Hour)}
+	daily := ForSource("v2ex")
+	weekly := daily.Merge(Scorer{Gravity: 0.5})
+	if daily.Score(old, now) >= daily.Score(fresh, now) {
+		t.Error("default gravity should favor the fresh item")
+	}
+	if weekly.Score(old, now) <= weekly.Score(fresh, now) {
+		t.Error("gravity 0.5 should favor the popular older item")
+	}
+}
+
+func TestBlendAndValidate(t *testing.T) {
+	it := model.NewsItem{Replies: 4, Points: 10, CreatedAt: now}
+	s := Scorer{Signal: SignalBlend}
+	if got, want := s.Score(it, now), 13/math.Pow(2, 1.8); got != want {
+		t.Errorf("unweighted blend = %v, want %v", got, want)
+	}
+	s = s.Merge(Scorer{ReplyWeight: 3, PointWeight: 0.5})
+	if got, want := s.Score(it, now), 16/math.Pow(2, 1.8); got != want {
+		t.Errorf("weighted blend = %v, want %v", got, want)
+	}
+	if err := (Scorer{Signal: "likes"}).Validate(); err == nil {
+		t.Error("unknown signal accepted")
+	}
+	if err := (Scorer{Gravity: -1}).Validate(); err == nil {
+		t.Error("negative gravity accepted")
+	}
+	if err := s.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+// hnFront is a front-page-like mix at the same age: a discussion-heavy story, a
+// link that collected points but little talk, and one in between.
+var hnFront = []model.NewsItem{
+	{ID: "discussion", Points: 50, Replies: 300},
+	{ID: "link", Points: 120, Replies: 5},
+	{ID: "middle", Points: 90, Replies: 60},
+}
+
+func order(s Scorer, items []model.NewsItem) string {
+	sorted := append([]model.NewsItem(nil), items...)
+	for i := range sorted {
+		sorted[i].CreatedAt = now.Add(-3 * time.Hour)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return s.Score(sorted[i], now) > s.Score(sorted[j], now) })
+	ids := make([]string, len(sorted))
+	for i, it := range sorted {
+		ids[i] = it.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestOrderingBySignal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    Scorer
+		want string
+	}{
+		{"hackernews default", ForSource("hackernews"), "link,middle,discussion"},
+		{"points", Scorer{Signal: SignalPoints}, "link,middle,discussion"},
+		{"replies", Scorer{Signal: SignalReplies}, "discussion,middle,link"},
+		{"blend", Scorer{Signal: SignalBlend}, "discussion,middle,link"},
+		// points + 0.2*comments: the link's points still win, but the thread passes the middle story.
+		{"light comment weight", Scorer{Signal: SignalBlend, ReplyWeight: 0.2}, "link,discussion,middle"},
+		// 4*points + comments: a 300-comment thread still edges out the link.
+		{"heavy point weight", Scorer{Signal: SignalBlend, PointWeight: 4}, "discussion,link,middle"},
+	} {
+		if got := order(tc.s, hnFront); got != tc.want {
+			t.Errorf("%s: order = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRecencyFallback(t *testing.T) {
+	s := ForSource("rss")
+	fresh := model.NewsItem{CreatedAt: now.Add(-time.Hour)}
+	old := model.NewsItem{CreatedAt: now.Add(-48 * time.Hour)}
+	if got, want := s.Score(fresh, now), 1/math.Pow(3, DefaultGravity); got != want {
+		t.Errorf("fresh item without signal = %v, want %v", got, want)
+	}
+	if s.Score(old, now) >= s.Score(fresh, now) {
+		t.Error("older item without signal does not score lower")
+	}
+	discussed := model.NewsItem{Replies: 10, CreatedAt: now.Add(-time.Hour)}
+	if s.Score(discussed, now) <= s.Score(fresh, now) {
+		t.Error("item with replies does not outscore the recency floor")
+	}
+	if ForSource("v2ex").Score(fresh, now) != 0 {
+		t.Error("v2ex item without replies scored")
+	}
+	if !ForSource("v2ex").Merge(Scorer{RecencyFallback: true}).RecencyFallback || !s.Merge(Scorer{Gravity: 1}).RecencyFallback {
+		t.Error("Merge dropped RecencyFallback")
+	}
+	if ForSource("jsonfeed").Score(fresh, now) != s.Score(fresh, now) {
+		t.Error("jsonfeed item without signal does not fall back to recency")
+	}
+	liked := model.NewsItem{Points: 50, CreatedAt: now.Add(-time.Hour)}
+	if yt := ForSource("youtube"); yt.Score(fresh, now) != s.Score(fresh, now) || yt.Score(liked, now) <= yt.Score(fresh, now) {
+		t.Error("youtube video does not rank by likes above the recency floor")
+	}
+	boosted := ForSource("arxiv").Merge(Scorer{RecencyBoost: 5})
+	if got, want := boosted.Score(fresh, now), 5/math.Pow(3, DefaultGravity); got != want {
+		t.Errorf("boosted fresh item = %v, want %v", got, want)
+	}
+	if (Scorer{RecencyBoost: -1}).Validate() == nil {
+		t.Error("negative recency_boost accepted")
+	}
+}
@@ -0,0 +1,175 @@
+// Package reddit reads subreddit listings (/r/<sub>/hot.json) into model.NewsItem.
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/version"
+)
+
+// Source is the model.NewsItem source of Reddit posts.
+const Source = "reddit"
+
+// DefaultBaseURL serves the public JSON listings.
+const DefaultBaseURL = "https://www.reddit.com"
+
+// listingLimit is the number of posts requested per listing, Reddit's maximum.
+const listingLimit = 100
+
+// DefaultUserAgent follows Reddit's requested form, <platform>:<app ID>:<version>;
+// generic agents are throttled much harder.
+func DefaultUserAgent() string {
+	return "go:quaily-journalist:" + version.Version
+}
+
+// RateLimitError is returned when Reddit still answers 429 after the HTTP client's
+// retries. RetryAfter is the wait Reddit asked for, 0 when it did not say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("reddit: rate limited; retry after %s", e.RetryAfter)
+	}
+	return "reddit: rate limited"
+}
+
+// Client is a minimal client of the public Reddit listings.
+type Client struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+// NewClient returns a client for the site at baseURL (empty uses DefaultBaseURL)
+// sending userAgent (empty uses DefaultUserAgent).
+func NewClient(baseURL, userAgent string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	if strings.TrimSpace(userAgent) == "" {
+		userAgent = DefaultUserAgent()
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), userAgent: userAgent, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// post mirrors the listing fields items are built from.
+type post struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	URL         string  `json:"url"`
+	Permalink   string  `json:"permalink"`
+	Subreddit   string  `json:"subreddit"`
+	Ups         int     `json:"ups"`
+	NumComments int     `json:"num_comments"`
+	Selftext    string  `json:"selftext"`
+	Author      string  `json:"author"`
+	CreatedUTC  float64 `json:"created_utc"`
+	Stickied    bool    `json:"stickied"`
+	Promoted    bool    `json:"promoted"`
+}
+
+type listing struct {
+	Data struct {
+		Children []struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// Hot returns the posts on the hot listing of subreddit, leaving out stickied and
+// promoted posts.
+func (c *Client) Hot(ctx context.Context, subreddit string) ([]model.NewsItem, error) {
+	sub := strings.TrimPrefix(strings.TrimSpace(subreddit), "r/")
+	endpoint := fmt.Sprintf("%s/r/%s/hot.json?limit=%d&raw_json=1", c.baseURL, url.PathEscape(sub), listingLimit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitError{RetryAfter: retryAfter(resp.Header)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("reddit r/%s: status %d", sub, resp.StatusCode)
+	}
+	var l listing
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		return nil, fmt.Errorf("reddit r/%s: decode: %w", sub, err)
+	}
+	out := make([]model.NewsItem, 0, len(l.Data.Children))
+	for _, child := range l.Data.Children {
+		if child.Kind != "t3" {
+			continue
+		}
+		var p post
+		if err := json.Unmarshal(child.Data, &p); err != nil {
+			return nil, fmt.Errorf("reddit r/%s: decode post: %w", sub, err)
+		}
+		if p.ID == "" || p.Stickied || p.Promoted {
+			continue
+		}
+		it := p.item(c.baseURL)
+		it.Raw = child.Data
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// item maps a post to a NewsItem. Self posts link their comments page.
+func (p post) item(baseURL string) model.NewsItem {
+	link := strings.TrimSpace(p.URL)
+	if link == "" || strings.HasPrefix(link, "/") {
+		link = baseURL + p.Permalink
+	}
+	sec, frac := math.Modf(p.CreatedUTC)
+	return model.NewsItem{
+		Source:    Source,
+		ID:        p.ID,
+		Title:     strings.TrimSpace(p.Title),
+		URL:       link,
+		NodeName:  p.Subreddit,
+		Replies:   p.NumComments,
+		Points:    p.Ups,
+		CreatedAt: time.Unix(int64(sec), int64(frac*1e9)).UTC(),
+		Content:   strings.TrimSpace(p.Selftext),
+		Author:    p.Author,
+	}
+}
+
+// retryAfter reads the wait from Retry-After, or from Reddit's X-Ratelimit-Reset
+// (seconds until the window resets); 0 when neither is usable.
+func retryAfter(h http.Header) time.Duration {
+	for _, name := range []string{"Retry-After", "X-Ratelimit-Reset"} {
+		if s, err := strconv.ParseFloat(strings.TrimSpace(h.Get(name)), 64); err == nil && s > 0 {
+			return time.Duration(s * float64(time.Second))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package reddit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestHot(t *testing.T) {
+	body, err := os.ReadFile("testdata/hot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/r/golang/hot.json" {
+			http.NotFound(w, r)
+			return
+		}
+		gotUA = r.Header.Get("User-Agent")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	items, err := NewClient(srv.URL, "").Hot(context.Background(), "golang")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Hot = %d items, %v; want 2 (stickied and promoted posts left out)", len(items), err)
+	}
+	if gotUA != DefaultUserAgent() {
+		t.Errorf("User-Agent = %q, want %q", gotUA, DefaultUserAgent())
+	}
+	if !strings.Contains(string(items[0].Raw), `"is_self": false`) {
+		t.Errorf("items[0].Raw = %s, want the post as served", items[0].Raw)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "1g9bb2",
+		Title:     "Go 1.25 ships a new garbage collector",
+		URL:       "https://go.dev/blog/greenteagc",
+		NodeName:  "golang",
+		Replies:   96,
+		Points:    512,
+		CreatedAt: time.Date(2025, 10, 24, 12, 0, 0, 5e8, time.UTC),
+		Author:    "gopher",
+	}, {
+		// A self post without a URL links its comments page.
+		Source:    Source,
+		ID:        "1g9dd4",
+		Title:     "How do you structure large services?",
+		URL:       srv.URL + "/r/golang/comments/1g9dd4/how_do_you_structure_large_services/",
+		NodeName:  "golang",
+		Replies:   31,
+		Points:    48,
+		CreatedAt: time.Date(2025, 10, 24, 13, 0, 0, 0, time.UTC),
+		Content:   "We are at 200k lines & growing.",
+		Author:    "newbie",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+
+	if _, err := NewClient(srv.URL, "").Hot(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Hot on a missing subreddit = %v, want a status error", err)
+	}
+}
+
+func TestHotRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test:agent:1 (by /u/someone)" {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		w.Header().Set("X-Ratelimit-Reset", "42")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "test:agent:1 (by /u/someone)").Hot(context.Background(), "r/golang")
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || rl.RetryAfter != 42*time.Second {
+		t.Fatalf("Hot = %v, want a RateLimitError asking for 42s", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package rss reads RSS 2.0, RSS 1.0 (RDF), and Atom feeds into model.NewsItem.
+package rss
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of feed items.
+const Source = "rss"
+
+// maxFeedBytes caps the feed body read per fetch.
+const maxFeedBytes = 10 << 20
+
+// Client fetches feeds over HTTP.
+type Client struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClient returns a feed client with a 15s timeout.
+func NewClient() *Client {
+	return &Client{client: &http.Client{Timeout: 15 * time.Second}, now: time.Now}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// Fetch reads the feed at feedURL. Items are labeled with node, or with the feed's
+// title when node is empty.
+func (c *Client) Fetch(ctx context.Context, feedURL, node string) ([]model.NewsItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("rss: %s: status %d", feedURL, resp.StatusCode)
+	}
+	items, err := Parse(io.LimitReader(resp.Body, maxFeedBytes), feedURL, node, c.now())
+	if err != nil {
+		return nil, fmt.Errorf("rss: %s: %w", feedURL, err)
+	}
+	return items, nil
+}
+
+// document covers the elements of the three formats that items are built from;
+// only the fields of the format at hand are filled.
+type document struct {
+	XMLName xml.Name
+	// RSS 2.0: <rss><channel><item>
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 keeps items next to the channel; Atom names them entries.
+	Items   []rssItem   `xml:"item"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string `xml:"author"`
+	Comments    string `xml:"http://purl.org/rss/1.0/modules/slash/ comments"`
+	Raw         string `xml:",innerxml"`
+}
+
+type atomEntry struct {
+	Title     string `xml:"title"`
+	ID        string `xml:"id"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Authors []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Raw string `xml:",innerxml"`
+}
+
+// Parse reads a feed document. Item IDs are derived from the guid (Atom id), else
+// the link; items with neither are skipped. Items without a parseable date get
+// now, and relative links are resolved against feedURL. slash:comments, when a
+// feed carries it, becomes the item's replies.
+func Parse(r io.Reader, feedURL, node string, now time.Time) ([]model.NewsItem, error) {
+	var doc document
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	base, _ := url.Parse(feedURL)
+	var out []model.NewsItem
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		items, title := doc.Channel.Items, doc.Channel.Title
+		if len(items) == 0 {
+			items = doc.Items
+		}
+		if node == "" {
+			node = clean(title)
+		}
+		for _, it := range items {
+			link := resolve(base, it.Link)
+			if link == "" && strings.HasPrefix(it.GUID, "http") {
+				link = it.GUID
+			}
+			content := it.Content
+			if strings.TrimSpace(content) == "" {
+				content = it.Description
+			}
+			author := it.Creator
+			if strings.TrimSpace(author) == "" {
+				author = it.Author
+			}
+			replies, _ := strconv.Atoi(strings.TrimSpace(it.Comments))
+			out = appendItem(out, firstNonEmpty(it.GUID, link), model.NewsItem{
+				Title:     clean(it.Title),
+				URL:       link,
+				NodeName:  node,
+				Replies:   replies,
+				CreatedAt: parseDate(firstNonEmpty(it.PubDate, it.Date), now),
+				Content:   strings.TrimSpace(content),
+				Author:    clean(author),
+				Raw:       []byte(strings.TrimSpace(it.Raw)),
+			})
+		}
+	case "feed":
+		if node == "" {
+			node = clean(doc.Title)
+		}
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = resolve(base, l.Href)
+					break
+				}
+			}
+			content := e.Content
+			if strings.TrimSpace(content) == "" {
+				content = e.Summary
+			}
+			author := ""
+			if len(e.Authors) > 0 {
+				author = e.Authors[0].Name
+			}
+			out = appendItem(out, firstNonEmpty(e.ID, link), model.NewsItem{
+				Title:     clean(e.Title),
+				URL:       link,
+				NodeName:  node,
+				CreatedAt: parseDate(firstNonEmpty(e.Published, e.Updated), now),
+				Content:   strings.TrimSpace(content),
+				Author:    clean(author),
+				Raw:       []byte(strings.TrimSpace(e.Raw)),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("parse feed: unknown root element <%s>", doc.XMLName.Local)
+	}
+	return out, nil
+}
+
+// appendItem appends it under an ID derived from key, unless key is empty.
+func appendItem(out []model.NewsItem, key string, it model.NewsItem) []model.NewsItem {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return out
+	}
+	it.Source = Source
+	it.ID = ItemID(key)
+	if it.Title == "" {
+		it.Title = it.URL
+	}
+	return append(out, it)
+}
+
+// ItemID returns the stored ID of a feed item with the given guid or link: a short
+// hash, so IDs stay compact and safe in Redis keys and file names.
+func ItemID(key string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// dateLayouts are the pubDate and Atom date forms seen in the wild.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseDate(s string, now time.Time) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return now.UTC()
+}
+
+func resolve(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" || base == nil {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(u).String()
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// charsetReader accepts feeds declaring UTF-8 or ASCII, which decode as is; other
+// charsets fail the parse.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
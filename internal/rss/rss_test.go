@@ -0,0 +1,108 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+var now = time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+
+func parseFile(t *testing.T, name, feedURL, node string) []model.NewsItem {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	items, err := Parse(f, feedURL, node, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestParseRSS2(t *testing.T) {
+	items := parseFile(t, "rss2.xml", "https://go.dev/blog/feed.xml", "")
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2: %+v", len(items), items)
+	}
+	first := items[0]
+	want := model.NewsItem{
+		Source:    "rss",
+		ID:        ItemID("tag:go.dev,2025:blog/go1.25"),
+		Title:     "Go 1.25 is released",
+		URL:       "https://go.dev/blog/go1.25",
+		NodeName:  "The Go Blog",
+		Replies:   12,
+		CreatedAt: time.Date(2025, 8, 12, 10, 0, 0, 0, time.UTC),
+		Content:   "<p>Today the Go team is happy to release Go 1.25.</p>",
+		Author:    "Dmitri Shuralyov",
+	}
+	if !strings.Contains(string(first.Raw), "<slash:comments>12</slash:comments>") {
+		t.Errorf("first item raw = %q, want the item's XML", first.Raw)
+	}
+	first.Raw = nil
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("first item =\n%+v\nwant\n%+v", first, want)
+	}
+	second := items[1]
+	if second.ID != ItemID("https://go.dev/blog/synctest") || second.URL != "https://go.dev/blog/synctest" {
+		t.Errorf("relative link: ID %s, URL %s", second.ID, second.URL)
+	}
+	if second.Title != "Testing & synctest" || !second.CreatedAt.Equal(now) || second.Content != "<p>Testing concurrent code.</p>" {
+		t.Errorf("second item = %+v", second)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	items := parseFile(t, "atom.xml", "https://example.com/feed.atom", "eng")
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if it := items[0]; it.URL != "https://example.com/posts/queue" || it.NodeName != "eng" || it.Author != "Ada" ||
+		!it.CreatedAt.Equal(time.Date(2025, 10, 20, 6, 30, 0, 0, time.UTC)) || it.Content != "How we moved to a sharded queue." {
+		t.Errorf("first entry = %+v", it)
+	}
+	if it := items[1]; it.ID != ItemID("https://example.com/posts/postmortem") || !it.CreatedAt.Equal(time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)) || it.Content != "<p>What went wrong.</p>" {
+		t.Errorf("second entry = %+v", it)
+	}
+}
+
+func TestParseRejectsOtherDocuments(t *testing.T) {
+	for _, doc := range []string{`<html><body/></html>`, `not xml`, `<?xml version="1.0" encoding="koi8-r"?><rss/>`} {
+		if _, err := Parse(strings.NewReader(doc), "", "", now); err == nil {
+			t.Errorf("Parse(%q) = nil error", doc)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	feed, err := os.ReadFile("testdata/atom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.atom" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(feed)
+	}))
+	defer srv.Close()
+
+	items, err := NewClient().Fetch(context.Background(), srv.URL+"/feed.atom", "")
+	if err != nil || len(items) != 2 || items[0].NodeName != "Example Engineering" {
+		t.Fatalf("Fetch = %+v, %v", items, err)
+	}
+	if _, err := NewClient().Fetch(context.Background(), srv.URL+"/missing", ""); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("missing feed: err = %v", err)
+	}
+}
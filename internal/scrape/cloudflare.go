@@ -13,6 +13,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"quaily-journalist/internal/httpclient"
 )
 
 // CloudflareClient calls Cloudflare Browser Rendering REST API.
@@ -74,13 +76,22 @@ func NewCloudflare(accountID, token string, timeout time.Duration) *CloudflareCl
 	}
 }
 
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *CloudflareClient) WithHTTPClient(hc *http.Client) *CloudflareClient {
+	c2 := *c
+	if hc != nil {
+		c2.http = hc
+	}
+	return &c2
+}
+
 // Scrape fetches title and content for a URL using Cloudflare Browser Rendering.
 func (c *CloudflareClient) Scrape(ctx context.Context, u string) (title, content string, err error) {
 	body, _ := json.Marshal(markdownRequest{
 		URL:                  u,
 		RejectRequestPattern: []string{"/^.*\\.(css)/"},
 	})
-	slog.Info("cloudflare: markdown request", "url", u)
+	slog.Debug("cloudflare: markdown request", "url", u)
 	r, err := c.scrape(ctx, "/markdown", u, body)
 	if err != nil {
 		return "", "", err
@@ -148,7 +159,8 @@ func (c *CloudflareClient) scrape(ctx context.Context, path, u string, body []by
 		return nil, fmt.Errorf("invalid url: %w", err)
 	}
 	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	// Rendering is read-only, so the POST is safe to retry on 429/5xx.
+	req, err := http.NewRequestWithContext(httpclient.Idempotent(ctx), http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,133 @@
+package selection
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"quaily-journalist/internal/model"
+)
+
+// DefaultTitleDedupThreshold is the title similarity at or above which two items
+// are treated as reposts. It is deliberately conservative: short titles that
+// differ in a single meaningful token (e.g., "Linux 6.8" vs "Linux 6.9") stay apart.
+const DefaultTitleDedupThreshold = 0.8
+
+// DedupItems drops repeated URLs and near-duplicate titles. Items are expected in
+// descending score order, so the first (higher-scored) occurrence is kept.
+// threshold is a token-set Jaccard similarity in (0, 1]; 0 uses
+// DefaultTitleDedupThreshold and a negative value disables the title check.
+func DedupItems(items []model.WithScore, threshold float64, channel string) []model.WithScore {
+	if threshold == 0 {
+		threshold = DefaultTitleDedupThreshold
+	}
+	out := make([]model.WithScore, 0, len(items))
+	seenURL := map[string]struct{}{}
+	kept := make([]map[string]struct{}, 0, len(items))
+	for _, ws := range items {
+		if u := normalizeURL(ws.Item.URL); u != "" {
+			if _, dup := seenURL[u]; dup {
+				slog.Debug("dedup: dropped duplicate url", "channel", channel, "item_id", ws.Item.ID, "url", ws.Item.URL)
+				continue
+			}
+			seenURL[u] = struct{}{}
+		}
+		tokens := titleTokens(ws.Item.Title)
+		if threshold > 0 {
+			dup := -1
+			for i, k := range kept {
+				if jaccard(tokens, k) >= threshold {
+					dup = i
+					break
+				}
+			}
+			if dup >= 0 {
+				slog.Debug("dedup: dropped near-duplicate title", "channel", channel, "item_id", ws.Item.ID, "title", ws.Item.Title, "kept", out[dup].Item.Title)
+				continue
+			}
+		}
+		out = append(out, ws)
+		kept = append(kept, tokens)
+	}
+	return out
+}
+
+// normalizeURL reduces a URL to host+path so that scheme, www./m./amp. hosts,
+// AMP path suffixes, tracking parameters, fragments, and trailing slashes do not
+// make the same article look distinct.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, p := range []string{"www.", "m.", "amp."} {
+		host = strings.TrimPrefix(host, p)
+	}
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	path = strings.TrimSuffix(path, "/amp")
+	path = strings.TrimSuffix(path, ".amp")
+	q := u.Query()
+	for k := range q {
+		if strings.HasPrefix(k, "utm_") || k == "amp" || k == "ref" {
+			q.Del(k)
+		}
+	}
+	out := host + path
+	if enc := q.Encode(); enc != "" {
+		out += "?" + enc
+	}
+	return out
+}
+
+var (
+	// HN prefixes and trailing annotations like "[pdf]", "(2019)", "[video]".
+	titlePrefixRe     = regexp.MustCompile(`(?i)^\s*(ask|show|tell|launch)\s+hn\s*[:\-–—]?\s*`)
+	titleAnnotationRe = regexp.MustCompile(`[\[(](?:pdf|video|audio|\d{4})[\])]`)
+)
+
+var titleStopwords = map[string]struct{}{
+	"a": {}, "an": {}, "the": {}, "and": {}, "or": {}, "of": {}, "for": {}, "to": {},
+	"in": {}, "on": {}, "at": {}, "by": {}, "with": {}, "is": {}, "are": {}, "from": {},
+	"your": {}, "how": {}, "why": {}, "what": {},
+}
+
+// titleTokens lower-cases a title, drops HN prefixes, annotations, and stopwords,
+// and splits it into a token set. Dots inside tokens are kept so versions like
+// "6.8" and names like "pgvecto.rs" stay whole.
+func titleTokens(title string) map[string]struct{} {
+	t := strings.ToLower(title)
+	t = titlePrefixRe.ReplaceAllString(t, "")
+	t = titleAnnotationRe.ReplaceAllString(t, " ")
+	fields := strings.FieldsFunc(t, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '.' && r != '+' && r != '#'
+	})
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, ".")
+		if f == "" {
+			continue
+		}
+		if _, stop := titleStopwords[f]; stop {
+			continue
+		}
+		set[f] = struct{}{}
+	}
+	return set
+}
+
+// jaccard returns |a∩b| / |a∪b|, or 0 when both sets are empty.
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
@@ -0,0 +1,80 @@
+package selection
+
+import (
+	"testing"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestTitleSimilarityRepostPairs(t *testing.T) {
+	// Real-world style HN repost pairs that should collapse at the default threshold.
+	reposts := [][2]string{
+		{"Show HN: Pgvecto.rs – a Postgres extension for vector search", "Pgvecto.rs: A Postgres extension for vector similarity search"},
+		{"Python 3.13.0 Is Released", "Python 3.13.0 released"},
+		{"The Rust Foundation announces new security initiative [pdf]", "Rust Foundation Announces New Security Initiative"},
+		{"Ask HN: How do you back up your homelab?", "How do you back up your homelab"},
+		{"Launch HN: Trellis (YC W24) – AI-powered workflows for unstructured data", "Trellis (YC W24) – AI-powered workflows for unstructured data"},
+		{"Why SQLite Uses Bytecode (2024)", "Why SQLite uses bytecode"},
+	}
+	for _, p := range reposts {
+		if s := jaccard(titleTokens(p[0]), titleTokens(p[1])); s < DefaultTitleDedupThreshold {
+			t.Errorf("similarity(%q, %q) = %.2f, want >= %.2f", p[0], p[1], s, DefaultTitleDedupThreshold)
+		}
+	}
+
+	// Distinct stories that share vocabulary must stay apart.
+	distinct := [][2]string{
+		{"Linux 6.8 released", "Linux 6.9 released"},
+		{"Show HN: Foo – a bar", "Foo: a bar for X"},
+		{"Stripe acquires Bridge for $1.1B", "Stripe to acquire stablecoin platform Bridge for $1.1B"},
+		{"Go 1.22 is released", "Go 1.22 range-over-func experiment"},
+		{"Ask HN: Who is hiring? (March 2025)", "Ask HN: Who wants to be hired? (March 2025)"},
+	}
+	for _, p := range distinct {
+		if s := jaccard(titleTokens(p[0]), titleTokens(p[1])); s >= DefaultTitleDedupThreshold {
+			t.Errorf("similarity(%q, %q) = %.2f, want < %.2f", p[0], p[1], s, DefaultTitleDedupThreshold)
+		}
+	}
+}
+
+func TestDedupItemsKeepsHigherScored(t *testing.T) {
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "1", Title: "Python 3.13.0 released", URL: "https://www.python.org/downloads/release/python-3130/"}, Score: 9},
+		{Item: model.NewsItem{ID: "2", Title: "Linux 6.9 released", URL: "https://lkml.org/lkml/2024/5/12/1"}, Score: 8},
+		{Item: model.NewsItem{ID: "3", Title: "What's new in Python 3.13", URL: "http://python.org/downloads/release/python-3130?utm_source=hn"}, Score: 7},
+		{Item: model.NewsItem{ID: "4", Title: "Python 3.13.0 Is Released", URL: "https://blog.python.org/2024/10/python-3130-final-released.html"}, Score: 6},
+		{Item: model.NewsItem{ID: "5", Title: "Linux 6.8 released", URL: "https://lkml.org/lkml/2024/3/10/1"}, Score: 5},
+		{Item: model.NewsItem{ID: "6", Title: "An AMP copy", URL: "https://amp.lkml.org/lkml/2024/5/12/1/amp"}, Score: 4},
+	}
+	got := DedupItems(items, 0, "test")
+	want := []string{"1", "2", "5"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %v", len(got), want)
+	}
+	for i, ws := range got {
+		if ws.Item.ID != want[i] {
+			t.Errorf("got[%d] = %s, want %s", i, ws.Item.ID, want[i])
+		}
+	}
+
+	// A negative threshold keeps near-duplicate titles but still drops repeated URLs.
+	got = DedupItems(items, -1, "test")
+	if len(got) != 4 {
+		t.Errorf("title dedup disabled: got %d items, want 4", len(got))
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://www.example.com/post/":                 "example.com/post",
+		"http://m.example.com/post?utm_source=x#frag":   "example.com/post",
+		"https://example.com/post/amp/":                 "example.com/post",
+		"https://example.com/search?q=go&utm_medium=hn": "example.com/search?q=go",
+		"not a url": "",
+	}
+	for in, want := range cases {
+		if got := normalizeURL(in); got != want {
+			t.Errorf("normalizeURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package selection
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// Exclusion entry prefixes: an entry is "id:<item id>" or "url:<canonical URL>".
+const (
+	excludeIDPrefix  = "id:"
+	excludeURLPrefix = "url:"
+)
+
+// ExclusionEntry turns an item ID or URL given on the command line into an
+// exclusion entry; URLs are stored canonical (see CanonicalURL).
+func ExclusionEntry(idOrURL string) string {
+	s := strings.TrimSpace(idOrURL)
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return excludeURLPrefix + CanonicalURL(s)
+	}
+	return excludeIDPrefix + s
+}
+
+// CanonicalURL normalizes a URL so the same story matches however it is linked:
+// lowercase scheme and host without "www.", no fragment, no trailing slash, and no
+// utm_* tracking parameters. Unparsable URLs are returned trimmed.
+func CanonicalURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Fragment, u.RawFragment = "", ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	q := u.Query()
+	for k := range q {
+		if strings.HasPrefix(strings.ToLower(k), "utm_") {
+			q.Del(k)
+		}
+	}
+	u.RawQuery = q.Encode() // sorted by key
+	return u.String()
+}
+
+// Exclusions is a source's permanent exclusion list, checked by every builder and
+// by generate; see the exclude command.
+type Exclusions struct {
+	ids  map[string]bool
+	urls map[string]bool
+}
+
+// LoadExclusions reads the exclusion list of source.
+func LoadExclusions(ctx context.Context, store *storage.RedisStore, source string) (Exclusions, error) {
+	entries, err := store.Exclusions(ctx, source)
+	if err != nil {
+		return Exclusions{}, err
+	}
+	ex := Exclusions{ids: map[string]bool{}, urls: map[string]bool{}}
+	for _, e := range entries {
+		switch {
+		case strings.HasPrefix(e, excludeIDPrefix):
+			ex.ids[strings.TrimPrefix(e, excludeIDPrefix)] = true
+		case strings.HasPrefix(e, excludeURLPrefix):
+			ex.urls[strings.TrimPrefix(e, excludeURLPrefix)] = true
+		}
+	}
+	return ex, nil
+}
+
+// Excludes reports whether it is on the list, by ID or canonical URL.
+func (ex Exclusions) Excludes(it model.NewsItem) bool {
+	if ex.ids[it.ID] {
+		return true
+	}
+	return it.URL != "" && len(ex.urls) > 0 && ex.urls[CanonicalURL(it.URL)]
+}
+
+// DropExcluded filters out the items on the list.
+func (ex Exclusions) DropExcluded(items []model.WithScore) []model.WithScore {
+	if len(ex.ids) == 0 && len(ex.urls) == 0 {
+		return items
+	}
+	out := make([]model.WithScore, 0, len(items))
+	for _, ws := range items {
+		if !ex.Excludes(ws.Item) {
+			out = append(out, ws)
+		}
+	}
+	return out
+}
+
+// Stage runs DropExcluded as a pipeline stage.
+func (ex Exclusions) Stage() Stage {
+	return Stage{Name: StageExcluded, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		return ex.DropExcluded(items)
+	}}
+}
@@ -0,0 +1,21 @@
+package selection
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://WWW.Example.com/a/b/?utm_source=x&b=2&a=1#frag": "https://example.com/a/b?a=1&b=2",
+		"http://example.com/": "http://example.com",
+		"not a url":           "not a url",
+	} {
+		if got := CanonicalURL(in); got != want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ExclusionEntry(" 12345 "); got != "id:12345" {
+		t.Errorf("ExclusionEntry(id) = %q", got)
+	}
+	if got := ExclusionEntry("https://www.example.com/x/"); got != "url:https://example.com/x" {
+		t.Errorf("ExclusionEntry(url) = %q", got)
+	}
+}
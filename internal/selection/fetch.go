@@ -0,0 +1,67 @@
+package selection
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultFetchDepthFactor times TopN is the default MaxFetchDepth.
+const DefaultFetchDepthFactor = 20
+
+// FetchDepth is how deep into a period's ranking Fetch reads at most.
+func (c Config) FetchDepth() int {
+	switch {
+	case c.MaxFetchDepth < 0:
+		return math.MaxInt
+	case c.MaxFetchDepth > 0:
+		return c.MaxFetchDepth
+	}
+	return max(c.TopN*DefaultFetchDepthFactor, 1)
+}
+
+// Fetched is what Fetch read from a period.
+type Fetched struct {
+	Items   []model.WithScore // the pool after the pool stages, best first
+	Pool    []model.WithScore // every item the batch stages kept, best first
+	Depth   int               // items read from the period
+	Removed Report
+}
+
+// Fetch reads the source's items of period from store in batches until TopN are
+// left, the period is exhausted, or FetchDepth is reached. The first batch is 2×TopN
+// items and each next one doubles the depth read, continuing where the last ended,
+// so heavily filtered channels reach deep enough without unfiltered ones reading
+// more than they need. perBatch ranks and filters each batch as it is read; perPool
+// runs over everything kept so far, best first, after every batch, for stages such
+// as Dedup that compare items with each other.
+func (c Config) Fetch(ctx context.Context, store *storage.RedisStore, period string, perBatch, perPool Pipeline) (Fetched, error) {
+	limit := c.FetchDepth()
+	batch := max(c.TopN*2, 1)
+	var f Fetched
+	var poolRemoved Report
+	for {
+		n := min(batch, limit-f.Depth)
+		raw, err := store.TopNewsRange(ctx, c.Source, period, f.Depth, n)
+		if err != nil {
+			return f, fmt.Errorf("fetch top news of %s %s: %w", c.Source, period, err)
+		}
+		f.Depth += len(raw)
+		ranked, r := perBatch.Run(ctx, raw)
+		f.Removed = f.Removed.Add(r)
+		f.Pool = append(f.Pool, ranked...)
+		sort.SliceStable(f.Pool, func(i, j int) bool { return f.Pool[i].Score > f.Pool[j].Score })
+		// perPool gets a copy, as stages may filter their input in place.
+		f.Items, poolRemoved = perPool.Run(ctx, append([]model.WithScore(nil), f.Pool...))
+		if len(f.Items) >= c.TopN || len(raw) < n || f.Depth >= limit {
+			break
+		}
+		batch = f.Depth
+	}
+	f.Removed = f.Removed.Add(poolRemoved)
+	return f, nil
+}
@@ -0,0 +1,105 @@
+package selection
+
+import (
+	"strings"
+
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/v2ex"
+)
+
+// FilterNodes keeps the items of a channel's nodes, read the way source uses them:
+// item types on Hacker News (see FilterHNTypes), tags on Lobste.rs and Product Hunt
+// (see FilterByTags), and node names or searches elsewhere (see FilterByNodes).
+func FilterNodes(items []model.WithScore, source string, nodes []string) []model.WithScore {
+	switch strings.ToLower(source) {
+	case "hackernews":
+		return FilterHNTypes(items, nodes)
+	case "lobsters", "producthunt":
+		return FilterByTags(items, nodes)
+	}
+	return FilterByNodes(items, nodes)
+}
+
+// FilterByNodes keeps items of the channel's nodes and, for "q:<query>" nodes,
+// items matching the query (see v2ex.MatchesQuery) whatever their node.
+func FilterByNodes(items []model.WithScore, nodes []string) []model.WithScore {
+	if len(nodes) == 0 {
+		return items
+	}
+	set := map[string]struct{}{}
+	var queries []string
+	for _, n := range nodes {
+		if q, ok := v2ex.SearchQuery(n); ok {
+			queries = append(queries, q)
+			continue
+		}
+		set[strings.TrimSpace(strings.ToLower(n))] = struct{}{}
+	}
+	out := make([]model.WithScore, 0, len(items))
+	for _, it := range items {
+		if _, ok := set[strings.ToLower(it.Item.NodeName)]; ok || matchesAnyQuery(it.Item, queries) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func matchesAnyQuery(it model.NewsItem, queries []string) bool {
+	for _, q := range queries {
+		if v2ex.MatchesQuery(it, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTags keeps the Lobste.rs items tagged with any of nodes, or the Product
+// Hunt items with any of them as a topic; their node names list their tags (see
+// lobsters.NodeName). No nodes keeps every item.
+func FilterByTags(items []model.WithScore, nodes []string) []model.WithScore {
+	if len(nodes) == 0 {
+		return items
+	}
+	want := map[string]struct{}{}
+	for _, n := range nodes {
+		want[strings.ToLower(strings.TrimSpace(n))] = struct{}{}
+	}
+	out := make([]model.WithScore, 0, len(items))
+	for _, it := range items {
+		for _, tag := range lobsters.Tags(strings.ToLower(it.Item.NodeName)) {
+			if _, ok := want[tag]; ok {
+				out = append(out, it)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FilterHNTypes keeps the items of the Hacker News item types (ask, show, tell,
+// launch, job, story) among nodes. Nodes naming only lists to poll (top, new, best,
+// ...) keep every item.
+func FilterHNTypes(items []model.WithScore, nodes []string) []model.WithScore {
+	if len(nodes) == 0 {
+		return items
+	}
+	allowed := map[string]struct{}{}
+	for _, n := range nodes {
+		s := strings.ToLower(strings.TrimSpace(n))
+		switch s {
+		case "ask", "show", "tell", "launch", "job", "story":
+			allowed[s] = struct{}{}
+		}
+	}
+	if len(allowed) == 0 {
+		return items
+	}
+	out := make([]model.WithScore, 0, len(items))
+	for _, it := range items {
+		if _, ok := allowed[strings.ToLower(it.Item.NodeName)]; ok {
+			out = append(out, it)
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package selection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"quaily-journalist/internal/model"
+)
+
+// Item orders control how a digest lists the items selected for it.
+const (
+	OrderScore         = "score"         // highest score first (default)
+	OrderChronological = "chronological" // oldest first, by CreatedAt
+	OrderNode          = "node"          // alphabetically by node, by score within a node
+)
+
+// CheckItemOrder reports an unknown item_order; empty means score.
+func CheckItemOrder(order string) error {
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", OrderScore, OrderChronological, OrderNode:
+		return nil
+	}
+	return fmt.Errorf("unknown item_order %q (want score, chronological, or node)", order)
+}
+
+// OrderItems returns the selected items of a digest, which arrive in score order, in
+// the given order. Ties keep their score order.
+func OrderItems(items []model.WithScore, order string) []model.WithScore {
+	var less func(a, b model.NewsItem) bool
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case OrderChronological:
+		less = func(a, b model.NewsItem) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case OrderNode:
+		less = func(a, b model.NewsItem) bool { return strings.ToLower(a.NodeName) < strings.ToLower(b.NodeName) }
+	default:
+		return items
+	}
+	out := append([]model.WithScore(nil), items...)
+	sort.SliceStable(out, func(i, j int) bool { return less(out[i].Item, out[j].Item) })
+	return out
+}
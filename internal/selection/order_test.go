@@ -0,0 +1,42 @@
+package selection
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestOrderItems(t *testing.T) {
+	base := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	// In score order, as the builder selects them.
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "a", NodeName: "rust", CreatedAt: base.Add(5 * time.Hour)}, Score: 9},
+		{Item: model.NewsItem{ID: "b", NodeName: "Go", CreatedAt: base.Add(1 * time.Hour)}, Score: 7},
+		{Item: model.NewsItem{ID: "c", NodeName: "apple", CreatedAt: base.Add(3 * time.Hour)}, Score: 5},
+		{Item: model.NewsItem{ID: "d", NodeName: "go", CreatedAt: base.Add(2 * time.Hour)}, Score: 3},
+	}
+	cases := map[string]string{
+		"":              "a,b,c,d",
+		"score":         "a,b,c,d",
+		"chronological": "b,d,c,a",
+		"Node":          "c,b,d,a", // node names compare case-insensitively; b outscores d
+	}
+	for order, want := range cases {
+		got := OrderItems(items, order)
+		ids := make([]string, len(got))
+		for i, ws := range got {
+			ids[i] = ws.Item.ID
+		}
+		if strings.Join(ids, ",") != want {
+			t.Errorf("OrderItems(%q) = %v, want %s", order, ids, want)
+		}
+	}
+	if items[0].Item.ID != "a" {
+		t.Error("OrderItems reordered its input")
+	}
+	if err := CheckItemOrder("random"); err == nil {
+		t.Error("CheckItemOrder(random) = nil, want error")
+	}
+}
@@ -0,0 +1,60 @@
+package selection
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// PinnedItems loads the items pinned into the channel's next digest, oldest pin
+// first, scored by their score in period (0 when they were not collected in it).
+// Pins ignore the score cutoff, node filter, and skip marks; pins of expired items
+// are removed.
+func PinnedItems(ctx context.Context, store *storage.RedisStore, channel, source, period string) ([]model.WithScore, error) {
+	ids, err := store.PinnedIDs(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.WithScore, 0, len(ids))
+	for _, id := range ids {
+		it, err := store.GetItem(ctx, source, id)
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("pins: pinned item expired; unpinning", "channel", channel, "item_id", id)
+			if _, err := store.UnpinItem(ctx, channel, id); err != nil {
+				slog.Warn("pins: unpin failed", "err", err, "channel", channel, "item_id", id)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		score, _, err := store.ItemScore(ctx, source, period, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, model.WithScore{Item: it, Score: score})
+	}
+	return out, nil
+}
+
+// MergePins returns pins followed by items, without the items that are pinned.
+func MergePins(pins, items []model.WithScore) []model.WithScore {
+	if len(pins) == 0 {
+		return items
+	}
+	pinned := make(map[string]bool, len(pins))
+	out := make([]model.WithScore, 0, len(pins)+len(items))
+	for _, ws := range pins {
+		pinned[ws.Item.ID] = true
+		out = append(out, ws)
+	}
+	for _, ws := range items {
+		if !pinned[ws.Item.ID] {
+			out = append(out, ws)
+		}
+	}
+	return out
+}
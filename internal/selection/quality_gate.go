@@ -0,0 +1,72 @@
+package selection
+
+import (
+	"context"
+	"log/slog"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// QualityGate drops candidate items that an AI judge rates below a threshold
+// against the channel's topic description. Scores are cached per item+channel.
+type QualityGate struct {
+	Summarizer  ai.Summarizer
+	Store       *storage.RedisStore
+	Channel     string
+	Description string
+	Threshold   float64
+	Language    string
+}
+
+// Filter evaluates items in order and keeps those scoring at or above the threshold.
+// Evaluation stops once limit items have been accepted (limit <= 0 evaluates all),
+// since anything further down would be cut by TopN anyway. Items whose scoring
+// fails are kept, so an AI outage degrades to the ungated behavior.
+func (g *QualityGate) Filter(ctx context.Context, items []model.WithScore, limit int) []model.WithScore {
+	if g == nil || g.Summarizer == nil {
+		return items
+	}
+	out := make([]model.WithScore, 0, len(items))
+	dropped := 0
+	for _, ws := range items {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		it := ws.Item
+		score, ok, err := g.Store.GetRelevance(ctx, g.Channel, it.ID)
+		if err != nil {
+			slog.Warn("quality-gate: cache read failed", "err", err, "channel", g.Channel, "item_id", it.ID)
+		}
+		if !ok {
+			score, err = g.Summarizer.ScoreRelevance(ctx, it.Title, it.Content, g.Description, g.Language)
+			if err != nil {
+				slog.Warn("quality-gate: scoring failed; keeping item", "err", err, "channel", g.Channel, "item_id", it.ID)
+				out = append(out, ws)
+				continue
+			}
+			if err := g.Store.SetRelevance(ctx, g.Channel, it.ID, score, 0); err != nil {
+				slog.Warn("quality-gate: cache write failed", "err", err, "channel", g.Channel, "item_id", it.ID)
+			}
+		}
+		if score < g.Threshold {
+			dropped++
+			slog.Info("quality-gate: dropped item", "channel", g.Channel, "item_id", it.ID, "title", it.Title, "relevance", score, "threshold", g.Threshold)
+			continue
+		}
+		out = append(out, ws)
+	}
+	if dropped > 0 {
+		slog.Info("quality-gate: summary", "channel", g.Channel, "kept", len(out), "dropped", dropped)
+	}
+	return out
+}
+
+// Stage runs Filter as a pipeline stage. Since Filter stops at limit accepted
+// items, the items it never evaluated count as removed by it.
+func (g *QualityGate) Stage(limit int) Stage {
+	return Stage{Name: StageQualityGate, Apply: func(ctx context.Context, items []model.WithScore) []model.WithScore {
+		return g.Filter(ctx, items, limit)
+	}}
+}
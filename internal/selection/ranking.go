@@ -0,0 +1,128 @@
+package selection
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// Rescore replaces the items' collector scores with s at now and sorts them by the
+// new score, highest first. Ties keep their previous order.
+func Rescore(items []model.WithScore, s ranking.Scorer, now time.Time) []model.WithScore {
+	out := make([]model.WithScore, len(items))
+	for i, ws := range items {
+		out[i] = model.WithScore{Item: ws.Item, Score: s.Score(ws.Item, now)}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// DropLowSignal keeps the items with a positive score that, except on Hacker News,
+// Lobste.rs, Reddit, GitHub, Product Hunt, RSS, arXiv, Bluesky, Stack Overflow, JSON
+// Feed, and YouTube (where comments or answers may be 0, or do not exist), have at least minReplies
+// replies (posts sharing a Mastodon link); 0 means 1, and a negative minReplies
+// keeps every scored item, e.g., points-only V2EX posts ranked by a blend.
+func DropLowSignal(items []model.WithScore, source string, minReplies int) []model.WithScore {
+	if minReplies == 0 {
+		minReplies = 1
+	}
+	if s := strings.ToLower(source); s == "hackernews" || s == "lobsters" || s == "reddit" || s == "github" || s == "producthunt" || s == "rss" || s == "arxiv" || s == "bluesky" || s == "stackoverflow" || s == "jsonfeed" || s == "youtube" {
+		minReplies = -1
+	}
+	out := make([]model.WithScore, 0, len(items))
+	for _, ws := range items {
+		if ws.Score > 0 && (minReplies < 0 || ws.Item.Replies >= minReplies) {
+			out = append(out, ws)
+		}
+	}
+	return out
+}
+
+// CheckNodeWeights reports node weights that cannot be applied; weights must not be negative.
+func CheckNodeWeights(weights map[string]float64) error {
+	for node, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return fmt.Errorf("node_weights: weight of %q must not be negative", node)
+		}
+	}
+	return nil
+}
+
+// WeightByNode multiplies each item's score by the weight of its node and sorts the
+// items by the weighted score, highest first. Node names match case-insensitively;
+// unknown nodes weigh 1, and a weight of 0 drops the node's items from the digest.
+func WeightByNode(items []model.WithScore, weights map[string]float64) []model.WithScore {
+	if len(weights) == 0 {
+		return items
+	}
+	lower := make(map[string]float64, len(weights))
+	for node, w := range weights {
+		lower[strings.ToLower(strings.TrimSpace(node))] = w
+	}
+	out := make([]model.WithScore, len(items))
+	for i, ws := range items {
+		out[i] = ws
+		if w, ok := lower[strings.ToLower(ws.Item.NodeName)]; ok {
+			out[i].Score = ws.Score * w
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// CheckRepeatPenalty reports a repeat penalty outside 0..1.
+func CheckRepeatPenalty(p float64) error {
+	if p < 0 || p > 1 || math.IsNaN(p) {
+		return fmt.Errorf("repeat_penalty must be between 0 and 1, got %v", p)
+	}
+	return nil
+}
+
+// PenalizeRepeats scales each item's score by (1-penalty)^n, where n is the number
+// of earlier digests that included it (appearances), and sorts the items by the new
+// score, highest first. A penalty of 0 leaves the items unchanged.
+func PenalizeRepeats(items []model.WithScore, appearances map[string]int, penalty float64) []model.WithScore {
+	if penalty <= 0 || len(appearances) == 0 {
+		return items
+	}
+	out := make([]model.WithScore, len(items))
+	for i, ws := range items {
+		out[i] = ws
+		if n := appearances[ws.Item.ID]; n > 0 {
+			out[i].Score = ws.Score * math.Pow(1-penalty, float64(n))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// ApplyRepeatPenalty looks up the channel's appearance counts of items and applies
+// PenalizeRepeats. When the lookup fails the items are returned unchanged.
+func ApplyRepeatPenalty(ctx context.Context, store *storage.RedisStore, channel string, items []model.WithScore, penalty float64) []model.WithScore {
+	if penalty <= 0 {
+		return items
+	}
+	counts, err := store.Appearances(ctx, channel, itemIDs(items))
+	if err != nil {
+		slog.Warn("ranking: read appearances failed; no repeat penalty", "channel", channel, "error", err)
+		return items
+	}
+	return PenalizeRepeats(items, counts, penalty)
+}
+
+// itemIDs returns the IDs of items in order.
+func itemIDs(items []model.WithScore) []string {
+	ids := make([]string, len(items))
+	for i, ws := range items {
+		ids[i] = ws.Item.ID
+	}
+	return ids
+}
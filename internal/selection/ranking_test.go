@@ -0,0 +1,117 @@
+package selection
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+)
+
+func TestRescoreReorders(t *testing.T) {
+	now := time.Now()
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "fresh", Replies: 10, CreatedAt: now.Add(-time.Hour)}, Score: 2},
+		{Item: model.NewsItem{ID: "old", Replies: 100, CreatedAt: now.Add(-72 * time.Hour)}, Score: 1},
+	}
+	got := Rescore(items, ranking.Scorer{Gravity: 0.5}, now)
+	if got[0].Item.ID != "old" || got[0].Score <= got[1].Score {
+		t.Errorf("Rescore order = %s, %s", got[0].Item.ID, got[1].Item.ID)
+	}
+	if items[0].Item.ID != "fresh" || items[0].Score != 2 {
+		t.Error("Rescore modified its input")
+	}
+}
+
+func TestWeightByNode(t *testing.T) {
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "qna-1", NodeName: "qna"}, Score: 9},
+		{Item: model.NewsItem{ID: "create-1", NodeName: "create"}, Score: 4},
+		{Item: model.NewsItem{ID: "other-1", NodeName: "apple"}, Score: 6},
+		{Item: model.NewsItem{ID: "qna-2", NodeName: "qna"}, Score: 1},
+	}
+	ids := func(ws []model.WithScore) []string {
+		out := make([]string, len(ws))
+		for i, w := range ws {
+			out[i] = w.Item.ID
+		}
+		return out
+	}
+
+	// Without weights the stored order stands.
+	if got := ids(WeightByNode(items, nil)); !slices.Equal(got, []string{"qna-1", "create-1", "other-1", "qna-2"}) {
+		t.Errorf("unweighted order = %v", got)
+	}
+	// Boosting create and damping qna reorders; unknown nodes keep their score.
+	got := WeightByNode(items, map[string]float64{"Create": 3, "qna": 0.5})
+	if want := []string{"create-1", "other-1", "qna-1", "qna-2"}; !slices.Equal(ids(got), want) {
+		t.Errorf("weighted order = %v, want %v", ids(got), want)
+	}
+	if got[0].Score != 12 || got[1].Score != 6 || got[2].Score != 4.5 {
+		t.Errorf("weighted scores = %v, %v, %v", got[0].Score, got[1].Score, got[2].Score)
+	}
+	// A zero weight sinks the node to a score of 0, which the builder drops.
+	if got := WeightByNode(items, map[string]float64{"qna": 0}); got[2].Score != 0 || got[3].Score != 0 {
+		t.Errorf("zero weight scores = %v", got)
+	}
+	if err := CheckNodeWeights(map[string]float64{"qna": -1}); err == nil {
+		t.Error("negative weight accepted")
+	}
+}
+
+func TestPenalizeRepeats(t *testing.T) {
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "straggler"}, Score: 10},
+		{Item: model.NewsItem{ID: "fresh"}, Score: 6},
+		{Item: model.NewsItem{ID: "resurgent"}, Score: 40},
+	}
+	seen := map[string]int{"straggler": 2, "resurgent": 2}
+	got := PenalizeRepeats(items, seen, 0.5)
+	ids := []string{got[0].Item.ID, got[1].Item.ID, got[2].Item.ID}
+	// A routine straggler falls behind fresh items; a thread with a real surge stays on top.
+	if !slices.Equal(ids, []string{"resurgent", "fresh", "straggler"}) {
+		t.Errorf("order = %v", ids)
+	}
+	if got[0].Score != 10 || got[2].Score != 2.5 {
+		t.Errorf("scores = %v, %v; want 10, 2.5", got[0].Score, got[2].Score)
+	}
+	if got := PenalizeRepeats(items, seen, 0); got[0].Item.ID != "straggler" || got[0].Score != 10 {
+		t.Error("zero penalty changed the ranking")
+	}
+	if CheckRepeatPenalty(1.5) == nil || CheckRepeatPenalty(-0.1) == nil || CheckRepeatPenalty(1) != nil {
+		t.Error("CheckRepeatPenalty bounds")
+	}
+}
+
+func TestDropLowSignal(t *testing.T) {
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "replies", Replies: 3}, Score: 1},
+		{Item: model.NewsItem{ID: "points-only", Points: 40}, Score: 2},
+		{Item: model.NewsItem{ID: "unscored", Replies: 5}, Score: 0},
+	}
+	for _, c := range []struct {
+		source     string
+		minReplies int
+		want       []string
+	}{
+		{"v2ex", 0, []string{"replies"}},
+		{"v2ex", 4, nil},
+		{"v2ex", -1, []string{"replies", "points-only"}},
+		{"hackernews", 0, []string{"replies", "points-only"}},
+		{"lobsters", 4, []string{"replies", "points-only"}},
+		{"reddit", 4, []string{"replies", "points-only"}},
+		{"github", 0, []string{"replies", "points-only"}},
+		{"producthunt", 0, []string{"replies", "points-only"}},
+		{"arxiv", 0, []string{"replies", "points-only"}},
+		{"mastodon", 0, []string{"replies"}},
+		{"bluesky", 0, []string{"replies", "points-only"}},
+		{"stackoverflow", 0, []string{"replies", "points-only"}},
+		{"jsonfeed", 0, []string{"replies", "points-only"}},
+		{"youtube", 0, []string{"replies", "points-only"}},
+	} {
+		if got := itemIDs(DropLowSignal(items, c.source, c.minReplies)); !slices.Equal(got, c.want) {
+			t.Errorf("DropLowSignal(%s, %d) = %v, want %v", c.source, c.minReplies, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package selection picks a channel's digest items from its stored candidates. The
+// builder, generate, and top read a period with the same Fetch and compose the same
+// stages into a Pipeline, so each filter is written once, and every run reports how
+// many items each stage removed.
+package selection
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// Stage names, as they appear in a Report.
+const (
+	StageRescore       = "rescore"
+	StageNodeWeights   = "node_weights"
+	StageRepeatPenalty = "repeat_penalty"
+	StageExcluded      = "excluded"
+	StageNodes         = "nodes"
+	StageLowSignal     = "low_signal"
+	StageDedup         = "dedup"
+	StageSkipped       = "skipped"
+	StageQualityGate   = "quality_gate"
+)
+
+// Stage is one step of a Pipeline. Apply returns the items it keeps, best first; a
+// ranking stage rescores and reorders them instead.
+type Stage struct {
+	Name  string
+	Apply func(ctx context.Context, items []model.WithScore) []model.WithScore
+}
+
+// Pipeline is an ordered list of stages.
+type Pipeline []Stage
+
+// Run passes items through every stage in order and reports what each removed.
+func (p Pipeline) Run(ctx context.Context, items []model.WithScore) ([]model.WithScore, Report) {
+	r := make(Report, 0, len(p))
+	for _, s := range p {
+		n := len(items)
+		items = s.Apply(ctx, items)
+		r = r.add(s.Name, n-len(items))
+	}
+	return items, r
+}
+
+// Removed is how many items a stage removed.
+type Removed struct {
+	Stage string
+	Count int
+}
+
+// Report lists the items removed per stage, in stage order.
+type Report []Removed
+
+// Add returns r with the counts of o added stage by stage, for callers that run a
+// pipeline over several batches.
+func (r Report) Add(o Report) Report {
+	for _, c := range o {
+		r = r.add(c.Stage, c.Count)
+	}
+	return r
+}
+
+func (r Report) add(stage string, n int) Report {
+	for i := range r {
+		if r[i].Stage == stage {
+			r[i].Count += n
+			return r
+		}
+	}
+	return append(r, Removed{Stage: stage, Count: n})
+}
+
+// Count returns how many items stage removed.
+func (r Report) Count(stage string) int {
+	for _, c := range r {
+		if c.Stage == stage {
+			return c.Count
+		}
+	}
+	return 0
+}
+
+// LogValue logs the stages that removed items as a group, e.g., removed.nodes=3.
+func (r Report) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(r))
+	for _, c := range r {
+		if c.Count != 0 {
+			attrs = append(attrs, slog.Int(c.Stage, c.Count))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Config is what selection needs to know about a channel; see FromChannel.
+type Config struct {
+	Channel             string
+	Source              string // lowercased
+	Nodes               []string
+	MinReplies          int
+	NodeWeights         map[string]float64
+	RepeatPenalty       float64
+	TitleDedupThreshold float64
+	TopN                int
+	ItemOrder           string
+	// MaxFetchDepth caps how many of a period's items Fetch reads; 0 uses
+	// TopN×DefaultFetchDepthFactor, negative reads the whole period.
+	MaxFetchDepth int
+	// Scorer re-ranks stored items for the channel's ranking override; nil keeps
+	// the collector scores.
+	Scorer *ranking.Scorer
+}
+
+// FromChannel returns the selection settings of a channel. The ranking override
+// needs the source defaults, so Scorer is left for the caller to set.
+func FromChannel(ch config.Channel) Config {
+	return Config{
+		Channel:             ch.Name,
+		Source:              ch.Source,
+		Nodes:               ch.Nodes,
+		MinReplies:          ch.Config.MinReplies,
+		NodeWeights:         ch.Config.NodeWeights,
+		RepeatPenalty:       ch.Config.RepeatPenalty,
+		TitleDedupThreshold: ch.Config.TitleDedupThreshold,
+		TopN:                ch.TopN,
+		ItemOrder:           ch.Config.ItemOrder,
+		MaxFetchDepth:       ch.Config.MaxFetchDepth,
+	}
+}
+
+// Check reports settings no stage can apply.
+func (c Config) Check() error {
+	for _, check := range []func() error{
+		func() error { return CheckNodeWeights(c.NodeWeights) },
+		func() error { return CheckRepeatPenalty(c.RepeatPenalty) },
+		func() error { return CheckItemOrder(c.ItemOrder) },
+	} {
+		if err := check(); err != nil {
+			return fmt.Errorf("channel %s: %w", c.Channel, err)
+		}
+	}
+	return nil
+}
+
+// Ranking is the stages scoring stored items for the channel: the ranking override
+// at now, node weights, and the repeat penalty looked up in store.
+func (c Config) Ranking(store *storage.RedisStore, now time.Time) Pipeline {
+	return Pipeline{c.Rescore(now), c.WeightNodes(), c.PenalizeRepeats(store)}
+}
+
+// Filters is the stages dropping items outside the channel's nodes or without signal.
+func (c Config) Filters() Pipeline {
+	return Pipeline{c.FilterNodes(), c.DropLowSignal()}
+}
+
+// Rescore applies Scorer at now; without one it keeps the items as they are.
+func (c Config) Rescore(now time.Time) Stage {
+	return Stage{Name: StageRescore, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		if c.Scorer == nil {
+			return items
+		}
+		return Rescore(items, *c.Scorer, now)
+	}}
+}
+
+// WeightNodes applies WeightByNode with the channel's node weights.
+func (c Config) WeightNodes() Stage {
+	return Stage{Name: StageNodeWeights, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		return WeightByNode(items, c.NodeWeights)
+	}}
+}
+
+// PenalizeRepeats applies ApplyRepeatPenalty with the channel's appearance counts.
+func (c Config) PenalizeRepeats(store *storage.RedisStore) Stage {
+	return Stage{Name: StageRepeatPenalty, Apply: func(ctx context.Context, items []model.WithScore) []model.WithScore {
+		return ApplyRepeatPenalty(ctx, store, c.Channel, items, c.RepeatPenalty)
+	}}
+}
+
+// FilterNodes applies FilterNodes for the channel's source and nodes.
+func (c Config) FilterNodes() Stage {
+	return Stage{Name: StageNodes, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		return FilterNodes(items, c.Source, c.Nodes)
+	}}
+}
+
+// DropLowSignal applies DropLowSignal with the channel's min_replies.
+func (c Config) DropLowSignal() Stage {
+	return Stage{Name: StageLowSignal, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		return DropLowSignal(items, c.Source, c.MinReplies)
+	}}
+}
+
+// Dedup applies DedupItems with the channel's title threshold.
+func (c Config) Dedup() Stage {
+	return Stage{Name: StageDedup, Apply: func(_ context.Context, items []model.WithScore) []model.WithScore {
+		return DedupItems(items, c.TitleDedupThreshold, c.Channel)
+	}}
+}
+
+// DropSkipped applies DropSkipped for the channel, caching skip marks in seen
+// across runs; nil looks every item up.
+func (c Config) DropSkipped(store *storage.RedisStore, seen map[string]bool) Stage {
+	return Stage{Name: StageSkipped, Apply: func(ctx context.Context, items []model.WithScore) []model.WithScore {
+		return DropSkipped(ctx, store, c.Channel, items, seen)
+	}}
+}
+
+// Select returns a digest's items: the first TopN of items, listed in ItemOrder
+// after the leading pinned ones (see MergePins).
+func (c Config) Select(items []model.WithScore, pinned int) []model.WithScore {
+	n := min(len(items), c.TopN)
+	p := min(pinned, n)
+	return append(items[:p:p], OrderItems(items[p:n], c.ItemOrder)...)
+}
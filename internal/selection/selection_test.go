@@ -0,0 +1,187 @@
+package selection
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) *storage.RedisStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return storage.NewRedisStore(rdb)
+}
+
+func scored(ids ...string) []model.WithScore {
+	out := make([]model.WithScore, len(ids))
+	for i, id := range ids {
+		out[i] = model.WithScore{Item: model.NewsItem{ID: id, Title: "Item " + id, NodeName: "go", Replies: 1}, Score: float64(len(ids) - i)}
+	}
+	return out
+}
+
+func TestPipelineReportsRemovedPerStage(t *testing.T) {
+	dropFirst := Stage{Name: "first", Apply: func(_ context.Context, items []model.WithScore) []model.WithScore { return items[1:] }}
+	keep := Stage{Name: "keep", Apply: func(_ context.Context, items []model.WithScore) []model.WithScore { return items }}
+	p := Pipeline{dropFirst, keep, dropFirst}
+	items, r := p.Run(context.Background(), scored("a", "b", "c", "d"))
+	if got := itemIDs(items); !slices.Equal(got, []string{"c", "d"}) {
+		t.Errorf("items = %v", got)
+	}
+	if want := (Report{{"first", 2}, {"keep", 0}}); !slices.Equal(r, want) {
+		t.Errorf("report = %v, want %v", r, want)
+	}
+	total := r.Add(Report{{"keep", 1}, {"other", 3}})
+	if total.Count("first") != 2 || total.Count("keep") != 1 || total.Count("other") != 3 || total.Count("missing") != 0 {
+		t.Errorf("Add = %v", total)
+	}
+	if got := total.LogValue().String(); got != "[first=2 keep=1 other=3]" {
+		t.Errorf("LogValue = %s", got)
+	}
+}
+
+func TestFromChannel(t *testing.T) {
+	c := config.Config{Newsletters: config.NewslettersConfig{Channels: []config.ChannelConfig{{
+		Name: "hn", Source: "HackerNews", TopN: 5, Nodes: []string{"show"}, MinReplies: 2,
+		NodeWeights: map[string]float64{"show": 2}, RepeatPenalty: 0.5, TitleDedupThreshold: 0.9, ItemOrder: "node",
+	}}}}
+	ch, _ := c.FindChannel("hn")
+	got := FromChannel(ch)
+	if got.Channel != "hn" || got.Source != "hackernews" || got.TopN != 5 || got.MinReplies != 2 || got.NodeWeights["show"] != 2 ||
+		got.RepeatPenalty != 0.5 || got.TitleDedupThreshold != 0.9 || got.ItemOrder != "node" || !slices.Equal(got.Nodes, []string{"show"}) {
+		t.Errorf("FromChannel = %+v", got)
+	}
+	if err := got.Check(); err != nil {
+		t.Errorf("Check = %v", err)
+	}
+	got.ItemOrder = "random"
+	if err := got.Check(); err == nil || !strings.HasPrefix(err.Error(), "channel hn: unknown item_order") {
+		t.Errorf("Check = %v, want the item order", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	items := scored("pin", "a", "b", "c")
+	items[1].Item.NodeName, items[2].Item.NodeName = "rust", "c"
+	c := Config{TopN: 3, ItemOrder: OrderNode}
+	if got := itemIDs(c.Select(items, 1)); !slices.Equal(got, []string{"pin", "b", "a"}) {
+		t.Errorf("Select = %v, want the pin first and the rest by node", got)
+	}
+	if got := itemIDs(c.Select(items[:2], 5)); !slices.Equal(got, []string{"pin", "a"}) {
+		t.Errorf("Select(short) = %v", got)
+	}
+}
+
+func TestFilterNodes(t *testing.T) {
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "show", NodeName: "show"}},
+		{Item: model.NewsItem{ID: "story", NodeName: "story"}},
+		{Item: model.NewsItem{ID: "go-sec", NodeName: "go,security"}},
+		{Item: model.NewsItem{ID: "python", NodeName: "Python", Title: "Django tips"}},
+	}
+	for _, c := range []struct {
+		source string
+		nodes  []string
+		want   []string
+	}{
+		{"hackernews", []string{"top", "show"}, []string{"show"}},
+		{"hackernews", []string{"top", "best"}, []string{"show", "story", "go-sec", "python"}},
+		{"lobsters", []string{"Security"}, []string{"go-sec"}},
+		{"producthunt", []string{"go"}, []string{"go-sec"}},
+		{"v2ex", []string{"python"}, []string{"python"}},
+		{"v2ex", []string{"story", "q:django"}, []string{"story", "python"}},
+		{"rss", nil, []string{"show", "story", "go-sec", "python"}},
+	} {
+		if got := itemIDs(FilterNodes(items, c.source, c.nodes)); !slices.Equal(got, c.want) {
+			t.Errorf("FilterNodes(%s, %v) = %v, want %v", c.source, c.nodes, got, c.want)
+		}
+	}
+}
+
+func TestDropSkipped(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	if err := store.MarkSkipped(ctx, "ch", "b", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{"c": true} // cached as skipped
+	got, r := Pipeline{Config{Channel: "ch"}.DropSkipped(store, seen)}.Run(ctx, scored("a", "b", "c"))
+	if ids := itemIDs(got); !slices.Equal(ids, []string{"a"}) || r.Count(StageSkipped) != 2 {
+		t.Errorf("DropSkipped = %v, report %v", ids, r)
+	}
+	if !seen["b"] || seen["a"] {
+		t.Errorf("seen = %v", seen)
+	}
+	if got := itemIDs(DropSkipped(ctx, store, "other", scored("a", "b"), nil)); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("other channel = %v", got)
+	}
+}
+
+func TestExclusionsStage(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	for _, e := range []string{ExclusionEntry("b"), ExclusionEntry("https://www.example.com/c/")} {
+		if _, err := store.AddExclusion(ctx, "v2ex", e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ex, err := LoadExclusions(ctx, store, "v2ex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := scored("a", "b", "c")
+	items[2].Item.URL = "https://example.com/c?utm_source=feed"
+	got, r := Pipeline{ex.Stage()}.Run(ctx, items)
+	if ids := itemIDs(got); !slices.Equal(ids, []string{"a"}) || r.Count(StageExcluded) != 2 {
+		t.Errorf("excluded stage = %v, report %v", ids, r)
+	}
+}
+
+// relevance scores items by title from a map; unknown titles fail.
+type relevance struct {
+	ai.Summarizer
+	scores map[string]float64
+	calls  int
+}
+
+func (s *relevance) ScoreRelevance(ctx context.Context, title, content, description, language string) (float64, error) {
+	s.calls++
+	score, ok := s.scores[title]
+	if !ok {
+		return 0, errors.New("no score")
+	}
+	return score, nil
+}
+
+func TestQualityGateStage(t *testing.T) {
+	ctx := context.Background()
+	sum := &relevance{scores: map[string]float64{"Item a": 0.9, "Item b": 0.1, "Item d": 0.8}}
+	g := &QualityGate{Summarizer: sum, Store: newTestStore(t), Channel: "ch", Threshold: 0.5}
+	// c fails to score and is kept; evaluation stops once two items are accepted.
+	got, r := Pipeline{g.Stage(2)}.Run(ctx, scored("a", "b", "c", "d"))
+	if ids := itemIDs(got); !slices.Equal(ids, []string{"a", "c"}) || r.Count(StageQualityGate) != 2 || sum.calls != 3 {
+		t.Errorf("gate = %v, report %v, %d calls", ids, r, sum.calls)
+	}
+	// Scores are cached: a second run only retries the failed item.
+	g.Stage(0).Apply(ctx, scored("a", "b", "c"))
+	if sum.calls != 4 {
+		t.Errorf("calls = %d after a cached run, want 4", sum.calls)
+	}
+	var off *QualityGate
+	if got := off.Stage(1).Apply(ctx, scored("a", "b")); len(got) != 2 {
+		t.Errorf("nil gate kept %d items", len(got))
+	}
+}
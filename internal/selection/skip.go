@@ -0,0 +1,34 @@
+package selection
+
+import (
+	"context"
+	"log/slog"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// DropSkipped filters out the items the channel marked skipped after an earlier
+// digest, caching lookups in seen (nil caches nothing). An item whose lookup fails
+// is dropped too.
+func DropSkipped(ctx context.Context, store *storage.RedisStore, channel string, items []model.WithScore, seen map[string]bool) []model.WithScore {
+	filtered := make([]model.WithScore, 0, len(items))
+	for _, ws := range items {
+		skip, ok := seen[ws.Item.ID]
+		if !ok {
+			var err error
+			skip, err = store.IsSkipped(ctx, channel, ws.Item.ID)
+			if err != nil {
+				slog.Warn("selection: skip-check failed", "err", err, "channel", channel, "item_id", ws.Item.ID)
+				continue
+			}
+			if seen != nil {
+				seen[ws.Item.ID] = skip
+			}
+		}
+		if !skip {
+			filtered = append(filtered, ws)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,172 @@
+package site
+
+import (
+	"html"
+	"strings"
+)
+
+// markdownToHTML converts the Markdown subset digests are written in: ATX headings,
+// paragraphs, blockquotes, "-"/"*" lists, inline links and images, **strong**,
+// *emphasis*, and `code`. Anything else is kept as escaped text, so the output is
+// safe to embed whatever the input contains.
+func markdownToHTML(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], " \t")
+		trim := strings.TrimSpace(line)
+		switch {
+		case trim == "":
+			i++
+		case strings.HasPrefix(trim, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+			}
+			b.WriteString("<blockquote>\n")
+			b.WriteString(markdownToHTML(strings.Join(quoted, "\n")))
+			b.WriteString("</blockquote>\n")
+		case headingLevel(trim) > 0:
+			n := headingLevel(trim)
+			text := strings.TrimSpace(strings.TrimRight(trim[n:], "# "))
+			b.WriteString("<h" + string(rune('0'+n)) + ">" + inlineHTML(text) + "</h" + string(rune('0'+n)) + ">\n")
+			i++
+		case isListItem(trim):
+			b.WriteString("<ul>\n")
+			for ; i < len(lines) && isListItem(strings.TrimSpace(lines[i])); i++ {
+				b.WriteString("<li>" + inlineHTML(strings.TrimSpace(lines[i])[2:]) + "</li>\n")
+			}
+			b.WriteString("</ul>\n")
+		default:
+			var para []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || strings.HasPrefix(t, ">") || headingLevel(t) > 0 || isListItem(t) {
+					break
+				}
+				para = append(para, t)
+			}
+			b.WriteString("<p>" + inlineHTML(strings.Join(para, "\n")) + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// headingLevel returns n for a line starting with n (1-6) "#" and a space, else 0.
+func headingLevel(line string) int {
+	n := 0
+	for n < len(line) && line[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || n >= len(line) || line[n] != ' ' {
+		return 0
+	}
+	return n
+}
+
+func isListItem(line string) bool {
+	return strings.HasPrefix(line, "- ") || (strings.HasPrefix(line, "* ") && !strings.HasSuffix(line, "*"))
+}
+
+// inlineHTML escapes text and renders its links, images, strong, emphasis, and code spans.
+func inlineHTML(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "!["):
+			if text, url, n, ok := linkAt(s[i+1:]); ok {
+				b.WriteString(`<img src="` + html.EscapeString(safeURL(url)) + `" alt="` + html.EscapeString(text) + `">`)
+				i += 1 + n
+				continue
+			}
+		case s[i] == '[':
+			if text, url, n, ok := linkAt(s[i:]); ok {
+				b.WriteString(`<a href="` + html.EscapeString(safeURL(url)) + `">` + inlineHTML(text) + `</a>`)
+				i += n
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**"):
+			if end := strings.Index(s[i+2:], "**"); end > 0 {
+				b.WriteString("<strong>" + inlineHTML(s[i+2:i+2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+		case s[i] == '*':
+			if end := strings.IndexByte(s[i+1:], '*'); end > 0 && !strings.Contains(s[i+1:i+1+end], "\n") {
+				b.WriteString("<em>" + inlineHTML(s[i+1:i+1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		case s[i] == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				b.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+		}
+		j := i + 1
+		for j < len(s) && !strings.ContainsRune("![*`", rune(s[j])) {
+			j++
+		}
+		b.WriteString(html.EscapeString(s[i:j]))
+		i = j
+	}
+	return b.String()
+}
+
+// linkAt parses "[text](url)" at the start of s, allowing balanced brackets in the
+// text (e.g., "[Show HN] ...") and balanced parentheses in the URL. n is the length
+// consumed.
+func linkAt(s string) (text, url string, n int, ok bool) {
+	depth := 0
+	closeText := -1
+	for i := 0; i < len(s) && closeText < 0; i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeText = i
+			}
+		case '\n':
+			return "", "", 0, false
+		}
+	}
+	if closeText < 0 || closeText+1 >= len(s) || s[closeText+1] != '(' {
+		return "", "", 0, false
+	}
+	depth = 0
+	for i := closeText + 1; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				url = strings.TrimSpace(s[closeText+2 : i])
+				if url == "" || strings.ContainsAny(url, " \n") {
+					return "", "", 0, false
+				}
+				return s[1:closeText], url, i + 1, true
+			}
+		case '\n':
+			return "", "", 0, false
+		}
+	}
+	return "", "", 0, false
+}
+
+// safeURL drops URLs with a scheme other than http, https, or mailto (e.g., javascript:).
+func safeURL(u string) string {
+	lower := strings.ToLower(u)
+	if i := strings.IndexByte(lower, ':'); i >= 0 && !strings.ContainsAny(lower[:i], "/?#") {
+		switch lower[:i] {
+		case "http", "https", "mailto":
+		default:
+			return "#"
+		}
+	}
+	return u
+}
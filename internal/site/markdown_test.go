@@ -0,0 +1,33 @@
+package site
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	src := "> Welcome <b>in</b>\n\n*≈3 min of reading*\n\n" +
+		"## [[Show HN] A (tiny) tool](https://example.com/a_(b))\n\n" +
+		"Uses **bold** and `x < y`.\nSecond line.\n\n" +
+		"- one\n- [two](javascript:alert(1))\n\n" +
+		"![cover](cover.webp)\n"
+	got := markdownToHTML(src)
+	for _, want := range []string{
+		"<blockquote>\n<p>Welcome &lt;b&gt;in&lt;/b&gt;</p>\n</blockquote>",
+		"<p><em>≈3 min of reading</em></p>",
+		`<h2><a href="https://example.com/a_(b)">[Show HN] A (tiny) tool</a></h2>`,
+		"<p>Uses <strong>bold</strong> and <code>x &lt; y</code>.\nSecond line.</p>",
+		"<ul>\n<li>one</li>\n<li><a href=\"#\">two</a></li>\n</ul>",
+		`<img src="cover.webp" alt="cover">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestInlineHTMLLeavesUnmatchedMarkers(t *testing.T) {
+	if got := inlineHTML("a * b [c] d"); got != "a * b [c] d" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,438 @@
+// Package site exports a channel's digests as a static website: one HTML page per
+// digest, an index, an Atom feed, and the cover images.
+package site
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/fsutil"
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/newsletter"
+)
+
+//go:embed page.html.tmpl
+var defaultPageTemplate string
+
+//go:embed index.html.tmpl
+var defaultIndexTemplate string
+
+// ManifestFile records, under the output directory, the hash each page was built from.
+const ManifestFile = ".site-manifest.json"
+
+// FeedEntries is how many of the newest digests the Atom feed lists.
+const FeedEntries = 20
+
+// Options configures a build.
+type Options struct {
+	Channel string
+	Title   string // site title; defaults to the channel name
+	// BaseURL is the absolute URL the site is served from. It makes feed links
+	// absolute; without it the feed uses relative links and urn ids.
+	BaseURL string
+	SrcDir  string // the channel's digest directory, <output_dir>/<channel>
+	OutDir  string
+	// PageTemplate and IndexTemplate are html/template files overriding the
+	// built-in ones; empty uses the built-in.
+	PageTemplate  string
+	IndexTemplate string
+}
+
+// Result summarizes a build.
+type Result struct {
+	Channel   string   `json:"channel"`
+	Out       string   `json:"out"`
+	Pages     int      `json:"pages"`
+	Built     []string `json:"built"`     // slugs whose page was (re)written
+	Unchanged int      `json:"unchanged"` // pages skipped because their inputs did not change
+	Removed   []string `json:"removed"`   // slugs whose digest no longer exists
+}
+
+// Page is one digest as the templates see it.
+type Page struct {
+	Slug     string
+	Title    string
+	Date     time.Time
+	Summary  string
+	Cover    string // relative path of the copied cover, or an absolute URL
+	CoverAlt string
+	Body     template.HTML
+
+	src      string // digest file the page is built from
+	coverSrc string // cover image to copy, if any
+}
+
+// pageData and indexData are the template inputs.
+type pageData struct {
+	Page
+	Site siteData
+}
+
+type indexData struct {
+	Site  siteData
+	Pages []Page
+}
+
+type siteData struct {
+	Title   string
+	BaseURL string
+}
+
+// Build writes the site for opts and returns what changed. Pages whose digest,
+// cover, page template, and site settings are unchanged since the last build are
+// left alone; the index and feed are always rewritten.
+func Build(opts Options) (Result, error) {
+	res := Result{Channel: opts.Channel, Out: opts.OutDir, Built: []string{}, Removed: []string{}}
+	if opts.Title == "" {
+		opts.Title = opts.Channel
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	pageSrc, err := templateSource(opts.PageTemplate, defaultPageTemplate)
+	if err != nil {
+		return res, err
+	}
+	indexSrc, err := templateSource(opts.IndexTemplate, defaultIndexTemplate)
+	if err != nil {
+		return res, err
+	}
+	pageTmpl, err := template.New("page").Parse(pageSrc)
+	if err != nil {
+		return res, fmt.Errorf("page template: %w", err)
+	}
+	indexTmpl, err := template.New("index").Parse(indexSrc)
+	if err != nil {
+		return res, fmt.Errorf("index template: %w", err)
+	}
+
+	sources, err := digestFiles(opts.SrcDir, opts.OutDir)
+	if err != nil {
+		return res, err
+	}
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return res, err
+	}
+	old := readManifest(opts.OutDir)
+	manifest := map[string]string{}
+	site := siteData{Title: opts.Title, BaseURL: opts.BaseURL}
+	var pages []Page
+	for _, src := range sources {
+		p, raw, err := loadPage(src)
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", src, err)
+		}
+		if _, dup := manifest[p.Slug]; dup {
+			continue // a part or format already seen under the same slug
+		}
+		hash := pageHash(raw, pageSrc, site, p.coverSrc)
+		manifest[p.Slug] = hash
+		pages = append(pages, p)
+		out := filepath.Join(opts.OutDir, p.Slug+".html")
+		if old[p.Slug] == hash && fileExists(out) {
+			res.Unchanged++
+			continue
+		}
+		if p.coverSrc != "" {
+			if err := copyFile(p.coverSrc, filepath.Join(opts.OutDir, p.Slug, "cover.webp")); err != nil {
+				return res, err
+			}
+		}
+		var buf bytes.Buffer
+		if err := pageTmpl.Execute(&buf, pageData{Page: p, Site: site}); err != nil {
+			return res, fmt.Errorf("render %s: %w", p.Slug, err)
+		}
+		if err := fsutil.WriteFileAtomic(out, buf.Bytes()); err != nil {
+			return res, err
+		}
+		res.Built = append(res.Built, p.Slug)
+	}
+	for slug := range old {
+		if _, ok := manifest[slug]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(opts.OutDir, slug+".html")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return res, err
+		}
+		if err := os.RemoveAll(filepath.Join(opts.OutDir, slug)); err != nil {
+			return res, err
+		}
+		res.Removed = append(res.Removed, slug)
+	}
+	sort.Strings(res.Removed)
+
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].Date.After(pages[j].Date) })
+	res.Pages = len(pages)
+	var buf bytes.Buffer
+	if err := indexTmpl.Execute(&buf, indexData{Site: site, Pages: pages}); err != nil {
+		return res, fmt.Errorf("render index: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(filepath.Join(opts.OutDir, "index.html"), buf.Bytes()); err != nil {
+		return res, err
+	}
+	feed, err := renderFeed(opts, pages)
+	if err != nil {
+		return res, err
+	}
+	if err := fsutil.WriteFileAtomic(filepath.Join(opts.OutDir, "feed.xml"), feed); err != nil {
+		return res, err
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return res, err
+	}
+	return res, fsutil.WriteFileAtomic(filepath.Join(opts.OutDir, ManifestFile), append(b, '\n'))
+}
+
+func templateSource(path, fallback string) (string, error) {
+	if path == "" {
+		return fallback, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read template: %w", err)
+	}
+	return string(b), nil
+}
+
+// digestFiles lists the digest files under dir in every output layout, one per
+// digest: the markdown file, or the json file when no markdown was written. The
+// output directory is skipped when it lives inside dir.
+func digestFiles(dir, outDir string) ([]string, error) {
+	absOut, _ := filepath.Abs(outDir)
+	byBase := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if abs, _ := filepath.Abs(path); abs == absOut {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".md" && ext != ".json" {
+			return nil
+		}
+		base := strings.TrimSuffix(path, ext)
+		if prev, ok := byBase[base]; ok && filepath.Ext(prev) == ".md" {
+			return nil
+		}
+		byBase[base] = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(byBase))
+	for _, p := range byBase {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadPage reads a digest file into a page and returns the raw bytes it was built from.
+func loadPage(path string) (Page, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Page{}, nil, err
+	}
+	md := raw
+	if filepath.Ext(path) == ".json" {
+		var d newsletter.Data
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return Page{}, nil, err
+		}
+		s, err := newsletter.Render(d)
+		if err != nil {
+			return Page{}, nil, err
+		}
+		md = []byte(s)
+	}
+	doc, err := markdown.Parse(bytes.NewReader(md))
+	if err != nil {
+		return Page{}, nil, err
+	}
+	fm := doc.Frontmatter
+	p := Page{
+		Slug:     fmString(fm, "slug"),
+		Title:    fmString(fm, "title"),
+		Summary:  fmString(fm, "summary"),
+		CoverAlt: fmString(fm, "cover_image_alt"),
+		Body:     template.HTML(markdownToHTML(doc.Body)),
+		src:      path,
+	}
+	if p.Slug == "" {
+		p.Slug = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if p.Title == "" {
+		p.Title = p.Slug
+	}
+	p.Date = fmTime(fm["datetime"])
+	if p.Date.IsZero() {
+		if st, err := os.Stat(path); err == nil {
+			p.Date = st.ModTime()
+		}
+	}
+	if cover := filepath.Join(filepath.Dir(path), p.Slug, "cover.webp"); fileExists(cover) {
+		p.coverSrc = cover
+		p.Cover = p.Slug + "/cover.webp"
+	} else if u := fmString(fm, "cover_image_url"); strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") {
+		p.Cover = u
+	}
+	return p, raw, nil
+}
+
+func fmString(fm map[string]any, key string) string {
+	if v, ok := fm[key]; ok && v != nil {
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+	return ""
+}
+
+// fmTime reads a datetime frontmatter value: YAML may have parsed it already, or it
+// is a string in the "2006-01-02 15:04" form digests are written with.
+func fmTime(v any) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case string:
+		for _, layout := range []string{"2006-01-02 15:04", time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, strings.TrimSpace(t)); err == nil {
+				return parsed
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func pageHash(raw []byte, pageTemplate string, site siteData, cover string) string {
+	h := sha256.New()
+	h.Write(raw)
+	fmt.Fprintf(h, "\x00%s\x00%s\x00%s", pageTemplate, site.Title, site.BaseURL)
+	if cover != "" {
+		if st, err := os.Stat(cover); err == nil {
+			fmt.Fprintf(h, "\x00%d\x00%d", st.Size(), st.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func readManifest(dir string) map[string]string {
+	m := map[string]string{}
+	if b, err := os.ReadFile(filepath.Join(dir, ManifestFile)); err == nil {
+		_ = json.Unmarshal(b, &m)
+	}
+	return m
+}
+
+func fileExists(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir()
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(dst, b)
+}
+
+// Atom feed (RFC 4287) elements.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string    `xml:"title"`
+	ID      string    `xml:"id"`
+	Updated string    `xml:"updated"`
+	Link    atomLink  `xml:"link"`
+	Summary string    `xml:"summary,omitempty"`
+	Content atomChunk `xml:"content"`
+}
+
+type atomChunk struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// renderFeed writes the Atom feed of the newest pages; pages are sorted newest first.
+func renderFeed(opts Options, pages []Page) ([]byte, error) {
+	link := func(rel string) string {
+		if opts.BaseURL == "" {
+			return rel
+		}
+		return opts.BaseURL + "/" + rel
+	}
+	id := func(slug string) string {
+		if opts.BaseURL == "" {
+			return "urn:quaily-journalist:" + opts.Channel + ":" + slug
+		}
+		return link(slug + ".html")
+	}
+	feed := atomFeed{
+		Title:  opts.Title,
+		ID:     "urn:quaily-journalist:" + opts.Channel,
+		Author: atomPerson{Name: opts.Title},
+		Links:  []atomLink{{Href: link("feed.xml"), Rel: "self"}, {Href: link("index.html")}},
+	}
+	if opts.BaseURL != "" {
+		feed.ID = opts.BaseURL + "/"
+	}
+	updated := time.Unix(0, 0)
+	for i, p := range pages {
+		if p.Date.After(updated) {
+			updated = p.Date
+		}
+		if i >= FeedEntries {
+			continue
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   p.Title,
+			ID:      id(p.Slug),
+			Updated: p.Date.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: link(p.Slug + ".html")},
+			Summary: p.Summary,
+			Content: atomChunk{Type: "html", Body: string(p.Body)},
+		})
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	b, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
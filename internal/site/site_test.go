@@ -0,0 +1,108 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quaily-journalist/internal/newsletter"
+)
+
+func writeDigest(t *testing.T, dir, slug, datetime string) {
+	t.Helper()
+	md, err := newsletter.Render(newsletter.Data{
+		Title: "Daily " + slug, Slug: slug, Datetime: datetime, ShortSummary: "Short " + slug,
+		Items: []newsletter.Item{{Title: "Item", URL: "https://example.com/1", NodeName: "go"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, slug+".md"), []byte(md), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildIncremental(t *testing.T) {
+	src := t.TempDir()
+	out := filepath.Join(t.TempDir(), "site")
+	writeDigest(t, src, "daily-20251023", "2025-10-23 08:00")
+	writeDigest(t, filepath.Join(src, "2025", "10"), "daily-20251024", "2025-10-24 08:00")
+	coverDir := filepath.Join(src, "2025", "10", "daily-20251024")
+	if err := os.MkdirAll(coverDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(coverDir, "cover.webp"), []byte("img"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Channel: "daily", Title: "Daily Digest", BaseURL: "https://news.example.com/", SrcDir: src, OutDir: out}
+
+	res, err := Build(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pages != 2 || len(res.Built) != 2 || res.Unchanged != 0 {
+		t.Fatalf("first build = %+v", res)
+	}
+	page, err := os.ReadFile(filepath.Join(out, "daily-20251024.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `src="daily-20251024/cover.webp"`) || !strings.Contains(string(page), `<a href="https://example.com/1">Item</a>`) {
+		t.Errorf("page:\n%s", page)
+	}
+	if b, err := os.ReadFile(filepath.Join(out, "daily-20251024", "cover.webp")); err != nil || string(b) != "img" {
+		t.Errorf("cover not copied: %q, %v", b, err)
+	}
+	index, _ := os.ReadFile(filepath.Join(out, "index.html"))
+	if i, j := strings.Index(string(index), "daily-20251024.html"), strings.Index(string(index), "daily-20251023.html"); i < 0 || j < i {
+		t.Errorf("index not newest first:\n%s", index)
+	}
+	feed, _ := os.ReadFile(filepath.Join(out, "feed.xml"))
+	for _, want := range []string{"<id>https://news.example.com/daily-20251024.html</id>", "<updated>2025-10-24T08:00:00Z</updated>", "<summary>Short daily-20251023</summary>"} {
+		if !strings.Contains(string(feed), want) {
+			t.Errorf("feed missing %q:\n%s", want, feed)
+		}
+	}
+
+	writeDigest(t, src, "daily-20251023", "2025-10-23 09:00")
+	res, err = Build(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.Built, ",") != "daily-20251023" || res.Unchanged != 1 {
+		t.Errorf("second build = %+v, want only the edited digest rebuilt", res)
+	}
+
+	if err := os.Remove(filepath.Join(src, "daily-20251023.md")); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Build(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pages != 1 || strings.Join(res.Removed, ",") != "daily-20251023" {
+		t.Errorf("third build = %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(out, "daily-20251023.html")); !os.IsNotExist(err) {
+		t.Errorf("removed digest's page still exists: %v", err)
+	}
+}
+
+func TestBuildSkipsNestedOutDir(t *testing.T) {
+	src := t.TempDir()
+	writeDigest(t, src, "daily-20251024", "2025-10-24 08:00")
+	out := filepath.Join(src, "site")
+	for i := 0; i < 2; i++ {
+		res, err := Build(Options{Channel: "daily", SrcDir: src, OutDir: out})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Pages != 1 {
+			t.Fatalf("build %d found %d pages", i+1, res.Pages)
+		}
+	}
+}
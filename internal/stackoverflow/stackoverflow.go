@@ -0,0 +1,286 @@
+// Package stackoverflow reads the hot questions of a Stack Exchange site
+// (/2.3/questions?sort=hot) into model.NewsItem.
+package stackoverflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/textclean"
+)
+
+// Source is the model.NewsItem source of Stack Overflow questions.
+const Source = "stackoverflow"
+
+// DefaultBaseURL serves the Stack Exchange API.
+const DefaultBaseURL = "https://api.stackexchange.com"
+
+// DefaultSite is the Stack Exchange site questions are read from.
+const DefaultSite = "stackoverflow"
+
+// pageSize is the number of questions requested per call, the API's maximum.
+const pageSize = 100
+
+// maxWait bounds how long Hot sleeps for a backoff the API asked for; a longer one
+// (or a used-up daily quota) fails fast with a RateLimitError instead.
+const maxWait = 2 * time.Minute
+
+// RateLimitError is returned when the API may not be called before Until: the
+// daily quota is used up, the client was throttled, or a backoff is too long to
+// wait for.
+type RateLimitError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("stackoverflow: %s; no requests until %s", e.Reason, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Client is a minimal Stack Exchange API client. Every response may carry a
+// backoff (seconds before the same method may be called again) and the remaining
+// daily quota; the client holds later calls until then, so copies made by
+// WithHTTPClient share that state.
+type Client struct {
+	baseURL string
+	site    string
+	key     string
+	client  *http.Client
+	hold    *hold
+}
+
+// hold is the time before which no request is sent, and why.
+type hold struct {
+	mu     sync.Mutex
+	until  time.Time
+	reason string
+}
+
+// extend moves the end of the hold to until unless it is already later.
+func (h *hold) extend(until time.Time, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until.After(h.until) {
+		h.until, h.reason = until, reason
+	}
+}
+
+// NewClient returns a client for the API at baseURL (empty uses DefaultBaseURL)
+// reading site (empty uses DefaultSite). key is an optional app key, which raises
+// the daily quota from 300 to 10,000 requests.
+func NewClient(baseURL, site, key string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	if strings.TrimSpace(site) == "" {
+		site = DefaultSite
+	}
+	return &Client{
+		baseURL: strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		site:    strings.TrimSpace(site),
+		key:     strings.TrimSpace(key),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		hold:    &hold{},
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// question mirrors the fields of the withbody filter items are built from.
+type question struct {
+	QuestionID   int64    `json:"question_id"`
+	Title        string   `json:"title"`
+	Link         string   `json:"link"`
+	Tags         []string `json:"tags"`
+	Score        int      `json:"score"`
+	AnswerCount  int      `json:"answer_count"`
+	CreationDate int64    `json:"creation_date"`
+	ClosedDate   int64    `json:"closed_date"`
+	Body         string   `json:"body"`
+	Owner        struct {
+		DisplayName string `json:"display_name"`
+	} `json:"owner"`
+}
+
+// wrapper is the common response object, errors included.
+type wrapper struct {
+	Items          []json.RawMessage `json:"items"`
+	Backoff        int               `json:"backoff"`
+	QuotaRemaining *int              `json:"quota_remaining"`
+	ErrorID        int               `json:"error_id"`
+	ErrorName      string            `json:"error_name"`
+	ErrorMessage   string            `json:"error_message"`
+}
+
+// Hot returns the open questions on the site's hot list, tagged tag when it is not
+// empty. Questions are stored under tag, or under their first tag when unfiltered.
+func (c *Client) Hot(ctx context.Context, tag string) ([]model.NewsItem, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	q := url.Values{"order": {"desc"}, "sort": {"hot"}, "site": {c.site}, "pagesize": {strconv.Itoa(pageSize)}, "filter": {"withbody"}}
+	if tag != "" {
+		q.Set("tagged", tag)
+	}
+	if c.key != "" {
+		q.Set("key", c.key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/2.3/questions?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var w wrapper
+	decodeErr := json.NewDecoder(resp.Body).Decode(&w)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || w.ErrorID != 0 {
+		if w.ErrorName == "throttle_violation" {
+			return nil, c.throttled(w.ErrorMessage)
+		}
+		if w.ErrorName != "" {
+			return nil, fmt.Errorf("stackoverflow %s: status %d: %s: %s", c.site, resp.StatusCode, w.ErrorName, w.ErrorMessage)
+		}
+		return nil, fmt.Errorf("stackoverflow %s: status %d", c.site, resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("stackoverflow %s: decode: %w", c.site, decodeErr)
+	}
+	c.limit(w.Backoff, w.QuotaRemaining)
+	out := make([]model.NewsItem, 0, len(w.Items))
+	for _, raw := range w.Items {
+		var qn question
+		if err := json.Unmarshal(raw, &qn); err != nil {
+			return nil, fmt.Errorf("stackoverflow %s: decode question: %w", c.site, err)
+		}
+		if qn.QuestionID == 0 || qn.ClosedDate != 0 {
+			continue
+		}
+		it := qn.item(tag, c.site)
+		it.Raw = raw
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// wait sleeps until the hold ends when that is within maxWait, and returns a
+// RateLimitError without waiting otherwise.
+func (c *Client) wait(ctx context.Context) error {
+	c.hold.mu.Lock()
+	until, reason := c.hold.until, c.hold.reason
+	c.hold.mu.Unlock()
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	if d > maxWait {
+		return &RateLimitError{Until: until, Reason: reason}
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// limit records a successful response's backoff and, once the quota is used up,
+// holds requests until it resets at midnight UTC.
+func (c *Client) limit(backoff int, quotaRemaining *int) {
+	now := time.Now()
+	var until time.Time
+	var reason string
+	if backoff > 0 {
+		until, reason = now.Add(time.Duration(backoff)*time.Second), fmt.Sprintf("asked to back off %ds", backoff)
+	}
+	if quotaRemaining != nil && *quotaRemaining <= 0 {
+		y, m, d := now.UTC().Date()
+		until, reason = time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC), "daily quota used up"
+	}
+	if !until.IsZero() {
+		c.hold.extend(until, reason)
+	}
+}
+
+// throttled holds requests for the wait a throttle_violation names ("... more
+// requests available in 123 seconds"), or a minute when it names none.
+func (c *Client) throttled(message string) error {
+	wait := time.Minute
+	if i := strings.LastIndex(message, " in "); i >= 0 {
+		if n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(message[i+4:]), " seconds")); err == nil && n > 0 {
+			wait = time.Duration(n) * time.Second
+		}
+	}
+	e := &RateLimitError{Until: time.Now().Add(wait), Reason: "throttled"}
+	c.hold.extend(e.Until, e.Reason)
+	return e
+}
+
+// blankLinesRe matches the runs of blank lines left where the body's HTML has line
+// breaks next to paragraphs.
+var blankLinesRe = regexp.MustCompile(`\n{3,}`)
+
+// item maps a question to a NewsItem: its score is the points, its answers the
+// replies, and its body as plain text the content. Titles and names come HTML
+// encoded.
+func (q question) item(tag, site string) model.NewsItem {
+	node := tag
+	if node == "" && len(q.Tags) > 0 {
+		node = strings.ToLower(q.Tags[0])
+	}
+	if node == "" {
+		node = site
+	}
+	return model.NewsItem{
+		Source:    Source,
+		ID:        strconv.FormatInt(q.QuestionID, 10),
+		Title:     strings.TrimSpace(html.UnescapeString(q.Title)),
+		URL:       q.Link,
+		NodeName:  node,
+		Replies:   q.AnswerCount,
+		Points:    q.Score,
+		CreatedAt: time.Unix(q.CreationDate, 0).UTC(),
+		Content:   blankLinesRe.ReplaceAllString(textclean.StripHTML(q.Body), "\n\n"),
+		Author:    html.UnescapeString(q.Owner.DisplayName),
+	}
+}
+
+// SiteURL returns the web address of a Stack Exchange site parameter:
+// stackoverflow.com and the other sites with their own domain, <site>.com, and
+// <site>.stackexchange.com for the rest; a site given as a domain is kept.
+func SiteURL(site string) string {
+	site = strings.ToLower(strings.TrimSpace(site))
+	switch {
+	case site == "":
+		site = DefaultSite + ".com"
+	case strings.Contains(site, "."):
+	case site == "stackoverflow" || site == "serverfault" || site == "superuser" || site == "askubuntu" || site == "stackapps":
+		site += ".com"
+	default:
+		site += ".stackexchange.com"
+	}
+	return "https://" + site
+}
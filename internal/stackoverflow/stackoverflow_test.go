@@ -0,0 +1,135 @@
+package stackoverflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestHot(t *testing.T) {
+	body, err := os.ReadFile("testdata/questions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagged []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/2.3/questions" || q.Get("sort") != "hot" || q.Get("site") != "stackoverflow" || q.Get("filter") != "withbody" || q.Get("key") != "k" {
+			http.Error(w, `{"error_id":400,"error_name":"bad_parameter","error_message":"unexpected"}`, http.StatusBadRequest)
+			return
+		}
+		tagged = append(tagged, q.Get("tagged"))
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "k")
+	items, err := c.Hot(context.Background(), "Go")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Hot = %d items, %v; want 2 (the closed question left out)", len(items), err)
+	}
+	for i := range items {
+		items[i].Raw = nil
+	}
+	want := []model.NewsItem{{
+		Source:    Source,
+		ID:        "79800001",
+		Title:     "Why can't a generic method have its own type parameters?",
+		URL:       "https://stackoverflow.com/questions/79800001/why-cant-a-generic-method-have-type-parameters",
+		NodeName:  "go",
+		Replies:   4,
+		Points:    27,
+		CreatedAt: time.Date(2025, 10, 24, 10, 0, 0, 0, time.UTC),
+		Content:   "I tried this:\nfunc (s *Set[T]) Map[U any](f func(T) U) *Set[U]\n\nand got methods cannot have type parameters. See the FAQ (https://go.dev/doc/faq).",
+		Author:    "Gopher & Co",
+	}, {
+		Source:    Source,
+		ID:        "79800003",
+		Title:     "When is context.CancelFunc required?",
+		URL:       "https://stackoverflow.com/questions/79800003/context-cancel",
+		NodeName:  "go",
+		Points:    5,
+		CreatedAt: time.Date(2025, 10, 24, 13, 46, 40, 0, time.UTC),
+		Content:   "Is it a leak if I never call cancel?",
+		Author:    "newbie",
+	}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", items, want)
+	}
+
+	// Unfiltered, questions go under their first tag.
+	items, err = c.Hot(context.Background(), "")
+	if err != nil || items[0].NodeName != "go" || tagged[1] != "" {
+		t.Errorf("unfiltered Hot = %+v, %v (tagged %q)", items, err, tagged)
+	}
+}
+
+// A used-up quota or a throttle stops requests until it ends; a short backoff is
+// waited for.
+func TestHotRateLimits(t *testing.T) {
+	var hits atomic.Int32
+	var reply atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(reply.Load().(int))
+		if reply.Load().(int) == http.StatusOK {
+			w.Write([]byte(`{"items": [], "backoff": 10, "quota_remaining": 0}`))
+			return
+		}
+		w.Write([]byte(`{"error_id": 502, "error_name": "throttle_violation", "error_message": "too many requests from this IP, more requests available in 600 seconds"}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	reply.Store(http.StatusOK)
+	c := NewClient(srv.URL, "", "")
+	if _, err := c.Hot(ctx, "go"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.Hot(ctx, "go")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || limited.Reason != "daily quota used up" || limited.Until.Hour() != 0 || hits.Load() != 1 {
+		t.Fatalf("Hot after the quota = %v after %d requests", err, hits.Load())
+	}
+
+	reply.Store(http.StatusBadRequest)
+	c = NewClient(srv.URL, "", "")
+	_, err = c.Hot(ctx, "go")
+	if !errors.As(err, &limited) || limited.Reason != "throttled" || time.Until(limited.Until) < 9*time.Minute {
+		t.Fatalf("throttled Hot = %v", err)
+	}
+	if _, err := c.Hot(ctx, "go"); !errors.As(err, &limited) || hits.Load() != 2 {
+		t.Fatalf("Hot while throttled = %v after %d requests", err, hits.Load())
+	}
+
+	// A 10s backoff is waited for, within the context's deadline.
+	c = NewClient(srv.URL, "", "")
+	c.limit(10, nil)
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Hot(short, "go"); !errors.Is(err, context.DeadlineExceeded) || hits.Load() != 2 {
+		t.Errorf("Hot in a backoff = %v after %d requests", err, hits.Load())
+	}
+}
+
+func TestSiteURL(t *testing.T) {
+	for site, want := range map[string]string{
+		"":                 "https://stackoverflow.com",
+		"StackOverflow":    "https://stackoverflow.com",
+		"superuser":        "https://superuser.com",
+		"unix":             "https://unix.stackexchange.com",
+		"mathoverflow.net": "https://mathoverflow.net",
+	} {
+		if got := SiteURL(site); got != want {
+			t.Errorf("SiteURL(%q) = %q, want %q", site, got, want)
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package storage
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"quaily-journalist/internal/model"
@@ -13,13 +23,37 @@ import (
 )
 
 type RedisStore struct {
-	rdb *redis.Client
+	rdb           *redis.Client
+	compressItems bool
 }
 
 func NewRedisStore(rdb *redis.Client) *RedisStore {
 	return &RedisStore{rdb: rdb}
 }
 
+// WithItemCompression makes AddNews store items gzip-compressed when on. Reads
+// accept both forms either way, so it can be switched at any time.
+func (s *RedisStore) WithItemCompression(on bool) *RedisStore {
+	s.compressItems = on
+	return s
+}
+
+// IsUnavailable reports whether err means Redis could not be reached (refused or
+// reset connections, timeouts, a closed client, or a server still loading its
+// data after a restart) rather than that a command failed.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, redis.ErrClosed):
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "LOADING ")
+}
+
 func periodZKey(source, period string) string {
 	return fmt.Sprintf("news:source:%s:period:%s", source, period)
 }
@@ -32,18 +66,81 @@ func publishedKey(channel, period string) string {
 	return fmt.Sprintf("news:published:%s:%s", channel, period)
 }
 
+func publishMetaKey(channel, period string) string {
+	return fmt.Sprintf("news:publish_meta:%s:%s", channel, period)
+}
+
+func seoKey(channel, period string) string {
+	return fmt.Sprintf("news:seo:%s:%s", channel, period)
+}
+
 func skipKey(channel, id string) string {
 	return fmt.Sprintf("news:skip:%s:%s", channel, id)
 }
 
+func pinsKey(channel string) string {
+	return fmt.Sprintf("news:pins:%s", channel)
+}
+
+func excludeKey(source string) string {
+	return fmt.Sprintf("news:exclude:%s", source)
+}
+
+func counterKey(name string, hour time.Time) string {
+	return fmt.Sprintf("news:counter:%s:%s", name, hour.UTC().Format("2006010215"))
+}
+
+func appearancesKey(channel, id string) string {
+	return fmt.Sprintf("news:appearances:%s:%s", channel, id)
+}
+
+func topCommentKey(source, id string) string {
+	return fmt.Sprintf("news:top_comment:%s:%s", source, id)
+}
+
+func rawKey(source, id string) string {
+	return fmt.Sprintf("news:raw:%s:%s", source, id)
+}
+
+func summaryFailuresKey(source, id string) string {
+	return fmt.Sprintf("news:summary_failures:%s:%s", source, id)
+}
+
+func itemSummaryKey(source, id, language string) string {
+	return fmt.Sprintf("news:item_summary:%s:%s:%s", source, id, strings.ToLower(strings.TrimSpace(language)))
+}
+
+func relevanceKey(channel, id string) string {
+	return fmt.Sprintf("news:relevance:%s:%s", channel, id)
+}
+
+func listSnapshotKey(source, list string) string {
+	return fmt.Sprintf("news:source:%s:list:%s", source, list)
+}
+
+func fetchedKey(source, id string) string {
+	return fmt.Sprintf("news:fetched:%s:%s", source, id)
+}
+
+func quailyHashKey(quailyChannel, slug string) string {
+	return fmt.Sprintf("news:quaily_hash:%s:%s", quailyChannel, slug)
+}
+
 func nodeTitleKey(source, node string) string {
 	return fmt.Sprintf("news:source:%s:node_title:%s", source, node)
 }
 
+func lockKey(name string) string {
+	return fmt.Sprintf("news:lock:%s", name)
+}
+
 // AddNews stores/updates a news item and adds it to the current period sorted set with a score.
 func (s *RedisStore) AddNews(ctx context.Context, source, period string, item model.NewsItem, score float64) error {
+	if item.Source == "" {
+		item.Source = source
+	}
 	// Store item data
-	b, err := json.Marshal(item)
+	b, err := EncodeItem(item, s.compressItems)
 	if err != nil {
 		return err
 	}
@@ -57,7 +154,16 @@ func (s *RedisStore) AddNews(ctx context.Context, source, period string, item mo
 
 // TopNews retrieves the top N items by score for a period and source.
 func (s *RedisStore) TopNews(ctx context.Context, source, period string, n int) ([]model.WithScore, error) {
-	ids, err := s.rdb.ZRevRangeWithScores(ctx, periodZKey(source, period), 0, int64(n-1)).Result()
+	return s.TopNewsRange(ctx, source, period, 0, n)
+}
+
+// TopNewsRange retrieves n items by descending score for a period and source,
+// starting at rank offset (0-based).
+func (s *RedisStore) TopNewsRange(ctx context.Context, source, period string, offset, n int) ([]model.WithScore, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	ids, err := s.rdb.ZRevRangeWithScores(ctx, periodZKey(source, period), int64(offset), int64(offset+n-1)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +174,8 @@ func (s *RedisStore) TopNews(ctx context.Context, source, period string, n int)
 		if err != nil {
 			return nil, err
 		}
-		var it model.NewsItem
-		if err := json.Unmarshal(b, &it); err != nil {
+		it, err := DecodeItem(b, source)
+		if err != nil {
 			return nil, err
 		}
 		out = append(out, model.WithScore{Item: it, Score: z.Score})
@@ -92,6 +198,37 @@ func (s *RedisStore) MarkPublished(ctx context.Context, channel, period string)
 	return s.rdb.Set(ctx, publishedKey(channel, period), "1", 30*24*time.Hour).Err()
 }
 
+// AcquireLock takes the named lock for ttl unless it is held (SET NX), returning the
+// token that releases it; ok is false while another holder has it. The TTL frees
+// the lock of a holder that died without releasing it.
+func (s *RedisStore) AcquireLock(ctx context.Context, name string, ttl time.Duration) (token string, ok bool, err error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	token = hex.EncodeToString(b)
+	ok, err = s.rdb.SetNX(ctx, lockKey(name), token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// releaseLock deletes a lock only while it still holds the caller's token.
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// ReleaseLock releases the named lock if token still holds it, reporting whether it
+// did. A holder whose lock expired and was taken by another cannot release the new
+// holder's lock.
+func (s *RedisStore) ReleaseLock(ctx context.Context, name, token string) (bool, error) {
+	n, err := releaseLock.Run(ctx, s.rdb, []string{lockKey(name)}, token).Int()
+	return n == 1, err
+}
+
 // IsSkipped returns true if the item is marked as skipped for the channel.
 func (s *RedisStore) IsSkipped(ctx context.Context, channel, id string) (bool, error) {
 	_, err := s.rdb.Get(ctx, skipKey(channel, id)).Result()
@@ -112,6 +249,187 @@ func (s *RedisStore) MarkSkipped(ctx context.Context, channel, id string, d time
 	return s.rdb.Set(ctx, skipKey(channel, id), "1", d).Err()
 }
 
+// pinsTTL matches the item TTL: a pin outliving its item could never be included.
+const pinsTTL = 7 * 24 * time.Hour
+
+// PinItem forces an item into the channel's next digest; see PinnedIDs.
+func (s *RedisStore) PinItem(ctx context.Context, channel, id string) error {
+	pipe := s.rdb.TxPipeline()
+	pipe.ZAddNX(ctx, pinsKey(channel), redis.Z{Score: float64(time.Now().UnixNano()), Member: id})
+	pipe.Expire(ctx, pinsKey(channel), pinsTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// UnpinItem removes a pin; it reports whether the item was pinned.
+func (s *RedisStore) UnpinItem(ctx context.Context, channel, id string) (bool, error) {
+	n, err := s.rdb.ZRem(ctx, pinsKey(channel), id).Result()
+	return n > 0, err
+}
+
+// PinnedIDs returns the channel's pinned item IDs, oldest pin first.
+func (s *RedisStore) PinnedIDs(ctx context.Context, channel string) ([]string, error) {
+	return s.rdb.ZRange(ctx, pinsKey(channel), 0, -1).Result()
+}
+
+// AddExclusion adds an entry (an item ID or canonical URL, see selection.ExclusionEntry)
+// to the source's permanent exclusion set; it reports whether the entry is new.
+func (s *RedisStore) AddExclusion(ctx context.Context, source, entry string) (bool, error) {
+	n, err := s.rdb.SAdd(ctx, excludeKey(source), entry).Result()
+	return n > 0, err
+}
+
+// RemoveExclusion removes an entry; it reports whether the entry was present.
+func (s *RedisStore) RemoveExclusion(ctx context.Context, source, entry string) (bool, error) {
+	n, err := s.rdb.SRem(ctx, excludeKey(source), entry).Result()
+	return n > 0, err
+}
+
+// Exclusions returns the source's exclusion entries, sorted.
+func (s *RedisStore) Exclusions(ctx context.Context, source string) ([]string, error) {
+	out, err := s.rdb.SMembers(ctx, excludeKey(source)).Result()
+	sort.Strings(out)
+	return out, err
+}
+
+// counterTTL keeps hourly counter buckets long enough for the daily health report.
+const counterTTL = 48 * time.Hour
+
+// IncrCounter adds n to the named counter's bucket for the hour of at.
+func (s *RedisStore) IncrCounter(ctx context.Context, name string, at time.Time, n int64) error {
+	key := counterKey(name, at)
+	pipe := s.rdb.TxPipeline()
+	pipe.IncrBy(ctx, key, n)
+	pipe.Expire(ctx, key, counterTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SumCounter sums the named counter's hourly buckets from the hour of from up to,
+// but not including, the hour of to.
+func (s *RedisStore) SumCounter(ctx context.Context, name string, from, to time.Time) (int64, error) {
+	var keys []string
+	for h := from.UTC().Truncate(time.Hour); h.Before(to.UTC().Truncate(time.Hour)); h = h.Add(time.Hour) {
+		keys = append(keys, counterKey(name, h))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	vals, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+	var sum int64
+	for _, v := range vals {
+		if str, ok := v.(string); ok {
+			n, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			sum += n
+		}
+	}
+	return sum, nil
+}
+
+// appearancesTTL bounds how long digest appearances are remembered; items
+// themselves expire after a week, so older appearances cannot matter.
+const appearancesTTL = 30 * 24 * time.Hour
+
+// RecordAppearances counts one more published digest of the channel for each item.
+func (s *RedisStore) RecordAppearances(ctx context.Context, channel string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pipe := s.rdb.Pipeline()
+	for _, id := range ids {
+		pipe.Incr(ctx, appearancesKey(channel, id))
+		pipe.Expire(ctx, appearancesKey(channel, id), appearancesTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Appearances returns how many published digests of the channel included each of
+// ids; items never published are absent from the map.
+func (s *RedisStore) Appearances(ctx context.Context, channel string, ids []string) (map[string]int, error) {
+	out := make(map[string]int, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = appearancesKey(channel, id)
+	}
+	vals, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscan(str, &n); err == nil && n > 0 {
+			out[ids[i]] = n
+		}
+	}
+	return out, nil
+}
+
+// RecordSummaryFailure counts one more consecutive failed AI summary of an item and
+// returns the count. The count expires ttl after the latest failure.
+func (s *RedisStore) RecordSummaryFailure(ctx context.Context, source, id string, ttl time.Duration) (int, error) {
+	pipe := s.rdb.Pipeline()
+	incr := pipe.Incr(ctx, summaryFailuresKey(source, id))
+	pipe.Expire(ctx, summaryFailuresKey(source, id), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// SummaryFailures returns how many AI summaries of an item failed in a row; 0 when
+// none did or the count expired.
+func (s *RedisStore) SummaryFailures(ctx context.Context, source, id string) (int, error) {
+	n, err := s.rdb.Get(ctx, summaryFailuresKey(source, id)).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ClearSummaryFailures resets an item's failure count after a successful summary.
+func (s *RedisStore) ClearSummaryFailures(ctx context.Context, source, id string) error {
+	return s.rdb.Del(ctx, summaryFailuresKey(source, id)).Err()
+}
+
+// GetTopComment returns the cached comment pick of an item. ok is false when nothing
+// is cached; a cached pick with an empty Text records that the item had no comment.
+func (s *RedisStore) GetTopComment(ctx context.Context, source, id string) (c model.CommentHighlight, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, topCommentKey(source, id)).Bytes()
+	if err == redis.Nil {
+		return c, false, nil
+	}
+	if err != nil {
+		return c, false, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, false, err
+	}
+	return c, true, nil
+}
+
+// SetTopComment caches the comment pick of an item for ttl.
+func (s *RedisStore) SetTopComment(ctx context.Context, source, id string, c model.CommentHighlight, ttl time.Duration) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, topCommentKey(source, id), b, ttl).Err()
+}
+
 // SetNodeTitle caches a human-friendly node title for a given source/node.
 func (s *RedisStore) SetNodeTitle(ctx context.Context, source, node, title string, ttl time.Duration) error {
 	if strings.TrimSpace(title) == "" {
@@ -134,3 +452,791 @@ func (s *RedisStore) GetNodeTitle(ctx context.Context, source, node string) (str
 	}
 	return res, nil
 }
+
+// ErrNotFound is returned when a requested record is missing or expired, so
+// callers can tell it apart from Redis being unavailable.
+var ErrNotFound = errors.New("storage: not found")
+
+// ItemJSON returns the stored JSON for an item, decompressed if it was stored
+// compressed. Returns ErrNotFound when the item is missing or expired.
+func (s *RedisStore) ItemJSON(ctx context.Context, source, id string) ([]byte, error) {
+	b, err := s.rdb.Get(ctx, itemKey(source, id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return itemPlainJSON(b)
+}
+
+// GetItem loads a single stored item by source and ID; see ItemJSON for errors.
+func (s *RedisStore) GetItem(ctx context.Context, source, id string) (model.NewsItem, error) {
+	b, err := s.ItemJSON(ctx, source, id)
+	if err != nil {
+		return model.NewsItem{}, err
+	}
+	return DecodeItem(b, source)
+}
+
+// itemGzipMagic prefixes item records stored gzip-compressed. Plain records are
+// JSON objects starting with '{', so records of both forms can coexist.
+const itemGzipMagic = 0x01
+
+// EncodeItem returns the stored form of an item: its JSON, or with compress the
+// magic byte followed by the gzip-compressed JSON.
+func EncodeItem(item model.NewsItem, compress bool) ([]byte, error) {
+	b, err := json.Marshal(item)
+	if err != nil || !compress {
+		return b, err
+	}
+	return gzipRecord(b)
+}
+
+// gzipRecord returns b gzip-compressed behind itemGzipMagic.
+func gzipRecord(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(itemGzipMagic)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// itemPlainJSON returns the JSON of a stored item record in either form.
+func itemPlainJSON(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != itemGzipMagic {
+		return b, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("decompress item: %w", err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompress item: %w", err)
+	}
+	return out, nil
+}
+
+// DecodeItem unmarshals a stored item, compressed or not. Records written before
+// NewsItem.Source existed lack it, so the source from the key they were read under
+// is used instead.
+func DecodeItem(b []byte, keySource string) (model.NewsItem, error) {
+	var it model.NewsItem
+	b, err := itemPlainJSON(b)
+	if err != nil {
+		return it, err
+	}
+	if err := json.Unmarshal(b, &it); err != nil {
+		return it, err
+	}
+	if it.Source == "" {
+		it.Source = keySource
+	}
+	return it, nil
+}
+
+// Archived source payloads (sources.archive_raw) are kept this long and only up to
+// this size, uncompressed.
+const (
+	RawTTL      = 48 * time.Hour
+	MaxRawBytes = 256 << 10
+)
+
+// ErrRawTooLarge is returned by SetRaw for payloads above MaxRawBytes.
+var ErrRawTooLarge = errors.New("storage: raw payload too large")
+
+// SetRaw archives an item's source payload, gzip-compressed, for RawTTL.
+func (s *RedisStore) SetRaw(ctx context.Context, source, id string, payload []byte) error {
+	if len(payload) > MaxRawBytes {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrRawTooLarge, len(payload), MaxRawBytes)
+	}
+	b, err := gzipRecord(payload)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, rawKey(source, id), b, RawTTL).Err()
+}
+
+// GetRaw returns an item's archived source payload; ErrNotFound when none was
+// archived or it expired.
+func (s *RedisStore) GetRaw(ctx context.Context, source, id string) ([]byte, error) {
+	b, err := s.rdb.Get(ctx, rawKey(source, id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return itemPlainJSON(b)
+}
+
+// ItemScore returns the score of an item in a period ZSET; ok is false when the item is not in the period.
+func (s *RedisStore) ItemScore(ctx context.Context, source, period, id string) (score float64, ok bool, err error) {
+	score, err = s.rdb.ZScore(ctx, periodZKey(source, period), id).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// CountNews returns the number of items in a period.
+func (s *RedisStore) CountNews(ctx context.Context, source, period string) (int64, error) {
+	return s.rdb.ZCard(ctx, periodZKey(source, period)).Result()
+}
+
+// ItemIDs returns a page of item IDs of a period ordered by descending score.
+// A non-positive limit returns every ID from offset on.
+func (s *RedisStore) ItemIDs(ctx context.Context, source, period string, offset, limit int) ([]string, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(offset + limit - 1)
+	}
+	return s.rdb.ZRevRange(ctx, periodZKey(source, period), int64(offset), stop).Result()
+}
+
+// PeriodItems returns every item of a period ordered by descending score.
+// Members whose item JSON has expired are skipped rather than failing the scan.
+func (s *RedisStore) PeriodItems(ctx context.Context, source, period string) ([]model.WithScore, error) {
+	zs, err := s.rdb.ZRevRangeWithScores(ctx, periodZKey(source, period), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.WithScore, 0, len(zs))
+	for _, z := range zs {
+		id := z.Member.(string)
+		it, err := s.GetItem(ctx, source, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, model.WithScore{Item: it, Score: z.Score})
+	}
+	return out, nil
+}
+
+// itemEventsChannel is the pub/sub channel collectors publish item events to.
+const itemEventsChannel = "news:events:items"
+
+// ItemEvent is the compact notification published after an item is stored.
+type ItemEvent struct {
+	Source   string  `json:"source"`
+	Period   string  `json:"period"`
+	ID       string  `json:"id"`
+	NodeName string  `json:"node_name"`
+	Title    string  `json:"title"`
+	Score    float64 `json:"score"`
+}
+
+// PublishItemEvent announces a stored item to subscribers (e.g., the watch command).
+func (s *RedisStore) PublishItemEvent(ctx context.Context, ev ItemEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Publish(ctx, itemEventsChannel, b).Err()
+}
+
+// SubscribeItemEvents streams item events until ctx is cancelled.
+// Malformed messages are dropped. The returned channel is closed on exit.
+func (s *RedisStore) SubscribeItemEvents(ctx context.Context) (<-chan ItemEvent, error) {
+	sub := s.rdb.Subscribe(ctx, itemEventsChannel)
+	// Wait for the subscription confirmation so connection errors surface here.
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	out := make(chan ItemEvent)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var ev ItemEvent
+				if err := json.Unmarshal([]byte(m.Payload), &ev); err != nil {
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// GetItemSummary returns the cached AI summary of an item in a language; ok is false
+// when none is stored.
+func (s *RedisStore) GetItemSummary(ctx context.Context, source, id, language string) (sum model.ItemSummary, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, itemSummaryKey(source, id, language)).Bytes()
+	if err == redis.Nil {
+		return sum, false, nil
+	}
+	if err != nil {
+		return sum, false, err
+	}
+	if err := json.Unmarshal(b, &sum); err != nil {
+		return sum, false, err
+	}
+	return sum, true, nil
+}
+
+// SetItemSummary caches the AI summary of an item in a language for ttl (0 = 7 days),
+// so the digests of later periods and regenerations reuse it.
+func (s *RedisStore) SetItemSummary(ctx context.Context, source, id, language string, sum model.ItemSummary, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	b, err := json.Marshal(sum)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, itemSummaryKey(source, id, language), b, ttl).Err()
+}
+
+// GetRelevance returns the cached quality-gate score for an item in a channel; ok is false when not cached.
+func (s *RedisStore) GetRelevance(ctx context.Context, channel, id string) (score float64, ok bool, err error) {
+	score, err = s.rdb.Get(ctx, relevanceKey(channel, id)).Float64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// SetRelevance caches a quality-gate score for an item in a channel.
+func (s *RedisStore) SetRelevance(ctx context.Context, channel, id string, score float64, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	return s.rdb.Set(ctx, relevanceKey(channel, id), score, ttl).Err()
+}
+
+// ListSnapshot is the last-seen state of a source list (e.g., HN topstories).
+type ListSnapshot struct {
+	ETag string `json:"etag,omitempty"`
+	IDs  []int  `json:"ids"`
+}
+
+// GetListSnapshot returns the cached list snapshot; ok is false when none is stored.
+func (s *RedisStore) GetListSnapshot(ctx context.Context, source, list string) (snap ListSnapshot, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, listSnapshotKey(source, list)).Bytes()
+	if err == redis.Nil {
+		return snap, false, nil
+	}
+	if err != nil {
+		return snap, false, err
+	}
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return snap, false, err
+	}
+	return snap, true, nil
+}
+
+// SetListSnapshot caches the last-seen state of a source list for a day.
+func (s *RedisStore) SetListSnapshot(ctx context.Context, source, list string, snap ListSnapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, listSnapshotKey(source, list), b, 24*time.Hour).Err()
+}
+
+// FreshItemIDs reports which of ids were fetched from the source within their staleness window.
+func (s *RedisStore) FreshItemIDs(ctx context.Context, source string, ids []string) (map[string]bool, error) {
+	fresh := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return fresh, nil
+	}
+	pipe := s.rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Exists(ctx, fetchedKey(source, id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		if cmds[i].Val() > 0 {
+			fresh[id] = true
+		}
+	}
+	return fresh, nil
+}
+
+// MarkFetched records the fetch time of items; the marker expires after staleness,
+// after which FreshItemIDs no longer reports them and they are fetched again.
+func (s *RedisStore) MarkFetched(ctx context.Context, source string, ids []string, staleness time.Duration) error {
+	if len(ids) == 0 || staleness <= 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	pipe := s.rdb.Pipeline()
+	for _, id := range ids {
+		pipe.Set(ctx, fetchedKey(source, id), now, staleness)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// PublishMeta records where a channel's digest for a period was written and
+// whether it was pushed to Quaily. A period the builder closed without a digest
+// has only Skipped and SkippedAt set.
+type PublishMeta struct {
+	Path              string            `json:"path"`            // absolute path of the primary (first) format
+	Paths             map[string]string `json:"paths,omitempty"` // absolute path per output format
+	Slug              string            `json:"slug"`
+	WrittenAt         time.Time         `json:"written_at"`
+	QuailyPublishedAt *time.Time        `json:"quaily_published_at,omitempty"`
+	TelegramSentAt    *time.Time        `json:"telegram_sent_at,omitempty"`
+	Skipped           string            `json:"skipped,omitempty"` // e.g., "insufficient items (4/5)"
+	SkippedAt         *time.Time        `json:"skipped_at,omitempty"`
+	// Parts lists the further parts of a digest split to fit the content limit;
+	// Path, Paths, and Slug above describe the first part.
+	Parts []PublishPart `json:"parts,omitempty"`
+	// QuailyChannel is the Quaily channel slug the digest went to when it is not the
+	// channel name (preview mode); see QuailySlug.
+	QuailyChannel string `json:"quaily_channel,omitempty"`
+	// ItemIDs lists, in digest order, the items rendered across all parts; they
+	// are the ones marked skipped for the channel.
+	ItemIDs []string `json:"item_ids,omitempty"`
+	// ContentHash is the quaily.FileHash of the first part as last pushed to Quaily.
+	ContentHash string `json:"content_hash,omitempty"`
+	// Title is the title of the first part, shown in the next digest's navigation.
+	Title string `json:"title,omitempty"`
+}
+
+// QuailySlug is the Quaily channel slug the digest of channel is published to.
+func (m PublishMeta) QuailySlug(channel string) string {
+	if m.QuailyChannel != "" {
+		return m.QuailyChannel
+	}
+	return channel
+}
+
+// PublishPart is one further part of a split digest.
+type PublishPart struct {
+	Slug  string            `json:"slug"`
+	Paths map[string]string `json:"paths"`
+}
+
+// GetPublishMeta returns the publish metadata of a period; ok is false when none is stored.
+func (s *RedisStore) GetPublishMeta(ctx context.Context, channel, period string) (meta PublishMeta, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, publishMetaKey(channel, period)).Bytes()
+	if err == redis.Nil {
+		return meta, false, nil
+	}
+	if err != nil {
+		return meta, false, err
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return meta, false, err
+	}
+	return meta, true, nil
+}
+
+// SetPublishMeta stores the publish metadata of a period; it lives as long as the published marker.
+func (s *RedisStore) SetPublishMeta(ctx context.Context, channel, period string, meta PublishMeta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, publishMetaKey(channel, period), b, 30*24*time.Hour).Err()
+}
+
+// GetQuailyHash returns the content hash of the post last pushed to a Quaily channel
+// under slug; it is empty when none is recorded.
+func (s *RedisStore) GetQuailyHash(ctx context.Context, quailyChannel, slug string) (string, error) {
+	h, err := s.rdb.Get(ctx, quailyHashKey(quailyChannel, slug)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return h, err
+}
+
+// SetQuailyHash records the content hash of a post pushed to a Quaily channel; it
+// lives as long as publish metadata.
+func (s *RedisStore) SetQuailyHash(ctx context.Context, quailyChannel, slug, hash string) error {
+	return s.rdb.Set(ctx, quailyHashKey(quailyChannel, slug), hash, 30*24*time.Hour).Err()
+}
+
+// GetDigestSEO returns the cached SEO metadata of a channel's digest for a period;
+// ok is false when none is stored.
+func (s *RedisStore) GetDigestSEO(ctx context.Context, channel, period string) (meta model.SEOMeta, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, seoKey(channel, period)).Bytes()
+	if err == redis.Nil {
+		return meta, false, nil
+	}
+	if err != nil {
+		return meta, false, err
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return meta, false, err
+	}
+	return meta, true, nil
+}
+
+// SetDigestSEO caches the SEO metadata of a period so regenerating the digest does not call the AI again.
+func (s *RedisStore) SetDigestSEO(ctx context.Context, channel, period string, meta model.SEOMeta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, seoKey(channel, period), b, 30*24*time.Hour).Err()
+}
+
+func workerStatusKey(worker string) string {
+	return "worker:status:" + worker
+}
+
+// WorkerStatus is the persisted state of a long-running worker.
+type WorkerStatus struct {
+	Worker    string    `json:"worker"`
+	LastRunAt time.Time `json:"last_run_at"`
+	// LastError is the error of the worker's most recent run; empty after a clean run.
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	// QuietUntil is when the quiet hours that skipped the worker's latest run end, in
+	// the window's timezone; nil once it has run again.
+	QuietUntil *time.Time `json:"quiet_until,omitempty"`
+}
+
+// SetWorkerLastRun records when a worker last ran, which ends any quiet hours
+// recorded with SetWorkerQuietUntil.
+func (s *RedisStore) SetWorkerLastRun(ctx context.Context, worker string, at time.Time) error {
+	if err := s.rdb.HSet(ctx, workerStatusKey(worker), "last_run_at", at.UTC().Format(time.RFC3339Nano)).Err(); err != nil {
+		return err
+	}
+	return s.rdb.HDel(ctx, workerStatusKey(worker), "quiet_until").Err()
+}
+
+// SetWorkerQuietUntil records that a worker skipped its run for quiet hours ending
+// at until; the offset of until is kept so status shows the window's local time.
+func (s *RedisStore) SetWorkerQuietUntil(ctx context.Context, worker string, until time.Time) error {
+	return s.rdb.HSet(ctx, workerStatusKey(worker), "quiet_until", until.Format(time.RFC3339)).Err()
+}
+
+// SetWorkerError records the error of a worker's latest run.
+func (s *RedisStore) SetWorkerError(ctx context.Context, worker, msg string, at time.Time) error {
+	return s.rdb.HSet(ctx, workerStatusKey(worker), "last_error", msg, "last_error_at", at.UTC().Format(time.RFC3339Nano)).Err()
+}
+
+// ClearWorkerError removes a worker's recorded error after a clean run.
+func (s *RedisStore) ClearWorkerError(ctx context.Context, worker string) error {
+	return s.rdb.HDel(ctx, workerStatusKey(worker), "last_error", "last_error_at").Err()
+}
+
+// GetWorkerStatus returns the persisted status of a worker; ok is false when none is stored.
+func (s *RedisStore) GetWorkerStatus(ctx context.Context, worker string) (st WorkerStatus, ok bool, err error) {
+	m, err := s.rdb.HGetAll(ctx, workerStatusKey(worker)).Result()
+	if err != nil || len(m) == 0 {
+		return st, false, err
+	}
+	st.Worker = worker
+	if v := m["last_run_at"]; v != "" {
+		if st.LastRunAt, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return st, false, fmt.Errorf("worker %s: bad last_run_at %q: %w", worker, v, err)
+		}
+	}
+	st.LastError = m["last_error"]
+	if v := m["last_error_at"]; v != "" {
+		at, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return st, false, fmt.Errorf("worker %s: bad last_error_at %q: %w", worker, v, err)
+		}
+		st.LastErrorAt = &at
+	}
+	if v := m["quiet_until"]; v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return st, false, fmt.Errorf("worker %s: bad quiet_until %q: %w", worker, v, err)
+		}
+		st.QuietUntil = &until
+	}
+	return st, true, nil
+}
+
+// WorkerStatuses returns the persisted status of every worker, sorted by name.
+func (s *RedisStore) WorkerStatuses(ctx context.Context) ([]WorkerStatus, error) {
+	var names []string
+	iter := s.rdb.Scan(ctx, 0, workerStatusKey("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		names = append(names, strings.TrimPrefix(iter.Val(), workerStatusKey("")))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	out := make([]WorkerStatus, 0, len(names))
+	for _, n := range names {
+		st, ok, err := s.GetWorkerStatus(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, st)
+		}
+	}
+	return out, nil
+}
+
+// Delivery task states.
+const (
+	DeliveryPending = "pending"
+	DeliveryDone    = "done"
+	DeliveryDead    = "dead"
+)
+
+// deliveriesDueKey is a sorted set of pending delivery tasks scored by next attempt time.
+const deliveriesDueKey = "news:deliveries:due"
+
+// Delivery targets. Quaily tasks predate targets, so an empty Target means Quaily.
+const (
+	TargetQuaily   = "quaily"
+	TargetEmail    = "email"
+	TargetTelegram = "telegram"
+	TargetDiscord  = "discord"
+	TargetSlack    = "slack"
+)
+
+// deliveryKey is news:delivery:<channel>:<slug> for Quaily tasks and
+// news:delivery:<channel>:<slug>:<target> for other targets.
+func deliveryKey(channel, slug, target string) string {
+	if target == "" || target == TargetQuaily {
+		return fmt.Sprintf("news:delivery:%s:%s", channel, slug)
+	}
+	return fmt.Sprintf("news:delivery:%s:%s:%s", channel, slug, target)
+}
+
+// DeliveryTask tracks the delivery (send) of a published digest to one target.
+type DeliveryTask struct {
+	Channel string `json:"channel"`
+	Slug    string `json:"slug"`
+	Target  string `json:"target,omitempty"` // TargetQuaily when empty
+	// Title and Paths (absolute path per output format) let targets other than
+	// Quaily rebuild the message on every attempt.
+	Title string            `json:"title,omitempty"`
+	Paths map[string]string `json:"paths,omitempty"`
+	// Period is the digest's period, for recording the outcome in its publish metadata.
+	Period string `json:"period,omitempty"`
+	// Progress counts the parts of a multi-message delivery already sent, so a
+	// retry resumes instead of repeating them.
+	Progress int `json:"progress,omitempty"`
+	// QuailyChannel is the Quaily channel slug to deliver to when it is not Channel
+	// (a digest published in preview mode).
+	QuailyChannel string    `json:"quaily_channel,omitempty"`
+	State         string    `json:"state"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SaveDelivery stores a delivery task and keeps the due index in sync with its state.
+// Tasks are kept for 30 days so finished ones remain visible for inspection.
+func (s *RedisStore) SaveDelivery(ctx context.Context, t DeliveryTask) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	member := deliveryKey(t.Channel, t.Slug, t.Target)
+	pipe := s.rdb.TxPipeline()
+	pipe.Set(ctx, member, b, 30*24*time.Hour)
+	if t.State == DeliveryPending {
+		pipe.ZAdd(ctx, deliveriesDueKey, redis.Z{Score: float64(t.NextAttemptAt.Unix()), Member: member})
+	} else {
+		pipe.ZRem(ctx, deliveriesDueKey, member)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetDelivery returns the delivery task of a post to a target; ok is false when none is stored.
+func (s *RedisStore) GetDelivery(ctx context.Context, channel, slug, target string) (t DeliveryTask, ok bool, err error) {
+	return s.getDeliveryByKey(ctx, deliveryKey(channel, slug, target))
+}
+
+func (s *RedisStore) getDeliveryByKey(ctx context.Context, key string) (t DeliveryTask, ok bool, err error) {
+	b, err := s.rdb.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return t, false, nil
+	}
+	if err != nil {
+		return t, false, err
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, false, err
+	}
+	return t, true, nil
+}
+
+// DueDeliveries returns pending tasks whose next attempt is at or before now.
+// Index entries whose task has expired are dropped.
+func (s *RedisStore) DueDeliveries(ctx context.Context, now time.Time) ([]DeliveryTask, error) {
+	keys, err := s.rdb.ZRangeByScore(ctx, deliveriesDueKey, &redis.ZRangeBy{Min: "-inf", Max: fmt.Sprint(now.Unix())}).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DeliveryTask, 0, len(keys))
+	for _, k := range keys {
+		t, ok, err := s.getDeliveryByKey(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			s.rdb.ZRem(ctx, deliveriesDueKey, k)
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Deliveries lists the delivery tasks of a channel (all channels when empty), oldest first.
+func (s *RedisStore) Deliveries(ctx context.Context, channel string) ([]DeliveryTask, error) {
+	// The slug glob also matches the ":<target>" suffix of non-Quaily tasks.
+	pattern := deliveryKey("*", "*", "")
+	if channel != "" {
+		pattern = deliveryKey(channel, "*", "")
+	}
+	var out []DeliveryTask
+	iter := s.rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		t, ok, err := s.getDeliveryByKey(ctx, iter.Val())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, t)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// KeyPrefixes are the Redis key families owned by this app. Add new prefixes here
+// so inspection and cleanup tools (redis keys) can see them.
+var KeyPrefixes = []string{"news:", "worker:status:"}
+
+// isAppKey reports whether key belongs to one of KeyPrefixes.
+func isAppKey(key string) bool {
+	for _, p := range KeyPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanKeys returns up to limit app keys matching the glob pattern (all app keys when
+// empty), using SCAN so large keyspaces are not blocked. Keys outside KeyPrefixes are
+// never returned, whatever the pattern. limit <= 0 means no limit.
+func (s *RedisStore) ScanKeys(ctx context.Context, pattern string, limit int) ([]string, error) {
+	patterns := []string{pattern}
+	if pattern == "" {
+		patterns = patterns[:0]
+		for _, p := range KeyPrefixes {
+			patterns = append(patterns, p+"*")
+		}
+	}
+	var out []string
+	seen := map[string]struct{}{}
+	for _, p := range patterns {
+		iter := s.rdb.Scan(ctx, 0, p, 500).Iterator()
+		for iter.Next(ctx) {
+			k := iter.Val()
+			if _, dup := seen[k]; dup || !isAppKey(k) {
+				continue
+			}
+			seen[k] = struct{}{}
+			out = append(out, k)
+			if limit > 0 && len(out) >= limit {
+				sort.Strings(out)
+				return out, nil
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// KeyInfo describes a stored key. TTL is -1 for keys without expiry; Size is the
+// MEMORY USAGE estimate in bytes, or -1 when the server does not support it.
+type KeyInfo struct {
+	Key  string
+	Type string
+	TTL  time.Duration
+	Size int64
+}
+
+// DescribeKey returns the type, TTL, and approximate size of an app key.
+func (s *RedisStore) DescribeKey(ctx context.Context, key string) (KeyInfo, error) {
+	info := KeyInfo{Key: key, Size: -1}
+	pipe := s.rdb.Pipeline()
+	typ := pipe.Type(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return info, err
+	}
+	info.Type = typ.Val()
+	info.TTL = ttl.Val()
+	if info.TTL < 0 {
+		info.TTL = -1
+	}
+	if n, err := s.rdb.MemoryUsage(ctx, key).Result(); err == nil {
+		info.Size = n
+	}
+	return info, nil
+}
+
+// DeleteKeys unlinks the given app keys in batches and returns how many were removed.
+// Keys outside KeyPrefixes are refused.
+func (s *RedisStore) DeleteKeys(ctx context.Context, keys []string) (int64, error) {
+	for _, k := range keys {
+		if !isAppKey(k) {
+			return 0, fmt.Errorf("refusing to delete non-app key %q", k)
+		}
+	}
+	var n int64
+	for start := 0; start < len(keys); start += 500 {
+		end := min(start+500, len(keys))
+		c, err := s.rdb.Unlink(ctx, keys[start:end]...).Result()
+		n += c
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,372 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewRedisStore(rdb), mr
+}
+
+func TestItemSourceRoundTrip(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	// New records carry their source; AddNews fills it in when the caller did not.
+	if err := store.AddNews(ctx, "hackernews", "daily:20250102", model.NewsItem{Source: "hackernews", ID: "1", Title: "a"}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddNews(ctx, "v2ex", "daily:20250102", model.NewsItem{ID: "2", Title: "b"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]any
+	if err := json.Unmarshal([]byte(mustGet(t, mr, itemKey("v2ex", "2"))), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored["source"] != "v2ex" {
+		t.Errorf("stored record source = %v, want v2ex", stored["source"])
+	}
+	it, err := store.GetItem(ctx, "hackernews", "1")
+	if err != nil || it.Source != "hackernews" {
+		t.Fatalf("GetItem = %+v, %v; want source hackernews", it, err)
+	}
+}
+
+func TestLegacyItemsFallBackToKeySource(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	// A record written before NewsItem.Source existed.
+	legacy := `{"id":"42","title":"Old topic","url":"https://www.v2ex.com/t/42","node_name":"go","replies":7,"points":0,"created_at":"2025-01-02T03:04:05Z","content":""}`
+	if err := mr.Set(itemKey("v2ex", "42"), legacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mr.ZAdd(periodZKey("v2ex", "daily:20250102"), 5, "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := store.GetItem(ctx, "v2ex", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Source != "v2ex" || it.Title != "Old topic" {
+		t.Errorf("GetItem = %+v, want source v2ex from the key", it)
+	}
+	top, err := store.TopNews(ctx, "v2ex", "daily:20250102", 10)
+	if err != nil || len(top) != 1 || top[0].Item.Source != "v2ex" {
+		t.Errorf("TopNews = %+v, %v; want one item with source v2ex", top, err)
+	}
+	all, err := store.PeriodItems(ctx, "v2ex", "daily:20250102")
+	if err != nil || len(all) != 1 || all[0].Item.Source != "v2ex" {
+		t.Errorf("PeriodItems = %+v, %v; want one item with source v2ex", all, err)
+	}
+
+	// An explicit source in the record wins over the key.
+	it, err = DecodeItem([]byte(`{"source":"hackernews","id":"1"}`), "v2ex")
+	if err != nil || it.Source != "hackernews" {
+		t.Errorf("DecodeItem = %+v, %v; want the record's own source", it, err)
+	}
+}
+
+func TestCompressedAndPlainItemsMix(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	period := "daily:20250102"
+	body := strings.Repeat("Compressible topic body. ", 200)
+
+	// "1" is written plain, then compression is switched on for "2".
+	if err := store.AddNews(ctx, "v2ex", period, model.NewsItem{ID: "1", Title: "plain", Content: body}, 1); err != nil {
+		t.Fatal(err)
+	}
+	store.WithItemCompression(true)
+	if err := store.AddNews(ctx, "v2ex", period, model.NewsItem{ID: "2", Title: "packed", Content: body}, 2); err != nil {
+		t.Fatal(err)
+	}
+	plain, packed := mustGet(t, mr, itemKey("v2ex", "1")), mustGet(t, mr, itemKey("v2ex", "2"))
+	if plain[0] != '{' || packed[0] != itemGzipMagic {
+		t.Fatalf("stored prefixes = %q, %q; want '{' and the gzip magic", plain[0], packed[0])
+	}
+	if len(packed) >= len(plain)/4 {
+		t.Errorf("compressed record is %d bytes, plain %d; want much smaller", len(packed), len(plain))
+	}
+
+	top, err := store.TopNews(ctx, "v2ex", period, 10)
+	if err != nil || len(top) != 2 {
+		t.Fatalf("TopNews = %+v, %v; want both items", top, err)
+	}
+	for _, sc := range top {
+		if sc.Item.Content != body || sc.Item.Source != "v2ex" {
+			t.Errorf("item %s read back as %+v", sc.Item.ID, sc.Item)
+		}
+	}
+	it, err := store.GetItem(ctx, "v2ex", "2")
+	if err != nil || it.Title != "packed" {
+		t.Errorf("GetItem = %+v, %v; want the compressed item", it, err)
+	}
+	raw, err := store.ItemJSON(ctx, "v2ex", "2")
+	if err != nil || !json.Valid(raw) {
+		t.Errorf("ItemJSON = %q, %v; want decompressed JSON", raw, err)
+	}
+
+	// Turning compression back off leaves compressed records readable.
+	store.WithItemCompression(false)
+	if it, err := store.GetItem(ctx, "v2ex", "2"); err != nil || it.Content != body {
+		t.Errorf("GetItem after disabling = %+v, %v", it.Title, err)
+	}
+}
+
+func benchItem() model.NewsItem {
+	return model.NewsItem{
+		Source:    "hackernews",
+		ID:        "40000000",
+		Title:     "A reasonably long headline about something on the front page",
+		URL:       "https://example.com/articles/something",
+		Points:    321,
+		Replies:   123,
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Content:   strings.Repeat("Typical article text scraped for summarization, with some variety in it. ", 60),
+	}
+}
+
+func BenchmarkEncodeItem(b *testing.B) {
+	item := benchItem()
+	for _, compress := range []bool{false, true} {
+		name := map[bool]string{false: "plain", true: "gzip"}[compress]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := EncodeItem(item, compress); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeItem(b *testing.B) {
+	item := benchItem()
+	for _, compress := range []bool{false, true} {
+		name := map[bool]string{false: "plain", true: "gzip"}[compress]
+		stored, err := EncodeItem(item, compress)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportMetric(float64(len(stored)), "stored_bytes")
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeItem(stored, "hackernews"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	t.Helper()
+	v, err := mr.Get(key)
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	return v
+}
+
+func TestRawPayloads(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	payload := []byte(`{"id": 1, "title": "t", "content": "` + strings.Repeat("long body ", 2000) + `"}`)
+
+	if err := store.SetRaw(ctx, "v2ex", "1", payload); err != nil {
+		t.Fatal(err)
+	}
+	if stored := mustGet(t, mr, rawKey("v2ex", "1")); stored[0] != itemGzipMagic || len(stored) >= len(payload)/4 {
+		t.Errorf("stored payload is %d bytes of %d; want it compressed", len(stored), len(payload))
+	}
+	if ttl := mr.TTL(rawKey("v2ex", "1")); ttl != RawTTL {
+		t.Errorf("TTL = %v, want %v", ttl, RawTTL)
+	}
+	got, err := store.GetRaw(ctx, "v2ex", "1")
+	if err != nil || string(got) != string(payload) {
+		t.Errorf("GetRaw = %d bytes, %v; want the payload back", len(got), err)
+	}
+
+	// Payloads above the cap are refused rather than cut into invalid JSON.
+	huge := make([]byte, MaxRawBytes+1)
+	if err := store.SetRaw(ctx, "v2ex", "2", huge); !errors.Is(err, ErrRawTooLarge) {
+		t.Errorf("oversize SetRaw = %v, want ErrRawTooLarge", err)
+	}
+	if _, err := store.GetRaw(ctx, "v2ex", "2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRaw of a refused payload = %v, want ErrNotFound", err)
+	}
+}
+
+func TestScanKeysStaysInAppKeyspace(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	for _, k := range []string{"news:item:v2ex:1", "news:item:v2ex:2", "worker:status:hn-collector", "other:app:key", "newsletter:foreign"} {
+		if err := mr.Set(k, "x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mr.SetTTL("news:item:v2ex:1", time.Hour)
+
+	keys, err := store.ScanKeys(ctx, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"news:item:v2ex:1", "news:item:v2ex:2", "worker:status:hn-collector"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("ScanKeys(all) = %v, want %v", keys, want)
+	}
+	// A catch-all pattern still never reaches other apps' keys.
+	keys, _ = store.ScanKeys(ctx, "*", 0)
+	if len(keys) != 3 {
+		t.Errorf("ScanKeys(*) = %v, want only app keys", keys)
+	}
+	if keys, _ = store.ScanKeys(ctx, "news:*", 1); len(keys) != 1 {
+		t.Errorf("ScanKeys with limit 1 = %v", keys)
+	}
+
+	info, err := store.DescribeKey(ctx, "news:item:v2ex:1")
+	if err != nil || info.Type != "string" || info.TTL != time.Hour {
+		t.Errorf("DescribeKey = %+v, %v", info, err)
+	}
+
+	if _, err := store.DeleteKeys(ctx, []string{"other:app:key"}); err == nil {
+		t.Error("DeleteKeys removed a foreign key")
+	}
+	n, err := store.DeleteKeys(ctx, []string{"news:item:v2ex:1", "news:item:v2ex:2"})
+	if err != nil || n != 2 || mr.Exists("news:item:v2ex:1") || !mr.Exists("other:app:key") {
+		t.Errorf("DeleteKeys = %d, %v", n, err)
+	}
+}
+
+func TestItemAccessors(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	const period = "daily:20250102"
+	for i, score := range []float64{3, 9, 1, 5} {
+		it := model.NewsItem{ID: string(rune('a' + i)), Title: "t"}
+		if err := store.AddNews(ctx, "hackernews", period, it, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := store.CountNews(ctx, "hackernews", period); err != nil || n != 4 {
+		t.Errorf("CountNews = %d, %v; want 4", n, err)
+	}
+	if n, err := store.CountNews(ctx, "hackernews", "daily:20990101"); err != nil || n != 0 {
+		t.Errorf("CountNews(empty period) = %d, %v; want 0", n, err)
+	}
+	ids, err := store.ItemIDs(ctx, "hackernews", period, 1, 2)
+	if err != nil || strings.Join(ids, ",") != "d,a" {
+		t.Errorf("ItemIDs(1, 2) = %v, %v; want [d a]", ids, err)
+	}
+	ids, err = store.ItemIDs(ctx, "hackernews", period, 2, 0)
+	if err != nil || strings.Join(ids, ",") != "a,c" {
+		t.Errorf("ItemIDs(2, all) = %v, %v; want [a c]", ids, err)
+	}
+
+	if _, err := store.GetItem(ctx, "hackernews", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetItem(missing) err = %v, want ErrNotFound", err)
+	}
+	mr.Close()
+	if _, err := store.GetItem(ctx, "hackernews", "a"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("GetItem(redis down) err = %v, want a connection error", err)
+	}
+}
+
+func TestAppearances(t *testing.T) {
+	s, mr := newTestStore(t)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := s.RecordAppearances(ctx, "ch", []string{"1", "2"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RecordAppearances(ctx, "ch", []string{"2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordAppearances(ctx, "other", []string{"3"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Appearances(ctx, "ch", []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["1"] != 2 || got["2"] != 3 {
+		t.Errorf("Appearances = %v, want map[1:2 2:3]", got)
+	}
+	if ttl := mr.TTL("news:appearances:ch:1"); ttl <= 0 {
+		t.Errorf("appearance key ttl = %v, want an expiry", ttl)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	s, mr := newTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC)
+	for _, c := range []struct {
+		at time.Time
+		n  int64
+	}{{at, 3}, {at.Add(10 * time.Minute), 2}, {at.Add(-2 * time.Hour), 4}, {at.Add(-30 * time.Hour), 100}} {
+		if err := s.IncrCounter(ctx, "collected:v2ex", c.at, c.n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.SumCounter(ctx, "collected:v2ex", at.Add(-24*time.Hour), at.Add(time.Hour))
+	if err != nil || got != 9 {
+		t.Errorf("SumCounter = %d, %v; want 9", got, err)
+	}
+	if ttl := mr.TTL("news:counter:collected:v2ex:2025010210"); ttl <= 0 {
+		t.Errorf("counter key ttl = %v, want an expiry", ttl)
+	}
+}
+
+func TestLocks(t *testing.T) {
+	s, mr := newTestStore(t)
+	ctx := context.Background()
+	token, ok, err := s.AcquireLock(ctx, "publish:ch:daily:20250102", time.Minute)
+	if err != nil || !ok || token == "" {
+		t.Fatalf("AcquireLock = %q, %v, %v", token, ok, err)
+	}
+	if ttl := mr.TTL("news:lock:publish:ch:daily:20250102"); ttl != time.Minute {
+		t.Errorf("lock ttl = %v", ttl)
+	}
+	if _, ok, _ := s.AcquireLock(ctx, "publish:ch:daily:20250102", time.Minute); ok {
+		t.Error("held lock acquired twice")
+	}
+	if released, err := s.ReleaseLock(ctx, "publish:ch:daily:20250102", "not-the-token"); err != nil || released {
+		t.Errorf("release with a wrong token = %v, %v", released, err)
+	}
+
+	// Once the lock expires another holder takes it, and the first holder's late
+	// release leaves the new lock alone.
+	mr.FastForward(time.Minute)
+	next, ok, err := s.AcquireLock(ctx, "publish:ch:daily:20250102", time.Minute)
+	if err != nil || !ok || next == token {
+		t.Fatalf("AcquireLock after expiry = %q, %v, %v", next, ok, err)
+	}
+	if released, _ := s.ReleaseLock(ctx, "publish:ch:daily:20250102", token); released {
+		t.Error("expired token released the new holder's lock")
+	}
+	if released, err := s.ReleaseLock(ctx, "publish:ch:daily:20250102", next); err != nil || !released {
+		t.Errorf("ReleaseLock = %v, %v", released, err)
+	}
+	if _, ok, _ := s.AcquireLock(ctx, "publish:ch:daily:20250102", time.Minute); !ok {
+		t.Error("released lock not acquirable")
+	}
+}
@@ -0,0 +1,245 @@
+package telegram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Message formats.
+const (
+	FormatMarkdownV2 = "markdownv2" // links and emphasis via parse_mode MarkdownV2 (default)
+	FormatText       = "text"       // plain text, URLs spelled out
+)
+
+// CheckFormat validates a format option; empty means FormatMarkdownV2.
+func CheckFormat(format string) error {
+	switch format {
+	case "", FormatMarkdownV2, FormatText:
+		return nil
+	}
+	return fmt.Errorf("unknown telegram format %q (want %s or %s)", format, FormatMarkdownV2, FormatText)
+}
+
+// ParseMode returns the Bot API parse_mode of a format.
+func ParseMode(format string) string {
+	if format == FormatText {
+		return ""
+	}
+	return "MarkdownV2"
+}
+
+// markdownV2Special are the characters MarkdownV2 requires to be escaped in text.
+const markdownV2Special = "\\_*[]()~`>#+-=|{}.!"
+
+// EscapeMarkdownV2 escapes s for use as plain text in a MarkdownV2 message.
+func EscapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 128 && strings.ContainsRune(markdownV2Special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeLinkURL escapes the URL part of an inline link, where only ")" and "\" are special.
+func escapeLinkURL(u string) string {
+	return strings.NewReplacer(`\`, `\\`, `)`, `\)`).Replace(u)
+}
+
+var (
+	// headingLinkRe matches a digest item heading; the title may itself contain brackets.
+	headingLinkRe = regexp.MustCompile(`^\[(.*)\]\((\S+)\)$`)
+	inlineLinkRe  = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+	emphasisRe    = regexp.MustCompile(`^\*([^*].*[^*]|[^*])\*$`)
+)
+
+// Messages converts a rendered Markdown digest (body without frontmatter) into
+// Telegram messages in the given format. Each item stays in one message; items
+// are packed into as few messages as fit MaxMessageLen.
+func Messages(title, body, format string) []string {
+	blocks := []string{}
+	if t := strings.TrimSpace(title); t != "" {
+		if format == FormatText {
+			blocks = append(blocks, t)
+		} else {
+			blocks = append(blocks, "*"+EscapeMarkdownV2(t)+"*")
+		}
+	}
+	var cur []string
+	flush := func() {
+		if b := joinLines(cur); b != "" {
+			blocks = append(blocks, b)
+		}
+		cur = cur[:0]
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+		}
+		cur = append(cur, convertLine(line, format))
+	}
+	flush()
+	return pack(blocks, MaxMessageLen)
+}
+
+// joinLines joins converted lines, collapsing runs of blank lines.
+func joinLines(lines []string) string {
+	var out []string
+	blank := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, l)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// convertLine renders one line of the digest Markdown.
+func convertLine(line, format string) string {
+	s := strings.TrimSpace(line)
+	text := format == FormatText
+	switch {
+	case s == "" || s == ">":
+		return ""
+	case strings.HasPrefix(s, "#"):
+		h := strings.TrimSpace(strings.TrimLeft(s, "#"))
+		if m := headingLinkRe.FindStringSubmatch(h); m != nil {
+			if text {
+				return m[1] + "\n" + m[2]
+			}
+			return "*[" + EscapeMarkdownV2(m[1]) + "](" + escapeLinkURL(m[2]) + ")*"
+		}
+		if text {
+			return h
+		}
+		return "*" + inline(h) + "*"
+	case strings.HasPrefix(s, ">"):
+		q := strings.TrimSpace(strings.TrimPrefix(s, ">"))
+		if text {
+			return "> " + inlineText(q)
+		}
+		return ">" + inline(q)
+	case emphasisRe.MatchString(s):
+		inner := s[1 : len(s)-1]
+		if text {
+			return inlineText(inner)
+		}
+		return "_" + inline(inner) + "_"
+	}
+	if text {
+		return inlineText(s)
+	}
+	return inline(s)
+}
+
+// inline escapes s for MarkdownV2, keeping inline Markdown links as links.
+func inline(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range inlineLinkRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(EscapeMarkdownV2(s[last:m[0]]))
+		b.WriteString("[" + EscapeMarkdownV2(s[m[2]:m[3]]) + "](" + escapeLinkURL(s[m[4]:m[5]]) + ")")
+		last = m[1]
+	}
+	b.WriteString(EscapeMarkdownV2(s[last:]))
+	return b.String()
+}
+
+// inlineText spells out inline links as "text (url)".
+func inlineText(s string) string {
+	return inlineLinkRe.ReplaceAllString(s, "$1 ($2)")
+}
+
+// textLen is the length Telegram counts: UTF-16 code units.
+func textLen(s string) int { return len(utf16.Encode([]rune(s))) }
+
+// pack greedily joins blocks into messages of at most limit. A block that is too
+// long on its own is split at line breaks, and a line that is still too long at
+// rune boundaries (never right after an escaping backslash).
+func pack(blocks []string, limit int) []string {
+	var msgs []string
+	cur := ""
+	add := func(piece string) {
+		switch {
+		case cur == "":
+			cur = piece
+		case textLen(cur)+2+textLen(piece) <= limit:
+			cur += "\n\n" + piece
+		default:
+			msgs = append(msgs, cur)
+			cur = piece
+		}
+	}
+	for _, b := range blocks {
+		if textLen(b) <= limit {
+			add(b)
+			continue
+		}
+		for _, piece := range splitLong(b, limit) {
+			add(piece)
+		}
+	}
+	if cur != "" {
+		msgs = append(msgs, cur)
+	}
+	return msgs
+}
+
+func splitLong(b string, limit int) []string {
+	var out []string
+	cur := ""
+	for _, line := range strings.Split(b, "\n") {
+		for textLen(line) > limit {
+			r := []rune(line)
+			cut := len([]rune(truncateUnits(line, limit)))
+			for cut > 1 && r[cut-1] == '\\' {
+				cut--
+			}
+			if cur != "" {
+				out = append(out, cur)
+				cur = ""
+			}
+			out = append(out, string(r[:cut]))
+			line = string(r[cut:])
+		}
+		switch {
+		case cur == "":
+			cur = line
+		case textLen(cur)+1+textLen(line) <= limit:
+			cur += "\n" + line
+		default:
+			out = append(out, cur)
+			cur = line
+		}
+	}
+	if cur != "" {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// truncateUnits returns the longest prefix of s that fits in limit UTF-16 units.
+func truncateUnits(s string, limit int) string {
+	n := 0
+	for i, r := range s {
+		w := 1
+		if r >= 0x10000 {
+			w = 2
+		}
+		if n+w > limit {
+			return s[:i]
+		}
+		n += w
+	}
+	return s
+}
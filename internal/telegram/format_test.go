@@ -0,0 +1,109 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeMarkdownV2EverySpecialChar(t *testing.T) {
+	for _, c := range "_*[]()~`>#+-=|{}.!\\" {
+		in := "a" + string(c) + "b"
+		want := "a\\" + string(c) + "b"
+		if got := EscapeMarkdownV2(in); got != want {
+			t.Errorf("EscapeMarkdownV2(%q) = %q, want %q", in, got, want)
+		}
+	}
+	// Non-special ASCII and non-ASCII text pass through unchanged.
+	if got := EscapeMarkdownV2("Hello, world: 100% \"ok\" 中文 🚀 'x' @y $z ^ & ; / ?"); got != "Hello, world: 100% \"ok\" 中文 🚀 'x' @y $z ^ & ; / ?" {
+		t.Errorf("plain text changed: %q", got)
+	}
+}
+
+func TestConvertItemHeadingTitles(t *testing.T) {
+	cases := []struct {
+		title, want string
+	}{
+		{"Python 3.13.0 released", `Python 3\.13\.0 released`},
+		{"snake_case vs camelCase", `snake\_case vs camelCase`},
+		{"Show HN: *bold* claims [pdf]", `Show HN: \*bold\* claims \[pdf\]`},
+		{"f(x) = x + 1 - y", `f\(x\) \= x \+ 1 \- y`},
+		{"~strike~ > quote # hash", `\~strike\~ \> quote \# hash`},
+		{"a|b {c} d.e!", `a\|b \{c\} d\.e\!`},
+		{"back\\slash `code`", "back\\\\slash \\`code\\`"},
+		{"_*[]()~>#+-=|{}.!", `\_\*\[\]\(\)\~\>\#\+\-\=\|\{\}\.\!`},
+	}
+	for _, tc := range cases {
+		got := convertLine("## ["+tc.title+"](https://example.com/a_(b)?q=1\\2)", FormatMarkdownV2)
+		want := "*[" + tc.want + `](https://example.com/a_(b\)?q=1\\2)*`
+		if got != want {
+			t.Errorf("heading %q:\n got %s\nwant %s", tc.title, got, want)
+		}
+	}
+}
+
+func TestConvertLines(t *testing.T) {
+	cases := []struct {
+		line, v2, text string
+	}{
+		{"*3 Replies - [@go](https://v2ex.com/go/go) - 2025-01-02 03:04*", `_3 Replies \- [@go](https://v2ex.com/go/go) \- 2025\-01\-02 03:04_`, "3 Replies - @go (https://v2ex.com/go/go) - 2025-01-02 03:04"},
+		{"> Your daily highlights.", `>Your daily highlights\.`, "> Your daily highlights."},
+		{">", "", ""},
+		{"Plain 1+1=2 (really).", `Plain 1\+1\=2 \(really\)\.`, "Plain 1+1=2 (really)."},
+		{"## [A [b]](https://x.y/z)", `*[A \[b\]](https://x.y/z)*`, "A [b]\nhttps://x.y/z"},
+	}
+	for _, tc := range cases {
+		if got := convertLine(tc.line, FormatMarkdownV2); got != tc.v2 {
+			t.Errorf("markdownv2 %q:\n got %s\nwant %s", tc.line, got, tc.v2)
+		}
+		if got := convertLine(tc.line, FormatText); got != tc.text {
+			t.Errorf("text %q:\n got %q\nwant %q", tc.line, got, tc.text)
+		}
+	}
+}
+
+func TestMessagesSplitOnItemBoundaries(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("> Preface.\n\nSummary paragraph.\n\n")
+	for i := 0; i < 30; i++ {
+		b.WriteString("## [Item title](https://example.com/item)\n\n")
+		b.WriteString(strings.Repeat("Description words. ", 12) + "\n\n")
+		b.WriteString("*3 Replies - [@go](https://example.com/go) - 2025-01-02 03:04*\n\n")
+	}
+	msgs := Messages("Daily Digest 2025-10-24", b.String(), FormatMarkdownV2)
+	if len(msgs) < 2 {
+		t.Fatalf("got %d messages, want the digest split", len(msgs))
+	}
+	items := 0
+	for i, m := range msgs {
+		if n := textLen(m); n > MaxMessageLen {
+			t.Errorf("message %d is %d units long", i, n)
+		}
+		// Every item heading is followed by its metadata line in the same message.
+		if strings.Count(m, "*[Item title]") != strings.Count(m, "_3 Replies") {
+			t.Errorf("message %d splits an item:\n%s", i, m)
+		}
+		items += strings.Count(m, "*[Item title]")
+	}
+	if items != 30 {
+		t.Errorf("items across messages = %d, want 30", items)
+	}
+	if !strings.HasPrefix(msgs[0], `*Daily Digest 2025\-10\-24*`) {
+		t.Errorf("first message should start with the title: %q", msgs[0][:40])
+	}
+}
+
+func TestPackSplitsOversizedBlocks(t *testing.T) {
+	long := strings.Repeat(`\.`, 3000) // escapes must not be cut in half
+	msgs := pack([]string{long}, MaxMessageLen)
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	for _, m := range msgs {
+		if textLen(m) > MaxMessageLen || strings.HasSuffix(m, `\`) {
+			t.Errorf("bad piece: len=%d suffix=%q", textLen(m), m[len(m)-2:])
+		}
+	}
+	if msgs[0]+msgs[1] != long {
+		t.Error("pieces do not reassemble the block")
+	}
+}
@@ -0,0 +1,87 @@
+// Package telegram posts digests to a Telegram chat through the Bot API.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Bot API endpoint.
+const DefaultBaseURL = "https://api.telegram.org"
+
+// MaxMessageLen is the Bot API limit on message text, in UTF-16 code units.
+const MaxMessageLen = 4096
+
+// Client sends messages as one bot.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New creates a client for the bot token; an empty baseURL uses DefaultBaseURL.
+func New(baseURL, token string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.http = hc
+	}
+	return &c2
+}
+
+// SendMessage posts text to chatID. parseMode is "MarkdownV2" or empty for plain text.
+func (c *Client) SendMessage(ctx context.Context, chatID, text, parseMode string) error {
+	body := map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if parseMode != "" {
+		body["parse_mode"] = parseMode
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	// The token is part of the path; keep it out of errors and logs.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/sendMessage", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("telegram: build request failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram: send failed: %s", strings.ReplaceAll(err.Error(), c.token, "<token>"))
+	}
+	defer resp.Body.Close()
+	var out struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(raw, &out); err != nil || !out.OK || resp.StatusCode != http.StatusOK {
+		desc := out.Description
+		if desc == "" {
+			desc = strings.TrimSpace(string(raw))
+		}
+		return fmt.Errorf("telegram: status=%d: %s", resp.StatusCode, desc)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendMessage(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if strings.Contains(got["text"].(string), "bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: can't parse entities"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "TOKEN")
+	if err := c.SendMessage(context.Background(), "@digest", `hi\.`, "MarkdownV2"); err != nil {
+		t.Fatal(err)
+	}
+	if got["chat_id"] != "@digest" || got["parse_mode"] != "MarkdownV2" || got["text"] != `hi\.` {
+		t.Errorf("request = %v", got)
+	}
+	err := c.SendMessage(context.Background(), "@digest", "bad", "")
+	if err == nil || !strings.Contains(err.Error(), "can't parse entities") || strings.Contains(err.Error(), "TOKEN") {
+		t.Errorf("err = %v, want the API description without the token", err)
+	}
+}
@@ -0,0 +1,114 @@
+package textclean
+
+import (
+	"html"
+	"strings"
+)
+
+// StripHTML converts the HTML of a source's text (Hacker News items, Stack Overflow
+// questions) to plain text for summarizers and digests. <p> starts a new paragraph,
+// <br> a new line, and links render as "text (url)" (or just the URL when the link
+// text is a truncated copy of it). Entities are decoded with html.UnescapeString,
+// so numeric forms like &#x27; and &#x2F; are handled. It makes a single pass over
+// s into one buffer.
+func StripHTML(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+	buf := make([]byte, 0, len(s))
+	var (
+		href     string
+		linkFrom = -1 // buf offset where the current link text starts
+	)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c != '<' {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			// Unterminated tag: keep the rest as text.
+			buf = append(buf, s[i:]...)
+			break
+		}
+		tag := s[i+1 : i+end]
+		i += end + 1
+		name, closing := tagName(tag)
+		switch name {
+		case "p":
+			if !closing && len(buf) > 0 {
+				buf = trimTrailingSpace(buf)
+				buf = append(buf, '\n', '\n')
+			}
+		case "br":
+			buf = trimTrailingSpace(buf)
+			buf = append(buf, '\n')
+		case "a":
+			if !closing {
+				href, linkFrom = attr(tag, "href"), len(buf)
+				continue
+			}
+			if linkFrom >= 0 && href != "" {
+				text := string(buf[linkFrom:])
+				switch {
+				case text == "" || strings.HasPrefix(text, "http") && strings.HasPrefix(href, strings.TrimSuffix(text, "...")):
+					// Link text is (a truncated copy of) the URL: show the full URL once.
+					buf = append(buf[:linkFrom], href...)
+				default:
+					buf = append(buf, " ("...)
+					buf = append(buf, href...)
+					buf = append(buf, ')')
+				}
+			}
+			href, linkFrom = "", -1
+		}
+	}
+	return strings.TrimSpace(html.UnescapeString(string(buf)))
+}
+
+// tagName returns the lower-cased element name of a tag body like `a href="..."` or `/p`.
+func tagName(tag string) (name string, closing bool) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "/") {
+		closing = true
+		tag = tag[1:]
+	}
+	end := strings.IndexAny(tag, " \t\n/")
+	if end >= 0 {
+		tag = tag[:end]
+	}
+	return strings.ToLower(tag), closing
+}
+
+// attr extracts a double- or single-quoted attribute value from a tag body.
+func attr(tag, key string) string {
+	lower := strings.ToLower(tag)
+	idx := strings.Index(lower, key+"=")
+	if idx < 0 {
+		return ""
+	}
+	v := tag[idx+len(key)+1:]
+	if v == "" {
+		return ""
+	}
+	if q := v[0]; q == '"' || q == '\'' {
+		if end := strings.IndexByte(v[1:], q); end >= 0 {
+			return v[1 : end+1]
+		}
+		return ""
+	}
+	if end := strings.IndexAny(v, " \t>"); end >= 0 {
+		return v[:end]
+	}
+	return v
+}
+
+func trimTrailingSpace(b []byte) []byte {
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == '\t') {
+		b = b[:len(b)-1]
+	}
+	return b
+}
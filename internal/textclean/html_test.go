@@ -0,0 +1,46 @@
+package textclean
+
+import "testing"
+
+// Samples are verbatim "text" fields from HN items.
+var (
+	hnAskText  = `I&#x27;ve been running a small SaaS for ~3 years and I&#x27;m thinking about open-sourcing it.<p>Things I&#x27;m worried about:<p>- support burden<br>- people forking &amp; competing<br>- the &quot;open core&quot; trap&hellip;<p>Has anyone done this? Previous discussion: <a href="https:&#x2F;&#x2F;news.ycombinator.com&#x2F;item?id=123456">https:&#x2F;&#x2F;news.ycombinator.com&#x2F;item?id=123456</a>`
+	hnLongLink = `See <a href="https:&#x2F;&#x2F;github.com&#x2F;golang&#x2F;go&#x2F;issues&#x2F;12345#issuecomment-987654321" rel="nofollow">https:&#x2F;&#x2F;github.com&#x2F;golang&#x2F;go&#x2F;issues&#x2F;12345#issuecomm...</a> for context.`
+)
+
+func TestStripHTML(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"empty", "  ", ""},
+		{"plain", "Hello world", "Hello world"},
+		{"basic entities", `&quot;a&quot; &amp; &lt;b&gt; &apos;c&apos;`, `"a" & <b> 'c'`},
+		{"numeric entities", `It&#x27;s 5&#39;11&#34; &#8212; really`, `It's 5'11" — really`},
+		{"named entities", `Wait&hellip; &nbsp;ok&mdash;fine`, "Wait… \u00a0ok—fine"},
+		{"paragraphs", `First para.<p>Second para.<p>Third.`, "First para.\n\nSecond para.\n\nThird."},
+		{"closed paragraphs", `<p>One</p><p>Two</p>`, "One\n\nTwo"},
+		{"line breaks", `a<br>b<br/>c<BR />d`, "a\nb\nc\nd"},
+		{"link with text", `Read <a href="https://example.com/post">this post</a> first.`, "Read this post (https://example.com/post) first."},
+		{"link as url", `<a href="https:&#x2F;&#x2F;example.com">https:&#x2F;&#x2F;example.com</a>`, "https://example.com"},
+		{"truncated link", hnLongLink, "See https://github.com/golang/go/issues/12345#issuecomment-987654321 for context."},
+		{"formatting tags", `<i>really</i> <b>bold</b> <pre><code>  x := 1</code></pre>`, "really bold   x := 1"},
+		{"unterminated tag", `a < b and c <d`, "a < b and c <d"},
+		{"ask hn", hnAskText, "I've been running a small SaaS for ~3 years and I'm thinking about open-sourcing it.\n\n" +
+			"Things I'm worried about:\n\n- support burden\n- people forking & competing\n- the \"open core\" trap…\n\n" +
+			"Has anyone done this? Previous discussion: https://news.ycombinator.com/item?id=123456"},
+	}
+	for _, tc := range cases {
+		if got := StripHTML(tc.in); got != tc.want {
+			t.Errorf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkStripHTML(b *testing.B) {
+	in := hnAskText + "<p>" + hnLongLink
+	b.ReportAllocs()
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		_ = StripHTML(in)
+	}
+}
@@ -0,0 +1,43 @@
+package textclean
+
+import (
+	"html"
+	"math"
+	"unicode"
+)
+
+// Reading speeds used by ReadingMinutes.
+const (
+	wordsPerMinute    = 200
+	cjkCharsPerMinute = 400
+)
+
+// ReadingMinutes estimates how long s takes to read, rounded up to whole minutes:
+// words (runs of non-space, non-CJK text containing a letter or digit) at 200 per
+// minute plus CJK characters at 400 per minute. HTML tags are ignored and text
+// without any readable content is 0.
+func ReadingMinutes(s string) int {
+	s = html.UnescapeString(htmlTagRe.ReplaceAllString(s, " "))
+	words, cjk := 0, 0
+	inWord := false
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			cjk++
+			inWord = false
+		case unicode.IsSpace(r):
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		}
+	}
+	minutes := float64(words)/wordsPerMinute + float64(cjk)/cjkCharsPerMinute
+	return int(math.Ceil(minutes))
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,30 @@
+package textclean
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadingMinutes(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"markup only", "<p> </p><br>", 0},
+		{"short english", "Release v1.2 is out, don't miss it.", 1},
+		{"english 1000 words", strings.Repeat("word ", 1000), 5},
+		{"english 1001 words", strings.Repeat("word ", 1001), 6},
+		{"tags are not words", strings.Repeat("<b>word</b> ", 400), 2},
+		{"chinese 800 chars", strings.Repeat("今天天气很好", 800/6) + "今天", 2},
+		{"chinese 801 chars", strings.Repeat("中", 801), 3},
+		// 400 English words (2 min) plus 400 CJK chars (1 min).
+		{"mixed", strings.Repeat("Go 语言", 200) + strings.Repeat(" text", 200), 3},
+	}
+	for _, c := range cases {
+		if got := ReadingMinutes(c.in); got != c.want {
+			t.Errorf("%s: ReadingMinutes = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
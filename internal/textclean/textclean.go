@@ -0,0 +1,143 @@
+// Package textclean normalizes user-generated source text before it is stored
+// or sent to summarizers.
+package textclean
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxRunes is the content budget used when Options.MaxRunes is zero.
+const DefaultMaxRunes = 2000
+
+// maxURLRunes is the length above which URLs lose their query string and fragment.
+const maxURLRunes = 100
+
+// Options controls Clean.
+type Options struct {
+	// MaxRunes caps the output size. Zero means DefaultMaxRunes; negative disables the cap.
+	MaxRunes int
+}
+
+var (
+	// data:image/png;base64,.... blobs, optionally wrapped in markdown image syntax.
+	dataURIRe = regexp.MustCompile(`!?\[[^\]]*\]\(\s*data:[a-zA-Z0-9.+/-]+;base64,[A-Za-z0-9+/=\s]*\)|data:[a-zA-Z0-9.+/-]+;base64,[A-Za-z0-9+/=]+`)
+	// [img]...[/img] blocks are dropped entirely.
+	bbImgRe = regexp.MustCompile(`(?is)\[img[^\]]*\].*?\[/img\]`)
+	// Remaining BBCode-ish tags like [b], [/url], [url=https://...], [color=red] keep their inner text.
+	bbTagRe   = regexp.MustCompile(`(?i)\[/?(?:b|i|u|s|url|quote|code|color|size|font|center|left|right)(?:=[^\]]*)?\]`)
+	urlRe     = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	spaceRe   = regexp.MustCompile(`[ \t\f\v\x{00A0}\x{3000}]+`)
+	newlineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// Clean strips image data URIs and BBCode-style markup, shortens very long URLs,
+// collapses whitespace, and caps the result at a rune budget. The first
+// paragraph is always kept intact; later paragraphs are appended while they fit.
+func Clean(s string, opts Options) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = dataURIRe.ReplaceAllString(s, "")
+	s = bbImgRe.ReplaceAllString(s, "")
+	s = bbTagRe.ReplaceAllString(s, "")
+	s = urlRe.ReplaceAllStringFunc(s, shortenURL)
+	s = collapseWhitespace(s)
+
+	max := opts.MaxRunes
+	if max == 0 {
+		max = DefaultMaxRunes
+	}
+	if max < 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return capParagraphs(s, max)
+}
+
+// collapseWhitespace squeezes horizontal whitespace, trims each line, and limits blank lines to one.
+func collapseWhitespace(s string) string {
+	s = spaceRe.ReplaceAllString(s, " ")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	s = strings.Join(lines, "\n")
+	s = newlineRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// shortenURL drops the query and fragment of URLs longer than maxURLRunes.
+func shortenURL(raw string) string {
+	if utf8.RuneCountInString(raw) <= maxURLRunes {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// capParagraphs keeps the first paragraph whole and fills the remaining budget paragraph by paragraph.
+func capParagraphs(s string, max int) string {
+	paras := strings.Split(s, "\n\n")
+	var b strings.Builder
+	b.WriteString(paras[0])
+	used := utf8.RuneCountInString(paras[0])
+	for _, p := range paras[1:] {
+		left := max - used - 2
+		if left <= 0 {
+			break
+		}
+		n := utf8.RuneCountInString(p)
+		b.WriteString("\n\n")
+		if n <= left {
+			b.WriteString(p)
+			used += n + 2
+			continue
+		}
+		b.WriteString(string([]rune(p)[:left]))
+		b.WriteString("…")
+		break
+	}
+	return b.String()
+}
+
+var htmlTagRe = regexp.MustCompile(`<[^>]+>`)
+
+// ContentRunes counts the runes of s after stripping HTML tags, decoding entities,
+// and trimming surrounding whitespace.
+func ContentRunes(s string) int {
+	s = htmlTagRe.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return utf8.RuneCountInString(strings.TrimSpace(s))
+}
+
+// FirstSentence returns the first sentence of s after HTML stripping: text up to and
+// including the first terminal punctuation (. ! ? 。 ！ ？) or line break.
+func FirstSentence(s string) string {
+	s = html.UnescapeString(htmlTagRe.ReplaceAllString(s, " "))
+	s = strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+	for i, r := range s {
+		switch r {
+		case '\n':
+			return strings.TrimSpace(s[:i])
+		case '.', '!', '?':
+			// Require a following space (or end) so "v1.2" and URLs stay whole.
+			next := i + 1
+			if next >= len(s) || s[next] == ' ' || s[next] == '\n' {
+				return strings.TrimSpace(s[:next])
+			}
+		case '。', '！', '？':
+			return strings.TrimSpace(s[:i+utf8.RuneLen(r)])
+		}
+	}
+	return s
+}
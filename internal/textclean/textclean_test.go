@@ -0,0 +1,91 @@
+package textclean
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanMessyV2EXContent(t *testing.T) {
+	blob := strings.Repeat("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk", 400)
+	longURL := "https://example.com/path/to/article?utm_source=v2ex&utm_medium=social&utm_campaign=" + strings.Repeat("x", 200) + "#comments"
+	messy := "求推荐一款适合程序员的机械键盘，预算 1000 以内。\r\n\r\n\r\n" +
+		"[b]要求[/b]：静音、[url=https://example.com]蓝牙[/url]双模。\n" +
+		"![shot](data:image/png;base64," + blob + ")\n" +
+		"[img]https://i.imgur.com/abc.png[/img]\n\n\n\n" +
+		"参考链接：" + longURL + "\n\n" +
+		"      谢谢各位 \t\t 大佬！     "
+
+	got := Clean(messy, Options{})
+	for _, bad := range []string{"base64", "[b]", "[/url]", "[img]", "utm_source", "\n\n\n", "\t"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("cleaned content still contains %q:\n%s", bad, got)
+		}
+	}
+	for _, keep := range []string{"求推荐一款适合程序员的机械键盘，预算 1000 以内。", "要求：静音、蓝牙双模。", "https://example.com/path/to/article", "谢谢各位 大佬！"} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("cleaned content lost %q:\n%s", keep, got)
+		}
+	}
+	if len(got)*10 > len(messy) {
+		t.Errorf("expected >90%% size reduction, got %d -> %d bytes", len(messy), len(got))
+	}
+	t.Logf("size %d -> %d bytes", len(messy), len(got))
+}
+
+func TestCleanCapsAtParagraphs(t *testing.T) {
+	first := strings.Repeat("第一段", 50) // 150 runes
+	second := strings.Repeat("b", 100)
+	third := strings.Repeat("c", 100)
+	in := first + "\n\n" + second + "\n\n" + third
+
+	got := Clean(in, Options{MaxRunes: 200})
+	if !strings.HasPrefix(got, first+"\n\n") {
+		t.Fatalf("first paragraph not preserved: %q", got)
+	}
+	if strings.Contains(got, "c") {
+		t.Errorf("third paragraph should be dropped: %q", got)
+	}
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("truncated paragraph should end with an ellipsis: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n > 201 {
+		t.Errorf("output has %d runes, want <= 201", n)
+	}
+
+	// A first paragraph longer than the budget is kept whole.
+	long := strings.Repeat("a", 500)
+	if got := Clean(long+"\n\nmore", Options{MaxRunes: 100}); got != long {
+		t.Errorf("oversized first paragraph should be kept intact, got %d runes", utf8.RuneCountInString(got))
+	}
+
+	// Negative budget disables the cap.
+	if got := Clean(in, Options{MaxRunes: -1}); got != in {
+		t.Errorf("uncapped output mismatch")
+	}
+}
+
+func TestCleanEmpty(t *testing.T) {
+	if got := Clean("  \n\t ", Options{}); got != "" {
+		t.Errorf("Clean(blank) = %q, want empty", got)
+	}
+}
+
+func TestContentRunesAndFirstSentence(t *testing.T) {
+	if n := ContentRunes("<p>Hi &amp; bye</p>"); n != len("Hi & bye") {
+		t.Errorf("ContentRunes = %d, want %d", n, len("Hi & bye"))
+	}
+	cases := []struct{ in, want string }{
+		{"Release v1.2 is out. Upgrade now!", "Release v1.2 is out."},
+		{"<p>Does anyone use Nix? I'm curious.</p>", "Does anyone use Nix?"},
+		{"今天发布了新版本。欢迎试用！", "今天发布了新版本。"},
+		{"first line\nsecond line", "first line"},
+		{"no terminal punctuation", "no terminal punctuation"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := FirstSentence(tc.in); got != tc.want {
+			t.Errorf("FirstSentence(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
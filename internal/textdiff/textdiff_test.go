@@ -0,0 +1,33 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Errorf("equal inputs: %q", got)
+	}
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := `--- old
++++ new
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+@@ -12 +12,2 @@
+ 12
++13
+`
+	if got := Unified("old", "new", a, b, 1); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+	want = `--- old
++++ new
+@@ -0,0 +1 @@
++x
+`
+	if got := Unified("old", "new", "", "x", 3); got != want {
+		t.Errorf("from empty =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+// Package urlutil repairs item URLs coming from sources.
+package urlutil
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*://`)
+	// hostLikeRe matches scheme-less URLs such as "www.example.com/post" or "example.com:8080".
+	hostLikeRe = regexp.MustCompile(`^[a-zA-Z0-9\-]+(\.[a-zA-Z0-9\-]+)+(:\d+)?([/?#]|$)`)
+)
+
+// Normalize returns raw as an absolute http(s) URL. Surrounding whitespace is
+// trimmed, scheme-relative ("//host/x") and scheme-less ("www.host/x") URLs get
+// https, root-relative paths are resolved against base, and characters that are
+// invalid in a URL (spaces, control and non-ASCII bytes) are percent-encoded.
+// When raw cannot be repaired, fallback (e.g., the item's page on the source) is
+// returned. changed reports whether the result differs from raw.
+func Normalize(raw, base, fallback string) (out string, changed bool) {
+	s := strings.TrimSpace(raw)
+	switch {
+	case s == "":
+		return fallback, fallback != raw
+	case strings.HasPrefix(s, "//"):
+		s = "https:" + s
+	case strings.HasPrefix(s, "/"):
+		if base == "" {
+			return fallback, fallback != raw
+		}
+		s = strings.TrimRight(base, "/") + s
+	case !schemeRe.MatchString(s) && hostLikeRe.MatchString(s):
+		s = "https://" + s
+	}
+	u, err := url.Parse(escapeInvalid(s))
+	if err != nil || u.Host == "" {
+		return fallback, fallback != raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fallback, fallback != raw
+	}
+	out = u.String()
+	return out, out != raw
+}
+
+// escapeInvalid percent-encodes bytes that may not appear in a URL, leaving
+// reserved characters and existing escapes alone.
+func escapeInvalid(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"<>\^`+"`"+`{|}`, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package urlutil
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	const fallback = "https://news.ycombinator.com/item?id=1"
+	cases := []struct {
+		name, raw, want string
+		changed         bool
+	}{
+		{"valid", "https://example.com/post?id=1#top", "https://example.com/post?id=1#top", false},
+		{"surrounding whitespace", "  https://example.com/post\n", "https://example.com/post", true},
+		{"scheme-less", "www.example.com/post", "https://www.example.com/post", true},
+		{"scheme-less with port", "example.com:8080", "https://example.com:8080", true},
+		{"scheme-relative", "//example.com/post", "https://example.com/post", true},
+		{"root-relative", "/item?id=2", "https://news.ycombinator.com/item?id=2", true},
+		{"inner space", "https://example.com/my post?q=a b", "https://example.com/my%20post?q=a%20b", true},
+		{"non-ascii path", "https://example.com/文章", "https://example.com/%E6%96%87%E7%AB%A0", true},
+		{"upper-case scheme", "HTTPS://example.com/", "https://example.com/", true},
+		{"existing escapes kept", "https://example.com/a%20b", "https://example.com/a%20b", false},
+		{"empty", "", fallback, true},
+		{"relative path", "post/1", fallback, true},
+		{"javascript", "javascript:alert(1)", fallback, true},
+		{"ftp", "ftp://example.com/file", fallback, true},
+		{"missing host", "https:///path", fallback, true},
+	}
+	for _, c := range cases {
+		got, changed := Normalize(c.raw, "https://news.ycombinator.com", fallback)
+		if got != c.want || changed != c.changed {
+			t.Errorf("%s: Normalize(%q) = %q, %v; want %q, %v", c.name, c.raw, got, changed, c.want, c.changed)
+		}
+	}
+}
@@ -4,17 +4,29 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/urlutil"
 )
 
 type Client struct {
-	baseURL string
-	client  *http.Client
-	token   string
+	baseURL   string
+	client    *http.Client
+	token     string
+	searchAPI string     // SearchTopics endpoint; empty uses DefaultSearchAPI
+	nodes     *nodeCache // node names by ID, shared by copies of the client
+}
+
+// nodeCache maps node IDs to names; they never change, so entries do not expire.
+type nodeCache struct {
+	mu   sync.Mutex
+	byID map[int]string
 }
 
 func NewClient(baseURL, token string) *Client {
@@ -22,7 +34,17 @@ func NewClient(baseURL, token string) *Client {
 		baseURL: baseURL,
 		client:  &http.Client{Timeout: 10 * time.Second},
 		token:   token,
+		nodes:   &nodeCache{byID: map[int]string{}},
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
 	}
+	return &c2
 }
 
 // Topic represents a subset of V2EX topic fields used by this service.
@@ -35,6 +57,9 @@ type Topic struct {
 	Node    struct {
 		Name string `json:"name"`
 	} `json:"node"`
+	Member struct {
+		Username string `json:"username"`
+	} `json:"member"`
 	Created int64 `json:"created"`
 }
 
@@ -58,17 +83,23 @@ func (c *Client) TopicsByNode(ctx context.Context, node string) ([]model.NewsIte
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("v2ex: status %d", resp.StatusCode)
 	}
-	var raw []Topic
+	var raw []json.RawMessage
 	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
 		return nil, err
 	}
 	items := make([]model.NewsItem, 0, len(raw))
-	for _, t := range raw {
-		urlStr := t.URL
-		if urlStr == "" {
-			urlStr = fmt.Sprintf("%s/t/%d", c.baseURL, t.ID)
+	for _, payload := range raw {
+		var t Topic
+		if err := json.Unmarshal(payload, &t); err != nil {
+			return nil, err
+		}
+		topicPage := fmt.Sprintf("%s/t/%d", c.baseURL, t.ID)
+		urlStr, changed := urlutil.Normalize(t.URL, c.baseURL, topicPage)
+		if changed && strings.TrimSpace(t.URL) != "" {
+			slog.Debug("v2ex: repaired topic url", "id", t.ID, "url", t.URL, "repaired", urlStr)
 		}
 		items = append(items, model.NewsItem{
+			Source:    "v2ex",
 			ID:        fmt.Sprintf("%d", t.ID),
 			Title:     t.Title,
 			URL:       urlStr,
@@ -76,6 +107,8 @@ func (c *Client) TopicsByNode(ctx context.Context, node string) ([]model.NewsIte
 			Replies:   t.Replies,
 			CreatedAt: time.Unix(t.Created, 0),
 			Content:   t.Content,
+			Author:    t.Member.Username,
+			Raw:       payload,
 		})
 	}
 	return items, nil
@@ -0,0 +1,170 @@
+package v2ex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// QueryPrefix marks a channel node as a search query instead of a node name,
+// e.g., "q:rust".
+const QueryPrefix = "q:"
+
+// DefaultSearchAPI is the sov2ex full-text search endpoint; V2EX has no search API.
+const DefaultSearchAPI = "https://www.sov2ex.com/api/search"
+
+// searchSize is how many of the newest matches a search returns.
+const searchSize = 50
+
+// SearchQuery returns the query of a "q:<query>" node; ok is false for a node name.
+func SearchQuery(node string) (query string, ok bool) {
+	node = strings.TrimSpace(node)
+	if len(node) < len(QueryPrefix) || !strings.EqualFold(node[:len(QueryPrefix)], QueryPrefix) {
+		return "", false
+	}
+	query = strings.TrimSpace(node[len(QueryPrefix):])
+	return query, query != ""
+}
+
+// MatchesQuery reports whether every word of query occurs, case-insensitively, in
+// the item's title or content. Channels use it to select the collected items of
+// their "q:" nodes, which keep their real node names.
+func MatchesQuery(it model.NewsItem, query string) bool {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return false
+	}
+	text := strings.ToLower(it.Title + "\n" + it.Content)
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithSearchAPI overrides the search endpoint used by SearchTopics (empty keeps the default).
+func (c *Client) WithSearchAPI(endpoint string) *Client {
+	c2 := *c
+	if strings.TrimSpace(endpoint) != "" {
+		c2.searchAPI = strings.TrimSpace(endpoint)
+	}
+	return &c2
+}
+
+// searchResponse mirrors the subset of the sov2ex response we use.
+type searchResponse struct {
+	Hits []struct {
+		Source struct {
+			ID      int    `json:"id"`
+			Title   string `json:"title"`
+			Content string `json:"content"`
+			Node    int    `json:"node"`
+			Replies int    `json:"replies"`
+			Member  string `json:"member"`
+			Created string `json:"created"`
+		} `json:"_source"`
+	} `json:"hits"`
+}
+
+// SearchTopics returns the newest topics matching query across all nodes. Each
+// topic keeps its real node name, resolved by node ID (cached per client); topics
+// whose node cannot be resolved are skipped.
+// API: GET <search_api>?q={query}&sort=created&order=0&size=50
+func (c *Client) SearchTopics(ctx context.Context, query string) ([]model.NewsItem, error) {
+	endpoint := c.searchAPI
+	if endpoint == "" {
+		endpoint = DefaultSearchAPI
+	}
+	q := url.Values{"q": {query}, "sort": {"created"}, "order": {"0"}, "size": {strconv.Itoa(searchSize)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("v2ex: search status %d", resp.StatusCode)
+	}
+	var raw searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	items := make([]model.NewsItem, 0, len(raw.Hits))
+	for _, h := range raw.Hits {
+		t := h.Source
+		node, err := c.nodeName(ctx, t.Node)
+		if err != nil || node == "" {
+			slog.Warn("v2ex: search hit skipped; node not resolved", "id", t.ID, "node_id", t.Node, "err", err)
+			continue
+		}
+		items = append(items, model.NewsItem{
+			Source:    "v2ex",
+			ID:        strconv.Itoa(t.ID),
+			Title:     t.Title,
+			URL:       fmt.Sprintf("%s/t/%d", c.baseURL, t.ID),
+			NodeName:  node,
+			Replies:   t.Replies,
+			CreatedAt: parseSearchTime(t.Created),
+			Content:   t.Content,
+			Author:    t.Member,
+		})
+	}
+	return items, nil
+}
+
+// parseSearchTime parses sov2ex's created field (UTC, without a zone).
+func parseSearchTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02T15:04:05", time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nodeName resolves a node ID to its name.
+// API: GET /api/nodes/show.json?id={id}
+func (c *Client) nodeName(ctx context.Context, id int) (string, error) {
+	c.nodes.mu.Lock()
+	name, ok := c.nodes.byID[id]
+	c.nodes.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+	endpoint := fmt.Sprintf("%s/api/nodes/show.json?id=%d", c.baseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("v2ex: node status %d", resp.StatusCode)
+	}
+	var meta NodeMeta
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return "", err
+	}
+	c.nodes.mu.Lock()
+	c.nodes.byID[id] = meta.Name
+	c.nodes.mu.Unlock()
+	return meta.Name, nil
+}
@@ -0,0 +1,83 @@
+package v2ex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+func TestSearchTopics(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	nodeLookups := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search":
+			if r.URL.Query().Get("q") != "rust" || r.URL.Query().Get("sort") != "created" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write(fixture)
+		case "/api/nodes/show.json":
+			id := r.URL.Query().Get("id")
+			mu.Lock()
+			nodeLookups[id]++
+			mu.Unlock()
+			switch id {
+			case "300":
+				_, _ = w.Write([]byte(`{"id":300,"name":"programmer","title":"程序员"}`))
+			case "17":
+				_, _ = w.Write([]byte(`{"id":17,"name":"go","title":"Go 编程语言"}`))
+			default:
+				http.NotFound(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "").WithSearchAPI(srv.URL + "/search")
+	for i := 0; i < 2; i++ {
+		items, err := c.SearchTopics(context.Background(), "rust")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("got %d items, want 2 (the hit of an unknown node skipped)", len(items))
+		}
+		first := items[0]
+		if first.ID != "1100001" || first.NodeName != "programmer" || first.Replies != 42 || first.Author != "alice" ||
+			first.URL != srv.URL+"/t/1100001" || !first.CreatedAt.Equal(time.Date(2025, 10, 24, 3, 15, 0, 0, time.UTC)) {
+			t.Errorf("first item = %+v", first)
+		}
+		if items[1].NodeName != "go" {
+			t.Errorf("second item node = %q, want go", items[1].NodeName)
+		}
+	}
+	if nodeLookups["300"] != 1 || nodeLookups["17"] != 1 {
+		t.Errorf("node lookups = %v, want one per node", nodeLookups)
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	for node, want := range map[string]string{"q:rust": "rust", " Q: 远程 工作 ": "远程 工作", "q:": "", "rust": "", "qa": ""} {
+		got, ok := SearchQuery(node)
+		if got != want || ok != (want != "") {
+			t.Errorf("SearchQuery(%q) = %q, %v", node, got, ok)
+		}
+	}
+	it := model.NewsItem{Title: "招聘 Rust 工程师", Content: "可远程"}
+	if !MatchesQuery(it, "rust 远程") || MatchesQuery(it, "rust go") || MatchesQuery(it, " ") {
+		t.Error("MatchesQuery needs every word, case-insensitively")
+	}
+}
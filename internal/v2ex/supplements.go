@@ -0,0 +1,73 @@
+package v2ex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"quaily-journalist/internal/textclean"
+)
+
+// SupplementMaxRunes caps the combined supplement text appended to a topic's content.
+const SupplementMaxRunes = 1500
+
+// ErrNoToken is returned by API v2 calls when the client has no token.
+var ErrNoToken = errors.New("v2ex: api v2 requires a token")
+
+// Supplement is an appendix ("附言") added to a topic after it was posted.
+type Supplement struct {
+	ID              int    `json:"id"`
+	Content         string `json:"content"`
+	ContentRendered string `json:"content_rendered"`
+	Created         int64  `json:"created"`
+}
+
+// TopicSupplements fetches a topic's supplements in posting order; see TopicDetails.
+func (c *Client) TopicSupplements(ctx context.Context, topicID string) ([]Supplement, error) {
+	d, err := c.TopicDetails(ctx, topicID)
+	return d.Supplements, err
+}
+
+// AppendSupplements appends supplements to content as "附言 N:" paragraphs. The
+// supplement text is cleaned and capped at SupplementMaxRunes in total, so a long
+// thread of appendices cannot crowd out the topic itself.
+func AppendSupplements(content string, sups []Supplement) string {
+	parts := make([]string, 0, len(sups))
+	for i, s := range sups {
+		text := s.Content
+		if strings.TrimSpace(text) == "" {
+			text = stripTags(s.ContentRendered)
+		}
+		text = textclean.Clean(text, textclean.Options{MaxRunes: -1})
+		if text == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("附言 %d: %s", i+1, text))
+	}
+	if len(parts) == 0 {
+		return content
+	}
+	extra := textclean.Clean(strings.Join(parts, "\n\n"), textclean.Options{MaxRunes: SupplementMaxRunes})
+	if strings.TrimSpace(content) == "" {
+		return extra
+	}
+	return content + "\n\n" + extra
+}
+
+// stripTags drops HTML tags from rendered supplement content.
+func stripTags(s string) string {
+	var b strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
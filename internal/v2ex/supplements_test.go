@@ -0,0 +1,61 @@
+package v2ex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTopicSupplements(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/topic_supplements.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/topics/1024" || r.Header.Get("Authorization") != "Bearer tok" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	sups, err := NewClient(srv.URL, "tok").TopicSupplements(context.Background(), "1024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sups) != 3 {
+		t.Fatalf("got %d supplements, want 3", len(sups))
+	}
+	got := AppendSupplements("群晖 DS920+，四块盘里有一块每隔几天掉线一次。", sups)
+	want := "群晖 DS920+，四块盘里有一块每隔几天掉线一次。\n\n" +
+		"附言 1: 已经换过 SATA 线，问题依旧。\n\n" +
+		"附言 2: 换了电源后再没掉过。\n\n" +
+		"附言 3: 结论：电源老化导致供电不稳，已解决。"
+	if got != want {
+		t.Errorf("AppendSupplements =\n%q\nwant\n%q", got, want)
+	}
+
+	if _, err := NewClient(srv.URL, "").TopicSupplements(context.Background(), "1024"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("without token: err = %v, want ErrNoToken", err)
+	}
+}
+
+func TestAppendSupplementsCap(t *testing.T) {
+	sups := make([]Supplement, 0, 5)
+	for i := 0; i < 5; i++ {
+		sups = append(sups, Supplement{Content: strings.Repeat("更新", 400)})
+	}
+	got := AppendSupplements("body", sups)
+	if n := utf8.RuneCountInString(strings.TrimPrefix(got, "body\n\n")); n > SupplementMaxRunes+10 {
+		t.Errorf("supplements use %d runes, want about %d", n, SupplementMaxRunes)
+	}
+	if AppendSupplements("body", nil) != "body" {
+		t.Error("no supplements changed the content")
+	}
+}
@@ -0,0 +1,51 @@
+package v2ex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TopicDetail holds the fields of a topic that only API v2 returns.
+type TopicDetail struct {
+	// Likes is the topic's appreciation count (thanks); the collector stores it as
+	// the item's points.
+	Likes       int          `json:"likes"`
+	Supplements []Supplement `json:"supplements"`
+}
+
+// TopicDetails fetches a topic's API v2 fields, supplements in posting order.
+// API: GET /api/v2/topics/:topic_id (token required)
+func (c *Client) TopicDetails(ctx context.Context, topicID string) (TopicDetail, error) {
+	if c.token == "" {
+		return TopicDetail{}, ErrNoToken
+	}
+	endpoint := fmt.Sprintf("%s/api/v2/topics/%s", c.baseURL, url.PathEscape(topicID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return TopicDetail{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return TopicDetail{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return TopicDetail{}, fmt.Errorf("v2ex: topic status %d", resp.StatusCode)
+	}
+	var envelope struct {
+		Success bool        `json:"success"`
+		Message string      `json:"message"`
+		Result  TopicDetail `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return TopicDetail{}, err
+	}
+	if !envelope.Success {
+		return TopicDetail{}, fmt.Errorf("v2ex: topic %s: %s", topicID, envelope.Message)
+	}
+	return envelope.Result, nil
+}
@@ -0,0 +1,44 @@
+package v2ex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestTopicDetails(t *testing.T) {
+	fixtures := map[string]string{}
+	for id, file := range map[string]string{"1024": "testdata/topic_supplements.json", "2048": "testdata/topic_likes.json"} {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures["/api/v2/topics/"+id] = string(b)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fixtures[r.URL.Path]
+		if !ok || r.Header.Get("Authorization") != "Bearer tok" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "tok")
+	for id, want := range map[string]struct{ likes, supplements int }{"1024": {0, 3}, "2048": {37, 0}} {
+		d, err := c.TopicDetails(context.Background(), id)
+		if err != nil || d.Likes != want.likes || len(d.Supplements) != want.supplements {
+			t.Errorf("TopicDetails(%s) = %d likes, %d supplements, %v; want %d, %d", id, d.Likes, len(d.Supplements), err, want.likes, want.supplements)
+		}
+	}
+	if _, err := c.TopicDetails(context.Background(), "1"); err == nil {
+		t.Error("missing topic: err = nil")
+	}
+	if _, err := NewClient(srv.URL, "").TopicDetails(context.Background(), "2048"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("without token: err = %v, want ErrNoToken", err)
+	}
+}
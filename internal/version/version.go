@@ -0,0 +1,7 @@
+// Package version holds the build version, set at link time:
+//
+//	go build -ldflags "-X quaily-journalist/internal/version.Version=v1.2.3"
+package version
+
+// Version is the application version; "dev" for local builds.
+var Version = "dev"
@@ -0,0 +1,290 @@
+// Package youtube reads the newest videos of YouTube channels and playlists from
+// their public feeds (/feeds/videos.xml) into model.NewsItem and, given a Data API
+// key, adds each video's like and comment counts (/youtube/v3/videos).
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+// Source is the model.NewsItem source of YouTube videos.
+const Source = "youtube"
+
+// DefaultBaseURL serves the channel and playlist feeds.
+const DefaultBaseURL = "https://www.youtube.com"
+
+// DefaultAPIBaseURL serves the Data API.
+const DefaultAPIBaseURL = "https://www.googleapis.com/youtube/v3"
+
+// maxFeedBytes caps the feed body read per fetch; feeds list 15 videos.
+const maxFeedBytes = 5 << 20
+
+// apiBatch is the most video IDs one videos.list call accepts.
+const apiBatch = 50
+
+// Client reads feeds over HTTP and, when it has a key, calls the Data API.
+type Client struct {
+	baseURL    string
+	apiBaseURL string
+	key        string
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewClient returns a client for the feeds at baseURL (empty uses DefaultBaseURL)
+// and the Data API at apiBaseURL (empty uses DefaultAPIBaseURL). key is an optional
+// Data API key; without one, videos carry no counts.
+func NewClient(baseURL, apiBaseURL, key string) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	if strings.TrimSpace(apiBaseURL) == "" {
+		apiBaseURL = DefaultAPIBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		apiBaseURL: strings.TrimRight(strings.TrimSpace(apiBaseURL), "/"),
+		key:        strings.TrimSpace(key),
+		client:     &http.Client{Timeout: 15 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// WithHTTPClient returns a copy of the client using hc for requests (nil keeps the default).
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c2 := *c
+	if hc != nil {
+		c2.client = hc
+	}
+	return &c2
+}
+
+// ChannelVideos returns the newest videos of the channel with the given ID
+// (UC...). Videos are labeled with node, or with the channel's title when node is
+// empty.
+func (c *Client) ChannelVideos(ctx context.Context, channelID, node string) ([]model.NewsItem, error) {
+	return c.videos(ctx, "channel_id", channelID, node)
+}
+
+// PlaylistVideos returns the newest videos of the playlist with the given ID
+// (PL...). Videos are labeled with node, or with the title of the channel that
+// uploaded each one when node is empty.
+func (c *Client) PlaylistVideos(ctx context.Context, playlistID, node string) ([]model.NewsItem, error) {
+	return c.videos(ctx, "playlist_id", playlistID, node)
+}
+
+// videos reads the feed and, with a key, enriches its videos. An API failure is
+// logged and the feed's videos are returned without counts, so a used-up quota
+// does not stop collection.
+func (c *Client) videos(ctx context.Context, param, id, node string) ([]model.NewsItem, error) {
+	feedURL := c.baseURL + "/feeds/videos.xml?" + url.Values{param: {strings.TrimSpace(id)}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/atom+xml, application/xml;q=0.9")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("youtube: %s %s: status %d", param, id, resp.StatusCode)
+	}
+	items, err := Parse(io.LimitReader(resp.Body, maxFeedBytes), node, c.now())
+	if err != nil {
+		return nil, fmt.Errorf("youtube: %s %s: %w", param, id, err)
+	}
+	if c.key == "" || len(items) == 0 {
+		return items, nil
+	}
+	if err := c.enrich(ctx, items); err != nil {
+		slog.Warn("youtube: statistics unavailable; keeping feed videos without counts", param, id, "err", err)
+	}
+	return items, nil
+}
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Group struct {
+		Description string `xml:"http://search.yahoo.com/mrss/ description"`
+	} `xml:"http://search.yahoo.com/mrss/ group"`
+	Raw string `xml:",innerxml"`
+}
+
+// Parse reads a channel or playlist feed. Each entry with a video ID becomes an
+// item whose ID is the video ID and whose content is the video's description. The
+// node is node, else the uploading channel's title, else the feed's title. Entries
+// without a parseable date get now. Feeds carry no counts; see Client.
+func Parse(r io.Reader, node string, now time.Time) ([]model.NewsItem, error) {
+	var f atomFeed
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	var out []model.NewsItem
+	for _, e := range f.Entries {
+		id := strings.TrimSpace(e.VideoID)
+		if id == "" {
+			continue
+		}
+		channel := clean(e.Author.Name)
+		title := clean(e.Title)
+		if title == "" {
+			title = id
+		}
+		out = append(out, model.NewsItem{
+			Source:    Source,
+			ID:        id,
+			Title:     title,
+			URL:       WatchURL(id),
+			NodeName:  firstNonEmpty(node, channel, clean(f.Title)),
+			CreatedAt: parseDate(firstNonEmpty(e.Published, e.Updated), now),
+			Content:   strings.TrimSpace(e.Group.Description),
+			Author:    channel,
+			Raw:       []byte(strings.TrimSpace(e.Raw)),
+		})
+	}
+	return out, nil
+}
+
+// WatchURL returns the watch page of a video.
+func WatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + url.QueryEscape(videoID)
+}
+
+type videoList struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+type video struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Description string `json:"description"`
+	} `json:"snippet"`
+	// Counts are decimal strings; a count the uploader hides is missing.
+	Statistics struct {
+		ViewCount    string `json:"viewCount"`
+		LikeCount    string `json:"likeCount"`
+		CommentCount string `json:"commentCount"`
+	} `json:"statistics"`
+}
+
+// enrich looks items up in the Data API, up to apiBatch per call. Points become the
+// like count and Replies the comment count; views, which dwarf likes, are kept only
+// in Raw. A non-empty API description replaces the feed's, which YouTube may
+// shorten, and Raw becomes the API's JSON.
+func (c *Client) enrich(ctx context.Context, items []model.NewsItem) error {
+	byID := make(map[string]*model.NewsItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+	for start := 0; start < len(items); start += apiBatch {
+		ids := make([]string, 0, apiBatch)
+		for _, it := range items[start:min(start+apiBatch, len(items))] {
+			ids = append(ids, it.ID)
+		}
+		q := url.Values{"part": {"snippet,statistics"}, "id": {strings.Join(ids, ",")}, "key": {c.key}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBaseURL+"/videos?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		var list videoList
+		err = decodeAPI(resp, &list)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		for _, raw := range list.Items {
+			var v video
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("decode video: %w", err)
+			}
+			it := byID[v.ID]
+			if it == nil {
+				continue
+			}
+			it.Points = count(v.Statistics.LikeCount)
+			it.Replies = count(v.Statistics.CommentCount)
+			if d := strings.TrimSpace(v.Snippet.Description); d != "" {
+				it.Content = d
+			}
+			it.Raw = bytes.TrimSpace(raw)
+		}
+	}
+	return nil
+}
+
+// decodeAPI decodes a successful response into v; an error response reports the
+// API's message, e.g., that the daily quota is exceeded.
+func decodeAPI(resp *http.Response, v any) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+		if e.Error.Message != "" {
+			return fmt.Errorf("videos: status %d: %s", resp.StatusCode, e.Error.Message)
+		}
+		return fmt.Errorf("videos: status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode videos: %w", err)
+	}
+	return nil
+}
+
+func count(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// parseDate reads the feed's RFC 3339 dates; anything else is now.
+func parseDate(s string, now time.Time) time.Time {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(s)); err == nil {
+		return t.UTC()
+	}
+	return now.UTC()
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
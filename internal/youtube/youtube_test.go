@@ -0,0 +1,156 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+)
+
+var now = time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/videos.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	items, err := Parse(f, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2: %+v", len(items), items)
+	}
+	first := items[0]
+	want := model.NewsItem{
+		Source:    "youtube",
+		ID:        "abcDEF12345",
+		Title:     "Range over func iterators",
+		URL:       "https://www.youtube.com/watch?v=abcDEF12345",
+		NodeName:  "Go Example Channel",
+		CreatedAt: time.Date(2025, 10, 22, 16, 0, 6, 0, time.UTC),
+		Content:   "How iterators work in Go 1.23.\nSlides: https://example.com/slides",
+		Author:    "Go Example Channel",
+	}
+	if !strings.Contains(string(first.Raw), "<yt:videoId>abcDEF12345</yt:videoId>") {
+		t.Errorf("first item raw = %q, want the entry's XML", first.Raw)
+	}
+	first.Raw = nil
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("first item =\n%+v\nwant\n%+v", first, want)
+	}
+	if second := items[1]; second.ID != "ghiJKL67890" || second.Content != "" || !second.CreatedAt.Equal(now) || second.Points != 0 {
+		t.Errorf("second item (bad date, no description) = %+v", second)
+	}
+
+	f.Seek(0, 0)
+	labeled, err := Parse(f, "Go talks", now)
+	if err != nil || labeled[0].NodeName != "Go talks" || labeled[0].Author != "Go Example Channel" {
+		t.Errorf("labeled = %+v, %v", labeled, err)
+	}
+}
+
+func TestParseRejectsNonXML(t *testing.T) {
+	if _, err := Parse(strings.NewReader(`{"items": []}`), "", now); err == nil {
+		t.Error("Parse accepted JSON")
+	}
+}
+
+// server serves testdata/videos.xml as every feed and answers videos.list with the
+// statistics of the first video only, recording the requests.
+func server(t *testing.T, apiStatus int) (*httptest.Server, *[]string) {
+	t.Helper()
+	feed, err := os.ReadFile("testdata/videos.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+		switch r.URL.Path {
+		case "/feeds/videos.xml":
+			w.Write(feed)
+		case "/api/videos":
+			if apiStatus != http.StatusOK {
+				w.WriteHeader(apiStatus)
+				fmt.Fprint(w, `{"error": {"code": 403, "message": "The request cannot be completed because you have exceeded your quota."}}`)
+				return
+			}
+			fmt.Fprint(w, `{"items": [{"id": "abcDEF12345",
+				"snippet": {"title": "Range over func iterators", "description": "The full description."},
+				"statistics": {"viewCount": "20411", "likeCount": "812", "commentCount": "37"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// Without a key only the feed is read, and videos carry no counts.
+func TestChannelVideosFeedOnly(t *testing.T) {
+	srv, requests := server(t, http.StatusOK)
+	items, err := NewClient(srv.URL, srv.URL+"/api", "").ChannelVideos(context.Background(), "UC_GoExampleChannel0000", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Points != 0 || items[0].Replies != 0 || items[0].NodeName != "Go Example Channel" {
+		t.Errorf("items = %+v", items)
+	}
+	if len(*requests) != 1 || (*requests)[0] != "/feeds/videos.xml?channel_id=UC_GoExampleChannel0000" {
+		t.Errorf("requests = %v, want the channel feed only", *requests)
+	}
+}
+
+// With a key the videos are looked up in one call: likes become points, comments
+// replies, and the API description replaces the feed's.
+func TestPlaylistVideosWithAPIKey(t *testing.T) {
+	srv, requests := server(t, http.StatusOK)
+	items, err := NewClient(srv.URL, srv.URL+"/api", "secret").PlaylistVideos(context.Background(), "PLgo", "Go talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 || (*requests)[0] != "/feeds/videos.xml?playlist_id=PLgo" ||
+		(*requests)[1] != "/api/videos?id=abcDEF12345%2CghiJKL67890&key=secret&part=snippet%2Cstatistics" {
+		t.Fatalf("requests = %v", *requests)
+	}
+	first := items[0]
+	if first.Points != 812 || first.Replies != 37 || first.Content != "The full description." || first.NodeName != "Go talks" {
+		t.Errorf("enriched item = %+v", first)
+	}
+	if !strings.Contains(string(first.Raw), `"viewCount": "20411"`) {
+		t.Errorf("raw = %s, want the API's JSON", first.Raw)
+	}
+	// The API returned nothing for the second video, which keeps its feed fields.
+	if second := items[1]; second.Points != 0 || !strings.HasPrefix(string(second.Raw), "<") {
+		t.Errorf("unenriched item = %+v", second)
+	}
+}
+
+// A failing API call leaves the feed's videos as they are.
+func TestAPIErrorKeepsFeedVideos(t *testing.T) {
+	srv, _ := server(t, http.StatusForbidden)
+	items, err := NewClient(srv.URL, srv.URL+"/api", "secret").ChannelVideos(context.Background(), "UC_GoExampleChannel0000", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Points != 0 || !strings.HasPrefix(items[0].Content, "How iterators work") {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestFeedStatusError(t *testing.T) {
+	srv, _ := server(t, http.StatusOK)
+	_, err := NewClient(srv.URL+"/missing", "", "").ChannelVideos(context.Background(), "UCnope", "")
+	if err == nil || !strings.Contains(err.Error(), "channel_id UCnope: status 404") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/arxiv"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultArxivRequestGap is the wait between two API requests that arXiv asks
+// clients to keep.
+const DefaultArxivRequestGap = 3 * time.Second
+
+// ArxivCollector polls the newest submissions of arXiv categories and stores them
+// into period ZSETs under the category. Papers have neither replies nor points, so
+// the default ranking scores them by recency alone.
+type ArxivCollector struct {
+	Client     ArxivSource
+	Store      *storage.RedisStore
+	Categories []string
+	Interval   time.Duration
+	// RequestGap is the wait between categories; 0 uses DefaultArxivRequestGap,
+	// negative does not wait.
+	RequestGap time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores papers; zero fields use ranking.ForSource("arxiv"), recency
+	// with a floor of ranking recency_boost.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each paper's Atom entry; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Categories after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled categories; the change applies at the next run.
+func (w *ArxivCollector) SetNodes(units []string) (added []string) {
+	return setUnits(&w.mu, &w.Categories, units, stringKey)
+}
+
+func (w *ArxivCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = time.Hour
+	}
+	if !waitForResume(ctx, w.Store, arxivCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// arxivCollectorName identifies the collector's persisted status record.
+const arxivCollectorName = "arxiv-collector"
+
+func (w *ArxivCollector) Name() string { return arxivCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Categories that fail are logged, counted, and
+// joined into the error; the others are still stored.
+func (w *ArxivCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, arxivCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, arxivCollectorName, arxiv.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, arxivCollectorName, started, err)
+	countCollected(ctx, w.Store, arxiv.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *ArxivCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	cats := currentUnits(&w.mu, &w.Categories)
+	gap := w.RequestGap
+	if gap == 0 {
+		gap = DefaultArxivRequestGap
+	}
+	loop := collectLoop{source: arxiv.Source, unit: "category", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		before: func(i int) error {
+			if i == 0 || gap <= 0 {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(gap):
+				return nil
+			}
+		}}
+	return loop.run(ctx, cats, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return w.Client.Papers(ctx, cats[i])
+	})
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quaily-journalist/internal/arxiv"
+	"quaily-journalist/internal/mocksource"
+)
+
+// Papers carry neither replies nor points; they still score by recency, pass the
+// builder's filters, and make a digest linking their category listing.
+func TestArxivDigestFromFixtures(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	c := &ArxivCollector{Client: mocksource.NewArxiv(filepath.Join("..", "fixtures")), Store: store, Categories: []string{"cs.CL", "cs.LG"}, RequestGap: -1}
+	res, err := c.RunOnce(ctx)
+	if res.Stored != 3 || res.Failed != 1 || err == nil || !strings.Contains(err.Error(), "category cs.LG") {
+		t.Fatalf("RunOnce = %+v, %v; want cs.CL stored and cs.LG (no fixture) failed", res, err)
+	}
+
+	b := &NewsletterBuilder{
+		Store:     store,
+		Source:    arxiv.Source,
+		Channel:   "papers",
+		Frequency: "daily",
+		TopN:      10,
+		MinItems:  3,
+		Nodes:     []string{"cs.cl"},
+		BaseURL:   arxiv.SiteURL,
+		OutputDir: t.TempDir(),
+	}
+	out, err := b.RunOnce(ctx)
+	if err != nil || out.Path == "" {
+		t.Fatalf("builder RunOnce = %+v, %v", out, err)
+	}
+	md, err := os.ReadFile(out.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## [Sparse Attention at Scale: Long-Context Retrieval Without the Quadratic Cost](https://arxiv.org/abs/2510.20001v2)",
+		"[@cs.CL](https://arxiv.org/list/cs.CL/new)",
+		"## [A Note on Tokenizers]",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("digest lacks %q:\n%s", want, md)
+		}
+	}
+}
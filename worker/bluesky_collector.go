@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/bluesky"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// BlueskyFeed is a custom feed (URI) or a search (Query) the Bluesky collector
+// polls; exactly one is set. Its posts are stored under Node.
+type BlueskyFeed struct {
+	Node  string
+	URI   string
+	Query string
+}
+
+// BlueskyCollector polls Bluesky custom feeds and searches and stores their posts
+// into period ZSETs under each feed's node, the way the RSS collector does for
+// feeds.
+type BlueskyCollector struct {
+	Client   BlueskySource
+	Store    *storage.RedisStore
+	Feeds    []BlueskyFeed
+	Interval time.Duration
+	// Limit is the most posts read per feed or search, following the cursor; 0 uses
+	// bluesky.DefaultLimit.
+	Limit int
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores posts; zero fields use ranking.ForSource("bluesky"), the Hacker
+	// News formula on likes.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each post's JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Feeds after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled feeds; the nodes added are returned; the change applies at the next run.
+func (w *BlueskyCollector) SetNodes(units []BlueskyFeed) (added []string) {
+	return setUnits(&w.mu, &w.Feeds, units, func(f BlueskyFeed) string { return f.Node })
+}
+
+func (w *BlueskyCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, blueskyCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// blueskyCollectorName identifies the collector's persisted status record.
+const blueskyCollectorName = "bluesky-collector"
+
+func (w *BlueskyCollector) Name() string { return blueskyCollectorName }
+
+// Nodes returns the nodes of the polled feeds.
+func (w *BlueskyCollector) Nodes() []string {
+	nodes := make([]string, len(w.Feeds))
+	for i, f := range w.Feeds {
+		nodes[i] = f.Node
+	}
+	return nodes
+}
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Feeds that fail are logged, counted, and joined
+// into the error; the others are still stored.
+func (w *BlueskyCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, blueskyCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, blueskyCollectorName, bluesky.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, blueskyCollectorName, started, err)
+	countCollected(ctx, w.Store, bluesky.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *BlueskyCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	feeds := currentUnits(&w.mu, &w.Feeds)
+	nodes := make([]string, len(feeds))
+	for i, f := range feeds {
+		nodes[i] = f.Node
+	}
+	loop := collectLoop{source: bluesky.Source, unit: "feed", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now}
+	return loop.run(ctx, nodes, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return w.fetch(ctx, feeds[i])
+	})
+}
+
+func (w *BlueskyCollector) fetch(ctx context.Context, f BlueskyFeed) ([]model.NewsItem, error) {
+	if f.URI != "" {
+		return w.Client.Feed(ctx, f.URI, f.Node, w.Limit)
+	}
+	return w.Client.SearchPosts(ctx, f.Query, f.Node, w.Limit)
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/httpclient"
+	"quaily-journalist/internal/storage"
+)
+
+// skipBlocked returns why service's shared request guard refuses requests now (the
+// hourly budget is spent or the circuit is open), or nil. The collector named worker
+// then skips its run; the reason is logged and recorded as its last error, where
+// `status` shows it until a later run completes.
+func skipBlocked(ctx context.Context, store *storage.RedisStore, worker, service string, at time.Time) error {
+	b, blocked := httpclient.Blocked(service)
+	if !blocked {
+		return nil
+	}
+	slog.Warn("collector: skipping run", "worker", worker, "service", service, "reason", b.Reason, "until", b.Until)
+	recordOutcome(ctx, store, worker, at, b)
+	return b
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/config"
+	"quaily-journalist/internal/httpclient"
+)
+
+func TestSkipBlockedRecordsReason(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	if err := skipBlocked(ctx, store, "test-collector", "skip-test", time.Now()); err != nil {
+		t.Fatalf("skipBlocked without a guard = %v", err)
+	}
+
+	hc, err := httpclient.New(config.Config{HTTP: config.HTTPConfig{Services: map[string]config.HTTPClientConfig{
+		"skip-test": {MaxPerHour: 1},
+	}}}, "skip-test", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := hc.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := skipBlocked(ctx, store, "test-collector", "skip-test", time.Now()); err == nil {
+		t.Fatal("run not skipped with the budget spent")
+	}
+	st, ok, err := store.GetWorkerStatus(ctx, "test-collector")
+	if err != nil || !ok || !strings.Contains(st.LastError, "http skip-test: request budget exhausted until") {
+		t.Fatalf("status = %+v, %v, %v", st, ok, err)
+	}
+}
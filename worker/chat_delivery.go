@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/storage"
+)
+
+// ChatTarget posts a summary of a channel's digests to a Discord or Slack
+// incoming webhook, built from the Markdown output.
+type ChatTarget struct {
+	Webhook *notify.ChatWebhook
+}
+
+// ChatKey keys DeliveryReconciler.Chat by channel and target (storage.TargetDiscord
+// or storage.TargetSlack).
+func ChatKey(channel, target string) string { return channel + ":" + target }
+
+// itemHeadingRe matches a digest item heading, "## [title](url)".
+var itemHeadingRe = regexp.MustCompile(`^## \[(.*)\]\((\S+)\)\s*$`)
+
+// Send posts the messages of t that were not sent yet, advancing t.Progress after each.
+func (ct ChatTarget) Send(ctx context.Context, t *storage.DeliveryTask) error {
+	p := t.Paths[newsletter.FormatMarkdown]
+	if p == "" {
+		return fmt.Errorf("%s: digest %s has no markdown output", t.Target, t.Slug)
+	}
+	doc, err := markdown.ParseFile(p)
+	if err != nil {
+		return fmt.Errorf("%s: read markdown: %w", t.Target, err)
+	}
+	payloads, err := ct.Webhook.Payloads(chatDigest(t.Title, doc))
+	if err != nil {
+		return err
+	}
+	for i := t.Progress; i < len(payloads); i++ {
+		if err := ct.Webhook.Post(ctx, payloads[i]); err != nil {
+			return fmt.Errorf("%s: message %d/%d: %w", t.Target, i+1, len(payloads), err)
+		}
+		t.Progress = i + 1
+	}
+	return nil
+}
+
+// chatDigest extracts the title, frontmatter summary, and item links of a digest.
+func chatDigest(title string, doc markdown.Document) notify.Digest {
+	d := notify.Digest{Title: title}
+	if d.Title == "" {
+		d.Title, _ = doc.Frontmatter["title"].(string)
+	}
+	d.Summary, _ = doc.Frontmatter["summary"].(string)
+	for _, line := range strings.Split(strings.ReplaceAll(doc.Body, "\r\n", "\n"), "\n") {
+		if m := itemHeadingRe.FindStringSubmatch(line); m != nil {
+			d.Items = append(d.Items, notify.DigestItem{Title: m[1], URL: m[2]})
+		}
+	}
+	return d
+}
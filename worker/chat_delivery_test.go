@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/storage"
+)
+
+func TestChatDeliveryPostsDigestSummary(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	items := make([]newsletter.Item, 8)
+	for i := range items {
+		items[i] = newsletter.Item{Title: fmt.Sprintf("Item_%d", i), URL: fmt.Sprintf("https://example.com/%d", i), NodeName: "go"}
+	}
+	out, err := newsletter.Render(newsletter.Data{Title: "Daily", Slug: "daily-20251024", ShortSummary: "Five *great* stories", Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "daily-20251024.md")
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &DeliveryReconciler{
+		Store: newDeliveryTestStore(t),
+		Chat: map[string]ChatTarget{
+			ChatKey("ch", storage.TargetDiscord): {Webhook: &notify.ChatWebhook{Platform: notify.PlatformDiscord, URL: srv.URL}},
+			ChatKey("ch", storage.TargetSlack):   {Webhook: &notify.ChatWebhook{Platform: notify.PlatformSlack, URL: srv.URL}},
+		},
+	}
+	for _, target := range []string{storage.TargetDiscord, storage.TargetSlack} {
+		task := storage.DeliveryTask{Channel: "ch", Slug: "daily-20251024", Target: target, Title: "Daily",
+			Paths: map[string]string{newsletter.FormatMarkdown: path}, State: storage.DeliveryPending}
+		if task = rec.Deliver(context.Background(), task); task.State != storage.DeliveryDone {
+			t.Fatalf("%s: %+v", target, task)
+		}
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("posted %d payloads, want one per platform", len(bodies))
+	}
+	embed := bodies[0]["embeds"].([]any)[0].(map[string]any)
+	desc, _ := embed["description"].(string)
+	if embed["title"] != "Daily" || !strings.Contains(desc, `Five \*great\* stories`) ||
+		!strings.Contains(desc, `5\. [Item\_4](https://example.com/4)`) || strings.Contains(desc, "Item\\_5") {
+		t.Errorf("discord embed = %v", embed)
+	}
+	text, _ := bodies[1]["text"].(string)
+	if !strings.HasPrefix(text, "*Daily*\nFive *great* stories") ||
+		!strings.Contains(text, "• <https://example.com/4|Item_4>") || strings.Contains(text, "Item_5") {
+		t.Errorf("slack text = %q", text)
+	}
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// collectLoop is the collect pass the single-source collectors share: it stores
+// what the buffer still holds, fetches each unit (a list, subreddit, feed, ...) in
+// turn, and stores the items scoring above zero at now under the day and week of
+// now. A unit that fails is logged, counted, and joined into the error; the others
+// are still stored.
+type collectLoop struct {
+	source     string // store key and ranking defaults, e.g., "reddit"
+	unit       string // what one fetch reads, for logs and errors, e.g., "subreddit"
+	store      *storage.RedisStore
+	buffer     *storeBuffer
+	bufferSize int
+	archiveRaw bool
+	ranking    ranking.Scorer
+	now        time.Time
+
+	// before, when set, runs ahead of unit i; an error skips that unit and the rest,
+	// counting them failed, e.g., while rate limited.
+	before func(i int) error
+	// keep, when set, drops the items it returns false for before scoring.
+	keep func(model.NewsItem) bool
+	// clean, when set, rewrites each item that is stored.
+	clean func(model.NewsItem) model.NewsItem
+}
+
+// run fetches units[i] with fetch(ctx, i) for every unit; units are the labels
+// logged and named in errors.
+func (l collectLoop) run(ctx context.Context, units []string, fetch func(ctx context.Context, i int) ([]model.NewsItem, error)) (CollectResult, error) {
+	day := period.Key(period.Daily, l.now.UTC())
+	week := period.Key(period.Weekly, l.now.UTC())
+	scorer := ranking.ForSource(l.source).Merge(l.ranking)
+	logPrefix := l.source + " collector: "
+	var res CollectResult
+	var errs []error
+	l.buffer.source, l.buffer.max, l.buffer.archiveRaw = l.source, l.bufferSize, l.archiveRaw
+	res.Stored, _ = l.buffer.flush(ctx, l.store)
+	for i, unit := range units {
+		if l.before != nil {
+			if err := l.before(i); err != nil {
+				res.Failed += len(units) - i
+				errs = append(errs, err)
+				break
+			}
+		}
+		items, err := fetch(ctx, i)
+		if err != nil {
+			slog.Error(logPrefix+"fetch "+l.unit+" failed", l.unit, unit, "error", err)
+			res.Failed++
+			errs = append(errs, fmt.Errorf("%s %s: %w", l.unit, unit, err))
+			continue
+		}
+		res.Fetched += len(items)
+		stored := 0
+		for _, it := range items {
+			if l.keep != nil && !l.keep(it) {
+				continue
+			}
+			score := scorer.Score(it, l.now)
+			if score <= 0 {
+				continue
+			}
+			if l.clean != nil {
+				it = l.clean(it)
+			}
+			ok, err := l.buffer.store(ctx, l.store, bufferedItem{item: it, score: score, periods: []string{day, week}})
+			if err != nil {
+				slog.Error(logPrefix+"store error", "id", it.ID, "error", err)
+			} else if ok {
+				stored++
+			}
+		}
+		slog.Info(logPrefix+"completed for "+l.unit, l.unit, unit, "stored", stored, "periods", []string{day, week})
+		res.Stored += stored
+	}
+	res.Buffered = l.buffer.len()
+	if err := l.buffer.err(); err != nil {
+		slog.Error(logPrefix+"items held until redis is back", "buffered", res.Buffered, "error", err)
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+)
+
+// Every collector on the shared loop stores under the day and week of its own clock,
+// not the wall clock's, and a unit without a fixture is counted, named in the error,
+// and does not keep the others out.
+func TestCollectorsStoreUnderTheirClock(t *testing.T) {
+	dir := filepath.Join("..", "fixtures")
+	at := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return at }
+	for _, tc := range []struct {
+		source string
+		failed string // the unit named in the error; "" when none fails
+		run    func(ctx context.Context, store *storage.RedisStore) (CollectResult, error)
+	}{
+		{"lobsters", "list newest", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &LobstersCollector{Client: &mocksource.Lobsters{Dir: dir, Now: clock}, Store: store, Now: clock, Lists: []string{"hottest", "newest"}}
+			return c.RunOnce(ctx)
+		}},
+		{"reddit", "subreddit rust", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &RedditCollector{Client: &mocksource.Reddit{Dir: dir, Now: clock}, Store: store, Now: clock, Subreddits: []string{"golang", "rust"}}
+			return c.RunOnce(ctx)
+		}},
+		{"github", "language zig", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &GitHubTrendingCollector{Client: &mocksource.GitHub{Dir: dir, Now: clock}, Store: store, Now: clock, Languages: []string{"go", "zig"}}
+			return c.RunOnce(ctx)
+		}},
+		{"producthunt", "", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &ProductHuntCollector{Client: &mocksource.ProductHunt{Dir: dir, Now: clock}, Store: store, Now: clock}
+			return c.RunOnce(ctx)
+		}},
+		{"arxiv", "category cs.LG", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &ArxivCollector{Client: &mocksource.Arxiv{Dir: dir, Now: clock}, Store: store, Now: clock, Categories: []string{"cs.CL", "cs.LG"}, RequestGap: -1}
+			return c.RunOnce(ctx)
+		}},
+		{"mastodon", "instance down.example", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &MastodonCollector{Store: store, Now: clock, Instances: []MastodonSource{
+				&mocksource.Mastodon{Dir: dir, Instance: "mastodon.social", Now: clock},
+				&mocksource.Mastodon{Dir: dir, Instance: "down.example", Now: clock},
+			}}
+			return c.RunOnce(ctx)
+		}},
+		{"bluesky", "feed gone", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &BlueskyCollector{Client: &mocksource.Bluesky{Dir: dir, Now: clock}, Store: store, Now: clock, Feeds: []BlueskyFeed{
+				{Node: "golang", Query: "golang"},
+				{Node: "gone", URI: "at://did:plc:f/app.bsky.feed.generator/gone"},
+			}}
+			return c.RunOnce(ctx)
+		}},
+		{"stackoverflow", "tag zig", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &StackOverflowCollector{Client: &mocksource.StackOverflow{Dir: dir, Now: clock}, Store: store, Now: clock, Tags: []string{"go", "zig"}}
+			return c.RunOnce(ctx)
+		}},
+		{"jsonfeed", "feed https://broken.example/feed.json", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &JSONFeedCollector{Client: &mocksource.JSONFeed{Dir: dir, Now: clock}, Store: store, Now: clock, Feeds: []JSONFeed{
+				{URL: "https://indie.example/feed.json", Label: "indie"},
+				{URL: "https://broken.example/feed.json", Label: "broken"},
+			}}
+			return c.RunOnce(ctx)
+		}},
+		{"youtube", "playlist PLgone", func(ctx context.Context, store *storage.RedisStore) (CollectResult, error) {
+			c := &YouTubeCollector{Client: &mocksource.YouTube{Dir: dir, Now: clock}, Store: store, Now: clock, Feeds: []YouTubeFeed{
+				{ChannelID: "UCgo", Label: "golang"},
+				{PlaylistID: "PLgone"},
+			}}
+			return c.RunOnce(ctx)
+		}},
+	} {
+		t.Run(tc.source, func(t *testing.T) {
+			ctx := context.Background()
+			store := newDeliveryTestStore(t)
+			res, err := tc.run(ctx, store)
+			wantFailed := 0
+			if tc.failed != "" {
+				wantFailed = 1
+			}
+			if res.Stored == 0 || res.Failed != wantFailed {
+				t.Fatalf("RunOnce = %+v, %v; want items stored and %d failed", res, err, wantFailed)
+			}
+			if tc.failed == "" && err != nil || tc.failed != "" && (err == nil || !strings.Contains(err.Error(), tc.failed)) {
+				t.Errorf("RunOnce error = %v; want %q named", err, tc.failed)
+			}
+			for _, p := range []string{period.Key(period.Daily, at), period.Key(period.Weekly, at)} {
+				if got, err := store.TopNews(ctx, tc.source, p, 100); err != nil || len(got) != res.Stored {
+					t.Errorf("TopNews(%s) = %d items, %v; want the %d stored", p, len(got), err, res.Stored)
+				}
+			}
+			if got, _ := store.TopNews(ctx, tc.source, period.Key(period.Daily, time.Now()), 100); len(got) != 0 {
+				t.Errorf("%d items stored under the wall clock's day", len(got))
+			}
+		})
+	}
+}
+
+// The loop's hooks: before stops the run, counting the units left as failed; keep
+// drops items before scoring; clean rewrites the items stored.
+func TestCollectLoopHooks(t *testing.T) {
+	now := time.Now()
+	items := func(ids ...string) []model.NewsItem {
+		var out []model.NewsItem
+		for _, id := range ids {
+			out = append(out, model.NewsItem{ID: id, Title: "t" + id, URL: "https://example.com/" + id, Source: "reddit", NodeName: "n", Points: 10, CreatedAt: now.Add(-time.Hour)})
+		}
+		return out
+	}
+	stop := errors.New("stop")
+	for _, tc := range []struct {
+		name       string
+		loop       collectLoop
+		wantStored []string
+		wantFailed int
+	}{
+		{"plain", collectLoop{}, []string{"a", "b", "c", "d"}, 0},
+		{"before stops", collectLoop{before: func(i int) error {
+			if i == 1 {
+				return stop
+			}
+			return nil
+		}}, []string{"a", "b"}, 2},
+		{"keep", collectLoop{keep: func(it model.NewsItem) bool { return it.ID != "b" }}, []string{"a", "c", "d"}, 0},
+		{"clean", collectLoop{clean: func(it model.NewsItem) model.NewsItem {
+			it.Content = "clean"
+			return it
+		}}, []string{"a", "b", "c", "d"}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newDeliveryTestStore(t)
+			l := tc.loop
+			l.source, l.unit, l.store, l.buffer, l.now = "reddit", "subreddit", store, &storeBuffer{}, now
+			fetched := [][]model.NewsItem{items("a", "b"), items("c"), items("d")}
+			res, err := l.run(ctx, []string{"one", "two", "three"}, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+				return fetched[i], nil
+			})
+			if res.Failed != tc.wantFailed || (tc.wantFailed > 0) != errors.Is(err, stop) {
+				t.Fatalf("run = %+v, %v", res, err)
+			}
+			got, _ := store.TopNews(ctx, "reddit", period.Key(period.Daily, now), 10)
+			ids := itemIDs(got)
+			slices.Sort(ids)
+			if !slices.Equal(ids, tc.wantStored) {
+				t.Errorf("stored %v, want %v", ids, tc.wantStored)
+			}
+			if tc.loop.clean != nil && got[0].Item.Content != "clean" {
+				t.Errorf("stored content %q, want it cleaned", got[0].Item.Content)
+			}
+		})
+	}
+}
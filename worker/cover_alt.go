@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/fsutil"
+)
+
+// CoverAlt returns the alt text of the cover at coverPath. For a cover generated
+// in this run (prompt non-empty) it asks the summarizer and saves the answer next
+// to the image, so a reused cover keeps its alt text; for an existing cover it
+// reads the saved text. Without either it falls back to "Cover image for <title>".
+func CoverAlt(ctx context.Context, summarizer ai.Summarizer, coverPath, title, prompt, language string) string {
+	sidecar := coverPath + ".alt.txt"
+	if prompt == "" {
+		if b, err := os.ReadFile(sidecar); err == nil && strings.TrimSpace(string(b)) != "" {
+			return strings.TrimSpace(string(b))
+		}
+	} else if summarizer != nil {
+		alt, err := summarizer.CoverAlt(ctx, title, prompt, language)
+		alt = strings.TrimSpace(alt)
+		if err == nil && alt != "" {
+			if err := fsutil.WriteFileAtomic(sidecar, []byte(alt+"\n")); err != nil {
+				slog.Warn("cover: save alt text failed", "err", err, "path", sidecar)
+			}
+			return alt
+		}
+		slog.Warn("cover: alt text generation failed", "err", err, "path", coverPath)
+	}
+	return fmt.Sprintf("Cover image for %s", title)
+}
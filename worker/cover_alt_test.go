@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"quaily-journalist/internal/ai"
+)
+
+// altSummarizer answers CoverAlt only; other methods are not used here.
+type altSummarizer struct {
+	ai.Summarizer
+	alt string
+	err error
+}
+
+func (s altSummarizer) CoverAlt(ctx context.Context, title, prompt, language string) (string, error) {
+	return s.alt, s.err
+}
+
+func TestCoverAlt(t *testing.T) {
+	ctx := context.Background()
+	cover := filepath.Join(t.TempDir(), "cover.webp")
+
+	if got := CoverAlt(ctx, nil, cover, "Daily", "a prompt", "English"); got != "Cover image for Daily" {
+		t.Errorf("no summarizer: %q", got)
+	}
+	if got := CoverAlt(ctx, altSummarizer{err: errors.New("down")}, cover, "Daily", "a prompt", "English"); got != "Cover image for Daily" {
+		t.Errorf("AI error: %q", got)
+	}
+	s := altSummarizer{alt: " A lighthouse over a stormy sea of code. "}
+	if got := CoverAlt(ctx, s, cover, "Daily", "a prompt", "English"); got != "A lighthouse over a stormy sea of code." {
+		t.Errorf("generated: %q", got)
+	}
+	// A reused cover (no prompt) keeps the saved alt text without another AI call.
+	if got := CoverAlt(ctx, altSummarizer{err: errors.New("must not be called")}, cover, "Daily", "", "English"); got != "A lighthouse over a stormy sea of code." {
+		t.Errorf("reused cover: %q", got)
+	}
+}
@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+// Delivery retry defaults.
+const (
+	DefaultDeliveryMaxAttempts = 8
+	defaultDeliveryBaseDelay   = time.Minute
+	defaultDeliveryMaxDelay    = time.Hour
+)
+
+// DeliveryReconciler retries pending deliveries (Quaily sends, emails, Telegram, Discord and Slack posts) recorded by
+// the builders. A failed attempt is rescheduled with capped exponential backoff;
+// after MaxAttempts failures the task is dead-lettered and the Notifier is told.
+type DeliveryReconciler struct {
+	Store       *storage.RedisStore
+	Quaily      map[string]*quaily.Client // per channel (its quaily_profile's client); channels without one fail Quaily tasks
+	Email       map[string]EmailTarget    // per channel, likewise for email tasks
+	Telegram    map[string]TelegramTarget // per channel, likewise
+	Chat        map[string]ChatTarget     // per "<channel>:<target>" (see ChatKey), for Discord and Slack tasks
+	Notifier    notify.Notifier           // nil only logs dead-lettered tasks
+	Interval    time.Duration             // how often to scan for due tasks; default 30s
+	MaxAttempts int                       // 0 uses DefaultDeliveryMaxAttempts
+	BaseDelay   time.Duration             // delay after the first failure; default 1m
+	MaxDelay    time.Duration             // backoff cap; default 1h
+	Now         func() time.Time
+}
+
+// QueueDelivery records a pending Quaily delivery of a published post, first attempted
+// at at. quailyChannel is the Quaily channel slug when it differs from channel (preview
+// mode); empty means channel.
+func QueueDelivery(ctx context.Context, store *storage.RedisStore, channel, quailyChannel, slug string, at time.Time) error {
+	t := storage.DeliveryTask{Channel: channel, Slug: slug, Target: storage.TargetQuaily}
+	if quailyChannel != channel {
+		t.QuailyChannel = quailyChannel
+	}
+	return QueueTask(ctx, store, t, at)
+}
+
+// QueueTask records t as a pending delivery first attempted at at.
+func QueueTask(ctx context.Context, store *storage.RedisStore, t storage.DeliveryTask, at time.Time) error {
+	now := time.Now().UTC()
+	t.State = storage.DeliveryPending
+	t.NextAttemptAt = at
+	t.CreatedAt, t.UpdatedAt = now, now
+	return store.SaveDelivery(ctx, t)
+}
+
+func (w *DeliveryReconciler) Name() string { return "delivery-reconciler" }
+
+func (w *DeliveryReconciler) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Second
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+	for {
+		w.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+func (w *DeliveryReconciler) runOnce(ctx context.Context) {
+	tasks, err := w.Store.DueDeliveries(ctx, nowFunc(w.Now))
+	if err != nil {
+		slog.Warn("deliveries: scan due tasks failed", "err", err)
+		return
+	}
+	for _, t := range tasks {
+		w.Deliver(ctx, t)
+	}
+}
+
+// Deliver makes one delivery attempt for t, stores the outcome, and returns the updated task.
+func (w *DeliveryReconciler) Deliver(ctx context.Context, t storage.DeliveryTask) storage.DeliveryTask {
+	ctxDel, cancel := context.WithTimeout(ctx, 30*time.Second)
+	err := w.send(ctxDel, &t)
+	cancel()
+	target := t.Target
+	if target == "" {
+		target = storage.TargetQuaily
+	}
+	now := nowFunc(w.Now).UTC()
+	t.Attempts++
+	t.UpdatedAt = now
+	switch {
+	case err == nil:
+		t.State = storage.DeliveryDone
+		t.LastError = ""
+		slog.Info("deliveries: deliver ok", "target", target, "channel", t.Channel, "slug", t.Slug, "attempts", t.Attempts)
+	case t.Attempts >= w.maxAttempts():
+		t.State = storage.DeliveryDead
+		t.LastError = err.Error()
+		slog.Error("deliveries: giving up on delivery", "target", target, "channel", t.Channel, "slug", t.Slug, "attempts", t.Attempts, "err", err)
+		w.notify(ctx, notify.Event{
+			Kind:    notify.KindDeliveryFailed,
+			Channel: t.Channel,
+			Message: fmt.Sprintf("%s delivery of %s failed after %d attempts: %v", target, t.Slug, t.Attempts, err),
+			Time:    now,
+		})
+	default:
+		t.State = storage.DeliveryPending
+		t.LastError = err.Error()
+		t.NextAttemptAt = now.Add(w.backoff(t.Attempts))
+		slog.Warn("deliveries: deliver failed; will retry", "target", target, "channel", t.Channel, "slug", t.Slug, "attempts", t.Attempts, "next_attempt_at", t.NextAttemptAt, "err", err)
+	}
+	if err := w.Store.SaveDelivery(ctx, t); err != nil {
+		slog.Warn("deliveries: save task failed", "channel", t.Channel, "slug", t.Slug, "err", err)
+	}
+	return t
+}
+
+// send performs the delivery of t to its target; targets may record progress in t.
+func (w *DeliveryReconciler) send(ctx context.Context, t *storage.DeliveryTask) error {
+	switch t.Target {
+	case "", storage.TargetQuaily:
+		qc := w.Quaily[t.Channel]
+		if qc == nil {
+			return fmt.Errorf("quaily is not configured for channel %s", t.Channel)
+		}
+		ch := t.Channel
+		if t.QuailyChannel != "" {
+			ch = t.QuailyChannel
+		}
+		return qc.DeliverPost(ctx, ch, t.Slug)
+	case storage.TargetEmail:
+		et, ok := w.Email[t.Channel]
+		if !ok || et.Sender == nil {
+			return fmt.Errorf("email is not configured for channel %s", t.Channel)
+		}
+		return et.Send(ctx, *t)
+	case storage.TargetTelegram:
+		tt, ok := w.Telegram[t.Channel]
+		if !ok || tt.Client == nil {
+			return fmt.Errorf("telegram is not configured for channel %s", t.Channel)
+		}
+		if err := tt.Send(ctx, t); err != nil {
+			return err
+		}
+		w.recordTelegramSent(ctx, *t)
+		return nil
+	case storage.TargetDiscord, storage.TargetSlack:
+		ct, ok := w.Chat[ChatKey(t.Channel, t.Target)]
+		if !ok || ct.Webhook == nil {
+			return fmt.Errorf("%s is not configured for channel %s", t.Target, t.Channel)
+		}
+		return ct.Send(ctx, t)
+	}
+	return fmt.Errorf("unknown delivery target %q", t.Target)
+}
+
+func (w *DeliveryReconciler) maxAttempts() int {
+	if w.MaxAttempts <= 0 {
+		return DefaultDeliveryMaxAttempts
+	}
+	return w.MaxAttempts
+}
+
+// backoff returns the delay after the given number of failed attempts: BaseDelay doubled
+// per additional failure, capped at MaxDelay.
+func (w *DeliveryReconciler) backoff(attempts int) time.Duration {
+	base, ceiling := w.BaseDelay, w.MaxDelay
+	if base <= 0 {
+		base = defaultDeliveryBaseDelay
+	}
+	if ceiling <= 0 {
+		ceiling = defaultDeliveryMaxDelay
+	}
+	d := base
+	for i := 1; i < attempts && d < ceiling; i++ {
+		d *= 2
+	}
+	if d > ceiling {
+		d = ceiling
+	}
+	return d
+}
+
+func (w *DeliveryReconciler) notify(ctx context.Context, ev notify.Event) {
+	if w.Notifier == nil {
+		return
+	}
+	if err := w.Notifier.Notify(ctx, ev); err != nil {
+		slog.Warn("deliveries: notification failed", "kind", ev.Kind, "channel", ev.Channel, "err", err)
+	}
+}
@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestDeliveryReconcilerRetriesThenSucceeds(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n < 3 {
+			http.Error(w, "upstream hiccup", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := newDeliveryTestStore(t)
+	now := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	rec := &DeliveryReconciler{
+		Store:  store,
+		Quaily: map[string]*quaily.Client{"ch": quaily.New(srv.URL, "key", time.Second)},
+		Now:    func() time.Time { return now },
+	}
+	ctx := context.Background()
+	if err := QueueDelivery(ctx, store, "ch", "", "daily-20251024", now); err != nil {
+		t.Fatal(err)
+	}
+
+	// First attempt fails and is rescheduled one BaseDelay later.
+	rec.runOnce(ctx)
+	task, _, _ := store.GetDelivery(ctx, "ch", "daily-20251024", storage.TargetQuaily)
+	if task.State != storage.DeliveryPending || task.Attempts != 1 || !task.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after first failure: %+v", task)
+	}
+	// Not due yet: nothing happens.
+	rec.runOnce(ctx)
+	if calls != 1 {
+		t.Fatalf("calls = %d before the task was due, want 1", calls)
+	}
+	// Second failure doubles the delay.
+	now = now.Add(time.Minute)
+	rec.runOnce(ctx)
+	task, _, _ = store.GetDelivery(ctx, "ch", "daily-20251024", storage.TargetQuaily)
+	if task.Attempts != 2 || !task.NextAttemptAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("after second failure: %+v", task)
+	}
+	now = now.Add(2 * time.Minute)
+	rec.runOnce(ctx)
+	task, _, _ = store.GetDelivery(ctx, "ch", "daily-20251024", storage.TargetQuaily)
+	if task.State != storage.DeliveryDone || task.LastError != "" {
+		t.Fatalf("after success: %+v", task)
+	}
+	if due, _ := store.DueDeliveries(ctx, now.Add(time.Hour)); len(due) != 0 {
+		t.Errorf("done task still due: %+v", due)
+	}
+}
+
+func TestDeliveryReconcilerDeadLettersAndNotifies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	events := make(chan notify.Event, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev notify.Event
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		events <- ev
+	}))
+	defer hook.Close()
+
+	store := newDeliveryTestStore(t)
+	rec := &DeliveryReconciler{
+		Store:       store,
+		Quaily:      map[string]*quaily.Client{"ch": quaily.New(srv.URL, "key", time.Second)},
+		Notifier:    notify.NewWebhooks([]string{hook.URL}, nil),
+		MaxAttempts: 2,
+	}
+	ctx := context.Background()
+	task := storage.DeliveryTask{Channel: "ch", Slug: "daily-20251024", State: storage.DeliveryPending}
+	task = rec.Deliver(ctx, task)
+	if task.State != storage.DeliveryPending {
+		t.Fatalf("after one failure state = %s, want pending", task.State)
+	}
+	task = rec.Deliver(ctx, task)
+	if task.State != storage.DeliveryDead || task.LastError == "" {
+		t.Fatalf("after max attempts: %+v", task)
+	}
+	select {
+	case ev := <-events:
+		if ev.Kind != notify.KindDeliveryFailed || ev.Channel != "ch" {
+			t.Errorf("notification = %+v", ev)
+		}
+	default:
+		t.Fatal("no notification for dead-lettered delivery")
+	}
+}
+
+func TestDeliveryBackoffIsCapped(t *testing.T) {
+	rec := &DeliveryReconciler{BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, w := range want {
+		if got := rec.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func newDeliveryTestStore(t *testing.T) *storage.RedisStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return storage.NewRedisStore(rdb)
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+)
+
+// ErrNothingToDerive is returned for a derived digest whose daily channel published
+// no digest during the week.
+var ErrNothingToDerive = errors.New("no published digests to derive from")
+
+// DeriveGrace is how long after its week ends a derived digest waits for the daily
+// channel to close the week's last day before it is built without it.
+const DeriveGrace = 6 * time.Hour
+
+// DerivedItems returns the items the daily channel from published during the weekly
+// period key, each once, scored by their stored score in that week, highest first.
+// Items that expired since are left out. It fails with ErrNothingToDerive when from
+// published nothing that week.
+func DerivedItems(ctx context.Context, store *storage.RedisStore, source, from, key string) ([]model.WithScore, error) {
+	_, start, end, err := period.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	seen := map[string]bool{}
+	for _, day := range period.Range(period.Daily, start, end.Add(-time.Nanosecond)) {
+		meta, ok, err := store.GetPublishMeta(ctx, from, day)
+		if err != nil {
+			return nil, fmt.Errorf("read publish metadata of %s %s: %w", from, day, err)
+		}
+		if !ok {
+			continue
+		}
+		for _, id := range meta.ItemIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: channel %s in %s", ErrNothingToDerive, from, key)
+	}
+	out := make([]model.WithScore, 0, len(ids))
+	for _, id := range ids {
+		it, err := store.GetItem(ctx, source, id)
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("derive: published item expired; leaving it out", "channel", from, "item_id", id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		score, _, err := store.ItemScore(ctx, source, key, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, model.WithScore{Item: it, Score: score})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
+
+// deriveSettled reports whether the weekly period key can be derived from the daily
+// channel from: its last day is published or recorded as skipped, or DeriveGrace has
+// passed since the week ended (a day nothing was collected for is never recorded).
+func deriveSettled(ctx context.Context, store *storage.RedisStore, from, key string, now time.Time) (bool, error) {
+	_, _, end, err := period.Parse(key)
+	if err != nil {
+		return false, err
+	}
+	if !now.Before(end.Add(DeriveGrace)) {
+		return true, nil
+	}
+	last := period.Key(period.Daily, end.Add(-time.Nanosecond))
+	if published, err := store.IsPublished(ctx, from, last); err != nil || published {
+		return published, err
+	}
+	meta, ok, err := store.GetPublishMeta(ctx, from, last)
+	return ok && meta.Skipped != "", err
+}
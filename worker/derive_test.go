@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+)
+
+// A derived weekly digest re-ranks what the daily channel published that week,
+// not the week's raw items, and is built only once the week has ended.
+func TestDerivedWeeklyDigest(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	week := period.Key(period.Weekly, time.Now().AddDate(0, 0, -7))
+	_, start, end, _ := period.Parse(week)
+	for id, score := range map[string]float64{"a": 1, "b": 3, "c": 2, "raw": 9} {
+		it := model.NewsItem{ID: id, Title: "item " + id, URL: "https://example.com/" + id, NodeName: "elsewhere", Replies: 5, CreatedAt: start}
+		if err := store.AddNews(ctx, "v2ex", week, it, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The daily channel published a and b on Monday, b and c on Wednesday.
+	for day, ids := range map[time.Time][]string{start: {"a", "b"}, start.AddDate(0, 0, 2): {"b", "c"}} {
+		if err := store.SetPublishMeta(ctx, "daily", period.Key(period.Daily, day), storage.PublishMeta{ItemIDs: ids}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := DerivedItems(ctx, store, "v2ex", "daily", week)
+	if err != nil || !slices.Equal(itemIDs(items), []string{"b", "c", "a"}) {
+		t.Fatalf("DerivedItems = %v, %v; want b, c, a by stored score", itemIDs(items), err)
+	}
+	if _, err := DerivedItems(ctx, store, "v2ex", "quiet", week); !errors.Is(err, ErrNothingToDerive) {
+		t.Errorf("DerivedItems of a channel without publishes = %v, want ErrNothingToDerive", err)
+	}
+
+	// Nodes do not filter derived items; the current week waits.
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "best", Frequency: "weekly", TopN: 2, MinItems: 1,
+		OutputDir: t.TempDir(), Nodes: []string{"crypto"}, DeriveFrom: "daily"}
+	last := period.Key(period.Daily, end.Add(-time.Hour))
+	if err := store.MarkPublished(ctx, "daily", last); err != nil {
+		t.Fatal(err)
+	}
+	res, err := w.RunOnce(ctx)
+	if err != nil || res.Skipped != BuildPeriodOpen {
+		t.Fatalf("RunOnce = %+v, %v; want the current week left open", res, err)
+	}
+	meta, ok, err := store.GetPublishMeta(ctx, "best", week)
+	if err != nil || !ok || !slices.Equal(meta.ItemIDs, []string{"b", "c"}) {
+		t.Fatalf("closed week metadata = %+v, %v, %v; want items b, c", meta, ok, err)
+	}
+
+	// A week the daily channel published nothing in is reported and recorded as
+	// skipped, so the next run leaves it alone.
+	w.Channel, w.DeriveFrom = "best-quiet", "quiet"
+	if err := store.MarkPublished(ctx, "quiet", last); err != nil {
+		t.Fatal(err)
+	}
+	w.RunOnce(ctx)
+	if st, _, _ := store.GetWorkerStatus(ctx, w.Name()); !strings.Contains(st.LastError, ErrNothingToDerive.Error()) {
+		t.Fatalf("status last error = %q, want %q", st.LastError, ErrNothingToDerive)
+	}
+	if meta, _, _ := store.GetPublishMeta(ctx, "best-quiet", week); meta.Skipped == "" {
+		t.Error("week without daily publishes not recorded as skipped")
+	}
+	w.RunOnce(ctx)
+	if st, _, _ := store.GetWorkerStatus(ctx, w.Name()); st.LastError != "" {
+		t.Errorf("second run last error = %q, want the skip remembered", st.LastError)
+	}
+}
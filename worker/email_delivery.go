@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"quaily-journalist/internal/email"
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultEmailSubject is used when a channel's email block sets no subject.
+const DefaultEmailSubject = "{.Title}"
+
+// EmailTarget emails a channel's digests: the HTML output as the body and the
+// Markdown output (or the HTML without tags) as the plain-text alternative.
+type EmailTarget struct {
+	Sender *email.Sender
+	// Subject supports {.Title} and the newsletter variables (e.g., {.CurrentDate}).
+	Subject string
+}
+
+// Send builds the message from the files recorded in t and sends it.
+func (e EmailTarget) Send(ctx context.Context, t storage.DeliveryTask) error {
+	msg, err := e.message(t)
+	if err != nil {
+		return err
+	}
+	return e.Sender.Send(ctx, msg)
+}
+
+func (e EmailTarget) message(t storage.DeliveryTask) (email.Message, error) {
+	htmlPath := t.Paths[newsletter.FormatHTML]
+	if htmlPath == "" {
+		return email.Message{}, fmt.Errorf("email: digest %s has no html output", t.Slug)
+	}
+	body, err := os.ReadFile(htmlPath)
+	if err != nil {
+		return email.Message{}, fmt.Errorf("email: read html: %w", err)
+	}
+	text := ""
+	if p := t.Paths[newsletter.FormatMarkdown]; p != "" {
+		if doc, err := markdown.ParseFile(p); err == nil {
+			text = strings.TrimSpace(doc.Body)
+		}
+	}
+	if text == "" {
+		text = htmlToText(string(body))
+	}
+	subject := e.Subject
+	if strings.TrimSpace(subject) == "" {
+		subject = DefaultEmailSubject
+	}
+	subject = strings.ReplaceAll(newsletter.ExpandVars(subject, t.CreatedAt), "{.Title}", t.Title)
+	return email.Message{Subject: subject, HTML: string(body), Text: text}, nil
+}
+
+var (
+	htmlBlockEndRe = regexp.MustCompile(`(?i)</(p|h[1-6]|li|blockquote|section|div)>|<br\s*/?>`)
+	htmlTagRe      = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRe   = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText is a crude plain-text rendering of the digest HTML.
+func htmlToText(s string) string {
+	s = htmlBlockEndRe.ReplaceAllString(s, "\n\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+)
+
+func TestEmailTargetMessage(t *testing.T) {
+	dir := t.TempDir()
+	d := newsletter.Data{Title: "Daily <Digest>", Slug: "daily-20251024", Summary: "Top & more.", Items: []newsletter.Item{{Title: "A", URL: "https://example.com/a"}}}
+	outs, err := newsletter.RenderAll(d, []string{newsletter.FormatMarkdown, newsletter.FormatHTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]string{}
+	for _, o := range outs {
+		p := filepath.Join(dir, d.Slug+o.Ext)
+		if err := os.WriteFile(p, o.Content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths[o.Format] = p
+	}
+	task := storage.DeliveryTask{Channel: "ch", Slug: d.Slug, Target: storage.TargetEmail, Title: d.Title, Paths: paths, CreatedAt: time.Date(2025, 10, 24, 8, 0, 0, 0, time.UTC)}
+
+	msg, err := EmailTarget{Subject: "{.Title} ({.CurrentDate})"}.message(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Daily <Digest> (2025-10-24)" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	if msg.HTML != string(outs[1].Content) {
+		t.Errorf("html body differs from the html output")
+	}
+	if msg.Text == "" || msg.Text[0] == '-' {
+		t.Errorf("text alternative should be the markdown body without frontmatter: %q", msg.Text)
+	}
+
+	// Without markdown, the HTML is flattened to text.
+	delete(task.Paths, newsletter.FormatMarkdown)
+	msg, err = EmailTarget{}.message(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != d.Title || msg.Text == "" || msg.Text[0] == '<' {
+		t.Errorf("fallback message = %q / %q", msg.Subject, msg.Text)
+	}
+
+	delete(task.Paths, newsletter.FormatHTML)
+	if _, err := (EmailTarget{}).message(task); err == nil {
+		t.Error("message without html output: want error")
+	}
+}
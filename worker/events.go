@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// publishItemEvent announces a freshly stored item on the store's pub/sub channel.
+// Failures are logged at debug level only; watching is best-effort.
+func publishItemEvent(ctx context.Context, store *storage.RedisStore, source, period string, it model.NewsItem, score float64) {
+	ev := storage.ItemEvent{
+		Source:   source,
+		Period:   period,
+		ID:       it.ID,
+		NodeName: it.NodeName,
+		Title:    it.Title,
+		Score:    score,
+	}
+	if err := store.PublishItemEvent(ctx, ev); err != nil {
+		slog.Debug("publish item event failed", "source", source, "id", it.ID, "error", err)
+	}
+}
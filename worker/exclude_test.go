@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/selection"
+)
+
+func TestExcludedItemsNeverSelected(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t)
+	key := period.Key(period.Daily, time.Now())
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1}
+	before, _, err := w.candidates(ctx, key)
+	if err != nil || len(before) < 2 {
+		t.Fatalf("candidates = %d, %v", len(before), err)
+	}
+	byID, byURL := before[0].Item, before[1].Item
+	if _, err := store.AddExclusion(ctx, "v2ex", selection.ExclusionEntry(byID.ID)); err != nil {
+		t.Fatal(err)
+	}
+	// The same story linked differently is still excluded.
+	if _, err := store.AddExclusion(ctx, "v2ex", selection.ExclusionEntry(byURL.URL+"/?utm_medium=feed#reply1")); err != nil {
+		t.Fatal(err)
+	}
+	after, _, err := w.candidates(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ws := range after {
+		if ws.Item.ID == byID.ID || ws.Item.ID == byURL.ID {
+			t.Errorf("excluded item %s is a candidate", ws.Item.ID)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/githubtrending"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultCreatedWithin is how far back a repository's creation may go for it to
+// count as trending.
+const DefaultCreatedWithin = 7 * 24 * time.Hour
+
+// GitHubTrendingCollector searches, per language, the most-starred repositories
+// created within CreatedWithin and stores them into period ZSETs under the
+// language. Once the search rate limit is used up, the rest of the run is skipped
+// and no search is made before the limit resets.
+type GitHubTrendingCollector struct {
+	Client    GitHubTrendingSource
+	Store     *storage.RedisStore
+	Languages []string
+	Interval  time.Duration
+	// CreatedWithin bounds the age of the repositories searched; 0 uses
+	// DefaultCreatedWithin.
+	CreatedWithin time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores repositories; zero fields use ranking.ForSource("github"), the
+	// Hacker News formula on stars.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each repository's search JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu          sync.Mutex // guards Languages after Start
+	buffer      storeBuffer
+	pausedUntil time.Time // no searches before this, after the rate limit ran out
+}
+
+// SetNodes replaces the polled languages; the change applies at the next run.
+func (w *GitHubTrendingCollector) SetNodes(units []string) (added []string) {
+	return setUnits(&w.mu, &w.Languages, units, stringKey)
+}
+
+func (w *GitHubTrendingCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = time.Hour
+	}
+	if !waitForResume(ctx, w.Store, githubCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// githubCollectorName identifies the collector's persisted status record.
+const githubCollectorName = "github-collector"
+
+func (w *GitHubTrendingCollector) Name() string { return githubCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Languages that fail are logged, counted, and joined into the
+// error; the others are still stored.
+func (w *GitHubTrendingCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, githubCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, githubCollectorName, githubtrending.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, githubCollectorName, started, err)
+	countCollected(ctx, w.Store, githubtrending.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *GitHubTrendingCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	langs := currentUnits(&w.mu, &w.Languages)
+	within := w.CreatedWithin
+	if within <= 0 {
+		within = DefaultCreatedWithin
+	}
+	loop := collectLoop{source: githubtrending.Source, unit: "language", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		before: func(i int) error {
+			if !nowFunc(w.Now).Before(w.pausedUntil) {
+				return nil
+			}
+			skipped := len(langs) - i
+			slog.Warn("github collector: rate limited; skipping languages", "until", w.pausedUntil, "skipped", skipped)
+			return fmt.Errorf("rate limited until %s; %d languages skipped", w.pausedUntil.Format(time.RFC3339), skipped)
+		}}
+	return loop.run(ctx, langs, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		items, err := w.Client.Trending(ctx, langs[i], now.Add(-within))
+		var limited *githubtrending.RateLimitError
+		if errors.As(err, &limited) {
+			// The search limit is per minute; wait one when GitHub gives no reset time.
+			w.pausedUntil = limited.Reset
+			if w.pausedUntil.IsZero() {
+				w.pausedUntil = nowFunc(w.Now).Add(time.Minute)
+			}
+		}
+		return items, err
+	})
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/githubtrending"
+	"quaily-journalist/internal/period"
+)
+
+// Repositories are stored under the searched language; once the search limit runs
+// out, the rest of the run is skipped and searching resumes at the reset time.
+func TestGitHubTrendingCollectorStopsAtRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	created := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339)
+	var mu sync.Mutex
+	var queries []string
+	limited := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+		if limited && len(queries) == 2 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		id := len(queries)
+		fmt.Fprintf(w, `{"items": [{"id": %d, "full_name": "o/r%d", "html_url": "https://github.com/o/r%d", "language": "Go", "stargazers_count": 50, "created_at": %q, "owner": {"login": "o"}}]}`, id, id, id, created)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &GitHubTrendingCollector{Client: githubtrending.NewClient(srv.URL, ""), Store: store, Languages: []string{"go", "rust", "zig"}, Now: func() time.Time { return now }}
+	res, err := c.RunOnce(ctx)
+	if err == nil || res.Stored != 1 || res.Failed != 2 {
+		t.Fatalf("RunOnce = %+v, %v; want go stored, rust limited, zig skipped", res, err)
+	}
+	since := now.Add(-DefaultCreatedWithin).UTC().Format("2006-01-02")
+	if want := []string{"created:>" + since + " language:go", "created:>" + since + " language:rust"}; !slices.Equal(queries, want) {
+		t.Fatalf("queries = %q, want %q", queries, want)
+	}
+
+	now = reset.Add(time.Second)
+	mu.Lock()
+	limited = false
+	mu.Unlock()
+	if res, err := c.RunOnce(ctx); err != nil || res.Stored != 3 {
+		t.Fatalf("run after the reset = %+v, %v", res, err)
+	}
+	got, err := store.TopNews(ctx, "github", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(got) != 4 {
+		t.Fatalf("TopNews = %v, %v", itemIDs(got), err)
+	}
+	b := &NewsletterBuilder{Store: store, Source: "github", Nodes: []string{"Zig"}}
+	if kept := itemIDs(b.rank(ctx, got)); len(kept) != 1 || kept[0] != "5" {
+		t.Errorf("channel on Zig kept %v", kept)
+	}
+}
@@ -0,0 +1,316 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"quaily-journalist/internal/fsutil"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+)
+
+// CounterAITokens counts the tokens of every AI completion; see CountAITokens.
+const CounterAITokens = "ai_tokens"
+
+// CounterPublishLockContended counts builder runs that found a period's publish lock
+// held by another instance of serve; see NewsletterBuilder.lockPublish.
+const CounterPublishLockContended = "publish_lock_contended"
+
+// CollectedCounter names the counter of the items a source's collector stored.
+func CollectedCounter(source string) string { return "collected:" + source }
+
+// CountAITokens returns an ai.Config.OnUsage hook adding each completion's tokens
+// to the CounterAITokens counter.
+func CountAITokens(store *storage.RedisStore) func(tokens int) {
+	return func(tokens int) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := store.IncrCounter(ctx, CounterAITokens, time.Now(), int64(tokens)); err != nil {
+			slog.Warn("health: count ai tokens failed", "err", err)
+		}
+	}
+}
+
+// countCollected adds a collector pass's stored items to the source's counter.
+func countCollected(ctx context.Context, store *storage.RedisStore, source string, at time.Time, stored int) {
+	if stored == 0 {
+		return
+	}
+	if err := store.IncrCounter(ctx, CollectedCounter(source), at, int64(stored)); err != nil {
+		slog.Warn("health: count collected items failed", "source", source, "err", err)
+	}
+}
+
+// Digest statuses of a HealthReport.
+const (
+	DigestPublished = "published"
+	DigestSkipped   = "skipped"
+	DigestPending   = "pending" // the period is still open
+	DigestMissing   = "missing" // the period is over without a digest or a recorded skip
+)
+
+// HealthChannel is a channel whose digests the health report covers.
+type HealthChannel struct {
+	Name      string
+	Frequency string
+	Quaily    bool // the channel publishes to Quaily, so a digest not on Quaily is reported
+}
+
+// SourceCount is the number of items a source's collector stored.
+type SourceCount struct {
+	Source string `json:"source"`
+	Items  int64  `json:"items"`
+}
+
+// DigestHealth is the state of one channel period's digest.
+type DigestHealth struct {
+	Channel string `json:"channel"`
+	Period  string `json:"period"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"` // the slug, or why the digest was skipped
+}
+
+// HealthReport summarizes the pipeline over [From, To).
+type HealthReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Collected []SourceCount  `json:"collected"`
+	AITokens  int64          `json:"ai_tokens"`
+	Digests   []DigestHealth `json:"digests"`
+	// PublishLockContended is how many builder runs waited for another instance to
+	// finish publishing a period.
+	PublishLockContended int64 `json:"publish_lock_contended,omitempty"`
+	// FailedDeliveries are the dead-lettered or retrying delivery tasks updated in the window.
+	FailedDeliveries []storage.DeliveryTask `json:"failed_deliveries"`
+	// FailingWorkers are the workers whose latest run failed.
+	FailingWorkers []storage.WorkerStatus `json:"failing_workers"`
+}
+
+// HealthReporter sends a daily report of the last 24 hours of the pipeline:
+// items collected, digests published or skipped, AI tokens spent, failed
+// deliveries, and failing workers.
+type HealthReporter struct {
+	Store    *storage.RedisStore
+	Channels []HealthChannel
+	Sources  []string
+	// Notifier, when set, receives the report as a KindHealthReport event.
+	Notifier notify.Notifier
+	// OutputDir, when set, receives the report as <OutputDir>/_health/YYYYMMDD.md.
+	OutputDir string
+	// Hour and Minute are the time of day to report at, in Location (UTC when nil).
+	Hour, Minute int
+	Location     *time.Location
+	Now          func() time.Time
+}
+
+// healthReporterName identifies the reporter's persisted status record.
+const healthReporterName = "health-reporter"
+
+func (w *HealthReporter) Name() string { return healthReporterName }
+
+// Start sends a report every day at Hour:Minute until ctx is canceled.
+func (w *HealthReporter) Start(ctx context.Context) error {
+	for {
+		now := nowFunc(w.Now)
+		t := time.NewTimer(w.next(now).Sub(now))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+		started := nowFunc(w.Now)
+		err := w.RunOnce(ctx)
+		if err != nil {
+			slog.Warn("health: report failed", "err", err)
+		}
+		recordOutcome(ctx, w.Store, healthReporterName, started, err)
+	}
+}
+
+// next returns the first Hour:Minute in Location after now.
+func (w *HealthReporter) next(now time.Time) time.Time {
+	local := now.In(w.location())
+	at := time.Date(local.Year(), local.Month(), local.Day(), w.Hour, w.Minute, 0, 0, local.Location())
+	if !at.After(now) {
+		at = time.Date(local.Year(), local.Month(), local.Day()+1, w.Hour, w.Minute, 0, 0, local.Location())
+	}
+	return at
+}
+
+func (w *HealthReporter) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// RunOnce reports the 24 hours up to now: it writes the report file and sends the
+// notification, joining their errors.
+func (w *HealthReporter) RunOnce(ctx context.Context) error {
+	to := nowFunc(w.Now)
+	rep, err := w.Report(ctx, to)
+	if err != nil {
+		return err
+	}
+	text := RenderHealthReport(rep, w.location())
+	var errs []error
+	if w.OutputDir != "" {
+		p := filepath.Join(w.OutputDir, "_health", to.In(w.location()).Format("20060102")+".md")
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			errs = append(errs, err)
+		} else if err := fsutil.WriteFileAtomic(p, []byte(text)); err != nil {
+			errs = append(errs, err)
+		} else {
+			slog.Info("health: report written", "path", p)
+		}
+	}
+	if w.Notifier != nil {
+		ev := notify.Event{Kind: notify.KindHealthReport, Message: text, Time: to.UTC()}
+		if err := w.Notifier.Notify(ctx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("notify: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Report gathers the health of the 24 hours up to to.
+func (w *HealthReporter) Report(ctx context.Context, to time.Time) (HealthReport, error) {
+	from := to.Add(-24 * time.Hour)
+	rep := HealthReport{From: from, To: to}
+	for _, s := range w.Sources {
+		// Counter buckets are hourly: sum the 24 ending with the current, partial one.
+		n, err := w.Store.SumCounter(ctx, CollectedCounter(s), from.Add(time.Hour), to.Add(time.Hour))
+		if err != nil {
+			return HealthReport{}, err
+		}
+		rep.Collected = append(rep.Collected, SourceCount{Source: s, Items: n})
+	}
+	tokens, err := w.Store.SumCounter(ctx, CounterAITokens, from.Add(time.Hour), to.Add(time.Hour))
+	if err != nil {
+		return HealthReport{}, err
+	}
+	rep.AITokens = tokens
+	if rep.PublishLockContended, err = w.Store.SumCounter(ctx, CounterPublishLockContended, from.Add(time.Hour), to.Add(time.Hour)); err != nil {
+		return HealthReport{}, err
+	}
+
+	for _, ch := range w.Channels {
+		current := period.Key(ch.Frequency, to)
+		for _, key := range period.Range(ch.Frequency, from, to) {
+			d := DigestHealth{Channel: ch.Name, Period: key}
+			meta, ok, err := w.Store.GetPublishMeta(ctx, ch.Name, key)
+			if err != nil {
+				return HealthReport{}, err
+			}
+			switch {
+			case ok && meta.Skipped != "":
+				d.Status, d.Detail = DigestSkipped, meta.Skipped
+			case ok:
+				d.Status, d.Detail = DigestPublished, meta.Slug
+				if ch.Quaily && meta.QuailyPublishedAt == nil {
+					d.Detail += ", not on Quaily"
+				}
+			case key == current:
+				d.Status = DigestPending
+			default:
+				d.Status = DigestMissing
+			}
+			rep.Digests = append(rep.Digests, d)
+		}
+	}
+
+	tasks, err := w.Store.Deliveries(ctx, "")
+	if err != nil {
+		return HealthReport{}, err
+	}
+	for _, t := range tasks {
+		failed := t.State == storage.DeliveryDead || (t.State == storage.DeliveryPending && t.LastError != "")
+		if failed && !t.UpdatedAt.Before(from) && t.UpdatedAt.Before(to) {
+			rep.FailedDeliveries = append(rep.FailedDeliveries, t)
+		}
+	}
+	sort.SliceStable(rep.FailedDeliveries, func(i, j int) bool {
+		return rep.FailedDeliveries[i].UpdatedAt.Before(rep.FailedDeliveries[j].UpdatedAt)
+	})
+
+	statuses, err := w.Store.WorkerStatuses(ctx)
+	if err != nil {
+		return HealthReport{}, err
+	}
+	for _, st := range statuses {
+		if st.LastError != "" {
+			rep.FailingWorkers = append(rep.FailingWorkers, st)
+		}
+	}
+	return rep, nil
+}
+
+// RenderHealthReport renders the report as markdown, with times in loc.
+func RenderHealthReport(rep HealthReport, loc *time.Location) string {
+	const stamp = "2006-01-02 15:04"
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Journalist health report\n\n%s to %s (%s)\n", rep.From.In(loc).Format(stamp), rep.To.In(loc).Format(stamp), loc)
+
+	b.WriteString("\n## Collection\n\n")
+	if len(rep.Collected) == 0 {
+		b.WriteString("No sources configured.\n")
+	}
+	for _, c := range rep.Collected {
+		fmt.Fprintf(&b, "- %s: %d items\n", c.Source, c.Items)
+	}
+
+	b.WriteString("\n## Digests\n\n")
+	if len(rep.Digests) == 0 {
+		b.WriteString("No channels configured.\n")
+	}
+	for _, d := range rep.Digests {
+		fmt.Fprintf(&b, "- %s %s: %s", d.Channel, d.Period, d.Status)
+		if d.Detail != "" {
+			fmt.Fprintf(&b, ": %s", d.Detail)
+		}
+		b.WriteString("\n")
+	}
+	if rep.PublishLockContended > 0 {
+		fmt.Fprintf(&b, "- %d builder runs waited for another instance publishing the same period\n", rep.PublishLockContended)
+	}
+
+	fmt.Fprintf(&b, "\n## AI\n\n- %d tokens\n", rep.AITokens)
+
+	b.WriteString("\n## Delivery failures\n\n")
+	if len(rep.FailedDeliveries) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, t := range rep.FailedDeliveries {
+		target := t.Target
+		if target == "" {
+			target = storage.TargetQuaily
+		}
+		state := "retrying"
+		if t.State == storage.DeliveryDead {
+			state = "dead"
+		}
+		fmt.Fprintf(&b, "- %s %s to %s: %s after %d attempts: %s\n", t.Channel, t.Slug, target, state, t.Attempts, t.LastError)
+	}
+
+	b.WriteString("\n## Failing workers\n\n")
+	if len(rep.FailingWorkers) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, st := range rep.FailingWorkers {
+		fmt.Fprintf(&b, "- %s: %s", st.Worker, st.LastError)
+		if st.LastErrorAt != nil {
+			fmt.Fprintf(&b, " (at %s)", st.LastErrorAt.In(loc).Format(stamp))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/storage"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+func TestHealthReportGolden(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	cst := time.FixedZone("CST", 8*3600)
+	to := time.Date(2025, 1, 3, 8, 0, 0, 0, cst)
+
+	for _, c := range []struct {
+		name string
+		at   time.Time
+		n    int64
+	}{
+		{CollectedCounter("v2ex"), to.Add(-time.Hour), 12},
+		{CollectedCounter("v2ex"), to.Add(-30 * time.Hour), 5}, // before the window
+		{CounterAITokens, to.Add(-2 * time.Hour), 1500},
+		{CounterAITokens, to, 250},
+		{CounterPublishLockContended, to.Add(-5 * time.Hour), 2},
+	} {
+		if err := store.IncrCounter(ctx, c.name, c.at, c.n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetPublishMeta(ctx, "v2ex-daily", "2025-01-02", storage.PublishMeta{Slug: "v2ex-daily-20250102"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetPublishMeta(ctx, "hn-weekly", "2025-W01", storage.PublishMeta{Skipped: "insufficient items (3/5)"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []storage.DeliveryTask{
+		{Channel: "v2ex-daily", Slug: "v2ex-daily-20250102", Target: storage.TargetEmail, State: storage.DeliveryDead, Attempts: 5, LastError: "smtp: 550 mailbox unavailable", UpdatedAt: to.Add(-3 * time.Hour)},
+		{Channel: "v2ex-daily", Slug: "v2ex-daily-20250102", State: storage.DeliveryPending, Attempts: 2, LastError: "quaily: status=502", UpdatedAt: to.Add(-time.Hour)},
+		{Channel: "v2ex-daily", Slug: "v2ex-daily-20250102", Target: storage.TargetTelegram, State: storage.DeliveryDone, Attempts: 1, UpdatedAt: to.Add(-time.Hour)},
+		{Channel: "v2ex-daily", Slug: "v2ex-daily-20241230", Target: storage.TargetSlack, State: storage.DeliveryDead, Attempts: 5, LastError: "old", UpdatedAt: to.Add(-72 * time.Hour)},
+	} {
+		if err := store.SaveDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetWorkerLastRun(ctx, "hn-collector", to.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetWorkerError(ctx, "v2ex-collector", "node crypto: status=503", to.Add(-90*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	n := &recordingNotifier{}
+	w := &HealthReporter{
+		Store: store,
+		Channels: []HealthChannel{
+			{Name: "v2ex-daily", Frequency: "daily", Quaily: true},
+			{Name: "hn-weekly", Frequency: "weekly"},
+			{Name: "quiet", Frequency: "daily"},
+		},
+		Sources:   []string{"v2ex", "hackernews"},
+		Notifier:  n,
+		OutputDir: dir,
+		Location:  cst,
+		Now:       func() time.Time { return to },
+	}
+	if err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "_health", "20250103.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "health_report.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("report =\n%s\nwant\n%s", got, want)
+	}
+	if len(n.events) != 1 || n.events[0].Kind != notify.KindHealthReport || n.events[0].Message != string(got) {
+		t.Errorf("events = %+v", n.events)
+	}
+}
+
+func TestHealthReporterNext(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	w := &HealthReporter{Hour: 8, Minute: 30, Location: cst}
+	for now, want := range map[time.Time]time.Time{
+		time.Date(2025, 1, 3, 1, 0, 0, 0, time.UTC):  time.Date(2025, 1, 4, 8, 30, 0, 0, cst), // 09:00 CST
+		time.Date(2025, 1, 2, 23, 0, 0, 0, time.UTC): time.Date(2025, 1, 3, 8, 30, 0, 0, cst), // 07:00 CST
+	} {
+		if got := w.next(now); !got.Equal(want) {
+			t.Errorf("next(%v) = %v, want %v", now, got, want)
+		}
+	}
+}
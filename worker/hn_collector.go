@@ -2,23 +2,62 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"math"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"quaily-journalist/internal/hackernews"
 	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/ranking"
 	"quaily-journalist/internal/storage"
 )
 
 // HNCollector polls Hacker News story lists, scores items, and stores them into period ZSETs.
 type HNCollector struct {
-	Client       *hackernews.Client
+	Client       HNSource
 	Store        *storage.RedisStore
-	Lists        []string // e.g., top,new,best,ask,show,job
+	Lists        []string // e.g., top,new,best,ask,show,job; use SetNodes once running
 	Interval     time.Duration
 	LimitPerList int // how many IDs to fetch per list
+	// ItemStaleness is how long a resolved item is trusted before it is fetched again.
+	// Items seen within this window are skipped on later runs. <= 0 disables change detection.
+	ItemStaleness time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores stories; zero fields use ranking.ForSource("hackernews").
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each story's item JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Lists after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled lists (HN channel nodes are list names); the change
+// applies at the next run. It returns the lists that were not polled before.
+func (w *HNCollector) SetNodes(lists []string) (added []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	added = newEntries(w.Lists, lists)
+	w.Lists = append([]string(nil), lists...)
+	return added
+}
+
+func (w *HNCollector) currentLists() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.Lists...)
 }
 
 func (w *HNCollector) Start(ctx context.Context) error {
@@ -29,89 +68,181 @@ func (w *HNCollector) Start(ctx context.Context) error {
 		w.LimitPerList = 10
 	}
 
+	if !waitForResume(ctx, w.Store, hnCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+
 	// initial run
-	w.runOnce(ctx)
+	w.RunOnce(ctx)
 
 	t := time.NewTicker(w.Interval)
 	defer t.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-t.C:
-			w.runOnce(ctx)
-		}
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// hnCollectorName identifies the collector's persisted status record.
+const hnCollectorName = "hn-collector"
+
+func (w *HNCollector) Name() string { return hnCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Lists that fail are logged, counted, and joined into the error;
+// items a failing list did return are still stored.
+func (w *HNCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, hnCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
 	}
+	if err := skipBlocked(ctx, w.Store, hnCollectorName, "hackernews", started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx)
+	recordOutcome(ctx, w.Store, hnCollectorName, started, err)
+	countCollected(ctx, w.Store, "hackernews", started, res.Stored)
+	return res, err
 }
 
-func (w *HNCollector) runOnce(ctx context.Context) {
-	day := periodKey("daily", time.Now().UTC())
-	week := periodKey("weekly", time.Now().UTC())
+func (w *HNCollector) collect(ctx context.Context) (CollectResult, error) {
+	day := period.Key(period.Daily, time.Now().UTC())
+	week := period.Key(period.Weekly, time.Now().UTC())
 
-	lists := w.Lists
+	lists := w.currentLists()
 	if len(lists) == 0 {
 		lists = []string{"top"}
 	}
+	scorer := ranking.ForSource("hackernews").Merge(w.Ranking)
+	limit := w.LimitPerList
+	if limit <= 0 {
+		limit = 10
+	}
+	var res CollectResult
+	var errs []error
+	w.buffer.source, w.buffer.max, w.buffer.archiveRaw = "hackernews", w.BufferSize, w.ArchiveRaw
+	res.Stored, _ = w.buffer.flush(ctx, w.Store)
 	for _, list := range lists {
-		items, err := w.fetchList(ctx, list, w.LimitPerList)
+		items, err := w.fetchList(ctx, list, limit)
 		if err != nil {
-			slog.Error("hn-collector: fetch list error", "list", list, "error", err)
-			continue
+			slog.Error("hn-collector: fetch list error", "list", list, "error", err, "partial", len(items))
+			res.Failed++
+			errs = append(errs, fmt.Errorf("list %s: %w", list, err))
+			if len(items) == 0 {
+				continue
+			}
 		}
+		res.Fetched += len(items)
 		stored := 0
 		for _, it := range items {
-			score := hnPopularityScore(it)
+			score := scorer.Score(it, time.Now())
 			if score <= 0 {
 				continue
 			}
-			if err := w.Store.AddNews(ctx, "hackernews", day, it, score); err != nil {
+			ok, err := w.buffer.store(ctx, w.Store, bufferedItem{item: it, score: score, periods: []string{day, week}})
+			if err != nil {
 				slog.Error("hn-collector: store error", "id", it.ID, "error", err)
 				continue
 			}
-			if err := w.Store.AddNews(ctx, "hackernews", week, it, score); err != nil {
-				slog.Error("hn-collector: store error", "id", it.ID, "error", err)
-				continue
+			if ok {
+				stored++
 			}
-			stored++
 		}
 		slog.Info("hn-collector: completed for list", "list", list, "stored", stored, "periods", []string{day, week})
+		res.Stored += stored
+	}
+	res.Buffered = w.buffer.len()
+	if err := w.buffer.err(); err != nil {
+		slog.Error("hn-collector: items held until redis is back", "buffered", res.Buffered, "error", err)
+		errs = append(errs, err)
 	}
+	return res, errors.Join(errs...)
 }
 
 func (w *HNCollector) fetchList(ctx context.Context, list string, limit int) ([]model.NewsItem, error) {
+	endpoint := hnListEndpoint(list)
+	if w.ItemStaleness <= 0 {
+		res, err := w.Client.ListIDs(ctx, endpoint, "")
+		if err != nil {
+			return nil, err
+		}
+		return w.Client.Items(ctx, truncateIDs(res.IDs, limit))
+	}
+
+	// Conditional fetch: reuse the cached ID list when the API reports no change.
+	snap, ok, err := w.Store.GetListSnapshot(ctx, "hackernews", endpoint)
+	if err != nil {
+		slog.Warn("hn-collector: list snapshot read failed", "list", list, "error", err)
+	}
+	etag := ""
+	if ok {
+		etag = snap.ETag
+	}
+	res, err := w.Client.ListIDs(ctx, endpoint, etag)
+	if err != nil {
+		return nil, err
+	}
+	ids := res.IDs
+	if res.NotModified {
+		ids = snap.IDs
+	} else if err := w.Store.SetListSnapshot(ctx, "hackernews", endpoint, storage.ListSnapshot{ETag: res.ETag, IDs: ids}); err != nil {
+		slog.Warn("hn-collector: list snapshot write failed", "list", list, "error", err)
+	}
+	ids = truncateIDs(ids, limit)
+
+	// Only resolve items that are new or whose last fetch is older than the staleness window.
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = strconv.Itoa(id)
+	}
+	fresh, err := w.Store.FreshItemIDs(ctx, "hackernews", keys)
+	if err != nil {
+		slog.Warn("hn-collector: freshness check failed; fetching all", "list", list, "error", err)
+		fresh = nil
+	}
+	stale := make([]int, 0, len(ids))
+	for i, id := range ids {
+		if !fresh[keys[i]] {
+			stale = append(stale, id)
+		}
+	}
+	// Items may come back partially resolved alongside an error; keep what we got.
+	items, err := w.Client.Items(ctx, stale)
+	if err != nil && len(items) == 0 {
+		return nil, err
+	}
+	fetched := make([]string, 0, len(items))
+	for _, it := range items {
+		fetched = append(fetched, it.ID)
+	}
+	if err := w.Store.MarkFetched(ctx, "hackernews", fetched, w.ItemStaleness); err != nil {
+		slog.Warn("hn-collector: mark fetched failed", "list", list, "error", err)
+	}
+	slog.Debug("hn-collector: change detection", "list", list, "not_modified", res.NotModified, "ids", len(ids), "fresh", len(ids)-len(stale), "fetched", len(items))
+	return items, err
+}
+
+// hnListEndpoint maps a configured list name to its API endpoint; unknown lists default to top.
+func hnListEndpoint(list string) string {
 	switch strings.ToLower(strings.TrimSpace(list)) {
-	case "top", "topstories":
-		return w.Client.TopStories(ctx, limit)
 	case "new", "newstories":
-		return w.Client.NewStories(ctx, limit)
+		return "newstories"
 	case "best", "beststories":
-		return w.Client.BestStories(ctx, limit)
+		return "beststories"
 	case "ask", "askstories":
-		return w.Client.AskStories(ctx, limit)
+		return "askstories"
 	case "show", "showstories":
-		return w.Client.ShowStories(ctx, limit)
+		return "showstories"
 	case "job", "jobs", "jobstories":
-		return w.Client.JobStories(ctx, limit)
+		return "jobstories"
 	default:
-		// unknown list; default to top
-		return w.Client.TopStories(ctx, limit)
+		return "topstories"
 	}
 }
 
-// hnPopularityScore uses HN points (score) and age for time-decayed ranking.
-func hnPopularityScore(it model.NewsItem) float64 {
-	if it.Points <= 0 {
-		return 0
-	}
-	count := it.Points
-	diff := time.Since(it.CreatedAt).Hours()
-	if diff < 0 {
-		diff = 0
-	}
-	// Score = (count-1) / (diff+2)^1.8
-	score := float64(count-1) / math.Pow(diff+2, 1.8)
-	if math.IsNaN(score) || score < 0 {
-		score = 0
+func truncateIDs(ids []int, limit int) []int {
+	if limit > 0 && len(ids) > limit {
+		return ids[:limit]
 	}
-	return score
+	return ids
 }
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeHN serves a topstories list with an ETag and counts item endpoint hits.
+type fakeHN struct {
+	mu          sync.Mutex
+	ids         []int
+	itemHits    atomic.Int64
+	listHits    atomic.Int64
+	notModified atomic.Int64
+}
+
+func (f *fakeHN) etag() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fmt.Sprintf(`"%d-%d"`, len(f.ids), f.ids[0])
+}
+
+func (f *fakeHN) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/topstories.json":
+		f.listHits.Add(1)
+		tag := f.etag()
+		if r.Header.Get("If-None-Match") == tag {
+			f.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", tag)
+		f.mu.Lock()
+		_ = json.NewEncoder(w).Encode(f.ids)
+		f.mu.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/item/"):
+		f.itemHits.Add(1)
+		var id int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/item/"), "%d.json", &id)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":    id,
+			"type":  "story",
+			"title": fmt.Sprintf("Story %d", id),
+			"url":   fmt.Sprintf("https://example.com/%d", id),
+			"time":  time.Now().Add(-time.Hour).Unix(),
+			"score": 50,
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestHNCollectorSkipsUnchangedItems(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	fake := &fakeHN{}
+	for i := 1; i <= 30; i++ {
+		fake.ids = append(fake.ids, 1000+i)
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	w := &HNCollector{
+		Client:        hackernews.NewClient(srv.URL),
+		Store:         storage.NewRedisStore(rdb),
+		Lists:         []string{"top"},
+		LimitPerList:  30,
+		ItemStaleness: time.Hour,
+	}
+	ctx := context.Background()
+
+	w.RunOnce(ctx)
+	first := fake.itemHits.Load()
+	if first != 30 {
+		t.Fatalf("first run fetched %d items, want 30", first)
+	}
+
+	w.RunOnce(ctx)
+	second := fake.itemHits.Load() - first
+	if second*5 > first {
+		t.Fatalf("second run fetched %d items, want <= 20%% of %d", second, first)
+	}
+	if fake.notModified.Load() != 1 {
+		t.Errorf("expected the second list fetch to be answered with 304, got %d", fake.notModified.Load())
+	}
+
+	// A new story at the head of the list changes the ETag; only it is resolved.
+	fake.mu.Lock()
+	fake.ids = append([]int{2000}, fake.ids[:29]...)
+	fake.mu.Unlock()
+	before := fake.itemHits.Load()
+	w.RunOnce(ctx)
+	if got := fake.itemHits.Load() - before; got != 1 {
+		t.Errorf("third run fetched %d items, want 1 (the new story)", got)
+	}
+
+	// Once the staleness window passes every item is refreshed again.
+	mr.FastForward(time.Hour + time.Minute)
+	before = fake.itemHits.Load()
+	w.RunOnce(ctx)
+	if got := fake.itemHits.Load() - before; got != 30 {
+		t.Errorf("run after staleness window fetched %d items, want 30", got)
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
+)
+
+// JSONFeed is a feed the JSON Feed collector polls. Label is the node name its
+// items are stored under; empty uses the feed's title.
+type JSONFeed struct {
+	URL   string
+	Label string
+}
+
+// JSONFeedCollector polls JSON Feeds and stores their items into period ZSETs, like
+// the RSS collector does for RSS and Atom feeds.
+type JSONFeedCollector struct {
+	Client          JSONFeedSource
+	Store           *storage.RedisStore
+	Feeds           []JSONFeed
+	Interval        time.Duration
+	MaxContentRunes int // content budget after cleaning; 0 uses textclean.DefaultMaxRunes
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores items; zero fields use ranking.ForSource("jsonfeed"), which
+	// ranks by recency.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each item's JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Feeds after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled feeds; the URLs added are returned; the change applies at the next run.
+func (w *JSONFeedCollector) SetNodes(units []JSONFeed) (added []string) {
+	return setUnits(&w.mu, &w.Feeds, units, func(f JSONFeed) string { return f.URL })
+}
+
+func (w *JSONFeedCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, jsonFeedCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// jsonFeedCollectorName identifies the collector's persisted status record.
+const jsonFeedCollectorName = "jsonfeed-collector"
+
+func (w *JSONFeedCollector) Name() string { return jsonFeedCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. A feed that cannot be fetched or parsed is logged,
+// counted, and joined into the error; the other feeds are still stored.
+func (w *JSONFeedCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, jsonFeedCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, jsonFeedCollectorName, "jsonfeed", started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, jsonFeedCollectorName, started, err)
+	countCollected(ctx, w.Store, "jsonfeed", started, res.Stored)
+	return res, err
+}
+
+func (w *JSONFeedCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	feeds := currentUnits(&w.mu, &w.Feeds)
+	urls := make([]string, len(feeds))
+	for i, feed := range feeds {
+		urls[i] = feed.URL
+	}
+	loop := collectLoop{source: "jsonfeed", unit: "feed", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		clean: func(it model.NewsItem) model.NewsItem {
+			it.Content = textclean.Clean(it.Content, textclean.Options{MaxRunes: w.MaxContentRunes})
+			return it
+		}}
+	return loop.run(ctx, urls, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return w.Client.Fetch(ctx, feeds[i].URL, feeds[i].Label)
+	})
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Output layouts control where a channel's digests live under <output_dir>/<channel>.
+const (
+	LayoutFlat    = "flat"     // <channel>/daily-20251024.md
+	LayoutByMonth = "by_month" // <channel>/2025/10/daily-20251024.md
+	LayoutByYear  = "by_year"  // <channel>/2025/daily-20251024.md
+)
+
+// CheckOutputLayout reports an unknown layout; empty means flat.
+func CheckOutputLayout(layout string) error {
+	switch strings.ToLower(strings.TrimSpace(layout)) {
+	case "", LayoutFlat, LayoutByMonth, LayoutByYear:
+		return nil
+	}
+	return fmt.Errorf("unknown output_layout %q (want flat, by_month, or by_year)", layout)
+}
+
+// DigestDir returns the directory holding a channel's digest dated t. Years and
+// months come from the UTC date, matching the digest file name.
+func DigestDir(outputDir, channel, layout string, t time.Time) string {
+	dir := filepath.Join(outputDir, channel)
+	utc := t.UTC()
+	switch strings.ToLower(strings.TrimSpace(layout)) {
+	case LayoutByMonth:
+		return filepath.Join(dir, utc.Format("2006"), utc.Format("01"))
+	case LayoutByYear:
+		return filepath.Join(dir, utc.Format("2006"))
+	default:
+		return dir
+	}
+}
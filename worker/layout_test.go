@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDigestDir(t *testing.T) {
+	// Late evening in UTC-8 is already the next day (and month) in UTC.
+	at := time.Date(2025, 10, 31, 20, 0, 0, 0, time.FixedZone("PST", -8*3600))
+	cases := map[string]string{
+		"":         filepath.Join("out", "ch"),
+		"flat":     filepath.Join("out", "ch"),
+		"by_month": filepath.Join("out", "ch", "2025", "11"),
+		"by_year":  filepath.Join("out", "ch", "2025"),
+	}
+	for layout, want := range cases {
+		if got := DigestDir("out", "ch", layout, at); got != want {
+			t.Errorf("DigestDir(%q) = %q, want %q", layout, got, want)
+		}
+	}
+	if err := CheckOutputLayout("by_week"); err == nil {
+		t.Error("CheckOutputLayout(by_week) = nil, want error")
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// LobstersCollector polls Lobste.rs listings and stores their stories into period
+// ZSETs. Stories are stored under their tags (see lobsters.NodeName); channels pick
+// tags at build time, so every configured list is polled whatever they select.
+type LobstersCollector struct {
+	Client   LobstersSource
+	Store    *storage.RedisStore
+	Lists    []string // hottest and/or newest; empty polls hottest
+	Interval time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores stories; zero fields use ranking.ForSource("lobsters"), the
+	// Hacker News formula on points.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each story's listing JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Lists after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled lists; the change applies at the next run.
+func (w *LobstersCollector) SetNodes(units []string) (added []string) {
+	return setUnits(&w.mu, &w.Lists, units, stringKey)
+}
+
+func (w *LobstersCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 15 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, lobstersCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// lobstersCollectorName identifies the collector's persisted status record.
+const lobstersCollectorName = "lobsters-collector"
+
+func (w *LobstersCollector) Name() string { return lobstersCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Lists that fail are logged, counted, and joined into the error;
+// the others are still stored.
+func (w *LobstersCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, lobstersCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, lobstersCollectorName, lobsters.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, lobstersCollectorName, started, err)
+	countCollected(ctx, w.Store, lobsters.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *LobstersCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	lists := currentUnits(&w.mu, &w.Lists)
+	if len(lists) == 0 {
+		lists = []string{lobsters.Hottest}
+	}
+	seen := map[string]struct{}{} // the listings overlap
+	loop := collectLoop{source: lobsters.Source, unit: "list", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		keep: func(it model.NewsItem) bool {
+			if _, dup := seen[it.ID]; dup {
+				return false
+			}
+			seen[it.ID] = struct{}{}
+			return true
+		}}
+	return loop.run(ctx, lists, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return w.fetchList(ctx, lists[i])
+	})
+}
+
+func (w *LobstersCollector) fetchList(ctx context.Context, list string) ([]model.NewsItem, error) {
+	switch strings.ToLower(strings.TrimSpace(list)) {
+	case lobsters.Hottest:
+		return w.Client.Hottest(ctx)
+	case lobsters.Newest:
+		return w.Client.Newest(ctx)
+	}
+	return nil, fmt.Errorf("unknown lobsters list %q (want hottest or newest)", list)
+}
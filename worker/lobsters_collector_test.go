@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/lobsters"
+	"quaily-journalist/internal/period"
+)
+
+// Stories are stored once across overlapping listings and ranked by points; a
+// lobsters channel keeps those carrying any of its tags and links the tag page.
+func TestLobstersCollectorStoresTaggedStories(t *testing.T) {
+	created := time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339)
+	story := func(id string, score int, tags string) string {
+		return fmt.Sprintf(`{"short_id": %q, "title": "story %s", "url": "https://example.com/%s", "score": %d, "comment_count": 0, "created_at": %q, "submitter_user": "u", "tags": [%s]}`, id, id, id, score, created, tags)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hottest.json":
+			fmt.Fprintf(w, "[%s, %s]", story("go1", 30, `"go"`), story("sec1", 12, `"security", "linux"`))
+		case "/newest.json":
+			fmt.Fprintf(w, "[%s, %s, %s]", story("new1", 2, `"go", "release"`), story("go1", 30, `"go"`), story("flat", 1, `"rust"`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &LobstersCollector{Client: lobsters.NewClient(srv.URL), Store: store, Lists: []string{"hottest", "newest"}}
+	res, err := c.RunOnce(ctx)
+	if err != nil || res.Fetched != 5 || res.Stored != 3 {
+		t.Fatalf("RunOnce = %+v, %v; want 5 fetched, 3 stored (go1 once, flat unscored)", res, err)
+	}
+
+	got, err := store.TopNews(ctx, "lobsters", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || !slices.Equal(itemIDs(got), []string{"go1", "sec1", "new1"}) {
+		t.Fatalf("TopNews = %v, %v; want go1, sec1, new1 by points", itemIDs(got), err)
+	}
+
+	b := &NewsletterBuilder{Store: store, Source: "lobsters", Nodes: []string{"Go", "linux"}}
+	if kept := itemIDs(b.rank(ctx, got)); !slices.Equal(kept, []string{"go1", "sec1", "new1"}) {
+		t.Errorf("channel on go, linux kept %v", kept)
+	}
+	b.Nodes = []string{"release"}
+	if kept := itemIDs(b.rank(ctx, got)); !slices.Equal(kept, []string{"new1"}) {
+		t.Errorf("channel on release kept %v", kept)
+	}
+	if u := NodeURL("lobsters", lobsters.DefaultBaseURL, "security,linux"); u != "https://lobste.rs/t/security,linux" {
+		t.Errorf("node URL = %q", u)
+	}
+
+	c.Lists = []string{"active"}
+	if _, err := c.RunOnce(ctx); err == nil {
+		t.Error("unknown list: RunOnce = nil error")
+	}
+}
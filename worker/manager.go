@@ -2,39 +2,174 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
-// Manager starts and supervises a set of workers.
+// Manager starts and supervises a set of workers. Each worker runs under its own
+// context derived from the one passed to Start, so a single worker can be stopped
+// (e.g., on config reload) without touching the others.
 type Manager struct {
-	workers []Worker
+	mu      sync.Mutex
+	ctx     context.Context // nil until Start
+	workers map[string]*managedWorker
+	pending []Worker // added before Start, in order
+	wg      sync.WaitGroup
+	err     error // first error returned by a worker
 }
 
-func NewManager(ws ...Worker) *Manager {
-	return &Manager{workers: ws}
+type managedWorker struct {
+	w      Worker
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-func (m *Manager) Start(ctx context.Context) error {
-	var wg sync.WaitGroup
-	errs := make(chan error, len(m.workers))
-	for _, w := range m.workers {
-		wg.Add(1)
-		go func(w Worker) {
-			defer wg.Done()
-			if err := w.Start(ctx); err != nil {
-				errs <- err
+// NewManager registers ws with Add; it fails if two of them share a name.
+func NewManager(ws ...Worker) (*Manager, error) {
+	m := &Manager{workers: map[string]*managedWorker{}}
+	for _, w := range ws {
+		if err := m.Add(w); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add registers a worker. Once the manager is started, the worker starts immediately.
+// Names must be unique among live workers. Add fails once the context passed to
+// Start is cancelled.
+func (m *Manager) Add(w Worker) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx != nil && m.ctx.Err() != nil {
+		return fmt.Errorf("manager stopped: %w", m.ctx.Err())
+	}
+	name := w.Name()
+	if _, ok := m.workers[name]; ok {
+		return fmt.Errorf("worker %q already registered", name)
+	}
+	for _, p := range m.pending {
+		if p.Name() == name {
+			return fmt.Errorf("worker %q already registered", name)
+		}
+	}
+	if m.ctx == nil {
+		m.pending = append(m.pending, w)
+		return nil
+	}
+	m.launch(w)
+	return nil
+}
+
+// launch starts w in its own goroutine; m.mu must be held and m.ctx set.
+func (m *Manager) launch(w Worker) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	mw := &managedWorker{w: w, cancel: cancel, done: make(chan struct{})}
+	m.workers[w.Name()] = mw
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(mw.done)
+		defer cancel()
+		if err := w.Start(ctx); err != nil {
+			m.mu.Lock()
+			if m.err == nil {
+				m.err = fmt.Errorf("worker %s: %w", w.Name(), err)
 			}
-		}(w)
+			m.mu.Unlock()
+		}
+	}()
+}
+
+// Remove cancels a worker and forgets it without waiting for it to exit.
+func (m *Manager) Remove(name string) error {
+	_, err := m.detach(name)
+	return err
+}
+
+// Stop cancels a worker and waits up to timeout for its goroutine to exit.
+// The worker is removed either way, so its name can be reused by Add.
+func (m *Manager) Stop(name string, timeout time.Duration) error {
+	mw, err := m.detach(name)
+	if err != nil || mw == nil {
+		return err
 	}
-	// Wait for context cancellation then wait for workers to exit.
-	<-ctx.Done()
-	wg.Wait()
-	close(errs)
-	// If any worker returned an error before context cancelled, report one.
-	for err := range errs {
-		if err != nil {
-			return err
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-mw.done:
+		return nil
+	case <-t.C:
+		return fmt.Errorf("worker %q did not stop within %s", name, timeout)
+	}
+}
+
+// detach removes a worker and cancels it; it returns nil for a worker that was never started.
+func (m *Manager) detach(name string) (*managedWorker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mw, ok := m.workers[name]; ok {
+		delete(m.workers, name)
+		mw.cancel()
+		return mw, nil
+	}
+	for i, p := range m.pending {
+		if p.Name() == name {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			return nil, nil
 		}
 	}
+	return nil, fmt.Errorf("worker %q not found", name)
+}
+
+// Names returns the registered worker names, sorted.
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]string, 0, len(m.workers)+len(m.pending))
+	for n := range m.workers {
+		out = append(out, n)
+	}
+	for _, p := range m.pending {
+		out = append(out, p.Name())
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Start launches every registered worker and returns without waiting; see Wait.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx != nil {
+		return fmt.Errorf("manager already started")
+	}
+	m.ctx = ctx
+	for _, w := range m.pending {
+		m.launch(w)
+	}
+	m.pending = nil
 	return nil
 }
+
+// Wait blocks until the context passed to Start is cancelled and every worker has
+// exited. It returns the first error a worker returned, if any.
+func (m *Manager) Wait() error {
+	m.mu.Lock()
+	ctx := m.ctx
+	m.mu.Unlock()
+	if ctx == nil {
+		return fmt.Errorf("manager not started")
+	}
+	<-ctx.Done()
+	// An Add that saw the context alive finishes its wg.Add under m.mu; later ones
+	// fail. Passing through the lock orders both before wg.Wait.
+	m.mu.Lock()
+	m.mu.Unlock()
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
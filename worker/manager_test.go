@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeWorker runs until cancelled; stubborn ones ignore cancellation until released.
+type fakeWorker struct {
+	name     string
+	started  chan struct{}
+	stopped  atomic.Bool
+	stubborn chan struct{}
+	err      error
+}
+
+func newFakeWorker(name string) *fakeWorker {
+	return &fakeWorker{name: name, started: make(chan struct{})}
+}
+
+func (f *fakeWorker) Name() string { return f.name }
+
+func (f *fakeWorker) Start(ctx context.Context) error {
+	close(f.started)
+	if f.err != nil {
+		return f.err
+	}
+	<-ctx.Done()
+	if f.stubborn != nil {
+		<-f.stubborn
+	}
+	f.stopped.Store(true)
+	return nil
+}
+
+func waitStarted(t *testing.T, f *fakeWorker) {
+	t.Helper()
+	select {
+	case <-f.started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("worker %s did not start", f.name)
+	}
+}
+
+func TestManagerStopsOneWorker(t *testing.T) {
+	a, b := newFakeWorker("a"), newFakeWorker("b")
+	m, err := NewManager(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitStarted(t, a)
+	waitStarted(t, b)
+
+	if err := m.Stop("a", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if !a.stopped.Load() || b.stopped.Load() {
+		t.Fatalf("after Stop(a): a stopped=%v, b stopped=%v", a.stopped.Load(), b.stopped.Load())
+	}
+	if err := m.Stop("a", time.Second); err == nil {
+		t.Error("Stop of a removed worker = nil error")
+	}
+
+	// Workers added after Start run immediately, and a stopped name can be reused.
+	a2 := newFakeWorker("a")
+	if err := m.Add(a2); err != nil {
+		t.Fatal(err)
+	}
+	waitStarted(t, a2)
+	if err := m.Add(newFakeWorker("b")); err == nil {
+		t.Error("Add with a duplicate name = nil error")
+	}
+
+	done := make(chan error)
+	go func() { done <- m.Wait() }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait did not return after cancellation")
+	}
+	if !a2.stopped.Load() || !b.stopped.Load() {
+		t.Error("Wait returned before all workers stopped")
+	}
+}
+
+func TestManagerStopTimeoutAndRemove(t *testing.T) {
+	slow := newFakeWorker("slow")
+	slow.stubborn = make(chan struct{})
+	other := newFakeWorker("other")
+	m, err := NewManager(slow, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = m.Start(ctx)
+	waitStarted(t, slow)
+	waitStarted(t, other)
+
+	if err := m.Stop("slow", 20*time.Millisecond); err == nil {
+		t.Error("Stop of a worker ignoring cancellation = nil error")
+	}
+	close(slow.stubborn)
+
+	if err := m.Remove("other"); err != nil {
+		t.Fatal(err)
+	}
+	if names := m.Names(); len(names) != 0 {
+		t.Errorf("Names after removing all = %v", names)
+	}
+	cancel()
+	if err := m.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if !other.stopped.Load() {
+		t.Error("removed worker was not cancelled")
+	}
+}
+
+func TestNewManagerRejectsDuplicateNames(t *testing.T) {
+	if _, err := NewManager(newFakeWorker("builder:ch"), newFakeWorker("builder:ch")); err == nil {
+		t.Error("NewManager with a duplicate name = nil error")
+	}
+}
+
+// Add after cancellation fails instead of starting a worker while Wait waits.
+func TestManagerAddDuringWait(t *testing.T) {
+	a := newFakeWorker("a")
+	m, err := NewManager(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = m.Start(ctx)
+	waitStarted(t, a)
+	done := make(chan error)
+	go func() { done <- m.Wait() }()
+	cancel()
+	late := newFakeWorker("late")
+	if err := m.Add(late); err == nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("Add after cancel = %v, want context.Canceled", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-late.started:
+		t.Error("worker added after cancel was started")
+	default:
+	}
+}
+
+func TestManagerWaitReportsWorkerError(t *testing.T) {
+	bad := newFakeWorker("bad")
+	bad.err = errors.New("boom")
+	m, err := NewManager(bad, newFakeWorker("good"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = m.Start(ctx)
+	waitStarted(t, bad)
+	cancel()
+	if err := m.Wait(); err == nil || !errors.Is(err, bad.err) {
+		t.Errorf("Wait = %v, want wrapped %v", err, bad.err)
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/mastodon"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// MastodonCollector polls the trending links of Mastodon instances and stores them
+// into period ZSETs under the instance host, so channels mix instances or pick
+// some in nodes. Links are scored on their recent sharing accounts (see
+// mastodon.Client.TrendingLinks) with the usual time decay.
+type MastodonCollector struct {
+	Instances []MastodonSource
+	Store     *storage.RedisStore
+	Interval  time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores links; zero fields use ranking.ForSource("mastodon"), the
+	// Hacker News formula on points.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each link's JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Instances after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled instances; the hosts added are returned; the change applies at the next run.
+func (w *MastodonCollector) SetNodes(units []MastodonSource) (added []string) {
+	return setUnits(&w.mu, &w.Instances, units, MastodonSource.Host)
+}
+
+func (w *MastodonCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, mastodonCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// mastodonCollectorName identifies the collector's persisted status record.
+const mastodonCollectorName = "mastodon-collector"
+
+func (w *MastodonCollector) Name() string { return mastodonCollectorName }
+
+// Hosts returns the hosts of the polled instances.
+func (w *MastodonCollector) Hosts() []string {
+	return unitKeys(currentUnits(&w.mu, &w.Instances), MastodonSource.Host)
+}
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Instances that fail are logged, counted, and
+// joined into the error; the others are still stored.
+func (w *MastodonCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, mastodonCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, mastodonCollectorName, mastodon.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, mastodonCollectorName, started, err)
+	countCollected(ctx, w.Store, mastodon.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *MastodonCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	instances := currentUnits(&w.mu, &w.Instances)
+	hosts := make([]string, len(instances))
+	for i, in := range instances {
+		hosts[i] = in.Host()
+	}
+	loop := collectLoop{source: mastodon.Source, unit: "instance", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now}
+	return loop.run(ctx, hosts, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return instances[i].TrendingLinks(ctx)
+	})
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/mastodon"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+)
+
+// Links of two instances are stored under their hosts and ranked by recent sharing
+// accounts; a channel picks an instance in nodes.
+func TestMastodonCollectorMixesInstances(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(n int) int64 { return today.AddDate(0, 0, -n).Unix() }
+	links := map[string]string{
+		"/a/api/v1/trends/links": fmt.Sprintf(`[
+			{"url": "https://example.org/hot", "title": "Hot today", "history": [{"day": "%[1]d", "uses": "90", "accounts": "60"}, {"day": "%[2]d", "uses": "0", "accounts": "0"}]},
+			{"url": "https://example.org/fading", "title": "Fading", "history": [{"day": "%[1]d", "uses": "4", "accounts": "4"}, {"day": "%[2]d", "uses": "80", "accounts": "70"}]}
+		]`, day(0), day(1)),
+		"/b/api/v1/trends/links": fmt.Sprintf(`[
+			{"url": "https://example.org/hot", "title": "Hot today", "history": [{"day": "%d", "uses": "9", "accounts": "8"}]}
+		]`, day(0)),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := links[r.URL.Path]
+		if !ok {
+			http.Error(w, "private", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	// The test server has one host, so the instances are told apart by host aliases.
+	a := &hostAlias{Client: mastodon.NewClient(srv.URL+"/a", ""), host: "a.example"}
+	b := &hostAlias{Client: mastodon.NewClient(srv.URL+"/b", ""), host: "b.example"}
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &MastodonCollector{Instances: []MastodonSource{a, b}, Store: store}
+	res, err := c.RunOnce(ctx)
+	if err != nil || res.Stored != 3 {
+		t.Fatalf("RunOnce = %+v, %v; want 3 links stored", res, err)
+	}
+	got, err := store.TopNews(ctx, "mastodon", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(got) != 3 {
+		t.Fatalf("TopNews = %d items, %v", len(got), err)
+	}
+	if got[0].Item.Title != "Hot today" || got[0].Item.NodeName != "a.example" || got[1].Item.Title != "Fading" {
+		t.Errorf("ranked %q@%s, %q@%s, %q@%s", got[0].Item.Title, got[0].Item.NodeName, got[1].Item.Title, got[1].Item.NodeName, got[2].Item.Title, got[2].Item.NodeName)
+	}
+	bld := &NewsletterBuilder{Store: store, Source: "mastodon", Nodes: []string{"B.example"}}
+	if kept := bld.rank(ctx, got); len(kept) != 1 || kept[0].Item.NodeName != "b.example" {
+		t.Errorf("channel on b.example kept %v", itemIDs(kept))
+	}
+	if kept := (&NewsletterBuilder{Store: store, Source: "mastodon"}).rank(ctx, got); !slices.Equal(itemIDs(kept), itemIDs(got)) {
+		t.Errorf("channel without nodes kept %v", itemIDs(kept))
+	}
+	if u := NodeURL("mastodon", "", "mastodon.social"); u != "https://mastodon.social/explore/links" {
+		t.Errorf("node URL = %q", u)
+	}
+}
+
+// hostAlias reports a host of its own and stores the instance's links under it.
+type hostAlias struct {
+	*mastodon.Client
+	host string
+}
+
+func (h *hostAlias) Host() string { return h.host }
+
+func (h *hostAlias) TrendingLinks(ctx context.Context) ([]model.NewsItem, error) {
+	items, err := h.Client.TrendingLinks(ctx)
+	for i := range items {
+		items[i].NodeName = h.host
+		items[i].ID = mastodon.ItemID(h.host, items[i].URL)
+	}
+	return items, err
+}
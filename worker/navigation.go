@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+// Navigate links d, the digest of channel for key, to the channel's digests of the
+// periods before and after it on the Quaily site at siteBaseURL, from their publish
+// metadata. A period without a digest (before the channel's first, one skipped for
+// too few items, or one yet to come) gets no link. A digest that went to a preview
+// channel is linked there.
+func Navigate(ctx context.Context, store *storage.RedisStore, channel, key, siteBaseURL string, d *newsletter.Data) error {
+	prev, err := period.Previous(key)
+	if err != nil {
+		return err
+	}
+	next, err := period.Next(key)
+	if err != nil {
+		return err
+	}
+	if meta, ok, err := store.GetPublishMeta(ctx, channel, prev); err != nil {
+		return fmt.Errorf("read publish metadata of %s: %w", prev, err)
+	} else if ok && meta.Slug != "" {
+		d.PreviousSlug, d.PreviousTitle = meta.Slug, meta.Title
+		d.PreviousURL = quaily.PostURL(siteBaseURL, meta.QuailySlug(channel), meta.Slug)
+	}
+	if meta, ok, err := store.GetPublishMeta(ctx, channel, next); err != nil {
+		return fmt.Errorf("read publish metadata of %s: %w", next, err)
+	} else if ok && meta.Slug != "" {
+		d.NextSlug, d.NextTitle = meta.Slug, meta.Title
+		d.NextURL = quaily.PostURL(siteBaseURL, meta.QuailySlug(channel), meta.Slug)
+	}
+	return nil
+}
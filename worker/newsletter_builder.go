@@ -2,22 +2,29 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/fsutil"
 	"quaily-journalist/internal/imagegen"
 	"quaily-journalist/internal/model"
 	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/period"
 	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/ranking"
 	"quaily-journalist/internal/scrape"
+	"quaily-journalist/internal/selection"
 	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
 )
 
 type NewsletterBuilder struct {
@@ -42,8 +49,142 @@ type NewsletterBuilder struct {
 	CoverGen      imagegen.Generator
 	CoverPrompt   string
 	CoverAspect   string
+	// MinContentRunesForAI skips the AI item summary when content is shorter; 0 disables.
+	MinContentRunesForAI int
+	// QualityGate optionally drops low-relevance items before the min_items check; nil disables.
+	QualityGate *selection.QualityGate
+	// ShowAuthor renders item authors in the metadata line.
+	ShowAuthor bool
+	// TitleDedupThreshold controls near-duplicate title collapsing; see selection.DedupItems.
+	TitleDedupThreshold float64
+	// OutputLayout places digests under year/month subdirectories; see DigestDir.
+	OutputLayout string
+	// Formats lists the output formats written per digest (see newsletter.ParseFormats);
+	// empty means markdown only. Only the markdown file is published to Quaily.
+	Formats []string
+	// SEO asks the summarizer for a search description and keywords for the frontmatter.
+	SEO bool
+	// PullQuote asks the summarizer for a quote from the items to render under the preface.
+	PullQuote bool
+	// WhyItMatters asks for a one-line takeaway per item with its description; see
+	// SummarizeItemWithTakeaway.
+	WhyItMatters bool
+	// Email and Telegram queue deliveries of each digest to those targets; the
+	// delivery reconciler performs and retries them.
+	Email    bool
+	Telegram bool
+	// Ranking rescores the fetched candidates for this channel; nil keeps the collector scores.
+	Ranking *ranking.Scorer
+	// NodeWeights multiplies item scores by their node's weight before ranking; see selection.WeightByNode.
+	NodeWeights map[string]float64
+	// RepeatPenalty scales an item's score by (1-RepeatPenalty) per earlier digest of
+	// the channel that included it; 0 disables. See selection.PenalizeRepeats.
+	RepeatPenalty float64
+	// TopComments, when set on a Hacker News channel, adds each item's top comment as
+	// a community highlight; see TopComments.
+	TopComments HNSource
+	// OnInsufficientItems handles a previous period that ended below MinItems:
+	// InsufficientSkip (default) or InsufficientPublish; see CheckOnInsufficientItems.
+	OnInsufficientItems string
+	// Notifier reports periods skipped for insufficient items; nil only logs them.
+	Notifier notify.Notifier
+	// MaxContentBytes caps the rendered markdown of a digest (Quaily's post size
+	// limit); 0 disables. OnOversize picks newsletter.OversizeTrim (default) or
+	// newsletter.OversizeSplit for digests above it.
+	MaxContentBytes int
+	OnOversize      string
+	// MaxFetchDepth caps how many of the period's top items are read to find TopN
+	// candidates; 0 uses TopN×selection.DefaultFetchDepthFactor, negative reads the
+	// whole period.
+	MaxFetchDepth int
+	// PreviewChannel, while PreviewUntil is in the future, is the Quaily channel slug
+	// digests are published and delivered to instead of Channel (e.g., a staging
+	// channel to check a template change). Files and published markers are unchanged.
+	PreviewChannel string
+	PreviewUntil   time.Time
+	// ItemOrder lists the selected items by score (default), chronologically, or by
+	// node; see selection.OrderItems.
+	ItemOrder string
+	// Discord and Slack queue a digest summary post per part to those webhooks,
+	// delivered like Email and Telegram.
+	Discord bool
+	Slack   bool
+	// PinLabel marks pinned items (see selection.PinnedItems) in the digest, e.g., "Editor's pick";
+	// empty renders them unmarked.
+	PinLabel string
+	// MinReplies is the least replies an item outside Hacker News needs; 0 means 1,
+	// negative keeps any item with a positive score (see selection.DropLowSignal).
+	MinReplies int
+	// DeriveFrom names a daily channel whose published items make up this weekly
+	// channel's candidates (see DerivedItems) instead of the source's weekly period;
+	// node filters do not apply. The digest is built once the week has ended.
+	DeriveFrom string
+	// KeepFiles, when positive, keeps only the newest KeepFiles digests of the channel
+	// on disk after each publish; RetentionMode deletes (default) or archives the rest.
+	// See PruneDigestFiles.
+	KeepFiles     int
+	RetentionMode string
+	// Style is newsletter.StyleMinimal for a links-only digest: no post summary
+	// in the body and a compact list of items. Such channels are configured
+	// without a Summarizer, CoverGen, or Cloudflare client.
+	Style string
+	// SiteBaseURL, when set, adds links to the channel's previous and next digests
+	// on that Quaily site to the footer; see Navigate.
+	SiteBaseURL string
+	// NoPostSummary and NoShortSummary leave out the post summary and the short
+	// summary: neither the summarizer nor the title fallback is asked for them.
+	NoPostSummary  bool
+	NoShortSummary bool
+	// QuietHours defers publishing: runs inside the window do nothing, and the first
+	// run after it evaluates the period (and closes the previous one) as usual.
+	QuietHours QuietHours
+	// PublishLockTTL bounds how long a run holds a period's publish lock (see
+	// lockPublish), so an instance that dies mid-publish blocks the period no longer;
+	// 0 uses DefaultPublishLockTTL.
+	PublishLockTTL time.Duration
+	Now            func() time.Time // clock; nil uses time.Now
+
+	render func(newsletter.Data, []string) ([]newsletter.Output, error) // nil uses newsletter.RenderAll; swapped in tests
+	fit    func(newsletter.Data) ([]newsletter.Data, error)             // nil uses fitSize; swapped in tests
+}
+
+// DefaultPublishLockTTL is the default NewsletterBuilder.PublishLockTTL, well beyond
+// rendering, writing, and the Quaily publish of a digest.
+const DefaultPublishLockTTL = 10 * time.Minute
+
+// publishLockPoll is how often a run waiting for a period's publish lock retries it.
+var publishLockPoll = 500 * time.Millisecond
+
+// Values of NewsletterBuilder.OnInsufficientItems.
+const (
+	InsufficientSkip    = "skip"
+	InsufficientPublish = "publish"
+)
+
+// LightEditionMarker is appended to the title of a digest published below MinItems.
+const LightEditionMarker = " (light edition)"
+
+// CheckOnInsufficientItems reports an unknown on_insufficient_items value; empty means skip.
+func CheckOnInsufficientItems(v string) error {
+	switch v {
+	case "", InsufficientSkip, InsufficientPublish:
+		return nil
+	}
+	return fmt.Errorf("on_insufficient_items must be %q or %q, got %q", InsufficientSkip, InsufficientPublish, v)
 }
 
+// QuailyChannel is the Quaily channel slug digests are published to at now: the
+// preview channel while preview mode lasts, else the channel name.
+func (w *NewsletterBuilder) QuailyChannel(now time.Time) string {
+	if w.PreviewChannel != "" && now.Before(w.PreviewUntil) {
+		return w.PreviewChannel
+	}
+	return w.Channel
+}
+
+// Name is "builder:<channel>".
+func (w *NewsletterBuilder) Name() string { return "builder:" + w.Channel }
+
 func (w *NewsletterBuilder) Start(ctx context.Context) error {
 	if w.Interval <= 0 {
 		w.Interval = 30 * time.Minute
@@ -54,7 +195,7 @@ func (w *NewsletterBuilder) Start(ctx context.Context) error {
 		return err
 	}
 	// run immediately then on interval
-	w.runOnce(ctx)
+	w.run(ctx)
 
 	t := time.NewTicker(w.Interval)
 	defer t.Stop()
@@ -63,157 +204,628 @@ func (w *NewsletterBuilder) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case <-t.C:
-			w.runOnce(ctx)
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *NewsletterBuilder) run(ctx context.Context) {
+	if _, err := w.RunOnce(ctx); err != nil {
+		slog.Warn("builder: run failed", "err", err, "channel", w.Channel)
+	}
+}
+
+// RunOnce evaluates the current period once, as Start does on every tick: unless the
+// period is already published, it ranks and filters the candidates and, with at least
+// MinItems left, writes the digest, marks it published, and queues its deliveries.
+// Failures before the digest is marked published are returned; later ones (metadata,
+// deliveries, Quaily) are logged, as the digest itself was written.
+//
+// Before that, the previous period is closed once if it was never published; see
+// closePreviousPeriod. Its outcome is logged, not returned.
+//
+// The run is recorded in the builder's worker status, including its last error: the
+// returned one, a Quaily publish failure, or a failure closing the previous period.
+// Inside QuietHours nothing is evaluated and the result is skipped as BuildQuietHours.
+func (w *NewsletterBuilder) RunOnce(ctx context.Context) (BuildResult, error) {
+	now := nowFunc(w.Now)
+	if skipQuiet(ctx, w.Store, w.Name(), w.QuietHours, now) != nil {
+		return BuildResult{Period: period.Key(w.Frequency, now.UTC()), Skipped: BuildQuietHours}, nil
+	}
+	closeErr := w.closePreviousPeriod(ctx, now)
+	var res BuildResult
+	var err error
+	if w.DeriveFrom != "" {
+		// The week's daily digests are still being published.
+		res = BuildResult{Period: period.Key(w.Frequency, now.UTC()), Skipped: BuildPeriodOpen}
+	} else {
+		res, err = w.buildPeriod(ctx, period.Key(w.Frequency, now.UTC()), now, now, false)
+	}
+	errs := []error{closeErr, err}
+	if res.PublishError != "" {
+		errs = append(errs, fmt.Errorf("quaily publish of %s: %s", res.Period, res.PublishError))
+	}
+	recordOutcome(ctx, w.Store, w.Name(), now, errors.Join(errs...))
+	return res, err
+}
+
+// closePreviousPeriod evaluates the period before now's one last time. A period that
+// still reaches MinItems (items may have arrived after the last tick) is published as
+// usual; one that ended below it is published as a light edition or recorded as
+// skipped and reported, per OnInsufficientItems. Periods that are published or
+// already recorded as skipped are left alone, so this runs once per rollover.
+// Failures are logged and returned for the worker status.
+func (w *NewsletterBuilder) closePreviousPeriod(ctx context.Context, now time.Time) error {
+	at := now.AddDate(0, 0, -1)
+	if w.Frequency == "weekly" {
+		at = now.AddDate(0, 0, -7)
+	}
+	key := period.Key(w.Frequency, at.UTC())
+	meta, ok, err := w.Store.GetPublishMeta(ctx, w.Channel, key)
+	if err != nil {
+		slog.Warn("builder: read publish metadata failed", "err", err, "channel", w.Channel, "period", key)
+		return fmt.Errorf("read publish metadata of %s: %w", key, err)
+	}
+	if ok && meta.Skipped != "" {
+		return nil
+	}
+	if w.DeriveFrom != "" {
+		if settled, err := deriveSettled(ctx, w.Store, w.DeriveFrom, key, now); err != nil || !settled {
+			return err
 		}
 	}
+	res, err := w.buildPeriod(ctx, key, at, now, true)
+	if errors.Is(err, ErrNothingToDerive) {
+		// No digest will be published into a past week any more; report it once.
+		w.recordSkipped(ctx, key, err.Error())
+	}
+	if err != nil {
+		slog.Warn("builder: closing previous period failed", "err", err, "channel", w.Channel, "period", key)
+		return fmt.Errorf("close %s: %w", key, err)
+	}
+	if res.PublishError != "" {
+		return fmt.Errorf("quaily publish of %s: %s", key, res.PublishError)
+	}
+	return nil
 }
 
-func (w *NewsletterBuilder) runOnce(ctx context.Context) {
-	period := periodKey(w.Frequency, time.Now().UTC())
+// buildPeriod evaluates one period, dating the digest at; now is the run's clock,
+// which stamps the publish metadata and decides preview mode. closing marks a period
+// that has ended, whose shortfall below MinItems is handled per OnInsufficientItems.
+func (w *NewsletterBuilder) buildPeriod(ctx context.Context, period string, at, now time.Time, closing bool) (BuildResult, error) {
+	res := BuildResult{Period: period}
 	published, err := w.Store.IsPublished(ctx, w.Channel, period)
 	if err != nil {
-		slog.Warn("builder: check published failed", "err", err, "channel", w.Channel, "period", period)
-		return
+		return res, fmt.Errorf("check published: %w", err)
 	}
 	if published {
-		return
+		res.Skipped = BuildAlreadyPublished
+		return res, nil
 	}
 
-	// Fetch more than TopN so filtering by nodes still leaves enough.
-	fetchN := w.TopN * 5
-	if fetchN < w.TopN { // overflow safety, though unlikely
-		fetchN = w.TopN
+	items, depth, err := w.candidates(ctx, period)
+	if err != nil {
+		return res, err
+	}
+	res.Candidates = depth
+	// Pinned items lead the digest whatever their rank, and count toward TopN. A
+	// period nothing was collected for gets none, so pins alone never make a digest.
+	var pins []model.WithScore
+	if depth > 0 {
+		pins, err = selection.PinnedItems(ctx, w.Store, w.Channel, w.Source, period)
+		if err != nil {
+			slog.Warn("builder: load pinned items failed", "err", err, "channel", w.Channel)
+		}
+		items = selection.MergePins(pins, items)
+	}
+	res.Filtered = len(items)
+	light := false
+	if len(items) < w.MinItems {
+		res.Skipped = BuildBelowMinItems
+		// A period nothing was collected for (e.g., the service was not running) is not reported.
+		if !closing || res.Candidates == 0 {
+			return res, nil
+		}
+		if len(items) == 0 || w.OnInsufficientItems != InsufficientPublish {
+			w.recordInsufficient(ctx, period, len(items))
+			return res, nil
+		}
+		res.Skipped, res.Light, light = "", true, true
 	}
-	items, err := w.Store.TopNews(ctx, w.Source, period, fetchN)
+	formats, err := newsletter.ParseFormats(w.Formats)
 	if err != nil {
-		slog.Warn("builder: fetch top news failed", "err", err, "source", w.Source, "channel", w.Channel, "period", period)
-		return
+		return res, err
 	}
-	// For Hacker News, nodes represent lists to poll; only filter by nodes if
-	// they include item types (ask/show/job/story). Otherwise, skip filtering.
-	if strings.ToLower(w.Source) == "hackernews" {
-		items = filterHNTypes(items, w.Nodes)
-	} else {
-		items = filterByNodes(items, w.Nodes)
+	data := w.buildData(ctx, period, at, w.selectItems(items, len(pins)))
+	if w.SiteBaseURL != "" {
+		if err := Navigate(ctx, w.Store, w.Channel, period, w.SiteBaseURL, &data); err != nil {
+			slog.Warn("builder: navigation links skipped", "err", err, "channel", w.Channel, "period", period)
+		}
 	}
-	// filter out low-signal items (safety, though collector already skips)
-	nz := make([]model.WithScore, 0, len(items))
-	for _, ws := range items {
-		if strings.ToLower(w.Source) == "hackernews" {
-			if ws.Score > 0 { // use computed score only; comments may be 0
-				nz = append(nz, ws)
-			}
-		} else {
-			if ws.Item.Replies > 0 && ws.Score > 0 {
-				nz = append(nz, ws)
-			}
+	// Shutting down mid-build leaves fallback descriptions where AI calls were cut
+	// short; write nothing so the period is built again on the next run.
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	pinned := make(map[string]bool, len(pins))
+	for _, ws := range pins {
+		pinned[ws.Item.ID] = true
+	}
+	for i := range data.Items {
+		if pinned[data.Items[i].ID] {
+			data.Items[i].Label = w.PinLabel
 		}
 	}
-	items = nz
-	// filter by skip marks
-	filtered := make([]model.WithScore, 0, len(items))
-	for _, ws := range items {
-		skip, err := w.Store.IsSkipped(ctx, w.Channel, ws.Item.ID)
+	if light {
+		data.Title += LightEditionMarker
+	}
+	// Instances of serve sharing Redis take turns from here to the Quaily publish; one
+	// that waited for another finds the period published and stops.
+	release, err := w.lockPublish(ctx, period)
+	if err != nil {
+		return res, err
+	}
+	defer release()
+	if published, err := w.Store.IsPublished(ctx, w.Channel, period); err != nil {
+		return res, fmt.Errorf("check published: %w", err)
+	} else if published {
+		res.Skipped = BuildAlreadyPublished
+		return res, nil
+	}
+	parts, err := w.fitParts(data)
+	if err != nil {
+		return res, err
+	}
+	// Exactly the items that made it into a part are marked as used and recorded.
+	used := renderedItems(parts, items)
+	// Render every part before writing anything: a render failure must leave no file,
+	// published marker, or skip marker behind, so the period is built again next tick.
+	rendered := make([][]newsletter.Output, len(parts))
+	for i, part := range parts {
+		outputs, err := w.renderAll(part, formats)
 		if err != nil {
-			slog.Warn("builder: skip-check failed", "err", err, "channel", w.Channel, "item_id", ws.Item.ID)
-			continue
-		}
-		if !skip {
-			filtered = append(filtered, ws)
+			err = fmt.Errorf("render %s: %w", part.Slug, err)
+			w.notifyRenderFailed(ctx, period, err)
+			return res, err
 		}
+		rendered[i] = outputs
 	}
-	items = filtered
-	if len(items) < w.MinItems {
-		return
+	dir := DigestDir(w.OutputDir, w.Channel, w.OutputLayout, at)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return res, fmt.Errorf("create output dir: %w", err)
 	}
-	md := w.renderMarkdown(period, items)
-	name := w.filename(period)
-	path := filepath.Join(w.OutputDir, w.Channel, name)
-	if err := os.WriteFile(path, []byte(md), 0o644); err != nil {
-		slog.Warn("builder: write file failed", "err", err, "channel", w.Channel, "path", path)
-		return
+	// Atomic writes: a crash mid-write must not leave a truncated digest marked as published.
+	partPaths := make([]map[string]string, len(parts))
+	mdPaths := make([]string, len(parts)) // the markdown files, published to Quaily
+	for i, part := range parts {
+		outputs := rendered[i]
+		partPaths[i] = make(map[string]string, len(outputs))
+		for _, o := range outputs {
+			p := filepath.Join(dir, part.Slug+o.Ext)
+			if err := fsutil.WriteFileAtomic(p, o.Content); err != nil {
+				return res, fmt.Errorf("write %s: %w", p, err)
+			}
+			if abs, err := filepath.Abs(p); err == nil {
+				partPaths[i][o.Format] = abs
+			} else {
+				partPaths[i][o.Format] = p
+			}
+			if o.Format == newsletter.FormatMarkdown {
+				mdPaths[i] = p
+			}
+		}
 	}
 	if err := w.Store.MarkPublished(ctx, w.Channel, period); err != nil {
-		slog.Warn("builder: mark published failed", "err", err, "channel", w.Channel, "period", period)
-		return
+		return res, fmt.Errorf("mark %s published: %w", period, err)
+	}
+	paths := partPaths[0]
+	res.Path, res.Paths = paths[formats[0]], paths
+	meta := storage.PublishMeta{Path: paths[formats[0]], Paths: paths, Slug: parts[0].Slug, Title: parts[0].Title, WrittenAt: now.UTC(), ItemIDs: itemIDs(used)}
+	if w.Quaily != nil {
+		if qch := w.QuailyChannel(now); qch != w.Channel {
+			meta.QuailyChannel = qch
+			slog.Warn("builder: PREVIEW MODE: publishing to the preview Quaily channel instead of the channel", "channel", w.Channel, "preview_channel", qch, "until", w.PreviewUntil)
+		}
+	}
+	for i, part := range parts[1:] {
+		meta.Parts = append(meta.Parts, storage.PublishPart{Slug: part.Slug, Paths: partPaths[i+1]})
+	}
+	if err := w.Store.SetPublishMeta(ctx, w.Channel, period, meta); err != nil {
+		slog.Warn("builder: save publish metadata failed", "err", err, "channel", w.Channel, "period", period)
+	}
+	for i, part := range parts {
+		for target, on := range map[string]bool{storage.TargetEmail: w.Email, storage.TargetTelegram: w.Telegram, storage.TargetDiscord: w.Discord, storage.TargetSlack: w.Slack} {
+			if !on {
+				continue
+			}
+			task := storage.DeliveryTask{Channel: w.Channel, Slug: part.Slug, Target: target, Title: part.Title, Paths: partPaths[i], Period: period}
+			if err := QueueTask(ctx, w.Store, task, time.Now()); err != nil {
+				slog.Warn("builder: queue delivery failed", "err", err, "target", target, "channel", w.Channel, "slug", part.Slug)
+			}
+		}
 	}
 	// mark items as skipped for the configured duration
-	for _, ws := range items[:min(len(items), w.TopN)] {
+	for _, ws := range used {
 		if err := w.Store.MarkSkipped(ctx, w.Channel, ws.Item.ID, w.SkipDuration); err != nil {
 			slog.Warn("builder: mark skipped failed", "err", err, "channel", w.Channel, "item_id", ws.Item.ID)
 		}
 	}
-	slog.Info("builder: published", "channel", w.Channel, "path", path, "items", len(items))
-	// After generating, publish to Quaily if configured
-	if w.Quaily != nil {
-		ctxPub, cancel := context.WithTimeout(ctx, 30*time.Second)
+	for _, ws := range used {
+		if !pinned[ws.Item.ID] {
+			continue
+		}
+		if _, err := w.Store.UnpinItem(ctx, w.Channel, ws.Item.ID); err != nil {
+			slog.Warn("builder: unpin failed", "err", err, "channel", w.Channel, "item_id", ws.Item.ID)
+		}
+	}
+	if err := w.Store.RecordAppearances(ctx, w.Channel, meta.ItemIDs); err != nil {
+		slog.Warn("builder: record appearances failed", "err", err, "channel", w.Channel)
+	}
+	slog.Info("builder: published", "channel", w.Channel, "period", period, "paths", paths, "items", len(used), "parts", len(parts), "light", light)
+	// After generating, publish the markdown files to Quaily if configured
+	if w.Quaily != nil && mdPaths[0] == "" {
+		slog.Warn("builder: quaily publish skipped; markdown is not among the channel formats", "channel", w.Channel)
+	}
+	if w.Quaily != nil && mdPaths[0] != "" {
+		if err := w.publishParts(ctx, period, meta, mdPaths); err != nil {
+			res.PublishError = err.Error()
+		}
+	}
+	w.pruneFiles(ctx)
+	return res, nil
+}
+
+// lockPublish takes the publish lock of the channel's period, waiting while another
+// instance holds it; the wait is logged and counted (CounterPublishLockContended).
+// The returned func releases the lock unless it expired and was taken meanwhile.
+func (w *NewsletterBuilder) lockPublish(ctx context.Context, period string) (release func(), err error) {
+	ttl := w.PublishLockTTL
+	if ttl <= 0 {
+		ttl = DefaultPublishLockTTL
+	}
+	name := "publish:" + w.Channel + ":" + period
+	token, ok, err := w.Store.AcquireLock(ctx, name, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("acquire publish lock: %w", err)
+	}
+	if !ok {
+		started := time.Now()
+		slog.Warn("builder: publish lock held by another instance; waiting", "channel", w.Channel, "period", period)
+		if err := w.Store.IncrCounter(ctx, CounterPublishLockContended, started, 1); err != nil {
+			slog.Warn("builder: count publish lock contention failed", "err", err, "channel", w.Channel)
+		}
+		for !ok {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(publishLockPoll):
+			}
+			if token, ok, err = w.Store.AcquireLock(ctx, name, ttl); err != nil {
+				return nil, fmt.Errorf("acquire publish lock: %w", err)
+			}
+		}
+		slog.Info("builder: publish lock acquired", "channel", w.Channel, "period", period, "waited", time.Since(started).Round(time.Millisecond))
+	}
+	return func() {
+		// A canceled run still releases, rather than holding the period for the TTL.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 		defer cancel()
-		if err := quaily.PublishMarkdownFile(ctxPub, w.Quaily, path, w.Channel); err != nil {
-			slog.Warn("builder: quaily publish failed", "err", err, "channel", w.Channel, "path", path)
-		} else {
-			slog.Info("builder: quaily publish ok", "channel", w.Channel, "path", path)
-			// After publish, schedule a send (deliver) 5s later.
-			p := path
-			ch := w.Channel
-			go func() {
-				// small delay to allow publish to settle
-				time.Sleep(5 * time.Second)
-				ctxDel, cancelDel := context.WithTimeout(context.Background(), 30*time.Second)
-				defer cancelDel()
-				if err := quaily.DeliverMarkdownOrSlug(ctxDel, w.Quaily, p, ch); err != nil {
-					slog.Warn("builder: quaily deliver failed", "err", err, "channel", ch, "path", p)
-				} else {
-					slog.Info("builder: quaily deliver ok", "channel", ch, "path", p)
-				}
-			}()
+		released, err := w.Store.ReleaseLock(ctx, name, token)
+		if err != nil {
+			slog.Warn("builder: release publish lock failed", "err", err, "channel", w.Channel, "period", period)
+		} else if !released {
+			slog.Warn("builder: publish lock expired before release", "channel", w.Channel, "period", period, "ttl", ttl)
+		}
+	}, nil
+}
+
+// pruneFiles applies KeepFiles after a publish. Failures are only logged: the digest
+// itself is out.
+func (w *NewsletterBuilder) pruneFiles(ctx context.Context) {
+	if w.KeepFiles <= 0 {
+		return
+	}
+	res, err := PruneDigestFiles(ctx, w.Store, PruneOptions{OutputDir: w.OutputDir, Channel: w.Channel, Keep: w.KeepFiles, Mode: w.RetentionMode})
+	if err != nil {
+		slog.Warn("builder: prune digest files failed", "err", err, "channel", w.Channel)
+	}
+	if len(res.Pruned) > 0 || len(res.Held) > 0 {
+		slog.Info("builder: pruned digest files", "channel", w.Channel, "mode", res.Mode, "pruned", len(res.Pruned), "held", res.Held)
+	}
+}
+
+// selectItems is the digest's item list; see selection.Config.Select.
+func (w *NewsletterBuilder) selectItems(items []model.WithScore, pinned int) []model.WithScore {
+	return w.selection().Select(items, pinned)
+}
+
+// selection returns the channel's selection settings.
+func (w *NewsletterBuilder) selection() selection.Config {
+	return selection.Config{
+		Channel:             w.Channel,
+		Source:              strings.ToLower(w.Source),
+		Nodes:               w.Nodes,
+		MinReplies:          w.MinReplies,
+		NodeWeights:         w.NodeWeights,
+		RepeatPenalty:       w.RepeatPenalty,
+		TitleDedupThreshold: w.TitleDedupThreshold,
+		TopN:                w.TopN,
+		ItemOrder:           w.ItemOrder,
+		MaxFetchDepth:       w.MaxFetchDepth,
+		Scorer:              w.Ranking,
+	}
+}
+
+// itemIDs returns the IDs of items in order.
+func itemIDs(items []model.WithScore) []string {
+	ids := make([]string, len(items))
+	for i, ws := range items {
+		ids[i] = ws.Item.ID
+	}
+	return ids
+}
+
+// renderedItems returns, in digest order, the items of parts, which may have dropped
+// some of the selected items to fit the content limit.
+func renderedItems(parts []newsletter.Data, items []model.WithScore) []model.WithScore {
+	byID := make(map[string]model.WithScore, len(items))
+	for _, ws := range items {
+		byID[ws.Item.ID] = ws
+	}
+	var out []model.WithScore
+	for _, p := range parts {
+		for _, it := range p.Items {
+			if ws, ok := byID[it.ID]; ok {
+				out = append(out, ws)
+			}
+		}
+	}
+	return out
+}
+
+func (w *NewsletterBuilder) fitParts(d newsletter.Data) ([]newsletter.Data, error) {
+	if w.fit != nil {
+		return w.fit(d)
+	}
+	return w.fitSize(d)
+}
+
+// fitSize applies MaxContentBytes to data per OnOversize: the digest itself when it
+// fits or no limit is set, the digest with trailing items dropped, or its parts.
+func (w *NewsletterBuilder) fitSize(data newsletter.Data) ([]newsletter.Data, error) {
+	if w.MaxContentBytes <= 0 {
+		return []newsletter.Data{data}, nil
+	}
+	if w.OnOversize == newsletter.OversizeSplit {
+		parts, err := newsletter.Split(data, w.MaxContentBytes)
+		if err != nil {
+			return nil, fmt.Errorf("split %s: %w", data.Slug, err)
+		}
+		if len(parts) > 1 {
+			slog.Info("builder: digest split to fit the content limit", "channel", w.Channel, "slug", data.Slug, "parts", len(parts), "max_bytes", w.MaxContentBytes)
+		}
+		return parts, nil
+	}
+	trimmed, dropped, err := newsletter.Trim(data, w.MaxContentBytes)
+	if err != nil {
+		return nil, fmt.Errorf("trim %s: %w", data.Slug, err)
+	}
+	if len(dropped) > 0 {
+		slog.Warn("builder: dropped trailing items to fit the content limit", "channel", w.Channel, "slug", data.Slug, "dropped", newsletter.ItemTitles(dropped), "max_bytes", w.MaxContentBytes)
+	}
+	return []newsletter.Data{trimmed}, nil
+}
+
+// publishParts pushes the markdown file of each part to Quaily in order, stopping at
+// the first failure so parts never go out of sequence. Each published part has its
+// delivery queued; QuailyPublishedAt is recorded once every part is live. The publish
+// error, if any, is logged and returned.
+func (w *NewsletterBuilder) publishParts(ctx context.Context, period string, meta storage.PublishMeta, mdPaths []string) error {
+	slugs := []string{meta.Slug}
+	for _, p := range meta.Parts {
+		slugs = append(slugs, p.Slug)
+	}
+	for i, path := range mdPaths {
+		ctxPub, cancel := context.WithTimeout(ctx, 30*time.Second)
+		hash, unchanged, err := PushMarkdownFile(ctxPub, w.Store, w.Quaily, path, meta.QuailySlug(w.Channel), false)
+		cancel()
+		if err != nil {
+			slog.Warn("builder: quaily publish failed", "err", err, "channel", w.Channel, "quaily_channel", meta.QuailySlug(w.Channel), "path", path)
+			return err
+		}
+		if i == 0 {
+			meta.ContentHash = hash
+		}
+		if unchanged {
+			// Already live with this content, and delivered when it was first pushed.
+			continue
 		}
+		slog.Info("builder: quaily publish ok", "channel", w.Channel, "quaily_channel", meta.QuailySlug(w.Channel), "path", path)
+		// Queue the send (deliver) 5s later to let the publish settle; the
+		// delivery reconciler performs it and retries on failure.
+		if err := QueueDelivery(ctx, w.Store, w.Channel, meta.QuailyChannel, slugs[i], time.Now().Add(5*time.Second)); err != nil {
+			slog.Warn("builder: queue quaily delivery failed", "err", err, "channel", w.Channel, "slug", slugs[i])
+		}
+	}
+	pushed := time.Now().UTC()
+	meta.QuailyPublishedAt = &pushed
+	if err := w.Store.SetPublishMeta(ctx, w.Channel, period, meta); err != nil {
+		slog.Warn("builder: save publish metadata failed", "err", err, "channel", w.Channel, "period", period)
+	}
+	return nil
+}
+
+// candidates reads the period's items with selection.Config.Fetch, ranked and
+// filtered for the channel, until TopN pass the quality gate. Items on the source's
+// exclusion list never become candidates. It returns the items, best first, and how many
+// were read.
+func (w *NewsletterBuilder) candidates(ctx context.Context, period string) ([]model.WithScore, int, error) {
+	if w.DeriveFrom != "" {
+		return w.derivedCandidates(ctx, period)
+	}
+	sel := w.selection()
+	excluded, err := selection.LoadExclusions(ctx, w.Store, w.Source)
+	if err != nil {
+		slog.Warn("builder: load exclusions failed", "err", err, "channel", w.Channel)
+	}
+	perBatch := append(w.ranking(sel), excluded.Stage())
+	// Reposts are collapsed before skip marks apply, so a repost of an item that
+	// was already published is dropped with it. Skip marks already looked up are
+	// cached across batches, as are relevance scores, so the quality gate rejecting
+	// items only makes the next batch read deeper.
+	perPool := selection.Pipeline{sel.Dedup(), sel.DropSkipped(w.Store, map[string]bool{}), w.QualityGate.Stage(w.TopN)}
+	f, err := sel.Fetch(ctx, w.Store, period, perBatch, perPool)
+	if err != nil {
+		return nil, f.Depth, err
+	}
+	slog.Info("builder: fetched candidates", "channel", w.Channel, "period", period, "depth", f.Depth, "items", len(f.Items), "removed", f.Removed)
+	return f.Items, f.Depth, nil
+}
+
+// derivedCandidates returns the DeriveFrom channel's items of the week, without
+// excluded, skipped, and reposted ones or those the quality gate rejects.
+func (w *NewsletterBuilder) derivedCandidates(ctx context.Context, period string) ([]model.WithScore, int, error) {
+	items, err := DerivedItems(ctx, w.Store, w.Source, w.DeriveFrom, period)
+	if err != nil {
+		return nil, 0, err
+	}
+	depth := len(items)
+	sel := w.selection()
+	excluded, err := selection.LoadExclusions(ctx, w.Store, w.Source)
+	if err != nil {
+		slog.Warn("builder: load exclusions failed", "err", err, "channel", w.Channel)
+	}
+	items, removed := selection.Pipeline{excluded.Stage(), sel.Dedup(), sel.DropSkipped(w.Store, nil), w.QualityGate.Stage(w.TopN)}.Run(ctx, items)
+	slog.Info("builder: derived candidates", "channel", w.Channel, "from", w.DeriveFrom, "period", period, "items", len(items), "removed", removed)
+	return items, depth, nil
+}
+
+// ranking is the stages scoring a batch of stored items for the channel (ranking
+// override, node weights, repeat penalty) and dropping the ones outside its nodes
+// or without signal, though collectors already skip those.
+func (w *NewsletterBuilder) ranking(sel selection.Config) selection.Pipeline {
+	return append(sel.Ranking(w.Store, time.Now()), sel.Filters()...)
+}
+
+// rank runs the ranking stages over items.
+func (w *NewsletterBuilder) rank(ctx context.Context, items []model.WithScore) []model.WithScore {
+	items, _ = w.ranking(w.selection()).Run(ctx, items)
+	return items
+}
+
+// recordInsufficient records a closed period that had n items, below MinItems, as skipped.
+func (w *NewsletterBuilder) recordInsufficient(ctx context.Context, period string, n int) {
+	w.recordSkipped(ctx, period, fmt.Sprintf("insufficient items (%d/%d)", n, w.MinItems))
+}
+
+// recordSkipped records a closed period as skipped for reason in its publish metadata
+// and notifies the operator.
+func (w *NewsletterBuilder) recordSkipped(ctx context.Context, period, reason string) {
+	now := time.Now().UTC()
+	meta := storage.PublishMeta{Skipped: reason, SkippedAt: &now}
+	if err := w.Store.SetPublishMeta(ctx, w.Channel, period, meta); err != nil {
+		slog.Warn("builder: save publish metadata failed", "err", err, "channel", w.Channel, "period", period)
+	}
+	slog.Warn("builder: period closed without a digest", "channel", w.Channel, "period", period, "reason", reason)
+	if w.Notifier == nil {
+		return
+	}
+	ev := notify.Event{
+		Kind:    notify.KindDigestSkipped,
+		Channel: w.Channel,
+		Message: fmt.Sprintf("%s digest for %s skipped: %s", w.Channel, period, reason),
+		Time:    now,
+	}
+	if err := w.Notifier.Notify(ctx, ev); err != nil {
+		slog.Warn("builder: notification failed", "kind", ev.Kind, "channel", w.Channel, "err", err)
+	}
+}
+
+func (w *NewsletterBuilder) renderAll(d newsletter.Data, formats []string) ([]newsletter.Output, error) {
+	if w.render != nil {
+		return w.render(d, formats)
+	}
+	return newsletter.RenderAll(d, formats)
+}
+
+// notifyRenderFailed logs a digest that could not be rendered and reports it to the
+// Notifier; the period stays unpublished and is retried on the next tick.
+func (w *NewsletterBuilder) notifyRenderFailed(ctx context.Context, period string, err error) {
+	slog.Error("builder: render failed; nothing written", "channel", w.Channel, "period", period, "err", err)
+	if w.Notifier == nil {
+		return
+	}
+	ev := notify.Event{
+		Kind:    notify.KindRenderFailed,
+		Channel: w.Channel,
+		Message: fmt.Sprintf("%s digest for %s failed to render: %v", w.Channel, period, err),
+		Time:    time.Now().UTC(),
+	}
+	if err := w.Notifier.Notify(ctx, ev); err != nil {
+		slog.Warn("builder: notification failed", "kind", ev.Kind, "channel", w.Channel, "err", err)
 	}
 }
 
-func (w *NewsletterBuilder) filename(period string) string {
+func (w *NewsletterBuilder) filename(at time.Time) string {
 	// Always use ":frequency-YYYYMMDD.md" as filename
-	dateName := time.Now().UTC().Format("20060102")
+	dateName := at.UTC().Format("20060102")
 	return fmt.Sprintf("%s-%s.md", strings.ToLower(w.Frequency), dateName)
 }
 
-func (w *NewsletterBuilder) renderMarkdown(period string, items []model.WithScore) string {
+// buildData assembles the template data of a digest of items (see selectItems),
+// including AI summaries and the cover. The digest is dated at: the current time,
+// or a time within the period being closed.
+func (w *NewsletterBuilder) buildData(ctx context.Context, period string, at time.Time, items []model.WithScore) newsletter.Data {
 	// Build template data
 	// Determine post title: use configured template or default to "Digest of <Channel> <YYYY-MM-DD>"
-	now := time.Now()
+	now := at
 	postTitle := strings.TrimSpace(w.TitleTemplate)
 	if postTitle == "" {
-		postTitle = fmt.Sprintf("Digest of %s %s", w.Channel, time.Now().UTC().Format("2006-01-02"))
+		postTitle = fmt.Sprintf("Digest of %s %s", w.Channel, now.UTC().Format("2006-01-02"))
 	}
 	// Expand template variables in configured title/preface/postscript
 	postTitle = newsletter.ExpandVars(postTitle, now)
 	// Slug is always the filename without ".md"
-	name := w.filename(period)
+	name := w.filename(at)
 	slug := strings.TrimSuffix(name, ".md")
 	data := newsletter.Data{
 		Title:      postTitle,
 		Slug:       slug,
-		Datetime:   time.Now().UTC().Format("2006-01-02 15:04"),
+		Datetime:   now.UTC().Format("2006-01-02 15:04"),
 		Preface:    newsletter.ExpandVars(w.Preface, now),
 		Postscript: newsletter.ExpandVars(w.Postscript, now),
-		Items:      make([]newsletter.Item, 0, min(len(items), w.TopN)),
+		Items:      make([]newsletter.Item, 0, len(items)),
+		Style:      w.Style,
+		Frequency:  w.Frequency,
 	}
-	// Use a base context and rely on per-call timeouts inside the AI client
-	ctxAI := context.Background()
-	maxN := min(len(items), w.TopN)
+	// Per-call timeouts live in the AI client; ctx only stops the calls on shutdown.
+	ctxAI := ctx
 	// Resolve node display titles via cached values in storage (populated at init).
 	nodeTitle := map[string]string{}
 	set := map[string]struct{}{}
-	for i := 0; i < maxN; i++ {
+	for i := 0; i < len(items); i++ {
 		set[items[i].Item.NodeName] = struct{}{}
 	}
 	for n := range set {
-		if t, err := w.Store.GetNodeTitle(context.Background(), w.Source, n); err == nil && strings.TrimSpace(t) != "" {
+		if t, err := w.Store.GetNodeTitle(ctx, w.Source, n); err == nil && strings.TrimSpace(t) != "" {
 			nodeTitle[n] = t
 		}
 	}
-	for i := 0; i < maxN; i++ {
+	var highlights map[string]model.CommentHighlight
+	if w.TopComments != nil && w.Source == "hackernews" {
+		raw := make([]model.NewsItem, len(items))
+		for i := range raw {
+			raw[i] = items[i].Item
+		}
+		highlights = TopComments(ctxAI, w.Store, w.TopComments, w.Summarizer, raw, w.Language)
+	}
+	skippedAI, backedOff := 0, 0
+	for i := 0; i < len(items); i++ {
 		it := items[i].Item
-		var desc string
+		if it.Source == "" {
+			it.Source = w.Source
+		}
+		var desc, why string
 		contentForSum := it.Content
 		// If content is empty and Cloudflare is configured, scrape the URL to populate content before summarizing.
 		if strings.TrimSpace(contentForSum) == "" && w.Cloudflare != nil {
@@ -227,57 +839,107 @@ func (w *NewsletterBuilder) renderMarkdown(period string, items []model.WithScor
 			}
 		}
 		if w.Summarizer != nil {
-			if d, err := w.Summarizer.SummarizeItem(ctxAI, it.Title, contentForSum, w.Language); err == nil && d != "" {
+			if w.MinContentRunesForAI > 0 && textclean.ContentRunes(contentForSum) < w.MinContentRunesForAI {
+				// Too little text to summarize faithfully; use a deterministic description instead.
+				desc = textclean.FirstSentence(contentForSum)
+				skippedAI++
+			} else if w.WhyItMatters {
+				sum, off, err := SummarizeItemWithTakeaway(ctxAI, w.Store, w.Summarizer, it, contentForSum, w.Language)
+				if err != nil {
+					slog.Warn("builder: summarize item failed", "err", err, "channel", w.Channel, "title", it.Title, "url", it.URL)
+				}
+				desc, why = sum.Description, sum.WhyItMatters
+				if off {
+					backedOff++
+				}
+			} else if d, off, err := SummarizeItem(ctxAI, w.Store, w.Summarizer, it, contentForSum, w.Language); err == nil && d != "" {
 				desc = d
+				if off {
+					backedOff++
+				}
 			} else if err != nil {
 				slog.Warn("builder: summarize item failed", "err", err, "channel", w.Channel, "title", it.Title, "url", it.URL)
 			}
 		}
-		nodeURL := nodeURLFor(w.Source, w.BaseURL, it.NodeName)
+		src := it.Source
+		if src == "" {
+			src = w.Source
+		}
+		nodeURL := NodeURL(src, w.BaseURL, it.NodeName)
 		displayNode := it.NodeName
 		if t, ok := nodeTitle[it.NodeName]; ok && strings.TrimSpace(t) != "" {
 			displayNode = t
 		}
+		author := ""
+		if w.ShowAuthor {
+			author = it.Author
+		}
 		data.Items = append(data.Items, newsletter.Item{
-			Title:       it.Title,
-			URL:         it.URL,
-			NodeName:    displayNode,
-			NodeURL:     nodeURL,
-			Description: desc,
-			Replies:     it.Replies,
-			Created:     it.CreatedAt.UTC().Format("2006-01-02 15:04"),
+			ID:           it.ID,
+			Title:        it.Title,
+			URL:          it.URL,
+			NodeName:     displayNode,
+			NodeURL:      nodeURL,
+			Description:  desc,
+			WhyItMatters: why,
+			Replies:      it.Replies,
+			Points:       it.Points,
+			Created:      it.CreatedAt.UTC().Format("2006-01-02 15:04"),
+			Author:       author,
+
+			ReadingMinutes: textclean.ReadingMinutes(contentForSum),
+			Highlight:      NewsletterHighlight(highlights, it.ID),
 		})
 	}
+	data.ReadingMinutes = newsletter.TotalReadingMinutes(data.Items)
+	if skippedAI > 0 {
+		slog.Info("builder: skipped AI item summaries for thin content", "channel", w.Channel, "count", skippedAI, "min_runes", w.MinContentRunesForAI)
+	}
+	if backedOff > 0 {
+		slog.Info("builder: skipped AI item summaries for items that keep failing", "channel", w.Channel, "count", backedOff)
+	}
 	// Post-level summary: prefer AI, fallback to heuristic to ensure non-empty
-	raw := make([]model.NewsItem, 0, maxN)
-	for i := 0; i < maxN; i++ {
+	raw := make([]model.NewsItem, 0, len(items))
+	for i := 0; i < len(items); i++ {
 		raw = append(raw, items[i].Item)
 	}
-	if w.Summarizer != nil {
+	if w.Summarizer != nil && !w.NoPostSummary {
 		if s, err := w.Summarizer.SummarizePost(ctxAI, raw, w.Language); err == nil {
 			data.Summary = strings.TrimSpace(s)
 		} else if err != nil {
 			slog.Warn("builder: summarize post failed", "err", err, "channel", w.Channel)
 		}
+	}
+	if w.Summarizer != nil && !w.NoShortSummary {
 		if s, err := w.Summarizer.SummarizePostLikeAZenMaster(ctxAI, raw, w.Language); err == nil {
 			data.ShortSummary = strings.TrimSpace(s)
 		} else if err != nil {
 			slog.Warn("builder: summarize short post failed", "err", err, "channel", w.Channel)
 		}
 	}
-	if strings.TrimSpace(data.Summary) == "" {
-		// Fallback summary built from titles if AI not configured or returned empty
-		titles := make([]string, 0, min(3, len(raw)))
-		for i := 0; i < min(3, len(raw)); i++ {
-			titles = append(titles, raw[i].Title)
+	if w.PullQuote && w.Summarizer != nil {
+		if q, err := w.Summarizer.ExtractQuote(ctxAI, raw, w.Language); err != nil {
+			slog.Warn("builder: extract quote failed", "err", err, "channel", w.Channel)
+		} else {
+			data.Quote, data.QuoteSource = q.Text, newsletter.QuoteSource{Title: q.Item.Title, URL: q.Item.URL}
 		}
-		if len(titles) > 0 {
-			data.Summary = fmt.Sprintf("Top highlights: %s.", strings.Join(titles, ", "))
+	}
+	if w.SEO {
+		if meta, ok := DigestSEO(ctxAI, w.Store, w.Summarizer, w.Channel, period, data.Title, data.Summary, raw, w.Language); ok {
+			data.SEODescription, data.Keywords = meta.Description, meta.Keywords
 		}
 	}
+	// Fallback summaries built from titles if AI is not configured or returned empty
+	if data.Summary == "" && w.Style != newsletter.StyleMinimal && !w.NoPostSummary {
+		data.Summary = newsletter.FallbackSummary(data.Items)
+	}
+	if data.ShortSummary == "" && !w.NoShortSummary {
+		data.ShortSummary = newsletter.FallbackSummary(data.Items)
+	}
 	coverRel := path.Join(slug, "cover.webp")
-	coverPath := filepath.Join(w.OutputDir, w.Channel, slug, "cover.webp")
+	coverPath := filepath.Join(DigestDir(w.OutputDir, w.Channel, w.OutputLayout, now), slug, "cover.webp")
 	coverURL := ""
+	coverPrompt := "" // set when the cover is generated in this run
 	if _, err := os.Stat(coverPath); err == nil {
 		coverURL = coverRel
 		slog.Info("builder: using existing cover image", "channel", w.Channel, "slug", slug, "path", coverPath)
@@ -302,6 +964,7 @@ func (w *NewsletterBuilder) renderMarkdown(period string, items []model.WithScor
 			slog.Warn("builder: cover image generation failed", "err", err, "channel", w.Channel, "slug", slug, "path", coverPath)
 		} else {
 			coverURL = coverRel
+			coverPrompt = prompt
 			slog.Info("builder: cover image generated", "channel", w.Channel, "slug", slug, "path", coverPath)
 		}
 	} else {
@@ -319,35 +982,9 @@ func (w *NewsletterBuilder) renderMarkdown(period string, items []model.WithScor
 	}
 	if coverURL != "" {
 		data.CoverImageURL = coverURL
+		data.CoverImageAlt = CoverAlt(ctxAI, w.Summarizer, coverPath, data.Title, coverPrompt, w.Language)
 	}
-	out, err := newsletter.Render(data)
-	if err != nil {
-		slog.Warn("builder: render template failed", "err", err, "channel", w.Channel, "slug", slug)
-		return ""
-	}
-	if !utf8.ValidString(out) {
-		out = string([]rune(out))
-	}
-	return out
-}
-
-// no local summary fallback; descriptions remain empty when AI is not configured
-
-func filterByNodes(items []model.WithScore, nodes []string) []model.WithScore {
-	if len(nodes) == 0 {
-		return items
-	}
-	set := map[string]struct{}{}
-	for _, n := range nodes {
-		set[strings.TrimSpace(strings.ToLower(n))] = struct{}{}
-	}
-	out := make([]model.WithScore, 0, len(items))
-	for _, it := range items {
-		if _, ok := set[strings.ToLower(it.Item.NodeName)]; ok {
-			out = append(out, it)
-		}
-	}
-	return out
+	return data
 }
 
 func min(a, b int) int {
@@ -357,12 +994,17 @@ func min(a, b int) int {
 	return b
 }
 
-// nodeURLFor returns a source-appropriate URL for a node/category name.
-func nodeURLFor(source, baseURL, node string) string {
+// NodeURL returns a source-appropriate URL for a node/category name; baseURL is
+// the source's site (see NewsletterBuilder.BaseURL). A V2EX node without a site
+// gets no link.
+func NodeURL(source, baseURL, node string) string {
 	source = strings.ToLower(strings.TrimSpace(source))
 	base := strings.TrimRight(baseURL, "/")
 	switch source {
 	case "v2ex":
+		if base == "" {
+			return ""
+		}
 		return base + "/go/" + node
 	case "hackernews":
 		// Map HN types to list pages for convenience.
@@ -372,39 +1014,31 @@ func nodeURLFor(source, baseURL, node string) string {
 			return base + "/ask"
 		case "show":
 			return base + "/show"
+		case "tell":
+			return base + "/ask"
+		case "launch":
+			return base + "/launches"
 		case "job", "jobs":
 			return base + "/jobs"
 		default:
 			return base + "/news"
 		}
+	case "lobsters":
+		return base + "/t/" + node
+	case "reddit":
+		return base + "/r/" + node
+	case "github":
+		return base + "/trending/" + strings.ToLower(node)
+	case "producthunt":
+		return base + "/topics/" + strings.ToLower(node)
+	case "arxiv":
+		return base + "/list/" + node + "/new"
+	case "mastodon":
+		// Nodes are instance hosts; link the instance's trending links.
+		return "https://" + node + "/explore/links"
+	case "stackoverflow":
+		return base + "/questions/tagged/" + url.PathEscape(node)
 	default:
 		return base
 	}
 }
-
-// filterHNTypes filters only when nodes include known HN item types; otherwise returns input unmodified.
-func filterHNTypes(items []model.WithScore, nodes []string) []model.WithScore {
-	if len(nodes) == 0 {
-		return items
-	}
-	// Determine which types are specified in nodes
-	allowed := map[string]struct{}{}
-	for _, n := range nodes {
-		s := strings.ToLower(strings.TrimSpace(n))
-		switch s {
-		case "ask", "show", "job", "story":
-			allowed[s] = struct{}{}
-		}
-	}
-	if len(allowed) == 0 {
-		// nodes likely specify lists (top/new/best/ask/show/job); do not filter here
-		return items
-	}
-	out := make([]model.WithScore, 0, len(items))
-	for _, it := range items {
-		if _, ok := allowed[strings.ToLower(it.Item.NodeName)]; ok {
-			out = append(out, it)
-		}
-	}
-	return out
-}
@@ -0,0 +1,413 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/notify"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/selection"
+)
+
+func TestBuildDataFallbackSummaryWithoutAI(t *testing.T) {
+	w := &NewsletterBuilder{
+		Store:     newDeliveryTestStore(t),
+		Source:    "v2ex",
+		Channel:   "ch",
+		Frequency: "daily",
+		TopN:      5,
+		OutputDir: t.TempDir(),
+	}
+	items := []model.WithScore{
+		{Item: model.NewsItem{ID: "1", Title: "First", NodeName: "go", CreatedAt: time.Now()}},
+		{Item: model.NewsItem{ID: "2", Title: "Second", NodeName: "go", CreatedAt: time.Now()}},
+	}
+	data := w.buildData(context.Background(), "2025-10-24", time.Now(), items)
+	if data.Summary != "Top highlights: First, Second." || data.ShortSummary != data.Summary {
+		t.Errorf("summary = %q, short summary = %q", data.Summary, data.ShortSummary)
+	}
+}
+
+type recordingNotifier struct{ events []notify.Event }
+
+func (n *recordingNotifier) Notify(ctx context.Context, ev notify.Event) error {
+	n.events = append(n.events, ev)
+	return nil
+}
+
+// insufficientBuilder returns a builder whose previous (daily) period holds n items,
+// below its MinItems of 5.
+func insufficientBuilder(t *testing.T, n int, policy string) (*NewsletterBuilder, *recordingNotifier, string) {
+	t.Helper()
+	store := newDeliveryTestStore(t)
+	prev := time.Now().AddDate(0, 0, -1)
+	key := period.Key(period.Daily, prev)
+	for i := 0; i < n; i++ {
+		it := model.NewsItem{ID: fmt.Sprint(i + 1), Title: fmt.Sprintf("Item %d", i+1), NodeName: "go", Replies: 3, CreatedAt: prev}
+		if err := store.AddNews(context.Background(), "v2ex", key, it, float64(10-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec := &recordingNotifier{}
+	return &NewsletterBuilder{
+		Store:               store,
+		Source:              "v2ex",
+		Channel:             "ch",
+		Frequency:           "daily",
+		TopN:                10,
+		MinItems:            5,
+		OutputDir:           t.TempDir(),
+		OnInsufficientItems: policy,
+		Notifier:            rec,
+	}, rec, key
+}
+
+func TestClosePreviousPeriodSkipsInsufficientItems(t *testing.T) {
+	ctx := context.Background()
+	w, rec, period := insufficientBuilder(t, 4, "")
+	for i := 0; i < 2; i++ { // the second tick must not report again
+		res, err := w.RunOnce(ctx)
+		if err != nil || res.Candidates != 0 { // the current period is empty
+
+			t.Fatalf("RunOnce = %+v, %v", res, err)
+		}
+	}
+	meta, ok, err := w.Store.GetPublishMeta(ctx, "ch", period)
+	if err != nil || !ok || meta.Skipped != "insufficient items (4/5)" || meta.SkippedAt == nil {
+		t.Fatalf("publish meta = %+v, %v, %v", meta, ok, err)
+	}
+	if len(rec.events) != 1 || rec.events[0].Kind != notify.KindDigestSkipped || !strings.Contains(rec.events[0].Message, "(4/5)") {
+		t.Errorf("events = %+v", rec.events)
+	}
+	if published, _ := w.Store.IsPublished(ctx, "ch", period); published {
+		t.Error("skipped period marked published")
+	}
+}
+
+func TestClosePreviousPeriodPublishesLightEdition(t *testing.T) {
+	ctx := context.Background()
+	w, rec, period := insufficientBuilder(t, 4, InsufficientPublish)
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if published, _ := w.Store.IsPublished(ctx, "ch", period); !published {
+		t.Fatal("previous period not published")
+	}
+	meta, ok, _ := w.Store.GetPublishMeta(ctx, "ch", period)
+	if !ok || meta.Slug != "daily-"+strings.ReplaceAll(period, "-", "") {
+		t.Fatalf("publish meta = %+v", meta)
+	}
+	b, err := os.ReadFile(meta.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), LightEditionMarker) {
+		t.Errorf("digest title lacks the light edition marker:\n%s", b)
+	}
+	if len(rec.events) != 0 {
+		t.Errorf("events = %+v", rec.events)
+	}
+}
+
+// A minimal channel needs nothing but Redis: with no summarizer, scraper, or cover
+// generator it still closes its period with a publishable links-only digest.
+func TestMinimalStylePublishesWithoutAI(t *testing.T) {
+	ctx := context.Background()
+	w, _, period := insufficientBuilder(t, 5, "")
+	w.Style = newsletter.StyleMinimal
+	w.Preface = "Today's links."
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	meta, ok, _ := w.Store.GetPublishMeta(ctx, "ch", period)
+	if !ok {
+		t.Fatal("previous period not published")
+	}
+	b, err := os.ReadFile(meta.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, want := range []string{`summary: "Top highlights: Item 1, Item 2, Item 3."`, "\nToday's links.\n\n1. [Item 1]", "\n5. [Item 5]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("digest lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "Top highlights") != 1 || strings.Contains(out, "## ") {
+		t.Errorf("minimal digest has a body summary or full item sections:\n%s", out)
+	}
+}
+
+// The first digest of a channel has no previous one to link; the next day's links
+// back to it on the Quaily site.
+func TestNavigationLinksPreviousDigest(t *testing.T) {
+	ctx := context.Background()
+	w, _, yesterday := insufficientBuilder(t, 5, "")
+	w.SiteBaseURL = "https://quaily.com"
+	today := period.Key(period.Daily, time.Now())
+	for i := 0; i < 5; i++ {
+		it := model.NewsItem{ID: fmt.Sprint(i + 11), Title: fmt.Sprintf("New %d", i+1), NodeName: "go", Replies: 3, CreatedAt: time.Now()}
+		if err := w.Store.AddNews(ctx, "v2ex", today, it, float64(10-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	first, _, _ := w.Store.GetPublishMeta(ctx, "ch", yesterday)
+	if b, err := os.ReadFile(first.Path); err != nil || strings.Contains(string(b), "digest](") {
+		t.Errorf("first digest links a neighbour (%v):\n%s", err, b)
+	}
+	second, _, _ := w.Store.GetPublishMeta(ctx, "ch", today)
+	b, err := os.ReadFile(second.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[← Yesterday's digest](https://quaily.com/ch/p/" + first.Slug + ")\n"; !strings.HasSuffix(string(b), want) {
+		t.Errorf("second digest does not end with %q:\n%s", want, b)
+	}
+	if first.Title == "" || !strings.HasPrefix(first.Title, "Digest of ch") {
+		t.Errorf("publish meta title = %q", first.Title)
+	}
+}
+
+func TestClosePreviousPeriodIgnoresEmptyPeriod(t *testing.T) {
+	ctx := context.Background()
+	w, rec, period := insufficientBuilder(t, 0, "")
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := w.Store.GetPublishMeta(ctx, "ch", period); ok || len(rec.events) != 0 {
+		t.Errorf("empty period recorded (meta %v) or reported (%+v)", ok, rec.events)
+	}
+}
+
+func TestCheckOnInsufficientItems(t *testing.T) {
+	for _, v := range []string{"", "skip", "publish"} {
+		if err := CheckOnInsufficientItems(v); err != nil {
+			t.Errorf("%q: %v", v, err)
+		}
+	}
+	if err := CheckOnInsufficientItems("partial"); err == nil {
+		t.Error("partial accepted")
+	}
+}
+
+// deepStore holds 80 high-scored items of node "other" above 10 items of node "go".
+func deepStore(t *testing.T, period string) *NewsletterBuilder {
+	t.Helper()
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	for i := 0; i < 90; i++ {
+		node, score := "other", float64(1000-i)
+		if i >= 80 {
+			node = "go"
+		}
+		it := model.NewsItem{ID: fmt.Sprint(i), Title: fmt.Sprintf("Item %d", i), NodeName: node, Replies: 2, CreatedAt: time.Now()}
+		if err := store.AddNews(ctx, "v2ex", period, it, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", TopN: 5}
+}
+
+func TestCandidatesFetchDeeperForFilteredChannel(t *testing.T) {
+	w := deepStore(t, "2025-10-24")
+	w.Nodes = []string{"go"}
+	items, depth, err := w.candidates(context.Background(), "2025-10-24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10, 20, 40, 80, then 160 reaches the "go" items at rank 80+ and exhausts the period.
+	if len(items) != 10 || depth != 90 || items[0].Item.ID != "80" {
+		t.Errorf("got %d items (first %v) at depth %d", len(items), itemIDs(items[:1]), depth)
+	}
+
+	w.MaxFetchDepth = 40
+	if items, depth, _ := w.candidates(context.Background(), "2025-10-24"); len(items) != 0 || depth != 40 {
+		t.Errorf("capped: got %d items at depth %d", len(items), depth)
+	}
+}
+
+// offTopic rates items 0 through 6 irrelevant and every other item relevant.
+type offTopic struct{ ai.Summarizer }
+
+func (offTopic) ScoreRelevance(_ context.Context, title, _, _, _ string) (float64, error) {
+	var n int
+	fmt.Sscanf(title, "Item %d", &n)
+	if n < 7 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// Items the quality gate rejects are replaced from deeper ranks.
+func TestCandidatesReadPastQualityGate(t *testing.T) {
+	w := deepStore(t, "2025-10-24")
+	w.QualityGate = &selection.QualityGate{Summarizer: offTopic{}, Store: w.Store, Channel: "ch", Threshold: 0.5}
+	items, depth, err := w.candidates(context.Background(), "2025-10-24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first 10 leave 3 past the gate, so the next batch of 10 is read.
+	if got := itemIDs(items); depth != 20 || strings.Join(got, ",") != "7,8,9,10,11" {
+		t.Errorf("got %v at depth %d", got, depth)
+	}
+}
+
+func TestCandidatesStopEarlyWithoutFilters(t *testing.T) {
+	w := deepStore(t, "2025-10-24")
+	ctx := context.Background()
+	if err := w.Store.MarkSkipped(ctx, "ch", "0", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	items, depth, err := w.candidates(ctx, "2025-10-24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth != 10 || len(items) != 9 || items[0].Item.ID != "1" {
+		t.Errorf("got %d items (first %v) at depth %d", len(items), itemIDs(items[:1]), depth)
+	}
+}
+
+func TestRenderFailureLeavesStateUntouched(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t)
+	key := period.Key(period.Daily, time.Now())
+	stored, err := store.TopNews(ctx, "v2ex", key, 100)
+	if err != nil || len(stored) == 0 {
+		t.Fatalf("seeded items = %d, %v", len(stored), err)
+	}
+	notifier := &recordingNotifier{}
+	out := t.TempDir()
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: out,
+		SkipDuration: time.Hour, Notifier: notifier,
+		render: func(newsletter.Data, []string) ([]newsletter.Output, error) {
+			return nil, fmt.Errorf(`template: newsletter:12: can't evaluate field Missing`)
+		}}
+
+	if _, err := w.RunOnce(ctx); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("RunOnce err = %v, want the render error", err)
+	}
+	if entries, _ := os.ReadDir(out); len(entries) != 0 {
+		t.Errorf("output dir has %d entries, want none", len(entries))
+	}
+	if ok, _ := store.IsPublished(ctx, "ch", key); ok {
+		t.Error("period marked published")
+	}
+	if _, ok, _ := store.GetPublishMeta(ctx, "ch", key); ok {
+		t.Error("publish metadata written")
+	}
+	ids := make([]string, len(stored))
+	for i, ws := range stored {
+		ids[i] = ws.Item.ID
+		if skipped, _ := store.IsSkipped(ctx, "ch", ws.Item.ID); skipped {
+			t.Errorf("item %s marked skipped", ws.Item.ID)
+		}
+	}
+	if seen, _ := store.Appearances(ctx, "ch", ids); len(seen) != 0 {
+		t.Errorf("appearances recorded: %v", seen)
+	}
+	if st, _, _ := store.GetWorkerStatus(ctx, w.Name()); !strings.Contains(st.LastError, "render") {
+		t.Errorf("status last error = %q", st.LastError)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].Kind != notify.KindRenderFailed {
+		t.Errorf("notifications = %+v", notifier.events)
+	}
+}
+
+func TestSkipMarksExactlyTheRenderedItems(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t)
+	key := period.Key(period.Daily, time.Now())
+	// Drops the second item, which a count of rendered items would still have
+	// marked skipped in place of the last one.
+	dropSecond := func(d newsletter.Data) ([]newsletter.Data, error) {
+		if len(d.Items) > 1 {
+			d.Items = append(d.Items[:1:1], d.Items[2:]...)
+		}
+		return []newsletter.Data{d}, nil
+	}
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1,
+		OutputDir: t.TempDir(), SkipDuration: time.Hour, fit: dropSecond}
+	candidates, _, err := w.candidates(ctx, key)
+	if err != nil || len(candidates) < 3 {
+		t.Fatalf("candidates = %d, %v; want at least 3", len(candidates), err)
+	}
+	selected := w.selectItems(candidates, 0)
+
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{selected[0].Item.ID, selected[2].Item.ID}
+	meta, ok, err := store.GetPublishMeta(ctx, "ch", key)
+	if err != nil || !ok || strings.Join(meta.ItemIDs, ",") != strings.Join(want, ",") {
+		t.Errorf("publish meta item IDs = %v (%v, %v), want %v", meta.ItemIDs, ok, err, want)
+	}
+	for i, ws := range selected {
+		skipped, _ := store.IsSkipped(ctx, "ch", ws.Item.ID)
+		if skipped != (i != 1) {
+			t.Errorf("item %d (%s) skipped = %v", i, ws.Item.ID, skipped)
+		}
+	}
+	if seen, _ := store.Appearances(ctx, "ch", []string{selected[1].Item.ID}); len(seen) != 0 {
+		t.Errorf("dropped item recorded as appearing: %v", seen)
+	}
+}
+
+// countingSummarizer counts the post-level summary calls.
+type countingSummarizer struct {
+	ai.Summarizer
+	post, short int
+}
+
+func (s *countingSummarizer) SummarizeItem(ctx context.Context, title, content, language string) (string, error) {
+	return "About " + title + ".", nil
+}
+
+func (s *countingSummarizer) SummarizePost(ctx context.Context, items []model.NewsItem, language string) (string, error) {
+	s.post++
+	return "The long summary.", nil
+}
+
+func (s *countingSummarizer) SummarizePostLikeAZenMaster(ctx context.Context, items []model.NewsItem, language string) (string, error) {
+	s.short++
+	return "The teaser.", nil
+}
+
+func TestSummaryToggles(t *testing.T) {
+	for _, tc := range []struct {
+		noPost, noShort bool
+	}{{false, false}, {true, false}, {false, true}, {true, true}} {
+		ctx := context.Background()
+		w, _, period := insufficientBuilder(t, 5, "")
+		s := &countingSummarizer{}
+		w.Summarizer, w.NoPostSummary, w.NoShortSummary = s, tc.noPost, tc.noShort
+		if _, err := w.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[bool]int{false: 1, true: 0}; s.post != want[tc.noPost] || s.short != want[tc.noShort] {
+			t.Errorf("%+v: SummarizePost called %d times, SummarizePostLikeAZenMaster %d times", tc, s.post, s.short)
+		}
+		meta, _, _ := w.Store.GetPublishMeta(ctx, "ch", period)
+		b, err := os.ReadFile(meta.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(b)
+		// A disabled summary leaves neither the AI text nor the title fallback behind.
+		if got := strings.Contains(out, "The long summary.") || strings.Contains(out, "\nTop highlights:"); got == tc.noPost {
+			t.Errorf("%+v: post summary rendered = %v:\n%s", tc, got, out)
+		}
+		if got := strings.Contains(out, "summary:"); got == tc.noShort {
+			t.Errorf("%+v: short summary rendered = %v:\n%s", tc, got, out)
+		}
+	}
+}
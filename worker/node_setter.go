@@ -0,0 +1,37 @@
+package worker
+
+import "sync"
+
+// NodeSetter is a collector whose polled units (nodes, lists, subreddits, feeds,
+// instances, ...) can be replaced while it runs, as serve does on SIGHUP. The
+// change applies at the next run; SetNodes returns the units not polled before.
+type NodeSetter[T any] interface {
+	SetNodes(units []T) (added []string)
+}
+
+// setUnits replaces *units with next under mu and returns the keys of next that
+// were not among the old units.
+func setUnits[T any](mu *sync.Mutex, units *[]T, next []T, key func(T) string) []string {
+	mu.Lock()
+	defer mu.Unlock()
+	added := newEntries(unitKeys(*units, key), unitKeys(next, key))
+	*units = append([]T(nil), next...)
+	return added
+}
+
+// currentUnits returns a copy of *units taken under mu, for one run to work on.
+func currentUnits[T any](mu *sync.Mutex, units *[]T) []T {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]T(nil), *units...)
+}
+
+func unitKeys[T any](units []T, key func(T) string) []string {
+	keys := make([]string, len(units))
+	for i, u := range units {
+		keys[i] = key(u)
+	}
+	return keys
+}
+
+func stringKey(s string) string { return s }
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/storage"
+)
+
+// SetNodes reports the units not polled before, and the next run polls the new units
+// only: a collector started on a unit without a fixture fails until it is replaced.
+func TestSetNodesAppliesAtNextRun(t *testing.T) {
+	dir := filepath.Join("..", "fixtures")
+	type collector interface {
+		RunOnce(ctx context.Context) (CollectResult, error)
+	}
+	for _, tc := range []struct {
+		name      string
+		c         func(store *storage.RedisStore) collector
+		set       func(c collector) []string
+		wantAdded []string
+	}{
+		{"reddit", func(store *storage.RedisStore) collector {
+			return &RedditCollector{Client: mocksource.NewReddit(dir), Store: store, Subreddits: []string{"gone"}}
+		}, func(c collector) []string { return c.(NodeSetter[string]).SetNodes([]string{"golang"}) }, []string{"golang"}},
+		{"arxiv", func(store *storage.RedisStore) collector {
+			return &ArxivCollector{Client: mocksource.NewArxiv(dir), Store: store, Categories: []string{"cs.CL", "gone"}, RequestGap: -1}
+		}, func(c collector) []string { return c.(NodeSetter[string]).SetNodes([]string{"cs.CL"}) }, nil},
+		{"mastodon", func(store *storage.RedisStore) collector {
+			return &MastodonCollector{Store: store, Instances: []MastodonSource{mocksource.NewMastodon(dir, "gone.example")}}
+		}, func(c collector) []string {
+			return c.(NodeSetter[MastodonSource]).SetNodes([]MastodonSource{mocksource.NewMastodon(dir, "mastodon.social")})
+		}, []string{"mastodon.social"}},
+		{"bluesky", func(store *storage.RedisStore) collector {
+			return &BlueskyCollector{Client: mocksource.NewBluesky(dir), Store: store, Feeds: []BlueskyFeed{{Node: "gone", Query: "gone"}}}
+		}, func(c collector) []string {
+			return c.(NodeSetter[BlueskyFeed]).SetNodes([]BlueskyFeed{{Node: "golang", Query: "golang"}})
+		}, []string{"golang"}},
+		{"youtube", func(store *storage.RedisStore) collector {
+			return &YouTubeCollector{Client: mocksource.NewYouTube(dir), Store: store, Feeds: []YouTubeFeed{{PlaylistID: "PLgone"}}}
+		}, func(c collector) []string {
+			return c.(NodeSetter[YouTubeFeed]).SetNodes([]YouTubeFeed{{ChannelID: "UCgo", Label: "golang"}})
+		}, []string{"channel UCgo"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := tc.c(newDeliveryTestStore(t))
+			if res, err := c.RunOnce(ctx); err == nil || res.Failed == 0 {
+				t.Fatalf("first run = %+v, %v; want the missing unit failed", res, err)
+			}
+			if added := tc.set(c); !slices.Equal(added, tc.wantAdded) {
+				t.Errorf("SetNodes added %q, want %q", added, tc.wantAdded)
+			}
+			if res, err := c.RunOnce(ctx); err != nil || res.Failed != 0 || res.Fetched == 0 {
+				t.Errorf("run after SetNodes = %+v, %v; want only the new units polled", res, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+)
+
+func TestPinnedItemLeadsDigest(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t)
+	key := period.Key(period.Daily, time.Now())
+	stored, err := store.TopNews(ctx, "v2ex", key, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pin the lowest-ranked item outside the channel's nodes.
+	var pinID, pinTitle string
+	for _, ws := range stored {
+		if ws.Item.NodeName != "crypto" {
+			pinID, pinTitle = ws.Item.ID, ws.Item.Title
+		}
+	}
+	if pinID == "" {
+		t.Fatal("no seeded item outside the crypto node")
+	}
+	if err := store.PinItem(ctx, "ch", pinID); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", Nodes: []string{"crypto"},
+		TopN: 2, MinItems: 1, OutputDir: t.TempDir(), SkipDuration: time.Hour, PinLabel: "Editor's pick"}
+	res, err := w.buildPeriod(ctx, key, time.Now(), time.Now(), false)
+	if err != nil || res.Path == "" {
+		t.Fatalf("buildPeriod = %+v, %v", res, err)
+	}
+	meta, _, _ := store.GetPublishMeta(ctx, "ch", key)
+	if len(meta.ItemIDs) != 2 || meta.ItemIDs[0] != pinID {
+		t.Errorf("item IDs = %v, want %s first and 2 in all", meta.ItemIDs, pinID)
+	}
+	b, err := os.ReadFile(res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(b)
+	if i := strings.Index(body, "## ["); i < 0 || !strings.HasPrefix(body[i:], "## ["+pinTitle+"]") {
+		t.Errorf("pinned item is not the first item:\n%s", body)
+	}
+	if strings.Count(body, "*Editor's pick*") != 1 {
+		t.Errorf("want one pin label:\n%s", body)
+	}
+	if ids, _ := store.PinnedIDs(ctx, "ch"); len(ids) != 0 {
+		t.Errorf("pins left after publishing: %v", ids)
+	}
+}
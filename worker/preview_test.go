@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+// fakeQuaily accepts every call and records them as "METHOD path".
+func fakeQuaily(t *testing.T) (*quaily.Client, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(`{"data":{"id":1}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return quaily.New(srv.URL, "k", 0), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+}
+
+func TestPreviewModeRedirectsQuailyPublishing(t *testing.T) {
+	ctx := context.Background()
+	// The cutover follows the builder's clock: "edge" ends a nanosecond after it, long
+	// before the run is over by the wall clock.
+	now := time.Now()
+	for _, tc := range []struct {
+		name  string
+		until time.Time
+		want  string // Quaily channel slug
+		task  string // the delivery's QuailyChannel
+	}{
+		{"active", now.Add(time.Hour), "staging", "staging"},
+		{"edge", now.Add(time.Nanosecond), "staging", "staging"},
+		{"expired", now.Add(-time.Minute), "ch", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := seed(t)
+			qc, calls := fakeQuaily(t)
+			w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: t.TempDir(),
+				Quaily: qc, PreviewChannel: "staging", PreviewUntil: tc.until, Now: func() time.Time { return now }}
+			res, err := w.RunOnce(ctx)
+			if err != nil || res.Path == "" || res.PublishError != "" {
+				t.Fatalf("RunOnce = %+v, %v", res, err)
+			}
+			if joined := strings.Join(calls(), "\n"); !strings.Contains(joined, "POST /lists/"+tc.want+"/posts") {
+				t.Errorf("calls:\n%s\nwant a post created in %s", joined, tc.want)
+			}
+			key := period.Key(period.Daily, now)
+			if ok, _ := store.IsPublished(ctx, "ch", key); !ok {
+				t.Error("period not marked published under the channel")
+			}
+			meta, _, _ := store.GetPublishMeta(ctx, "ch", key)
+			if !meta.WrittenAt.Equal(now.UTC()) {
+				t.Errorf("publish meta written at %v, want the builder's clock %v", meta.WrittenAt, now.UTC())
+			}
+			if got := meta.QuailySlug("ch"); got != tc.want {
+				t.Errorf("publish meta Quaily channel = %q, want %q", got, tc.want)
+			}
+			tasks, _ := store.Deliveries(ctx, "ch")
+			if len(tasks) != 1 || tasks[0].Target != storage.TargetQuaily {
+				t.Fatalf("deliveries = %+v", tasks)
+			}
+			if got := tasks[0].QuailyChannel; got != tc.task {
+				t.Errorf("delivery Quaily channel = %q, want %q", got, tc.task)
+			}
+		})
+	}
+}
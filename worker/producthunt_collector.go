@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/producthunt"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+)
+
+// productHuntDay is how far back a launch may go to count as one of the day's.
+const productHuntDay = 24 * time.Hour
+
+// ProductHuntCollector polls the launches of the last day, most voted first, and
+// stores them into period ZSETs. Launches are stored under their topics (joined
+// like Lobste.rs tags); channels pick topics at build time.
+type ProductHuntCollector struct {
+	Client   ProductHuntSource
+	Store    *storage.RedisStore
+	Interval time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores launches; zero fields use ranking.ForSource("producthunt"), the
+	// Hacker News formula on votes.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each launch's GraphQL JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	buffer storeBuffer
+}
+
+func (w *ProductHuntCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, productHuntCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// productHuntCollectorName identifies the collector's persisted status record.
+const productHuntCollectorName = "producthunt-collector"
+
+func (w *ProductHuntCollector) Name() string { return productHuntCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error.
+func (w *ProductHuntCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, productHuntCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, productHuntCollectorName, producthunt.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, productHuntCollectorName, started, err)
+	countCollected(ctx, w.Store, producthunt.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *ProductHuntCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	loop := collectLoop{source: producthunt.Source, unit: "list", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now}
+	return loop.run(ctx, []string{"today"}, func(ctx context.Context, _ int) ([]model.NewsItem, error) {
+		return w.Client.Posts(ctx, now.Add(-productHuntDay))
+	})
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/producthunt"
+)
+
+// The day's launches are asked for and stored under their topics, which channels
+// pick like Lobste.rs tags.
+func TestProductHuntCollectorStoresLaunchesByTopic(t *testing.T) {
+	now := time.Now()
+	created := now.UTC().Add(-3 * time.Hour).Format(time.RFC3339)
+	var postedAfter any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		postedAfter = req.Variables["postedAfter"]
+		fmt.Fprintf(w, `{"data": {"posts": {"edges": [
+			{"node": {"id": "1", "name": "Inkwell", "url": "https://www.producthunt.com/posts/inkwell", "votesCount": 300, "commentsCount": 0, "createdAt": %[1]q, "topics": {"edges": [{"node": {"slug": "developer-tools"}}, {"node": {"slug": "productivity"}}]}}},
+			{"node": {"id": "2", "name": "Lumen", "url": "https://www.producthunt.com/posts/lumen", "votesCount": 90, "commentsCount": 3, "createdAt": %[1]q, "topics": {"edges": [{"node": {"slug": "email"}}]}}}
+		]}}}`, created)
+	}))
+	defer srv.Close()
+
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &ProductHuntCollector{Client: producthunt.NewClient(srv.URL, "tok"), Store: store, Now: func() time.Time { return now }}
+	if res, err := c.RunOnce(ctx); err != nil || res.Stored != 2 {
+		t.Fatalf("RunOnce = %+v, %v", res, err)
+	}
+	if want := now.Add(-24 * time.Hour).UTC().Format(time.RFC3339); postedAfter != want {
+		t.Errorf("postedAfter = %v, want %s", postedAfter, want)
+	}
+	got, err := store.TopNews(ctx, "producthunt", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("TopNews = %v, %v", itemIDs(got), err)
+	}
+	b := &NewsletterBuilder{Store: store, Source: "producthunt", Nodes: []string{"Productivity"}}
+	if kept := itemIDs(b.rank(ctx, got)); !slices.Equal(kept, []string{"1"}) {
+		t.Errorf("channel on productivity kept %v", kept)
+	}
+	if u := NodeURL("producthunt", producthunt.SiteURL, "developer-tools"); u != "https://www.producthunt.com/topics/developer-tools" {
+		t.Errorf("node URL = %q", u)
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/period"
+)
+
+// Two instances racing on the same period publish it once: the second waits for
+// the first's publish lock, then finds the period published and renders nothing.
+func TestPublishLockSerializesInstances(t *testing.T) {
+	poll := publishLockPoll
+	publishLockPoll = 5 * time.Millisecond
+	t.Cleanup(func() { publishLockPoll = poll })
+
+	ctx := context.Background()
+	store := seed(t)
+	qc, calls := fakeQuaily(t)
+	rendering, proceed := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	first := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: t.TempDir(), Quaily: qc,
+		render: func(d newsletter.Data, formats []string) ([]newsletter.Output, error) {
+			once.Do(func() { close(rendering) })
+			<-proceed
+			return newsletter.RenderAll(d, formats)
+		}}
+	var renders atomic.Int32
+	secondDir := t.TempDir()
+	second := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: secondDir, Quaily: qc,
+		render: func(d newsletter.Data, formats []string) ([]newsletter.Output, error) {
+			renders.Add(1)
+			return newsletter.RenderAll(d, formats)
+		}}
+
+	type outcome struct {
+		res BuildResult
+		err error
+	}
+	firstDone, secondDone := make(chan outcome, 1), make(chan outcome, 1)
+	go func() {
+		res, err := first.RunOnce(ctx)
+		firstDone <- outcome{res, err}
+	}()
+	<-rendering
+	go func() {
+		res, err := second.RunOnce(ctx)
+		secondDone <- outcome{res, err}
+	}()
+	// Let the first instance go on once the second is waiting for the lock.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, err := store.SumCounter(ctx, CounterPublishLockContended, time.Now(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second instance never waited for the publish lock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(proceed)
+
+	a, b := <-firstDone, <-secondDone
+	if a.err != nil || a.res.Skipped != "" || a.res.Path == "" || a.res.PublishError != "" {
+		t.Errorf("first = %+v, %v; want it published", a.res, a.err)
+	}
+	if b.err != nil || b.res.Skipped != BuildAlreadyPublished || b.res.Path != "" {
+		t.Errorf("second = %+v, %v; want %s", b.res, b.err, BuildAlreadyPublished)
+	}
+	if n := renders.Load(); n != 0 {
+		t.Errorf("second instance rendered %d times", n)
+	}
+	if entries, _ := os.ReadDir(secondDir); len(entries) != 0 {
+		t.Errorf("second instance wrote %d entries", len(entries))
+	}
+	posts := 0
+	for _, c := range calls() {
+		if strings.HasPrefix(c, "POST /lists/ch/posts") {
+			posts++
+		}
+	}
+	if posts != 1 {
+		t.Errorf("Quaily posts = %d, want 1; calls %v", posts, calls())
+	}
+	// The lock is released for the next period's run.
+	if _, ok, err := store.AcquireLock(ctx, "publish:ch:"+period.Key(period.Daily, time.Now()), time.Minute); err != nil || !ok {
+		t.Errorf("publish lock still held after both runs: %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+// PushMarkdownFile publishes the Markdown file at path to a Quaily channel, unless
+// the post last pushed there under the file's slug had the same content hash
+// (quaily.FileHash); then it logs and reports skipped. force publishes regardless.
+// After a successful publish the new hash is recorded. Hash lookups are
+// best-effort: when the store fails, the file is published.
+func PushMarkdownFile(ctx context.Context, store *storage.RedisStore, qc *quaily.Client, path, quailyChannel string, force bool) (hash string, skipped bool, err error) {
+	doc, err := markdown.ParseFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	slug, _ := doc.Frontmatter["slug"].(string)
+	slug = strings.TrimSpace(slug)
+	hash, err = quaily.FileHash(qc, path)
+	if err != nil {
+		return "", false, err
+	}
+	if slug != "" && !force {
+		prev, err := store.GetQuailyHash(ctx, quailyChannel, slug)
+		if err != nil {
+			slog.Warn("quaily: read content hash failed; publishing", "err", err, "quaily_channel", quailyChannel, "slug", slug)
+		} else if prev == hash {
+			slog.Info("quaily: content identical, skipping update", "quaily_channel", quailyChannel, "slug", slug, "path", path)
+			return hash, true, nil
+		}
+	}
+	if err := quaily.PublishMarkdownFile(ctx, qc, path, quailyChannel); err != nil {
+		return hash, false, err
+	}
+	if slug != "" {
+		if err := store.SetQuailyHash(ctx, quailyChannel, slug, hash); err != nil {
+			slog.Warn("quaily: save content hash failed", "err", err, "quaily_channel", quailyChannel, "slug", slug)
+		}
+	}
+	return hash, false, nil
+}
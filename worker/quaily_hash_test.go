@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPushMarkdownFileSkipsIdenticalContent(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	qc, calls := fakeQuaily(t)
+	path := filepath.Join(t.TempDir(), "daily-20251024.md")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("---\ntitle: T\nslug: daily-20251024\n---\n"+body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	push := func(force bool) bool {
+		t.Helper()
+		_, skipped, err := PushMarkdownFile(ctx, store, qc, path, "ch", force)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return skipped
+	}
+
+	write("Body.")
+	if push(false) {
+		t.Fatal("first push skipped")
+	}
+	n := len(calls())
+	if !push(false) || len(calls()) != n {
+		t.Errorf("identical push was sent; calls %d -> %d", n, len(calls()))
+	}
+	if push(true) || len(calls()) == n {
+		t.Error("--force push was skipped")
+	}
+	n = len(calls())
+	write("Body, edited.")
+	if push(false) || len(calls()) == n {
+		t.Error("changed content was skipped")
+	}
+	if h, _ := store.GetQuailyHash(ctx, "ch", "daily-20251024"); h == "" {
+		t.Error("content hash not recorded")
+	}
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultQuailyReconcileDays is how many days back QuailyReconciler checks by default.
+const DefaultQuailyReconcileDays = 3
+
+// Actions of a QuailyReconcileResult.
+const (
+	ReconcilePush    = "push"    // the post is missing on Quaily; the markdown file is published again
+	ReconcilePublish = "publish" // the post exists as a draft; it is published
+	ReconcileRecord  = "record"  // the post is already live; only the publish metadata is updated
+)
+
+// ReconcileChannel is a channel whose recent digests QuailyReconciler checks.
+type ReconcileChannel struct {
+	Name      string
+	Frequency string         // daily or weekly, for the period keys
+	Quaily    *quaily.Client // the client of the channel's quaily_profile; nil skips the channel
+}
+
+// QuailyReconcileResult reports one digest that was written but not recorded as pushed to Quaily.
+type QuailyReconcileResult struct {
+	Channel string `json:"channel"`
+	Period  string `json:"period"`
+	Slug    string `json:"slug"`
+	Path    string `json:"path"` // markdown file
+	Action  string `json:"action,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// QuailyReconciler finds recent digests whose Quaily push failed and pushes them.
+// The builder writes the digest and its publish metadata before publishing, so a
+// record without QuailyPublishedAt is a digest that may never have reached Quaily;
+// the post is looked up by slug before anything is published again.
+type QuailyReconciler struct {
+	Store    *storage.RedisStore
+	Channels []ReconcileChannel
+	Days     int  // how many days back to check, including today; 0 uses DefaultQuailyReconcileDays
+	DryRun   bool // report what would be done without changing Quaily or the store
+	Now      func() time.Time
+}
+
+// Run checks every channel and returns the digests that needed attention, oldest
+// period first per channel. Per-digest failures are reported in the results; the
+// error is set only when the store cannot be read.
+func (r *QuailyReconciler) Run(ctx context.Context) ([]QuailyReconcileResult, error) {
+	configured := false
+	for _, ch := range r.Channels {
+		configured = configured || ch.Quaily != nil
+	}
+	if !configured {
+		return nil, errors.New("quaily is not configured")
+	}
+	var out []QuailyReconcileResult
+	for _, ch := range r.Channels {
+		if ch.Quaily == nil {
+			continue
+		}
+		for _, period := range r.periods(ch.Frequency) {
+			meta, ok, err := r.Store.GetPublishMeta(ctx, ch.Name, period)
+			if err != nil {
+				return out, fmt.Errorf("read publish metadata of %s %s: %w", ch.Name, period, err)
+			}
+			if !ok || meta.QuailyPublishedAt != nil || meta.Skipped != "" {
+				continue
+			}
+			res := r.reconcile(ctx, ch.Quaily, ch.Name, period, meta)
+			if res.Error != "" {
+				slog.Warn("quaily reconcile: failed", "channel", ch.Name, "period", period, "slug", meta.Slug, "err", res.Error)
+			} else if !r.DryRun {
+				slog.Info("quaily reconcile: done", "channel", ch.Name, "period", period, "slug", meta.Slug, "action", res.Action)
+			}
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
+
+func (r *QuailyReconciler) reconcile(ctx context.Context, qc *quaily.Client, channel, period string, meta storage.PublishMeta) QuailyReconcileResult {
+	res := QuailyReconcileResult{Channel: channel, Period: period, Slug: meta.Slug, Path: meta.Paths[newsletter.FormatMarkdown]}
+	if res.Path == "" && len(meta.Paths) == 0 {
+		res.Path = meta.Path // records from before per-format paths
+	}
+	// Digests published in preview mode are reconciled against the preview channel.
+	qch := meta.QuailySlug(channel)
+	ctxReq, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	post, found, err := qc.GetPostBySlug(ctxReq, qch, meta.Slug)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	switch {
+	case found && post.Published:
+		res.Action = ReconcileRecord
+	case found:
+		res.Action = ReconcilePublish
+	default:
+		res.Action = ReconcilePush
+		if res.Path == "" {
+			res.Error = "markdown is not among the channel formats"
+			return res
+		}
+		if _, err := os.Stat(res.Path); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+	}
+	if r.DryRun {
+		return res
+	}
+	switch res.Action {
+	case ReconcilePublish:
+		err = qc.PublishPost(ctxReq, qch, post.ID)
+	case ReconcilePush:
+		err = quaily.PublishMarkdownFile(ctxReq, qc, res.Path, qch)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	pushed := nowFunc(r.Now).UTC()
+	meta.QuailyPublishedAt = &pushed
+	if err := r.Store.SetPublishMeta(ctx, channel, period, meta); err != nil {
+		slog.Warn("quaily reconcile: save publish metadata failed", "err", err, "channel", channel, "period", period)
+	}
+	// A post that was already live was delivered by whoever published it.
+	if res.Action != ReconcileRecord {
+		if err := QueueDelivery(ctx, r.Store, channel, qch, meta.Slug, pushed.Add(5*time.Second)); err != nil {
+			slog.Warn("quaily reconcile: queue quaily delivery failed", "err", err, "channel", channel, "slug", meta.Slug)
+		}
+	}
+	return res
+}
+
+// periods returns the distinct period keys of the last Days days, oldest first.
+func (r *QuailyReconciler) periods(freq string) []string {
+	days := r.Days
+	if days <= 0 {
+		days = DefaultQuailyReconcileDays
+	}
+	now := nowFunc(r.Now)
+	return period.Range(freq, now.AddDate(0, 0, -(days-1)), now)
+}
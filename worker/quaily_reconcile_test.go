@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/quaily"
+	"quaily-journalist/internal/storage"
+)
+
+func TestQuailyReconciler(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch r.Method + " " + r.URL.Path {
+		case "GET /lists/ch/posts/daily-20251022":
+			w.Write([]byte(`{"data":{"id":7,"slug":"daily-20251022","published_at":null}}`))
+		case "GET /lists/ch/posts/daily-20251023":
+			w.Write([]byte(`{"data":{"id":8,"slug":"daily-20251023","published_at":"2025-10-23T00:05:00Z"}}`))
+		case "POST /lists/ch/posts":
+			w.Write([]byte(`{"data":{"id":9}}`))
+		case "PUT /lists/ch/posts/7/publish", "PUT /lists/ch/posts/9/publish":
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	store := newDeliveryTestStore(t)
+	dir := t.TempDir()
+	for _, day := range []string{"2025-10-21", "2025-10-22", "2025-10-23", "2025-10-24"} {
+		slug := "daily-" + strings.ReplaceAll(day, "-", "")
+		p := filepath.Join(dir, slug+".md")
+		if err := os.WriteFile(p, []byte("---\ntitle: T\nslug: "+slug+"\n---\nbody\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		meta := storage.PublishMeta{Path: p, Paths: map[string]string{"markdown": p}, Slug: slug, WrittenAt: now}
+		if day == "2025-10-21" {
+			meta.QuailyPublishedAt = &now // pushed already, and outside the window anyway
+		}
+		if err := store.SetPublishMeta(ctx, "ch", day, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec := &QuailyReconciler{
+		Store:    store,
+		Channels: []ReconcileChannel{{Name: "ch", Frequency: "daily", Quaily: quaily.New(srv.URL, "k", 0)}},
+		Now:      func() time.Time { return now },
+		DryRun:   true,
+	}
+
+	results, err := rec.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"2025-10-22": ReconcilePublish, "2025-10-23": ReconcileRecord, "2025-10-24": ReconcilePush}
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	for _, r := range results {
+		if r.Action != want[r.Period] || r.Error != "" {
+			t.Errorf("%s: action=%q err=%q, want %q", r.Period, r.Action, r.Error, want[r.Period])
+		}
+	}
+	for _, c := range calls {
+		if !strings.HasPrefix(c, "GET ") {
+			t.Errorf("dry run made a write call: %s", c)
+		}
+	}
+
+	rec.DryRun = false
+	if _, err := rec.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(calls, "\n")
+	for _, c := range []string{"PUT /lists/ch/posts/7/publish", "POST /lists/ch/posts", "PUT /lists/ch/posts/9/publish"} {
+		if !strings.Contains(joined, c) {
+			t.Errorf("missing call %s in:\n%s", c, joined)
+		}
+	}
+	for day := range want {
+		meta, _, _ := store.GetPublishMeta(ctx, "ch", day)
+		if meta.QuailyPublishedAt == nil {
+			t.Errorf("%s not recorded as pushed", day)
+		}
+	}
+	tasks, err := store.Deliveries(ctx, "ch")
+	if err != nil || len(tasks) != 2 {
+		t.Errorf("queued deliveries = %d (%v), want 2 (not for the post that was already live)", len(tasks), err)
+	}
+
+	// Everything is recorded now, so a second pass has nothing to do.
+	if again, _ := rec.Run(ctx); len(again) != 0 {
+		t.Errorf("second run = %+v", again)
+	}
+}
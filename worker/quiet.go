@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/storage"
+)
+
+// QuietHours is a daily window in which a collector does not poll its source and a
+// builder does not publish. Start and End are minutes after midnight in Loc (nil is
+// UTC); an End before Start spans midnight, e.g., 22:00 to 07:00. The zero value,
+// with Start equal to End, is never quiet.
+type QuietHours struct {
+	Start, End int
+	Loc        *time.Location
+}
+
+// Until reports whether t falls inside the window and, if so, when the window ends.
+// The start is inside the window, the end is not.
+func (q QuietHours) Until(t time.Time) (time.Time, bool) {
+	if q.Start == q.End {
+		return time.Time{}, false
+	}
+	loc := q.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	lt := t.In(loc)
+	y, m, d := lt.Date()
+	// time.Date normalizes the minutes, which keeps wall-clock times across DST.
+	at := func(day, minute int) time.Time { return time.Date(y, m, d+day, 0, minute, 0, 0, loc) }
+	if q.Start < q.End {
+		if end := at(0, q.End); !lt.Before(at(0, q.Start)) && lt.Before(end) {
+			return end, true
+		}
+		return time.Time{}, false
+	}
+	// Spanning midnight: quiet from midnight to End and from Start to the next End.
+	if end := at(0, q.End); lt.Before(end) {
+		return end, true
+	}
+	if !lt.Before(at(0, q.Start)) {
+		return at(1, q.End), true
+	}
+	return time.Time{}, false
+}
+
+// QuietError is returned by a run skipped for quiet hours.
+type QuietError struct {
+	Until time.Time // end of the window, in its timezone
+}
+
+func (e *QuietError) Error() string {
+	return fmt.Sprintf("in quiet hours until %s", e.Until.Format("15:04"))
+}
+
+// skipQuiet returns a QuietError when at falls in q, after logging it and recording
+// the end of the window in the worker status, where `status` shows it; nil otherwise.
+// The skipped run is not recorded as a run, so the last run and error stay those of
+// the latest real one.
+func skipQuiet(ctx context.Context, store *storage.RedisStore, worker string, q QuietHours, at time.Time) error {
+	until, quiet := q.Until(at)
+	if !quiet {
+		return nil
+	}
+	slog.Info("worker: skipping run in quiet hours", "worker", worker, "until", until)
+	if err := store.SetWorkerQuietUntil(ctx, worker, until); err != nil {
+		slog.Warn("worker: record quiet hours failed", "worker", worker, "err", err)
+	}
+	return &QuietError{Until: until}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/bluesky"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tz database:", err)
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, berlin) }
+	night := QuietHours{Start: 22 * 60, End: 7 * 60, Loc: berlin}
+	day := QuietHours{Start: 9 * 60, End: 17*60 + 30, Loc: berlin}
+	cases := []struct {
+		name  string
+		q     QuietHours
+		t     time.Time
+		until time.Time // zero when not quiet
+	}{
+		{"before a midnight window", night, at(10, 21, 59), time.Time{}},
+		{"its start", night, at(10, 22, 0), at(11, 7, 0)},
+		{"before midnight", night, at(10, 23, 59), at(11, 7, 0)},
+		{"midnight", night, at(11, 0, 0), at(11, 7, 0)},
+		{"last minute", night, at(11, 6, 59), at(11, 7, 0)},
+		{"its end", night, at(11, 7, 0), time.Time{}},
+		{"in UTC", night, at(10, 22, 30).UTC(), at(11, 7, 0)},
+		{"before a daytime window", day, at(10, 8, 59), time.Time{}},
+		{"its start", day, at(10, 9, 0), at(10, 17, 30)},
+		{"its end", day, at(10, 17, 30), time.Time{}},
+		{"zero value", QuietHours{}, at(10, 12, 0), time.Time{}},
+	}
+	for _, c := range cases {
+		until, quiet := c.q.Until(c.t)
+		if quiet != !c.until.IsZero() || !until.Equal(c.until) {
+			t.Errorf("%s: Until(%s) = %s, %v; want %s", c.name, c.t.Format(time.RFC3339), until, quiet, c.until)
+		}
+	}
+	// A night across the switch to summer time still ends at 07:00 local time.
+	if until, _ := night.Until(at(28, 23, 0)); until.Format("2006-01-02 15:04 MST") != "2026-03-29 07:00 CEST" {
+		t.Errorf("window across DST ends %s", until)
+	}
+}
+
+// A collector inside its quiet hours fetches nothing and shows when they end in its
+// status; the first run after the window polls and clears it.
+func TestCollectorSkipsQuietHours(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"posts": []}`))
+	}))
+	defer srv.Close()
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC)
+	c := &BlueskyCollector{
+		Client:     bluesky.NewClient(srv.URL),
+		Store:      store,
+		Feeds:      []BlueskyFeed{{Node: "golang", Query: "golang"}},
+		QuietHours: QuietHours{Start: 22 * 60, End: 7 * 60},
+		Now:        func() time.Time { return now },
+	}
+	_, err := c.RunOnce(ctx)
+	var qe *QuietError
+	if !errors.As(err, &qe) || err.Error() != "in quiet hours until 07:00" || hits.Load() != 0 {
+		t.Fatalf("RunOnce in quiet hours = %v after %d requests", err, hits.Load())
+	}
+	st, ok, err := store.GetWorkerStatus(ctx, blueskyCollectorName)
+	if err != nil || !ok || st.QuietUntil == nil || !st.QuietUntil.Equal(time.Date(2026, 3, 11, 7, 0, 0, 0, time.UTC)) || !st.LastRunAt.IsZero() {
+		t.Fatalf("status in quiet hours = %+v, %v, %v", st, ok, err)
+	}
+
+	now = time.Date(2026, 3, 11, 7, 0, 0, 0, time.UTC)
+	if _, err := c.RunOnce(ctx); err != nil || hits.Load() != 1 {
+		t.Fatalf("RunOnce after quiet hours = %v after %d requests", err, hits.Load())
+	}
+	st, _, _ = store.GetWorkerStatus(ctx, blueskyCollectorName)
+	if st.QuietUntil != nil || !st.LastRunAt.Equal(now) {
+		t.Errorf("status after quiet hours = %+v", st)
+	}
+}
+
+// A builder defers a closing period's digest until its quiet hours end; the first
+// run afterwards publishes it.
+func TestBuilderDefersPublishingInQuietHours(t *testing.T) {
+	ctx := context.Background()
+	w, _, prev := insufficientBuilder(t, 5, "")
+	y, m, d := time.Now().UTC().Date()
+	now := time.Date(y, m, d, 6, 59, 0, 0, time.UTC)
+	w.Now = func() time.Time { return now }
+	w.QuietHours = QuietHours{Start: 22 * 60, End: 7 * 60}
+
+	res, err := w.RunOnce(ctx)
+	if err != nil || res.Skipped != BuildQuietHours {
+		t.Fatalf("RunOnce in quiet hours = %+v, %v", res, err)
+	}
+	if _, ok, _ := w.Store.GetPublishMeta(ctx, "ch", prev); ok {
+		t.Fatal("previous period published in quiet hours")
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if meta, ok, _ := w.Store.GetPublishMeta(ctx, "ch", prev); !ok || meta.Path == "" {
+		t.Fatalf("previous period not published after quiet hours: %+v", meta)
+	}
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/reddit"
+	"quaily-journalist/internal/storage"
+)
+
+// Bounds of the pause after Reddit rate-limits the collector without saying for how long.
+const (
+	redditMinBackoff = time.Minute
+	redditMaxBackoff = 30 * time.Minute
+)
+
+// RedditCollector polls the hot listing of subreddits and stores their posts into
+// period ZSETs under the subreddit name. When Reddit rate-limits it (429 after the
+// HTTP client's retries), the rest of the run is skipped and no subreddit is polled
+// until the wait Reddit asked for has passed, or a backoff of 1m doubling to 30m.
+type RedditCollector struct {
+	Client     RedditSource
+	Store      *storage.RedisStore
+	Subreddits []string
+	Interval   time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores posts; zero fields use ranking.ForSource("reddit"), the Hacker
+	// News formula on upvotes.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each post's listing JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu          sync.Mutex // guards Subreddits after Start
+	buffer      storeBuffer
+	pausedUntil time.Time     // no requests before this, after a 429
+	backoff     time.Duration // the last pause without a Retry-After, doubled per 429
+}
+
+// SetNodes replaces the polled subreddits; the change applies at the next run.
+func (w *RedditCollector) SetNodes(units []string) (added []string) {
+	return setUnits(&w.mu, &w.Subreddits, units, stringKey)
+}
+
+func (w *RedditCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 15 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, redditCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// redditCollectorName identifies the collector's persisted status record.
+const redditCollectorName = "reddit-collector"
+
+func (w *RedditCollector) Name() string { return redditCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Subreddits that fail are logged, counted, and joined into the
+// error; the others are still stored.
+func (w *RedditCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, redditCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, redditCollectorName, reddit.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, redditCollectorName, started, err)
+	countCollected(ctx, w.Store, reddit.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *RedditCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	subs := currentUnits(&w.mu, &w.Subreddits)
+	loop := collectLoop{source: reddit.Source, unit: "subreddit", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		before: func(i int) error {
+			if !nowFunc(w.Now).Before(w.pausedUntil) {
+				return nil
+			}
+			skipped := len(subs) - i
+			slog.Warn("reddit collector: rate limited; skipping subreddits", "until", w.pausedUntil, "skipped", skipped)
+			return fmt.Errorf("rate limited until %s; %d subreddits skipped", w.pausedUntil.Format(time.RFC3339), skipped)
+		}}
+	res, err := loop.run(ctx, subs, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		items, err := w.Client.Hot(ctx, subs[i])
+		var limited *reddit.RateLimitError
+		if errors.As(err, &limited) {
+			w.pause(nowFunc(w.Now), limited.RetryAfter)
+		}
+		return items, err
+	})
+	if res.Failed == 0 {
+		w.backoff = 0
+	}
+	return res, err
+}
+
+// pause holds off requests for retryAfter, or for the next backoff step when Reddit
+// did not say how long.
+func (w *RedditCollector) pause(now time.Time, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		w.backoff *= 2
+		if w.backoff < redditMinBackoff {
+			w.backoff = redditMinBackoff
+		}
+		if w.backoff > redditMaxBackoff {
+			w.backoff = redditMaxBackoff
+		}
+		retryAfter = w.backoff
+	}
+	w.pausedUntil = now.Add(retryAfter)
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/reddit"
+)
+
+// Posts are stored under their subreddit; a 429 skips the rest of the run and
+// pauses polling for the wait Reddit asked for.
+func TestRedditCollectorBacksOffWhenRateLimited(t *testing.T) {
+	created := time.Now().UTC().Add(-2 * time.Hour).Unix()
+	var mu sync.Mutex
+	var requested []string
+	limited := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/r/rust/hot.json" && limited {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		sub := r.URL.Path[len("/r/") : len(r.URL.Path)-len("/hot.json")]
+		fmt.Fprintf(w, `{"data": {"children": [
+			{"kind": "t3", "data": {"id": "%[1]s1", "title": "post", "url": "https://example.com/%[1]s", "subreddit": %[1]q, "ups": 20, "num_comments": 0, "created_utc": %[2]d}},
+			{"kind": "t3", "data": {"id": "%[1]s0", "title": "rules", "subreddit": %[1]q, "ups": 90, "created_utc": %[2]d, "stickied": true}}
+		]}}`, sub, created)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &RedditCollector{Client: reddit.NewClient(srv.URL, ""), Store: store, Subreddits: []string{"golang", "rust", "python"}, Now: func() time.Time { return now }}
+	res, err := c.RunOnce(ctx)
+	if err == nil || res.Stored != 1 || res.Failed != 2 {
+		t.Fatalf("RunOnce = %+v, %v; want golang stored, rust limited, python skipped", res, err)
+	}
+	if want := []string{"/r/golang/hot.json", "/r/rust/hot.json"}; !slices.Equal(requested, want) {
+		t.Fatalf("requested %v, want %v", requested, want)
+	}
+
+	now = now.Add(time.Minute)
+	if res, _ := c.RunOnce(ctx); res.Failed != 3 || len(requested) != 2 {
+		t.Fatalf("run within the pause = %+v after %d requests, want every subreddit skipped", res, len(requested))
+	}
+
+	now = now.Add(2 * time.Minute)
+	mu.Lock()
+	limited = false
+	mu.Unlock()
+	if res, err := c.RunOnce(ctx); err != nil || res.Stored != 3 {
+		t.Fatalf("run after the pause = %+v, %v", res, err)
+	}
+	got, err := store.TopNews(ctx, "reddit", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(got) != 3 || got[0].Item.NodeName == "" {
+		t.Fatalf("TopNews = %v, %v", itemIDs(got), err)
+	}
+	b := &NewsletterBuilder{Store: store, Source: "reddit", Nodes: []string{"Rust"}}
+	if kept := itemIDs(b.rank(ctx, got)); !slices.Equal(kept, []string{"rust1"}) {
+		t.Errorf("channel on Rust kept %v", kept)
+	}
+	if u := NodeURL("reddit", reddit.DefaultBaseURL, "rust"); u != "https://www.reddit.com/r/rust" {
+		t.Errorf("node URL = %q", u)
+	}
+}
+
+func TestRedditCollectorBackoffWithoutRetryAfter(t *testing.T) {
+	c := &RedditCollector{}
+	now := time.Now()
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		c.pause(now, 0)
+		if got := c.pausedUntil.Sub(now); got != want {
+			t.Errorf("pause = %s, want %s", got, want)
+		}
+	}
+	c.backoff = 20 * time.Minute
+	c.pause(now, 0)
+	if got := c.pausedUntil.Sub(now); got != redditMaxBackoff {
+		t.Errorf("pause = %s, want the %s cap", got, redditMaxBackoff)
+	}
+}
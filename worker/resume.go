@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultResumeRatio is the fraction of a collector's interval within which a
+// previous run makes a restarted collector skip its initial run.
+const DefaultResumeRatio = 0.5
+
+// resumeDelay returns how long a restarted worker should wait before its first run.
+// When the last run happened less than ratio*interval ago, it waits out the rest of
+// the interval, as if it had never stopped; otherwise it runs immediately.
+// ratio 0 uses DefaultResumeRatio; a negative ratio always runs immediately.
+func resumeDelay(lastRun, now time.Time, interval time.Duration, ratio float64) time.Duration {
+	if ratio == 0 {
+		ratio = DefaultResumeRatio
+	}
+	if ratio < 0 || lastRun.IsZero() || interval <= 0 {
+		return 0
+	}
+	elapsed := now.Sub(lastRun)
+	if elapsed < 0 || elapsed >= time.Duration(ratio*float64(interval)) {
+		return 0
+	}
+	return interval - elapsed
+}
+
+// waitForResume delays a worker's first run per resumeDelay using its persisted
+// last run time. It returns false if ctx is cancelled while waiting.
+func waitForResume(ctx context.Context, store *storage.RedisStore, worker string, interval time.Duration, ratio float64, now time.Time) bool {
+	st, ok, err := store.GetWorkerStatus(ctx, worker)
+	if err != nil {
+		slog.Warn("worker: read last run failed; running now", "worker", worker, "err", err)
+		return true
+	}
+	if !ok {
+		return true
+	}
+	wait := resumeDelay(st.LastRunAt, now, interval, ratio)
+	if wait <= 0 {
+		return true
+	}
+	slog.Info("worker: skipping initial run after restart", "worker", worker, "last_run_at", st.LastRunAt, "next_run_in", wait.Round(time.Second))
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// recordRun persists the start time of a completed run.
+func recordRun(ctx context.Context, store *storage.RedisStore, worker string, at time.Time) {
+	if err := store.SetWorkerLastRun(ctx, worker, at); err != nil {
+		slog.Warn("worker: record last run failed", "worker", worker, "err", err)
+	}
+}
+
+// recordOutcome persists the start time of a run together with its error, or clears
+// the recorded error when err is nil.
+func recordOutcome(ctx context.Context, store *storage.RedisStore, worker string, at time.Time, err error) {
+	recordRun(ctx, store, worker, at)
+	if err == nil {
+		if err := store.ClearWorkerError(ctx, worker); err != nil {
+			slog.Warn("worker: clear last error failed", "worker", worker, "err", err)
+		}
+		return
+	}
+	if serr := store.SetWorkerError(ctx, worker, err.Error(), time.Now()); serr != nil {
+		slog.Warn("worker: record last error failed", "worker", worker, "err", serr)
+	}
+}
+
+// nowFunc returns clock, or time.Now when clock is nil.
+func nowFunc(clock func() time.Time) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+	return clock()
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestResumeDelay(t *testing.T) {
+	now := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	interval := 10 * time.Minute
+	cases := []struct {
+		name    string
+		lastRun time.Time
+		ratio   float64
+		want    time.Duration
+	}{
+		{"never ran", time.Time{}, 0, 0},
+		{"30s ago waits out the interval", now.Add(-30 * time.Second), 0, interval - 30*time.Second},
+		{"just under default ratio", now.Add(-4*time.Minute - 59*time.Second), 0, 5*time.Minute + time.Second},
+		{"at default ratio runs now", now.Add(-5 * time.Minute), 0, 0},
+		{"custom ratio", now.Add(-7 * time.Minute), 0.9, 3 * time.Minute},
+		{"disabled", now.Add(-30 * time.Second), -1, 0},
+		{"clock skew runs now", now.Add(time.Minute), 0, 0},
+	}
+	for _, c := range cases {
+		if got := resumeDelay(c.lastRun, now, interval, c.ratio); got != c.want {
+			t.Errorf("%s: resumeDelay = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCollectorRecordsLastRun(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+
+	at := time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)
+	w := &HNCollector{Store: store, Now: func() time.Time { return at }}
+	recordRun(context.Background(), w.Store, hnCollectorName, nowFunc(w.Now))
+
+	st, ok, err := store.GetWorkerStatus(context.Background(), hnCollectorName)
+	if err != nil || !ok || !st.LastRunAt.Equal(at) {
+		t.Fatalf("GetWorkerStatus = %+v, %v, %v; want last run %v", st, ok, err, at)
+	}
+
+	// A restart 30s later waits instead of running; cancelling the wait stops the worker.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if waitForResume(ctx, store, hnCollectorName, 10*time.Minute, 0, at.Add(30*time.Second)) {
+		t.Error("waitForResume ran immediately, want a deferred first run")
+	}
+	if !waitForResume(context.Background(), store, hnCollectorName, 10*time.Minute, 0, at.Add(6*time.Minute)) {
+		t.Error("waitForResume deferred a run past half the interval")
+	}
+}
@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"quaily-journalist/internal/storage"
+)
+
+// Retention modes control what happens to digest files beyond a channel's keep_files.
+const (
+	RetentionDelete  = "delete"  // remove them (default)
+	RetentionArchive = "archive" // move them to <channel>/archive, keeping the layout
+)
+
+// ArchiveDir is the subdirectory of a channel's output directory that archive mode
+// moves pruned digests to; pruning never looks inside it.
+const ArchiveDir = "archive"
+
+// CheckRetentionMode reports an unknown retention.mode; empty means delete.
+func CheckRetentionMode(mode string) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", RetentionDelete, RetentionArchive:
+		return nil
+	}
+	return fmt.Errorf("unknown retention.mode %q (want delete or archive)", mode)
+}
+
+// digestEntryRE matches the files and cover directories of a digest: the slug
+// (<frequency>-YYYYMMDD, "-part-N" for split parts) with an optional extension,
+// which covers the formats and generate's .bak-<timestamp> copies.
+var digestEntryRE = regexp.MustCompile(`^([a-z]+-[0-9]{8})(?:-part-[0-9]+)?(?:\..+)?$`)
+
+// digestName returns the digest a file or directory name belongs to, e.g.
+// "daily-20251024" for "daily-20251024-part-2.html".
+func digestName(base string) (string, bool) {
+	m := digestEntryRE.FindStringSubmatch(base)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// PruneOptions selects the digest files PruneDigestFiles keeps.
+type PruneOptions struct {
+	OutputDir string
+	Channel   string
+	Keep      int    // digests to keep, newest first; <= 0 keeps everything
+	Mode      string // RetentionDelete (default) or RetentionArchive
+	DryRun    bool   // report what would be pruned without touching any file
+}
+
+// PrunedDigest is a digest whose files were deleted or archived (or would be, on a
+// dry run).
+type PrunedDigest struct {
+	Name  string   `json:"name"`  // e.g., daily-20251024
+	Files []string `json:"files"` // files and cover directories, as found under the output dir
+}
+
+// PruneResult reports a PruneDigestFiles pass.
+type PruneResult struct {
+	Channel string         `json:"channel"`
+	DryRun  bool           `json:"dry_run"`
+	Mode    string         `json:"mode"`
+	Kept    int            `json:"kept"`
+	Pruned  []PrunedDigest `json:"pruned"`
+	// Held lists digests beyond Keep that a pending delivery still reads, left in place.
+	Held []string `json:"held,omitempty"`
+}
+
+// PruneDigestFiles keeps the newest opts.Keep digests of a channel on disk and
+// deletes or archives the files of the older ones, in every output layout. A digest
+// counts once whatever its formats, parts, backups, and cover. Digests with a
+// pending delivery of the channel are held, so the delivery can still read them;
+// store may be nil to skip that check. Emptied year and month directories are removed.
+func PruneDigestFiles(ctx context.Context, store *storage.RedisStore, opts PruneOptions) (PruneResult, error) {
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	if mode == "" {
+		mode = RetentionDelete
+	}
+	res := PruneResult{Channel: opts.Channel, DryRun: opts.DryRun, Mode: mode, Pruned: []PrunedDigest{}}
+	if err := CheckRetentionMode(mode); err != nil {
+		return res, err
+	}
+	root := filepath.Join(opts.OutputDir, opts.Channel)
+	files := map[string][]string{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		if d.IsDir() && filepath.Dir(p) == root && d.Name() == ArchiveDir {
+			return filepath.SkipDir
+		}
+		name, ok := digestName(d.Name())
+		if !ok {
+			return nil // layout directories and unrelated files
+		}
+		files[name] = append(files[name], p)
+		if d.IsDir() {
+			return filepath.SkipDir // a cover directory goes as a whole
+		}
+		return nil
+	})
+	if err != nil {
+		return res, fmt.Errorf("list digests of %s: %w", opts.Channel, err)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	// Newest first by date, whatever frequency wrote the digest.
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := names[i][strings.LastIndex(names[i], "-")+1:], names[j][strings.LastIndex(names[j], "-")+1:]
+		if di != dj {
+			return di > dj
+		}
+		return names[i] < names[j]
+	})
+	if opts.Keep <= 0 || len(names) <= opts.Keep {
+		res.Kept = len(names)
+		return res, nil
+	}
+	held, err := pendingDigests(ctx, store, opts.Channel)
+	if err != nil {
+		return res, err
+	}
+	res.Kept = opts.Keep
+	for _, name := range names[opts.Keep:] {
+		if held[name] {
+			res.Held = append(res.Held, name)
+			res.Kept++
+			continue
+		}
+		paths := files[name]
+		sort.Strings(paths)
+		if !opts.DryRun {
+			for _, p := range paths {
+				if err := pruneEntry(root, p, mode); err != nil {
+					return res, err
+				}
+			}
+		}
+		res.Pruned = append(res.Pruned, PrunedDigest{Name: name, Files: paths})
+	}
+	return res, nil
+}
+
+// pendingDigests returns the digests of channel that a pending delivery refers to,
+// by slug or by the files it sends.
+func pendingDigests(ctx context.Context, store *storage.RedisStore, channel string) (map[string]bool, error) {
+	held := map[string]bool{}
+	if store == nil {
+		return held, nil
+	}
+	tasks, err := store.Deliveries(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries of %s: %w", channel, err)
+	}
+	for _, t := range tasks {
+		if t.State != storage.DeliveryPending || t.Channel != channel {
+			continue
+		}
+		if name, ok := digestName(t.Slug); ok {
+			held[name] = true
+		}
+		for _, p := range t.Paths {
+			if name, ok := digestName(filepath.Base(p)); ok {
+				held[name] = true
+			}
+		}
+	}
+	return held, nil
+}
+
+// pruneEntry deletes p, or moves it to the same place under root/ArchiveDir, then
+// removes the directories between p and root that it left empty.
+func pruneEntry(root, p, mode string) error {
+	if mode == RetentionArchive {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(root, ArchiveDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("archive %s: %w", p, err)
+		}
+		// A digest archived before (then regenerated) is replaced.
+		if err := os.RemoveAll(dst); err != nil {
+			return fmt.Errorf("archive %s: %w", p, err)
+		}
+		if err := os.Rename(p, dst); err != nil {
+			return fmt.Errorf("archive %s: %w", p, err)
+		}
+	} else if err := os.RemoveAll(p); err != nil {
+		return fmt.Errorf("delete %s: %w", p, err)
+	}
+	for dir := filepath.Dir(p); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // not empty
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/storage"
+)
+
+// writeTree creates the files (relative to dir) with placeholder content.
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listTree returns the files under dir, relative and slash-separated.
+func listTree(t *testing.T, dir string) []string {
+	t.Helper()
+	var out []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		out = append(out, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestPruneDigestFilesByMonth(t *testing.T) {
+	out := t.TempDir()
+	ch := filepath.Join(out, "ch")
+	writeTree(t, ch,
+		"2025/09/daily-20250930.md",
+		"2025/09/daily-20250930.html",
+		"2025/09/daily-20250930/cover.webp",
+		"2025/10/daily-20251001.md",
+		"2025/10/daily-20251001.md.bak-20251001T080000Z",
+		"2025/10/daily-20251002.md",
+		"2025/10/daily-20251002-part-2.md",
+		"2025/10/daily-20251003.md",
+		"2025/10/notes.txt",
+	)
+
+	res, err := PruneDigestFiles(context.Background(), nil, PruneOptions{OutputDir: out, Channel: "ch", Keep: 2, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, d := range res.Pruned {
+		names = append(names, d.Name)
+	}
+	if want := []string{"daily-20251001", "daily-20250930"}; !reflect.DeepEqual(names, want) || res.Kept != 2 {
+		t.Fatalf("dry run pruned %v (kept %d), want %v", names, res.Kept, want)
+	}
+	if got := listTree(t, ch); len(got) != 9 {
+		t.Fatalf("dry run changed files: %v", got)
+	}
+
+	if _, err := PruneDigestFiles(context.Background(), nil, PruneOptions{OutputDir: out, Channel: "ch", Keep: 2}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2025/10/daily-20251002-part-2.md",
+		"2025/10/daily-20251002.md",
+		"2025/10/daily-20251003.md",
+		"2025/10/notes.txt",
+	}
+	if got := listTree(t, ch); !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(ch, "2025", "09")); !os.IsNotExist(err) {
+		t.Errorf("emptied month directory left behind: %v", err)
+	}
+}
+
+func TestPruneDigestFilesArchivesAndHoldsPendingDeliveries(t *testing.T) {
+	out := t.TempDir()
+	ch := filepath.Join(out, "ch")
+	writeTree(t, ch,
+		"2024/weekly-20241230.md",
+		"2025/weekly-20250106.md",
+		"2025/weekly-20250106.html",
+		"2025/weekly-20250113.md",
+	)
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	task := storage.DeliveryTask{Channel: "ch", Slug: "weekly-20250106", Target: storage.TargetEmail,
+		Paths: map[string]string{"html": filepath.Join(ch, "2025", "weekly-20250106.html")}}
+	if err := QueueTask(ctx, store, task, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := PruneDigestFiles(ctx, store, PruneOptions{OutputDir: out, Channel: "ch", Keep: 1, Mode: RetentionArchive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Held, []string{"weekly-20250106"}) || len(res.Pruned) != 1 || res.Kept != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	want := []string{
+		"2025/weekly-20250106.html",
+		"2025/weekly-20250106.md",
+		"2025/weekly-20250113.md",
+		"archive/2024/weekly-20241230.md",
+	}
+	if got := listTree(t, ch); !reflect.DeepEqual(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+
+	// Archived digests are not counted again.
+	res, err = PruneDigestFiles(ctx, store, PruneOptions{OutputDir: out, Channel: "ch", Keep: 1, Mode: RetentionArchive})
+	if err != nil || len(res.Pruned) != 0 {
+		t.Fatalf("second pass = %+v, %v", res, err)
+	}
+}
+
+func TestPruneDigestFilesMissingDir(t *testing.T) {
+	res, err := PruneDigestFiles(context.Background(), nil, PruneOptions{OutputDir: t.TempDir(), Channel: "none", Keep: 1})
+	if err != nil || res.Kept != 0 || len(res.Pruned) != 0 {
+		t.Fatalf("PruneDigestFiles = %+v, %v", res, err)
+	}
+	if err := CheckRetentionMode("shred"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
+)
+
+// RSSFeed is a feed the RSS collector polls. Node is the node name its items are
+// stored under; empty uses the feed's title.
+type RSSFeed struct {
+	URL  string
+	Node string
+}
+
+// RSSCollector polls RSS/Atom feeds and stores their items into period ZSETs, like
+// the V2EX collector does for nodes.
+type RSSCollector struct {
+	Client          RSSSource
+	Store           *storage.RedisStore
+	Feeds           []RSSFeed // initial feeds; use SetNodes once running
+	Interval        time.Duration
+	MaxContentRunes int // content budget after cleaning; 0 uses textclean.DefaultMaxRunes
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores items; zero fields use ranking.ForSource("rss"), which falls
+	// back to recency for items without comments.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each entry's XML; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Feeds after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled feeds; the change applies at the next run. It
+// returns the URLs of the feeds that were not polled before.
+func (w *RSSCollector) SetNodes(feeds []RSSFeed) (added []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	added = newEntries(feedURLs(w.Feeds), feedURLs(feeds))
+	w.Feeds = append([]RSSFeed(nil), feeds...)
+	return added
+}
+
+func (w *RSSCollector) currentFeeds() []RSSFeed {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]RSSFeed(nil), w.Feeds...)
+}
+
+func feedURLs(feeds []RSSFeed) []string {
+	urls := make([]string, len(feeds))
+	for i, f := range feeds {
+		urls[i] = f.URL
+	}
+	return urls
+}
+
+func (w *RSSCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = 30 * time.Minute
+	}
+	if !waitForResume(ctx, w.Store, rssCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// rssCollectorName identifies the collector's persisted status record.
+const rssCollectorName = "rss-collector"
+
+func (w *RSSCollector) Name() string { return rssCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Feeds that fail are logged, counted, and joined into the error;
+// the others are still stored.
+func (w *RSSCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, rssCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, rssCollectorName, "rss", started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx)
+	recordOutcome(ctx, w.Store, rssCollectorName, started, err)
+	countCollected(ctx, w.Store, "rss", started, res.Stored)
+	return res, err
+}
+
+func (w *RSSCollector) collect(ctx context.Context) (CollectResult, error) {
+	day := period.Key(period.Daily, time.Now().UTC())
+	week := period.Key(period.Weekly, time.Now().UTC())
+	scorer := ranking.ForSource("rss").Merge(w.Ranking)
+	var res CollectResult
+	var errs []error
+	w.buffer.source, w.buffer.max, w.buffer.archiveRaw = "rss", w.BufferSize, w.ArchiveRaw
+	res.Stored, _ = w.buffer.flush(ctx, w.Store)
+	for _, feed := range w.currentFeeds() {
+		items, err := w.Client.Fetch(ctx, feed.URL, feed.Node)
+		if err != nil {
+			slog.Error("rss collector: fetch feed failed", "feed", feed.URL, "error", err)
+			res.Failed++
+			errs = append(errs, fmt.Errorf("feed %s: %w", feed.URL, err))
+			continue
+		}
+		res.Fetched += len(items)
+		stored := 0
+		for _, it := range items {
+			score := scorer.Score(it, time.Now())
+			if score <= 0 {
+				continue
+			}
+			it.Content = textclean.Clean(it.Content, textclean.Options{MaxRunes: w.MaxContentRunes})
+			ok, err := w.buffer.store(ctx, w.Store, bufferedItem{item: it, score: score, periods: []string{day, week}})
+			if err != nil {
+				slog.Error("rss collector: store error", "id", it.ID, "error", err)
+			} else if ok {
+				stored++
+			}
+		}
+		slog.Info("rss collector: completed for feed", "feed", feed.URL, "stored", stored, "periods", []string{day, week})
+		res.Stored += stored
+	}
+	res.Buffered = w.buffer.len()
+	if err := w.buffer.err(); err != nil {
+		slog.Error("rss collector: items held until redis is back", "buffered", res.Buffered, "error", err)
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
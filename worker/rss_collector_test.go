@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/rss"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Example Blog</title>
+<item><title>Fresh post</title><link>https://example.com/fresh</link><pubDate>%s</pubDate></item>
+<item><title>Older post</title><link>https://example.com/older</link><pubDate>%s</pubDate></item>
+</channel></rss>`
+
+// Feed items carry no replies or points; they are stored under the feed's node
+// with a recency score, and an rss channel keeps them through its node filter.
+func TestRSSCollectorStoresFeedItems(t *testing.T) {
+	now := time.Now().UTC()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testFeed, now.Add(-time.Hour).Format(time.RFC1123Z), now.Add(-30*time.Hour).Format(time.RFC1123Z))
+	}))
+	defer srv.Close()
+
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	// Both feeds serve the same links; the second, labeled by its title, is stored last.
+	c := &RSSCollector{Client: rss.NewClient(), Store: store, Feeds: []RSSFeed{{URL: srv.URL + "/a.xml", Node: "blog"}, {URL: srv.URL + "/b.xml"}}}
+	res, err := c.RunOnce(ctx)
+	if err != nil || res.Fetched != 4 || res.Stored != 4 {
+		t.Fatalf("RunOnce = %+v, %v", res, err)
+	}
+
+	got, err := store.TopNews(ctx, "rss", period.Key(period.Daily, now), 10)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("TopNews = %d items, %v; want 2 (one per link)", len(got), err)
+	}
+	if got[0].Item.Title != "Fresh post" || got[0].Score <= got[1].Score {
+		t.Errorf("ranking = %s %v, %s %v; want the fresh post first", got[0].Item.Title, got[0].Score, got[1].Item.Title, got[1].Score)
+	}
+
+	b := &NewsletterBuilder{Store: store, Source: "rss", Nodes: []string{"Example Blog"}}
+	if kept := b.rank(ctx, got); len(kept) != 2 {
+		t.Errorf("rss channel on the feed title kept %d items, want 2", len(kept))
+	}
+	b.Nodes = []string{"other"}
+	if kept := b.rank(ctx, got); len(kept) != 0 {
+		t.Errorf("rss channel on another node kept %d items", len(kept))
+	}
+}
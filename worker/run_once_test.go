@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+)
+
+// startOneTick runs w.Start until done reports the first tick finished, then stops it.
+func startOneTick(t *testing.T, w Worker, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- w.Start(ctx) }()
+	waitFor(t, done)
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("%s: Start = %v", w.Name(), err)
+	}
+}
+
+// seed collects the V2EX fixtures into a fresh store with RunOnce.
+func seed(t *testing.T) *storage.RedisStore {
+	t.Helper()
+	store := newDeliveryTestStore(t)
+	c := &V2EXCollector{Client: mocksource.NewV2EX(filepath.Join("..", "fixtures")), Store: store, Nodes: []string{"crypto", "create"}}
+	res, err := c.RunOnce(context.Background())
+	if err != nil || res.Fetched == 0 || res.Stored == 0 || res.Failed != 0 {
+		t.Fatalf("RunOnce = %+v, %v", res, err)
+	}
+	return store
+}
+
+func TestCollectorStartMatchesRunOnce(t *testing.T) {
+	ctx := context.Background()
+	day := period.Key(period.Daily, time.Now())
+	want, err := seed(t).TopNews(ctx, "v2ex", day, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := newDeliveryTestStore(t)
+	c := &V2EXCollector{Client: mocksource.NewV2EX(filepath.Join("..", "fixtures")), Store: store, Nodes: []string{"crypto", "create"}, Interval: time.Hour}
+	startOneTick(t, c, func() bool {
+		_, ok, _ := store.GetWorkerStatus(ctx, c.Name())
+		return ok
+	})
+	got, err := store.TopNews(ctx, "v2ex", day, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Start stored %d items, RunOnce %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Item.ID != want[i].Item.ID {
+			t.Errorf("item %d: Start %s, RunOnce %s", i, got[i].Item.ID, want[i].Item.ID)
+		}
+	}
+
+	// A failing node is counted and reported, and the others are still stored.
+	c.Nodes = []string{"crypto", "missing"}
+	res, err := c.RunOnce(ctx)
+	if err == nil || !strings.Contains(err.Error(), "missing") || res.Failed != 1 || res.Stored == 0 {
+		t.Errorf("RunOnce with a missing node = %+v, %v", res, err)
+	}
+}
+
+func TestBuilderStartMatchesRunOnce(t *testing.T) {
+	ctx := context.Background()
+	builder := func(store *storage.RedisStore) *NewsletterBuilder {
+		return &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: t.TempDir(), Interval: time.Hour}
+	}
+
+	once := builder(seed(t))
+	res, err := once.RunOnce(ctx)
+	if err != nil || res.Path == "" || res.Skipped != "" || res.Candidates == 0 || res.Filtered == 0 {
+		t.Fatalf("RunOnce = %+v, %v", res, err)
+	}
+	again, err := once.RunOnce(ctx)
+	if err != nil || again.Skipped != BuildAlreadyPublished || again.Path != "" {
+		t.Errorf("second RunOnce = %+v, %v", again, err)
+	}
+
+	store := seed(t)
+	ticked := builder(store)
+	startOneTick(t, ticked, func() bool {
+		ok, _ := store.IsPublished(ctx, "ch", res.Period)
+		return ok
+	})
+	rel, err := filepath.Rel(once.OutputDir, res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, errA := os.ReadFile(res.Path)
+	b, errB := os.ReadFile(filepath.Join(ticked.OutputDir, rel))
+	if errA != nil || errB != nil {
+		t.Fatalf("read digests: %v, %v", errA, errB)
+	}
+	if !reflect.DeepEqual(dropDatetime(a), dropDatetime(b)) {
+		t.Errorf("Start wrote a different digest than RunOnce:\n%s\n---\n%s", a, b)
+	}
+
+	below := builder(seed(t))
+	below.MinItems = 100
+	if res, err := below.RunOnce(ctx); err != nil || res.Skipped != BuildBelowMinItems || res.Path != "" {
+		t.Errorf("RunOnce below min_items = %+v, %v", res, err)
+	}
+}
+
+// dropDatetime removes the minute-precision datetime line, which may differ between runs.
+func dropDatetime(b []byte) []string {
+	var out []string
+	for _, l := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(l, "datetime:") {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func TestBuilderRecordsLastError(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t)
+	w := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 5, MinItems: 1, OutputDir: t.TempDir(), Formats: []string{"pdf"}}
+	if _, err := w.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce with an unknown format succeeded")
+	}
+	st, ok, err := store.GetWorkerStatus(ctx, "builder:ch")
+	if err != nil || !ok || !strings.Contains(st.LastError, "pdf") || st.LastErrorAt == nil || st.LastRunAt.IsZero() {
+		t.Fatalf("status after failure = %+v, %v, %v", st, ok, err)
+	}
+
+	w.Formats = nil
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st, _, _ := store.GetWorkerStatus(ctx, "builder:ch"); st.LastError != "" || st.LastErrorAt != nil {
+		t.Errorf("error not cleared after a clean run: %+v", st)
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/selection"
+)
+
+// The builder's digest for a channel using every selection stage must stay byte for
+// byte what it was before item selection moved to internal/selection.
+func TestBuildSelectionGolden(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	at := time.Date(2025, 10, 24, 9, 0, 0, 0, time.UTC)
+	key := "2025-10-24"
+	for i, it := range []model.NewsItem{
+		{ID: "1", Title: "Go 1.26 released", URL: "https://go.dev/blog/go1.26", NodeName: "go", Replies: 40},
+		{ID: "2", Title: "Go 1.26 is released", URL: "https://go.dev/blog/go1.26?utm_source=v2ex", NodeName: "go", Replies: 20},
+		{ID: "3", Title: "Rust in the kernel", URL: "https://example.com/rust", NodeName: "rust", Replies: 30},
+		{ID: "4", Title: "Published last week", URL: "https://example.com/old", NodeName: "go", Replies: 25},
+		{ID: "5", Title: "An excluded thread", URL: "https://example.com/excluded", NodeName: "go", Replies: 25},
+		{ID: "6", Title: "Cooking with cast iron", URL: "https://example.com/cooking", NodeName: "cooking", Replies: 50},
+		{ID: "7", Title: "Nobody replied", URL: "https://example.com/quiet", NodeName: "go"},
+		{ID: "8", Title: "Generic type aliases", URL: "https://example.com/aliases", NodeName: "go", Replies: 4},
+		{ID: "9", Title: "Seen twice before", URL: "https://example.com/seen", NodeName: "go", Replies: 9},
+		{ID: "10", Title: "A pinned announcement", URL: "https://example.com/pinned", NodeName: "cooking", Replies: 1},
+		{ID: "11", Title: "Range over func explained", URL: "https://example.com/range", NodeName: "go", Replies: 3},
+	} {
+		it.Source = "v2ex"
+		it.CreatedAt = at.Add(-time.Duration(i+1) * time.Hour)
+		it.Content = "Content of " + it.Title + "."
+		if err := store.AddNews(ctx, "v2ex", key, it, float64(100-5*i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.MarkSkipped(ctx, "ch", "4", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddExclusion(ctx, "v2ex", selection.ExclusionEntry("https://example.com/excluded")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordAppearances(ctx, "ch", []string{"9"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PinItem(ctx, "ch", "10"); err != nil {
+		t.Fatal(err)
+	}
+	w := &NewsletterBuilder{
+		Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 5, MinItems: 1,
+		OutputDir: t.TempDir(), Formats: []string{"markdown"}, Nodes: []string{"go", "rust"},
+		NodeWeights: map[string]float64{"rust": 0.5}, RepeatPenalty: 0.9, ItemOrder: selection.OrderChronological,
+		PinLabel: "Pinned",
+	}
+	res, err := w.buildPeriod(ctx, key, at, at, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprintf("candidates: %d\nfiltered: %d\n\n%s", res.Candidates, res.Filtered, b)
+	golden := filepath.Join("testdata", "selection_builder.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("digest =\n%s\nwant\n%s", got, want)
+	}
+}
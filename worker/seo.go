@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// DigestSEO returns the SEO description and keywords of a channel's digest for a
+// period. The result is cached per period, so regenerating a digest is free. ok is
+// false when no summarizer is configured or the call fails; the frontmatter keys
+// are then left out.
+func DigestSEO(ctx context.Context, store *storage.RedisStore, summarizer ai.Summarizer, channel, period, title, summary string, items []model.NewsItem, language string) (model.SEOMeta, bool) {
+	if meta, ok, err := store.GetDigestSEO(ctx, channel, period); err != nil {
+		slog.Warn("seo: cache read failed", "err", err, "channel", channel, "period", period)
+	} else if ok {
+		return meta, true
+	}
+	if summarizer == nil {
+		return model.SEOMeta{}, false
+	}
+	meta, err := summarizer.SEOMeta(ctx, title, summary, items, language)
+	if err != nil {
+		slog.Warn("seo: generate failed", "err", err, "channel", channel, "period", period)
+		return model.SEOMeta{}, false
+	}
+	if err := store.SetDigestSEO(ctx, channel, period, meta); err != nil {
+		slog.Warn("seo: cache write failed", "err", err, "channel", channel, "period", period)
+	}
+	return meta, true
+}
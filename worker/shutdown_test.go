@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/v2ex"
+)
+
+// slowSummarizer answers after a minute unless its context ends first.
+type slowSummarizer struct {
+	ai.Summarizer
+	calls atomic.Int32
+}
+
+func (s *slowSummarizer) wait(ctx context.Context) (string, error) {
+	s.calls.Add(1)
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-time.After(time.Minute):
+		return "late", nil
+	}
+}
+
+func (s *slowSummarizer) SummarizeItem(ctx context.Context, title, content, language string) (string, error) {
+	return s.wait(ctx)
+}
+
+func (s *slowSummarizer) SummarizePost(ctx context.Context, items []model.NewsItem, language string) (string, error) {
+	return s.wait(ctx)
+}
+
+func (s *slowSummarizer) SummarizePostLikeAZenMaster(ctx context.Context, items []model.NewsItem, language string) (string, error) {
+	return s.wait(ctx)
+}
+
+// Cancelling the manager stops in-flight source requests and AI calls instead of
+// waiting out their timeouts, and the interrupted digest is not written.
+func TestShutdownCancelsInFlightCalls(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	store := seed(t)
+	collector := &V2EXCollector{Client: v2ex.NewClient(srv.URL, ""), Store: store, Nodes: []string{"crypto"}, Interval: time.Hour, ResumeRatio: -1}
+	sum := &slowSummarizer{}
+	builder := &NewsletterBuilder{Store: store, Source: "v2ex", Channel: "ch", Frequency: "daily", TopN: 3, MinItems: 1, OutputDir: t.TempDir(), Interval: time.Hour, Summarizer: sum}
+
+	mgr, err := NewManager(collector, builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return requests.Load() > 0 && sum.calls.Load() > 0 })
+
+	cancel()
+	done := make(chan error, 1)
+	go func() { done <- mgr.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("workers did not exit within 3s of cancellation")
+	}
+	if ok, _ := store.IsPublished(context.Background(), "ch", period.Key(period.Daily, time.Now())); ok {
+		t.Error("interrupted digest marked published")
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/v2ex"
+)
+
+// V2EXSource is what the V2EX collector reads topics from. *v2ex.Client implements
+// it; mocksource.V2EX serves fixture files instead.
+type V2EXSource interface {
+	TopicsByNode(ctx context.Context, node string) ([]model.NewsItem, error)
+	SearchTopics(ctx context.Context, query string) ([]model.NewsItem, error)
+	TopicDetails(ctx context.Context, topicID string) (v2ex.TopicDetail, error)
+	NodeTitle(ctx context.Context, node string) (string, error)
+}
+
+// RSSSource is what the RSS collector reads feeds from. *rss.Client implements it;
+// mocksource.RSS serves fixture files instead.
+type RSSSource interface {
+	Fetch(ctx context.Context, feedURL, node string) ([]model.NewsItem, error)
+}
+
+// LobstersSource is what the Lobste.rs collector reads stories from.
+// *lobsters.Client implements it; mocksource.Lobsters serves fixture files instead.
+type LobstersSource interface {
+	Hottest(ctx context.Context) ([]model.NewsItem, error)
+	Newest(ctx context.Context) ([]model.NewsItem, error)
+}
+
+// RedditSource is what the Reddit collector reads posts from. *reddit.Client
+// implements it; mocksource.Reddit serves fixture files instead.
+type RedditSource interface {
+	Hot(ctx context.Context, subreddit string) ([]model.NewsItem, error)
+}
+
+// GitHubTrendingSource is what the GitHub trending collector searches repositories
+// with. *githubtrending.Client implements it; mocksource.GitHub serves fixture
+// files instead.
+type GitHubTrendingSource interface {
+	Trending(ctx context.Context, language string, since time.Time) ([]model.NewsItem, error)
+}
+
+// ProductHuntSource is what the Product Hunt collector reads launches from.
+// *producthunt.Client implements it; mocksource.ProductHunt serves a fixture file
+// instead.
+type ProductHuntSource interface {
+	Posts(ctx context.Context, since time.Time) ([]model.NewsItem, error)
+}
+
+// ArxivSource is what the arXiv collector reads papers from. *arxiv.Client
+// implements it; mocksource.Arxiv serves fixture files instead.
+type ArxivSource interface {
+	Papers(ctx context.Context, category string) ([]model.NewsItem, error)
+}
+
+// MastodonSource is what the Mastodon collector reads one instance's trending links
+// from. *mastodon.Client implements it; mocksource.Mastodon serves fixture files
+// instead.
+type MastodonSource interface {
+	Host() string
+	TrendingLinks(ctx context.Context) ([]model.NewsItem, error)
+}
+
+// BlueskySource is what the Bluesky collector reads custom feeds and searches from.
+// *bluesky.Client implements it; mocksource.Bluesky serves fixture files instead.
+type BlueskySource interface {
+	Feed(ctx context.Context, feedURI, node string, limit int) ([]model.NewsItem, error)
+	SearchPosts(ctx context.Context, query, node string, limit int) ([]model.NewsItem, error)
+}
+
+// StackOverflowSource is what the Stack Overflow collector reads hot questions
+// from. *stackoverflow.Client implements it; mocksource.StackOverflow serves fixture
+// files instead.
+type StackOverflowSource interface {
+	Hot(ctx context.Context, tag string) ([]model.NewsItem, error)
+}
+
+// JSONFeedSource is what the JSON Feed collector reads feeds from. *jsonfeed.Client
+// implements it; mocksource.JSONFeed serves fixture files instead.
+type JSONFeedSource interface {
+	Fetch(ctx context.Context, feedURL, label string) ([]model.NewsItem, error)
+}
+
+// YouTubeSource is what the YouTube collector reads channel and playlist videos
+// from. *youtube.Client implements it; mocksource.YouTube serves fixture files
+// instead.
+type YouTubeSource interface {
+	ChannelVideos(ctx context.Context, channelID, node string) ([]model.NewsItem, error)
+	PlaylistVideos(ctx context.Context, playlistID, node string) ([]model.NewsItem, error)
+}
+
+// HNSource is what the Hacker News collector reads stories from. *hackernews.Client
+// implements it; mocksource.HackerNews serves fixture files instead.
+type HNSource interface {
+	ListIDs(ctx context.Context, list, etag string) (hackernews.ListResult, error)
+	Items(ctx context.Context, ids []int) ([]model.NewsItem, error)
+	TopComment(ctx context.Context, storyID int) (hackernews.Comment, bool, error)
+}
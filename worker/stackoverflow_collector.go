@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/stackoverflow"
+	"quaily-journalist/internal/storage"
+)
+
+// StackOverflowCollector polls the hot questions of a Stack Exchange site, per tag,
+// and stores them into period ZSETs under the tag; an empty tag polls the whole hot
+// list, stored under each question's first tag. The client waits out the short
+// backoffs the API asks for; once it refuses a request (quota used up, throttled),
+// the rest of the run is skipped and no tag is polled before the time it named.
+type StackOverflowCollector struct {
+	Client   StackOverflowSource
+	Store    *storage.RedisStore
+	Tags     []string
+	Interval time.Duration
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores questions; zero fields use ranking.ForSource("stackoverflow"),
+	// the Hacker News formula on question score.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each question's API JSON; see V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu          sync.Mutex // guards Tags after Start
+	buffer      storeBuffer
+	pausedUntil time.Time // no requests before this, after the API refused one
+}
+
+// SetNodes replaces the polled tags; the change applies at the next run.
+func (w *StackOverflowCollector) SetNodes(units []string) (added []string) {
+	return setUnits(&w.mu, &w.Tags, units, stringKey)
+}
+
+func (w *StackOverflowCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = time.Hour
+	}
+	if !waitForResume(ctx, w.Store, stackOverflowCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// stackOverflowCollectorName identifies the collector's persisted status record.
+const stackOverflowCollectorName = "stackoverflow-collector"
+
+func (w *StackOverflowCollector) Name() string { return stackOverflowCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Tags that fail are logged, counted, and joined
+// into the error; the others are still stored.
+func (w *StackOverflowCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, stackOverflowCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, stackOverflowCollectorName, stackoverflow.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, stackOverflowCollectorName, started, err)
+	countCollected(ctx, w.Store, stackoverflow.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *StackOverflowCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	tags := currentUnits(&w.mu, &w.Tags)
+	labels := make([]string, len(tags))
+	for i, tag := range tags {
+		labels[i] = tag
+		if tag == "" {
+			labels[i] = "(all)"
+		}
+	}
+	loop := collectLoop{source: stackoverflow.Source, unit: "tag", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		before: func(i int) error {
+			if !nowFunc(w.Now).Before(w.pausedUntil) {
+				return nil
+			}
+			skipped := len(tags) - i
+			slog.Warn("stackoverflow collector: rate limited; skipping tags", "until", w.pausedUntil, "skipped", skipped)
+			return fmt.Errorf("rate limited until %s; %d tags skipped", w.pausedUntil.Format(time.RFC3339), skipped)
+		}}
+	return loop.run(ctx, labels, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		items, err := w.Client.Hot(ctx, tags[i])
+		var limited *stackoverflow.RateLimitError
+		if errors.As(err, &limited) {
+			w.pausedUntil = limited.Until
+		}
+		return items, err
+	})
+}
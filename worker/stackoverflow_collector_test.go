@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/stackoverflow"
+)
+
+// Questions are stored under the polled tag, or their first tag for the whole hot
+// list; once the API throttles the collector, the rest of the run is skipped.
+func TestStackOverflowCollectorStopsWhenThrottled(t *testing.T) {
+	created := time.Now().Add(-3 * time.Hour).Unix()
+	var mu sync.Mutex
+	var tagged []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		tag := r.URL.Query().Get("tagged")
+		tagged = append(tagged, tag)
+		if tag == "rust" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_id": 502, "error_name": "throttle_violation", "error_message": "too many requests from this IP, more requests available in 3600 seconds"}`))
+			return
+		}
+		id := len(tagged)
+		fmt.Fprintf(w, `{"items": [{"question_id": %d, "title": "Q%d", "link": "https://stackoverflow.com/q/%d", "tags": ["python", "go"], "score": 12, "answer_count": 0, "creation_date": %d, "body": "<p>Why?</p>"}], "quota_remaining": 250}`, id, id, id, created)
+	}))
+	defer srv.Close()
+
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	c := &StackOverflowCollector{Client: stackoverflow.NewClient(srv.URL, "", ""), Store: store, Tags: []string{"", "go", "rust", "zig"}}
+	res, err := c.RunOnce(ctx)
+	if err == nil || res.Stored != 2 || res.Failed != 2 {
+		t.Fatalf("RunOnce = %+v, %v; want the hot list and go stored, rust throttled, zig skipped", res, err)
+	}
+	if !slices.Equal(tagged, []string{"", "go", "rust"}) {
+		t.Fatalf("tags asked = %q", tagged)
+	}
+	got, err := store.TopNews(ctx, "stackoverflow", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("TopNews = %v, %v", itemIDs(got), err)
+	}
+	nodes := map[string]string{}
+	for _, ws := range got {
+		nodes[ws.Item.ID] = ws.Item.NodeName
+	}
+	if nodes["1"] != "python" || nodes["2"] != "go" || got[0].Item.Content != "Why?" {
+		t.Errorf("stored nodes = %v, content %q", nodes, got[0].Item.Content)
+	}
+}
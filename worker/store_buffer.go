@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+)
+
+// DefaultStoreBufferSize bounds the items a collector holds while Redis is unreachable.
+const DefaultStoreBufferSize = 1000
+
+// Backoff of the flush retries between collector runs while items are buffered.
+const (
+	storeRetryMin = 5 * time.Second
+	storeRetryMax = time.Minute
+)
+
+// bufferedItem is a scored item waiting to be stored in its periods.
+type bufferedItem struct {
+	item    model.NewsItem
+	score   float64
+	periods []string
+}
+
+// storeBuffer holds the items a collector could not store because Redis was
+// unreachable, keyed by item ID so a later copy of an item replaces the earlier
+// one; when full, the oldest items are dropped. Once an outage is seen, the rest
+// of the run buffers without calling Redis, so a restart costs one error per run
+// instead of one per item. Only the collector's run loop uses it.
+type storeBuffer struct {
+	source     string
+	max        int  // 0 uses DefaultStoreBufferSize
+	archiveRaw bool // archive each stored item's source payload (Item.Raw)
+
+	items   map[string]bufferedItem
+	order   []string // IDs, oldest first
+	dropped int      // items dropped while full since the buffer was last empty
+	outage  bool     // Redis was unreachable at the last attempt
+	lastErr error
+}
+
+func (b *storeBuffer) len() int { return len(b.order) }
+
+// add buffers it, replacing a buffered copy of the same item.
+func (b *storeBuffer) add(it bufferedItem) {
+	if b.items == nil {
+		b.items = map[string]bufferedItem{}
+	}
+	if _, ok := b.items[it.item.ID]; ok {
+		b.items[it.item.ID] = it
+		return
+	}
+	limit := b.max
+	if limit <= 0 {
+		limit = DefaultStoreBufferSize
+	}
+	for len(b.order) >= limit {
+		delete(b.items, b.order[0])
+		b.order = b.order[1:]
+		b.dropped++
+	}
+	b.items[it.item.ID] = it
+	b.order = append(b.order, it.item.ID)
+}
+
+// store stores an item in every period and announces it, or buffers it during an
+// outage. It reports whether the item was stored now; the error is a store error
+// other than Redis being unreachable, for which the item is not buffered.
+func (b *storeBuffer) store(ctx context.Context, store *storage.RedisStore, it bufferedItem) (bool, error) {
+	if !b.archiveRaw {
+		it.item.Raw = nil
+	}
+	if b.outage {
+		b.add(it)
+		return false, nil
+	}
+	if err := b.put(ctx, store, it); err != nil {
+		if !storage.IsUnavailable(err) {
+			return false, err
+		}
+		b.outage, b.lastErr = true, err
+		b.add(it)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (b *storeBuffer) put(ctx context.Context, store *storage.RedisStore, it bufferedItem) error {
+	for _, period := range it.periods {
+		if err := store.AddNews(ctx, b.source, period, it.item, it.score); err != nil {
+			return err
+		}
+	}
+	if b.archiveRaw && len(it.item.Raw) > 0 {
+		// Best-effort debugging aid: a failure never holds back the item itself.
+		if err := store.SetRaw(ctx, b.source, it.item.ID, it.item.Raw); errors.Is(err, storage.ErrRawTooLarge) {
+			slog.Debug("collector: raw payload not archived", "source", b.source, "id", it.item.ID, "error", err)
+		} else if err != nil {
+			slog.Warn("collector: archive raw payload failed", "source", b.source, "id", it.item.ID, "error", err)
+		}
+	}
+	publishItemEvent(ctx, store, b.source, it.periods[0], it.item, it.score)
+	return nil
+}
+
+// flush stores the buffered items, oldest first, and returns how many were
+// stored. It stops at the first sign that Redis is still unreachable and returns
+// that error; items failing otherwise are logged and dropped.
+func (b *storeBuffer) flush(ctx context.Context, store *storage.RedisStore) (int, error) {
+	stored := 0
+	for len(b.order) > 0 {
+		id := b.order[0]
+		if err := b.put(ctx, store, b.items[id]); err != nil {
+			if storage.IsUnavailable(err) {
+				b.outage, b.lastErr = true, err
+				return stored, err
+			}
+			slog.Error("collector: store buffered item failed", "source", b.source, "id", id, "error", err)
+		} else {
+			stored++
+		}
+		delete(b.items, id)
+		b.order = b.order[1:]
+	}
+	if stored > 0 {
+		slog.Info("collector: stored buffered items", "source", b.source, "stored", stored, "dropped", b.dropped)
+	}
+	b.outage, b.lastErr, b.dropped = false, nil, 0
+	return stored, nil
+}
+
+// err summarizes the buffered items as the run's single outage error; nil when
+// nothing is buffered.
+func (b *storeBuffer) err() error {
+	if b.len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("redis unavailable: %d items buffered, %d dropped: %w", b.len(), b.dropped, b.lastErr)
+}
+
+// wait blocks until tick fires, meanwhile retrying to flush buffered items with
+// capped exponential backoff. It returns false once ctx is done.
+func (b *storeBuffer) wait(ctx context.Context, store *storage.RedisStore, tick <-chan time.Time) bool {
+	delay := storeRetryMin
+	for {
+		var retry <-chan time.Time
+		if b.len() > 0 {
+			retry = time.After(delay)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-tick:
+			return true
+		case <-retry:
+			if _, err := b.flush(ctx, store); err != nil {
+				slog.Debug("collector: redis still unavailable", "source", b.source, "buffered", b.len(), "retry_in", delay, "error", err)
+				if delay *= 2; delay > storeRetryMax {
+					delay = storeRetryMax
+				}
+			}
+		}
+	}
+}
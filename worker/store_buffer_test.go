@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// failFirst is a redis hook failing the first n commands as if the server were down.
+type failFirst struct{ n, calls atomic.Int32 }
+
+func (h *failFirst) fail() error {
+	h.calls.Add(1)
+	if h.n.Add(-1) < 0 {
+		return nil
+	}
+	return &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+}
+
+func (h *failFirst) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failFirst) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.fail(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *failFirst) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.fail(); err != nil {
+			for _, c := range cmds {
+				c.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestCollectorBuffersDuringRedisOutage(t *testing.T) {
+	ctx := context.Background()
+	day := period.Key(period.Daily, time.Now())
+	want, err := seed(t).TopNews(ctx, "v2ex", day, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	hook := &failFirst{}
+	hook.n.Store(2)
+	rdb.AddHook(hook)
+	store := storage.NewRedisStore(rdb)
+	c := &V2EXCollector{Client: mocksource.NewV2EX(filepath.Join("..", "fixtures")), Store: store, Nodes: []string{"crypto", "create"}}
+
+	// The first item finds Redis down; the rest are buffered without calling it,
+	// and the run reports one error for all of them.
+	res, err := c.RunOnce(ctx)
+	if err == nil || !strings.Contains(err.Error(), "redis unavailable") || res.Stored != 0 || res.Buffered != len(want) {
+		t.Fatalf("RunOnce during outage = %+v, %v; want %d buffered", res, err, len(want))
+	}
+	if n := hook.calls.Load(); n != 3 { // the first AddNews and the run record with its error
+		t.Errorf("redis commands during outage = %d, want 3", n)
+	}
+
+	// Once Redis is back, the next run stores the buffered items first.
+	res, err = c.RunOnce(ctx)
+	if err != nil || res.Buffered != 0 || res.Stored < len(want) {
+		t.Fatalf("RunOnce after outage = %+v, %v", res, err)
+	}
+	got, err := store.TopNews(ctx, "v2ex", day, 100)
+	if err != nil || len(got) != len(want) {
+		t.Fatalf("stored %d items, %v; want %d", len(got), err, len(want))
+	}
+}
+
+func TestStoreBufferDedupAndBound(t *testing.T) {
+	b := storeBuffer{source: "v2ex", max: 2}
+	for _, id := range []string{"1", "2", "1", "3"} {
+		b.add(bufferedItem{item: model.NewsItem{ID: id, Title: "t" + id}, periods: []string{"2025-01-02"}})
+	}
+	if b.len() != 2 || b.dropped != 1 || strings.Join(b.order, ",") != "2,3" {
+		t.Errorf("buffer = %v, dropped %d; want [2 3], 1 dropped", b.order, b.dropped)
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
+)
+
+const (
+	// SummaryFailureLimit is how many AI summaries of an item may fail in a row before
+	// the item gets its first-sentence description instead, until SummaryFailureCooldown
+	// has passed since the latest failure.
+	SummaryFailureLimit    = 3
+	SummaryFailureCooldown = 24 * time.Hour
+)
+
+// SummarizeItem returns the AI description of an item, tracking failures per item in
+// store so content that keeps failing (e.g., timing out) stops costing a request per
+// run. While an item is backed off, its first sentence is returned with backedOff set.
+// A nil store summarizes without tracking.
+func SummarizeItem(ctx context.Context, store *storage.RedisStore, summarizer ai.Summarizer, it model.NewsItem, content, language string) (desc string, backedOff bool, err error) {
+	sum, backedOff, err := summarizeTracked(ctx, store, it, content, func() (model.ItemSummary, error) {
+		desc, err := summarizer.SummarizeItem(ctx, it.Title, content, language)
+		return model.ItemSummary{Description: desc}, err
+	})
+	return sum.Description, backedOff, err
+}
+
+// SummarizeItemWithTakeaway is SummarizeItem for channels that render a "why it
+// matters" line: the description and the takeaway come from one request. The pair
+// is cached per item and language, so an item picked again (a regeneration, or the
+// weekly digest after the daily one) costs no request. A backed-off item gets no
+// takeaway.
+func SummarizeItemWithTakeaway(ctx context.Context, store *storage.RedisStore, summarizer ai.Summarizer, it model.NewsItem, content, language string) (sum model.ItemSummary, backedOff bool, err error) {
+	if store != nil {
+		if cached, ok, err := store.GetItemSummary(ctx, it.Source, it.ID, language); err != nil {
+			slog.Warn("summary: cache read failed", "source", it.Source, "id", it.ID, "err", err)
+		} else if ok {
+			return cached, false, nil
+		}
+	}
+	sum, backedOff, err = summarizeTracked(ctx, store, it, content, func() (model.ItemSummary, error) {
+		return summarizer.SummarizeItemWithTakeaway(ctx, it.Title, content, language)
+	})
+	if store != nil && err == nil && !backedOff && sum.Description != "" {
+		if err := store.SetItemSummary(ctx, it.Source, it.ID, language, sum, 0); err != nil {
+			slog.Warn("summary: cache write failed", "source", it.Source, "id", it.ID, "err", err)
+		}
+	}
+	return sum, backedOff, err
+}
+
+// summarizeTracked runs summarize unless the item is backed off, recording its
+// failures and clearing them on success.
+func summarizeTracked(ctx context.Context, store *storage.RedisStore, it model.NewsItem, content string, summarize func() (model.ItemSummary, error)) (sum model.ItemSummary, backedOff bool, err error) {
+	source := it.Source
+	if store != nil {
+		n, err := store.SummaryFailures(ctx, source, it.ID)
+		if err != nil {
+			slog.Warn("summary: read failure count failed", "source", source, "id", it.ID, "err", err)
+		} else if n >= SummaryFailureLimit {
+			slog.Debug("summary: backing off item", "source", source, "id", it.ID, "failures", n)
+			return model.ItemSummary{Description: textclean.FirstSentence(content)}, true, nil
+		}
+	}
+	sum, err = summarize()
+	if store == nil {
+		return sum, false, err
+	}
+	if err != nil {
+		n, rerr := store.RecordSummaryFailure(ctx, source, it.ID, SummaryFailureCooldown)
+		if rerr != nil {
+			slog.Warn("summary: record failure failed", "source", source, "id", it.ID, "err", rerr)
+		} else if n == SummaryFailureLimit {
+			slog.Warn("summary: item keeps failing; using its first sentence until the cooldown passes", "source", source, "id", it.ID, "title", it.Title, "failures", n, "cooldown", SummaryFailureCooldown)
+		}
+		return sum, false, err
+	}
+	if err := store.ClearSummaryFailures(ctx, source, it.ID); err != nil {
+		slog.Warn("summary: clear failure count failed", "source", source, "id", it.ID, "err", err)
+	}
+	return sum, false, nil
+}
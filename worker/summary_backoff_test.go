@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+)
+
+// itemSummarizer fails SummarizeItem for the title failTitle and counts calls per title.
+type itemSummarizer struct {
+	ai.Summarizer
+	failTitle string
+	calls     map[string]int
+}
+
+func (s *itemSummarizer) SummarizeItem(ctx context.Context, title, content, language string) (string, error) {
+	s.calls[title]++
+	if title == s.failTitle {
+		return "", errors.New("context deadline exceeded")
+	}
+	return "AI: " + title, nil
+}
+
+func TestSummarizeItemBacksOffFailingItem(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	s := &itemSummarizer{failTitle: "Minified JS", calls: map[string]int{}}
+	bad := model.NewsItem{ID: "13", Source: "v2ex", Title: "Minified JS"}
+	good := model.NewsItem{ID: "14", Source: "v2ex", Title: "Fine"}
+	content := "First sentence here. Then a wall of minified code."
+
+	for i := 0; i < SummaryFailureLimit+2; i++ {
+		desc, off, err := SummarizeItem(ctx, store, s, bad, content, "English")
+		if i < SummaryFailureLimit {
+			if err == nil || off {
+				t.Fatalf("run %d: desc %q, backed off %v, err %v; want the AI error", i, desc, off, err)
+			}
+			continue
+		}
+		if err != nil || !off || desc != "First sentence here." {
+			t.Fatalf("run %d: desc %q, backed off %v, err %v; want the first sentence", i, desc, off, err)
+		}
+		if desc, off, err := SummarizeItem(ctx, store, s, good, content, "English"); err != nil || off || desc != "AI: Fine" {
+			t.Fatalf("run %d: other item got %q, %v, %v", i, desc, off, err)
+		}
+	}
+	if s.calls["Minified JS"] != SummaryFailureLimit {
+		t.Errorf("failing item summarized %d times, want %d", s.calls["Minified JS"], SummaryFailureLimit)
+	}
+	if n, _ := store.SummaryFailures(ctx, "v2ex", "13"); n != SummaryFailureLimit {
+		t.Errorf("failure count = %d", n)
+	}
+
+	// A success resets the count.
+	s.failTitle = ""
+	if err := store.ClearSummaryFailures(ctx, "v2ex", "13"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RecordSummaryFailure(ctx, "v2ex", "13", SummaryFailureCooldown); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SummarizeItem(ctx, store, s, bad, content, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := store.SummaryFailures(ctx, "v2ex", "13"); n != 0 {
+		t.Errorf("failure count after a success = %d", n)
+	}
+}
+
+func (s *itemSummarizer) SummarizeItemWithTakeaway(ctx context.Context, title, content, language string) (model.ItemSummary, error) {
+	s.calls[title]++
+	if title == s.failTitle {
+		return model.ItemSummary{}, errors.New("context deadline exceeded")
+	}
+	return model.ItemSummary{Description: "AI: " + title, WhyItMatters: "Why: " + title}, nil
+}
+
+// The description and takeaway are cached together per language; a failed request
+// caches nothing.
+func TestSummarizeItemWithTakeawayCaches(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	s := &itemSummarizer{failTitle: "Flaky", calls: map[string]int{}}
+	it := model.NewsItem{ID: "21", Source: "hackernews", Title: "Go 1.25"}
+
+	for i := 0; i < 2; i++ {
+		sum, off, err := SummarizeItemWithTakeaway(ctx, store, s, it, "Content.", "English")
+		if err != nil || off || sum != (model.ItemSummary{Description: "AI: Go 1.25", WhyItMatters: "Why: Go 1.25"}) {
+			t.Fatalf("run %d = %+v, %v, %v", i, sum, off, err)
+		}
+	}
+	if s.calls["Go 1.25"] != 1 {
+		t.Errorf("summarized %d times, want 1 (then cached)", s.calls["Go 1.25"])
+	}
+	if _, _, err := SummarizeItemWithTakeaway(ctx, store, s, it, "Content.", "中文"); err != nil || s.calls["Go 1.25"] != 2 {
+		t.Errorf("another language reused the cache: %d calls, %v", s.calls["Go 1.25"], err)
+	}
+
+	flaky := model.NewsItem{ID: "22", Source: "hackernews", Title: "Flaky"}
+	if _, _, err := SummarizeItemWithTakeaway(ctx, store, s, flaky, "Content.", "English"); err == nil {
+		t.Fatal("want the AI error")
+	}
+	if _, ok, _ := store.GetItemSummary(ctx, "hackernews", "22", "English"); ok {
+		t.Error("failed summary cached")
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"quaily-journalist/internal/markdown"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/telegram"
+)
+
+// TelegramTarget posts a channel's digests to a Telegram chat, converted from
+// the Markdown output and split into messages on item boundaries.
+type TelegramTarget struct {
+	Client *telegram.Client
+	ChatID string
+	Format string // see telegram.CheckFormat
+}
+
+// Send posts the messages of t that were not sent yet, advancing t.Progress after each.
+func (tt TelegramTarget) Send(ctx context.Context, t *storage.DeliveryTask) error {
+	p := t.Paths[newsletter.FormatMarkdown]
+	if p == "" {
+		return fmt.Errorf("telegram: digest %s has no markdown output", t.Slug)
+	}
+	doc, err := markdown.ParseFile(p)
+	if err != nil {
+		return fmt.Errorf("telegram: read markdown: %w", err)
+	}
+	msgs := telegram.Messages(t.Title, doc.Body, tt.Format)
+	for i := t.Progress; i < len(msgs); i++ {
+		if err := tt.Client.SendMessage(ctx, tt.ChatID, msgs[i], telegram.ParseMode(tt.Format)); err != nil {
+			return fmt.Errorf("telegram: message %d/%d: %w", i+1, len(msgs), err)
+		}
+		t.Progress = i + 1
+	}
+	return nil
+}
+
+// recordTelegramSent notes a finished Telegram delivery in the digest's publish metadata.
+func (w *DeliveryReconciler) recordTelegramSent(ctx context.Context, t storage.DeliveryTask) {
+	if t.Period == "" {
+		return
+	}
+	meta, ok, err := w.Store.GetPublishMeta(ctx, t.Channel, t.Period)
+	if err != nil || !ok {
+		slog.Warn("deliveries: publish metadata unavailable; telegram send not recorded", "channel", t.Channel, "period", t.Period, "err", err)
+		return
+	}
+	sent := nowFunc(w.Now).UTC().Truncate(time.Second)
+	meta.TelegramSentAt = &sent
+	if err := w.Store.SetPublishMeta(ctx, t.Channel, t.Period, meta); err != nil {
+		slog.Warn("deliveries: save publish metadata failed", "channel", t.Channel, "period", t.Period, "err", err)
+	}
+}
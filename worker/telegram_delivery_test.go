@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/telegram"
+)
+
+func TestTelegramDeliveryResumesAndRecordsMeta(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		texts []string
+		calls int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 2 { // the second message fails once
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"ok":false,"description":"Too Many Requests: retry after 1"}`)
+			return
+		}
+		texts = append(texts, body.Text)
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+
+	items := make([]newsletter.Item, 40)
+	for i := range items {
+		items[i] = newsletter.Item{Title: fmt.Sprintf("Item %d", i), URL: "https://example.com", Description: strings.Repeat("words ", 40), NodeName: "go", Replies: 1}
+	}
+	out, err := newsletter.Render(newsletter.Data{Title: "Daily", Slug: "daily-20251024", Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "daily-20251024.md")
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newDeliveryTestStore(t)
+	ctx := context.Background()
+	const period = "daily:20251024"
+	if err := store.SetPublishMeta(ctx, "ch", period, storage.PublishMeta{Path: path, Slug: "daily-20251024"}); err != nil {
+		t.Fatal(err)
+	}
+	rec := &DeliveryReconciler{
+		Store:    store,
+		Telegram: map[string]TelegramTarget{"ch": {Client: telegram.New(srv.URL, "tok"), ChatID: "@ch"}},
+	}
+	task := storage.DeliveryTask{Channel: "ch", Slug: "daily-20251024", Target: storage.TargetTelegram, Title: "Daily", Period: period,
+		Paths: map[string]string{newsletter.FormatMarkdown: path}, State: storage.DeliveryPending}
+
+	task = rec.Deliver(ctx, task)
+	if task.State != storage.DeliveryPending || task.Progress != 1 {
+		t.Fatalf("after partial failure: state=%s progress=%d", task.State, task.Progress)
+	}
+	task = rec.Deliver(ctx, task)
+	if task.State != storage.DeliveryDone {
+		t.Fatalf("after retry: %+v", task)
+	}
+	want := telegram.Messages("Daily", strings.SplitN(out, "---\n", 3)[2], "")
+	if len(want) < 3 || len(texts) != len(want) {
+		t.Fatalf("sent %d messages, want %d (each once)", len(texts), len(want))
+	}
+	meta, _, _ := store.GetPublishMeta(ctx, "ch", period)
+	if meta.TelegramSentAt == nil || time.Since(*meta.TelegramSentAt) > time.Minute {
+		t.Errorf("publish metadata not updated: %+v", meta)
+	}
+}
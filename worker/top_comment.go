@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"quaily-journalist/internal/ai"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/newsletter"
+	"quaily-journalist/internal/storage"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// HighlightMaxRunes caps a community highlight; longer comments are summarized
+	// when a summarizer is configured and truncated otherwise.
+	HighlightMaxRunes = 280
+	// topCommentConcurrency bounds the items whose comments are fetched at once.
+	topCommentConcurrency = 4
+	// Comment picks are cached per item; a story without comments is checked again sooner.
+	topCommentTTL     = 7 * 24 * time.Hour
+	noTopCommentTTL   = 6 * time.Hour
+	topCommentTimeout = 20 * time.Second
+)
+
+// TopComments picks the community highlight of each Hacker News item: its top-ranked
+// top-level comment, shortened to HighlightMaxRunes. Picks are cached in the store per
+// item, so a regenerated digest reuses them. Items without a usable comment, or whose
+// lookup failed, are absent from the result, which is keyed by item ID.
+func TopComments(ctx context.Context, store *storage.RedisStore, src HNSource, summarizer ai.Summarizer, items []model.NewsItem, language string) map[string]model.CommentHighlight {
+	out := map[string]model.CommentHighlight{}
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(topCommentConcurrency)
+	for _, it := range items {
+		it := it
+		g.Go(func() error {
+			if h, ok := topComment(gctx, store, src, summarizer, it, language); ok {
+				mu.Lock()
+				out[it.ID] = h
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return out
+}
+
+func topComment(ctx context.Context, store *storage.RedisStore, src HNSource, summarizer ai.Summarizer, it model.NewsItem, language string) (model.CommentHighlight, bool) {
+	if cached, ok, err := store.GetTopComment(ctx, "hackernews", it.ID); err != nil {
+		slog.Warn("top comment: cache read failed", "id", it.ID, "error", err)
+	} else if ok {
+		return cached, cached.Text != ""
+	}
+	id, err := strconv.Atoi(it.ID)
+	if err != nil {
+		return model.CommentHighlight{}, false
+	}
+	ctxReq, cancel := context.WithTimeout(ctx, topCommentTimeout)
+	defer cancel()
+	c, ok, err := src.TopComment(ctxReq, id)
+	if err != nil {
+		slog.Warn("top comment: fetch failed", "id", it.ID, "error", err)
+		return model.CommentHighlight{}, false
+	}
+	var h model.CommentHighlight
+	ttl := noTopCommentTTL
+	if ok {
+		h = model.CommentHighlight{
+			ID:     strconv.Itoa(c.ID),
+			Author: c.Author,
+			Text:   shortenHighlight(ctx, summarizer, it.Title, c.Text, language),
+			URL:    c.URL,
+		}
+		ttl = topCommentTTL
+	}
+	if err := store.SetTopComment(ctx, "hackernews", it.ID, h, ttl); err != nil {
+		slog.Warn("top comment: cache write failed", "id", it.ID, "error", err)
+	}
+	return h, h.Text != ""
+}
+
+// shortenHighlight flattens a comment to one paragraph and fits it in
+// HighlightMaxRunes, preferring an AI summary over truncation.
+func shortenHighlight(ctx context.Context, summarizer ai.Summarizer, title, text, language string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= HighlightMaxRunes {
+		return text
+	}
+	if summarizer != nil {
+		s, err := summarizer.SummarizeItem(ctx, "Comment on: "+title, text, language)
+		s = strings.Join(strings.Fields(s), " ")
+		if err != nil {
+			slog.Warn("top comment: summarize failed; truncating", "title", title, "error", err)
+		} else if s != "" && utf8.RuneCountInString(s) <= HighlightMaxRunes {
+			return s
+		}
+	}
+	return truncateWords(text, HighlightMaxRunes)
+}
+
+// truncateWords cuts s to at most max runes including the ellipsis, at a word
+// boundary when one is near the end.
+func truncateWords(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	cut := string(r[:max-1])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)*3/4 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
+
+// NewsletterHighlight returns the template highlight of an item, or nil.
+func NewsletterHighlight(highlights map[string]model.CommentHighlight, id string) *newsletter.Highlight {
+	h, ok := highlights[id]
+	if !ok {
+		return nil
+	}
+	return &newsletter.Highlight{Text: h.Text, Author: h.Author, URL: h.URL}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"quaily-journalist/internal/hackernews"
+	"quaily-journalist/internal/model"
+)
+
+// commentSource serves one comment per story ID; other HNSource methods are not used here.
+type commentSource struct {
+	HNSource
+	comments map[int]hackernews.Comment
+	calls    atomic.Int32
+}
+
+func (s *commentSource) TopComment(ctx context.Context, storyID int) (hackernews.Comment, bool, error) {
+	s.calls.Add(1)
+	c, ok := s.comments[storyID]
+	return c, ok, nil
+}
+
+func TestTopCommentsCachesAndShortens(t *testing.T) {
+	ctx := context.Background()
+	store := newDeliveryTestStore(t)
+	long := strings.Repeat("word ", 100)
+	src := &commentSource{comments: map[int]hackernews.Comment{
+		1: {ID: 11, Author: "pg", Text: "Short and\n\nsweet.", URL: "https://news.ycombinator.com/item?id=11"},
+		2: {ID: 22, Author: "dang", Text: long, URL: "https://news.ycombinator.com/item?id=22"},
+	}}
+	items := []model.NewsItem{{ID: "1", Title: "A"}, {ID: "2", Title: "B"}, {ID: "3", Title: "No comments"}}
+
+	got := TopComments(ctx, store, src, nil, items, "English")
+	if len(got) != 2 {
+		t.Fatalf("got %d highlights, want 2: %+v", len(got), got)
+	}
+	if h := got["1"]; h.Text != "Short and sweet." || h.Author != "pg" || h.ID != "11" {
+		t.Errorf("highlight 1 = %+v", h)
+	}
+	if h := got["2"]; utf8.RuneCountInString(h.Text) > HighlightMaxRunes || !strings.HasSuffix(h.Text, "word…") {
+		t.Errorf("highlight 2 not truncated at a word: %q", h.Text)
+	}
+	if n := src.calls.Load(); n != 3 {
+		t.Fatalf("fetches = %d, want 3", n)
+	}
+
+	// A second run is served from the cache, including the item without comments.
+	again := TopComments(ctx, store, src, nil, items, "English")
+	if n := src.calls.Load(); n != 3 || len(again) != 2 || again["1"] != got["1"] {
+		t.Errorf("second run: fetches=%d highlights=%+v", n, again)
+	}
+	if NewsletterHighlight(again, "3") != nil || NewsletterHighlight(again, "1").Author != "pg" {
+		t.Error("NewsletterHighlight mismatch")
+	}
+}
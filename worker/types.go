@@ -4,4 +4,37 @@ import "context"
 
 type Worker interface {
 	Start(ctx context.Context) error
+	// Name identifies the worker within a Manager; it must be unique.
+	Name() string
 }
+
+// CollectResult counts what one collector pass did.
+type CollectResult struct {
+	Fetched int `json:"fetched"` // items returned by the source
+	Stored  int `json:"stored"`  // items that scored above zero and were stored
+	Failed  int `json:"failed"`  // nodes or lists that could not be fetched
+	// Buffered counts the items held in memory because Redis was unreachable; the
+	// collector stores them once it is back.
+	Buffered int `json:"buffered,omitempty"`
+}
+
+// BuildResult describes one builder pass. Path is empty unless a digest was written.
+type BuildResult struct {
+	Period     string `json:"period"`
+	Candidates int    `json:"candidates"`        // items read from the period
+	Filtered   int    `json:"filtered"`          // items left after every filter
+	Skipped    string `json:"skipped,omitempty"` // why nothing was written; see the Build* reasons
+	Light      bool   `json:"light,omitempty"`   // written below MinItems as a light edition
+	// PublishError is why the written digest could not be pushed to Quaily.
+	PublishError string            `json:"publish_error,omitempty"`
+	Path         string            `json:"path,omitempty"`  // file of the first format
+	Paths        map[string]string `json:"paths,omitempty"` // file per format
+}
+
+// Reasons a builder pass writes nothing.
+const (
+	BuildAlreadyPublished = "already_published"
+	BuildBelowMinItems    = "below_min_items"
+	BuildPeriodOpen       = "period_open" // a derived digest waits for its week to end
+	BuildQuietHours       = "quiet_hours" // publishing waits for the channel's quiet hours to end
+)
@@ -2,97 +2,257 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/ranking"
 	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
 	"quaily-journalist/internal/v2ex"
 )
 
 type V2EXCollector struct {
-	Client   *v2ex.Client
-	Store    *storage.RedisStore
-	Nodes    []string
-	Interval time.Duration
+	Client          V2EXSource
+	Store           *storage.RedisStore
+	Nodes           []string // initial nodes; use SetNodes once running
+	Interval        time.Duration
+	MaxContentRunes int // content budget after cleaning; 0 uses textclean.DefaultMaxRunes
+	// IncludeSupplements appends topic supplements ("附言") to the content; one extra
+	// API v2 call per scored topic, so the client needs a token.
+	IncludeSupplements bool
+	// IncludePoints stores each topic's likes (thanks) as its points before it is
+	// scored, so a ranking blend can use them; one API v2 call per fetched topic,
+	// shared with the supplements, so the client needs a token.
+	IncludePoints bool
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores topics; zero fields use ranking.ForSource("v2ex").
+	Ranking ranking.Scorer
+	// Nodes of the form "q:<query>" are searched across all nodes instead. A query is
+	// searched at most once per SearchInterval (0 uses DefaultSearchInterval); runs
+	// in between reuse its last results. Searches within a run are SearchDelay apart
+	// (0 uses DefaultSearchDelay, negative disables).
+	SearchInterval time.Duration
+	SearchDelay    time.Duration
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each item's source payload for storage.RawTTL (see
+	// RedisStore.SetRaw), for inspecting odd items with "item show --raw".
+	ArchiveRaw bool
+
+	mu       sync.Mutex // guards Nodes after Start, and searches
+	searches map[string]searchResult
+	buffer   storeBuffer
+}
+
+// Search rate limits of the V2EX collector; see V2EXCollector.SearchInterval.
+const (
+	DefaultSearchInterval = time.Hour
+	DefaultSearchDelay    = 2 * time.Second
+)
+
+// searchResult is the last result of a search query.
+type searchResult struct {
+	at    time.Time
+	items []model.NewsItem
+}
+
+// SetNodes replaces the polled nodes; the change applies at the next run.
+// It returns the nodes that were not polled before.
+func (w *V2EXCollector) SetNodes(nodes []string) (added []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	added = newEntries(w.Nodes, nodes)
+	w.Nodes = append([]string(nil), nodes...)
+	return added
+}
+
+func (w *V2EXCollector) currentNodes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.Nodes...)
 }
 
 func (w *V2EXCollector) Start(ctx context.Context) error {
 	if w.Interval <= 0 {
 		w.Interval = 60 * time.Minute
 	}
+	if !waitForResume(ctx, w.Store, v2exCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
 	t := time.NewTicker(w.Interval)
 	defer t.Stop()
 
 	// initial run
-	w.runOnce(ctx)
+	w.RunOnce(ctx)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-t.C:
-			w.runOnce(ctx)
-		}
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// v2exCollectorName identifies the collector's persisted status record.
+const v2exCollectorName = "v2ex-collector"
+
+func (w *V2EXCollector) Name() string { return v2exCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Nodes that fail are logged, counted, and joined into the error;
+// the others are still stored.
+func (w *V2EXCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, v2exCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, v2exCollectorName, "v2ex", started); err != nil {
+		return CollectResult{}, err
 	}
+	res, err := w.collect(ctx)
+	recordOutcome(ctx, w.Store, v2exCollectorName, started, err)
+	countCollected(ctx, w.Store, "v2ex", started, res.Stored)
+	return res, err
 }
 
-func (w *V2EXCollector) runOnce(ctx context.Context) {
+func (w *V2EXCollector) collect(ctx context.Context) (CollectResult, error) {
 	// Collector writes into both daily and weekly periods for simplicity.
-	day := periodKey("daily", time.Now().UTC())
-	week := periodKey("weekly", time.Now().UTC())
-	for _, node := range w.Nodes {
-		items, err := w.Client.TopicsByNode(ctx, node)
+	day := period.Key(period.Daily, time.Now().UTC())
+	week := period.Key(period.Weekly, time.Now().UTC())
+	scorer := ranking.ForSource("v2ex").Merge(w.Ranking)
+	var res CollectResult
+	var errs []error
+	w.buffer.source, w.buffer.max, w.buffer.archiveRaw = "v2ex", w.BufferSize, w.ArchiveRaw
+	res.Stored, _ = w.buffer.flush(ctx, w.Store)
+	searched := 0
+	for _, node := range w.currentNodes() {
+		items, err := w.fetch(ctx, node, &searched)
 		if err != nil {
 			slog.Error("run v2ex collector failed.", "node", node, "error", err)
+			res.Failed++
+			errs = append(errs, fmt.Errorf("node %s: %w", node, err))
 			continue
 		}
+		res.Fetched += len(items)
 		for _, it := range items {
-			score := popularityScore(it)
+			var detail *v2ex.TopicDetail
+			if w.IncludePoints {
+				if detail = w.topicDetails(ctx, it.ID); detail != nil {
+					it.Points = detail.Likes
+				}
+			}
+			score := scorer.Score(it, time.Now())
 			if score <= 0 {
 				continue // ignore posts with no replies or low score
 			}
-			if err := w.Store.AddNews(ctx, "v2ex", day, it, score); err != nil {
-				slog.Error("run v2ex collector store error.", "id", it.ID, "error", err)
+			// Strip image blobs/markup and cap size before storage and summarization.
+			it.Content = textclean.Clean(it.Content, textclean.Options{MaxRunes: w.MaxContentRunes})
+			if w.IncludeSupplements {
+				if detail == nil && !w.IncludePoints {
+					detail = w.topicDetails(ctx, it.ID)
+				}
+				if detail != nil {
+					it.Content = v2ex.AppendSupplements(it.Content, detail.Supplements)
+				}
 			}
-			if err := w.Store.AddNews(ctx, "v2ex", week, it, score); err != nil {
+			stored, err := w.buffer.store(ctx, w.Store, bufferedItem{item: it, score: score, periods: []string{day, week}})
+			if err != nil {
 				slog.Error("run v2ex collector store error.", "id", it.ID, "error", err)
+			} else if stored {
+				res.Stored++
 			}
 		}
 		slog.Info("v2ex collector: completed for node", "node", node, "stored", len(items), "periods", []string{day, week})
 	}
+	res.Buffered = w.buffer.len()
+	if err := w.buffer.err(); err != nil {
+		slog.Error("v2ex collector: items held until redis is back", "buffered", res.Buffered, "error", err)
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
+
+// topicDetails fetches a topic's API v2 fields; nil when that fails, which is
+// logged and leaves the item as the node listing returned it.
+func (w *V2EXCollector) topicDetails(ctx context.Context, id string) *v2ex.TopicDetail {
+	d, err := w.Client.TopicDetails(ctx, id)
+	if err != nil {
+		slog.Warn("v2ex collector: fetch topic details failed", "id", id, "error", err)
+		return nil
+	}
+	return &d
 }
 
-func popularityScore(it model.NewsItem) float64 {
-	// Ignore posts with no replies
-	if it.Replies <= 0 {
-		return 0
+// fetch returns the topics of node, or the search results of a "q:" node within
+// the collector's search rate limits; searched counts the searches of this run.
+func (w *V2EXCollector) fetch(ctx context.Context, node string, searched *int) ([]model.NewsItem, error) {
+	query, ok := v2ex.SearchQuery(node)
+	if !ok {
+		return w.Client.TopicsByNode(ctx, node)
+	}
+	interval := w.SearchInterval
+	if interval <= 0 {
+		interval = DefaultSearchInterval
+	}
+	now := nowFunc(w.Now)
+	w.mu.Lock()
+	last, cached := w.searches[query]
+	w.mu.Unlock()
+	if cached && now.Sub(last.at) < interval {
+		slog.Debug("v2ex collector: reusing search results", "query", query, "searched_at", last.at)
+		return last.items, nil
+	}
+	delay := w.SearchDelay
+	if delay == 0 {
+		delay = DefaultSearchDelay
+	}
+	if *searched > 0 && delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 	}
-	count := it.Replies // use replies as count
-	// hours since published
-	diff := time.Since(it.CreatedAt).Hours()
-	if diff < 0 {
-		diff = 0
+	*searched++
+	items, err := w.Client.SearchTopics(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	// Hacker News-like score:
-	// Score = (count-1) / (diff+2)^1.8
-	score := float64(count-1) / math.Pow(diff+2, 1.8)
-	if math.IsNaN(score) || score < 0 {
-		score = 0
+	w.mu.Lock()
+	if w.searches == nil {
+		w.searches = map[string]searchResult{}
 	}
-	return score
+	w.searches[query] = searchResult{at: now, items: items}
+	w.mu.Unlock()
+	w.cacheNodeTitles(ctx, items)
+	return items, nil
 }
 
-func periodKey(freq string, t time.Time) string {
-	utc := t.UTC()
-	switch freq {
-	case "weekly":
-		y, w := utc.ISOWeek()
-		return fmt.Sprintf("%04d-W%02d", y, w)
-	default: // daily
-		return utc.Format("2006-01-02")
+// cacheNodeTitles caches the titles of nodes search results came from that are not
+// cached yet (best-effort), so digests show them like those of polled nodes.
+func (w *V2EXCollector) cacheNodeTitles(ctx context.Context, items []model.NewsItem) {
+	seen := map[string]struct{}{}
+	for _, it := range items {
+		if _, ok := seen[it.NodeName]; ok || it.NodeName == "" {
+			continue
+		}
+		seen[it.NodeName] = struct{}{}
+		if t, _ := w.Store.GetNodeTitle(ctx, "v2ex", it.NodeName); strings.TrimSpace(t) != "" {
+			continue
+		}
+		if title, err := w.Client.NodeTitle(ctx, it.NodeName); err == nil && strings.TrimSpace(title) != "" {
+			_ = w.Store.SetNodeTitle(ctx, "v2ex", it.NodeName, title, 30*24*time.Hour)
+		}
 	}
 }
 
@@ -102,3 +262,18 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// newEntries returns the elements of next that are not in prev.
+func newEntries(prev, next []string) []string {
+	seen := make(map[string]struct{}, len(prev))
+	for _, p := range prev {
+		seen[p] = struct{}{}
+	}
+	var out []string
+	for _, n := range next {
+		if _, ok := seen[n]; !ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
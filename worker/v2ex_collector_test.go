@@ -0,0 +1,247 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/mocksource"
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/selection"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/v2ex"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestV2EXCollectorPicksUpNodesAddedMidRun(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node := r.URL.Query().Get("node_name")
+		mu.Lock()
+		fetched[node]++
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode([]map[string]any{{
+			"id":      len(node),
+			"title":   "Topic in " + node,
+			"replies": 3,
+			"created": time.Now().Add(-time.Hour).Unix(),
+			"node":    map[string]string{"name": node},
+		}})
+	}))
+	defer srv.Close()
+	count := func(node string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return fetched[node]
+	}
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	w := &V2EXCollector{
+		Client:   v2ex.NewClient(srv.URL, ""),
+		Store:    storage.NewRedisStore(rdb),
+		Nodes:    []string{"go", "apple"},
+		Interval: 20 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = w.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor(t, func() bool { return count("go") > 0 })
+	if added := w.SetNodes([]string{"go", "rust"}); len(added) != 1 || added[0] != "rust" {
+		t.Fatalf("SetNodes added = %v, want [rust]", added)
+	}
+	waitFor(t, func() bool { return count("rust") > 0 })
+
+	// Once the new node list is in effect, the removed node is no longer polled.
+	before := count("apple")
+	goBefore := count("go")
+	waitFor(t, func() bool { return count("go") > goBefore+1 })
+	if after := count("apple"); after > before+1 {
+		t.Errorf("removed node polled %d more times, want at most one in-flight run", after-before)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCollectorsWithMockSources(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	store := storage.NewRedisStore(rdb)
+	ctx := context.Background()
+
+	fixtures := filepath.Join("..", "fixtures")
+	(&V2EXCollector{Client: mocksource.NewV2EX(fixtures), Store: store, Nodes: []string{"crypto", "create", "missing"}}).RunOnce(ctx)
+	(&HNCollector{Client: mocksource.NewHackerNews(fixtures), Store: store, Lists: []string{"top"}}).RunOnce(ctx)
+	(&RSSCollector{Client: mocksource.NewRSS(fixtures), Store: store, Feeds: []RSSFeed{{URL: "https://go.dev/blog/feed.atom", Node: "golang"}}}).RunOnce(ctx)
+	(&LobstersCollector{Client: mocksource.NewLobsters(fixtures), Store: store}).RunOnce(ctx)
+	(&RedditCollector{Client: mocksource.NewReddit(fixtures), Store: store, Subreddits: []string{"golang"}}).RunOnce(ctx)
+	(&GitHubTrendingCollector{Client: mocksource.NewGitHub(fixtures), Store: store, Languages: []string{"go"}}).RunOnce(ctx)
+	(&ProductHuntCollector{Client: mocksource.NewProductHunt(fixtures), Store: store}).RunOnce(ctx)
+	(&ArxivCollector{Client: mocksource.NewArxiv(fixtures), Store: store, Categories: []string{"cs.CL"}}).RunOnce(ctx)
+	(&MastodonCollector{Instances: []MastodonSource{mocksource.NewMastodon(fixtures, "mastodon.social")}, Store: store}).RunOnce(ctx)
+	(&BlueskyCollector{Client: mocksource.NewBluesky(fixtures), Store: store, Feeds: []BlueskyFeed{{Node: "golang", Query: "golang"}}}).RunOnce(ctx)
+	(&StackOverflowCollector{Client: mocksource.NewStackOverflow(fixtures), Store: store, Tags: []string{"go"}}).RunOnce(ctx)
+	(&JSONFeedCollector{Client: mocksource.NewJSONFeed(fixtures), Store: store, Feeds: []JSONFeed{{URL: "https://indie.example/feed.json", Label: "indie"}}}).RunOnce(ctx)
+	(&YouTubeCollector{Client: mocksource.NewYouTube(fixtures), Store: store, Feeds: []YouTubeFeed{{ChannelID: "UCgo", Label: "golang"}}}).RunOnce(ctx)
+
+	day := period.Key(period.Daily, time.Now())
+	for source, want := range map[string]int64{"v2ex": 6, "hackernews": 6, "rss": 3, "lobsters": 3, "reddit": 2, "github": 2, "producthunt": 2, "arxiv": 3, "mastodon": 3, "bluesky": 3, "stackoverflow": 3, "jsonfeed": 3, "youtube": 3} {
+		n, err := store.CountNews(ctx, source, day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("%s stored %d items, want %d", source, n, want)
+		}
+	}
+}
+
+// searchSource serves one search hit per query and counts the searches.
+type searchSource struct {
+	mocksource.V2EX
+	mu       sync.Mutex
+	searches int
+}
+
+func (s *searchSource) SearchTopics(ctx context.Context, query string) ([]model.NewsItem, error) {
+	s.mu.Lock()
+	s.searches++
+	s.mu.Unlock()
+	return []model.NewsItem{{Source: "v2ex", ID: "77", Title: "Anyone using " + query + " at work?", NodeName: "programmer", Replies: 12, CreatedAt: time.Now().Add(-time.Hour)}}, nil
+}
+
+func TestV2EXCollectorSearchQueries(t *testing.T) {
+	ctx := context.Background()
+	src := &searchSource{}
+	store := newDeliveryTestStore(t)
+	now := time.Now()
+	w := &V2EXCollector{Client: src, Store: store, Nodes: []string{"q:Rust"}, SearchDelay: -1, Now: func() time.Time { return now }}
+
+	for i := 0; i < 2; i++ {
+		res, err := w.RunOnce(ctx)
+		if err != nil || res.Stored != 1 {
+			t.Fatalf("RunOnce = %+v, %v", res, err)
+		}
+	}
+	if src.searches != 1 {
+		t.Errorf("searches = %d within the search interval, want 1", src.searches)
+	}
+	now = now.Add(DefaultSearchInterval)
+	if _, err := w.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if src.searches != 2 {
+		t.Errorf("searches = %d after the search interval, want 2", src.searches)
+	}
+
+	items, err := store.TopNews(ctx, "v2ex", period.Key(period.Daily, time.Now()), 10)
+	if err != nil || len(items) != 1 || items[0].Item.NodeName != "programmer" {
+		t.Fatalf("stored = %+v, %v", items, err)
+	}
+	if got := selection.FilterByNodes(items, []string{"create", "q:rust"}); len(got) != 1 {
+		t.Errorf("q:rust channel kept %d items, want the search hit", len(got))
+	}
+	if got := selection.FilterByNodes(items, []string{"create", "q:golang"}); len(got) != 0 {
+		t.Errorf("q:golang channel kept %d items, want none", len(got))
+	}
+}
+
+// With IncludePoints, a topic without replies but with likes on API v2 scores
+// under a blend and is stored with its points.
+func TestV2EXCollectorIncludePoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/topics/show.json":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{
+				"id": 2048, "title": "整理了一份 Go 并发模式的笔记", "replies": 0,
+				"created": time.Now().Add(-time.Hour).Unix(), "node": map[string]string{"name": "go"},
+			}})
+		case "/api/v2/topics/2048":
+			_, _ = w.Write([]byte(`{"success": true, "message": "", "result": {"id": 2048, "replies": 0, "likes": 37, "supplements": []}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	day := period.Key(period.Daily, time.Now())
+	for _, include := range []bool{false, true} {
+		mr := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		store := storage.NewRedisStore(rdb)
+		c := &V2EXCollector{Client: v2ex.NewClient(srv.URL, "tok"), Store: store, Nodes: []string{"go"}, IncludePoints: include, Ranking: ranking.Scorer{Signal: ranking.SignalBlend}}
+		if _, err := c.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		items, err := store.TopNews(ctx, "v2ex", day, 10)
+		rdb.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case !include && len(items) != 0:
+			t.Errorf("without points: stored %d items, want 0", len(items))
+		case include && (len(items) != 1 || items[0].Item.Points != 37):
+			t.Errorf("with points: stored %+v, want topic 2048 with 37 points", items)
+		}
+	}
+}
+
+func TestV2EXCollectorArchiveRaw(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `[{"id": 4096, "title": "raw", "replies": 3, "created": %d, "node": {"name": "go"}, "member": {"username": "ann"}}]`, time.Now().Add(-time.Hour).Unix())
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	for _, archive := range []bool{false, true} {
+		store := storage.NewRedisStore(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}))
+		c := &V2EXCollector{Client: v2ex.NewClient(srv.URL, ""), Store: store, Nodes: []string{"go"}, ArchiveRaw: archive}
+		if _, err := c.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := store.GetRaw(ctx, "v2ex", "4096")
+		switch {
+		case !archive && !errors.Is(err, storage.ErrNotFound):
+			t.Errorf("archiving off: GetRaw = %q, %v; want ErrNotFound", raw, err)
+		case archive && (err != nil || !strings.Contains(string(raw), `"username": "ann"`)):
+			t.Errorf("archiving on: GetRaw = %q, %v; want the topic as served", raw, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quaily-journalist/internal/model"
+	"quaily-journalist/internal/ranking"
+	"quaily-journalist/internal/storage"
+	"quaily-journalist/internal/textclean"
+	"quaily-journalist/internal/youtube"
+)
+
+// YouTubeFeed is a channel (ChannelID) or playlist (PlaylistID) the YouTube
+// collector polls; exactly one is set. Label is the node its videos are stored
+// under; empty uses the title of the channel that uploaded each video.
+type YouTubeFeed struct {
+	ChannelID  string
+	PlaylistID string
+	Label      string
+}
+
+func (f YouTubeFeed) String() string {
+	if f.ChannelID != "" {
+		return "channel " + f.ChannelID
+	}
+	return "playlist " + f.PlaylistID
+}
+
+// YouTubeCollector polls YouTube channel and playlist feeds and stores their new
+// videos into period ZSETs, like the RSS collector does for feeds.
+type YouTubeCollector struct {
+	Client          YouTubeSource
+	Store           *storage.RedisStore
+	Feeds           []YouTubeFeed
+	Interval        time.Duration
+	MaxContentRunes int // description budget after cleaning; 0 uses textclean.DefaultMaxRunes
+	// ResumeRatio skips the initial run after a restart when the last run was within
+	// this fraction of Interval; 0 uses DefaultResumeRatio, negative always runs.
+	ResumeRatio float64
+	Now         func() time.Time // clock; nil uses time.Now
+	// QuietHours skips runs that start inside the window; the zero value never does.
+	QuietHours QuietHours
+	// Ranking scores videos; zero fields use ranking.ForSource("youtube"), which
+	// ranks by likes when the client has an API key and by recency otherwise.
+	Ranking ranking.Scorer
+	// BufferSize bounds the items held in memory while Redis is unreachable, to be
+	// stored once it is back; 0 uses DefaultStoreBufferSize.
+	BufferSize int
+	// ArchiveRaw also stores each video's feed entry or API JSON; see
+	// V2EXCollector.ArchiveRaw.
+	ArchiveRaw bool
+
+	mu     sync.Mutex // guards Feeds after Start
+	buffer storeBuffer
+}
+
+// SetNodes replaces the polled channels and playlists; the change applies at the next run.
+func (w *YouTubeCollector) SetNodes(units []YouTubeFeed) (added []string) {
+	return setUnits(&w.mu, &w.Feeds, units, YouTubeFeed.String)
+}
+
+func (w *YouTubeCollector) Start(ctx context.Context) error {
+	if w.Interval <= 0 {
+		w.Interval = time.Hour
+	}
+	if !waitForResume(ctx, w.Store, youTubeCollectorName, w.Interval, w.ResumeRatio, nowFunc(w.Now)) {
+		return nil
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+
+	// initial run
+	w.RunOnce(ctx)
+
+	for w.buffer.wait(ctx, w.Store, t.C) {
+		w.RunOnce(ctx)
+	}
+	return nil
+}
+
+// youTubeCollectorName identifies the collector's persisted status record.
+const youTubeCollectorName = "youtube-collector"
+
+func (w *YouTubeCollector) Name() string { return youTubeCollectorName }
+
+// RunOnce performs a single collection pass, as Start does on every tick, and
+// records the run and its error. Channels and playlists that fail are logged,
+// counted, and joined into the error; the others are still stored.
+func (w *YouTubeCollector) RunOnce(ctx context.Context) (CollectResult, error) {
+	started := nowFunc(w.Now)
+	if err := skipQuiet(ctx, w.Store, youTubeCollectorName, w.QuietHours, started); err != nil {
+		return CollectResult{}, err
+	}
+	if err := skipBlocked(ctx, w.Store, youTubeCollectorName, youtube.Source, started); err != nil {
+		return CollectResult{}, err
+	}
+	res, err := w.collect(ctx, started)
+	recordOutcome(ctx, w.Store, youTubeCollectorName, started, err)
+	countCollected(ctx, w.Store, youtube.Source, started, res.Stored)
+	return res, err
+}
+
+func (w *YouTubeCollector) collect(ctx context.Context, now time.Time) (CollectResult, error) {
+	feeds := currentUnits(&w.mu, &w.Feeds)
+	labels := make([]string, len(feeds))
+	for i, f := range feeds {
+		labels[i] = f.String()
+	}
+	loop := collectLoop{source: youtube.Source, unit: "feed", store: w.Store, buffer: &w.buffer, bufferSize: w.BufferSize, archiveRaw: w.ArchiveRaw, ranking: w.Ranking, now: now,
+		clean: func(it model.NewsItem) model.NewsItem {
+			it.Content = textclean.Clean(it.Content, textclean.Options{MaxRunes: w.MaxContentRunes})
+			return it
+		}}
+	return loop.run(ctx, labels, func(ctx context.Context, i int) ([]model.NewsItem, error) {
+		return w.fetch(ctx, feeds[i])
+	})
+}
+
+func (w *YouTubeCollector) fetch(ctx context.Context, f YouTubeFeed) ([]model.NewsItem, error) {
+	if f.ChannelID != "" {
+		return w.Client.ChannelVideos(ctx, f.ChannelID, f.Label)
+	}
+	return w.Client.PlaylistVideos(ctx, f.PlaylistID, f.Label)
+}
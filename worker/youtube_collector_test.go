@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quaily-journalist/internal/period"
+	"quaily-journalist/internal/youtube"
+)
+
+// youTubeServer serves one channel feed with a fresh and an older video, fails
+// every playlist, and reports 900 likes for the older video in the Data API.
+func youTubeServer(t *testing.T, now time.Time) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/feeds/videos.xml" && r.URL.Query().Get("channel_id") == "UCgo":
+			fmt.Fprintf(w, `<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+				<title>Go Example Channel</title>
+				<entry><yt:videoId>fresh</yt:videoId><title>Fresh</title><author><name>Go Example Channel</name></author><published>%s</published></entry>
+				<entry><yt:videoId>liked</yt:videoId><title>Liked</title><author><name>Go Example Channel</name></author><published>%s</published></entry>
+				</feed>`, now.Add(-time.Hour).Format(time.RFC3339), now.Add(-20*time.Hour).Format(time.RFC3339))
+		case r.URL.Path == "/api/videos":
+			fmt.Fprint(w, `{"items": [{"id": "liked", "statistics": {"viewCount": "50000", "likeCount": "900", "commentCount": "12"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Without an API key videos rank by age; with one, likes lift the older video
+// above the fresh one. A playlist that fails is counted and named in the error.
+func TestYouTubeCollectorModes(t *testing.T) {
+	now := time.Now().UTC()
+	srv := youTubeServer(t, now)
+	for _, c := range []struct {
+		key   string
+		first string
+	}{
+		{"", "Fresh"},
+		{"secret", "Liked"},
+	} {
+		store := newDeliveryTestStore(t)
+		ctx := context.Background()
+		w := &YouTubeCollector{Client: youtube.NewClient(srv.URL, srv.URL+"/api", c.key), Store: store, Feeds: []YouTubeFeed{
+			{PlaylistID: "PLgone"},
+			{ChannelID: "UCgo"},
+		}}
+		res, err := w.RunOnce(ctx)
+		if res.Fetched != 2 || res.Stored != 2 || res.Failed != 1 {
+			t.Fatalf("key %q: RunOnce = %+v", c.key, res)
+		}
+		if err == nil || !strings.Contains(err.Error(), "playlist PLgone") {
+			t.Errorf("key %q: RunOnce error = %v; want the failed playlist named", c.key, err)
+		}
+		got, err := store.TopNews(ctx, youtube.Source, period.Key(period.Weekly, now), 10)
+		if err != nil || len(got) != 2 {
+			t.Fatalf("key %q: TopNews = %d items, %v; want 2", c.key, len(got), err)
+		}
+		if got[0].Item.Title != c.first || got[0].Item.NodeName != "Go Example Channel" {
+			t.Errorf("key %q: top video = %+v, want %s", c.key, got[0].Item, c.first)
+		}
+	}
+}